@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/database"
@@ -15,6 +17,8 @@ var (
 	direction  = flag.String("direction", "up", "migration direction (up or down)")
 	version    = flag.Uint("version", 0, "migrate to specific version (0 = latest)")
 	status     = flag.Bool("status", false, "show migration status")
+	force      = flag.Uint("force", 0, "set the schema version and clear the dirty flag without running migrations")
+	yes        = flag.Bool("yes", false, "don't ask for confirmation before -force")
 )
 
 func main() {
@@ -34,14 +38,7 @@ func main() {
 	})
 
 	// Build database URL
-	dbURL := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Database,
-	)
+	dbURL := database.BuildDSN(cfg.Database)
 
 	// Show status and exit
 	if *status {
@@ -56,10 +53,19 @@ func main() {
 		} else {
 			fmt.Printf("Current version: %d\n", ver)
 			fmt.Printf("Dirty: %v\n", dirty)
+			if dirty {
+				fmt.Println("A migration failed part way; fix the schema, then run with -force <last good version>")
+			}
 		}
 		return
 	}
 
+	// Force the version and exit
+	if flagSet("force") {
+		forceVersion(dbURL, *force, log)
+		return
+	}
+
 	// Run migrations
 	log.Info().
 		Str("host", cfg.Database.Host).
@@ -91,3 +97,46 @@ func main() {
 
 	log.Info().Msg("Migration completed successfully")
 }
+
+// forceVersion runs -force after showing the current state and asking for confirmation
+func forceVersion(dbURL string, target uint, log logger.Logger) {
+	ver, dirty, err := database.MigrateStatus(dbURL)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get migration status")
+		os.Exit(1)
+	}
+	fmt.Printf("Current version: %d (dirty: %v)\n", ver, dirty)
+
+	if !*yes && !confirm(fmt.Sprintf("Mark the database as migrated to version %d? No migrations will be run.", target)) {
+		fmt.Println("Aborted")
+		os.Exit(1)
+	}
+
+	if err := database.MigrateForce(dbURL, target, log); err != nil {
+		log.Error().Err(err).Msg("Failed to force migration version")
+		os.Exit(1)
+	}
+	fmt.Printf("Forced version %d, dirty flag cleared\n", target)
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
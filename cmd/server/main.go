@@ -11,9 +11,13 @@ import (
 
 	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/database"
+	"github.com/waffles/waffles/internal/handler"
 	"github.com/waffles/waffles/internal/metrics"
 	"github.com/waffles/waffles/internal/repository"
 	"github.com/waffles/waffles/internal/server"
+	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/internal/service/registry"
+	"github.com/waffles/waffles/internal/tracing"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -34,9 +38,14 @@ func main() {
 	}
 
 	// Initialize logger
+	subsystemLevels := make(map[string]logger.Level, len(cfg.Logging.Subsystems))
+	for subsystem, level := range cfg.Logging.Subsystems {
+		subsystemLevels[subsystem] = logger.Level(level)
+	}
 	log := logger.NewZerolog(logger.Config{
-		Level:  logger.Level(cfg.Logging.Level),
-		Format: cfg.Logging.Format,
+		Level:      logger.Level(cfg.Logging.Level),
+		Format:     cfg.Logging.Format,
+		Subsystems: subsystemLevels,
 	})
 
 	log.Info().
@@ -53,20 +62,23 @@ func main() {
 	}
 
 	// Run migrations
-	dbURL := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Database,
-	)
+	dbURL := database.BuildDSN(cfg.Database)
 
 	if err := database.MigrateUp(dbURL, log); err != nil {
 		log.Error().Err(err).Msg("Failed to run migrations")
 		os.Exit(1)
 	}
 
+	// Encrypt server auth configs at rest
+	var authConfigCipher *repository.AuthConfigCipher
+	if enc := cfg.Secrets.AuthConfigEncryption; enc.Enabled {
+		authConfigCipher, err = repository.NewAuthConfigCipherFromBase64(enc.KeyVersion, enc.Key, enc.PreviousKeys)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up auth config encryption")
+			os.Exit(1)
+		}
+	}
+
 	// Initialize metrics
 	var metricsRegistry *metrics.Registry
 	var metricsServer *metrics.Server
@@ -77,11 +89,35 @@ func main() {
 			Msg("Initializing Prometheus metrics")
 
 		metricsRegistry = metrics.NewRegistry()
+		if !cfg.Metrics.CacheMetrics {
+			metricsRegistry.DisableCacheMetrics()
+		}
 		metricsServer = metrics.NewServer(metricsRegistry, cfg.Metrics.PrometheusPort, log)
 	}
 
+	// Initialize tracing
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, version)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				log.Warn().Err(err).Msg("Failed to flush traces")
+			}
+		}()
+		log.Info().
+			Str("endpoint", cfg.Tracing.Endpoint).
+			Any("sample_ratio", cfg.Tracing.SampleRatio).
+			Msg("OpenTelemetry tracing enabled")
+	}
+
 	// Create HTTP server
 	srv := server.New(cfg, db, log, metricsRegistry, metricsServer)
+	srv.SetBuildInfo(handler.BuildInfo{Version: version, BuildTime: buildTime})
 	srv.SetupRoutes()
 
 	// Create context that listens for shutdown signals
@@ -111,8 +147,10 @@ func main() {
 
 		// Start Server Health collector (collects every 30 seconds)
 		serverRepo := repository.NewServerRepository(db.Pool, log)
+		serverRepo.SetAuthConfigCipher(authConfigCipher)
 		healthProvider := metrics.NewHealthProviderAdapter(serverRepo)
 		healthCollector := metrics.NewServerHealthCollector(metricsRegistry, healthProvider)
+		mcpHealthCollector := metrics.NewMCPServerHealthCollector(metricsRegistry, serverRepo)
 		go func() {
 			ticker := time.NewTicker(30 * time.Second)
 			defer ticker.Stop()
@@ -121,6 +159,9 @@ func main() {
 			if err := healthCollector.Collect(ctx); err != nil {
 				log.Warn().Err(err).Msg("Initial server health collection failed")
 			}
+			if err := mcpHealthCollector.Collect(ctx); err != nil {
+				log.Warn().Err(err).Msg("Initial MCP server health collection failed")
+			}
 
 			for {
 				select {
@@ -128,6 +169,9 @@ func main() {
 					if err := healthCollector.Collect(ctx); err != nil {
 						log.Warn().Err(err).Msg("Server health collection failed")
 					}
+					if err := mcpHealthCollector.Collect(ctx); err != nil {
+						log.Warn().Err(err).Msg("MCP server health collection failed")
+					}
 				case <-ctx.Done():
 					return
 				}
@@ -136,6 +180,37 @@ func main() {
 		log.Info().Msg("Server health collector started")
 	}
 
+	// Start health check scheduler (probes active servers on their health check interval)
+	if cfg.HealthCheck.Enabled {
+		// Shares the API's registry service, so scheduled checks connect under the same
+		// address guard, egress policy and secret store as manual ones
+		healthScheduler := registry.NewHealthScheduler(srv.RegistryService(), registry.HealthSchedulerConfig{
+			TickInterval: cfg.HealthCheck.TickInterval,
+			Workers:      cfg.HealthCheck.Workers,
+		}, log, metricsRegistry)
+		go healthScheduler.Run(ctx)
+		log.Info().
+			Dur("tick_interval", cfg.HealthCheck.TickInterval).
+			Int("workers", cfg.HealthCheck.Workers).
+			Msg("Health check scheduler started")
+	}
+
+	// Start audit log pruner (deletes audit logs past the retention period)
+	if cfg.Audit.Retention.Enabled {
+		auditPruner := audit.NewPruner(repository.NewAuditRepository(db.Pool), audit.PrunerConfig{
+			Retention: cfg.Audit.Retention.Period,
+			Interval:  cfg.Audit.Retention.Interval,
+			BatchSize: cfg.Audit.Retention.BatchSize,
+			DryRun:    cfg.Audit.Retention.DryRun,
+		}, log, metricsRegistry)
+		go auditPruner.Run(ctx)
+		log.Info().
+			Dur("retention", cfg.Audit.Retention.Period).
+			Dur("interval", cfg.Audit.Retention.Interval).
+			Bool("dry_run", cfg.Audit.Retention.DryRun).
+			Msg("Audit log pruner started")
+	}
+
 	// Listen for interrupt signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -6,13 +6,19 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Secrets  SecretsConfig  `mapstructure:"secrets"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Secrets     SecretsConfig     `mapstructure:"secrets"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Gateway     GatewayConfig     `mapstructure:"gateway"`
+	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
+	Registry    RegistryConfig    `mapstructure:"registry"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Audit       AuditConfig       `mapstructure:"audit"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -22,8 +28,9 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
-	Environment     string        `mapstructure:"environment"` // development, staging, production
-	StaticDir       string        `mapstructure:"static_dir"`  // Path to frontend static files (empty = no UI)
+	DrainTimeout    time.Duration `mapstructure:"drain_timeout"` // Wait for in-flight gateway requests on shutdown before closing them
+	Environment     string        `mapstructure:"environment"`   // development, staging, production
+	StaticDir       string        `mapstructure:"static_dir"`    // Path to frontend static files (empty = no UI)
 }
 
 // DatabaseConfig holds database connection configuration
@@ -37,6 +44,16 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	// How often idle connections are checked and the pool topped up to max_idle_conns (default: 1m)
+	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+	// SSL mode: disable, allow, prefer, require, verify-ca or verify-full (default: disable)
+	SSLMode string `mapstructure:"ssl_mode"`
+	// CA certificate file used to verify the server with verify-ca and verify-full
+	SSLRootCert string `mapstructure:"ssl_root_cert"`
+	// How long to wait for a connection to be established (default: 0 = no limit)
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// Abort statements, including migrations, running longer than this (default: 0 = no limit)
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"`
 }
 
 // RedisConfig holds Redis connection configuration
@@ -59,10 +76,20 @@ type AuthConfig struct {
 	CookieSameSite string        `mapstructure:"cookie_same_site"` // strict, lax, none
 	CookieDomain   string        `mapstructure:"cookie_domain"`    // Optional: for cross-subdomain
 
-	// Casbin authorization
+	// Where browser sessions are tracked: "cookie" (stateless, logout only clears the
+	// cookie) or "memory" (server-side, so logout and admins can revoke sessions)
+	SessionStore string `mapstructure:"session_store"`
+
+	// Casbin authorization. When both are set, policies are loaded from these files and
+	// POST /api/v1/admin/rbac/reload applies edits without a restart; otherwise built-in
+	// default policies are used.
 	CasbinModelPath  string `mapstructure:"casbin_model_path"`
 	CasbinPolicyPath string `mapstructure:"casbin_policy_path"`
 
+	// API keys not used for this long are rejected as expired, measured from last use (or
+	// creation if never used). 0 disables inactivity expiry.
+	APIKeyInactivityTimeout time.Duration `mapstructure:"api_key_inactivity_timeout"`
+
 	// Resource RBAC - controls which MCP servers users can see/execute based on role
 	// When enabled, users only see servers in namespaces their role has access to
 	// When disabled, all authenticated users see all servers (existing behavior)
@@ -71,6 +98,10 @@ type AuthConfig struct {
 	// Legacy alias for backwards compatibility - deprecated, use ResourceRBACEnabled
 	ServerGroupRBACEnabled bool `mapstructure:"server_group_rbac_enabled"`
 
+	// Short-lived cache of resource RBAC decisions, so repeat requests to a server skip
+	// the namespace access query
+	AccessCache AccessCacheConfig `mapstructure:"access_cache"`
+
 	// MCP Client Authentication - controls which auth methods are accepted for MCP clients
 	MCPAuth MCPAuthConfig `mapstructure:"mcp_auth"`
 
@@ -134,6 +165,49 @@ type OAuthConfig struct {
 	// Optional: restrict login to specific email domains
 	// e.g., ["example.com", "company.org"]
 	AllowedDomains []string `mapstructure:"allowed_domains"`
+
+	// Caching of bearer token validations for MCP clients
+	TokenCache OAuthTokenCacheConfig `mapstructure:"token_cache"`
+
+	// Additional OIDC providers whose bearer tokens MCP clients may use. Tokens are
+	// routed by their iss claim; the provider above stays the primary one.
+	Providers []OAuthProviderConfig `mapstructure:"providers"`
+}
+
+// OAuthProviderConfig is an additional OIDC provider for bearer token validation. It
+// shares base_url, default_role and auto_create_users with the primary provider.
+type OAuthProviderConfig struct {
+	// Name recorded as the provider of its users; must be unique and not "oidc"
+	Name           string   `mapstructure:"name"`
+	Issuer         string   `mapstructure:"issuer"`
+	ClientID       string   `mapstructure:"client_id"`
+	ClientSecret   string   `mapstructure:"client_secret"`
+	AllowedDomains []string `mapstructure:"allowed_domains"`
+}
+
+// OAuthTokenCacheConfig controls the in-memory cache of validated bearer tokens. A cached
+// token isn't revalidated with the provider until its entry expires, so revocation at the
+// provider takes effect after at most TTL.
+type OAuthTokenCacheConfig struct {
+	// Cache successful validations (default: true)
+	Enabled bool `mapstructure:"enabled"`
+	// How long a validation is reused; never past the token's exp claim (default: 5m)
+	TTL time.Duration `mapstructure:"ttl"`
+	// Most tokens cached; the least recently used is evicted beyond this (default: 10000)
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// AccessCacheConfig controls the in-memory cache of server access decisions, keyed by
+// the caller's roles, the server and the access level. The cache is dropped when namespace
+// access changes or the RBAC policy is reloaded on this instance; other instances see the
+// change after at most TTL.
+type AccessCacheConfig struct {
+	// Cache access decisions (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// How long a decision is reused (default: 30s)
+	TTL time.Duration `mapstructure:"ttl"`
+	// Most decisions cached; further ones aren't cached until entries expire (default: 10000)
+	MaxEntries int `mapstructure:"max_entries"`
 }
 
 // LDAPConfig holds LDAP/Active Directory authentication configuration
@@ -248,6 +322,36 @@ type PasswordPolicyConfig struct {
 type SecretsConfig struct {
 	Provider string           `mapstructure:"provider"` // env or aws
 	AWS      AWSSecretsConfig `mapstructure:"aws"`
+	AuthRefs SecretRefsConfig `mapstructure:"auth_refs"`
+	// Encryption of server auth configs stored in the database
+	AuthConfigEncryption AuthConfigEncryptionConfig `mapstructure:"auth_config_encryption"`
+}
+
+// AuthConfigEncryptionConfig controls encryption of server auth configs at rest with
+// AES-GCM. Keys are base64-encoded 16, 24 or 32 byte keys, typically injected into the
+// environment from a KMS or secrets manager. Each ciphertext records its key version, so a
+// key is rotated by moving it to previous_keys and setting a new key and key_version.
+type AuthConfigEncryptionConfig struct {
+	// Encrypt auth configs when servers are stored (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Version of key, 1-255, recorded in what it encrypts (default: 1)
+	KeyVersion int `mapstructure:"key_version"`
+	// Key new auth configs are encrypted with
+	Key string `mapstructure:"key"`
+	// Older keys by version, still used to decrypt configs stored before a rotation
+	PreviousKeys map[string]string `mapstructure:"previous_keys"`
+}
+
+// SecretRefsConfig controls secret references in server auth configs. With it enabled, an
+// auth config value such as "env://MCP_SECRET_GITHUB_TOKEN" is replaced by the secret it
+// names when upstream requests are authenticated, instead of being stored in the database.
+type SecretRefsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Only environment variables starting with this prefix can be referenced, so auth
+	// configs can't read the gateway's own settings (default: MCP_SECRET_)
+	EnvPrefix string `mapstructure:"env_prefix"`
+	// How long a resolved secret is reused (default: 1m)
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
 }
 
 // AWSSecretsConfig holds AWS Secrets Manager configuration
@@ -260,10 +364,357 @@ type AWSSecretsConfig struct {
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`  // debug, info, warn, error
 	Format string `mapstructure:"format"` // json or console
+	// Per-subsystem level overrides, e.g. {gateway: debug, api: info}
+	Subsystems map[string]string `mapstructure:"subsystems"`
+	Access     AccessLogConfig   `mapstructure:"access"`
+}
+
+// AccessLogConfig controls the HTTP request log
+type AccessLogConfig struct {
+	// Fraction of successful (2xx/3xx) requests logged, 0-1; 4xx and 5xx are always logged
+	SampleRate           float64 `mapstructure:"sample_rate"`
+	IncludeUserAgent     bool    `mapstructure:"include_user_agent"`
+	IncludeUserID        bool    `mapstructure:"include_user_id"`
+	IncludeLatencyBucket bool    `mapstructure:"include_latency_bucket"` // Coarse bucket such as "100ms-1s"
 }
 
 // MetricsConfig holds metrics configuration
 type MetricsConfig struct {
 	Enabled        bool `mapstructure:"enabled"`
 	PrometheusPort int  `mapstructure:"prometheus_port"`
+	// Export hits, misses, size and evictions of every cache, labelled by cache (default: true)
+	CacheMetrics bool `mapstructure:"cache_metrics"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration. Spans cover each API request,
+// proxied and JSON-RPC calls to MCP servers, session initialization and health checks.
+type TracingConfig struct {
+	// Export spans (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// OTLP/HTTP endpoint spans are sent to, e.g. http://jaeger:4318
+	Endpoint string `mapstructure:"endpoint"`
+	// Service name spans are reported under (default: waffles)
+	ServiceName string `mapstructure:"service_name"`
+	// Fraction of new traces sampled, 0 to 1; traces a client started follow the client's
+	// sampling decision (default: 1)
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// HealthCheckConfig holds the background server health check scheduler configuration
+type HealthCheckConfig struct {
+	// Run scheduled health checks against all active servers
+	Enabled bool `mapstructure:"enabled"`
+	// How often to look for servers whose health check interval has elapsed (default: 10s)
+	TickInterval time.Duration `mapstructure:"tick_interval"`
+	// Maximum number of concurrent health checks (default: 4)
+	Workers int `mapstructure:"workers"`
+	// How servers are probed: "auto" runs an MCP initialize handshake for MCP transports
+	// without a health_check_url, "http" always uses an HTTP GET (default: auto)
+	Mode string `mapstructure:"mode"`
+	// Tag servers with the capabilities they advertise to MCP health checks, as system tags
+	// like "has:resources" kept apart from user tags (default: false)
+	AutoTag bool `mapstructure:"auto_tag"`
+}
+
+// RegistryConfig holds limits on registered MCP servers
+type RegistryConfig struct {
+	// Most servers that may be active at once; creating or enabling a server beyond it
+	// is rejected. Disabled servers don't count (default: 0 = unlimited)
+	MaxActiveServers int `mapstructure:"max_active_servers"`
+}
+
+// RateLimitConfig holds per-caller request throttling for authenticated API routes.
+// Callers are identified by user, falling back to API key and then client IP.
+type RateLimitConfig struct {
+	// Throttle requests with a token bucket per caller (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Sustained request rate and burst for read-only and list routes (default: 20/s, burst 40)
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+	// Sustained request rate and burst for the MCP proxy and tools/call routes (default: 5/s, burst 10)
+	ToolCallRequestsPerSecond float64 `mapstructure:"tool_call_requests_per_second"`
+	ToolCallBurst             int     `mapstructure:"tool_call_burst"`
+	// Most random extra wait added to Retry-After on 429s to spread out client retries (default: 2s, 0 = none)
+	RetryAfterJitter time.Duration `mapstructure:"retry_after_jitter"`
+}
+
+// Gateway aggregation modes
+const (
+	AggregationModeBestEffort = "best_effort"
+	AggregationModeFailFast   = "fail_fast"
+)
+
+// Gateway retry jitter strategies
+const (
+	JitterNone         = "none"
+	JitterFull         = "full"
+	JitterEqual        = "equal"
+	JitterDecorrelated = "decorrelated"
+)
+
+// Health check modes
+const (
+	HealthCheckModeAuto = "auto"
+	HealthCheckModeHTTP = "http"
+)
+
+// GatewayConfig holds MCP gateway proxying configuration
+type GatewayConfig struct {
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	// Reject tools/call for tools missing from the server's cached tools/list (default: false)
+	RejectUnknownTools bool `mapstructure:"reject_unknown_tools"`
+	// Refresh a cached tools/list older than this before using it to reject a tool call (default: 5m, 0 = never)
+	ToolsCacheMaxAge time.Duration `mapstructure:"tools_cache_max_age"`
+	// Servers whose tools/list is cached at once; the least recently used is evicted (default: 1000, 0 = unlimited)
+	ToolsCacheMaxEntries int `mapstructure:"tools_cache_max_entries"`
+	// Limit concurrent upstream calls to each server's max_connections, queueing the rest (default: false)
+	EnforceMaxConnections bool `mapstructure:"enforce_max_connections"`
+	// Refetch a server's tools/list in the background when it sends tools list_changed (default: false)
+	WarmToolsOnListChanged bool `mapstructure:"warm_tools_on_list_changed"`
+	// How aggregated responses across servers handle a failing server: "best_effort" returns
+	// partial results with per-server errors, "fail_fast" fails the request (default: best_effort)
+	AggregationMode string `mapstructure:"aggregation_mode"`
+	// Most servers an aggregated response calls at once (default: 8)
+	AggregationConcurrency int `mapstructure:"aggregation_concurrency"`
+	// Store MCP sessions with Streamable HTTP servers in the database and resume them after
+	// a restart instead of re-initializing (default: false)
+	PersistSessions bool `mapstructure:"persist_sessions"`
+	// Evict MCP sessions with Streamable HTTP servers unused for this long, terminating them
+	// on the server (default: 30m, 0 = never)
+	SessionIdleTTL time.Duration `mapstructure:"session_idle_ttl"`
+	// Most MCP sessions held at once; past it the least recently used is evicted and
+	// terminated (default: 1000, 0 = unlimited)
+	MaxSessions int `mapstructure:"max_sessions"`
+	// Largest upstream response body read; a server's max_response_bytes overrides it
+	// (default: 4MB, 0 = unlimited)
+	MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+	// Per-call timeout of list methods (tools/list, resources/list, ...) on servers without
+	// a timeout_seconds of their own (default: 0 = the client timeout)
+	ListTimeout time.Duration `mapstructure:"list_timeout"`
+	// Largest JSON-RPC batch forwarded to a server; a server's max_batch_size overrides it
+	// (default: 100, 0 = unlimited)
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+	// Largest tools/call request body accepted from a client (default: 1MB, 0 = unlimited)
+	MaxRequestBytes int64 `mapstructure:"max_request_bytes"`
+	// Skip replicas whose latest health check failed or whose circuit breaker is open when
+	// load balancing across a replica group (default: true)
+	ReplicaHealthGating bool `mapstructure:"replica_health_gating"`
+	// Most initializes (including re-initializes after an expired session) sent to one
+	// server per minute; more fail until the window slides (default: 30, 0 = unlimited)
+	MaxInitializesPerMinute int `mapstructure:"max_initializes_per_minute"`
+	// Most clients subscribed at once to one server's resource updates and elicitations;
+	// more are refused with 503 (default: 100, 0 = unlimited)
+	MaxEventSubscribers int `mapstructure:"max_event_subscribers"`
+	// Probe servers with no explicit transport and no /mcp URL: Streamable HTTP first,
+	// then SSE. When both fail the call fails with both reasons instead of falling back
+	// to plain HTTP (default: false)
+	ProbeTransport bool `mapstructure:"probe_transport"`
+	// Bounds on the per-request timeout clients may ask for in JSON-RPC _meta
+	TimeoutHints TimeoutHintConfig `mapstructure:"timeout_hints"`
+	// Detection of server URLs and requests that loop back through the gateway
+	LoopDetection LoopDetectionConfig `mapstructure:"loop_detection"`
+	// Retries of upstream requests that fail to connect, and event stream reconnects
+	Retry RetryConfig `mapstructure:"retry"`
+	// Record of proxied requests that failed after retries were exhausted
+	DeadLetter DeadLetterConfig `mapstructure:"dead_letter"`
+	// In-memory record of recent requests and responses per server, for live debugging
+	DebugLog DebugLogConfig `mapstructure:"debug_log"`
+	// Proxy and host allowlist for requests the gateway sends upstream
+	Egress EgressConfig `mapstructure:"egress"`
+	// Client-facing endpoint for MCP clients that only speak the legacy SSE transport
+	LegacySSE LegacySSEConfig `mapstructure:"legacy_sse"`
+	// Keepalive of the persistent connections to WebSocket servers
+	WebSocket WebSocketConfig `mapstructure:"websocket"`
+	// Shape of the errors returned to clients for failed upstream calls
+	ErrorNormalization ErrorNormalizationConfig `mapstructure:"error_normalization"`
+	// Priority hint forwarded to servers with each request
+	Priority PriorityConfig `mapstructure:"priority"`
+}
+
+// PriorityConfig controls the priority hint forwarded with gateway requests, for servers
+// that schedule work by it. A caller's priority comes from their roles, or the default; the
+// client may ask for a lower one with the header, but not a higher one.
+type PriorityConfig struct {
+	// Forward priority hints (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Header the client can set and the hint is forwarded in (default: X-MCP-Priority, empty = neither)
+	Header string `mapstructure:"header"`
+	// Key of the hint in the _meta of forwarded JSON-RPC params; not added to requests
+	// proxied as they are (empty = not sent)
+	MetaKey string `mapstructure:"meta_key"`
+	// Allowed priorities, lowest first (default: [low, normal, high])
+	Levels []string `mapstructure:"levels"`
+	// Priority of callers without a role mapping (default: normal)
+	Default string `mapstructure:"default"`
+	// Priority by role; the highest of a caller's roles applies
+	Roles map[string]string `mapstructure:"roles"`
+	// Servers whose requests carry the hint (empty = all)
+	ServerIDs []string `mapstructure:"server_ids"`
+}
+
+// ErrorNormalizationConfig controls the errors clients get for failed upstream calls. When
+// enabled, every such error is a JSON-RPC response with jsonrpc, id and error{code, message,
+// data}, and failures reaching the server (connection refused, timeouts, HTTP error
+// statuses) get stable gateway codes and messages instead of the underlying error's text.
+type ErrorNormalizationConfig struct {
+	// Return normalized JSON-RPC errors (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Include the underlying error's text as error.data.detail; it can reveal upstream host
+	// names and addresses (default: true)
+	IncludeDetail bool `mapstructure:"include_detail"`
+}
+
+// LegacySSEConfig controls the gateway's legacy SSE endpoint: clients open an event stream
+// at /gateway/{server_id}/sse, POST JSON-RPC messages to the endpoint it announces, and
+// receive the responses as events on the stream, whatever transport the server uses.
+type LegacySSEConfig struct {
+	// Serve the legacy SSE endpoint (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Interval between keepalive comments on idle streams (default: 30s, 0 = none)
+	KeepaliveInterval time.Duration `mapstructure:"keepalive_interval"`
+	// Most responses queued for a stream that isn't reading; more are dropped (default: 64)
+	MaxQueuedEvents int `mapstructure:"max_queued_events"`
+}
+
+// WebSocketConfig controls how connections to WebSocket servers are kept alive and how
+// soon a dead one is noticed and reconnected
+type WebSocketConfig struct {
+	// Interval between pings on each connection (default: 30s, 0 = none)
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// How long a connection may go without any message or pong before it is considered
+	// dead and reconnected (default: 60s, 0 = twice ping_interval)
+	PongTimeout time.Duration `mapstructure:"pong_timeout"`
+}
+
+// EgressConfig controls where the gateway's upstream requests go, for networks where
+// outbound traffic must pass through a proxy. It covers proxied calls, SSE, Streamable
+// HTTP and WebSocket connections, and transport probes.
+type EgressConfig struct {
+	// HTTP(S) proxy for every upstream request, e.g. http://proxy.corp:3128 (empty = the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment)
+	ProxyURL string `mapstructure:"proxy_url"`
+	// Hosts upstream requests may go to; "*.example.com" matches its subdomains. Requests
+	// to other hosts are refused before connecting (empty = any host).
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// Refuse server URLs, and upstream connections, to loopback, link-local (including cloud
+	// metadata), private and other internal addresses. Host names are resolved and checked
+	// when dialing, so DNS rebinding can't get around it (default: false).
+	BlockPrivateAddresses bool `mapstructure:"block_private_addresses"`
+	// Internal ranges that stay reachable when blocking, e.g. 127.0.0.1/32 for a local server
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
+}
+
+// DeadLetterConfig controls the dead-letter log of proxied requests that failed for good.
+// Entries are kept in memory, with params redacted using audit.redact_keys, and listed
+// at GET /api/v1/admin/dead-letters.
+type DeadLetterConfig struct {
+	// Record failed requests (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Most entries kept; the oldest is dropped when full (default: 1000)
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// DebugLogConfig controls the in-memory record of each server's recent requests and
+// responses. Params and responses are redacted with audit.redact_keys; nothing is persisted.
+type DebugLogConfig struct {
+	// Keep recent requests and responses (default: false)
+	Enabled bool `mapstructure:"enabled"`
+	// Most entries kept per server; the oldest is dropped when full (default: 50)
+	MaxEntries int `mapstructure:"max_entries"`
+	// Largest response kept; bigger ones are recorded without their body (default: 16KB, 0 = unlimited)
+	MaxResponseBytes int `mapstructure:"max_response_bytes"`
+	// Servers whose traffic is kept (empty = all)
+	ServerIDs []string `mapstructure:"server_ids"`
+}
+
+// RetryConfig controls the backoff between retries. SSE messages and initialize requests
+// are retried only when they fail to connect, since the server never saw them. Event
+// stream reconnects use the jitter strategy with their own 1s to 30s delays.
+type RetryConfig struct {
+	// How delays are randomized so instances don't retry in lockstep: none, full, equal or
+	// decorrelated (default: equal)
+	Jitter string `mapstructure:"jitter"`
+	// Delay before the first retry, doubled for each one after it (default: 200ms)
+	BaseDelay time.Duration `mapstructure:"base_delay"`
+	// Longest delay between retries (default: 5s)
+	MaxDelay time.Duration `mapstructure:"max_delay"`
+	// Most retries of a request that fails to connect (default: 2, 0 = none)
+	MaxRetries int `mapstructure:"max_retries"`
+	// Most retries of the notifications/initialized handshake after a transient failure:
+	// no connection, a timeout, or a 408, 429, 502, 503 or 504. A session whose handshake
+	// still fails isn't used until the notification gets through (default: 2, 0 = none)
+	InitializedRetries int `mapstructure:"initialized_retries"`
+}
+
+// LoopDetectionConfig controls how the gateway keeps from proxying to itself. Proxied
+// requests carry the IDs of the gateways they passed through, and a request carrying
+// this gateway's ID is rejected.
+type LoopDetectionConfig struct {
+	// Reject self-referential server URLs and looping requests (default: true)
+	Enabled bool `mapstructure:"enabled"`
+	// Identifies this gateway in proxied requests. Replicas behind one address should
+	// share it; empty generates a random ID at startup (default: "")
+	GatewayID string `mapstructure:"gateway_id"`
+	// Addresses that reach this gateway in addition to its listen address, such as its
+	// public URL; servers with the same host and port are rejected
+	SelfURLs []string `mapstructure:"self_urls"`
+}
+
+// TimeoutHintConfig bounds the timeout a client may request with _meta.timeoutMs.
+// A hint is also capped by the server's timeout_seconds when that is set.
+type TimeoutHintConfig struct {
+	// Honor client timeout hints when deriving the upstream deadline (default: true)
+	Enabled bool `mapstructure:"enabled"`
+	// Longest timeout a client may request (default: 5m)
+	Max time.Duration `mapstructure:"max"`
+	// Per-role replacements for Max; the largest entry among a user's roles applies
+	RoleMax map[string]time.Duration `mapstructure:"role_max"`
+}
+
+// CircuitBreakerConfig holds per-server circuit breaker settings
+type CircuitBreakerConfig struct {
+	// Enable circuit breaking for calls to upstream MCP servers
+	Enabled bool `mapstructure:"enabled"`
+	// Consecutive failures before the breaker opens (default: 5)
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// How long the breaker stays open before allowing a trial call (default: 30s)
+	OpenTimeout time.Duration `mapstructure:"open_timeout"`
+	// Close the breaker when an admin-triggered health check succeeds (default: true)
+	ResetOnManualHealthCheck bool `mapstructure:"reset_on_manual_health_check"`
+	// Successful calls slower than this count as slow; servers can set their own
+	// latency_budget_ms (default: 0 = only servers with their own budget)
+	LatencyBudget time.Duration `mapstructure:"latency_budget"`
+	// Fraction of the last slow_call_window successful calls that must be slow to open the breaker (default: 0.5)
+	SlowCallRatio float64 `mapstructure:"slow_call_ratio"`
+	// Number of recent successful calls the slow call ratio covers (default: 20)
+	SlowCallWindow int `mapstructure:"slow_call_window"`
+}
+
+// AuditConfig controls what gateway audit logs record about MCP tool calls
+type AuditConfig struct {
+	// Tool argument keys whose values are redacted, matched case-insensitively at any depth
+	// (default: password, secret, token, api_key, apikey, authorization)
+	RedactKeys []string `mapstructure:"redact_keys"`
+	// Largest argument summary stored per tool call; bigger ones keep only the argument
+	// names (default: 2048)
+	MaxArgumentBytes int `mapstructure:"max_argument_bytes"`
+	// Record requests denied by role, scope or namespace checks on every protected route,
+	// not only the gateway (default: true)
+	AccessDenied bool `mapstructure:"access_denied"`
+	// Deletion of audit logs past their retention period
+	Retention AuditRetentionConfig `mapstructure:"retention"`
+}
+
+// AuditRetentionConfig controls the background job that prunes old audit logs
+type AuditRetentionConfig struct {
+	// Run the pruner (default: false, keep audit logs forever)
+	Enabled bool `mapstructure:"enabled"`
+	// How long audit logs are kept (default: 2160h, 90 days)
+	Period time.Duration `mapstructure:"period"`
+	// How often the pruner runs (default: 1h)
+	Interval time.Duration `mapstructure:"interval"`
+	// Most rows deleted per statement (default: 1000)
+	BatchSize int `mapstructure:"batch_size"`
+	// Only log how many rows would be deleted (default: false)
+	DryRun bool `mapstructure:"dry_run"`
 }
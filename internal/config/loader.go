@@ -63,6 +63,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.read_timeout", "30s")
 	v.SetDefault("server.write_timeout", "30s")
 	v.SetDefault("server.shutdown_timeout", "10s")
+	v.SetDefault("server.drain_timeout", "15s")
 	v.SetDefault("server.environment", "development")
 
 	// Database defaults
@@ -75,6 +76,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_idle_conns", 5)
 	v.SetDefault("database.conn_max_lifetime", "5m")
 	v.SetDefault("database.conn_max_idle_time", "1m")
+	v.SetDefault("database.health_check_period", "1m")
+	v.SetDefault("database.ssl_mode", "disable")
+	v.SetDefault("database.connect_timeout", "0s")
+	v.SetDefault("database.statement_timeout", "0s")
 
 	// Redis defaults
 	v.SetDefault("redis.host", "localhost")
@@ -86,17 +91,132 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("auth.jwt_secret", "change-this-in-production")
 	v.SetDefault("auth.jwt_access_token_expiry", "15m")
 	v.SetDefault("auth.jwt_refresh_token_expiry", "168h")
+	v.SetDefault("auth.api_key_inactivity_timeout", "0s")
+	v.SetDefault("auth.session_store", "cookie")
+	v.SetDefault("auth.access_cache.enabled", false)
+	v.SetDefault("auth.access_cache.ttl", "30s")
+	v.SetDefault("auth.access_cache.max_entries", 10000)
+	v.SetDefault("auth.oauth.token_cache.enabled", true)
+	v.SetDefault("auth.oauth.token_cache.ttl", "5m")
+	v.SetDefault("auth.oauth.token_cache.max_entries", 10000)
 
 	// Secrets defaults
 	v.SetDefault("secrets.provider", "env")
 	v.SetDefault("secrets.aws.region", "us-east-1")
 	v.SetDefault("secrets.aws.secret_prefix", "waffles")
+	v.SetDefault("secrets.auth_refs.enabled", false)
+	v.SetDefault("secrets.auth_refs.env_prefix", "MCP_SECRET_")
+	v.SetDefault("secrets.auth_refs.cache_ttl", "1m")
+	v.SetDefault("secrets.auth_config_encryption.enabled", false)
+	v.SetDefault("secrets.auth_config_encryption.key_version", 1)
+	v.SetDefault("secrets.auth_config_encryption.key", "")
+	v.SetDefault("secrets.auth_config_encryption.previous_keys", map[string]string{})
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.access.sample_rate", 1.0)
+	v.SetDefault("logging.access.include_user_agent", true)
+	v.SetDefault("logging.access.include_user_id", true)
+	v.SetDefault("logging.access.include_latency_bucket", false)
 
 	// Metrics defaults
 	v.SetDefault("metrics.enabled", true)
 	v.SetDefault("metrics.prometheus_port", 9090)
+	v.SetDefault("metrics.cache_metrics", true)
+
+	// Tracing defaults
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.service_name", "waffles")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	// Gateway defaults
+	v.SetDefault("gateway.circuit_breaker.enabled", true)
+	v.SetDefault("gateway.circuit_breaker.failure_threshold", 5)
+	v.SetDefault("gateway.circuit_breaker.open_timeout", "30s")
+	v.SetDefault("gateway.circuit_breaker.reset_on_manual_health_check", true)
+	v.SetDefault("gateway.circuit_breaker.latency_budget", "0s")
+	v.SetDefault("gateway.circuit_breaker.slow_call_ratio", 0.5)
+	v.SetDefault("gateway.circuit_breaker.slow_call_window", 20)
+	v.SetDefault("gateway.reject_unknown_tools", false)
+	v.SetDefault("gateway.tools_cache_max_age", "5m")
+	v.SetDefault("gateway.tools_cache_max_entries", 1000)
+	v.SetDefault("gateway.enforce_max_connections", false)
+	v.SetDefault("gateway.warm_tools_on_list_changed", false)
+	v.SetDefault("gateway.aggregation_mode", "best_effort")
+	v.SetDefault("gateway.aggregation_concurrency", 8)
+	v.SetDefault("gateway.persist_sessions", false)
+	v.SetDefault("gateway.session_idle_ttl", "30m")
+	v.SetDefault("gateway.max_sessions", 1000)
+	v.SetDefault("gateway.max_response_bytes", 4<<20)
+	v.SetDefault("gateway.list_timeout", "0s")
+	v.SetDefault("gateway.max_batch_size", 100)
+	v.SetDefault("gateway.max_request_bytes", 1<<20)
+	v.SetDefault("gateway.replica_health_gating", true)
+	v.SetDefault("gateway.max_initializes_per_minute", 30)
+	v.SetDefault("gateway.max_event_subscribers", 100)
+	v.SetDefault("gateway.probe_transport", false)
+	v.SetDefault("gateway.timeout_hints.enabled", true)
+	v.SetDefault("gateway.timeout_hints.max", "5m")
+	v.SetDefault("gateway.loop_detection.enabled", true)
+	v.SetDefault("gateway.loop_detection.gateway_id", "")
+	v.SetDefault("gateway.loop_detection.self_urls", []string{})
+	v.SetDefault("gateway.retry.jitter", "equal")
+	v.SetDefault("gateway.retry.base_delay", "200ms")
+	v.SetDefault("gateway.retry.max_delay", "5s")
+	v.SetDefault("gateway.retry.max_retries", 2)
+	v.SetDefault("gateway.retry.initialized_retries", 2)
+	v.SetDefault("gateway.dead_letter.enabled", false)
+	v.SetDefault("gateway.dead_letter.max_entries", 1000)
+	v.SetDefault("gateway.debug_log.enabled", false)
+	v.SetDefault("gateway.debug_log.max_entries", 50)
+	v.SetDefault("gateway.debug_log.max_response_bytes", 16384)
+	v.SetDefault("gateway.debug_log.server_ids", []string{})
+	v.SetDefault("gateway.egress.proxy_url", "")
+	v.SetDefault("gateway.egress.allowed_hosts", []string{})
+	v.SetDefault("gateway.egress.block_private_addresses", false)
+	v.SetDefault("gateway.egress.allowed_cidrs", []string{})
+	v.SetDefault("gateway.legacy_sse.enabled", false)
+	v.SetDefault("gateway.legacy_sse.keepalive_interval", "30s")
+	v.SetDefault("gateway.legacy_sse.max_queued_events", 64)
+	v.SetDefault("gateway.websocket.ping_interval", "30s")
+	v.SetDefault("gateway.websocket.pong_timeout", "60s")
+	v.SetDefault("gateway.error_normalization.enabled", false)
+	v.SetDefault("gateway.error_normalization.include_detail", true)
+	v.SetDefault("gateway.priority.enabled", false)
+	v.SetDefault("gateway.priority.header", "X-MCP-Priority")
+	v.SetDefault("gateway.priority.meta_key", "")
+	v.SetDefault("gateway.priority.levels", []string{"low", "normal", "high"})
+	v.SetDefault("gateway.priority.default", "normal")
+	v.SetDefault("gateway.priority.roles", map[string]string{})
+	v.SetDefault("gateway.priority.server_ids", []string{})
+
+	// Health check scheduler defaults
+	v.SetDefault("health_check.enabled", true)
+	v.SetDefault("health_check.tick_interval", "10s")
+	v.SetDefault("health_check.workers", 4)
+	v.SetDefault("health_check.mode", "auto")
+	v.SetDefault("health_check.auto_tag", false)
+
+	// Registry defaults
+	v.SetDefault("registry.max_active_servers", 0)
+
+	// Audit defaults
+	v.SetDefault("audit.redact_keys", []string{"password", "secret", "token", "api_key", "apikey", "authorization"})
+	v.SetDefault("audit.max_argument_bytes", 2048)
+	v.SetDefault("audit.access_denied", true)
+	v.SetDefault("audit.retention.enabled", false)
+	v.SetDefault("audit.retention.period", "2160h")
+	v.SetDefault("audit.retention.interval", "1h")
+	v.SetDefault("audit.retention.batch_size", 1000)
+	v.SetDefault("audit.retention.dry_run", false)
+
+	// Rate limit defaults
+	v.SetDefault("rate_limit.enabled", false)
+	v.SetDefault("rate_limit.requests_per_second", 20)
+	v.SetDefault("rate_limit.burst", 40)
+	v.SetDefault("rate_limit.tool_call_requests_per_second", 5)
+	v.SetDefault("rate_limit.tool_call_burst", 10)
+	v.SetDefault("rate_limit.retry_after_jitter", "2s")
 }
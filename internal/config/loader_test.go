@@ -145,6 +145,219 @@ func TestLoad_Validation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid environment",
 		},
+		{
+			name: "invalid database ssl mode",
+			envVars: map[string]string{
+				"DATABASE_SSL_MODE": "always",
+			},
+			expectError: true,
+			errorMsg:    "invalid database ssl_mode",
+		},
+		{
+			name: "database min connections above max",
+			envVars: map[string]string{
+				"DATABASE_MAX_OPEN_CONNS": "4",
+				"DATABASE_MAX_IDLE_CONNS": "8",
+			},
+			expectError: true,
+			errorMsg:    "cannot exceed max_open_conns",
+		},
+		{
+			name: "negative database health check period",
+			envVars: map[string]string{
+				"DATABASE_HEALTH_CHECK_PERIOD": "-1s",
+			},
+			expectError: true,
+			errorMsg:    "health_check_period cannot be negative",
+		},
+		{
+			name: "negative server drain timeout",
+			envVars: map[string]string{
+				"SERVER_DRAIN_TIMEOUT": "-1s",
+			},
+			expectError: true,
+			errorMsg:    "drain_timeout cannot be negative",
+		},
+		{
+			name: "negative gateway list timeout",
+			envVars: map[string]string{
+				"GATEWAY_LIST_TIMEOUT": "-1s",
+			},
+			expectError: true,
+			errorMsg:    "list_timeout must not be negative",
+		},
+		{
+			name: "negative gateway max batch size",
+			envVars: map[string]string{
+				"GATEWAY_MAX_BATCH_SIZE": "-1",
+			},
+			expectError: true,
+			errorMsg:    "max_batch_size must not be negative",
+		},
+		{
+			name: "negative gateway max event subscribers",
+			envVars: map[string]string{
+				"GATEWAY_MAX_EVENT_SUBSCRIBERS": "-1",
+			},
+			expectError: true,
+			errorMsg:    "max_event_subscribers must not be negative",
+		},
+		{
+			name: "invalid gateway retry jitter",
+			envVars: map[string]string{
+				"GATEWAY_RETRY_JITTER": "random",
+			},
+			expectError: true,
+			errorMsg:    "invalid gateway retry jitter",
+		},
+		{
+			name: "gateway retry max delay below base delay",
+			envVars: map[string]string{
+				"GATEWAY_RETRY_BASE_DELAY": "1s",
+				"GATEWAY_RETRY_MAX_DELAY":  "500ms",
+			},
+			expectError: true,
+			errorMsg:    "max_delay at least base_delay",
+		},
+		{
+			name: "negative gateway max sessions",
+			envVars: map[string]string{
+				"GATEWAY_MAX_SESSIONS": "-1",
+			},
+			expectError: true,
+			errorMsg:    "max_sessions must not be negative",
+		},
+		{
+			name: "negative gateway initialized retries",
+			envVars: map[string]string{
+				"GATEWAY_RETRY_INITIALIZED_RETRIES": "-1",
+			},
+			expectError: true,
+			errorMsg:    "initialized_retries must not be negative",
+		},
+		{
+			name: "enabled dead letter log without entries",
+			envVars: map[string]string{
+				"GATEWAY_DEAD_LETTER_ENABLED":     "true",
+				"GATEWAY_DEAD_LETTER_MAX_ENTRIES": "0",
+			},
+			expectError: true,
+			errorMsg:    "dead_letter max_entries must be at least 1",
+		},
+		{
+			name: "gateway debug log without entries",
+			envVars: map[string]string{
+				"GATEWAY_DEBUG_LOG_ENABLED":     "true",
+				"GATEWAY_DEBUG_LOG_MAX_ENTRIES": "0",
+			},
+			expectError: true,
+			errorMsg:    "debug_log max_entries must be at least 1",
+		},
+		{
+			name: "gateway priority default not a level",
+			envVars: map[string]string{
+				"GATEWAY_PRIORITY_ENABLED": "true",
+				"GATEWAY_PRIORITY_DEFAULT": "urgent",
+			},
+			expectError: true,
+			errorMsg:    "priority default \"urgent\" must be one of the levels",
+		},
+		{
+			name: "gateway egress proxy without scheme",
+			envVars: map[string]string{
+				"GATEWAY_EGRESS_PROXY_URL": "proxy.corp:3128",
+			},
+			expectError: true,
+			errorMsg:    "egress proxy_url",
+		},
+		{
+			name: "gateway egress allowed cidr without mask",
+			envVars: map[string]string{
+				"GATEWAY_EGRESS_BLOCK_PRIVATE_ADDRESSES": "true",
+				"GATEWAY_EGRESS_ALLOWED_CIDRS":           "10.0.0.1",
+			},
+			expectError: true,
+			errorMsg:    "egress allowed_cidrs",
+		},
+		{
+			name: "gateway legacy sse without queue",
+			envVars: map[string]string{
+				"GATEWAY_LEGACY_SSE_ENABLED":           "true",
+				"GATEWAY_LEGACY_SSE_MAX_QUEUED_EVENTS": "0",
+			},
+			expectError: true,
+			errorMsg:    "legacy_sse max_queued_events",
+		},
+		{
+			name: "gateway websocket pong timeout within ping interval",
+			envVars: map[string]string{
+				"GATEWAY_WEBSOCKET_PING_INTERVAL": "30s",
+				"GATEWAY_WEBSOCKET_PONG_TIMEOUT":  "10s",
+			},
+			expectError: true,
+			errorMsg:    "websocket pong_timeout",
+		},
+		{
+			name: "tracing without endpoint",
+			envVars: map[string]string{
+				"TRACING_ENABLED": "true",
+			},
+			expectError: true,
+			errorMsg:    "tracing endpoint is required",
+		},
+		{
+			name: "tracing sample ratio out of range",
+			envVars: map[string]string{
+				"TRACING_ENABLED":      "true",
+				"TRACING_ENDPOINT":     "http://jaeger:4318",
+				"TRACING_SAMPLE_RATIO": "1.5",
+			},
+			expectError: true,
+			errorMsg:    "tracing sample_ratio",
+		},
+		{
+			name: "access cache without ttl",
+			envVars: map[string]string{
+				"AUTH_ACCESS_CACHE_ENABLED": "true",
+				"AUTH_ACCESS_CACHE_TTL":     "0s",
+			},
+			expectError: true,
+			errorMsg:    "access_cache ttl must be positive",
+		},
+		{
+			name: "auth config encryption without a key",
+			envVars: map[string]string{
+				"SECRETS_AUTH_CONFIG_ENCRYPTION_ENABLED": "true",
+			},
+			expectError: true,
+			errorMsg:    "auth_config_encryption key: must be 16, 24 or 32 bytes",
+		},
+		{
+			name: "auth config encryption with a short key",
+			envVars: map[string]string{
+				"SECRETS_AUTH_CONFIG_ENCRYPTION_ENABLED": "true",
+				"SECRETS_AUTH_CONFIG_ENCRYPTION_KEY":     "c2hvcnQ=",
+			},
+			expectError: true,
+			errorMsg:    "must be 16, 24 or 32 bytes, got 5",
+		},
+		{
+			name: "negative secret refs cache ttl",
+			envVars: map[string]string{
+				"SECRETS_AUTH_REFS_ENABLED":   "true",
+				"SECRETS_AUTH_REFS_CACHE_TTL": "-1m",
+			},
+			expectError: true,
+			errorMsg:    "cache_ttl must not be negative",
+		},
+		{
+			name: "access log sample rate out of range",
+			envVars: map[string]string{
+				"LOGGING_ACCESS_SAMPLE_RATE": "-0.5",
+			},
+			expectError: true,
+			errorMsg:    "invalid access log sample_rate",
+		},
 	}
 
 	for _, tt := range tests {
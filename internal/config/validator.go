@@ -1,7 +1,15 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/waffles/waffles/pkg/logger"
 )
 
 // Validate checks if the configuration is valid
@@ -15,6 +23,10 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("invalid environment: %s (must be development, staging, or production)", cfg.Server.Environment)
 	}
 
+	if cfg.Server.DrainTimeout < 0 {
+		return fmt.Errorf("server drain_timeout cannot be negative")
+	}
+
 	// Validate database config
 	if cfg.Database.Host == "" {
 		return fmt.Errorf("database host is required")
@@ -45,6 +57,32 @@ func Validate(cfg *Config) error {
 			cfg.Database.MaxIdleConns, cfg.Database.MaxOpenConns)
 	}
 
+	if cfg.Database.ConnMaxLifetime < 0 {
+		return fmt.Errorf("database conn_max_lifetime cannot be negative")
+	}
+
+	if cfg.Database.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("database conn_max_idle_time cannot be negative")
+	}
+
+	if cfg.Database.HealthCheckPeriod < 0 {
+		return fmt.Errorf("database health_check_period cannot be negative")
+	}
+
+	switch cfg.Database.SSLMode {
+	case "", "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("invalid database ssl_mode: %s (must be disable, allow, prefer, require, verify-ca or verify-full)", cfg.Database.SSLMode)
+	}
+
+	if cfg.Database.ConnectTimeout < 0 {
+		return fmt.Errorf("database connect_timeout cannot be negative")
+	}
+
+	if cfg.Database.StatementTimeout < 0 {
+		return fmt.Errorf("database statement_timeout cannot be negative")
+	}
+
 	// Validate Redis config
 	if cfg.Redis.Host == "" {
 		return fmt.Errorf("redis host is required")
@@ -71,11 +109,91 @@ func Validate(cfg *Config) error {
 		return fmt.Errorf("jwt_refresh_token_expiry must be positive")
 	}
 
+	if cfg.Auth.SessionStore != "cookie" && cfg.Auth.SessionStore != "memory" {
+		return fmt.Errorf("invalid session_store: %s (must be 'cookie' or 'memory')", cfg.Auth.SessionStore)
+	}
+
+	if cfg.Auth.APIKeyInactivityTimeout < 0 {
+		return fmt.Errorf("api_key_inactivity_timeout cannot be negative")
+	}
+
+	if cfg.Auth.AccessCache.Enabled {
+		if cfg.Auth.AccessCache.TTL <= 0 {
+			return fmt.Errorf("access_cache ttl must be positive")
+		}
+		if cfg.Auth.AccessCache.MaxEntries < 1 {
+			return fmt.Errorf("access_cache max_entries must be at least 1")
+		}
+	}
+	if cfg.Auth.OAuth.TokenCache.Enabled {
+		if cfg.Auth.OAuth.TokenCache.TTL <= 0 {
+			return fmt.Errorf("oauth token_cache ttl must be positive")
+		}
+		if cfg.Auth.OAuth.TokenCache.MaxEntries < 1 {
+			return fmt.Errorf("oauth token_cache max_entries must be at least 1")
+		}
+	}
+
+	providerNames := map[string]bool{"oidc": true}
+	for i, provider := range cfg.Auth.OAuth.Providers {
+		if provider.Name == "" {
+			return fmt.Errorf("oauth providers[%d] name is required", i)
+		}
+		if providerNames[provider.Name] {
+			return fmt.Errorf("oauth provider name %s is reserved or duplicated", provider.Name)
+		}
+		providerNames[provider.Name] = true
+		if provider.Issuer == "" {
+			return fmt.Errorf("oauth provider %s issuer is required", provider.Name)
+		}
+	}
+
+	if cfg.Audit.MaxArgumentBytes < 0 {
+		return fmt.Errorf("audit max_argument_bytes cannot be negative")
+	}
+	if cfg.Audit.Retention.Enabled {
+		if cfg.Audit.Retention.Period <= 0 {
+			return fmt.Errorf("audit retention period must be positive")
+		}
+		if cfg.Audit.Retention.Interval <= 0 {
+			return fmt.Errorf("audit retention interval must be positive")
+		}
+		if cfg.Audit.Retention.BatchSize < 1 {
+			return fmt.Errorf("audit retention batch_size must be at least 1")
+		}
+	}
+
 	// Validate secrets config
 	if cfg.Secrets.Provider != "env" && cfg.Secrets.Provider != "aws" {
 		return fmt.Errorf("invalid secrets provider: %s (must be 'env' or 'aws')", cfg.Secrets.Provider)
 	}
 
+	if cfg.Secrets.AuthRefs.Enabled {
+		if cfg.Secrets.AuthRefs.EnvPrefix == "" {
+			return fmt.Errorf("secrets auth_refs env_prefix is required so gateway settings can't be referenced")
+		}
+		if cfg.Secrets.AuthRefs.CacheTTL < 0 {
+			return fmt.Errorf("secrets auth_refs cache_ttl must not be negative")
+		}
+	}
+
+	if enc := cfg.Secrets.AuthConfigEncryption; enc.Enabled {
+		if enc.KeyVersion < 1 || enc.KeyVersion > 255 {
+			return fmt.Errorf("secrets auth_config_encryption key_version must be between 1 and 255")
+		}
+		if err := validateEncryptionKey(enc.Key); err != nil {
+			return fmt.Errorf("secrets auth_config_encryption key: %w", err)
+		}
+		for version, key := range enc.PreviousKeys {
+			if n, err := strconv.Atoi(version); err != nil || n < 1 || n > 255 {
+				return fmt.Errorf("secrets auth_config_encryption previous_keys version %q must be between 1 and 255", version)
+			}
+			if err := validateEncryptionKey(key); err != nil {
+				return fmt.Errorf("secrets auth_config_encryption previous_keys version %s: %w", version, err)
+			}
+		}
+	}
+
 	if cfg.Secrets.Provider == "aws" {
 		if cfg.Secrets.AWS.Region == "" {
 			return fmt.Errorf("aws region is required when using aws secrets provider")
@@ -90,16 +208,223 @@ func Validate(cfg *Config) error {
 	if !validLogLevels[cfg.Logging.Level] {
 		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", cfg.Logging.Level)
 	}
+	for subsystem, level := range cfg.Logging.Subsystems {
+		if subsystem != logger.SubsystemGateway && subsystem != logger.SubsystemAPI {
+			return fmt.Errorf("invalid logging subsystem: %s (must be gateway or api)", subsystem)
+		}
+		if !validLogLevels[level] {
+			return fmt.Errorf("invalid log level for subsystem %s: %s (must be debug, info, warn, or error)", subsystem, level)
+		}
+	}
 
 	validLogFormats := map[string]bool{"json": true, "console": true}
 	if !validLogFormats[cfg.Logging.Format] {
 		return fmt.Errorf("invalid log format: %s (must be json or console)", cfg.Logging.Format)
 	}
+	if cfg.Logging.Access.SampleRate < 0 || cfg.Logging.Access.SampleRate > 1 {
+		return fmt.Errorf("invalid access log sample_rate: %v (must be between 0 and 1)", cfg.Logging.Access.SampleRate)
+	}
 
 	// Validate metrics config
 	if cfg.Metrics.Enabled && (cfg.Metrics.PrometheusPort < 1 || cfg.Metrics.PrometheusPort > 65535) {
 		return fmt.Errorf("invalid prometheus port: %d", cfg.Metrics.PrometheusPort)
 	}
 
+	// Validate tracing config
+	if cfg.Tracing.Enabled {
+		if cfg.Tracing.Endpoint == "" {
+			return fmt.Errorf("tracing endpoint is required when tracing is enabled")
+		}
+		if u, err := url.Parse(cfg.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing endpoint must be an http(s) URL")
+		}
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing sample_ratio must be between 0 and 1")
+		}
+	}
+
+	// Validate gateway config
+	if cfg.Gateway.CircuitBreaker.Enabled {
+		if cfg.Gateway.CircuitBreaker.FailureThreshold < 1 {
+			return fmt.Errorf("gateway circuit_breaker failure_threshold must be at least 1")
+		}
+		if cfg.Gateway.CircuitBreaker.OpenTimeout <= 0 {
+			return fmt.Errorf("gateway circuit_breaker open_timeout must be positive")
+		}
+		if cfg.Gateway.CircuitBreaker.LatencyBudget < 0 {
+			return fmt.Errorf("gateway circuit_breaker latency_budget must not be negative")
+		}
+		if cfg.Gateway.CircuitBreaker.SlowCallRatio <= 0 || cfg.Gateway.CircuitBreaker.SlowCallRatio > 1 {
+			return fmt.Errorf("gateway circuit_breaker slow_call_ratio must be greater than 0 and at most 1")
+		}
+		if cfg.Gateway.CircuitBreaker.SlowCallWindow < 1 {
+			return fmt.Errorf("gateway circuit_breaker slow_call_window must be at least 1")
+		}
+	}
+	if cfg.Gateway.ToolsCacheMaxAge < 0 {
+		return fmt.Errorf("gateway tools_cache_max_age must not be negative")
+	}
+	if cfg.Gateway.ToolsCacheMaxEntries < 0 {
+		return fmt.Errorf("gateway tools_cache_max_entries must not be negative")
+	}
+	if cfg.Gateway.AggregationMode != AggregationModeBestEffort && cfg.Gateway.AggregationMode != AggregationModeFailFast {
+		return fmt.Errorf("invalid gateway aggregation_mode: %s (must be best_effort or fail_fast)", cfg.Gateway.AggregationMode)
+	}
+	if cfg.Gateway.AggregationConcurrency < 1 {
+		return fmt.Errorf("gateway aggregation_concurrency must be at least 1")
+	}
+	if cfg.Gateway.MaxResponseBytes < 0 || cfg.Gateway.MaxRequestBytes < 0 {
+		return fmt.Errorf("gateway max_response_bytes and max_request_bytes must not be negative")
+	}
+
+	if cfg.Gateway.ListTimeout < 0 {
+		return fmt.Errorf("gateway list_timeout must not be negative")
+	}
+	if cfg.Gateway.MaxBatchSize < 0 {
+		return fmt.Errorf("gateway max_batch_size must not be negative")
+	}
+	if cfg.Gateway.MaxInitializesPerMinute < 0 {
+		return fmt.Errorf("gateway max_initializes_per_minute must not be negative")
+	}
+	if cfg.Gateway.SessionIdleTTL < 0 {
+		return fmt.Errorf("gateway session_idle_ttl must not be negative")
+	}
+	if cfg.Gateway.MaxSessions < 0 {
+		return fmt.Errorf("gateway max_sessions must not be negative")
+	}
+	if cfg.Gateway.MaxEventSubscribers < 0 {
+		return fmt.Errorf("gateway max_event_subscribers must not be negative")
+	}
+	if cfg.Gateway.TimeoutHints.Enabled {
+		if cfg.Gateway.TimeoutHints.Max <= 0 {
+			return fmt.Errorf("gateway timeout_hints max must be positive")
+		}
+		for role, max := range cfg.Gateway.TimeoutHints.RoleMax {
+			if max <= 0 {
+				return fmt.Errorf("gateway timeout_hints role_max for %s must be positive", role)
+			}
+		}
+	}
+	if strings.ContainsAny(cfg.Gateway.LoopDetection.GatewayID, ", ") {
+		return fmt.Errorf("gateway loop_detection gateway_id must not contain commas or spaces")
+	}
+	for _, selfURL := range cfg.Gateway.LoopDetection.SelfURLs {
+		if u, err := url.Parse(selfURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return fmt.Errorf("gateway loop_detection self_urls entry %q must be an http(s) URL", selfURL)
+		}
+	}
+	switch cfg.Gateway.Retry.Jitter {
+	case JitterNone, JitterFull, JitterEqual, JitterDecorrelated:
+	default:
+		return fmt.Errorf("invalid gateway retry jitter: %s (must be none, full, equal or decorrelated)", cfg.Gateway.Retry.Jitter)
+	}
+	if cfg.Gateway.Retry.BaseDelay <= 0 || cfg.Gateway.Retry.MaxDelay < cfg.Gateway.Retry.BaseDelay {
+		return fmt.Errorf("gateway retry base_delay must be positive and max_delay at least base_delay")
+	}
+	if cfg.Gateway.Retry.MaxRetries < 0 {
+		return fmt.Errorf("gateway retry max_retries must not be negative")
+	}
+	if cfg.Gateway.Retry.InitializedRetries < 0 {
+		return fmt.Errorf("gateway retry initialized_retries must not be negative")
+	}
+	if cfg.Gateway.DeadLetter.Enabled && cfg.Gateway.DeadLetter.MaxEntries < 1 {
+		return fmt.Errorf("gateway dead_letter max_entries must be at least 1")
+	}
+	if cfg.Gateway.DebugLog.Enabled {
+		if cfg.Gateway.DebugLog.MaxEntries < 1 {
+			return fmt.Errorf("gateway debug_log max_entries must be at least 1")
+		}
+		if cfg.Gateway.DebugLog.MaxResponseBytes < 0 {
+			return fmt.Errorf("gateway debug_log max_response_bytes must not be negative")
+		}
+	}
+	if priority := cfg.Gateway.Priority; priority.Enabled {
+		if priority.Header == "" && priority.MetaKey == "" {
+			return fmt.Errorf("gateway priority needs a header or meta_key to forward the hint in")
+		}
+		if !slices.Contains(priority.Levels, priority.Default) {
+			return fmt.Errorf("gateway priority default %q must be one of the levels %v", priority.Default, priority.Levels)
+		}
+		for role, level := range priority.Roles {
+			if !slices.Contains(priority.Levels, level) {
+				return fmt.Errorf("gateway priority for role %s %q must be one of the levels %v", role, level, priority.Levels)
+			}
+		}
+	}
+	if proxyURL := cfg.Gateway.Egress.ProxyURL; proxyURL != "" {
+		if u, err := url.Parse(proxyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return fmt.Errorf("gateway egress proxy_url %q must be an http(s) URL", proxyURL)
+		}
+	}
+	for _, host := range cfg.Gateway.Egress.AllowedHosts {
+		if host == "" || strings.ContainsAny(host, "/: ") {
+			return fmt.Errorf("gateway egress allowed_hosts entry %q must be a host name", host)
+		}
+	}
+	for _, cidr := range cfg.Gateway.Egress.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("gateway egress allowed_cidrs entry %q must be a CIDR range", cidr)
+		}
+	}
+	if cfg.Gateway.LegacySSE.Enabled {
+		if cfg.Gateway.LegacySSE.KeepaliveInterval < 0 {
+			return fmt.Errorf("gateway legacy_sse keepalive_interval must not be negative")
+		}
+		if cfg.Gateway.LegacySSE.MaxQueuedEvents < 1 {
+			return fmt.Errorf("gateway legacy_sse max_queued_events must be at least 1")
+		}
+	}
+	if cfg.Gateway.WebSocket.PingInterval < 0 || cfg.Gateway.WebSocket.PongTimeout < 0 {
+		return fmt.Errorf("gateway websocket ping_interval and pong_timeout must not be negative")
+	}
+	if timeout := cfg.Gateway.WebSocket.PongTimeout; timeout > 0 && timeout <= cfg.Gateway.WebSocket.PingInterval {
+		return fmt.Errorf("gateway websocket pong_timeout must be longer than ping_interval")
+	}
+
+	// Validate health check scheduler config
+	if cfg.HealthCheck.Enabled {
+		if cfg.HealthCheck.TickInterval <= 0 {
+			return fmt.Errorf("health_check tick_interval must be positive")
+		}
+		if cfg.HealthCheck.Workers < 1 {
+			return fmt.Errorf("health_check workers must be at least 1")
+		}
+	}
+	if cfg.HealthCheck.Mode != HealthCheckModeAuto && cfg.HealthCheck.Mode != HealthCheckModeHTTP {
+		return fmt.Errorf("invalid health_check mode: %s (must be auto or http)", cfg.HealthCheck.Mode)
+	}
+
+	// Validate registry config
+	if cfg.Registry.MaxActiveServers < 0 {
+		return fmt.Errorf("registry max_active_servers must not be negative")
+	}
+
+	// Validate rate limit config
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.RequestsPerSecond <= 0 || cfg.RateLimit.ToolCallRequestsPerSecond <= 0 {
+			return fmt.Errorf("rate_limit requests_per_second and tool_call_requests_per_second must be positive")
+		}
+		if cfg.RateLimit.Burst < 1 || cfg.RateLimit.ToolCallBurst < 1 {
+			return fmt.Errorf("rate_limit burst and tool_call_burst must be at least 1")
+		}
+		if cfg.RateLimit.RetryAfterJitter < 0 {
+			return fmt.Errorf("rate_limit retry_after_jitter must not be negative")
+		}
+	}
+
 	return nil
 }
+
+// validateEncryptionKey checks that key is a base64-encoded AES key
+func validateEncryptionKey(key string) error {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("must be base64: %w", err)
+	}
+	switch len(decoded) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("must be 16, 24 or 32 bytes, got %d", len(decoded))
+	}
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/waffles/waffles/internal/config"
+)
+
+// BuildDSN returns the postgres:// connection URL for cfg, used both for the connection
+// pool and for migrations. Credentials are escaped, so they may contain any character.
+func BuildDSN(cfg config.DatabaseConfig) string {
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", sslMode)
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	if cfg.ConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(timeoutSeconds(cfg.ConnectTimeout)))
+	}
+	if cfg.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Database,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// timeoutSeconds rounds a timeout up to whole seconds, the unit connect_timeout takes
+func timeoutSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
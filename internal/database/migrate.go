@@ -4,6 +4,9 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"sync"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -99,6 +102,57 @@ func MigrateTo(databaseURL string, version uint, log logger.Logger) error {
 	return nil
 }
 
+// MigrateForce marks the database as migrated to version and clears its dirty flag,
+// without running any migration. It recovers from a migration that failed part way once
+// the schema has been fixed by hand to match version.
+func MigrateForce(databaseURL string, version uint, log logger.Logger) error {
+	m, err := newMigrate(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return forceVersion(m, version, log)
+}
+
+// forceVersion sets m's schema version, refusing versions with no embedded migration
+func forceVersion(m *migrate.Migrate, version uint, log logger.Logger) error {
+	if err := checkMigrationExists(version); err != nil {
+		return err
+	}
+
+	previous, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	if err := m.Force(int(version)); err != nil { // #nosec G115 -- checked against the embedded migrations
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+
+	log.Warn().
+		Uint("previous_version", previous).
+		Bool("was_dirty", dirty).
+		Uint("version", version).
+		Msg("Forced migration version; no migrations were run")
+	return nil
+}
+
+// checkMigrationExists returns an error if no embedded migration has version
+func checkMigrationExists(version uint) error {
+	sourceDriver, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to create migration source: %w", err)
+	}
+	defer sourceDriver.Close()
+
+	r, _, err := sourceDriver.ReadUp(version)
+	if err != nil {
+		return fmt.Errorf("no migration with version %d", version)
+	}
+	return r.Close()
+}
+
 // MigrateStatus returns the current migration status
 func MigrateStatus(databaseURL string) (version uint, dirty bool, err error) {
 	m, err := newMigrate(databaseURL)
@@ -115,6 +169,90 @@ func MigrateStatus(databaseURL string) (version uint, dirty bool, err error) {
 	return version, dirty, err
 }
 
+// LatestMigrationVersion returns the version of the newest embedded migration
+func LatestMigrationVersion() (uint, error) {
+	sourceDriver, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migration source: %w", err)
+	}
+	defer sourceDriver.Close()
+
+	version, err := sourceDriver.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		next, err := sourceDriver.Next(version)
+		if err != nil {
+			// Next fails with an fs.ErrNotExist error after the last migration
+			return version, nil
+		}
+		version = next
+	}
+}
+
+// ErrMigrationInProgress is returned when a migration is started while another is running
+var ErrMigrationInProgress = errors.New("a migration is already in progress")
+
+// Migrator runs the embedded migrations against one database for the running server.
+// Errors never contain the database password.
+type Migrator struct {
+	databaseURL string
+	logger      logger.Logger
+	running     sync.Mutex
+}
+
+// NewMigrator creates a migrator for the database at databaseURL
+func NewMigrator(databaseURL string, log logger.Logger) *Migrator {
+	return &Migrator{databaseURL: databaseURL, logger: log}
+}
+
+// Status returns the applied migration version and whether it is dirty
+func (m *Migrator) Status() (version uint, dirty bool, err error) {
+	version, dirty, err = MigrateStatus(m.databaseURL)
+	return version, dirty, m.redact(err)
+}
+
+// Up applies all pending migrations, returning ErrMigrationInProgress if a migration
+// started by this migrator is still running
+func (m *Migrator) Up() error {
+	if !m.running.TryLock() {
+		return ErrMigrationInProgress
+	}
+	defer m.running.Unlock()
+
+	return m.redact(MigrateUp(m.databaseURL, m.logger))
+}
+
+// redact removes the database URL's password from err's message
+func (m *Migrator) redact(err error) error {
+	if err == nil {
+		return nil
+	}
+	u, parseErr := url.Parse(m.databaseURL)
+	if parseErr != nil {
+		return &redactedError{msg: "migration failed", err: err}
+	}
+	password, ok := u.User.Password()
+	if !ok || password == "" {
+		return err
+	}
+
+	msg := strings.ReplaceAll(err.Error(), m.databaseURL, u.Redacted())
+	msg = strings.ReplaceAll(msg, url.QueryEscape(password), "xxxxx")
+	msg = strings.ReplaceAll(msg, password, "xxxxx")
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError replaces an error's message while keeping it available to errors.Is
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
 // newMigrate creates a new migrate instance
 func newMigrate(databaseURL string) (*migrate.Migrate, error) {
 	// Create iofs driver from embedded filesystem
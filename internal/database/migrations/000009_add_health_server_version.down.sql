@@ -0,0 +1,2 @@
+-- Remove server_version column
+ALTER TABLE server_health DROP COLUMN IF EXISTS server_version;
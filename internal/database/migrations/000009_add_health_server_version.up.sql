@@ -0,0 +1,4 @@
+-- Add server_version column to server_health table
+-- Stores serverInfo.version reported by the backend during MCP initialize health checks
+-- Empty when the check was a plain HTTP probe or the server did not report a version
+ALTER TABLE server_health ADD COLUMN server_version VARCHAR(255) NOT NULL DEFAULT '';
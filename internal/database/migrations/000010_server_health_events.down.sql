@@ -0,0 +1,2 @@
+-- Remove server health transition events
+DROP TABLE IF EXISTS server_health_events;
@@ -0,0 +1,12 @@
+-- Server health transition events
+-- One row each time a server's health status changes (e.g. healthy -> unhealthy)
+CREATE TABLE server_health_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    previous_status VARCHAR(50) NOT NULL,
+    new_status VARCHAR(50) NOT NULL,
+    error_message TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_health_events_server_occurred ON server_health_events(server_id, occurred_at DESC);
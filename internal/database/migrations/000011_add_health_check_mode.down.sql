@@ -0,0 +1,2 @@
+-- Remove check_mode column
+ALTER TABLE server_health DROP COLUMN IF EXISTS check_mode;
@@ -0,0 +1,3 @@
+-- Add check_mode column to server_health table
+-- Records whether the result came from an HTTP GET ('http') or an MCP handshake ('mcp')
+ALTER TABLE server_health ADD COLUMN check_mode VARCHAR(20) NOT NULL DEFAULT 'http';
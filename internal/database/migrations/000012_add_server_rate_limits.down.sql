@@ -0,0 +1,3 @@
+-- Remove per-server request limits
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS max_tool_requests_per_minute;
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS max_requests_per_minute;
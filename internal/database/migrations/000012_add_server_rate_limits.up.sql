@@ -0,0 +1,4 @@
+-- Per-server request limits enforced by the gateway over a sliding one-minute window
+-- 0 means unlimited (default behavior)
+ALTER TABLE mcp_servers ADD COLUMN max_requests_per_minute INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE mcp_servers ADD COLUMN max_tool_requests_per_minute INTEGER NOT NULL DEFAULT 0;
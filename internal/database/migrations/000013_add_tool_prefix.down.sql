@@ -0,0 +1,2 @@
+-- Remove tool_prefix column
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS tool_prefix;
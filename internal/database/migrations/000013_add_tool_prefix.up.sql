@@ -0,0 +1,4 @@
+-- Add tool_prefix column to mcp_servers table
+-- Tools are exposed to clients as '<prefix>__<tool>' to avoid collisions across servers
+-- Empty means tool names are passed through unchanged (default behavior)
+ALTER TABLE mcp_servers ADD COLUMN tool_prefix VARCHAR(32) NOT NULL DEFAULT '';
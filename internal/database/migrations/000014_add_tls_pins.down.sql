@@ -0,0 +1,2 @@
+-- Remove tls_pins column
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS tls_pins;
@@ -0,0 +1,4 @@
+-- Add tls_pins column to mcp_servers table
+-- Each pin is 'sha256/<base64>' of a certificate's SubjectPublicKeyInfo
+-- NULL or empty means no pinning (default behavior)
+ALTER TABLE mcp_servers ADD COLUMN tls_pins TEXT[];
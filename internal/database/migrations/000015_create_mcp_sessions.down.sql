@@ -0,0 +1,2 @@
+-- Remove persisted gateway MCP sessions
+DROP TABLE IF EXISTS mcp_sessions;
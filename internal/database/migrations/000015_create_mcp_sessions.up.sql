@@ -0,0 +1,9 @@
+-- Gateway MCP sessions with upstream servers
+-- One row per server so a restarted gateway can resume its session instead of re-initializing
+CREATE TABLE mcp_sessions (
+    server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL DEFAULT '',
+    protocol_version VARCHAR(20) NOT NULL DEFAULT '',
+    last_event_id TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Remove max_response_bytes column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS max_response_bytes;
@@ -0,0 +1,4 @@
+-- Add max_response_bytes column to mcp_servers table
+-- Largest upstream response body the gateway reads for the server
+-- 0 means the gateway-wide gateway.max_response_bytes applies (default behavior)
+ALTER TABLE mcp_servers ADD COLUMN max_response_bytes BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Remove replica_group column from mcp_servers table
+DROP INDEX IF EXISTS idx_servers_replica_group;
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS replica_group;
@@ -0,0 +1,6 @@
+-- Add replica_group column to mcp_servers table
+-- Active servers sharing a non-empty replica group are interchangeable replicas; the
+-- gateway load balances calls to any of them across the group
+ALTER TABLE mcp_servers ADD COLUMN replica_group VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_servers_replica_group ON mcp_servers(replica_group) WHERE replica_group <> '';
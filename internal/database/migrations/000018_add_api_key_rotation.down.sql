@@ -0,0 +1,4 @@
+-- Remove API key rotation columns from api_keys table
+DROP INDEX IF EXISTS idx_api_keys_previous_hash;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS previous_key_expires_at;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS previous_key_hash;
@@ -0,0 +1,7 @@
+-- Track the hash an API key had before its last rotation
+-- previous_key_hash keeps authenticating until previous_key_expires_at, giving clients a
+-- grace period to switch to the new key; both are NULL when no grace period is active
+ALTER TABLE api_keys ADD COLUMN previous_key_hash VARCHAR(255);
+ALTER TABLE api_keys ADD COLUMN previous_key_expires_at TIMESTAMP;
+
+CREATE INDEX idx_api_keys_previous_hash ON api_keys(previous_key_hash) WHERE previous_key_hash IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Remove forward_headers column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS forward_headers;
@@ -0,0 +1,4 @@
+-- Add forward_headers column to mcp_servers table
+-- When set, only these client request headers (plus those MCP requires) are proxied to
+-- the server
+ALTER TABLE mcp_servers ADD COLUMN forward_headers TEXT[];
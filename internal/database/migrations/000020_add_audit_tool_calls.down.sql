@@ -0,0 +1,6 @@
+-- Remove MCP tool call context from audit_logs
+DROP INDEX IF EXISTS idx_audit_tool_name;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tool_error_code;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tool_is_error;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tool_arguments;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tool_name;
@@ -0,0 +1,8 @@
+-- Add MCP tool call context to audit_logs
+-- tool_arguments holds a redacted, truncated summary of the call's arguments
+ALTER TABLE audit_logs ADD COLUMN tool_name VARCHAR(255);
+ALTER TABLE audit_logs ADD COLUMN tool_arguments JSONB;
+ALTER TABLE audit_logs ADD COLUMN tool_is_error BOOLEAN;
+ALTER TABLE audit_logs ADD COLUMN tool_error_code INT;
+
+CREATE INDEX idx_audit_tool_name ON audit_logs(tool_name) WHERE tool_name IS NOT NULL;
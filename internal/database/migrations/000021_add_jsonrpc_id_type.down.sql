@@ -0,0 +1,2 @@
+-- Remove jsonrpc_id_type column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS jsonrpc_id_type;
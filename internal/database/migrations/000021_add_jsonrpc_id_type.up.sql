@@ -0,0 +1,4 @@
+-- Add jsonrpc_id_type column to mcp_servers table
+-- Selects whether the ids of JSON-RPC requests the gateway itself sends to the server
+-- (initialize, tools/list, health checks) are numbers or strings
+ALTER TABLE mcp_servers ADD COLUMN jsonrpc_id_type VARCHAR(16) NOT NULL DEFAULT 'number';
@@ -0,0 +1,5 @@
+-- Remove access denial context from audit_logs
+DROP INDEX IF EXISTS idx_audit_access_denied;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS denied_resource;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS deny_reason;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS access_denied;
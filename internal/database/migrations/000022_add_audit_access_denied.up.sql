@@ -0,0 +1,7 @@
+-- Record access denials in audit_logs so they can be reviewed separately
+-- deny_reason is a stable code (e.g. insufficient_scope), denied_resource what was requested
+ALTER TABLE audit_logs ADD COLUMN access_denied BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE audit_logs ADD COLUMN deny_reason VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN denied_resource TEXT;
+
+CREATE INDEX idx_audit_access_denied ON audit_logs(created_at DESC) WHERE access_denied;
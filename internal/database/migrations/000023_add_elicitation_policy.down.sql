@@ -0,0 +1,2 @@
+-- Remove elicitation_policy column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS elicitation_policy;
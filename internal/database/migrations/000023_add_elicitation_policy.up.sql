@@ -0,0 +1,4 @@
+-- Add elicitation_policy column to mcp_servers table
+-- deny (default) keeps servers from prompting clients for input through the gateway;
+-- allow relays elicitation/create requests to subscribed clients
+ALTER TABLE mcp_servers ADD COLUMN elicitation_policy VARCHAR(16) NOT NULL DEFAULT 'deny';
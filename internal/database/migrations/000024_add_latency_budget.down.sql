@@ -0,0 +1,2 @@
+-- Remove latency_budget_ms column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS latency_budget_ms;
@@ -0,0 +1,4 @@
+-- Add latency_budget_ms column to mcp_servers table
+-- Successful calls slower than the budget count toward opening the server's circuit breaker
+-- (0 = the gateway-wide circuit_breaker.latency_budget)
+ALTER TABLE mcp_servers ADD COLUMN latency_budget_ms INTEGER NOT NULL DEFAULT 0;
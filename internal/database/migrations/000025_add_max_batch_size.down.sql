@@ -0,0 +1,2 @@
+-- Remove max_batch_size column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS max_batch_size;
@@ -0,0 +1,4 @@
+-- Add max_batch_size column to mcp_servers table
+-- Largest JSON-RPC batch the gateway forwards to the server
+-- (0 = the gateway-wide gateway.max_batch_size)
+ALTER TABLE mcp_servers ADD COLUMN max_batch_size INTEGER NOT NULL DEFAULT 0;
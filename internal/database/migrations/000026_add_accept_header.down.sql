@@ -0,0 +1,2 @@
+-- Remove accept_header column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS accept_header;
@@ -0,0 +1,4 @@
+-- Add accept_header column to mcp_servers table
+-- Accept header sent with the gateway's POSTs to the server
+-- (empty = "application/json, text/event-stream")
+ALTER TABLE mcp_servers ADD COLUMN accept_header VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Remove require_initialize column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS require_initialize;
@@ -0,0 +1,3 @@
+-- Add require_initialize column to mcp_servers table
+-- Whether the gateway initializes a session before any other request to the server
+ALTER TABLE mcp_servers ADD COLUMN require_initialize BOOLEAN NOT NULL DEFAULT false;
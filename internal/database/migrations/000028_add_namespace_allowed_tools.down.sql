@@ -0,0 +1,2 @@
+-- Remove allowed_tools column from namespaces table
+ALTER TABLE namespaces DROP COLUMN IF EXISTS allowed_tools;
@@ -0,0 +1,3 @@
+-- Add allowed_tools column to namespaces table
+-- Tool names every server in the namespace is limited to (empty = all)
+ALTER TABLE namespaces ADD COLUMN allowed_tools TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Remove system_tags column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS system_tags;
@@ -0,0 +1,3 @@
+-- Add system_tags column to mcp_servers table
+-- Tags derived from the capabilities a server advertises, kept apart from user tags
+ALTER TABLE mcp_servers ADD COLUMN system_tags TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Remove the auth_config storage comment
+COMMENT ON COLUMN mcp_servers.auth_config IS NULL;
@@ -0,0 +1,5 @@
+-- Document how auth_config is stored when secrets.auth_config_encryption is enabled.
+-- Encrypted values are JSON strings "enc:<base64>" holding the key version byte, nonce and
+-- AES-GCM ciphertext, so the column type is unchanged. Existing plaintext rows stay
+-- readable and are encrypted the next time the server is updated.
+COMMENT ON COLUMN mcp_servers.auth_config IS 'Server credentials; "enc:<base64>" strings are AES-GCM encrypted (secrets.auth_config_encryption)';
@@ -0,0 +1,2 @@
+-- Remove quirks column from mcp_servers table
+ALTER TABLE mcp_servers DROP COLUMN IF EXISTS quirks;
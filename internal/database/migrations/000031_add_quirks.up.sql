@@ -0,0 +1,4 @@
+-- Add quirks column to mcp_servers table
+-- Workarounds for a backend that deviates from the MCP spec (session header case,
+-- trailing slash, response parsing)
+ALTER TABLE mcp_servers ADD COLUMN quirks JSONB NOT NULL DEFAULT '{}';
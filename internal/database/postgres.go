@@ -20,41 +20,10 @@ type DB struct {
 
 // NewPostgresDB creates a new PostgreSQL connection pool
 func NewPostgresDB(cfg config.DatabaseConfig, log logger.Logger) (*DB, error) {
-	// Build connection string
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host,
-		cfg.Port,
-		cfg.User,
-		cfg.Password,
-		cfg.Database,
-	)
-
-	// Parse config
-	poolConfig, err := pgxpool.ParseConfig(connStr)
+	poolConfig, err := newPoolConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse database config: %w", err)
-	}
-
-	// Set connection pool settings with bounds checking to avoid integer overflow
-	maxConns := cfg.MaxOpenConns
-	if maxConns < 0 {
-		maxConns = 0
-	} else if maxConns > math.MaxInt32 {
-		maxConns = math.MaxInt32
+		return nil, err
 	}
-	poolConfig.MaxConns = int32(maxConns) // #nosec G115 -- bounds checked above
-
-	minConns := cfg.MaxIdleConns
-	if minConns < 0 {
-		minConns = 0
-	} else if minConns > math.MaxInt32 {
-		minConns = math.MaxInt32
-	}
-	poolConfig.MinConns = int32(minConns) // #nosec G115 -- bounds checked above
-
-	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
-	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
 
 	// Create connection pool
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -76,6 +45,7 @@ func NewPostgresDB(cfg config.DatabaseConfig, log logger.Logger) (*DB, error) {
 		Int("port", cfg.Port).
 		Str("database", cfg.Database).
 		Int("max_conns", cfg.MaxOpenConns).
+		Int("min_conns", cfg.MaxIdleConns).
 		Msg("Database connection pool established")
 
 	return &DB{
@@ -84,6 +54,38 @@ func NewPostgresDB(cfg config.DatabaseConfig, log logger.Logger) (*DB, error) {
 	}, nil
 }
 
+// newPoolConfig builds the pgxpool configuration for cfg. MaxOpenConns and MaxIdleConns
+// map to the pool's MaxConns and MinConns; pgxpool keeps MinConns connections open even
+// when they're idle.
+func newPoolConfig(cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(BuildDSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+
+	// Set connection pool settings with bounds checking to avoid integer overflow
+	poolConfig.MaxConns = clampInt32(cfg.MaxOpenConns)
+	poolConfig.MinConns = clampInt32(cfg.MaxIdleConns)
+
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	return poolConfig, nil
+}
+
+// clampInt32 limits n to the range of a non-negative int32
+func clampInt32(n int) int32 {
+	if n < 0 {
+		return 0
+	} else if n > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(n) // #nosec G115 -- bounds checked above
+}
+
 // Close closes the database connection pool
 func (db *DB) Close() {
 	if db.Pool != nil {
@@ -97,6 +99,11 @@ func (db *DB) Ping(ctx context.Context) error {
 	return db.Pool.Ping(ctx)
 }
 
+// PoolConfig returns a copy of the connection pool's configuration
+func (db *DB) PoolConfig() *pgxpool.Config {
+	return db.Pool.Config()
+}
+
 // Stats returns connection pool statistics
 func (db *DB) Stats() *pgxpool.Stat {
 	return db.Pool.Stat()
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPoolConfig(t *testing.T) {
+	cfg := testDatabaseConfig()
+	cfg.MaxOpenConns = 40
+	cfg.MaxIdleConns = 8
+	cfg.ConnMaxLifetime = 30 * time.Minute
+	cfg.ConnMaxIdleTime = 2 * time.Minute
+	cfg.HealthCheckPeriod = 15 * time.Second
+
+	poolConfig, err := newPoolConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(40), poolConfig.MaxConns)
+	assert.Equal(t, int32(8), poolConfig.MinConns)
+	assert.Equal(t, 30*time.Minute, poolConfig.MaxConnLifetime)
+	assert.Equal(t, 2*time.Minute, poolConfig.MaxConnIdleTime)
+	assert.Equal(t, 15*time.Second, poolConfig.HealthCheckPeriod)
+	assert.Equal(t, "db.internal", poolConfig.ConnConfig.Host)
+}
+
+func TestNewPoolConfig_DefaultHealthCheckPeriod(t *testing.T) {
+	cfg := testDatabaseConfig()
+	cfg.MaxOpenConns = 25
+
+	poolConfig, err := newPoolConfig(cfg)
+	require.NoError(t, err)
+
+	// pgxpool's own default applies when no period is configured
+	assert.Equal(t, time.Minute, poolConfig.HealthCheckPeriod)
+}
+
+func TestClampInt32(t *testing.T) {
+	assert.Equal(t, int32(0), clampInt32(-1))
+	assert.Equal(t, int32(25), clampInt32(25))
+	assert.Equal(t, int32(math.MaxInt32), clampInt32(math.MaxInt32+1))
+}
@@ -112,6 +112,34 @@ func (k *APIKey) IsServerAllowed(serverID string) bool {
 	return false
 }
 
+// IsServerScoped reports whether the key is limited to specific servers or namespaces
+func (k *APIKey) IsServerScoped() bool {
+	return len(k.AllowedServers) > 0 || len(k.Namespaces) > 0
+}
+
+// AllowsServer checks if the API key can reach a server, given the namespaces the server
+// belongs to. A scoped key reaches the servers it lists and the servers in the namespaces
+// it lists; an unscoped key reaches every server. Either way the user's roles must also
+// grant access.
+func (k *APIKey) AllowsServer(serverID string, serverNamespaces []string) bool {
+	if !k.IsServerScoped() {
+		return true
+	}
+	for _, s := range k.AllowedServers {
+		if s == serverID {
+			return true
+		}
+	}
+	for _, ns := range serverNamespaces {
+		for _, n := range k.Namespaces {
+			if n == ns {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsToolAllowed checks if the API key can execute a specific tool
 func (k *APIKey) IsToolAllowed(toolName string) bool {
 	// Empty means all tools allowed
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_AllowsServer(t *testing.T) {
+	tests := []struct {
+		name             string
+		key              APIKey
+		serverID         string
+		serverNamespaces []string
+		wantScoped       bool
+		want             bool
+	}{
+		{
+			name:     "unscoped key allows every server",
+			key:      APIKey{},
+			serverID: "server-1",
+			want:     true,
+		},
+		{
+			name:       "listed server is allowed",
+			key:        APIKey{AllowedServers: []string{"server-1"}},
+			serverID:   "server-1",
+			wantScoped: true,
+			want:       true,
+		},
+		{
+			name:       "unlisted server is denied",
+			key:        APIKey{AllowedServers: []string{"server-1"}},
+			serverID:   "server-2",
+			wantScoped: true,
+			want:       false,
+		},
+		{
+			name:             "server in an allowed namespace is allowed",
+			key:              APIKey{Namespaces: []string{"ns-prod"}},
+			serverID:         "server-2",
+			serverNamespaces: []string{"ns-dev", "ns-prod"},
+			wantScoped:       true,
+			want:             true,
+		},
+		{
+			name:             "server outside the allowed namespaces is denied",
+			key:              APIKey{Namespaces: []string{"ns-prod"}},
+			serverID:         "server-2",
+			serverNamespaces: []string{"ns-dev"},
+			wantScoped:       true,
+			want:             false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantScoped, tt.key.IsServerScoped())
+			assert.Equal(t, tt.want, tt.key.AllowsServer(tt.serverID, tt.serverNamespaces))
+		})
+	}
+}
@@ -7,21 +7,33 @@ import (
 
 // AuditLog represents an audit log entry
 type AuditLog struct {
-	ID             string
-	UserID         *string // Nullable for Phase 2 (no auth)
-	ServerID       *string // Nullable
-	RequestID      string
-	Method         string
-	Path           string
-	QueryParams    json.RawMessage // JSONB
-	RequestBody    json.RawMessage // JSONB
-	ResponseStatus *int            // Nullable
-	ResponseBody   json.RawMessage // JSONB
-	LatencyMS      *int            // Nullable
-	IPAddress      string
-	UserAgent      string
-	ErrorMessage   *string // Nullable
-	CreatedAt      time.Time
+	ID             string          `json:"id"`
+	UserID         *string         `json:"user_id,omitempty"`   // Nullable for Phase 2 (no auth)
+	ServerID       *string         `json:"server_id,omitempty"` // Nullable
+	RequestID      string          `json:"request_id"`
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	QueryParams    json.RawMessage `json:"query_params,omitempty"`    // JSONB
+	RequestBody    json.RawMessage `json:"request_body,omitempty"`    // JSONB
+	ResponseStatus *int            `json:"response_status,omitempty"` // Nullable
+	ResponseBody   json.RawMessage `json:"response_body,omitempty"`   // JSONB
+	LatencyMS      *int            `json:"latency_ms,omitempty"`      // Nullable
+	IPAddress      string          `json:"ip_address"`
+	UserAgent      string          `json:"user_agent"`
+	ErrorMessage   *string         `json:"error_message,omitempty"` // Nullable
+
+	// MCP tool call context, set for tools/call requests through the gateway
+	ToolName      *string         `json:"tool_name,omitempty"`       // Nullable
+	ToolArguments json.RawMessage `json:"tool_arguments,omitempty"`  // JSONB, redacted and truncated argument summary
+	ToolIsError   *bool           `json:"tool_is_error,omitempty"`   // Nullable, the result's isError flag
+	ToolErrorCode *int            `json:"tool_error_code,omitempty"` // Nullable, the JSON-RPC error code when the call failed
+
+	// Access denial context, set when an access check rejected the request
+	AccessDenied   bool    `json:"access_denied"`
+	DenyReason     *string `json:"deny_reason,omitempty"`     // Nullable, one of the DenyReason constants
+	DeniedResource *string `json:"denied_resource,omitempty"` // Nullable, the server, namespace or path that was requested
+
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // AuditLogFilter represents filter criteria for querying audit logs
@@ -31,8 +43,32 @@ type AuditLogFilter struct {
 	RequestID      *string
 	Method         *string
 	ResponseStatus *int
+	MinStatus      *int // Inclusive lower bound on the response status
+	MaxStatus      *int // Inclusive upper bound on the response status
 	FromDate       *time.Time
 	ToDate         *time.Time
+	AccessDenied   *bool // Only entries whose request was (or wasn't) denied by an access check
+	DenyReason     *string
+	After          *AuditLogCursor // Only entries listed after this one, for cursor pagination
 	Limit          int
 	Offset         int
 }
+
+// Reasons recorded with audit logs of denied requests
+const (
+	DenyReasonNoRoles             = "no_roles"                   // The user has no roles
+	DenyReasonPermission          = "insufficient_permission"    // No role grants the permission
+	DenyReasonMissingRole         = "missing_role"               // The user lacks a required role
+	DenyReasonScope               = "insufficient_scope"         // The API key lacks a required scope
+	DenyReasonIPNotAllowed        = "ip_not_allowed"             // The API key's IP whitelist excludes the client
+	DenyReasonReadOnly            = "read_only_key"              // A read-only API key attempted a write
+	DenyReasonServerNotInScope    = "server_not_in_key_scope"    // The API key is limited to other servers
+	DenyReasonNamespaceNotInScope = "namespace_not_in_key_scope" // The API key is limited to other namespaces
+	DenyReasonServerAccess        = "server_access_denied"       // No role has the required access level on the server
+)
+
+// AuditLogCursor is the position of an audit log in the newest-first listing order
+type AuditLogCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
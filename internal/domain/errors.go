@@ -20,6 +20,11 @@ var (
 	ErrServerNotFound      = errors.New("server not found")
 	ErrServerAlreadyExists = errors.New("server with this name already exists")
 	ErrServerUnhealthy     = errors.New("server is unhealthy")
+	ErrServerLimitReached  = errors.New("active server limit reached")
+	ErrServerURLIsGateway  = errors.New("server URL points back at the gateway")
+	ErrServerURLBlocked    = errors.New("server URL points at a blocked internal address")
+	ErrAuthTestUnsupported = errors.New("auth test is not supported for this transport")
+	ErrTestCallUnsupported = errors.New("test call is not supported for this transport")
 
 	// API Key errors
 	ErrAPIKeyNotFound = errors.New("API key not found")
@@ -31,6 +36,9 @@ var (
 	ErrTokenExpired   = errors.New("token has expired")
 	ErrTokenMalformed = errors.New("malformed token")
 
+	// Session errors
+	ErrSessionNotFound = errors.New("session not found")
+
 	// Permission errors
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrForbidden    = errors.New("forbidden: insufficient permissions")
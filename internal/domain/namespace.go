@@ -28,25 +28,28 @@ func (a AccessLevel) Includes(other AccessLevel) bool {
 
 // Namespace represents a logical grouping of MCP servers
 type Namespace struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	AllowedTools []string  `json:"allowed_tools,omitempty"` // Tools every server in the namespace is limited to (empty = all)
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 	// Computed fields (not stored in DB)
 	ServerCount int `json:"server_count,omitempty"`
 }
 
 // NamespaceCreate represents data to create a namespace
 type NamespaceCreate struct {
-	Name        string `json:"name" validate:"required,min=2,max=100"`
-	Description string `json:"description,omitempty"`
+	Name         string   `json:"name" validate:"required,min=2,max=100"`
+	Description  string   `json:"description,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty"`
 }
 
 // NamespaceUpdate represents data to update a namespace
 type NamespaceUpdate struct {
-	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Description *string `json:"description,omitempty"`
+	Name         *string   `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description  *string   `json:"description,omitempty"`
+	AllowedTools *[]string `json:"allowed_tools,omitempty"`
 }
 
 // RoleNamespaceAccess represents a role's access to a namespace
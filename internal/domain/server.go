@@ -2,6 +2,8 @@ package domain
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,7 @@ const (
 	ServerAuthBasic  ServerAuthType = "basic"
 	ServerAuthBearer ServerAuthType = "bearer"
 	ServerAuthOAuth  ServerAuthType = "oauth"
+	ServerAuthAPIKey ServerAuthType = "api_key"
 )
 
 // ServerStatus represents the health status of a server
@@ -32,8 +35,57 @@ const (
 	TransportHTTP           TransportType = "http"            // REST-style HTTP endpoints (legacy)
 	TransportSSE            TransportType = "sse"             // Server-Sent Events with JSON-RPC (legacy, deprecated)
 	TransportStreamableHTTP TransportType = "streamable_http" // Streamable HTTP (MCP 2025-11-25)
+	TransportWebSocket      TransportType = "websocket"       // JSON-RPC over a persistent WebSocket
 )
 
+// JSONRPCIDType is the type of the ids in JSON-RPC requests the gateway generates
+type JSONRPCIDType string
+
+const (
+	JSONRPCIDNumber JSONRPCIDType = "number" // Integer ids (default)
+	JSONRPCIDString JSONRPCIDType = "string" // The same counter sent as a string, for servers that mishandle numbers
+)
+
+// ElicitationPolicy controls whether a server may ask clients for input with elicitation/create
+type ElicitationPolicy string
+
+const (
+	ElicitationDeny  ElicitationPolicy = "deny"  // Elicitation isn't offered to the server (default)
+	ElicitationAllow ElicitationPolicy = "allow" // Elicitation requests are relayed to subscribed clients
+)
+
+// TrailingSlashQuirk normalizes the trailing slash of the URL requests are sent to
+type TrailingSlashQuirk string
+
+const (
+	TrailingSlashAdd    TrailingSlashQuirk = "add"    // Always end the path with a slash
+	TrailingSlashRemove TrailingSlashQuirk = "remove" // Never end the path with a slash
+)
+
+// ParsingQuirk controls how strictly responses from a server are parsed
+type ParsingQuirk string
+
+const (
+	// ParsingStrict rejects responses that aren't well-formed JSON-RPC 2.0
+	ParsingStrict ParsingQuirk = "strict"
+	// ParsingLenient reads the body as SSE or JSON by its content whatever its
+	// Content-Type, and takes an empty body as an empty result
+	ParsingLenient ParsingQuirk = "lenient"
+)
+
+// ServerQuirks are workarounds for backends that deviate from the MCP spec. The zero
+// value talks to the server exactly as the spec describes.
+type ServerQuirks struct {
+	// SessionHeader is the exact name the session id header is sent with, for backends
+	// that match it case-sensitively, e.g. "mcp-session-id" (empty = Mcp-Session-Id).
+	// Responses are always read case-insensitively.
+	SessionHeader string `json:"session_header,omitempty"`
+	// TrailingSlash adds or removes the trailing slash of the server URL's path (empty = as is)
+	TrailingSlash TrailingSlashQuirk `json:"trailing_slash,omitempty"`
+	// Parsing is how responses are parsed (empty = the default, between the two)
+	Parsing ParsingQuirk `json:"parsing,omitempty"`
+}
+
 // MCPServer represents a registered MCP server
 type MCPServer struct {
 	ID                  string          `json:"id"`
@@ -56,10 +108,174 @@ type MCPServer struct {
 	CreatedAt           time.Time       `json:"created_at"`
 	UpdatedAt           time.Time       `json:"updated_at"`
 
+	// Request limits over a sliding minute, enforced by the gateway (0 = unlimited).
+	// The tool limit applies to each tool name separately.
+	MaxRequestsPerMinute     int `json:"max_requests_per_minute"`
+	MaxToolRequestsPerMinute int `json:"max_tool_requests_per_minute"`
+
+	// ToolPrefix namespaces the server's tools as "<prefix>__<tool>" to clients (empty = no prefix)
+	ToolPrefix string `json:"tool_prefix,omitempty"`
+
+	// TLSPins are "sha256/<base64>" SPKI hashes; when set, the backend's TLS certificate
+	// must match one of them in addition to passing normal verification
+	TLSPins []string `json:"tls_pins,omitempty"`
+
+	// MaxResponseBytes caps the upstream response body the gateway reads for the server
+	// (0 = the gateway-wide limit)
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty"`
+
+	// ReplicaGroup names a set of interchangeable servers; calls to any active member are
+	// load balanced across the group (empty = not replicated)
+	ReplicaGroup string `json:"replica_group,omitempty"`
+
+	// ForwardHeaders lists the client request headers the proxy passes to the server;
+	// when set, every other client header is stripped except those MCP needs
+	// (empty = forward all client headers)
+	ForwardHeaders []string `json:"forward_headers,omitempty"`
+
+	// JSONRPCIDType selects the id type of requests the gateway itself sends the server,
+	// such as initialize and tools/list (empty = number). Client request ids are proxied as is.
+	JSONRPCIDType JSONRPCIDType `json:"jsonrpc_id_type,omitempty"`
+
+	// ElicitationPolicy decides whether the server may prompt users for input mid-request.
+	// Elicitation is interactive, so it is denied unless explicitly allowed (empty = deny).
+	ElicitationPolicy ElicitationPolicy `json:"elicitation_policy,omitempty"`
+
+	// LatencyBudgetMs is the response time above which a successful call counts as slow for
+	// the server's circuit breaker (0 = the gateway-wide budget)
+	LatencyBudgetMs int `json:"latency_budget_ms,omitempty"`
+
+	// MaxBatchSize is the largest JSON-RPC batch forwarded to the server; larger batches
+	// are rejected before any request in them is sent (0 = the gateway-wide limit)
+	MaxBatchSize int `json:"max_batch_size,omitempty"`
+
+	// AcceptHeader replaces the Accept header the gateway sends with its POSTs, for
+	// backends that reject the combined default (empty = JSON and SSE)
+	AcceptHeader string `json:"accept_header,omitempty"`
+
+	// RequireInitialize makes the gateway initialize a session before sending any other
+	// request, for backends that reject calls without one
+	RequireInitialize bool `json:"require_initialize,omitempty"`
+
+	// Quirks are workarounds for a backend that deviates from the spec
+	Quirks ServerQuirks `json:"quirks,omitzero"`
+
+	// SystemTags are derived by the registry from the capabilities the server advertises,
+	// such as "has:resources". Kept apart from Tags, which only users set.
+	SystemTags []string `json:"system_tags,omitempty"`
+
+	// NamespaceAllowedTools holds the non-empty tool allowlists of the namespaces the server
+	// belongs to; filled in by the gateway, not stored with the server
+	NamespaceAllowedTools [][]string `json:"-"`
+
 	// Populated from separate query if needed
 	CurrentStatus *ServerHealth `json:"current_status,omitempty"`
 }
 
+// RedactedValue replaces credentials in API responses and logs
+const RedactedValue = "***"
+
+// AuthConfigPublicKeys are auth_config keys that aren't credentials, so their values can
+// be shown
+var AuthConfigPublicKeys = map[string]bool{"username": true, "header": true, "prefix": true}
+
+// RedactAuthConfig returns authConfig with the value of each key but the public ones
+// replaced by RedactedValue. A config that isn't a JSON object is replaced as a whole.
+func RedactAuthConfig(authConfig json.RawMessage) json.RawMessage {
+	if len(authConfig) == 0 || string(authConfig) == "null" {
+		return authConfig
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(authConfig, &config); err != nil {
+		redacted, _ := json.Marshal(RedactedValue)
+		return redacted
+	}
+	for key := range config {
+		if !AuthConfigPublicKeys[key] {
+			config[key] = RedactedValue
+		}
+	}
+	redacted, _ := json.Marshal(config)
+	return redacted
+}
+
+// RestoreRedactedAuthConfig returns updated with each value that is RedactedValue replaced
+// by the key's value in current, so an auth config read from the API can be sent back
+// without wiping the credentials it hides
+func RestoreRedactedAuthConfig(updated, current json.RawMessage) json.RawMessage {
+	var updatedConfig, currentConfig map[string]interface{}
+	if json.Unmarshal(updated, &updatedConfig) != nil || json.Unmarshal(current, &currentConfig) != nil {
+		return updated
+	}
+	for key, value := range updatedConfig {
+		if value == RedactedValue {
+			if currentValue, ok := currentConfig[key]; ok {
+				updatedConfig[key] = currentValue
+			}
+		}
+	}
+	restored, err := json.Marshal(updatedConfig)
+	if err != nil {
+		return updated
+	}
+	return restored
+}
+
+// MarshalJSON encodes the server with its auth config redacted, so API responses and logs
+// never carry its credentials. The gateway and other internal callers read AuthConfig
+// itself and get the real values.
+func (s MCPServer) MarshalJSON() ([]byte, error) {
+	type server MCPServer // Without this method
+	redacted := server(s)
+	redacted.AuthConfig = RedactAuthConfig(s.AuthConfig)
+	return json.Marshal(redacted)
+}
+
+// AllowsElicitation reports whether the server's elicitation requests may reach clients
+func (s *MCPServer) AllowsElicitation() bool {
+	return s.ElicitationPolicy == ElicitationAllow
+}
+
+// RestrictsTools reports whether the server or any of its namespaces limits which of its
+// tools clients may use
+func (s *MCPServer) RestrictsTools() bool {
+	return len(s.AllowedTools) > 0 || len(s.NamespaceAllowedTools) > 0
+}
+
+// AllowsTool reports whether clients may use the named upstream tool: it must be on the
+// server's AllowedTools, when set, and on every namespace allowlist
+func (s *MCPServer) AllowsTool(name string) bool {
+	if len(s.AllowedTools) > 0 && !slices.Contains(s.AllowedTools, name) {
+		return false
+	}
+	for _, allowed := range s.NamespaceAllowedTools {
+		if !slices.Contains(allowed, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolPrefixSeparator joins a server's ToolPrefix and a tool name
+const ToolPrefixSeparator = "__"
+
+// ExposedToolName returns the tool name clients see for one of the server's tools
+func (s *MCPServer) ExposedToolName(name string) string {
+	if s.ToolPrefix == "" {
+		return name
+	}
+	return s.ToolPrefix + ToolPrefixSeparator + name
+}
+
+// UpstreamToolName strips the server's ToolPrefix from a client-supplied tool name.
+// Returns false when the server has a prefix and the name doesn't carry it.
+func (s *MCPServer) UpstreamToolName(name string) (string, bool) {
+	if s.ToolPrefix == "" {
+		return name, true
+	}
+	return strings.CutPrefix(name, s.ToolPrefix+ToolPrefixSeparator)
+}
+
 // ServerCreate represents the data required to create a new MCP server
 type ServerCreate struct {
 	Name                string          `json:"name" validate:"required,min=3,max=255"`
@@ -76,6 +292,79 @@ type ServerCreate struct {
 	Tags                []string        `json:"tags,omitempty"`
 	AllowedTools        []string        `json:"allowed_tools,omitempty"` // List of tool names users can access (empty = all)
 	Metadata            json.RawMessage `json:"metadata,omitempty"`
+
+	MaxRequestsPerMinute     int               `json:"max_requests_per_minute,omitempty" validate:"omitempty,min=0"`
+	MaxToolRequestsPerMinute int               `json:"max_tool_requests_per_minute,omitempty" validate:"omitempty,min=0"`
+	ToolPrefix               string            `json:"tool_prefix,omitempty" validate:"omitempty,alphanum,max=32"`
+	TLSPins                  []string          `json:"tls_pins,omitempty"`
+	MaxResponseBytes         int64             `json:"max_response_bytes,omitempty" validate:"omitempty,min=0"`
+	ReplicaGroup             string            `json:"replica_group,omitempty" validate:"omitempty,max=255"`
+	ForwardHeaders           []string          `json:"forward_headers,omitempty"`
+	JSONRPCIDType            JSONRPCIDType     `json:"jsonrpc_id_type,omitempty"`
+	ElicitationPolicy        ElicitationPolicy `json:"elicitation_policy,omitempty"`
+	LatencyBudgetMs          int               `json:"latency_budget_ms,omitempty" validate:"omitempty,min=0"`
+	MaxBatchSize             int               `json:"max_batch_size,omitempty" validate:"omitempty,min=0"`
+	AcceptHeader             string            `json:"accept_header,omitempty" validate:"omitempty,max=255"`
+	RequireInitialize        bool              `json:"require_initialize,omitempty"`
+	Quirks                   ServerQuirks      `json:"quirks,omitzero"`
+}
+
+// ServerImportResult is the outcome of importing one server
+type ServerImportResult struct {
+	Index  int        `json:"index"` // Position in the imported list
+	Name   string     `json:"name"`
+	Server *MCPServer `json:"server,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// ServerImportSummary reports which servers of a bulk import were created
+type ServerImportSummary struct {
+	Created int                  `json:"created"`
+	Failed  int                  `json:"failed"`
+	Results []ServerImportResult `json:"results"`
+}
+
+// ServerExportSecrets controls how server credentials appear in an export
+type ServerExportSecrets string
+
+const (
+	// ServerExportSecretsOmit drops auth_config
+	ServerExportSecretsOmit ServerExportSecrets = "omit"
+	// ServerExportSecretsPlaceholder replaces credential values with placeholders to fill in
+	ServerExportSecretsPlaceholder ServerExportSecrets = "placeholder"
+	// ServerExportSecretsInclude exports credentials as stored
+	ServerExportSecretsInclude ServerExportSecrets = "include"
+)
+
+// ServerExport is a portable copy of the registry. Its servers can be sent back to the
+// server import as is; namespaces record how to rebuild membership and role access.
+type ServerExport struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Secrets    ServerExportSecrets `json:"secrets"`
+	Servers    []ExportedServer    `json:"servers"`
+	Namespaces []ExportedNamespace `json:"namespaces"`
+}
+
+// ExportedServer is a server as it would be created, with the namespaces it belongs to
+type ExportedServer struct {
+	ServerCreate
+	Namespaces []string `json:"namespaces,omitempty"` // Namespace names
+}
+
+// ExportedNamespace is a namespace with its exported members and role access
+type ExportedNamespace struct {
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description,omitempty"`
+	AllowedTools []string                `json:"allowed_tools,omitempty"`
+	Servers      []string                `json:"servers"` // Server names
+	RoleAccess   []ExportedNamespaceRole `json:"role_access,omitempty"`
+}
+
+// ExportedNamespaceRole is a role's access to an exported namespace
+type ExportedNamespaceRole struct {
+	Role        string      `json:"role"`
+	AccessLevel AccessLevel `json:"access_level"`
 }
 
 // ServerUpdate represents the data that can be updated for an MCP server
@@ -94,16 +383,51 @@ type ServerUpdate struct {
 	Tags                *[]string       `json:"tags,omitempty"`
 	AllowedTools        *[]string       `json:"allowed_tools,omitempty"` // List of tool names users can access (empty = all)
 	Metadata            json.RawMessage `json:"metadata,omitempty"`
+
+	MaxRequestsPerMinute     *int               `json:"max_requests_per_minute,omitempty" validate:"omitempty,min=0"`
+	MaxToolRequestsPerMinute *int               `json:"max_tool_requests_per_minute,omitempty" validate:"omitempty,min=0"`
+	ToolPrefix               *string            `json:"tool_prefix,omitempty" validate:"omitempty,alphanum,max=32"`
+	TLSPins                  *[]string          `json:"tls_pins,omitempty"`
+	MaxResponseBytes         *int64             `json:"max_response_bytes,omitempty" validate:"omitempty,min=0"`
+	ReplicaGroup             *string            `json:"replica_group,omitempty" validate:"omitempty,max=255"`
+	ForwardHeaders           *[]string          `json:"forward_headers,omitempty"`
+	JSONRPCIDType            *JSONRPCIDType     `json:"jsonrpc_id_type,omitempty"`
+	ElicitationPolicy        *ElicitationPolicy `json:"elicitation_policy,omitempty"`
+	LatencyBudgetMs          *int               `json:"latency_budget_ms,omitempty" validate:"omitempty,min=0"`
+	MaxBatchSize             *int               `json:"max_batch_size,omitempty" validate:"omitempty,min=0"`
+	AcceptHeader             *string            `json:"accept_header,omitempty" validate:"omitempty,max=255"`
+	RequireInitialize        *bool              `json:"require_initialize,omitempty"`
+	Quirks                   *ServerQuirks      `json:"quirks,omitempty"`
 }
 
+// HealthCheckMode identifies how a health check result was produced
+type HealthCheckMode string
+
+const (
+	HealthCheckModeHTTP HealthCheckMode = "http" // HTTP GET against the health check URL
+	HealthCheckModeMCP  HealthCheckMode = "mcp"  // MCP initialize handshake followed by tools/list
+)
+
 // ServerHealth represents the health check result for a server
 type ServerHealth struct {
+	ID             string          `json:"id"`
+	ServerID       string          `json:"server_id"`
+	Status         ServerStatus    `json:"status"`
+	ResponseTimeMs int             `json:"response_time_ms,omitempty"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	ServerVersion  string          `json:"server_version,omitempty"` // serverInfo.version from MCP initialize
+	CheckMode      HealthCheckMode `json:"check_mode,omitempty"`
+	CheckedAt      time.Time       `json:"checked_at"`
+}
+
+// ServerHealthEvent records a change in a server's health status
+type ServerHealthEvent struct {
 	ID             string       `json:"id"`
 	ServerID       string       `json:"server_id"`
-	Status         ServerStatus `json:"status"`
-	ResponseTimeMs int          `json:"response_time_ms,omitempty"`
+	PreviousStatus ServerStatus `json:"previous_status"`
+	NewStatus      ServerStatus `json:"new_status"`
 	ErrorMessage   string       `json:"error_message,omitempty"`
-	CheckedAt      time.Time    `json:"checked_at"`
+	OccurredAt     time.Time    `json:"occurred_at"`
 }
 
 // ServerFilter represents query filters for listing servers
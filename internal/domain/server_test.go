@@ -215,10 +215,31 @@ func TestMCPServer_AuthConfig(t *testing.T) {
 	require.NoError(t, err)
 
 	// Compare parsed JSON content, not raw bytes (JSON re-marshaling may change whitespace)
-	var expected, actual map[string]string
-	require.NoError(t, json.Unmarshal(authConfig, &expected))
+	var actual map[string]string
 	require.NoError(t, json.Unmarshal(parsed.AuthConfig, &actual))
-	assert.Equal(t, expected, actual)
+	assert.Equal(t, map[string]string{"token": RedactedValue}, actual, "credentials are redacted when encoded")
+	assert.Equal(t, authConfig, server.AuthConfig, "the server keeps the real values")
+}
+
+func TestRedactAuthConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		authConfig string
+		want       string
+	}{
+		{name: "bearer", authConfig: `{"token":"ghp_123"}`, want: `{"token":"***"}`},
+		{name: "basic keeps username", authConfig: `{"username":"bot","password":"hunter2"}`, want: `{"username":"bot","password":"***"}`},
+		{name: "api key keeps header", authConfig: `{"header":"X-API-Key","value":"k","prefix":"Key "}`, want: `{"header":"X-API-Key","value":"***","prefix":"Key "}`},
+		{name: "nested values", authConfig: `{"client_id":"id","client_secret":{"nested":"s"}}`, want: `{"client_id":"***","client_secret":"***"}`},
+		{name: "not an object", authConfig: `"raw-token"`, want: `"***"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.want, string(RedactAuthConfig(json.RawMessage(tt.authConfig))))
+		})
+	}
+
+	assert.Nil(t, RedactAuthConfig(nil))
 }
 
 func TestMCPServer_Metadata(t *testing.T) {
@@ -294,3 +315,49 @@ func TestServerUpdate_AllowedTools(t *testing.T) {
 	require.NotNil(t, parsed.AllowedTools)
 	assert.Equal(t, tools, *parsed.AllowedTools)
 }
+
+func TestMCPServer_ToolPrefix(t *testing.T) {
+	prefixed := &MCPServer{ToolPrefix: "github"}
+	plain := &MCPServer{}
+
+	assert.Equal(t, "github__search", prefixed.ExposedToolName("search"))
+	assert.Equal(t, "search", plain.ExposedToolName("search"))
+
+	name, ok := prefixed.UpstreamToolName("github__search")
+	assert.True(t, ok)
+	assert.Equal(t, "search", name)
+
+	name, ok = prefixed.UpstreamToolName("github__repo__search")
+	assert.True(t, ok, "only the server prefix is stripped")
+	assert.Equal(t, "repo__search", name)
+
+	_, ok = prefixed.UpstreamToolName("search")
+	assert.False(t, ok, "unprefixed names don't resolve on a prefixed server")
+	_, ok = prefixed.UpstreamToolName("jira__search")
+	assert.False(t, ok)
+
+	name, ok = plain.UpstreamToolName("search")
+	assert.True(t, ok)
+	assert.Equal(t, "search", name)
+}
+
+func TestMCPServer_AllowsTool(t *testing.T) {
+	open := &MCPServer{}
+	assert.False(t, open.RestrictsTools())
+	assert.True(t, open.AllowsTool("delete"))
+
+	server := &MCPServer{AllowedTools: []string{"search", "delete"}}
+	assert.True(t, server.RestrictsTools())
+	assert.True(t, server.AllowsTool("delete"))
+	assert.False(t, server.AllowsTool("fetch"))
+
+	server.NamespaceAllowedTools = [][]string{{"search", "fetch"}}
+	assert.True(t, server.AllowsTool("search"))
+	assert.False(t, server.AllowsTool("delete"), "the namespace doesn't allow it")
+	assert.False(t, server.AllowsTool("fetch"), "the server doesn't allow it")
+
+	scoped := &MCPServer{NamespaceAllowedTools: [][]string{{"search", "fetch"}, {"search"}}}
+	assert.True(t, scoped.RestrictsTools())
+	assert.True(t, scoped.AllowsTool("search"))
+	assert.False(t, scoped.AllowsTool("fetch"), "every namespace must allow the tool")
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// PersistedSession is the state needed to resume the gateway's MCP session with a server
+// after a restart, without re-initializing if the server still honors the session
+type PersistedSession struct {
+	ServerID        string    `json:"server_id"`
+	SessionID       string    `json:"session_id"`
+	ProtocolVersion string    `json:"protocol_version"`
+	LastEventID     string    `json:"last_event_id,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UserSession is a browser login tracked server-side so it can be revoked before its
+// cookie expires
+type UserSession struct {
+	ID        string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
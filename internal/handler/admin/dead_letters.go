@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// DeadLetterLister returns recorded dead letters, newest first
+type DeadLetterLister interface {
+	List(limit int) []gateway.DeadLetter
+}
+
+// DeadLettersHandler handles the admin dead-letter log endpoint
+type DeadLettersHandler struct {
+	store  DeadLetterLister
+	logger logger.Logger
+}
+
+// NewDeadLettersHandler creates a new admin dead letters handler
+func NewDeadLettersHandler(store DeadLetterLister, log logger.Logger) *DeadLettersHandler {
+	return &DeadLettersHandler{
+		store:  store,
+		logger: log.With().Str("handler", "admin-dead-letters").Logger(),
+	}
+}
+
+// List returns the proxied requests that failed after retries, newest first
+// GET /api/v1/admin/dead-letters?limit=100&kind=connect&server_id=...
+func (h *DeadLettersHandler) List(c *gin.Context) {
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	kind := gateway.FailureKind(c.Query("kind"))
+	serverID := c.Query("server_id")
+
+	letters := []gateway.DeadLetter{}
+	for _, letter := range h.store.List(0) {
+		if (kind != "" && letter.Kind != kind) || (serverID != "" && letter.ServerID != serverID) {
+			continue
+		}
+		letters = append(letters, letter)
+		if len(letters) == limit {
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+		"count":        len(letters),
+	})
+}
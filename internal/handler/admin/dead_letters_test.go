@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestDeadLettersHandler_List(t *testing.T) {
+	store := gateway.NewMemoryDeadLetterStore(10)
+	store.Add(gateway.DeadLetter{ID: "1", ServerID: "a", Kind: gateway.FailureConnect})
+	store.Add(gateway.DeadLetter{ID: "2", ServerID: "b", Kind: gateway.FailureTimeout})
+	store.Add(gateway.DeadLetter{ID: "3", ServerID: "a", Kind: gateway.FailureTimeout})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "newest first", query: "", wantStatus: http.StatusOK, wantIDs: []string{"3", "2", "1"}},
+		{name: "limit", query: "?limit=2", wantStatus: http.StatusOK, wantIDs: []string{"3", "2"}},
+		{name: "by kind", query: "?kind=timeout", wantStatus: http.StatusOK, wantIDs: []string{"3", "2"}},
+		{name: "by server", query: "?server_id=a&kind=connect", wantStatus: http.StatusOK, wantIDs: []string{"1"}},
+		{name: "invalid limit", query: "?limit=0", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter()
+			router.GET("/dead-letters", NewDeadLettersHandler(store, logger.NewNop()).List)
+
+			req, _ := http.NewRequest(http.MethodGet, "/dead-letters"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				DeadLetters []gateway.DeadLetter `json:"dead_letters"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			ids := []string{}
+			for _, letter := range body.DeadLetters {
+				ids = append(ids, letter.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
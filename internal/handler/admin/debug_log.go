@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// DebugLogStore returns and clears the recent requests and responses of a server
+type DebugLogStore interface {
+	List(serverID string, limit int) []gateway.DebugEntry
+	Clear(serverID string)
+}
+
+// DebugLogHandler handles the admin debug log endpoints
+type DebugLogHandler struct {
+	store  DebugLogStore
+	logger logger.Logger
+}
+
+// NewDebugLogHandler creates a new admin debug log handler
+func NewDebugLogHandler(store DebugLogStore, log logger.Logger) *DebugLogHandler {
+	return &DebugLogHandler{
+		store:  store,
+		logger: log.With().Str("handler", "admin-debug-log").Logger(),
+	}
+}
+
+// List returns the server's recent requests and responses, newest first
+// GET /api/v1/admin/debug-log/:server_id?limit=50
+func (h *DebugLogHandler) List(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	entries := h.store.List(c.Param("server_id"), limit)
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// Clear drops the server's recorded requests and responses
+// DELETE /api/v1/admin/debug-log/:server_id
+func (h *DebugLogHandler) Clear(c *gin.Context) {
+	serverID := c.Param("server_id")
+	h.store.Clear(serverID)
+	h.logger.Info().Str("server_id", serverID).Msg("Debug log cleared")
+	c.Status(http.StatusNoContent)
+}
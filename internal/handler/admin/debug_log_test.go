@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestDebugLogHandler(t *testing.T) {
+	debugLog := gateway.NewDebugLog(3, 0, nil)
+	for i := 1; i <= 4; i++ {
+		debugLog.Add(gateway.DebugEntry{ID: fmt.Sprint(i), ServerID: "a", Method: "tools/list"})
+	}
+
+	handler := NewDebugLogHandler(debugLog, logger.NewNop())
+	router := setupTestRouter()
+	router.GET("/debug-log/:server_id", handler.List)
+	router.DELETE("/debug-log/:server_id", handler.Clear)
+
+	list := func(query string) (int, []string) {
+		req, _ := http.NewRequest(http.MethodGet, "/debug-log/a"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var body struct {
+			Entries []gateway.DebugEntry `json:"entries"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		ids := []string{}
+		for _, entry := range body.Entries {
+			ids = append(ids, entry.ID)
+		}
+		return rec.Code, ids
+	}
+
+	code, ids := list("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"4", "3", "2"}, ids)
+
+	code, ids = list("?limit=1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"4"}, ids)
+
+	code, _ = list("?limit=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/debug-log/a", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	code, ids = list("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/database"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// Migrator reports and applies database schema migrations. Errors must not contain
+// database credentials; they are returned to the caller.
+type Migrator interface {
+	Status() (version uint, dirty bool, err error)
+	Up() error
+}
+
+// MigrationsHandler handles admin database migration endpoints
+type MigrationsHandler struct {
+	migrator Migrator
+	// latest returns the newest migration the server knows about
+	latest func() (uint, error)
+	logger logger.Logger
+}
+
+// MigrationStatusResponse is the schema state reported by the migration endpoints
+type MigrationStatusResponse struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	Latest  uint `json:"latest"`
+	Pending bool `json:"pending"`
+}
+
+// NewMigrationsHandler creates a new admin migrations handler
+func NewMigrationsHandler(migrator Migrator, log logger.Logger) *MigrationsHandler {
+	return &MigrationsHandler{
+		migrator: migrator,
+		latest:   database.LatestMigrationVersion,
+		logger:   log.With().Str("handler", "admin-migrations").Logger(),
+	}
+}
+
+// GetStatus returns the applied migration version and whether it is dirty
+// GET /api/v1/admin/migrations
+func (h *MigrationsHandler) GetStatus(c *gin.Context) {
+	status, err := h.status()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get migration status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get migration status", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// MigrateUp applies all pending migrations and returns the resulting status
+// POST /api/v1/admin/migrations/up
+func (h *MigrationsHandler) MigrateUp(c *gin.Context) {
+	_, dirty, err := h.migrator.Status()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get migration status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get migration status", "details": err.Error()})
+		return
+	}
+	if dirty {
+		c.JSON(http.StatusConflict, gin.H{"error": "The database is in a dirty state; fix it with the migrate command before running migrations"})
+		return
+	}
+
+	if err := h.migrator.Up(); err != nil {
+		if errors.Is(err, database.ErrMigrationInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A migration is already in progress"})
+			return
+		}
+		h.logger.Error().Err(err).Msg("Failed to run migrations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run migrations", "details": err.Error()})
+		return
+	}
+
+	status, err := h.status()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get migration status")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get migration status", "details": err.Error()})
+		return
+	}
+
+	h.logger.Info().Uint("version", status.Version).Msg("Migrations applied through the API")
+	c.JSON(http.StatusOK, status)
+}
+
+// status combines the applied version with the newest embedded migration
+func (h *MigrationsHandler) status() (*MigrationStatusResponse, error) {
+	version, dirty, err := h.migrator.Status()
+	if err != nil {
+		return nil, err
+	}
+	latest, err := h.latest()
+	if err != nil {
+		return nil, err
+	}
+	return &MigrationStatusResponse{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  latest,
+		Pending: version < latest,
+	}, nil
+}
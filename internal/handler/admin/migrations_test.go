@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/database"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// fakeMigrator reports a fixed schema state; Up moves it to upVersion
+type fakeMigrator struct {
+	version   uint
+	dirty     bool
+	statusErr error
+	upErr     error
+	upVersion uint
+	ups       int
+}
+
+func (f *fakeMigrator) Status() (uint, bool, error) {
+	return f.version, f.dirty, f.statusErr
+}
+
+func (f *fakeMigrator) Up() error {
+	f.ups++
+	if f.upErr != nil {
+		return f.upErr
+	}
+	f.version = f.upVersion
+	return nil
+}
+
+func newTestMigrationsHandler(migrator *fakeMigrator) *MigrationsHandler {
+	h := NewMigrationsHandler(migrator, logger.NewNop())
+	h.latest = func() (uint, error) { return 23, nil }
+	return h
+}
+
+func TestMigrationsHandler_GetStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrator   *fakeMigrator
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "up to date",
+			migrator:   &fakeMigrator{version: 23},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"version":23,"dirty":false,"latest":23,"pending":false}`,
+		},
+		{
+			name:       "pending and dirty",
+			migrator:   &fakeMigrator{version: 21, dirty: true},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"version":21,"dirty":true,"latest":23,"pending":true}`,
+		},
+		{
+			name:       "status failure",
+			migrator:   &fakeMigrator{statusErr: errors.New("dial tcp: connection refused")},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Failed to get migration status","details":"dial tcp: connection refused"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter()
+			router.GET("/migrations", newTestMigrationsHandler(tt.migrator).GetStatus)
+
+			req, _ := http.NewRequest(http.MethodGet, "/migrations", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestMigrationsHandler_MigrateUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrator   *fakeMigrator
+		wantStatus int
+		wantUps    int
+	}{
+		{name: "applies pending migrations", migrator: &fakeMigrator{version: 21, upVersion: 23}, wantStatus: http.StatusOK, wantUps: 1},
+		{name: "refuses a dirty database", migrator: &fakeMigrator{version: 21, dirty: true}, wantStatus: http.StatusConflict},
+		{name: "conflicts with a running migration", migrator: &fakeMigrator{upErr: fmt.Errorf("up: %w", database.ErrMigrationInProgress)}, wantStatus: http.StatusConflict, wantUps: 1},
+		{name: "migration failure", migrator: &fakeMigrator{upErr: errors.New("migration 22 failed: syntax error")}, wantStatus: http.StatusInternalServerError, wantUps: 1},
+		{name: "status failure", migrator: &fakeMigrator{statusErr: errors.New("connection refused")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouter()
+			router.POST("/migrations/up", newTestMigrationsHandler(tt.migrator).MigrateUp)
+
+			req, _ := http.NewRequest(http.MethodPost, "/migrations/up", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantUps, tt.migrator.ups)
+			if tt.wantStatus == http.StatusOK {
+				var resp MigrationStatusResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, uint(23), resp.Version)
+				assert.False(t, resp.Pending)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/authz"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// PolicyReloader reloads authorization policies from their source
+type PolicyReloader interface {
+	ReloadPolicy() error
+	PolicyCount() (int, error)
+}
+
+// RBACHandler handles admin RBAC policy endpoints
+type RBACHandler struct {
+	policies PolicyReloader
+	logger   logger.Logger
+}
+
+// NewRBACHandler creates a new admin RBAC handler
+func NewRBACHandler(policies PolicyReloader, log logger.Logger) *RBACHandler {
+	return &RBACHandler{
+		policies: policies,
+		logger:   log.With().Str("handler", "admin-rbac").Logger(),
+	}
+}
+
+// ReloadPolicy reloads the RBAC policy from its source without a restart
+// POST /api/v1/admin/rbac/reload
+func (h *RBACHandler) ReloadPolicy(c *gin.Context) {
+	if err := h.policies.ReloadPolicy(); err != nil {
+		switch {
+		case errors.Is(err, authz.ErrReloadInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "A policy reload is already in progress"})
+		case errors.Is(err, authz.ErrNoPolicySource):
+			c.JSON(http.StatusConflict, gin.H{"error": "Policies are built in; set auth.casbin_model_path and auth.casbin_policy_path to reload them"})
+		default:
+			h.logger.Error().Err(err).Msg("Failed to reload RBAC policy")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload RBAC policy"})
+		}
+		return
+	}
+
+	count, err := h.policies.PolicyCount()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to count RBAC policies")
+	}
+
+	h.logger.Info().Int("policies", count).Msg("RBAC policy reloaded")
+	c.JSON(http.StatusOK, gin.H{"message": "RBAC policy reloaded", "policies": count})
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/service/authz"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// fakePolicyReloader returns a fixed reload error and policy count
+type fakePolicyReloader struct {
+	err     error
+	count   int
+	reloads int
+}
+
+func (f *fakePolicyReloader) ReloadPolicy() error {
+	f.reloads++
+	return f.err
+}
+
+func (f *fakePolicyReloader) PolicyCount() (int, error) {
+	return f.count, nil
+}
+
+func TestRBACHandler_ReloadPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "reloads the policy", wantStatus: http.StatusOK},
+		{name: "conflicts with a running reload", err: authz.ErrReloadInProgress, wantStatus: http.StatusConflict},
+		{name: "conflicts without a policy source", err: authz.ErrNoPolicySource, wantStatus: http.StatusConflict},
+		{name: "fails when the source can't be read", err: errors.New("open policy.csv: permission denied"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloader := &fakePolicyReloader{err: tt.err, count: 12}
+			router := setupTestRouter()
+			router.POST("/rbac/reload", NewRBACHandler(reloader, logger.NewNop()).ReloadPolicy)
+
+			req, _ := http.NewRequest(http.MethodPost, "/rbac/reload", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, 1, reloader.reloads)
+			if tt.wantStatus == http.StatusOK {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, float64(12), resp["policies"])
+			}
+		})
+	}
+}
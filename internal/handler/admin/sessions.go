@@ -1,17 +1,22 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/handler/middleware"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
 // SessionsHandler handles admin session management endpoints
-// Note: Currently sessions are stored in cookies, so this provides limited functionality.
-// For full session management, implement a sessions table in the database.
+// Sessions can only be listed and revoked when a server-side session store is
+// configured (auth.session_store); with plain cookie sessions these endpoints are no-ops.
 type SessionsHandler struct {
+	store  middleware.SessionStore
 	logger logger.Logger
 }
 
@@ -22,6 +27,13 @@ func NewSessionsHandler(log logger.Logger) *SessionsHandler {
 	}
 }
 
+// NewSessionsHandlerWithStore creates an admin sessions handler backed by a session store
+func NewSessionsHandlerWithStore(store middleware.SessionStore, log logger.Logger) *SessionsHandler {
+	h := NewSessionsHandler(log)
+	h.store = store
+	return h
+}
+
 // SessionInfo represents information about a user session
 type SessionInfo struct {
 	SessionID string `json:"session_id"`
@@ -35,7 +47,6 @@ type SessionInfo struct {
 
 // ListUserSessions returns all active sessions for a user
 // GET /api/v1/admin/users/:id/sessions
-// Note: This is a placeholder. Full implementation requires a sessions table.
 func (h *SessionsHandler) ListUserSessions(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -43,17 +54,41 @@ func (h *SessionsHandler) ListUserSessions(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement session listing when sessions table is added
-	// For now, return empty list since sessions are cookie-based
-	c.JSON(http.StatusOK, gin.H{
-		"sessions": []SessionInfo{},
-		"message":  "Session listing requires database-backed sessions (not yet implemented)",
-	})
+	if h.store == nil {
+		// Cookie-only sessions can't be enumerated
+		c.JSON(http.StatusOK, gin.H{
+			"sessions": []SessionInfo{},
+			"message":  "Session listing requires a server-side session store (not yet implemented for cookie sessions)",
+		})
+		return
+	}
+
+	records, err := h.store.ListByUser(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", id).Msg("Failed to list sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	current := middleware.CurrentSessionID(c)
+	sessions := make([]SessionInfo, 0, len(records))
+	for _, record := range records {
+		sessions = append(sessions, SessionInfo{
+			SessionID: record.ID,
+			UserID:    record.UserID,
+			CreatedAt: record.CreatedAt.UTC().Format(time.RFC3339),
+			ExpiresAt: record.ExpiresAt.UTC().Format(time.RFC3339),
+			IPAddress: record.IPAddress,
+			UserAgent: record.UserAgent,
+			IsCurrent: current != "" && record.ID == current,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
 }
 
 // RevokeSession revokes a specific session
 // DELETE /api/v1/admin/users/:id/sessions/:sid
-// Note: This is a placeholder. Full implementation requires a sessions table.
 func (h *SessionsHandler) RevokeSession(c *gin.Context) {
 	userID := c.Param("id")
 	sessionID := c.Param("sid")
@@ -63,20 +98,46 @@ func (h *SessionsHandler) RevokeSession(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement session revocation when sessions table is added
+	if h.store == nil {
+		h.logger.Info().
+			Str("user_id", userID).
+			Str("session_id", sessionID).
+			Msg("Session revocation requested without a session store")
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Session revocation requires a server-side session store (not yet implemented for cookie sessions)",
+		})
+		return
+	}
+
+	// Only revoke the session if it belongs to the user in the path
+	record, err := h.store.Get(c.Request.Context(), sessionID)
+	if err == nil && record.UserID != userID {
+		err = domain.ErrSessionNotFound
+	}
+	if err == nil {
+		err = h.store.Revoke(c.Request.Context(), sessionID)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to revoke session")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
 	h.logger.Info().
 		Str("user_id", userID).
 		Str("session_id", sessionID).
-		Msg("Session revocation requested (not yet implemented)")
+		Msg("Session revoked")
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Session revocation requires database-backed sessions (not yet implemented)",
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
 }
 
 // RevokeAllUserSessions revokes all sessions for a user
 // DELETE /api/v1/admin/users/:id/sessions
-// Note: This is a placeholder. Full implementation requires a sessions table.
 func (h *SessionsHandler) RevokeAllUserSessions(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -85,12 +146,28 @@ func (h *SessionsHandler) RevokeAllUserSessions(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement bulk session revocation when sessions table is added
+	if h.store == nil {
+		h.logger.Info().
+			Str("user_id", userID).
+			Msg("All sessions revocation requested without a session store")
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Bulk session revocation requires a server-side session store (not yet implemented for cookie sessions)",
+		})
+		return
+	}
+
+	revoked, err := h.store.RevokeUser(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to revoke sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
 	h.logger.Info().
 		Str("user_id", userID).
-		Msg("All sessions revocation requested (not yet implemented)")
+		Int("revoked", revoked).
+		Msg("All user sessions revoked")
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Bulk session revocation requires database-backed sessions (not yet implemented)",
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked", "revoked": revoked})
 }
@@ -1,15 +1,18 @@
 package admin
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/waffles/waffles/internal/handler/middleware"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -188,3 +191,76 @@ func TestSessionsHandler_RevokeAllUserSessions_MissingID(t *testing.T) {
 	// Handler returns 400 for empty ID
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+func TestSessionsHandler_WithStore(t *testing.T) {
+	ctx := context.Background()
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func() (*gin.Engine, *middleware.MemorySessionStore) {
+		store := middleware.NewMemorySessionStore(time.Hour)
+		handler := NewSessionsHandlerWithStore(store, logger.NewNop())
+		router := gin.New()
+		router.GET("/users/:id/sessions", handler.ListUserSessions)
+		router.DELETE("/users/:id/sessions", handler.RevokeAllUserSessions)
+		router.DELETE("/users/:id/sessions/:sid", handler.RevokeSession)
+		return router, store
+	}
+	serve := func(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("lists the user's sessions", func(t *testing.T) {
+		router, store := newRouter()
+		session, err := store.Create(ctx, "user-123", "192.168.1.1", "Mozilla/5.0")
+		require.NoError(t, err)
+		_, err = store.Create(ctx, "user-456", "", "")
+		require.NoError(t, err)
+
+		rec := serve(router, http.MethodGet, "/users/user-123/sessions")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Sessions []SessionInfo `json:"sessions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Sessions, 1)
+		assert.Equal(t, session.ID, resp.Sessions[0].SessionID)
+		assert.Equal(t, "192.168.1.1", resp.Sessions[0].IPAddress)
+	})
+
+	t.Run("revokes a session", func(t *testing.T) {
+		router, store := newRouter()
+		session, err := store.Create(ctx, "user-123", "", "")
+		require.NoError(t, err)
+
+		// Another user's path can't revoke it
+		assert.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/users/user-456/sessions/"+session.ID).Code)
+
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodDelete, "/users/user-123/sessions/"+session.ID).Code)
+		_, err = store.Get(ctx, session.ID)
+		assert.Error(t, err)
+
+		assert.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/users/user-123/sessions/"+session.ID).Code)
+	})
+
+	t.Run("revokes all of a user's sessions", func(t *testing.T) {
+		router, store := newRouter()
+		for i := 0; i < 2; i++ {
+			_, err := store.Create(ctx, "user-123", "", "")
+			require.NoError(t, err)
+		}
+
+		rec := serve(router, http.MethodDelete, "/users/user-123/sessions")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, float64(2), resp["revoked"])
+		sessions, err := store.ListByUser(ctx, "user-123")
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
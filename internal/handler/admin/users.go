@@ -7,14 +7,16 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/handler/middleware"
 	"github.com/waffles/waffles/internal/service/user"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
 // UsersHandler handles admin user management endpoints
 type UsersHandler struct {
-	service *user.Service
-	logger  logger.Logger
+	service      *user.Service
+	sessionStore middleware.SessionStore
+	logger       logger.Logger
 }
 
 // NewUsersHandler creates a new admin users handler
@@ -25,6 +27,22 @@ func NewUsersHandler(service *user.Service, log logger.Logger) *UsersHandler {
 	}
 }
 
+// SetSessionStore makes role changes and deactivation revoke the user's browser
+// sessions, which otherwise keep the roles they were created with
+func (h *UsersHandler) SetSessionStore(store middleware.SessionStore) {
+	h.sessionStore = store
+}
+
+// revokeSessions ends all of a user's browser sessions, if sessions are tracked
+func (h *UsersHandler) revokeSessions(c *gin.Context, userID string) {
+	if h.sessionStore == nil {
+		return
+	}
+	if _, err := h.sessionStore.RevokeUser(c.Request.Context(), userID); err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to revoke user sessions")
+	}
+}
+
 // ListUsers returns a paginated list of users
 // GET /api/v1/admin/users
 func (h *UsersHandler) ListUsers(c *gin.Context) {
@@ -137,6 +155,7 @@ func (h *UsersHandler) DeleteUser(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
 		return
 	}
+	h.revokeSessions(c, id)
 
 	c.JSON(http.StatusOK, gin.H{"message": "User deactivated successfully"})
 }
@@ -166,6 +185,7 @@ func (h *UsersHandler) UpdateUserRoles(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user roles"})
 		return
 	}
+	h.revokeSessions(c, id)
 
 	c.JSON(http.StatusOK, userWithRoles)
 }
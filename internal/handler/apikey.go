@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -128,6 +130,15 @@ func (a *apiKeyRepoAdapter) UpdateLastUsed(ctx context.Context, keyID string) er
 	return a.repo.UpdateLastUsed(ctx, keyID)
 }
 
+func (a *apiKeyRepoAdapter) Rotate(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+	key, plainKey, err := a.repo.Rotate(ctx, keyID, previousValidUntil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return mapRepoKeyToAPIKey(key), plainKey, nil
+}
+
 func (a *apiKeyRepoAdapter) ListAll(ctx context.Context) ([]*APIKey, error) {
 	keys, err := a.repo.ListAll(ctx)
 	if err != nil {
@@ -168,6 +179,24 @@ type CreateAPIKeyResponse struct {
 	Message   string     `json:"message"`
 }
 
+// RotateAPIKeyRequest represents the optional rotate API key request body
+type RotateAPIKeyRequest struct {
+	// Minutes the old key keeps working after rotation (0 = revoked immediately, max 7 days)
+	GracePeriodMinutes int `json:"grace_period_minutes,omitempty" binding:"min=0,max=10080"`
+}
+
+// RotateAPIKeyResponse represents the rotate API key response
+// Note: The new key is only returned once!
+type RotateAPIKeyResponse struct {
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Key                  string     `json:"key"` // Only returned on rotation
+	KeyPrefix            string     `json:"key_prefix"`
+	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"` // Set when a grace period was requested
+	Message              string     `json:"message"`
+}
+
 // APIKeyInfo represents API key information (without the actual key)
 type APIKeyInfo struct {
 	ID             string     `json:"id"`
@@ -418,6 +447,105 @@ func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
 	})
 }
 
+// RotateAPIKey handles POST /api/v1/api-keys/:id/rotate
+// Replaces the key's secret while keeping its name, scopes and owner. Only the key's
+// owner or an admin may rotate it.
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "Not authenticated",
+		})
+		return
+	}
+
+	keyID := c.Param("id")
+	if keyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_error",
+			"message": "API key ID is required",
+		})
+		return
+	}
+
+	// The body is optional; an empty one rotates without a grace period
+	var req RotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_error",
+			"message": "Invalid request body. grace_period_minutes must be between 0 and 10080.",
+		})
+		return
+	}
+
+	key, err := h.apiKeyRepo.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": "API key not found",
+			})
+			return
+		}
+		h.logger.Error().Err(err).Str("key_id", keyID).Msg("Failed to get API key")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to rotate API key",
+		})
+		return
+	}
+
+	// Verify ownership; admins may rotate any key
+	if key.UserID != userID && !slices.Contains(middleware.GetUserRoles(c), "admin") {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "API key not found",
+		})
+		return
+	}
+
+	var previousValidUntil *time.Time
+	if req.GracePeriodMinutes > 0 {
+		until := time.Now().Add(time.Duration(req.GracePeriodMinutes) * time.Minute)
+		previousValidUntil = &until
+	}
+
+	rotated, plainKey, err := h.apiKeyRepo.Rotate(c.Request.Context(), keyID, previousValidUntil)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": "API key not found",
+			})
+			return
+		}
+		h.logger.Error().Err(err).Str("key_id", keyID).Msg("Failed to rotate API key")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to rotate API key",
+		})
+		return
+	}
+
+	h.logger.Info().
+		Str("user_id", userID).
+		Str("key_id", rotated.ID).
+		Str("owner_id", rotated.UserID).
+		Int("grace_period_minutes", req.GracePeriodMinutes).
+		Msg("API key rotated")
+
+	c.JSON(http.StatusOK, RotateAPIKeyResponse{
+		ID:                   rotated.ID,
+		Name:                 rotated.Name,
+		Key:                  plainKey,
+		KeyPrefix:            rotated.KeyPrefix,
+		ExpiresAt:            rotated.ExpiresAt,
+		PreviousKeyExpiresAt: previousValidUntil,
+		Message:              "Save this key securely. It will not be shown again.",
+	})
+}
+
 // AdminAPIKeyInfo represents API key information for admin view (includes user info)
 type AdminAPIKeyInfo struct {
 	ID         string     `json:"id"`
@@ -27,6 +27,7 @@ type mockAPIKeyRepo struct {
 	getByIDFunc    func(ctx context.Context, keyID string) (*APIKey, error)
 	listByUserFunc func(ctx context.Context, userID string) ([]*APIKey, error)
 	deleteFunc     func(ctx context.Context, keyID, userID string) error
+	rotateFunc     func(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error)
 }
 
 func newMockAPIKeyRepo() *mockAPIKeyRepo {
@@ -125,6 +126,19 @@ func (m *mockAPIKeyRepo) AdminDelete(ctx context.Context, keyID string) error {
 	return nil
 }
 
+func (m *mockAPIKeyRepo) Rotate(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+	if m.rotateFunc != nil {
+		return m.rotateFunc(ctx, keyID, previousValidUntil)
+	}
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, "", domain.ErrAPIKeyNotFound
+	}
+	key.KeyPrefix = "mcpgw_new1****abcd"
+
+	return key, "mcpgw_rotatedkey456", nil
+}
+
 // ======================== Tests ========================
 
 func TestNewAPIKeyHandler(t *testing.T) {
@@ -635,3 +649,147 @@ func TestAPIKeyHandler_AdminDeleteAPIKey(t *testing.T) {
 		assert.Equal(t, "API key deleted successfully", response["message"])
 	})
 }
+
+func TestAPIKeyHandler_RotateAPIKey(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	newRotateContext := func(body string, userID string, roles []string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/api-keys/key-123/rotate", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "key-123"}}
+		if userID != "" {
+			c.Set(middleware.ContextKeyUserID, userID)
+		}
+		if roles != nil {
+			c.Set(middleware.ContextKeyUserRoles, roles)
+		}
+		return c, w
+	}
+
+	newRepo := func() *mockAPIKeyRepo {
+		mockRepo := newMockAPIKeyRepo()
+		mockRepo.keys["key-123"] = &APIKey{
+			ID:        "key-123",
+			UserID:    "user-123",
+			Name:      "CI Key",
+			KeyPrefix: "mcpgw_old1****abcd",
+			Scopes:    []string{"gateway:execute"},
+		}
+		return mockRepo
+	}
+
+	t.Run("unauthorized - no user ID", func(t *testing.T) {
+		handler := NewAPIKeyHandlerWithInterface(newRepo(), log)
+		c, w := newRotateContext("", "", nil)
+
+		handler.RotateAPIKey(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("owner rotates without grace period", func(t *testing.T) {
+		mockRepo := newRepo()
+		var gotValidUntil *time.Time
+		mockRepo.rotateFunc = func(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+			gotValidUntil = previousValidUntil
+			key := *mockRepo.keys[keyID]
+			key.KeyPrefix = "mcpgw_new1****abcd"
+			return &key, "mcpgw_rotatedkey456", nil
+		}
+		handler := NewAPIKeyHandlerWithInterface(mockRepo, log)
+		c, w := newRotateContext("", "user-123", []string{"user"})
+
+		handler.RotateAPIKey(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, gotValidUntil, "old key is revoked immediately")
+
+		var response RotateAPIKeyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "key-123", response.ID)
+		assert.Equal(t, "CI Key", response.Name)
+		assert.Equal(t, "mcpgw_rotatedkey456", response.Key)
+		assert.Equal(t, "mcpgw_new1****abcd", response.KeyPrefix)
+		assert.Nil(t, response.PreviousKeyExpiresAt)
+	})
+
+	t.Run("grace period keeps the old key valid", func(t *testing.T) {
+		mockRepo := newRepo()
+		var gotValidUntil *time.Time
+		mockRepo.rotateFunc = func(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+			gotValidUntil = previousValidUntil
+			return mockRepo.keys[keyID], "mcpgw_rotatedkey456", nil
+		}
+		handler := NewAPIKeyHandlerWithInterface(mockRepo, log)
+		c, w := newRotateContext(`{"grace_period_minutes": 30}`, "user-123", nil)
+
+		before := time.Now()
+		handler.RotateAPIKey(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, gotValidUntil)
+		assert.WithinDuration(t, before.Add(30*time.Minute), *gotValidUntil, 5*time.Second)
+
+		var response RotateAPIKeyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.PreviousKeyExpiresAt)
+		assert.WithinDuration(t, *gotValidUntil, *response.PreviousKeyExpiresAt, time.Second)
+	})
+
+	t.Run("invalid grace period", func(t *testing.T) {
+		handler := NewAPIKeyHandlerWithInterface(newRepo(), log)
+		c, w := newRotateContext(`{"grace_period_minutes": -5}`, "user-123", nil)
+
+		handler.RotateAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("other user's key is not found", func(t *testing.T) {
+		mockRepo := newRepo()
+		mockRepo.rotateFunc = func(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+			t.Fatal("Rotate must not be called for a key the caller doesn't own")
+			return nil, "", nil
+		}
+		handler := NewAPIKeyHandlerWithInterface(mockRepo, log)
+		c, w := newRotateContext("", "user-456", []string{"operator"})
+
+		handler.RotateAPIKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("admin rotates another user's key", func(t *testing.T) {
+		handler := NewAPIKeyHandlerWithInterface(newRepo(), log)
+		c, w := newRotateContext("", "admin-1", []string{"admin"})
+
+		handler.RotateAPIKey(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "mcpgw_rotatedkey456")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewAPIKeyHandlerWithInterface(newMockAPIKeyRepo(), log)
+		c, w := newRotateContext("", "user-123", nil)
+
+		handler.RotateAPIKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := newRepo()
+		mockRepo.rotateFunc = func(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+			return nil, "", errors.New("database error")
+		}
+		handler := NewAPIKeyHandlerWithInterface(mockRepo, log)
+		c, w := newRotateContext("", "user-123", nil)
+
+		handler.RotateAPIKey(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// AuditHandler handles audit log queries
+type AuditHandler struct {
+	service AuditLogServiceInterface
+	logger  logger.Logger
+}
+
+// NewAuditHandler creates a new audit log handler
+func NewAuditHandler(service *audit.Service, log logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// NewAuditHandlerWithInterface creates a new audit log handler with interface (for testing).
+func NewAuditHandlerWithInterface(service AuditLogServiceInterface, log logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListAuditLogs handles GET /api/v1/audit-logs
+//
+// Query parameters, all optional: user_id, server_id, method, status_min and status_max
+// (inclusive response status range), access_denied (true for requests an access check
+// rejected) and deny_reason, from and to (RFC 3339 time window), limit (1-500, default
+// 100) and cursor (the next_cursor of the previous page).
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	filter := domain.AuditLogFilter{Limit: 100}
+
+	if userID := c.Query("user_id"); userID != "" {
+		filter.UserID = &userID
+	}
+	if serverID := c.Query("server_id"); serverID != "" {
+		filter.ServerID = &serverID
+	}
+	if method := c.Query("method"); method != "" {
+		filter.Method = &method
+	}
+
+	if deniedStr := c.Query("access_denied"); deniedStr != "" {
+		denied, err := strconv.ParseBool(deniedStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access_denied parameter (must be true or false)"})
+			return
+		}
+		filter.AccessDenied = &denied
+	}
+	if reason := c.Query("deny_reason"); reason != "" {
+		filter.DenyReason = &reason
+	}
+
+	var err error
+	if filter.MinStatus, err = statusQuery(c, "status_min"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.MaxStatus, err = statusQuery(c, "status_max"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.MinStatus != nil && filter.MaxStatus != nil && *filter.MinStatus > *filter.MaxStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status_min must not exceed status_max"})
+		return
+	}
+
+	if filter.FromDate, err = timeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.ToDate, err = timeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.FromDate != nil && filter.ToDate != nil && filter.FromDate.After(*filter.ToDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > audit.MaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid limit parameter (must be 1-500)",
+			})
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	logs, next, err := h.service.Query(c.Request.Context(), filter, c.Query("cursor"))
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cursor parameter",
+			})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Failed to list audit logs")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit_logs":  logs,
+		"count":       len(logs),
+		"next_cursor": next,
+	})
+}
+
+// statusQuery parses an optional HTTP status query parameter
+func statusQuery(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	status, err := strconv.Atoi(value)
+	if err != nil || status < 100 || status > 599 {
+		return nil, fmt.Errorf("invalid %s parameter (must be an HTTP status 100-599)", name)
+	}
+	return &status, nil
+}
+
+// timeQuery parses an optional RFC 3339 time query parameter
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter (must be an RFC 3339 time)", name)
+	}
+	return &t, nil
+}
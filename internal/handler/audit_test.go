@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// mockAuditLogRepository serves audit logs, newest first, applying filters in memory
+type mockAuditLogRepository struct {
+	logs    []*domain.AuditLog
+	filters []domain.AuditLogFilter
+	err     error
+}
+
+func (m *mockAuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
+	return nil
+}
+
+func (m *mockAuditLogRepository) Get(ctx context.Context, id string) (*domain.AuditLog, error) {
+	return nil, errors.New("not found")
+}
+
+func (m *mockAuditLogRepository) List(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLog, error) {
+	m.filters = append(m.filters, filter)
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	logs := []*domain.AuditLog{}
+	for _, log := range m.logs {
+		switch {
+		case filter.UserID != nil && (log.UserID == nil || *log.UserID != *filter.UserID):
+		case filter.MinStatus != nil && *log.ResponseStatus < *filter.MinStatus:
+		case filter.MaxStatus != nil && *log.ResponseStatus > *filter.MaxStatus:
+		case filter.After != nil && !log.CreatedAt.Before(filter.After.CreatedAt):
+		default:
+			logs = append(logs, log)
+		}
+		if len(logs) == filter.Limit {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// newAuditLogs returns n audit logs a minute apart, newest first, alternating users and
+// between 200 and 500 responses
+func newAuditLogs(n int) []*domain.AuditLog {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logs := make([]*domain.AuditLog, n)
+	for i := range logs {
+		userID := []string{"user-1", "user-2"}[i%2]
+		status := []int{200, 500}[i%2]
+		latency := 10 + i
+		logs[i] = &domain.AuditLog{
+			ID:             fmt.Sprintf("log-%d", i),
+			UserID:         &userID,
+			Method:         "POST",
+			Path:           "/api/v1/gateway/server-1",
+			ResponseStatus: &status,
+			LatencyMS:      &latency,
+			IPAddress:      "10.0.0.1",
+			CreatedAt:      start.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return logs
+}
+
+type auditLogsResponse struct {
+	AuditLogs []struct {
+		ID        string `json:"id"`
+		UserID    string `json:"user_id"`
+		LatencyMS int    `json:"latency_ms"`
+		IPAddress string `json:"ip_address"`
+	} `json:"audit_logs"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func listAuditLogs(t *testing.T, h *AuditHandler, query url.Values) (int, auditLogsResponse) {
+	t.Helper()
+
+	c, w := createTestContext("GET", "/api/v1/audit-logs?"+query.Encode(), nil)
+	h.ListAuditLogs(c)
+
+	var resp auditLogsResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestAuditHandler_ListAuditLogs(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	t.Run("pages through every matching log with the cursor", func(t *testing.T) {
+		repo := &mockAuditLogRepository{logs: newAuditLogs(5)}
+		h := NewAuditHandler(audit.NewService(repo, log), log)
+
+		var ids []string
+		query := url.Values{"limit": {"2"}}
+		for page := 0; ; page++ {
+			require.Less(t, page, 5, "pagination should end")
+			code, resp := listAuditLogs(t, h, query)
+			require.Equal(t, http.StatusOK, code)
+			for _, entry := range resp.AuditLogs {
+				ids = append(ids, entry.ID)
+			}
+			if resp.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", resp.NextCursor)
+		}
+
+		assert.Equal(t, []string{"log-0", "log-1", "log-2", "log-3", "log-4"}, ids)
+	})
+
+	t.Run("returns latency and IP address", func(t *testing.T) {
+		repo := &mockAuditLogRepository{logs: newAuditLogs(1)}
+		h := NewAuditHandler(audit.NewService(repo, log), log)
+
+		code, resp := listAuditLogs(t, h, url.Values{})
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.AuditLogs, 1)
+		assert.Equal(t, 10, resp.AuditLogs[0].LatencyMS)
+		assert.Equal(t, "10.0.0.1", resp.AuditLogs[0].IPAddress)
+		assert.Empty(t, resp.NextCursor)
+	})
+
+	t.Run("passes filters to the repository", func(t *testing.T) {
+		repo := &mockAuditLogRepository{logs: newAuditLogs(6)}
+		h := NewAuditHandler(audit.NewService(repo, log), log)
+
+		code, resp := listAuditLogs(t, h, url.Values{
+			"user_id":    {"user-2"},
+			"server_id":  {"server-1"},
+			"method":     {"POST"},
+			"status_min": {"500"},
+			"status_max": {"599"},
+			"from":       {"2025-01-01T00:00:00Z"},
+			"to":         {"2025-01-02T00:00:00Z"},
+		})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 3, resp.Count)
+		for _, entry := range resp.AuditLogs {
+			assert.Equal(t, "user-2", entry.UserID)
+		}
+
+		require.Len(t, repo.filters, 1)
+		filter := repo.filters[0]
+		assert.Equal(t, "user-2", *filter.UserID)
+		assert.Equal(t, "server-1", *filter.ServerID)
+		assert.Equal(t, "POST", *filter.Method)
+		assert.Equal(t, 500, *filter.MinStatus)
+		assert.Equal(t, 599, *filter.MaxStatus)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *filter.FromDate)
+		assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), *filter.ToDate)
+		assert.Nil(t, filter.After)
+		assert.Nil(t, filter.AccessDenied)
+	})
+
+	t.Run("filters access denials", func(t *testing.T) {
+		repo := &mockAuditLogRepository{}
+		h := NewAuditHandler(audit.NewService(repo, log), log)
+
+		code, _ := listAuditLogs(t, h, url.Values{
+			"access_denied": {"true"},
+			"deny_reason":   {domain.DenyReasonScope},
+		})
+		require.Equal(t, http.StatusOK, code)
+
+		require.Len(t, repo.filters, 1)
+		assert.True(t, *repo.filters[0].AccessDenied)
+		assert.Equal(t, domain.DenyReasonScope, *repo.filters[0].DenyReason)
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"limit": {"0"}},
+			{"limit": {"501"}},
+			{"status_min": {"abc"}},
+			{"status_max": {"600"}},
+			{"status_min": {"500"}, "status_max": {"400"}},
+			{"from": {"yesterday"}},
+			{"from": {"2025-01-02T00:00:00Z"}, "to": {"2025-01-01T00:00:00Z"}},
+			{"cursor": {"not-a-cursor"}},
+			{"access_denied": {"maybe"}},
+		} {
+			repo := &mockAuditLogRepository{}
+			h := NewAuditHandler(audit.NewService(repo, log), log)
+
+			code, _ := listAuditLogs(t, h, query)
+			assert.Equal(t, http.StatusBadRequest, code, query.Encode())
+			assert.Empty(t, repo.filters, query.Encode())
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := &mockAuditLogRepository{err: errors.New("database error")}
+		h := NewAuditHandler(audit.NewService(repo, log), log)
+
+		code, _ := listAuditLogs(t, h, url.Values{})
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+}
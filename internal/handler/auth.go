@@ -16,8 +16,9 @@ import (
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	userRepo UserRepositoryInterface
-	logger   logger.Logger
+	userRepo     UserRepositoryInterface
+	sessionStore middleware.SessionStore
+	logger       logger.Logger
 }
 
 // NewAuthHandler creates a new auth handler
@@ -41,6 +42,12 @@ func NewAuthHandlerWithInterface(userRepo UserRepositoryInterface, log logger.Lo
 	}
 }
 
+// SetSessionStore makes logins tracked server-side, so logout revokes the session
+// instead of only clearing the cookie
+func (h *AuthHandler) SetSessionStore(store middleware.SessionStore) {
+	h.sessionStore = store
+}
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -124,6 +131,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	session.Set(middleware.ContextKeyUserEmail, user.Email)
 	session.Set(middleware.ContextKeyUserRoles, roles)
 
+	if err := middleware.StartSession(c, h.sessionStore, user.ID); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to start session")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "session_error",
+			"message": "Failed to create session",
+		})
+		return
+	}
+
 	if err := session.Save(); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to save session")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -160,6 +176,11 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 			Msg("User logged out")
 	}
 
+	// Revoke the server-side session so a copy of the cookie can't be reused
+	if err := middleware.EndSession(c, h.sessionStore); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to revoke session")
+	}
+
 	// Clear session
 	session.Clear()
 	// Must include Path to match the original cookie settings, otherwise cookie won't be deleted
@@ -378,6 +378,53 @@ func TestAuthHandler_Logout(t *testing.T) {
 	})
 }
 
+func TestAuthHandler_LogoutRevokesServerSideSession(t *testing.T) {
+	log := logger.NewNopLogger()
+	mockRepo := newMockUserRepo()
+	user := createTestUser("user-123", "test@example.com", "password123", true)
+	mockRepo.users["user-123"] = user
+	mockRepo.usersByEmail["test@example.com"] = user
+	mockRepo.roles["user-123"] = []string{"user"}
+
+	store := middleware.NewMemorySessionStore(time.Hour)
+	handler := NewAuthHandlerWithInterface(mockRepo, log)
+	handler.SetSessionStore(store)
+	router := setupAuthTestRouter(handler)
+	router.POST("/api/v1/auth/login", handler.Login)
+	router.POST("/api/v1/auth/logout", handler.Logout)
+	router.GET("/protected", middleware.SessionAuth(&middleware.AuthConfig{Logger: log, SessionStore: store}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(method, path, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	login := request("POST", "/api/v1/auth/login", `{"email": "test@example.com", "password": "password123"}`, nil)
+	require.Equal(t, http.StatusOK, login.Code)
+	cookies := login.Result().Cookies()
+
+	assert.Equal(t, http.StatusOK, request("GET", "/protected", "", cookies).Code)
+	sessionsBefore, err := store.ListByUser(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.Len(t, sessionsBefore, 1)
+
+	assert.Equal(t, http.StatusOK, request("POST", "/api/v1/auth/logout", "", cookies).Code)
+
+	// Replaying the cookie captured before logout no longer grants access
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/protected", "", cookies).Code)
+	sessionsAfter, err := store.ListByUser(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.Empty(t, sessionsAfter)
+}
+
 func TestAuthHandler_GetCurrentUser(t *testing.T) {
 	log := logger.NewNopLogger()
 
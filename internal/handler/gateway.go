@@ -1,10 +1,10 @@
 package handler
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/internal/handler/middleware"
 	"github.com/waffles/waffles/internal/service/gateway"
@@ -66,9 +67,31 @@ type ToolCallParams struct {
 type GatewayHandler struct {
 	service       GatewayServiceInterface
 	accessService ServerAccessServiceInterface
+	requests      *gateway.RequestLimiter  // Per-server and per-tool requests per minute
+	timeoutHints  config.TimeoutHintConfig // Bounds on client _meta timeout hints (zero = ignore hints)
 	logger        logger.Logger
+
+	maxRequestBytes  int64 // Largest tools/call body accepted from clients (0 = unlimited)
+	maxResponseBytes int64 // Response size limit for servers without their own (0 = unlimited)
+	maxBatchSize     int   // Batch size limit for servers without their own (0 = unlimited)
+
+	legacySSE       *legacySSESessions     // Streams open on the legacy SSE endpoint
+	legacySSEConfig config.LegacySSEConfig // Keepalive and queue size of legacy SSE streams
+
+	errorNormalization config.ErrorNormalizationConfig // Shape of errors returned for failed upstream calls
 }
 
+// rateLimitedErrorCode is the JSON-RPC error code returned when a server's request limit is exceeded
+const rateLimitedErrorCode = gateway.CodeRateLimited
+
+// invalidRequestErrorCode is the JSON-RPC error code for a request the gateway refuses
+// to forward as sent, such as an oversized batch
+const invalidRequestErrorCode = -32600
+
+// payloadTooLargeErrorCode is the JSON-RPC error code returned when a request or upstream
+// response body exceeds its size limit
+const payloadTooLargeErrorCode = gateway.CodePayloadTooLarge
+
 // NewGatewayHandler creates a new gateway handler
 func NewGatewayHandler(service *gateway.Service, accessService *serveraccess.Service, log logger.Logger) *GatewayHandler {
 	var svc GatewayServiceInterface
@@ -84,16 +107,38 @@ func NewGatewayHandler(service *gateway.Service, accessService *serveraccess.Ser
 	return &GatewayHandler{
 		service:       svc,
 		accessService: accessSvc,
+		requests:      gateway.NewRequestLimiter(),
+		legacySSE:     newLegacySSESessions(),
 		logger:        log,
+
+		maxResponseBytes: gateway.DefaultMaxResponseBytes,
+		maxBatchSize:     gateway.DefaultMaxBatchSize,
 	}
 }
 
+// NewGatewayHandlerWithConfig creates a new gateway handler that applies gateway configuration
+func NewGatewayHandlerWithConfig(service *gateway.Service, accessService *serveraccess.Service, log logger.Logger, cfg config.GatewayConfig) *GatewayHandler {
+	h := NewGatewayHandler(service, accessService, log)
+	h.timeoutHints = cfg.TimeoutHints
+	h.maxRequestBytes = cfg.MaxRequestBytes
+	h.maxResponseBytes = cfg.MaxResponseBytes
+	h.maxBatchSize = cfg.MaxBatchSize
+	h.legacySSEConfig = cfg.LegacySSE
+	h.errorNormalization = cfg.ErrorNormalization
+	return h
+}
+
 // NewGatewayHandlerWithInterface creates a new gateway handler with interfaces (for testing).
 func NewGatewayHandlerWithInterface(service GatewayServiceInterface, accessService ServerAccessServiceInterface, log logger.Logger) *GatewayHandler {
 	return &GatewayHandler{
 		service:       service,
 		accessService: accessService,
+		requests:      gateway.NewRequestLimiter(),
+		legacySSE:     newLegacySSESessions(),
 		logger:        log,
+
+		maxResponseBytes: gateway.DefaultMaxResponseBytes,
+		maxBatchSize:     gateway.DefaultMaxBatchSize,
 	}
 }
 
@@ -126,22 +171,78 @@ func (a *gatewayServiceAdapter) CallStreamableHTTP(ctx context.Context, serverID
 	return a.service.CallStreamableHTTP(ctx, serverID, method, params)
 }
 
+func (a *gatewayServiceAdapter) CallStreamableHTTPWithProgress(ctx context.Context, serverID, method string, params map[string]interface{}, onProgress func(json.RawMessage)) (json.RawMessage, error) {
+	return a.service.CallStreamableHTTPWithProgress(ctx, serverID, method, params, onProgress)
+}
+
+func (a *gatewayServiceAdapter) HTTPClient(server *domain.MCPServer) *http.Client {
+	return a.service.HTTPClient(server)
+}
+
 func (a *gatewayServiceAdapter) InitializeStreamableHTTP(ctx context.Context, serverID string) (*MCPSession, error) {
 	session, err := a.service.InitializeStreamableHTTP(ctx, serverID)
 	if err != nil {
 		return nil, err
 	}
+	return toMCPSession(session), nil
+}
+
+func (a *gatewayServiceAdapter) CallWebSocket(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error) {
+	return a.service.CallWebSocket(ctx, serverID, method, params)
+}
+
+func (a *gatewayServiceAdapter) InitializeWebSocket(ctx context.Context, serverID string) (*MCPSession, error) {
+	session, err := a.service.InitializeWebSocket(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	return toMCPSession(session), nil
+}
 
+// toMCPSession converts a gateway session to the handler's view of it
+func toMCPSession(session *gateway.MCPSession) *MCPSession {
 	return &MCPSession{
 		SessionID:       session.SessionID,
 		ProtocolVersion: session.ProtocolVersion,
-	}, nil
+		ServerName:      session.ServerInfo.Name,
+		ServerVersion:   session.ServerInfo.Version,
+		Instructions:    session.Instructions,
+		Capabilities:    session.Capabilities,
+	}
 }
 
 func (a *gatewayServiceAdapter) TerminateStreamableHTTP(ctx context.Context, serverID string) error {
 	return a.service.TerminateStreamableHTTP(ctx, serverID)
 }
 
+func (a *gatewayServiceAdapter) CheckToolCall(ctx context.Context, serverID, toolName string) error {
+	return a.service.CheckToolCall(ctx, serverID, toolName)
+}
+
+func (a *gatewayServiceAdapter) RecordToolsList(serverID string, result json.RawMessage) {
+	a.service.RecordToolsList(serverID, result)
+}
+
+func (a *gatewayServiceAdapter) SubscribeResource(ctx context.Context, serverID, uri string) (ResourceSubscription, error) {
+	sub, err := a.service.SubscribeResource(ctx, serverID, uri)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (a *gatewayServiceAdapter) SubscribeElicitations(ctx context.Context, serverID string) (ElicitationSubscription, error) {
+	sub, err := a.service.SubscribeElicitations(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (a *gatewayServiceAdapter) RespondElicitation(ctx context.Context, serverID string, id, result json.RawMessage, rpcErr *gateway.JSONRPCError) error {
+	return a.service.RespondElicitation(ctx, serverID, id, result, rpcErr)
+}
+
 // ProxyRequest is a catch-all handler that proxies requests to MCP servers
 func (h *GatewayHandler) ProxyRequest(c *gin.Context) {
 	serverID := c.Param("server_id")
@@ -168,6 +269,12 @@ func (h *GatewayHandler) ProxyRequest(c *gin.Context) {
 		Str("path", c.Request.URL.Path).
 		Msg("Proxying request to MCP server")
 
+	mcpReq, _ := peekMCPRequest(c)
+	if !h.allowRequest(c, server, requestToolNames(c, mcpReq), mcpReq.ID) {
+		return
+	}
+	defer h.withProxyTimeout(c, server, mcpReq.Params)()
+
 	// Forward the request using the reverse proxy
 	proxy.ServeHTTP(c.Writer, c.Request)
 }
@@ -185,24 +292,8 @@ func (h *GatewayHandler) MCPProxy(c *gin.Context) {
 		Str("accept", c.GetHeader("Accept")).
 		Msg("MCP Proxy request received")
 
-	// Check execute-level access if access service is configured
-	if h.accessService != nil {
-		roles := middleware.GetUserRoles(c)
-		canExecute, err := h.accessService.CanAccessServer(c.Request.Context(), roles, serverID, domain.AccessLevelExecute)
-		if err != nil {
-			h.logger.Error().Err(err).Str("server_id", serverID).Any("roles", roles).Msg("Failed to check server execute access")
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to check server access",
-			})
-			return
-		}
-		if !canExecute {
-			h.logger.Warn().Str("server_id", serverID).Any("roles", roles).Msg("Execute access denied to server")
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "You don't have execute permission for this server",
-			})
-			return
-		}
+	if !h.checkExecuteAccess(c, serverID) {
+		return
 	}
 
 	// Get the server info to check allowed tools
@@ -227,8 +318,27 @@ func (h *GatewayHandler) MCPProxy(c *gin.Context) {
 		return
 	}
 
-	// If no tool filtering, use simple proxy
-	if len(server.AllowedTools) == 0 {
+	// Reject oversized batches before any request in them reaches the backend
+	if h.rejectOversizedBatch(c, server) {
+		return
+	}
+
+	// Reject calls to tools the backend never advertised without a round trip
+	if h.rejectUnknownToolCall(c, server) {
+		return
+	}
+
+	mcpReq, _ := peekMCPRequest(c)
+	if !h.allowRequest(c, server, requestToolNames(c, mcpReq), mcpReq.ID) {
+		return
+	}
+	if mcpReq.Method == "initialize" && !server.AllowsElicitation() {
+		stripElicitationCapability(c)
+	}
+	defer h.withProxyTimeout(c, server, mcpReq.Params)()
+
+	// If no tool filtering or renaming, use simple proxy
+	if !server.RestrictsTools() && server.ToolPrefix == "" {
 		h.proxySimple(c, serverID, server)
 		return
 	}
@@ -237,6 +347,211 @@ func (h *GatewayHandler) MCPProxy(c *gin.Context) {
 	h.proxyWithToolFiltering(c, serverID, server)
 }
 
+// checkExecuteAccess checks the caller has execute-level access to the server, when an
+// access service is configured. Returns false if the request was rejected.
+func (h *GatewayHandler) checkExecuteAccess(c *gin.Context, serverID string) bool {
+	if h.accessService == nil {
+		return true
+	}
+	roles := middleware.GetUserRoles(c)
+	canExecute, err := h.accessService.CanAccessServer(c.Request.Context(), roles, serverID, domain.AccessLevelExecute)
+	if err != nil {
+		h.logger.Error().Err(err).Str("server_id", serverID).Any("roles", roles).Msg("Failed to check server execute access")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check server access",
+		})
+		return false
+	}
+	if !canExecute {
+		h.logger.Warn().Str("server_id", serverID).Any("roles", roles).Msg("Execute access denied to server")
+		middleware.RecordAccessDenied(c, domain.DenyReasonServerAccess, "server:"+serverID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You don't have execute permission for this server",
+		})
+		return false
+	}
+	return true
+}
+
+// rejectUnknownToolCall answers a tools/call for a tool missing from the server's cached
+// tools list with a -32601 error. Returns true if the request was handled.
+// The request body is restored for the caller when the request is not rejected.
+func (h *GatewayHandler) rejectUnknownToolCall(c *gin.Context, server *domain.MCPServer) bool {
+	mcpReq, ok := peekMCPRequest(c)
+	if !ok || mcpReq.Method != "tools/call" {
+		return false
+	}
+	var params ToolCallParams
+	if err := json.Unmarshal(mcpReq.Params, &params); err != nil {
+		return false
+	}
+	upstreamName, ok := server.UpstreamToolName(params.Name)
+	if !ok {
+		return false // Rejected with a clearer error once the prefix is checked
+	}
+
+	if err := h.service.CheckToolCall(c.Request.Context(), server.ID, upstreamName); err != nil {
+		h.sendMCPError(c, mcpReq.ID, -32601, fmt.Sprintf("Tool '%s' not found", params.Name))
+		return true
+	}
+	return false
+}
+
+// rejectOversizedBatch answers a JSON-RPC batch with more messages than the server's
+// batch limit with a -32600 error. Returns true if the request was handled.
+// The request body is restored for the caller when the request is not rejected.
+func (h *GatewayHandler) rejectOversizedBatch(c *gin.Context, server *domain.MCPServer) bool {
+	limit := gateway.BatchLimit(server, h.maxBatchSize)
+	if limit <= 0 || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return false
+	}
+
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return false
+	}
+	size, ok := gateway.BatchSize(bodyBytes)
+	if !ok || size <= limit {
+		return false
+	}
+
+	h.logger.Warn().
+		Str("server_id", server.ID).
+		Int("batch_size", size).
+		Int("max_batch_size", limit).
+		Msg("Rejected oversized JSON-RPC batch")
+	h.sendMCPError(c, nil, invalidRequestErrorCode, fmt.Sprintf("batch of %d messages exceeds the limit of %d", size, limit))
+	return true
+}
+
+// peekMCPRequest parses a POSTed JSON-RPC request, restoring the body for the caller.
+// Returns false if the request has no body or it isn't JSON-RPC.
+func peekMCPRequest(c *gin.Context) (MCPRequest, bool) {
+	var mcpReq MCPRequest
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return mcpReq, false
+	}
+
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return mcpReq, false
+	}
+	if err := json.Unmarshal(bodyBytes, &mcpReq); err != nil {
+		return MCPRequest{}, false
+	}
+	return mcpReq, true
+}
+
+// toolCallName returns the tool name of a tools/call request, or "" for other methods
+func toolCallName(mcpReq MCPRequest) string {
+	if mcpReq.Method != "tools/call" {
+		return ""
+	}
+	var params ToolCallParams
+	if err := json.Unmarshal(mcpReq.Params, &params); err != nil {
+		return ""
+	}
+	return params.Name
+}
+
+// requestToolNames returns the tool each message of the request calls, "" for messages
+// that aren't tool calls: one entry for a single message, one per message of a JSON-RPC
+// batch, so every message in a batch counts against the request limits
+func requestToolNames(c *gin.Context, mcpReq MCPRequest) []string {
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return []string{toolCallName(mcpReq)}
+	}
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return []string{toolCallName(mcpReq)}
+	}
+	size, ok := gateway.BatchSize(bodyBytes)
+	if !ok {
+		return []string{toolCallName(mcpReq)}
+	}
+
+	var messages []json.RawMessage
+	_ = json.Unmarshal(bodyBytes, &messages) // BatchSize parsed it already
+	names := make([]string, size)
+	for i, msg := range messages {
+		var batchReq MCPRequest
+		if json.Unmarshal(msg, &batchReq) == nil {
+			names[i] = toolCallName(batchReq)
+		}
+	}
+	return names
+}
+
+// transportErrorStatus is the HTTP status for a GetTransportType failure: the server
+// couldn't be reached when probing for its transport, or otherwise wasn't found
+func transportErrorStatus(err error) int {
+	if errors.Is(err, gateway.ErrTransportDetectionFailed) {
+		return http.StatusBadGateway
+	}
+	return http.StatusNotFound
+}
+
+// allowRequest enforces the server's request limits for the request's messages, one per
+// entry of toolNames, answering with a JSON-RPC error when one is exceeded. Returns false
+// if the request was rejected.
+func (h *GatewayHandler) allowRequest(c *gin.Context, server *domain.MCPServer, toolNames []string, id interface{}) bool {
+	if server == nil {
+		return true
+	}
+	if err := h.requests.AllowBatch(server, toolNames); err != nil {
+		h.logger.Warn().
+			Err(err).
+			Str("server_id", server.ID).
+			Any("tools", toolNames).
+			Msg("Server request limit exceeded")
+		h.sendMCPError(c, id, rateLimitedErrorCode, err.Error())
+		return false
+	}
+	return true
+}
+
+// timeoutHint returns the client's _meta timeout hint capped by the caller's role limit,
+// or 0 when there is no hint or hints are disabled
+func (h *GatewayHandler) timeoutHint(c *gin.Context, params json.RawMessage) time.Duration {
+	if !h.timeoutHints.Enabled {
+		return 0
+	}
+	hint := gateway.TimeoutHintFromMeta(params)
+	if hint <= 0 {
+		return 0
+	}
+
+	limit := h.timeoutHints.Max
+	var roleLimit time.Duration
+	for _, role := range middleware.GetUserRoles(c) {
+		if max, ok := h.timeoutHints.RoleMax[role]; ok && max > roleLimit {
+			roleLimit = max
+		}
+	}
+	if roleLimit > 0 {
+		limit = roleLimit
+	}
+	if limit > 0 && hint > limit {
+		hint = limit
+	}
+	return hint
+}
+
+// withProxyTimeout bounds the proxied request by the client's timeout hint, if any.
+// The returned func releases the deadline and must be called once the proxy returns.
+func (h *GatewayHandler) withProxyTimeout(c *gin.Context, server *domain.MCPServer, params json.RawMessage) context.CancelFunc {
+	hint := h.timeoutHint(c, params)
+	if hint <= 0 || server == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), gateway.ClampTimeoutHint(server, hint))
+	c.Request = c.Request.WithContext(ctx)
+	return cancel
+}
+
 // proxySimple forwards requests without any filtering
 func (h *GatewayHandler) proxySimple(c *gin.Context, serverID string, server *domain.MCPServer) {
 	proxy, _, err := h.service.ProxyToServer(c.Request.Context(), serverID)
@@ -282,6 +597,15 @@ func (h *GatewayHandler) proxyWithToolFiltering(c *gin.Context, serverID string,
 		return
 	}
 
+	// A JSON-RPC batch is checked message by message before it's proxied
+	if _, isBatch := gateway.BatchSize(bodyBytes); isBatch {
+		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		if h.resolveBatchToolCalls(c, server, bodyBytes) {
+			h.proxySimple(c, serverID, server)
+		}
+		return
+	}
+
 	// Parse the JSON-RPC request
 	var mcpReq MCPRequest
 	if err := json.Unmarshal(bodyBytes, &mcpReq); err != nil {
@@ -295,38 +619,20 @@ func (h *GatewayHandler) proxyWithToolFiltering(c *gin.Context, serverID string,
 		Str("server_id", serverID).
 		Str("mcp_method", mcpReq.Method).
 		Int("allowed_tools_count", len(server.AllowedTools)).
+		Int("namespace_allowlists", len(server.NamespaceAllowedTools)).
 		Msg("Processing MCP request with tool filtering")
 
-	// Check if this is a tools/call request - reject if tool not allowed
+	// Restore the request body for proxying
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	// For tools/call, reject tools that aren't allowed and strip the tool prefix
 	if mcpReq.Method == "tools/call" {
-		var params ToolCallParams
-		if err := json.Unmarshal(mcpReq.Params, &params); err == nil {
-			if !h.isToolAllowed(params.Name, server.AllowedTools) {
-				h.logger.Warn().
-					Str("server_id", serverID).
-					Str("tool_name", params.Name).
-					Msg("Tool call rejected - not in allowed list")
-
-				// Return JSON-RPC error response
-				c.Header("Content-Type", "text/event-stream")
-				errorResp := MCPResponse{
-					JSONRPC: "2.0",
-					ID:      mcpReq.ID,
-					Error: &MCPError{
-						Code:    -32602,
-						Message: fmt.Sprintf("Tool '%s' is not allowed on this server", params.Name),
-					},
-				}
-				respBytes, _ := json.Marshal(errorResp)
-				writeSSEEvent(c.Writer, respBytes)
-				return
-			}
+		if h.resolveToolCall(c, server) {
+			h.proxySimple(c, serverID, server)
 		}
+		return
 	}
 
-	// Restore the request body for proxying
-	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-
 	// For tools/list, we need to intercept and filter the response
 	if mcpReq.Method == "tools/list" {
 		h.proxyToolsListWithFiltering(c, serverID, server, mcpReq)
@@ -343,8 +649,7 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 	// Build the tools/list request
 	reqBody, _ := json.Marshal(mcpReq)
 
-	// Create HTTP client with timeout
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := h.service.HTTPClient(server)
 
 	// Create request to backend server
 	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", server.URL, bytes.NewReader(reqBody))
@@ -355,7 +660,7 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 
 	// Copy relevant headers from original request
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Accept", gateway.AcceptHeader(server))
 
 	// Forward MCP-specific headers (session ID, protocol version)
 	if sessionID := c.Request.Header.Get("MCP-Session-Id"); sessionID != "" {
@@ -364,24 +669,34 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 	if protocolVersion := c.Request.Header.Get("MCP-Protocol-Version"); protocolVersion != "" {
 		req.Header.Set("MCP-Protocol-Version", protocolVersion)
 	}
+	gateway.SetGatewayHops(req)
+	gateway.SetRequestID(req)
+	gateway.SetPriorityHint(req)
+	gateway.SetTraceContext(req)
 
 	// Send request
 	resp, err := client.Do(req)
 	if err != nil {
 		h.logger.Error().Err(err).Str("server_id", serverID).Msg("Failed to call tools/list")
-		h.sendMCPError(c, mcpReq.ID, -32603, fmt.Sprintf("backend request failed: %v", err))
+		h.sendCallError(c, mcpReq.ID, fmt.Errorf("backend request failed: %w", err))
 		return
 	}
 	defer resp.Body.Close()
+	gateway.LimitResponseBody(resp, gateway.ResponseLimit(server, h.maxResponseBytes))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		h.sendMCPError(c, mcpReq.ID, -32603, fmt.Sprintf("backend returned %d: %s", resp.StatusCode, string(body)))
+		h.sendCallError(c, mcpReq.ID, fmt.Errorf("backend request failed: %w", &gateway.StatusError{StatusCode: resp.StatusCode, Body: string(body)}))
 		return
 	}
 
 	// Read the response body
 	bodyBytes, err := io.ReadAll(resp.Body)
+	if errors.Is(err, gateway.ErrResponseTooLarge) {
+		h.logger.Warn().Str("server_id", serverID).Msg("tools/list response exceeds the size limit")
+		h.sendMCPError(c, mcpReq.ID, payloadTooLargeErrorCode, err.Error())
+		return
+	}
 	if err != nil {
 		h.sendMCPError(c, mcpReq.ID, -32603, fmt.Sprintf("failed to read response: %v", err))
 		return
@@ -414,6 +729,15 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 		return
 	}
 
+	// Cache the unfiltered tool names; later pages of a paginated list are not complete
+	var listParams struct {
+		Cursor string `json:"cursor,omitempty"`
+	}
+	_ = json.Unmarshal(mcpReq.Params, &listParams) // #nosec G104 -- missing params mean first page
+	if listParams.Cursor == "" {
+		h.service.RecordToolsList(serverID, mcpResp.Result)
+	}
+
 	// Parse tools from result
 	var toolsResult ToolsListResult
 	if err := json.Unmarshal(mcpResp.Result, &toolsResult); err != nil {
@@ -424,12 +748,14 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 		return
 	}
 
-	// Filter tools
+	// Filter tools and apply the server's tool prefix
 	filteredTools := make([]MCPTool, 0)
 	for _, tool := range toolsResult.Tools {
-		if h.isToolAllowed(tool.Name, server.AllowedTools) {
-			filteredTools = append(filteredTools, tool)
+		if !server.AllowsTool(tool.Name) {
+			continue
 		}
+		tool.Name = server.ExposedToolName(tool.Name)
+		filteredTools = append(filteredTools, tool)
 	}
 
 	h.logger.Info().
@@ -456,22 +782,20 @@ func (h *GatewayHandler) proxyToolsListWithFiltering(c *gin.Context, serverID st
 // parseSSEResponse parses an SSE-formatted response to extract the JSON-RPC response
 func (h *GatewayHandler) parseSSEResponse(body []byte) (MCPResponse, error) {
 	var mcpResp MCPResponse
-	scanner := bufio.NewScanner(bytes.NewReader(body))
+	reader := gateway.NewSSEReader(bytes.NewReader(body), 0)
 	var lastData string
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "data:") {
-			data := strings.TrimPrefix(line, "data:")
-			data = strings.TrimSpace(data)
-			if data != "" {
-				lastData = data
-			}
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return mcpResp, fmt.Errorf("failed to scan SSE response: %w", err)
+		}
+		if msgs := event.Messages(); len(msgs) > 0 {
+			lastData = string(msgs[len(msgs)-1])
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return mcpResp, fmt.Errorf("failed to scan SSE response: %w", err)
 	}
 
 	if lastData == "" {
@@ -497,14 +821,21 @@ func writeSSEEvent(w io.Writer, data []byte) {
 
 // sendMCPError sends a JSON-RPC error response in SSE format
 func (h *GatewayHandler) sendMCPError(c *gin.Context, id interface{}, code int, message string) {
+	h.writeMCPError(c, id, &MCPError{Code: code, Message: message})
+}
+
+// sendCallError sends the JSON-RPC error for a failed upstream call as an SSE event
+func (h *GatewayHandler) sendCallError(c *gin.Context, id interface{}, err error) {
+	h.writeMCPError(c, id, h.callError(err))
+}
+
+// writeMCPError writes a JSON-RPC error response as an SSE event
+func (h *GatewayHandler) writeMCPError(c *gin.Context, id interface{}, mcpErr *MCPError) {
 	c.Header("Content-Type", "text/event-stream")
 	errorResp := MCPResponse{
 		JSONRPC: "2.0",
 		ID:      id,
-		Error: &MCPError{
-			Code:    code,
-			Message: message,
-		},
+		Error:   mcpErr,
 	}
 	respBytes, _ := json.Marshal(errorResp)
 	writeSSEEvent(c.Writer, respBytes)
@@ -520,11 +851,214 @@ func (h *GatewayHandler) isToolAllowed(toolName string, allowedTools []string) b
 	return false
 }
 
-// Initialize handles MCP initialize endpoint
+// rejectDisallowedTool answers a call to a tool outside the server's allowlist with a -32602 error
+func (h *GatewayHandler) rejectDisallowedTool(c *gin.Context, serverID, toolName string, id interface{}) {
+	h.logger.Warn().
+		Str("server_id", serverID).
+		Str("tool_name", toolName).
+		Msg("Tool call rejected - not in allowed list")
+	h.sendMCPError(c, id, -32602, fmt.Sprintf("Tool '%s' is not allowed on this server", toolName))
+}
+
+// peekCallToolName returns the tool name and JSON-RPC id of a tools/call body, restoring the
+// body for the caller. The body may be a JSON-RPC request or bare tools/call params.
+func peekCallToolName(c *gin.Context) (string, interface{}) {
+	if c.Request.Body == nil {
+		return "", nil
+	}
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", nil
+	}
+
+	var mcpReq MCPRequest
+	if err := json.Unmarshal(bodyBytes, &mcpReq); err == nil && mcpReq.Method != "" {
+		return toolCallName(mcpReq), mcpReq.ID
+	}
+	var params ToolCallParams
+	_ = json.Unmarshal(bodyBytes, &params) // #nosec G104 -- unparseable bodies have no tool name
+	return params.Name, nil
+}
+
+// resolveToolCall maps the tool named in a tools/call body to the upstream tool: the
+// server's tool prefix is stripped and the allowlist is checked against the unprefixed
+// name. Rejected calls are answered with -32602 and false is returned; otherwise the
+// request body carries the upstream tool name.
+func (h *GatewayHandler) resolveToolCall(c *gin.Context, server *domain.MCPServer) bool {
+	toolName, id := peekCallToolName(c)
+	upstreamName, ok := server.UpstreamToolName(toolName)
+	if !ok {
+		h.sendMCPError(c, id, -32602, fmt.Sprintf("Tool '%s' not found: tools on this server are named '%s'",
+			toolName, server.ExposedToolName("<tool>")))
+		return false
+	}
+	if !server.AllowsTool(upstreamName) {
+		h.rejectDisallowedTool(c, server.ID, toolName, id)
+		return false
+	}
+	if upstreamName != toolName {
+		setCallToolName(c, upstreamName)
+	}
+	return true
+}
+
+// resolveBatchToolCalls maps the tools/call messages of a JSON-RPC batch to upstream
+// tools the way resolveToolCall does: the tool prefix is stripped and the server's
+// allowlist and the allowlists of its namespaces are checked. A call that can't be
+// resolved rejects the whole batch with -32602 and false is returned; otherwise the
+// request body carries the upstream tool names.
+func (h *GatewayHandler) resolveBatchToolCalls(c *gin.Context, server *domain.MCPServer, bodyBytes []byte) bool {
+	var messages []json.RawMessage
+	_ = json.Unmarshal(bodyBytes, &messages) // BatchSize parsed it already
+	renamed := false
+	for i, msg := range messages {
+		var batchReq MCPRequest
+		if json.Unmarshal(msg, &batchReq) != nil || batchReq.Method != "tools/call" {
+			continue
+		}
+		toolName := toolCallName(batchReq)
+		upstreamName, ok := server.UpstreamToolName(toolName)
+		if !ok {
+			h.sendMCPError(c, batchReq.ID, -32602, fmt.Sprintf("Tool '%s' not found: tools on this server are named '%s'",
+				toolName, server.ExposedToolName("<tool>")))
+			return false
+		}
+		if !server.AllowsTool(upstreamName) {
+			h.rejectDisallowedTool(c, server.ID, toolName, batchReq.ID)
+			return false
+		}
+		if upstreamName != toolName {
+			messages[i] = withToolName(msg, upstreamName)
+			renamed = true
+		}
+	}
+
+	if renamed {
+		rewritten, _ := json.Marshal(messages) // #nosec G104 -- re-marshaling parsed JSON cannot fail
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+	}
+	return true
+}
+
+// setCallToolName replaces the tool name in a tools/call body, which may be a JSON-RPC
+// request or bare tools/call params. Other fields are preserved.
+func setCallToolName(c *gin.Context, name string) {
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return
+	}
+
+	rewritten := withToolName(bodyBytes, name)
+	c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+	c.Request.ContentLength = int64(len(rewritten))
+}
+
+// withToolName returns a tools/call message, either a JSON-RPC request or bare tools/call
+// params, with its tool name replaced. The message is returned unchanged if it can't be parsed.
+func withToolName(msg []byte, name string) []byte {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &body); err != nil {
+		return msg
+	}
+	nameJSON, _ := json.Marshal(name) // #nosec G104 -- marshaling a string cannot fail
+
+	if _, isRPC := body["method"]; isRPC {
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(body["params"], &params); err != nil {
+			return msg
+		}
+		params["name"] = nameJSON
+		body["params"], _ = json.Marshal(params) // #nosec G104 -- re-marshaling parsed JSON cannot fail
+	} else {
+		body["name"] = nameJSON
+	}
+
+	rewritten, _ := json.Marshal(body) // #nosec G104 -- re-marshaling parsed JSON cannot fail
+	return rewritten
+}
+
+// stripElicitationCapability removes the elicitation capability from a client's initialize
+// request, so a server whose policy denies elicitation never asks the client for input.
+// Other fields are preserved.
+func stripElicitationCapability(c *gin.Context) {
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return
+	}
+
+	var body, params, capabilities map[string]json.RawMessage
+	if json.Unmarshal(bodyBytes, &body) != nil ||
+		json.Unmarshal(body["params"], &params) != nil ||
+		json.Unmarshal(params["capabilities"], &capabilities) != nil {
+		return
+	}
+	if _, ok := capabilities["elicitation"]; !ok {
+		return
+	}
+	delete(capabilities, "elicitation")
+	params["capabilities"], _ = json.Marshal(capabilities)
+	body["params"], _ = json.Marshal(params)
+
+	rewritten, _ := json.Marshal(body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+	c.Request.ContentLength = int64(len(rewritten))
+}
+
+// exposeToolsResult rewrites a tools/list result for clients: tools outside the server's
+// non-empty AllowedTools are removed and names get the server's tool prefix. Every other
+// field of the result and of each tool is kept.
+func exposeToolsResult(result json.RawMessage, server *domain.MCPServer) (json.RawMessage, error) {
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse tools/list result: %w", err)
+	}
+	var tools []map[string]json.RawMessage
+	if raw, ok := page["tools"]; ok {
+		if err := json.Unmarshal(raw, &tools); err != nil {
+			return nil, fmt.Errorf("failed to parse tools/list result: %w", err)
+		}
+	}
+
+	exposed := make([]map[string]json.RawMessage, 0, len(tools))
+	for _, tool := range tools {
+		var name string
+		_ = json.Unmarshal(tool["name"], &name) // #nosec G104 -- tools without a name are never allowed
+		if !server.AllowsTool(name) {
+			continue
+		}
+		tool["name"], _ = json.Marshal(server.ExposedToolName(name)) // #nosec G104 -- marshaling a string cannot fail
+		exposed = append(exposed, tool)
+	}
+
+	filtered, err := json.Marshal(exposed)
+	if err != nil {
+		return nil, err
+	}
+	page["tools"] = filtered
+	return json.Marshal(page)
+}
+
+// Initialize handles MCP initialize endpoint.
+// For Streamable HTTP and WebSocket servers the initialize handshake is performed against
+// the backend and its instructions and serverInfo are forwarded to the client.
 func (h *GatewayHandler) Initialize(c *gin.Context) {
 	serverID := c.Param("server_id")
 
-	server, err := h.service.Initialize(c.Request.Context(), serverID)
+	transport, server, err := h.service.GetTransportType(c.Request.Context(), serverID)
+	if err == nil && transport == domain.TransportStreamableHTTP {
+		h.initializeSession(c, server, h.service.InitializeStreamableHTTP)
+		return
+	}
+	if err == nil && transport == domain.TransportWebSocket {
+		h.initializeSession(c, server, h.service.InitializeWebSocket)
+		return
+	}
+
+	server, err = h.service.Initialize(c.Request.Context(), serverID)
 	if err != nil {
 		h.logger.Error().
 			Err(err).
@@ -545,38 +1079,146 @@ func (h *GatewayHandler) Initialize(c *gin.Context) {
 	})
 }
 
-// ListTools handles tools/list requests (supports HTTP, SSE, and Streamable HTTP servers)
+// initializeSession runs the initialize handshake with a session-based server using
+// initialize and returns the backend's initialize details alongside the gateway's status
+// fields
+func (h *GatewayHandler) initializeSession(c *gin.Context, server *domain.MCPServer, initialize func(ctx context.Context, serverID string) (*MCPSession, error)) {
+	session, err := initialize(c.Request.Context(), server.ID)
+	if err != nil {
+		h.logger.Error().
+			Err(err).
+			Str("server_id", server.ID).
+			Msg("Initialization failed")
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"server_id":        server.ID,
+		"server_name":      server.Name,
+		"url":              server.URL,
+		"status":           "initialized",
+		"session_id":       session.SessionID,
+		"protocol_version": session.ProtocolVersion,
+		"server_info": gin.H{
+			"name":    session.ServerName,
+			"version": session.ServerVersion,
+		},
+	}
+	if session.Instructions != "" {
+		response["instructions"] = session.Instructions
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListTools handles tools/list requests (supports HTTP, SSE, and Streamable HTTP servers).
+// When the server has a non-empty AllowedTools list only those tools are returned;
+// an empty list returns every tool. Allowlists of the server's namespaces apply too. Tool names carry the server's ToolPrefix, if any.
+// A cursor query parameter fetches a later page; the server's nextCursor is returned as is.
 func (h *GatewayHandler) ListTools(c *gin.Context) {
 	serverID := c.Param("server_id")
 
-	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
+	transport, server, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if server != nil && (server.RestrictsTools() || server.ToolPrefix != "") {
+		h.listExposedTools(c, transport, server)
 		return
 	}
 
 	switch transport {
 	case domain.TransportStreamableHTTP:
-		h.handleStreamableHTTPRequest(c, "tools/list", nil)
+		h.handleStreamableHTTPRequest(c, "tools/list", listParams(c))
 	case domain.TransportSSE:
-		h.handleSSERequest(c, "tools/list", nil)
+		h.handleSSERequest(c, "tools/list", listParams(c))
+	case domain.TransportWebSocket:
+		h.handleWebSocketRequest(c, "tools/list", listParams(c))
 	default:
 		h.ProxyRequest(c)
 	}
 }
 
-// CallTool handles tools/call requests (supports HTTP, SSE, and Streamable HTTP servers)
+// listParams returns the params of a list request: the client's cursor query parameter,
+// if any. Plain HTTP servers get the client's JSON-RPC request, cursor included, unchanged.
+func listParams(c *gin.Context) interface{} {
+	cursor := c.Query("cursor")
+	if cursor == "" {
+		return nil
+	}
+	return map[string]interface{}{"cursor": cursor}
+}
+
+// listExposedTools answers tools/list with the server's tools as clients should see them
+func (h *GatewayHandler) listExposedTools(c *gin.Context, transport domain.TransportType, server *domain.MCPServer) {
+	var result json.RawMessage
+	var err error
+	switch transport {
+	case domain.TransportStreamableHTTP:
+		result, err = h.service.CallStreamableHTTP(c.Request.Context(), server.ID, "tools/list", listParams(c))
+	case domain.TransportSSE:
+		result, err = h.service.CallSSE(c.Request.Context(), server.ID, "tools/list", listParams(c))
+	case domain.TransportWebSocket:
+		result, err = h.service.CallWebSocket(c.Request.Context(), server.ID, "tools/list", listParams(c))
+	default:
+		// Plain HTTP servers speak JSON-RPC directly; forward the client's request when it sent one
+		mcpReq, ok := peekMCPRequest(c)
+		if !ok || mcpReq.Method != "tools/list" {
+			mcpReq = MCPRequest{JSONRPC: "2.0", ID: gateway.RequestID(server, 1), Method: "tools/list"}
+		}
+		h.proxyToolsListWithFiltering(c, server.ID, server, mcpReq)
+		return
+	}
+	if err != nil {
+		h.logger.Error().
+			Err(err).
+			Str("server_id", server.ID).
+			Msg("tools/list request failed")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	filtered, err := exposeToolsResult(result, server)
+	if err != nil {
+		h.logger.Error().Err(err).Str("server_id", server.ID).Msg("Failed to filter tools/list result")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/json", filtered)
+}
+
+// CallTool handles tools/call requests (supports HTTP, SSE, and Streamable HTTP servers).
+// When the server has a non-empty AllowedTools list, calls to any other tool are rejected
+// with -32602 before reaching the upstream; an empty list allows every tool. Allowlists of
+// the server's namespaces are enforced the same way. Servers with a ToolPrefix expect
+// prefixed names, which are stripped before forwarding.
 func (h *GatewayHandler) CallTool(c *gin.Context) {
 	serverID := c.Param("server_id")
 
-	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
+	if !h.limitRequestBody(c) {
+		return
+	}
+
+	transport, server, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	if server != nil && (server.RestrictsTools() || server.ToolPrefix != "") {
+		if !h.resolveToolCall(c, server) {
+			return
+		}
+	}
+
 	// For non-HTTP transports, we need to parse the body
-	if transport == domain.TransportStreamableHTTP || transport == domain.TransportSSE {
+	if isJSONRPCTransport(transport) {
 		body, err := io.ReadAll(c.Request.Body)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
@@ -591,9 +1233,24 @@ func (h *GatewayHandler) CallTool(c *gin.Context) {
 			}
 		}
 
-		if transport == domain.TransportStreamableHTTP {
-			h.handleStreamableHTTPRequest(c, "tools/call", params)
-		} else {
+		toolName, _ := params["name"].(string)
+		if !h.allowRequest(c, server, []string{toolName}, nil) {
+			return
+		}
+		if hint := h.timeoutHint(c, body); hint > 0 {
+			c.Request = c.Request.WithContext(gateway.WithTimeoutHint(c.Request.Context(), hint))
+		}
+
+		switch transport {
+		case domain.TransportStreamableHTTP:
+			if _, ok := gateway.ProgressToken(params); ok && strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+				h.handleStreamableHTTPProgress(c, "tools/call", params)
+			} else {
+				h.handleStreamableHTTPRequest(c, "tools/call", params)
+			}
+		case domain.TransportWebSocket:
+			h.handleWebSocketRequest(c, "tools/call", params)
+		default:
 			h.handleSSERequest(c, "tools/call", params)
 		}
 		return
@@ -601,67 +1258,281 @@ func (h *GatewayHandler) CallTool(c *gin.Context) {
 	h.ProxyRequest(c)
 }
 
-// ListResources handles resources/list requests
+// limitRequestBody buffers the request body, answering 413 and returning false when it is
+// larger than maxRequestBytes. Later readers see the buffered body.
+func (h *GatewayHandler) limitRequestBody(c *gin.Context) bool {
+	if h.maxRequestBytes <= 0 || c.Request.Body == nil {
+		return true
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.maxRequestBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("request body exceeds %d bytes", h.maxRequestBytes),
+				"code":  payloadTooLargeErrorCode,
+			})
+			return false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// ListResources handles resources/list requests, passing through the cursor query
+// parameter and the server's nextCursor
 func (h *GatewayHandler) ListResources(c *gin.Context) {
 	serverID := c.Param("server_id")
 
 	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	switch transport {
 	case domain.TransportStreamableHTTP:
-		h.handleStreamableHTTPRequest(c, "resources/list", nil)
+		h.handleStreamableHTTPRequest(c, "resources/list", listParams(c))
 	case domain.TransportSSE:
-		h.handleSSERequest(c, "resources/list", nil)
+		h.handleSSERequest(c, "resources/list", listParams(c))
+	case domain.TransportWebSocket:
+		h.handleWebSocketRequest(c, "resources/list", listParams(c))
 	default:
 		h.ProxyRequest(c)
 	}
 }
 
-// ReadResource handles resources/read requests
+// ReadResource handles resources/read requests. The uri comes from the query string or
+// the request body. The server's result is checked for text and valid base64 blobs and
+// forwarded unchanged; with raw=true, a single content item is returned as its decoded
+// bytes with its MIME type instead.
 func (h *GatewayHandler) ReadResource(c *gin.Context) {
 	serverID := c.Param("server_id")
 
 	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	if transport == domain.TransportStreamableHTTP || transport == domain.TransportSSE {
-		body, _ := io.ReadAll(c.Request.Body)
-		var params map[string]interface{}
-		if len(body) > 0 {
-			_ = json.Unmarshal(body, &params) // #nosec G104 -- parse errors handled via empty params
+	if !isJSONRPCTransport(transport) {
+		h.ProxyRequest(c)
+		return
+	}
+
+	body, _ := io.ReadAll(c.Request.Body)
+	var params map[string]interface{}
+	if len(body) > 0 {
+		_ = json.Unmarshal(body, &params) // #nosec G104 -- parse errors handled via empty params
+	}
+	if uri := c.Query("uri"); uri != "" {
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+		params["uri"] = uri
+	}
+
+	var result json.RawMessage
+	switch transport {
+	case domain.TransportStreamableHTTP:
+		result, err = h.service.CallStreamableHTTP(c.Request.Context(), serverID, "resources/read", params)
+		if err != nil {
+			h.writeStreamableHTTPResult(c, serverID, "resources/read", nil, err)
+			return
 		}
-		if transport == domain.TransportStreamableHTTP {
-			h.handleStreamableHTTPRequest(c, "resources/read", params)
-		} else {
-			h.handleSSERequest(c, "resources/read", params)
+	case domain.TransportWebSocket:
+		result, err = h.service.CallWebSocket(c.Request.Context(), serverID, "resources/read", params)
+		if err != nil {
+			h.writeCallResult(c, "WebSocket", serverID, "resources/read", nil, err)
+			return
+		}
+	default:
+		result, err = h.service.CallSSE(c.Request.Context(), serverID, "resources/read", params)
+		if err != nil {
+			h.writeSSEResult(c, serverID, "resources/read", nil, err)
+			return
 		}
+	}
+
+	contents, err := gateway.ParseReadResourceResult(result)
+	if err != nil {
+		h.logger.Warn().
+			Err(err).
+			Str("server_id", serverID).
+			Msg("Server returned invalid resource contents")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
-	h.ProxyRequest(c)
+
+	if c.Query("raw") != "true" {
+		c.Data(http.StatusOK, "application/json", result)
+		return
+	}
+
+	if len(contents.Contents) != 1 {
+		c.JSON(http.StatusNotAcceptable, gin.H{
+			"error": fmt.Sprintf("raw output needs exactly one content item, the server returned %d", len(contents.Contents)),
+		})
+		return
+	}
+	item := &contents.Contents[0]
+	data, _ := item.Bytes() // Validated by ParseReadResourceResult
+	contentType := item.MimeType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+		if item.IsBinary() {
+			contentType = "application/octet-stream"
+		}
+	}
+	c.Data(http.StatusOK, contentType, data)
 }
 
-// ListPrompts handles prompts/list requests
+// SubscribeResource subscribes to the resource named by the uri query parameter and streams
+// each notifications/resources/updated message for it as an SSE event, until the client
+// disconnects or the server's session is terminated. Streamable HTTP servers only.
+func (h *GatewayHandler) SubscribeResource(c *gin.Context) {
+	serverID := c.Param("server_id")
+	uri := c.Query("uri")
+	if uri == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uri query parameter is required"})
+		return
+	}
+
+	sub, err := h.service.SubscribeResource(c.Request.Context(), serverID, uri)
+	if err != nil {
+		if errors.Is(err, gateway.ErrTooManySubscribers) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error().
+			Err(err).
+			Str("server_id", serverID).
+			Str("uri", uri).
+			Msg("Resource subscription failed")
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer sub.Close()
+
+	streamSSEEvents(c, sub.Updates())
+}
+
+// ElicitationEvents streams each elicitation/create request the server sends as an SSE
+// event, until the client disconnects or the server's session is terminated. The server's
+// elicitation_policy must be allow. Requests are answered with RespondElicitation.
+func (h *GatewayHandler) ElicitationEvents(c *gin.Context) {
+	serverID := c.Param("server_id")
+
+	sub, err := h.service.SubscribeElicitations(c.Request.Context(), serverID)
+	if err != nil {
+		if errors.Is(err, gateway.ErrElicitationDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, gateway.ErrTooManySubscribers) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error().
+			Err(err).
+			Str("server_id", serverID).
+			Msg("Elicitation subscription failed")
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer sub.Close()
+
+	streamSSEEvents(c, sub.Requests())
+}
+
+// RespondElicitation handles the client's answer to a relayed elicitation request. The
+// body is the JSON-RPC response: the request's id with either a result or an error.
+func (h *GatewayHandler) RespondElicitation(c *gin.Context) {
+	serverID := c.Param("server_id")
+
+	var resp struct {
+		ID     json.RawMessage       `json:"id"`
+		Result json.RawMessage       `json:"result"`
+		Error  *gateway.JSONRPCError `json:"error"`
+	}
+	if err := c.ShouldBindJSON(&resp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON-RPC response: " + err.Error()})
+		return
+	}
+	if len(resp.ID) == 0 || (resp.Result == nil) == (resp.Error == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Response needs an id and exactly one of result or error"})
+		return
+	}
+
+	err := h.service.RespondElicitation(c.Request.Context(), serverID, resp.ID, resp.Result, resp.Error)
+	if err != nil {
+		if errors.Is(err, gateway.ErrElicitationNotPending) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error().
+			Err(err).
+			Str("server_id", serverID).
+			Msg("Failed to send elicitation response")
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "sent"})
+}
+
+// streamSSEEvents writes each message from events as an SSE event until events is closed
+// or the client disconnects
+func streamSSEEvents(c *gin.Context, events <-chan json.RawMessage) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSEEvent(c.Writer, msg)
+			c.Writer.Flush()
+		}
+	}
+}
+
+// ListPrompts handles prompts/list requests, passing through the cursor query parameter
+// and the server's nextCursor
 func (h *GatewayHandler) ListPrompts(c *gin.Context) {
 	serverID := c.Param("server_id")
 
 	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	switch transport {
 	case domain.TransportStreamableHTTP:
-		h.handleStreamableHTTPRequest(c, "prompts/list", nil)
+		h.handleStreamableHTTPRequest(c, "prompts/list", listParams(c))
 	case domain.TransportSSE:
-		h.handleSSERequest(c, "prompts/list", nil)
+		h.handleSSERequest(c, "prompts/list", listParams(c))
+	case domain.TransportWebSocket:
+		h.handleWebSocketRequest(c, "prompts/list", listParams(c))
 	default:
 		h.ProxyRequest(c)
 	}
@@ -673,19 +1544,22 @@ func (h *GatewayHandler) GetPrompt(c *gin.Context) {
 
 	transport, _, err := h.service.GetTransportType(c.Request.Context(), serverID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	if transport == domain.TransportStreamableHTTP || transport == domain.TransportSSE {
+	if isJSONRPCTransport(transport) {
 		body, _ := io.ReadAll(c.Request.Body)
 		var params map[string]interface{}
 		if len(body) > 0 {
 			_ = json.Unmarshal(body, &params) // #nosec G104 -- parse errors handled via empty params
 		}
-		if transport == domain.TransportStreamableHTTP {
+		switch transport {
+		case domain.TransportStreamableHTTP:
 			h.handleStreamableHTTPRequest(c, "prompts/get", params)
-		} else {
+		case domain.TransportWebSocket:
+			h.handleWebSocketRequest(c, "prompts/get", params)
+		default:
 			h.handleSSERequest(c, "prompts/get", params)
 		}
 		return
@@ -693,43 +1567,167 @@ func (h *GatewayHandler) GetPrompt(c *gin.Context) {
 	h.ProxyRequest(c)
 }
 
+// isJSONRPCTransport reports whether the gateway speaks JSON-RPC to servers on transport
+// itself, rather than proxying the client's HTTP request
+func isJSONRPCTransport(transport domain.TransportType) bool {
+	switch transport {
+	case domain.TransportStreamableHTTP, domain.TransportSSE, domain.TransportWebSocket:
+		return true
+	}
+	return false
+}
+
 // handleSSERequest handles requests to SSE-based MCP servers (legacy)
 func (h *GatewayHandler) handleSSERequest(c *gin.Context, method string, params interface{}) {
 	serverID := c.Param("server_id")
 
 	result, err := h.service.CallSSE(c.Request.Context(), serverID, method, params)
+	h.writeSSEResult(c, serverID, method, result, err)
+}
+
+// writeSSEResult writes the result of an SSE call, or its error
+func (h *GatewayHandler) writeSSEResult(c *gin.Context, serverID, method string, result json.RawMessage, err error) {
+	h.writeCallResult(c, "SSE", serverID, method, result, err)
+}
+
+// handleStreamableHTTPRequest handles requests to Streamable HTTP MCP servers (MCP 2025-11-25)
+func (h *GatewayHandler) handleStreamableHTTPRequest(c *gin.Context, method string, params interface{}) {
+	serverID := c.Param("server_id")
+
+	result, err := h.service.CallStreamableHTTP(c.Request.Context(), serverID, method, params)
+	h.writeStreamableHTTPResult(c, serverID, method, result, err)
+}
+
+// handleStreamableHTTPProgress handles a Streamable HTTP request carrying a progress token.
+// Once the server reports progress the response becomes an SSE stream: each progress
+// notification is sent as an event, followed by the result. Without progress the response
+// is the same as handleStreamableHTTPRequest's.
+func (h *GatewayHandler) handleStreamableHTTPProgress(c *gin.Context, method string, params map[string]interface{}) {
+	serverID := c.Param("server_id")
+
+	// Only read after the call returns; the service serializes progress callbacks with it
+	streaming := false
+	result, err := h.service.CallStreamableHTTPWithProgress(c.Request.Context(), serverID, method, params, func(notification json.RawMessage) {
+		if !streaming {
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Status(http.StatusOK)
+			streaming = true
+		}
+		writeSSEEvent(c.Writer, notification)
+		c.Writer.Flush()
+	})
+	if !streaming {
+		h.writeStreamableHTTPResult(c, serverID, method, result, err)
+		return
+	}
+
 	if err != nil {
 		h.logger.Error().
 			Err(err).
 			Str("server_id", serverID).
 			Str("method", method).
-			Msg("SSE request failed")
+			Msg("Streamable HTTP request failed")
 
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		h.sendCallError(c, nil, err)
 		return
 	}
+	writeSSEEvent(c.Writer, result)
+}
 
-	// Return the raw JSON result
-	c.Data(http.StatusOK, "application/json", result)
+// jsonRPCErrorStatus is the HTTP status for a JSON-RPC error the server answered with
+func jsonRPCErrorStatus(code int) int {
+	switch code {
+	case gateway.CodeMethodNotFound:
+		return http.StatusNotFound
+	case gateway.CodeInvalidParams, gateway.CodeInvalidRequest, gateway.CodeParseError:
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
 }
 
-// handleStreamableHTTPRequest handles requests to Streamable HTTP MCP servers (MCP 2025-11-25)
-func (h *GatewayHandler) handleStreamableHTTPRequest(c *gin.Context, method string, params interface{}) {
+// callErrorStatus is the HTTP status for a failed upstream call
+func callErrorStatus(err error) int {
+	if rpcErr, ok := gateway.AsJSONRPCError(err); ok {
+		return jsonRPCErrorStatus(rpcErr.Code)
+	}
+	switch {
+	case errors.Is(err, gateway.ErrUnknownTool):
+		return http.StatusNotFound
+	case errors.Is(err, gateway.ErrRateLimited):
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadGateway
+}
+
+// callErrorCode is the JSON-RPC error code for a failed upstream call: the server's own
+// code when it answered with an error
+func callErrorCode(err error) int {
+	if rpcErr, ok := gateway.AsJSONRPCError(err); ok {
+		return rpcErr.Code
+	}
+	switch {
+	case errors.Is(err, gateway.ErrUnknownTool):
+		return -32601
+	case errors.Is(err, gateway.ErrResponseTooLarge):
+		return payloadTooLargeErrorCode
+	case errors.Is(err, gateway.ErrRateLimited):
+		return rateLimitedErrorCode
+	}
+	return -32603
+}
+
+// callError is the JSON-RPC error sent to clients for a failed upstream call. With error
+// normalization on, it has a stable gateway code and message; otherwise it carries the
+// underlying error's text.
+func (h *GatewayHandler) callError(err error) *MCPError {
+	if h.errorNormalization.Enabled {
+		rpcErr := gateway.NormalizeError(err, h.errorNormalization.IncludeDetail)
+		return &MCPError{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+	}
+	if rpcErr, ok := gateway.AsJSONRPCError(err); ok {
+		return &MCPError{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+	}
+	return &MCPError{Code: callErrorCode(err), Message: err.Error()}
+}
+
+// writeStreamableHTTPResult writes the result of a Streamable HTTP call, or its error
+func (h *GatewayHandler) writeStreamableHTTPResult(c *gin.Context, serverID, method string, result json.RawMessage, err error) {
+	h.writeCallResult(c, "Streamable HTTP", serverID, method, result, err)
+}
+
+// handleWebSocketRequest handles requests to MCP servers reached over a WebSocket
+func (h *GatewayHandler) handleWebSocketRequest(c *gin.Context, method string, params interface{}) {
 	serverID := c.Param("server_id")
 
-	result, err := h.service.CallStreamableHTTP(c.Request.Context(), serverID, method, params)
+	result, err := h.service.CallWebSocket(c.Request.Context(), serverID, method, params)
+	h.writeCallResult(c, "WebSocket", serverID, method, result, err)
+}
+
+// writeCallResult writes the result of a call over a session-based transport, or its error
+func (h *GatewayHandler) writeCallResult(c *gin.Context, transport, serverID, method string, result json.RawMessage, err error) {
 	if err != nil {
 		h.logger.Error().
 			Err(err).
 			Str("server_id", serverID).
 			Str("method", method).
-			Msg("Streamable HTTP request failed")
+			Msg(transport + " request failed")
 
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		if h.errorNormalization.Enabled {
+			c.JSON(callErrorStatus(err), MCPResponse{JSONRPC: "2.0", Error: h.callError(err)})
+			return
+		}
+
+		body := gin.H{"error": err.Error()}
+		if rpcErr, ok := gateway.AsJSONRPCError(err); ok {
+			body["code"] = rpcErr.Code
+			if rpcErr.Data != nil {
+				body["data"] = rpcErr.Data
+			}
+		} else if errors.Is(err, gateway.ErrUnknownTool) || errors.Is(err, gateway.ErrResponseTooLarge) || errors.Is(err, gateway.ErrRateLimited) {
+			body["code"] = callErrorCode(err)
+		}
+		c.JSON(callErrorStatus(err), body)
 		return
 	}
 
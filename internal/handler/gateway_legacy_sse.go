@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+// defaultLegacySSEQueuedEvents is the queue size of legacy SSE streams when none is configured
+const defaultLegacySSEQueuedEvents = 64
+
+// legacySSESession is a client stream open on the legacy SSE endpoint
+type legacySSESession struct {
+	serverID string
+	events   chan json.RawMessage
+}
+
+// legacySSESessions tracks the streams open on the legacy SSE endpoint by session ID
+type legacySSESessions struct {
+	mu       sync.Mutex
+	sessions map[string]*legacySSESession
+}
+
+func newLegacySSESessions() *legacySSESessions {
+	return &legacySSESessions{sessions: make(map[string]*legacySSESession)}
+}
+
+// open registers a stream for serverID, returning its session ID
+func (s *legacySSESessions) open(serverID string, queued int) (string, *legacySSESession) {
+	session := &legacySSESession{serverID: serverID, events: make(chan json.RawMessage, queued)}
+	id := uuid.NewString()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = session
+	return id, session
+}
+
+// close forgets the stream with the session ID. Its channel is left open so a response
+// still being produced for it can't panic; it is dropped with the session.
+func (s *legacySSESessions) close(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// get returns the open stream with the session ID, or nil
+func (s *legacySSESessions) get(id string) *legacySSESession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id]
+}
+
+// send queues msg for the stream. Returns false when the queue is full and msg was dropped.
+func (s *legacySSESession) send(msg json.RawMessage) bool {
+	select {
+	case s.events <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// LegacySSE serves the legacy SSE transport to MCP clients that don't speak Streamable
+// HTTP. The stream opens with an endpoint event naming the URL to POST JSON-RPC messages
+// to; each response then arrives on the stream as a message event. The server may use any
+// JSON-RPC transport, since its session is the gateway's rather than the client's.
+func (h *GatewayHandler) LegacySSE(c *gin.Context) {
+	serverID := c.Param("server_id")
+
+	if !h.checkExecuteAccess(c, serverID) {
+		return
+	}
+
+	transport, server, err := h.service.GetTransportType(c.Request.Context(), serverID)
+	if err != nil {
+		c.JSON(transportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if server != nil && !server.IsActive {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is inactive"})
+		return
+	}
+	if !isJSONRPCTransport(transport) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("legacy SSE is not available for %s servers", transport),
+		})
+		return
+	}
+
+	queued := h.legacySSEConfig.MaxQueuedEvents
+	if queued <= 0 {
+		queued = defaultLegacySSEQueuedEvents
+	}
+	sessionID, session := h.legacySSE.open(serverID, queued)
+	defer h.legacySSE.close(sessionID)
+
+	h.logger.Info().
+		Str("server_id", serverID).
+		Str("session_id", sessionID).
+		Msg("Legacy SSE stream opened")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	writeSSEEndpointEvent(c.Writer, c.Request.URL.Path+"/message?session_id="+sessionID)
+	c.Writer.Flush()
+
+	var keepalive <-chan time.Time
+	if h.legacySSEConfig.KeepaliveInterval > 0 {
+		ticker := time.NewTicker(h.legacySSEConfig.KeepaliveInterval)
+		defer ticker.Stop()
+		keepalive = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg := <-session.events:
+			writeSSEEvent(c.Writer, msg)
+			c.Writer.Flush()
+		case <-keepalive:
+			_, _ = c.Writer.Write([]byte(": keepalive\n\n")) // #nosec G104 -- SSE write errors intentionally ignored
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeSSEEndpointEvent writes the endpoint event that opens a legacy SSE stream
+func writeSSEEndpointEvent(w http.ResponseWriter, endpoint string) {
+	_, _ = w.Write([]byte("event: endpoint\ndata: " + endpoint + "\n\n")) // #nosec G104 -- SSE write errors intentionally ignored
+}
+
+// LegacySSEMessage handles a JSON-RPC message POSTed to a legacy SSE stream's endpoint.
+// The message is answered with 202 Accepted and its response is sent on the stream;
+// notifications get no response. Tool allowlists, tool prefixes and request limits apply
+// as on the other gateway endpoints.
+func (h *GatewayHandler) LegacySSEMessage(c *gin.Context) {
+	serverID := c.Param("server_id")
+
+	session := h.legacySSE.get(c.Query("session_id"))
+	if session == nil || session.serverID != serverID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SSE session not found"})
+		return
+	}
+	if !h.checkExecuteAccess(c, serverID) {
+		return
+	}
+	if !h.limitRequestBody(c) {
+		return
+	}
+
+	var mcpReq MCPRequest
+	if err := c.ShouldBindJSON(&mcpReq); err != nil || mcpReq.Method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON-RPC message"})
+		return
+	}
+	if mcpReq.ID == nil {
+		// Notifications such as notifications/initialized are for the gateway's own session
+		c.Status(http.StatusAccepted)
+		return
+	}
+
+	resp := h.legacySSEResponse(c, serverID, mcpReq)
+	respBytes, err := json.Marshal(resp)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode response"})
+		return
+	}
+	if !session.send(respBytes) {
+		h.logger.Warn().
+			Str("server_id", serverID).
+			Str("method", mcpReq.Method).
+			Msg("Legacy SSE stream queue full, response dropped")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "SSE stream is not keeping up"})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// legacySSEResponse runs a JSON-RPC request against the server and returns its response
+func (h *GatewayHandler) legacySSEResponse(c *gin.Context, serverID string, mcpReq MCPRequest) MCPResponse {
+	resp := MCPResponse{JSONRPC: "2.0", ID: mcpReq.ID}
+	fail := func(code int, message string) MCPResponse {
+		resp.Error = &MCPError{Code: code, Message: message}
+		return resp
+	}
+
+	transport, server, err := h.service.GetTransportType(c.Request.Context(), serverID)
+	if err != nil {
+		return fail(-32603, err.Error())
+	}
+	if server == nil {
+		return fail(-32603, "server not found")
+	}
+
+	var params map[string]interface{}
+	if len(mcpReq.Params) > 0 {
+		if err := json.Unmarshal(mcpReq.Params, &params); err != nil {
+			return fail(-32602, "Invalid params")
+		}
+	}
+
+	toolName := ""
+	if mcpReq.Method == "tools/call" {
+		toolName, _ = params["name"].(string)
+		upstreamName, ok := server.UpstreamToolName(toolName)
+		if !ok {
+			return fail(-32602, fmt.Sprintf("Tool '%s' not found: tools on this server are named '%s'",
+				toolName, server.ExposedToolName("<tool>")))
+		}
+		if !server.AllowsTool(upstreamName) {
+			return fail(-32602, fmt.Sprintf("Tool '%s' is not allowed on this server", toolName))
+		}
+		if upstreamName != toolName {
+			params["name"] = upstreamName
+		}
+	}
+	if err := h.requests.Allow(server, toolName); err != nil {
+		return fail(rateLimitedErrorCode, err.Error())
+	}
+
+	ctx := c.Request.Context()
+	if hint := h.timeoutHint(c, mcpReq.Params); hint > 0 {
+		ctx = gateway.WithTimeoutHint(ctx, hint)
+	}
+
+	var result json.RawMessage
+	if mcpReq.Method == "initialize" && transport != domain.TransportSSE {
+		result, err = h.legacySSEInitialize(ctx, transport, serverID)
+	} else {
+		var callParams interface{}
+		if params != nil {
+			callParams = params
+		}
+		result, err = h.callJSONRPC(ctx, transport, serverID, mcpReq.Method, callParams)
+		if err == nil && mcpReq.Method == "tools/list" && (server.RestrictsTools() || server.ToolPrefix != "") {
+			result, err = exposeToolsResult(result, server)
+		}
+	}
+	if err != nil {
+		h.logger.Error().
+			Err(err).
+			Str("server_id", serverID).
+			Str("method", mcpReq.Method).
+			Msg("Legacy SSE request failed")
+
+		resp.Error = h.callError(err)
+		return resp
+	}
+
+	resp.Result = result
+	return resp
+}
+
+// legacySSEInitialize answers a client's initialize with the details of the gateway's
+// session with a Streamable HTTP or WebSocket server
+func (h *GatewayHandler) legacySSEInitialize(ctx context.Context, transport domain.TransportType, serverID string) (json.RawMessage, error) {
+	initialize := h.service.InitializeStreamableHTTP
+	if transport == domain.TransportWebSocket {
+		initialize = h.service.InitializeWebSocket
+	}
+	session, err := initialize(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := session.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = json.RawMessage(`{}`)
+	}
+	result := gin.H{
+		"protocolVersion": session.ProtocolVersion,
+		"capabilities":    capabilities,
+		"serverInfo": gin.H{
+			"name":    session.ServerName,
+			"version": session.ServerVersion,
+		},
+	}
+	if session.Instructions != "" {
+		result["instructions"] = session.Instructions
+	}
+	return json.Marshal(result)
+}
+
+// callJSONRPC sends a request to a server over its JSON-RPC transport
+func (h *GatewayHandler) callJSONRPC(ctx context.Context, transport domain.TransportType, serverID, method string, params interface{}) (json.RawMessage, error) {
+	switch transport {
+	case domain.TransportStreamableHTTP:
+		return h.service.CallStreamableHTTP(ctx, serverID, method, params)
+	case domain.TransportWebSocket:
+		return h.service.CallWebSocket(ctx, serverID, method, params)
+	default:
+		return h.service.CallSSE(ctx, serverID, method, params)
+	}
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/config"
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// legacySSEClient is a client connected to the gateway's legacy SSE endpoint
+type legacySSEClient struct {
+	server *httptest.Server
+	events *bufio.Reader
+	close  func()
+}
+
+// connectLegacySSE serves handler's legacy SSE routes and opens a stream for server-1
+func connectLegacySSE(t *testing.T, handler *GatewayHandler) *legacySSEClient {
+	t.Helper()
+	router := gin.New()
+	router.GET("/api/v1/gateway/:server_id/sse", handler.LegacySSE)
+	router.POST("/api/v1/gateway/:server_id/sse/message", handler.LegacySSEMessage)
+	server := httptest.NewServer(router)
+
+	resp, err := http.Get(server.URL + "/api/v1/gateway/server-1/sse")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	client := &legacySSEClient{server: server, events: bufio.NewReader(resp.Body)}
+	client.close = func() {
+		resp.Body.Close()
+		server.Close()
+	}
+	t.Cleanup(client.close)
+	return client
+}
+
+// next reads the next SSE event, skipping comments, and returns its type and data
+func (c *legacySSEClient) next(t *testing.T) (string, string) {
+	t.Helper()
+	type event struct{ name, data string }
+	read := make(chan event, 1)
+	go func() {
+		var e event
+		for {
+			line, err := c.events.ReadString('\n')
+			if err != nil {
+				read <- e
+				return
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && e.name != "":
+				read <- e
+				return
+			case strings.HasPrefix(line, "event: "):
+				e.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				e.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	select {
+	case e := <-read:
+		return e.name, e.data
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for SSE event")
+		return "", ""
+	}
+}
+
+// post sends a JSON-RPC message to the stream's endpoint, returning the status code
+func (c *legacySSEClient) post(t *testing.T, endpoint, body string) int {
+	t.Helper()
+	resp, err := http.Post(c.server.URL+endpoint, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestGatewayHandler_LegacySSE(t *testing.T) {
+	t.Run("streams the response to a posted message", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1", IsActive: true},
+			callStreamResult: json.RawMessage(`{"content":[{"type":"text","text":"hi"}]}`),
+		}
+		client := connectLegacySSE(t, NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger()))
+
+		name, endpoint := client.next(t)
+		require.Equal(t, "endpoint", name)
+		assert.True(t, strings.HasPrefix(endpoint, "/api/v1/gateway/server-1/sse/message?session_id="), endpoint)
+
+		status := client.post(t, endpoint, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)
+		assert.Equal(t, http.StatusAccepted, status)
+
+		name, data := client.next(t)
+		assert.Equal(t, "message", name)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":{"content":[{"type":"text","text":"hi"}]}}`, data)
+		assert.Equal(t, map[string]interface{}{"name": "echo", "arguments": map[string]interface{}{"text": "hi"}}, mockService.lastCallParams)
+	})
+
+	t.Run("answers initialize from the gateway's session", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportWebSocket,
+			server:        &domain.MCPServer{ID: "server-1", IsActive: true},
+			initStreamSession: &MCPSession{
+				ProtocolVersion: "2025-11-25",
+				ServerName:      "mock",
+				ServerVersion:   "1.0.0",
+				Capabilities:    json.RawMessage(`{"tools":{}}`),
+			},
+		}
+		client := connectLegacySSE(t, NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger()))
+		_, endpoint := client.next(t)
+
+		assert.Equal(t, http.StatusAccepted, client.post(t, endpoint, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+		assert.Equal(t, http.StatusAccepted, client.post(t, endpoint, `{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+
+		_, data := client.next(t)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25","capabilities":{"tools":{}},"serverInfo":{"name":"mock","version":"1.0.0"}}}`, data)
+		assert.Empty(t, mockService.lastCallMethod, "initialize and notifications aren't forwarded")
+	})
+
+	t.Run("rejects disallowed tools on the stream", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			server:        &domain.MCPServer{ID: "server-1", IsActive: true, AllowedTools: []string{"echo"}},
+		}
+		client := connectLegacySSE(t, NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger()))
+		_, endpoint := client.next(t)
+
+		assert.Equal(t, http.StatusAccepted, client.post(t, endpoint, `{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"delete_all"}}`))
+
+		_, data := client.next(t)
+		var resp MCPResponse
+		require.NoError(t, json.Unmarshal([]byte(data), &resp))
+		assert.Equal(t, "a", resp.ID)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, -32602, resp.Error.Code)
+		assert.Nil(t, mockService.lastCallParams)
+	})
+
+	t.Run("forwards upstream errors on the stream", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			server:        &domain.MCPServer{ID: "server-1", IsActive: true},
+			callSSEErr:    assert.AnError,
+		}
+		client := connectLegacySSE(t, NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger()))
+		_, endpoint := client.next(t)
+
+		assert.Equal(t, http.StatusAccepted, client.post(t, endpoint, `{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+
+		_, data := client.next(t)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"error":{"code":-32603,"message":"`+assert.AnError.Error()+`"}}`, data)
+	})
+
+	t.Run("sends keepalive comments", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1", IsActive: true},
+		}
+		handler := NewGatewayHandlerWithConfig(nil, nil, logger.NewNopLogger(), config.GatewayConfig{
+			LegacySSE: config.LegacySSEConfig{Enabled: true, KeepaliveInterval: 10 * time.Millisecond},
+		})
+		handler.service = mockService
+		client := connectLegacySSE(t, handler)
+		client.next(t)
+
+		line, err := client.events.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": keepalive\n", line)
+	})
+}
+
+func TestGatewayHandler_LegacySSEMessage(t *testing.T) {
+	newRequest := func(target, body string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", target, strings.NewReader(body))
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		return w, c
+	}
+
+	t.Run("unknown session", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("/api/v1/gateway/server-1/sse/message?session_id=missing", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+		handler.LegacySSEMessage(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("session of another server", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+		sessionID, _ := handler.legacySSE.open("server-2", 1)
+
+		w, c := newRequest("/api/v1/gateway/server-1/sse/message?session_id="+sessionID, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+		handler.LegacySSEMessage(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+		sessionID, _ := handler.legacySSE.open("server-1", 1)
+
+		w, c := newRequest("/api/v1/gateway/server-1/sse/message?session_id="+sessionID, `not json`)
+		handler.LegacySSEMessage(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGatewayHandler_LegacySSE_PlainHTTPServer(t *testing.T) {
+	handler := NewGatewayHandlerWithInterface(&mockGatewayService{
+		transportType: domain.TransportHTTP,
+		server:        &domain.MCPServer{ID: "server-1", IsActive: true},
+	}, nil, logger.NewNopLogger())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/sse", nil)
+	c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+	handler.LegacySSE(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -3,19 +3,30 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
+	"net/url"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/handler/middleware"
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -32,6 +43,7 @@ type mockGatewayService struct {
 	serverInfoErr     error
 	initErr           error
 	terminateErr      error
+	checkToolErr      error
 	callSSEErr        error
 	callStreamErr     error
 	server            *domain.MCPServer
@@ -40,6 +52,20 @@ type mockGatewayService struct {
 	transportType     domain.TransportType
 	callStreamResult  json.RawMessage
 	callSSEResult     json.RawMessage
+	callWSResult      json.RawMessage
+	callWSErr         error
+	lastCallMethod    string
+	recordedTools     json.RawMessage
+	lastCallCtx       context.Context
+	lastCallParams    interface{}
+	subscription      *mockResourceSubscription
+	subscribeErr      error
+	elicitations      *mockElicitationSubscription
+	elicitationErr    error
+	respondErr        error
+	lastResponse      []json.RawMessage // id, result
+	lastResponseErr   *gateway.JSONRPCError
+	progressEvents    []json.RawMessage // Sent to onProgress before the result
 }
 
 func (m *mockGatewayService) ProxyToServer(ctx context.Context, serverID string) (*httputil.ReverseProxy, *domain.MCPServer, error) {
@@ -75,6 +101,8 @@ func (m *mockGatewayService) GetTransportType(ctx context.Context, serverID stri
 }
 
 func (m *mockGatewayService) CallSSE(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error) {
+	m.lastCallCtx = ctx
+	m.lastCallParams = params
 	if m.callSSEErr != nil {
 		return nil, m.callSSEErr
 	}
@@ -83,6 +111,8 @@ func (m *mockGatewayService) CallSSE(ctx context.Context, serverID string, metho
 }
 
 func (m *mockGatewayService) CallStreamableHTTP(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error) {
+	m.lastCallCtx = ctx
+	m.lastCallParams = params
 	if m.callStreamErr != nil {
 		return nil, m.callStreamErr
 	}
@@ -90,6 +120,13 @@ func (m *mockGatewayService) CallStreamableHTTP(ctx context.Context, serverID st
 	return m.callStreamResult, nil
 }
 
+func (m *mockGatewayService) CallStreamableHTTPWithProgress(ctx context.Context, serverID, method string, params map[string]interface{}, onProgress func(json.RawMessage)) (json.RawMessage, error) {
+	for _, event := range m.progressEvents {
+		onProgress(event)
+	}
+	return m.CallStreamableHTTP(ctx, serverID, method, params)
+}
+
 func (m *mockGatewayService) InitializeStreamableHTTP(ctx context.Context, serverID string) (*MCPSession, error) {
 	if m.initStreamErr != nil {
 		return nil, m.initStreamErr
@@ -98,10 +135,87 @@ func (m *mockGatewayService) InitializeStreamableHTTP(ctx context.Context, serve
 	return m.initStreamSession, nil
 }
 
+func (m *mockGatewayService) CallWebSocket(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error) {
+	m.lastCallCtx = ctx
+	m.lastCallParams = params
+	m.lastCallMethod = method
+	if m.callWSErr != nil {
+		return nil, m.callWSErr
+	}
+
+	return m.callWSResult, nil
+}
+
+func (m *mockGatewayService) InitializeWebSocket(ctx context.Context, serverID string) (*MCPSession, error) {
+	return m.InitializeStreamableHTTP(ctx, serverID)
+}
+
 func (m *mockGatewayService) TerminateStreamableHTTP(ctx context.Context, serverID string) error {
 	return m.terminateErr
 }
 
+func (m *mockGatewayService) CheckToolCall(ctx context.Context, serverID, toolName string) error {
+	return m.checkToolErr
+}
+
+func (m *mockGatewayService) RecordToolsList(serverID string, result json.RawMessage) {
+	m.recordedTools = result
+}
+
+func (m *mockGatewayService) SubscribeResource(ctx context.Context, serverID, uri string) (ResourceSubscription, error) {
+	if m.subscribeErr != nil {
+		return nil, m.subscribeErr
+	}
+	m.subscription.uri = uri
+	return m.subscription, nil
+}
+
+func (m *mockGatewayService) SubscribeElicitations(ctx context.Context, serverID string) (ElicitationSubscription, error) {
+	if m.elicitationErr != nil {
+		return nil, m.elicitationErr
+	}
+	return m.elicitations, nil
+}
+
+func (m *mockGatewayService) RespondElicitation(ctx context.Context, serverID string, id, result json.RawMessage, rpcErr *gateway.JSONRPCError) error {
+	m.lastResponse = []json.RawMessage{id, result}
+	m.lastResponseErr = rpcErr
+	return m.respondErr
+}
+
+func (m *mockGatewayService) HTTPClient(server *domain.MCPServer) *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// mockElicitationSubscription implements ElicitationSubscription for testing
+type mockElicitationSubscription struct {
+	requests chan json.RawMessage
+	closed   bool
+}
+
+func (m *mockElicitationSubscription) Requests() <-chan json.RawMessage {
+	return m.requests
+}
+
+func (m *mockElicitationSubscription) Close() {
+	m.closed = true
+}
+
+// mockResourceSubscription implements ResourceSubscription for testing
+type mockResourceSubscription struct {
+	updates chan json.RawMessage
+	uri     string
+	closed  bool
+}
+
+func (m *mockResourceSubscription) Updates() <-chan json.RawMessage {
+	return m.updates
+}
+
+func (m *mockResourceSubscription) Close() {
+	m.closed = true
+}
+
 type mockGatewayAccessService struct {
 	accessErr error
 	serverIDs []string
@@ -709,6 +823,100 @@ func TestGatewayHandler_Initialize_WithMock(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+
+	t.Run("forwards instructions from streamable HTTP server", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1", Name: "Test Server", URL: "https://example.com/mcp"},
+			initStreamSession: &MCPSession{
+				SessionID:       "session-1",
+				ProtocolVersion: "2025-11-25",
+				ServerName:      "backend",
+				ServerVersion:   "1.2.3",
+				Instructions:    "Call search before fetch",
+			},
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/initialize", nil)
+
+		handler.Initialize(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "initialized", response["status"])
+		assert.Equal(t, "session-1", response["session_id"])
+		assert.Equal(t, "Call search before fetch", response["instructions"])
+		assert.Equal(t, map[string]interface{}{"name": "backend", "version": "1.2.3"}, response["server_info"])
+	})
+
+	t.Run("returns error when streamable HTTP initialize fails", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1"},
+			initStreamErr: errors.New("connection refused"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/initialize", nil)
+
+		handler.Initialize(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// staticServerRepository returns a single server for gateway service tests
+type staticServerRepository struct {
+	server *domain.MCPServer
+}
+
+func (r *staticServerRepository) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
+	return r.server, nil
+}
+
+func TestGatewayHandler_Initialize_ForwardsBackendInstructions(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["method"] != "initialize" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("MCP-Session-Id", "backend-session")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25","serverInfo":{"name":"docs","version":"0.9.0"},"instructions":"Use search for lookups"}}`))
+	}))
+	defer backend.Close()
+
+	repo := &staticServerRepository{server: &domain.MCPServer{
+		ID:        "server-1",
+		Name:      "Docs",
+		URL:       backend.URL,
+		Transport: domain.TransportStreamableHTTP,
+		IsActive:  true,
+	}}
+	handler := NewGatewayHandler(gateway.NewService(repo, logger.NewNopLogger(), nil), nil, logger.NewNopLogger())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/initialize", nil)
+
+	handler.Initialize(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Use search for lookups", response["instructions"])
+	assert.Equal(t, map[string]interface{}{"name": "docs", "version": "0.9.0"}, response["server_info"])
 }
 
 func TestGatewayHandler_ListTools_WithMock(t *testing.T) {
@@ -781,265 +989,1083 @@ func TestGatewayHandler_ListTools_WithMock(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadGateway, w.Code)
 	})
-}
 
-func TestGatewayHandler_CallTool_WithMock(t *testing.T) {
-	t.Run("returns not found on transport error", func(t *testing.T) {
+	t.Run("forwards cursor and returns next page", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportErr: errors.New("server not found"),
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"tools":[{"name":"two"}],"nextCursor":"page-3"}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
-		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/tools/list?cursor=page-2", nil)
 
-		handler.CallTool(c)
+		handler.ListTools(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]interface{}{"cursor": "page-2"}, mockService.lastCallParams)
+		assert.JSONEq(t, `{"tools":[{"name":"two"}],"nextCursor":"page-3"}`, w.Body.String())
 	})
 
-	t.Run("uses SSE transport for tool call", func(t *testing.T) {
+	t.Run("keeps next cursor when filtering tools", func(t *testing.T) {
 		mockService := &mockGatewayService{
 			transportType: domain.TransportSSE,
-			server:        &domain.MCPServer{ID: "server-1"},
-			callSSEResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
+			server:        &domain.MCPServer{ID: "server-1", AllowedTools: []string{"two"}},
+			callSSEResult: json.RawMessage(`{"tools":[{"name":"two"}],"nextCursor":"page-3"}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
-		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/tools/list?cursor=page-2", nil)
 
-		handler.CallTool(c)
+		handler.ListTools(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]interface{}{"cursor": "page-2"}, mockService.lastCallParams)
+		assert.JSONEq(t, `{"tools":[{"name":"two"}],"nextCursor":"page-3"}`, w.Body.String())
 	})
+}
 
-	t.Run("uses streamable HTTP transport for tool call", func(t *testing.T) {
+func TestGatewayHandler_CallTool_WithMock(t *testing.T) {
+	t.Run("uses WebSocket transport when configured", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportType:    domain.TransportStreamableHTTP,
-			server:           &domain.MCPServer{ID: "server-1"},
-			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
+			transportType: domain.TransportWebSocket,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callWSResult:  json.RawMessage(`{"content":[{"type":"text","text":"hi"}]}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"greet"}`))
 		c.Request.Header.Set("Content-Type", "application/json")
 
 		handler.CallTool(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "tools/call", mockService.lastCallMethod)
+		assert.Equal(t, map[string]interface{}{"name": "greet"}, mockService.lastCallParams)
+		assert.JSONEq(t, `{"content":[{"type":"text","text":"hi"}]}`, w.Body.String())
 	})
-}
 
-func TestGatewayHandler_ListResources_WithMock(t *testing.T) {
-	t.Run("returns not found on transport error", func(t *testing.T) {
+	t.Run("returns bad gateway when WebSocket call fails", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportErr: errors.New("server not found"),
+			transportType: domain.TransportWebSocket,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callWSErr:     gateway.ErrWebSocketClosed,
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/list", nil)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"greet"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.ListResources(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusBadGateway, w.Code)
 	})
 
-	t.Run("uses streamable HTTP transport", func(t *testing.T) {
+	t.Run("returns not found on transport error", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportType:    domain.TransportStreamableHTTP,
-			server:           &domain.MCPServer{ID: "server-1"},
-			callStreamResult: json.RawMessage(`{"resources":[]}`),
+			transportErr: errors.New("server not found"),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/list", nil)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.ListResources(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
-}
 
-func TestGatewayHandler_ReadResource_WithMock(t *testing.T) {
-	t.Run("returns not found on transport error", func(t *testing.T) {
+	t.Run("returns bad gateway when transport detection fails", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportErr: errors.New("server not found"),
+			transportErr: fmt.Errorf("%w for server server-1: streamable_http probe: status 500; sse probe: status 404", gateway.ErrTransportDetectionFailed),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/resources/read", strings.NewReader(`{"uri":"file:///test"}`))
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.ReadResource(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Contains(t, w.Body.String(), "streamable_http probe")
+		assert.Contains(t, w.Body.String(), "sse probe")
 	})
 
-	t.Run("uses SSE transport for resource read", func(t *testing.T) {
+	t.Run("uses SSE transport for tool call", func(t *testing.T) {
 		mockService := &mockGatewayService{
 			transportType: domain.TransportSSE,
 			server:        &domain.MCPServer{ID: "server-1"},
-			callSSEResult: json.RawMessage(`{"contents":[{"text":"content"}]}`),
+			callSSEResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/resources/read", strings.NewReader(`{"uri":"file:///test"}`))
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.ReadResource(c)
+		handler.CallTool(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
-}
 
-func TestGatewayHandler_ListPrompts_WithMock(t *testing.T) {
-	t.Run("returns not found on transport error", func(t *testing.T) {
+	t.Run("uses streamable HTTP transport for tool call", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportErr: errors.New("server not found"),
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/prompts/list", nil)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"test"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.ListPrompts(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
-	t.Run("uses SSE transport", func(t *testing.T) {
+	t.Run("streams progress before the result when the client sends a progress token", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportType: domain.TransportSSE,
-			server:        &domain.MCPServer{ID: "server-1"},
-			callSSEResult: json.RawMessage(`{"prompts":[]}`),
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
+			progressEvents: []json.RawMessage{
+				json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"p1","progress":1}}`),
+			},
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/prompts/list", nil)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo","_meta":{"progressToken":"p1"}}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Accept", "application/json, text/event-stream")
 
-		handler.ListPrompts(c)
+		handler.CallTool(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+		body := w.Body.String()
+		progressAt := strings.Index(body, `"progressToken":"p1"`)
+		resultAt := strings.Index(body, `"text":"result"`)
+		require.GreaterOrEqual(t, progressAt, 0)
+		require.GreaterOrEqual(t, resultAt, 0)
+		assert.Less(t, progressAt, resultAt)
 	})
-}
 
-func TestGatewayHandler_GetPrompt_WithMock(t *testing.T) {
-	t.Run("returns not found on transport error", func(t *testing.T) {
+	t.Run("returns plain JSON for a progress token without SSE accept", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportErr: errors.New("server not found"),
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
+			progressEvents: []json.RawMessage{
+				json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"p1","progress":1}}`),
+			},
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/prompts/get", strings.NewReader(`{"name":"test"}`))
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo","_meta":{"progressToken":"p1"}}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.GetPrompt(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.NotContains(t, w.Body.String(), "notifications/progress")
 	})
 
-	t.Run("uses streamable HTTP transport", func(t *testing.T) {
+	t.Run("returns not found for unknown tool", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			transportType:    domain.TransportStreamableHTTP,
-			server:           &domain.MCPServer{ID: "server-1"},
-			callStreamResult: json.RawMessage(`{"messages":[]}`),
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callStreamErr: fmt.Errorf("%w: \"ehco\" is not advertised by server server-1", gateway.ErrUnknownTool),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/prompts/get", strings.NewReader(`{"name":"test"}`))
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"ehco"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.GetPrompt(c)
+		handler.CallTool(c)
 
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":-32601`)
 	})
-}
 
-func TestGatewayHandler_InitializeStreamableHTTP_WithMock(t *testing.T) {
-	t.Run("returns session info on success", func(t *testing.T) {
+	t.Run("returns JSON-RPC error when tool request limit is exceeded", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			initStreamSession: &MCPSession{
-				SessionID:       "session-123",
-				ProtocolVersion: "2025-11-25",
-			},
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1", MaxToolRequestsPerMinute: 1},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/streamable-http/initialize", nil)
+		call := func(tool string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+			c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"`+tool+`"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handler.CallTool(c)
+			return w
+		}
 
-		handler.InitializeStreamableHTTP(c)
+		assert.Contains(t, call("search").Body.String(), "result")
 
-		assert.Equal(t, http.StatusOK, w.Code)
-		var response map[string]interface{}
-		json.Unmarshal(w.Body.Bytes(), &response)
-		assert.Equal(t, "server-1", response["server_id"])
-		assert.Equal(t, "session-123", response["session_id"])
-		assert.Equal(t, "2025-11-25", response["protocol_version"])
-		assert.Equal(t, "initialized", response["status"])
+		w := call("search")
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "event: message\ndata: ")
+		assert.Contains(t, w.Body.String(), `"code":-32029`)
+
+		assert.Contains(t, call("fetch").Body.String(), "result", "other tools are counted separately")
 	})
 
-	t.Run("returns error on failure", func(t *testing.T) {
+	t.Run("rejects a request body over the limit", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			initStreamErr: errors.New("connection refused"),
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"result"}]}`),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+		handler.maxRequestBytes = 64
 
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/streamable-http/initialize", nil)
+		call := func(body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+			c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handler.CallTool(c)
+			return w
+		}
 
-		handler.InitializeStreamableHTTP(c)
+		w := call(`{"name":"echo","arguments":{"text":"` + strings.Repeat("a", 100) + `"}}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":-32000`)
+		assert.Nil(t, mockService.lastCallParams, "oversized call is not forwarded")
 
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusOK, call(`{"name":"echo"}`).Code)
 	})
-}
 
-func TestGatewayHandler_TerminateStreamableHTTP_WithMock(t *testing.T) {
-	t.Run("returns success on termination", func(t *testing.T) {
+	t.Run("returns -32000 when the upstream response is too large", func(t *testing.T) {
 		mockService := &mockGatewayService{
-			terminateErr: nil,
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callStreamErr: fmt.Errorf("failed to read response body: %w", gateway.ErrResponseTooLarge),
 		}
 		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
-		c.Request = httptest.NewRequest("DELETE", "/api/v1/gateway/server-1/streamable-http/session", nil)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		handler.TerminateStreamableHTTP(c)
+		handler.CallTool(c)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":-32000`)
+	})
+
+	t.Run("returns 429 when the server was initialized too often", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callStreamErr: fmt.Errorf("failed to reinitialize session: %w", gateway.ErrInitializeThrottled),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CallTool(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":-32029`)
+	})
+}
+
+func TestGatewayHandler_CallTool_JSONRPCErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "method not found",
+			err:        &gateway.JSONRPCError{Code: gateway.CodeMethodNotFound, Message: "Method not found"},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"MCP error -32601: Method not found","code":-32601}`,
+		},
+		{
+			name:       "invalid params keeps data",
+			err:        fmt.Errorf("tool call failed: %w", &gateway.JSONRPCError{Code: gateway.CodeInvalidParams, Message: "Invalid params", Data: map[string]interface{}{"field": "query"}}),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"tool call failed: MCP error -32602: Invalid params","code":-32602,"data":{"field":"query"}}`,
+		},
+		{
+			name:       "server-defined error",
+			err:        &gateway.JSONRPCError{Code: -32001, Message: "Quota exhausted"},
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"error":"MCP error -32001: Quota exhausted","code":-32001}`,
+		},
+	}
+
+	for _, transport := range []domain.TransportType{domain.TransportStreamableHTTP, domain.TransportSSE, domain.TransportWebSocket} {
+		for _, tt := range tests {
+			t.Run(string(transport)+"/"+tt.name, func(t *testing.T) {
+				mockService := &mockGatewayService{
+					transportType: transport,
+					server:        &domain.MCPServer{ID: "server-1"},
+					callStreamErr: tt.err,
+					callSSEErr:    tt.err,
+					callWSErr:     tt.err,
+				}
+				handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+				c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo"}`))
+				c.Request.Header.Set("Content-Type", "application/json")
+
+				handler.CallTool(c)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			})
+		}
+	}
+}
+
+func TestGatewayHandler_CallTool_NormalizedErrors(t *testing.T) {
+	refused := fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+
+	tests := []struct {
+		name       string
+		normalize  config.ErrorNormalizationConfig
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "connection refused",
+			normalize:  config.ErrorNormalizationConfig{Enabled: true},
+			err:        refused,
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32001,"message":"Upstream server unavailable","data":{"kind":"upstream_unavailable"}}}`,
+		},
+		{
+			name:       "connection refused with detail",
+			normalize:  config.ErrorNormalizationConfig{Enabled: true, IncludeDetail: true},
+			err:        refused,
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32001,"message":"Upstream server unavailable","data":{"kind":"upstream_unavailable","detail":"request failed: dial tcp: connection refused"}}}`,
+		},
+		{
+			name:       "server error",
+			normalize:  config.ErrorNormalizationConfig{Enabled: true},
+			err:        &gateway.JSONRPCError{Code: gateway.CodeMethodNotFound, Message: "Method not found"},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32601,"message":"Method not found"}}`,
+		},
+		{
+			name:       "disabled",
+			err:        refused,
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"error":"request failed: dial tcp: connection refused"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockGatewayService{
+				transportType: domain.TransportStreamableHTTP,
+				server:        &domain.MCPServer{ID: "server-1"},
+				callStreamErr: tt.err,
+			}
+			handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+			handler.errorNormalization = tt.normalize
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+			c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(`{"name":"echo"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CallTool(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestGatewayHandler_timeoutHint(t *testing.T) {
+	handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+	handler.timeoutHints = config.TimeoutHintConfig{
+		Enabled: true,
+		Max:     time.Minute,
+		RoleMax: map[string]time.Duration{"admin": 10 * time.Minute, "operator": 2 * time.Minute},
+	}
+
+	hint := func(roles []string, params string) time.Duration {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set(middleware.ContextKeyUserRoles, roles)
+		return handler.timeoutHint(c, json.RawMessage(params))
+	}
+
+	assert.Equal(t, 20*time.Second, hint(nil, `{"_meta":{"timeoutMs":20000}}`), "within bounds")
+	assert.Equal(t, time.Minute, hint([]string{"viewer"}, `{"_meta":{"timeoutMs":600000}}`), "clamped to max")
+	assert.Equal(t, 2*time.Minute, hint([]string{"operator"}, `{"_meta":{"timeoutMs":600000}}`), "clamped to role max")
+	assert.Equal(t, 5*time.Minute, hint([]string{"operator", "admin"}, `{"_meta":{"timeoutMs":300000}}`), "largest role max applies")
+	assert.Equal(t, time.Duration(0), hint(nil, `{"name":"search"}`), "no hint")
+
+	handler.timeoutHints.Enabled = false
+	assert.Equal(t, time.Duration(0), hint(nil, `{"_meta":{"timeoutMs":20000}}`), "hints disabled")
+}
+
+func TestGatewayHandler_CallTool_AppliesTimeoutHint(t *testing.T) {
+	mockService := &mockGatewayService{
+		transportType:    domain.TransportStreamableHTTP,
+		server:           &domain.MCPServer{ID: "server-1"},
+		callStreamResult: json.RawMessage(`{"content":[]}`),
+	}
+	handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+	handler.timeoutHints = config.TimeoutHintConfig{Enabled: true, Max: time.Minute}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call",
+		strings.NewReader(`{"name":"slow","_meta":{"timeoutMs":3600000}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.CallTool(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, mockService.lastCallCtx)
+	assert.Equal(t, time.Minute, gateway.TimeoutHintFromContext(mockService.lastCallCtx))
+}
+
+func TestGatewayHandler_CallTool_AllowedTools(t *testing.T) {
+	call := func(handler *GatewayHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.CallTool(c)
+		return w
+	}
+
+	for _, transport := range []domain.TransportType{domain.TransportStreamableHTTP, domain.TransportSSE, domain.TransportHTTP} {
+		t.Run("rejects tool outside allowlist over "+string(transport), func(t *testing.T) {
+			mockService := &mockGatewayService{
+				transportType:    transport,
+				server:           &domain.MCPServer{ID: "server-1", AllowedTools: []string{"read_file"}},
+				callStreamResult: json.RawMessage(`{"content":[]}`),
+				callSSEResult:    json.RawMessage(`{"content":[]}`),
+			}
+			handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+			w := call(handler, `{"name":"delete_file"}`)
+
+			assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), `"code":-32602`)
+			assert.Contains(t, w.Body.String(), "delete_file")
+			assert.Nil(t, mockService.lastCallCtx, "upstream must not be called")
+		})
+	}
+
+	t.Run("rejects JSON-RPC tools/call outside allowlist with request id", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportHTTP,
+			server:        &domain.MCPServer{ID: "server-1", AllowedTools: []string{"read_file"}},
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := call(handler, `{"jsonrpc":"2.0","id":"req-9","method":"tools/call","params":{"name":"delete_file"}}`)
+
+		assert.Contains(t, w.Body.String(), `"id":"req-9"`)
+		assert.Contains(t, w.Body.String(), `"code":-32602`)
+	})
+
+	t.Run("allows tool in allowlist", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1", AllowedTools: []string{"read_file"}},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"ok"}]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := call(handler, `{"name":"read_file"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "ok")
+	})
+
+	t.Run("namespace allowlist rejects tool the server allows", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server: &domain.MCPServer{
+				ID:                    "server-1",
+				AllowedTools:          []string{"read_file", "delete_file"},
+				NamespaceAllowedTools: [][]string{{"read_file"}},
+			},
+			callStreamResult: json.RawMessage(`{"content":[]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := call(handler, `{"name":"delete_file"}`)
+
+		assert.Contains(t, w.Body.String(), `"code":-32602`)
+		assert.Nil(t, mockService.lastCallCtx, "upstream must not be called")
+	})
+
+	t.Run("namespace allowlist applies to servers without their own", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1", NamespaceAllowedTools: [][]string{{"read_file"}}},
+			callStreamResult: json.RawMessage(`{"content":[]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := call(handler, `{"name":"delete_file"}`)
+
+		assert.Contains(t, w.Body.String(), `"code":-32602`)
+		assert.Nil(t, mockService.lastCallCtx, "upstream must not be called")
+	})
+
+	t.Run("empty allowlist allows every tool", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1", AllowedTools: []string{}},
+			callStreamResult: json.RawMessage(`{"content":[{"text":"ok"}]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := call(handler, `{"name":"anything"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "ok")
+	})
+}
+
+func TestGatewayHandler_ListTools_AllowedTools(t *testing.T) {
+	toolsList := json.RawMessage(`{"tools":[{"name":"read_file","annotations":{"readOnlyHint":true}},{"name":"delete_file"}],"nextCursor":"abc"}`)
+	list := func(handler *GatewayHandler) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/list", nil)
+		handler.ListTools(c)
+		return w
+	}
+
+	for _, transport := range []domain.TransportType{domain.TransportStreamableHTTP, domain.TransportSSE} {
+		t.Run("filters tools over "+string(transport), func(t *testing.T) {
+			mockService := &mockGatewayService{
+				transportType:    transport,
+				server:           &domain.MCPServer{ID: "server-1", AllowedTools: []string{"read_file"}},
+				callStreamResult: toolsList,
+				callSSEResult:    toolsList,
+			}
+			handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+			w := list(handler)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"tools":[{"name":"read_file","annotations":{"readOnlyHint":true}}],"nextCursor":"abc"}`, w.Body.String())
+		})
+	}
+
+	t.Run("empty allowlist returns every tool", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: toolsList,
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := list(handler)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, string(toolsList), w.Body.String())
+	})
+
+	t.Run("filters tools outside the namespace allowlist", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportStreamableHTTP,
+			server: &domain.MCPServer{
+				ID:                    "server-1",
+				AllowedTools:          []string{"read_file", "delete_file"},
+				NamespaceAllowedTools: [][]string{{"read_file"}},
+			},
+			callStreamResult: toolsList,
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := list(handler)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"tools":[{"name":"read_file","annotations":{"readOnlyHint":true}}],"nextCursor":"abc"}`, w.Body.String())
+	})
+
+	t.Run("filters plain HTTP servers", func(t *testing.T) {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":%s}`, toolsList)
+		}))
+		defer backend.Close()
+
+		mockService := &mockGatewayService{
+			transportType: domain.TransportHTTP,
+			server:        &domain.MCPServer{ID: "server-1", URL: backend.URL, AllowedTools: []string{"read_file"}},
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := list(handler)
+
+		assert.Contains(t, w.Body.String(), "read_file")
+		assert.NotContains(t, w.Body.String(), "delete_file")
+	})
+}
+
+func TestGatewayHandler_ToolPrefix(t *testing.T) {
+	newHandler := func(server *domain.MCPServer) (*GatewayHandler, *mockGatewayService) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           server,
+			callStreamResult: json.RawMessage(`{"tools":[{"name":"search"},{"name":"delete"}]}`),
+		}
+		return NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger()), mockService
+	}
+	callTool := func(handler *GatewayHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/call", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.CallTool(c)
+		return w
+	}
+
+	t.Run("ListTools prefixes tool names", func(t *testing.T) {
+		handler, _ := newHandler(&domain.MCPServer{ID: "server-1", ToolPrefix: "github"})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/tools/list", nil)
+		handler.ListTools(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"tools":[{"name":"github__search"},{"name":"github__delete"}]}`, w.Body.String())
+	})
+
+	t.Run("CallTool strips the prefix before forwarding", func(t *testing.T) {
+		handler, mockService := newHandler(&domain.MCPServer{ID: "server-1", ToolPrefix: "github"})
+
+		w := callTool(handler, `{"name":"github__search","arguments":{"q":"go"}}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		params, ok := mockService.lastCallParams.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "search", params["name"])
+		assert.Equal(t, map[string]interface{}{"q": "go"}, params["arguments"])
+	})
+
+	t.Run("CallTool rejects names without the prefix", func(t *testing.T) {
+		handler, mockService := newHandler(&domain.MCPServer{ID: "server-1", ToolPrefix: "github"})
+
+		w := callTool(handler, `{"name":"search"}`)
+
+		assert.Contains(t, w.Body.String(), `"code":-32602`)
+		assert.Nil(t, mockService.lastCallCtx)
+	})
+
+	t.Run("AllowedTools applies to the unprefixed name", func(t *testing.T) {
+		handler, mockService := newHandler(&domain.MCPServer{ID: "server-1", ToolPrefix: "github", AllowedTools: []string{"search"}})
+
+		assert.Equal(t, http.StatusOK, callTool(handler, `{"name":"github__search"}`).Code)
+
+		mockService.lastCallCtx = nil
+		w := callTool(handler, `{"name":"github__delete"}`)
+		assert.Contains(t, w.Body.String(), `"code":-32602`)
+		assert.Contains(t, w.Body.String(), "github__delete")
+		assert.Nil(t, mockService.lastCallCtx)
+	})
+}
+
+func TestSetCallToolName(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "JSON-RPC request",
+			body:     `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"github__search","arguments":{"q":"go"}}}`,
+			expected: `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search","arguments":{"q":"go"}}}`,
+		},
+		{
+			name:     "bare params",
+			body:     `{"name":"github__search","arguments":{"q":"go"}}`,
+			expected: `{"name":"search","arguments":{"q":"go"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+
+			setCallToolName(c, "search")
+
+			body, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+			assert.Equal(t, int64(len(body)), c.Request.ContentLength)
+		})
+	}
+}
+
+func TestGatewayHandler_ListResources_WithMock(t *testing.T) {
+	t.Run("returns not found on transport error", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportErr: errors.New("server not found"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/list", nil)
+
+		handler.ListResources(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("uses streamable HTTP transport", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"resources":[]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/list", nil)
+
+		handler.ListResources(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("forwards cursor and returns next page over SSE", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callSSEResult: json.RawMessage(`{"resources":[{"uri":"file:///b"}],"nextCursor":"page-3"}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/list?cursor=page-2", nil)
+
+		handler.ListResources(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]interface{}{"cursor": "page-2"}, mockService.lastCallParams)
+		assert.JSONEq(t, `{"resources":[{"uri":"file:///b"}],"nextCursor":"page-3"}`, w.Body.String())
+	})
+}
+
+func TestGatewayHandler_ReadResource_WithMock(t *testing.T) {
+	t.Run("returns not found on transport error", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportErr: errors.New("server not found"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/resources/read", strings.NewReader(`{"uri":"file:///test"}`))
+
+		handler.ReadResource(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("uses SSE transport for resource read", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callSSEResult: json.RawMessage(`{"contents":[{"text":"content"}]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/resources/read", strings.NewReader(`{"uri":"file:///test"}`))
+
+		handler.ReadResource(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	blob := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe, '\n'}
+	blobResult := json.RawMessage(`{"contents":[{"uri":"file:///logo.png","mimeType":"image/png","blob":"` + base64.StdEncoding.EncodeToString(blob) + `"}]}`)
+
+	readResource := func(mockService *mockGatewayService, query string) *httptest.ResponseRecorder {
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/read?"+query, nil)
+		handler.ReadResource(c)
+		return w
+	}
+
+	t.Run("forwards blob result unchanged and takes uri from query", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			callStreamResult: blobResult,
+		}
+
+		w := readResource(mockService, "uri=file:///logo.png")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, string(blobResult), w.Body.String())
+		assert.Equal(t, map[string]interface{}{"uri": "file:///logo.png"}, mockService.lastCallParams)
+	})
+
+	t.Run("raw returns decoded bytes with mime type", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			callStreamResult: blobResult,
+		}
+
+		w := readResource(mockService, "uri=file:///logo.png&raw=true")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, blob, w.Body.Bytes())
+	})
+
+	t.Run("raw needs a single content item", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			callStreamResult: json.RawMessage(`{"contents":[{"uri":"a","text":"a"},{"uri":"b","text":"b"}]}`),
+		}
+
+		w := readResource(mockService, "uri=a&raw=true")
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+
+	t.Run("rejects invalid blob", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			callSSEResult: json.RawMessage(`{"contents":[{"uri":"file:///logo.png","blob":"%%%"}]}`),
+		}
+
+		w := readResource(mockService, "uri=file:///logo.png")
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Contains(t, w.Body.String(), "not valid base64")
+	})
+}
+
+func TestGatewayHandler_ListPrompts_WithMock(t *testing.T) {
+	t.Run("returns not found on transport error", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportErr: errors.New("server not found"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/prompts/list", nil)
+
+		handler.ListPrompts(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("uses SSE transport", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType: domain.TransportSSE,
+			server:        &domain.MCPServer{ID: "server-1"},
+			callSSEResult: json.RawMessage(`{"prompts":[]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/prompts/list", nil)
+
+		handler.ListPrompts(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("forwards cursor and returns next page", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"prompts":[{"name":"p2"}],"nextCursor":"page-3"}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/prompts/list?cursor=page-2", nil)
+
+		handler.ListPrompts(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]interface{}{"cursor": "page-2"}, mockService.lastCallParams)
+		assert.JSONEq(t, `{"prompts":[{"name":"p2"}],"nextCursor":"page-3"}`, w.Body.String())
+	})
+}
+
+func TestGatewayHandler_GetPrompt_WithMock(t *testing.T) {
+	t.Run("returns not found on transport error", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportErr: errors.New("server not found"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/prompts/get", strings.NewReader(`{"name":"test"}`))
+
+		handler.GetPrompt(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("uses streamable HTTP transport", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			transportType:    domain.TransportStreamableHTTP,
+			server:           &domain.MCPServer{ID: "server-1"},
+			callStreamResult: json.RawMessage(`{"messages":[]}`),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/prompts/get", strings.NewReader(`{"name":"test"}`))
+
+		handler.GetPrompt(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestGatewayHandler_InitializeStreamableHTTP_WithMock(t *testing.T) {
+	t.Run("returns session info on success", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			initStreamSession: &MCPSession{
+				SessionID:       "session-123",
+				ProtocolVersion: "2025-11-25",
+			},
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/streamable-http/initialize", nil)
+
+		handler.InitializeStreamableHTTP(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "server-1", response["server_id"])
+		assert.Equal(t, "session-123", response["session_id"])
+		assert.Equal(t, "2025-11-25", response["protocol_version"])
+		assert.Equal(t, "initialized", response["status"])
+	})
+
+	t.Run("returns error on failure", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			initStreamErr: errors.New("connection refused"),
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/streamable-http/initialize", nil)
+
+		handler.InitializeStreamableHTTP(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGatewayHandler_TerminateStreamableHTTP_WithMock(t *testing.T) {
+	t.Run("returns success on termination", func(t *testing.T) {
+		mockService := &mockGatewayService{
+			terminateErr: nil,
+		}
+		handler := NewGatewayHandlerWithInterface(mockService, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("DELETE", "/api/v1/gateway/server-1/streamable-http/session", nil)
+
+		handler.TerminateStreamableHTTP(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		var response map[string]interface{}
@@ -1066,6 +2092,44 @@ func TestGatewayHandler_TerminateStreamableHTTP_WithMock(t *testing.T) {
 }
 
 func TestGatewayHandler_MCPProxy_WithMock(t *testing.T) {
+	t.Run("returns JSON-RPC error when server request limit is exceeded", func(t *testing.T) {
+		server := &domain.MCPServer{ID: "server-1", IsActive: true, MaxRequestsPerMinute: 1}
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{server: server}, nil, logger.NewNopLogger())
+		require.NoError(t, handler.requests.Allow(server, ""))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/mcp/server-1", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/list"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.MCPProxy(c)
+
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"id":7`)
+		assert.Contains(t, w.Body.String(), `"code":-32029`)
+	})
+
+	t.Run("counts each message of a batch against the tool limit", func(t *testing.T) {
+		server := &domain.MCPServer{ID: "server-1", IsActive: true, MaxToolRequestsPerMinute: 1}
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{server: server}, nil, logger.NewNopLogger())
+		batch := `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}},` +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search"}}]`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/mcp/server-1", strings.NewReader(batch))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.MCPProxy(c)
+
+		assert.Contains(t, w.Body.String(), `"code":-32029`)
+		assert.Contains(t, w.Body.String(), `tool \"search\"`)
+		// The rejected batch used up nothing
+		assert.NoError(t, handler.requests.Allow(server, "search"))
+	})
+
 	t.Run("denies access when access check fails", func(t *testing.T) {
 		mockGwSvc := &mockGatewayService{
 			server: &domain.MCPServer{ID: "server-1", IsActive: true},
@@ -1139,6 +2203,26 @@ func TestGatewayHandler_MCPProxy_WithMock(t *testing.T) {
 
 		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 	})
+
+	t.Run("rejects unknown tool with method not found", func(t *testing.T) {
+		mockGwSvc := &mockGatewayService{
+			server:       &domain.MCPServer{ID: "server-1", IsActive: true},
+			checkToolErr: gateway.ErrUnknownTool,
+		}
+		handler := NewGatewayHandlerWithInterface(mockGwSvc, nil, logger.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		c.Request = httptest.NewRequest("POST", "/api/v1/mcp/server-1", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"ehco"}}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.MCPProxy(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":-32601`)
+		assert.Contains(t, w.Body.String(), `"id":7`)
+	})
 }
 
 func TestNewGatewayHandlerWithInterface(t *testing.T) {
@@ -1165,3 +2249,384 @@ func TestNewGatewayHandlerWithInterface(t *testing.T) {
 		assert.Nil(t, handler.accessService)
 	})
 }
+
+func TestGatewayHandler_SubscribeResource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRequest := func(query string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/resources/subscribe"+query, nil)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		return w, c
+	}
+
+	t.Run("streams updates until the subscription ends", func(t *testing.T) {
+		sub := &mockResourceSubscription{updates: make(chan json.RawMessage, 2)}
+		sub.updates <- json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a.txt"}}`)
+		close(sub.updates)
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{subscription: sub}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("?uri=file:///a.txt")
+		handler.SubscribeResource(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `data: {"jsonrpc":"2.0","method":"notifications/resources/updated"`)
+		assert.Equal(t, "file:///a.txt", sub.uri)
+		assert.True(t, sub.closed)
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		sub := &mockResourceSubscription{updates: make(chan json.RawMessage)}
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{subscription: sub}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("?uri=file:///a.txt")
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		cancel()
+		handler.SubscribeResource(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, sub.closed)
+	})
+
+	t.Run("requires uri", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("")
+		handler.SubscribeResource(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("subscribe failure", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{subscribeErr: errors.New("resources/subscribe failed")}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("?uri=file:///a.txt")
+		handler.SubscribeResource(c)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("too many subscribers", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{subscribeErr: gateway.ErrTooManySubscribers}, nil, logger.NewNopLogger())
+
+		w, c := newRequest("?uri=file:///a.txt")
+		handler.SubscribeResource(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestGatewayHandler_ElicitationEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRequest := func() (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/gateway/server-1/elicitation/events", nil)
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		return w, c
+	}
+
+	t.Run("streams elicitation requests", func(t *testing.T) {
+		sub := &mockElicitationSubscription{requests: make(chan json.RawMessage, 1)}
+		sub.requests <- json.RawMessage(`{"jsonrpc":"2.0","id":7,"method":"elicitation/create","params":{"message":"Name?"}}`)
+		close(sub.requests)
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{elicitations: sub}, nil, logger.NewNopLogger())
+
+		w, c := newRequest()
+		handler.ElicitationEvents(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `data: {"jsonrpc":"2.0","id":7,"method":"elicitation/create"`)
+		assert.True(t, sub.closed)
+	})
+
+	t.Run("denied by policy", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{elicitationErr: gateway.ErrElicitationDenied}, nil, logger.NewNopLogger())
+
+		w, c := newRequest()
+		handler.ElicitationEvents(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("subscribe failure", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{elicitationErr: errors.New("event stream failed")}, nil, logger.NewNopLogger())
+
+		w, c := newRequest()
+		handler.ElicitationEvents(c)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("too many subscribers", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{elicitationErr: gateway.ErrTooManySubscribers}, nil, logger.NewNopLogger())
+
+		w, c := newRequest()
+		handler.ElicitationEvents(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestGatewayHandler_RespondElicitation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRequest := func(body string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/elicitation/respond", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "server_id", Value: "server-1"}}
+		return w, c
+	}
+
+	t.Run("forwards the result", func(t *testing.T) {
+		svc := &mockGatewayService{}
+		handler := NewGatewayHandlerWithInterface(svc, nil, logger.NewNopLogger())
+
+		w, c := newRequest(`{"jsonrpc":"2.0","id":7,"result":{"action":"accept","content":{"name":"Ada"}}}`)
+		handler.RespondElicitation(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		require.Len(t, svc.lastResponse, 2)
+		assert.JSONEq(t, `7`, string(svc.lastResponse[0]))
+		assert.JSONEq(t, `{"action":"accept","content":{"name":"Ada"}}`, string(svc.lastResponse[1]))
+		assert.Nil(t, svc.lastResponseErr)
+	})
+
+	t.Run("forwards an error", func(t *testing.T) {
+		svc := &mockGatewayService{}
+		handler := NewGatewayHandlerWithInterface(svc, nil, logger.NewNopLogger())
+
+		w, c := newRequest(`{"jsonrpc":"2.0","id":"e-1","error":{"code":-32603,"message":"client failed"}}`)
+		handler.RespondElicitation(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		require.NotNil(t, svc.lastResponseErr)
+		assert.Equal(t, -32603, svc.lastResponseErr.Code)
+	})
+
+	t.Run("rejects malformed responses", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{}, nil, logger.NewNopLogger())
+
+		for _, body := range []string{
+			`not json`,
+			`{"jsonrpc":"2.0","result":{"action":"accept"}}`,
+			`{"jsonrpc":"2.0","id":7}`,
+			`{"jsonrpc":"2.0","id":7,"result":{},"error":{"code":1,"message":"x"}}`,
+		} {
+			w, c := newRequest(body)
+			handler.RespondElicitation(c)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{respondErr: gateway.ErrElicitationNotPending}, nil, logger.NewNopLogger())
+
+		w, c := newRequest(`{"jsonrpc":"2.0","id":7,"result":{"action":"decline"}}`)
+		handler.RespondElicitation(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		handler := NewGatewayHandlerWithInterface(&mockGatewayService{respondErr: errors.New("connection refused")}, nil, logger.NewNopLogger())
+
+		w, c := newRequest(`{"jsonrpc":"2.0","id":7,"result":{"action":"decline"}}`)
+		handler.RespondElicitation(c)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+}
+
+func TestStripElicitationCapability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/api/v1/gateway/server-1/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{},"roots":{}},"clientInfo":{"name":"c","version":"1"}}}`))
+
+	stripElicitationCapability(c)
+
+	body, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"roots":{}},"clientInfo":{"name":"c","version":"1"}}}`, string(body))
+	assert.Equal(t, int64(len(body)), c.Request.ContentLength)
+}
+
+// jsonRPCBatch returns a batch of n tools/list requests
+func jsonRPCBatch(n int) string {
+	messages := make([]string, n)
+	for i := range messages {
+		messages[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/list"}`, i+1)
+	}
+	return "[" + strings.Join(messages, ",") + "]"
+}
+
+func TestGatewayHandler_MCPProxy_BatchLimit(t *testing.T) {
+	var forwarded atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		serverLimit   int
+		gatewayLimit  int
+		batchSize     int
+		wantForwarded bool
+	}{
+		{name: "server limit rejects larger batch", serverLimit: 3, gatewayLimit: 100, batchSize: 4},
+		{name: "server limit accepts batch at the limit", serverLimit: 3, gatewayLimit: 100, batchSize: 3, wantForwarded: true},
+		{name: "gateway limit applies without a server limit", gatewayLimit: 2, batchSize: 3},
+		{name: "server limit overrides gateway limit", serverLimit: 5, gatewayLimit: 2, batchSize: 5, wantForwarded: true},
+		{name: "zero gateway limit is unlimited", batchSize: 500, wantForwarded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded.Store(0)
+			server := &domain.MCPServer{ID: "server-1", IsActive: true, URL: backend.URL, MaxBatchSize: tt.serverLimit}
+			handler := NewGatewayHandlerWithInterface(&mockGatewayService{
+				server:      server,
+				proxyServer: httputil.NewSingleHostReverseProxy(target),
+			}, nil, logger.NewNopLogger())
+			handler.maxBatchSize = tt.gatewayLimit
+
+			// A real server, since the reverse proxy needs a CloseNotifier
+			router := gin.New()
+			router.POST("/api/v1/mcp/:server_id", handler.MCPProxy)
+			gw := httptest.NewServer(router)
+			defer gw.Close()
+
+			resp, err := http.Post(gw.URL+"/api/v1/mcp/server-1", "application/json", strings.NewReader(jsonRPCBatch(tt.batchSize)))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if tt.wantForwarded {
+				assert.Equal(t, int32(1), forwarded.Load())
+				assert.Equal(t, "[]", string(body))
+				return
+			}
+			assert.Equal(t, int32(0), forwarded.Load(), "no request in an oversized batch reaches the backend")
+			assert.Contains(t, string(body), `"code":-32600`)
+			assert.Contains(t, string(body), `"id":null`)
+		})
+	}
+}
+
+func TestGatewayHandler_MCPProxy_BatchToolCalls(t *testing.T) {
+	var forwarded []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = append(forwarded, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		server        domain.MCPServer
+		batch         string
+		wantForwarded string
+		wantError     string
+	}{
+		{
+			name:   "allowlist rejects the batch",
+			server: domain.MCPServer{AllowedTools: []string{"search"}},
+			batch: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"delete"}}]`,
+			wantError: `"id":2,"error":{"code":-32602,"message":"Tool 'delete' is not allowed on this server"}`,
+		},
+		{
+			name:   "allowlist accepts the batch",
+			server: domain.MCPServer{AllowedTools: []string{"search"}},
+			batch: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`,
+			wantForwarded: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`,
+		},
+		{
+			name:      "namespace allowlist rejects a tool the server allows",
+			server:    domain.MCPServer{AllowedTools: []string{"search", "delete"}, NamespaceAllowedTools: [][]string{{"search"}}},
+			batch:     `[{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"delete"}}]`,
+			wantError: `"id":"a","error":{"code":-32602,"message":"Tool 'delete' is not allowed on this server"}`,
+		},
+		{
+			name:      "namespace allowlist applies to servers without their own",
+			server:    domain.MCPServer{NamespaceAllowedTools: [][]string{{"search"}}},
+			batch:     `[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"delete"}}]`,
+			wantError: `"id":2,"error":{"code":-32602,"message":"Tool 'delete' is not allowed on this server"}`,
+		},
+		{
+			name:   "tool prefix is stripped from every call",
+			server: domain.MCPServer{ToolPrefix: "github"},
+			batch: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"github__search","arguments":{"q":"go"}}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"},` +
+				`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"github__delete"}}]`,
+			wantForwarded: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"q":"go"}}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"},` +
+				`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"delete"}}]`,
+		},
+		{
+			name:      "call without the tool prefix rejects the batch",
+			server:    domain.MCPServer{ToolPrefix: "github"},
+			batch:     `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"github__search"}},{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search"}}]`,
+			wantError: `"id":2,"error":{"code":-32602,"message":"Tool 'search' not found: tools on this server are named 'github__\u003ctool\u003e'"}`,
+		},
+		{
+			name:      "allowlist applies to the unprefixed name",
+			server:    domain.MCPServer{ToolPrefix: "github", AllowedTools: []string{"search"}},
+			batch:     `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"github__delete"}}]`,
+			wantError: `"id":1,"error":{"code":-32602,"message":"Tool 'github__delete' is not allowed on this server"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded = nil
+			server := tt.server
+			server.ID, server.IsActive, server.URL = "server-1", true, backend.URL
+			handler := NewGatewayHandlerWithInterface(&mockGatewayService{
+				server:      &server,
+				proxyServer: httputil.NewSingleHostReverseProxy(target),
+			}, nil, logger.NewNopLogger())
+
+			// A real server, since the reverse proxy needs a CloseNotifier
+			router := gin.New()
+			router.POST("/api/v1/mcp/:server_id", handler.MCPProxy)
+			gw := httptest.NewServer(router)
+			defer gw.Close()
+
+			resp, err := http.Post(gw.URL+"/api/v1/mcp/server-1", "application/json", strings.NewReader(tt.batch))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if tt.wantError != "" {
+				assert.Empty(t, forwarded, "no request in a rejected batch reaches the backend")
+				assert.Contains(t, string(body), tt.wantError)
+				return
+			}
+			require.Len(t, forwarded, 1)
+			assert.JSONEq(t, tt.wantForwarded, forwarded[0])
+		})
+	}
+}
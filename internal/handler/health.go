@@ -2,7 +2,9 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 
@@ -14,6 +16,13 @@ import (
 type HealthHandler struct {
 	db     DatabaseHealthChecker
 	logger logger.Logger
+
+	// Checked by Readyz when set
+	migrations MigrationStatusChecker
+	// latestMigration returns the schema version the server expects
+	latestMigration func() (uint, error)
+	// Set once the schema is current; the check then no longer touches the database
+	migrationsCurrent atomic.Bool
 }
 
 // NewHealthHandler creates a new health handler
@@ -37,6 +46,13 @@ func NewHealthHandlerWithInterface(db DatabaseHealthChecker, log logger.Logger)
 	}
 }
 
+// SetMigrationCheck makes Readyz report not ready until the database schema is at the
+// latest migration this server embeds and not dirty
+func (h *HealthHandler) SetMigrationCheck(migrations MigrationStatusChecker) {
+	h.migrations = migrations
+	h.latestMigration = database.LatestMigrationVersion
+}
+
 // dbHealthAdapter adapts database.DB to DatabaseHealthChecker.
 type dbHealthAdapter struct {
 	db *database.DB
@@ -116,3 +132,74 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 		c.JSON(http.StatusServiceUnavailable, response)
 	}
 }
+
+// Readyz checks that the service can serve traffic: the database answers and its schema
+// is current. Unlike Health (also served at /healthz for liveness), it touches the database.
+// @Summary Readiness probe
+// @Description Check that the database is reachable and migrations are applied
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Success 503 {object} map[string]interface{}
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	checks := gin.H{}
+	ready := true
+
+	if h.db == nil {
+		checks["database"] = gin.H{"healthy": false, "message": "database not configured"}
+		ready = false
+	} else if dbHealth := h.db.Health(c.Request.Context()); !dbHealth.Healthy {
+		checks["database"] = gin.H{"healthy": false, "message": dbHealth.Message}
+		ready = false
+	} else {
+		checks["database"] = gin.H{"healthy": true}
+	}
+
+	// The schema can't be checked without the database
+	if h.migrations != nil && ready {
+		check, ok := h.migrationCheck()
+		checks["migrations"] = check
+		ready = ok
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// migrationCheck compares the applied schema version with the latest migration
+func (h *HealthHandler) migrationCheck() (gin.H, bool) {
+	if h.migrationsCurrent.Load() {
+		return gin.H{"healthy": true}, true
+	}
+
+	latest, err := h.latestMigration()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to read embedded migrations")
+		return gin.H{"healthy": false, "message": err.Error()}, false
+	}
+	version, dirty, err := h.migrations.Status()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Readiness check failed to get migration status")
+		return gin.H{"healthy": false, "message": err.Error()}, false
+	}
+
+	check := gin.H{"version": version, "expected": latest, "dirty": dirty}
+	switch {
+	case dirty:
+		check["message"] = fmt.Sprintf("migration %d failed and left the schema dirty", version)
+	case version < latest:
+		check["message"] = fmt.Sprintf("schema is at version %d, migrations through %d are pending", version, latest)
+	case version > latest:
+		check["message"] = fmt.Sprintf("schema version %d is newer than this server's migrations (%d)", version, latest)
+	default:
+		h.migrationsCurrent.Store(true)
+		check["healthy"] = true
+		return check, true
+	}
+	check["healthy"] = false
+	return check, false
+}
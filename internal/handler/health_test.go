@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -38,6 +39,18 @@ func (m *mockDBHealthChecker) Health(ctx context.Context) DatabaseHealthStatus {
 	}
 }
 
+type mockMigrationChecker struct {
+	err     error
+	version uint
+	dirty   bool
+	calls   int
+}
+
+func (m *mockMigrationChecker) Status() (uint, bool, error) {
+	m.calls++
+	return m.version, m.dirty, m.err
+}
+
 // ======================== Tests ========================
 
 func TestNewHealthHandler(t *testing.T) {
@@ -172,6 +185,103 @@ func TestHealthHandler_Ready(t *testing.T) {
 	})
 }
 
+func TestHealthHandler_Readyz(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	readyz := func(handler *HealthHandler) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/readyz", nil)
+		handler.Readyz(c)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	newHandler := func(db DatabaseHealthChecker, migrations *mockMigrationChecker) *HealthHandler {
+		handler := NewHealthHandlerWithInterface(db, log)
+		handler.SetMigrationCheck(migrations)
+		handler.latestMigration = func() (uint, error) { return 25, nil }
+		return handler
+	}
+
+	t.Run("ready when database answers and schema is current", func(t *testing.T) {
+		migrations := &mockMigrationChecker{version: 25}
+		handler := newHandler(&mockDBHealthChecker{healthy: true}, migrations)
+
+		code, response := readyz(handler)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ready", response["status"])
+		check := response["checks"].(map[string]interface{})["migrations"].(map[string]interface{})
+		assert.Equal(t, true, check["healthy"])
+		assert.Equal(t, float64(25), check["version"])
+
+		// A current schema stays current, so later probes skip the migration query
+		code, _ = readyz(handler)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 1, migrations.calls)
+	})
+
+	t.Run("not ready when database is down", func(t *testing.T) {
+		migrations := &mockMigrationChecker{version: 25}
+		handler := newHandler(&mockDBHealthChecker{healthy: false, message: "connection refused"}, migrations)
+
+		code, response := readyz(handler)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "not_ready", response["status"])
+		dbCheck := response["checks"].(map[string]interface{})["database"].(map[string]interface{})
+		assert.Equal(t, "connection refused", dbCheck["message"])
+		assert.Zero(t, migrations.calls)
+	})
+
+	t.Run("not ready while migrations are pending", func(t *testing.T) {
+		migrations := &mockMigrationChecker{version: 24}
+		handler := newHandler(&mockDBHealthChecker{healthy: true}, migrations)
+
+		code, response := readyz(handler)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		check := response["checks"].(map[string]interface{})["migrations"].(map[string]interface{})
+		assert.Equal(t, false, check["healthy"])
+		assert.Contains(t, check["message"], "pending")
+
+		// Ready once the migrations are applied
+		migrations.version = 25
+		code, _ = readyz(handler)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("not ready when schema is dirty", func(t *testing.T) {
+		handler := newHandler(&mockDBHealthChecker{healthy: true}, &mockMigrationChecker{version: 25, dirty: true})
+
+		code, response := readyz(handler)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		check := response["checks"].(map[string]interface{})["migrations"].(map[string]interface{})
+		assert.Equal(t, true, check["dirty"])
+		assert.Contains(t, check["message"], "dirty")
+	})
+
+	t.Run("not ready when migration status fails", func(t *testing.T) {
+		handler := newHandler(&mockDBHealthChecker{healthy: true}, &mockMigrationChecker{err: errors.New("no schema_migrations table")})
+
+		code, response := readyz(handler)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		check := response["checks"].(map[string]interface{})["migrations"].(map[string]interface{})
+		assert.Equal(t, "no schema_migrations table", check["message"])
+	})
+
+	t.Run("not ready without a database", func(t *testing.T) {
+		code, response := readyz(NewHealthHandlerWithInterface(nil, log))
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "not_ready", response["status"])
+	})
+}
+
 func TestDatabaseHealthStatus(t *testing.T) {
 	t.Run("struct fields", func(t *testing.T) {
 		status := DatabaseHealthStatus{
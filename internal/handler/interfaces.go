@@ -3,10 +3,12 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httputil"
 	"time"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/internal/service/oauth"
 	"github.com/waffles/waffles/internal/service/registry"
 )
@@ -14,6 +16,7 @@ import (
 // RegistryServiceInterface defines the interface for registry service operations.
 type RegistryServiceInterface interface {
 	CreateServer(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error)
+	ImportServers(ctx context.Context, reqs []*domain.ServerCreate, validate func(*domain.ServerCreate) error, continueOnError bool) *domain.ServerImportSummary
 	ListServersForUser(ctx context.Context, filter *domain.ServerFilter, accessibleServerIDs []string) ([]*domain.MCPServer, error)
 	GetServer(ctx context.Context, id string) (*domain.MCPServer, error)
 	UpdateServer(ctx context.Context, id string, req *domain.ServerUpdate) (*domain.MCPServer, error)
@@ -21,8 +24,11 @@ type RegistryServiceInterface interface {
 	ToggleServer(ctx context.Context, id string, enabled bool) (*domain.MCPServer, error)
 	GetHealthStatus(ctx context.Context, serverID string) (*domain.ServerHealth, error)
 	CheckHealth(ctx context.Context, serverID string) error
+	ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error)
+	TestAuth(ctx context.Context, serverID string) (*registry.AuthTestResult, error)
 	TestConnection(ctx context.Context, req *registry.TestConnectionRequest) (*registry.TestConnectionResult, error)
 	CallTool(ctx context.Context, req *registry.CallToolRequest) (*registry.CallToolResult, error)
+	TestCallTool(ctx context.Context, req *registry.TestCallRequest) (*registry.CallToolResult, error)
 }
 
 // ServerAccessServiceInterface defines the interface for server access operations.
@@ -31,6 +37,11 @@ type ServerAccessServiceInterface interface {
 	CanAccessServer(ctx context.Context, roles []string, serverID string, level domain.AccessLevel) (bool, error)
 }
 
+// AuditLogServiceInterface defines the audit log queries used by the audit handler.
+type AuditLogServiceInterface interface {
+	Query(ctx context.Context, filter domain.AuditLogFilter, cursor string) ([]*domain.AuditLog, string, error)
+}
+
 // CreateAPIKeyInput contains the parameters for creating a new API key
 type CreateAPIKeyInput struct {
 	UserID         string
@@ -55,6 +66,7 @@ type APIKeyRepositoryInterface interface {
 	Delete(ctx context.Context, keyID, userID string) error
 	AdminDelete(ctx context.Context, keyID string) error
 	UpdateLastUsed(ctx context.Context, keyID string) error
+	Rotate(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error)
 }
 
 // APIKey represents an API key for use in handler interfaces.
@@ -110,14 +122,39 @@ type GatewayServiceInterface interface {
 	GetTransportType(ctx context.Context, serverID string) (domain.TransportType, *domain.MCPServer, error)
 	CallSSE(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error)
 	CallStreamableHTTP(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error)
+	CallStreamableHTTPWithProgress(ctx context.Context, serverID, method string, params map[string]interface{}, onProgress func(notification json.RawMessage)) (json.RawMessage, error)
 	InitializeStreamableHTTP(ctx context.Context, serverID string) (*MCPSession, error)
 	TerminateStreamableHTTP(ctx context.Context, serverID string) error
+	CallWebSocket(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error)
+	InitializeWebSocket(ctx context.Context, serverID string) (*MCPSession, error)
+	CheckToolCall(ctx context.Context, serverID, toolName string) error
+	RecordToolsList(serverID string, result json.RawMessage)
+	SubscribeResource(ctx context.Context, serverID, uri string) (ResourceSubscription, error)
+	SubscribeElicitations(ctx context.Context, serverID string) (ElicitationSubscription, error)
+	RespondElicitation(ctx context.Context, serverID string, id, result json.RawMessage, rpcErr *gateway.JSONRPCError) error
+	HTTPClient(server *domain.MCPServer) *http.Client
+}
+
+// ResourceSubscription delivers a resource's update notifications (from gateway package).
+type ResourceSubscription interface {
+	Updates() <-chan json.RawMessage
+	Close()
+}
+
+// ElicitationSubscription delivers a server's elicitation requests (from gateway package).
+type ElicitationSubscription interface {
+	Requests() <-chan json.RawMessage
+	Close()
 }
 
 // MCPSession represents an MCP session (from gateway package).
 type MCPSession struct {
 	SessionID       string
 	ProtocolVersion string
+	ServerName      string
+	ServerVersion   string
+	Instructions    string
+	Capabilities    json.RawMessage
 }
 
 // DatabaseHealthChecker defines the interface for database health checks.
@@ -134,6 +171,11 @@ type DatabaseHealthStatus struct {
 	Healthy          bool
 }
 
+// MigrationStatusChecker reports the schema migration version applied to the database.
+type MigrationStatusChecker interface {
+	Status() (version uint, dirty bool, err error)
+}
+
 // NamespaceRepoInterface defines the interface for namespace repository operations.
 type NamespaceRepoInterface interface {
 	Create(ctx context.Context, req *domain.NamespaceCreate) (*domain.Namespace, error)
@@ -150,6 +192,11 @@ type NamespaceRepoInterface interface {
 	GetRoleIDByName(ctx context.Context, roleName string) (string, error)
 }
 
+// NamespaceToolsInterface defines the tools aggregation used by namespace endpoints.
+type NamespaceToolsInterface interface {
+	AggregateToolsPage(ctx context.Context, serverIDs []string, mode gateway.AggregationMode, cursor string) (*gateway.AggregatedToolsList, error)
+}
+
 // OAuthServiceInterface defines the interface for OAuth service operations.
 type OAuthServiceInterface interface {
 	IsEnabled() bool
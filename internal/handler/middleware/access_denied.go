@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/audit"
+)
+
+const (
+	// contextKeyAccessDenial holds the AccessDenial of a request an access check rejected
+	contextKeyAccessDenial = "access_denial"
+	// contextKeyAudited marks requests whose audit log the audit middleware writes
+	contextKeyAudited = "audited"
+)
+
+// AccessDenial describes why an access check rejected a request
+type AccessDenial struct {
+	// Reason is one of the domain.DenyReason constants
+	Reason string
+	// Resource is what was requested: "server:<id>", "namespace:<id>" or a path
+	Resource string
+}
+
+// RecordAccessDenied notes that an access check rejected the request, so its audit log
+// is marked as an access denial. Call it alongside the 403 response.
+func RecordAccessDenied(c *gin.Context, reason, resource string) {
+	c.Set(contextKeyAccessDenial, &AccessDenial{Reason: reason, Resource: resource})
+}
+
+// GetAccessDenial returns the denial recorded for the request, or nil
+func GetAccessDenial(c *gin.Context) *AccessDenial {
+	val, exists := c.Get(contextKeyAccessDenial)
+	if !exists {
+		return nil
+	}
+	denial, _ := val.(*AccessDenial)
+	return denial
+}
+
+// AuditAccessDenied writes an audit log for each request denied by an access check.
+// Requests that also pass through the audit middleware are left to it, so each denial
+// is logged once. Install it after authentication so the user is known.
+func AuditAccessDenied(auditService *audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		denial := GetAccessDenial(c)
+		if denial == nil || c.GetBool(contextKeyAudited) {
+			return
+		}
+
+		requestID := c.GetString(RequestIDKey)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		status := c.Writer.Status()
+		latency := int(time.Since(start).Milliseconds())
+		auditLog := &domain.AuditLog{
+			RequestID:      requestID,
+			Method:         c.Request.Method,
+			Path:           c.Request.URL.Path,
+			ResponseStatus: &status,
+			LatencyMS:      &latency,
+			IPAddress:      c.ClientIP(),
+			UserAgent:      c.Request.UserAgent(),
+		}
+		if userID := GetUserID(c); userID != "" {
+			auditLog.UserID = &userID
+		}
+		setAccessDenial(auditLog, denial)
+
+		// Log asynchronously to avoid blocking response
+		go func() {
+			_ = auditService.Log(context.Background(), auditLog) // Error already logged in service
+		}()
+	}
+}
+
+// setAccessDenial marks auditLog as a denied request
+func setAccessDenial(auditLog *domain.AuditLog, denial *AccessDenial) {
+	auditLog.AccessDenied = true
+	auditLog.DenyReason = &denial.Reason
+	if denial.Resource != "" {
+		auditLog.DeniedResource = &denial.Resource
+	}
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// withAPIKey authenticates requests as userID with apiKey
+func withAPIKey(userID string, apiKey *domain.APIKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKeyUserID, userID)
+		SetAPIKeyInContext(c, apiKey)
+		c.Next()
+	}
+}
+
+// nextAuditLog returns the next audit log written to repo
+func nextAuditLog(t *testing.T, repo *recordingAuditRepo) *domain.AuditLog {
+	t.Helper()
+	select {
+	case log := <-repo.logs:
+		return log
+	case <-time.After(time.Second):
+		t.Fatal("no audit log written")
+		return nil
+	}
+}
+
+func TestAuditMiddleware_DeniedProxyAttempt(t *testing.T) {
+	repo := &recordingAuditRepo{logs: make(chan *domain.AuditLog, 2)}
+	auditService := audit.NewService(repo, logger.NewNopLogger())
+	scope := NewScopeMiddlewareWithLogger(logger.NewNopLogger())
+
+	router := gin.New()
+	router.Use(withAPIKey("user-1", &domain.APIKey{ID: "key-1", Scopes: []string{"gateway:execute"}, AllowedServers: []string{"server-1"}}))
+	router.Use(AuditAccessDenied(auditService))
+	gatewayGroup := router.Group("/api/v1/gateway")
+	gatewayGroup.Use(AuditMiddlewareWithOptions(auditService, DefaultAuditOptions()))
+	gatewayGroup.Use(scope.RequireServerAccess())
+	gatewayGroup.POST("/:server_id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/gateway/server-2", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	log := nextAuditLog(t, repo)
+	assert.True(t, log.AccessDenied)
+	require.NotNil(t, log.DenyReason)
+	assert.Equal(t, domain.DenyReasonServerNotInScope, *log.DenyReason)
+	require.NotNil(t, log.DeniedResource)
+	assert.Equal(t, "server:server-2", *log.DeniedResource)
+	require.NotNil(t, log.UserID)
+	assert.Equal(t, "user-1", *log.UserID)
+	require.NotNil(t, log.ResponseStatus)
+	assert.Equal(t, http.StatusForbidden, *log.ResponseStatus)
+
+	// The denial is logged once, by the gateway's audit middleware
+	select {
+	case extra := <-repo.logs:
+		t.Fatalf("unexpected second audit log: %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Allowed requests aren't marked as denied
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/gateway/server-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, nextAuditLog(t, repo).AccessDenied)
+}
+
+func TestAuditAccessDenied(t *testing.T) {
+	newRouter := func(apiKey *domain.APIKey) (*gin.Engine, *recordingAuditRepo) {
+		repo := &recordingAuditRepo{logs: make(chan *domain.AuditLog, 1)}
+		scope := NewScopeMiddlewareWithLogger(logger.NewNopLogger())
+
+		router := gin.New()
+		router.Use(withAPIKey("user-1", apiKey))
+		router.Use(AuditAccessDenied(audit.NewService(repo, logger.NewNopLogger())))
+		router.GET("/api/v1/namespaces/:id", scope.RequireNamespaceAccess(), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{})
+		})
+		router.DELETE("/api/v1/servers/:id", scope.RequireScope("servers:write"), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{})
+		})
+		return router, repo
+	}
+
+	t.Run("records namespace denials", func(t *testing.T) {
+		router, repo := newRouter(&domain.APIKey{ID: "key-1", Namespaces: []string{"ns-1"}})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/namespaces/ns-2", nil))
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		log := nextAuditLog(t, repo)
+		assert.True(t, log.AccessDenied)
+		assert.Equal(t, domain.DenyReasonNamespaceNotInScope, *log.DenyReason)
+		assert.Equal(t, "namespace:ns-2", *log.DeniedResource)
+		assert.Equal(t, "user-1", *log.UserID)
+		assert.Equal(t, "GET", log.Method)
+		assert.Equal(t, "/api/v1/namespaces/ns-2", log.Path)
+	})
+
+	t.Run("records scope denials", func(t *testing.T) {
+		router, repo := newRouter(&domain.APIKey{ID: "key-1", Scopes: []string{"servers:read"}})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/servers/server-1", nil))
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		log := nextAuditLog(t, repo)
+		assert.Equal(t, domain.DenyReasonScope, *log.DenyReason)
+		assert.Equal(t, "/api/v1/servers/server-1", *log.DeniedResource)
+	})
+
+	t.Run("ignores allowed requests", func(t *testing.T) {
+		router, repo := newRouter(&domain.APIKey{ID: "key-1", Namespaces: []string{"ns-1"}})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/namespaces/ns-1", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		select {
+		case log := <-repo.logs:
+			t.Fatalf("unexpected audit log: %+v", log)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/internal/service/gateway"
 )
 
 // responseWriter wraps gin.ResponseWriter to capture response body
@@ -28,16 +29,28 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 
 // AuditMiddleware creates a middleware for audit logging
 func AuditMiddleware(auditService *audit.Service) gin.HandlerFunc {
+	return AuditMiddlewareWithOptions(auditService, DefaultAuditOptions())
+}
+
+// AuditMiddlewareWithOptions creates a middleware for audit logging that also records the
+// tool, argument summary and outcome of MCP tools/call requests
+func AuditMiddlewareWithOptions(auditService *audit.Service, opts AuditOptions) gin.HandlerFunc {
+	redactKeys := gateway.RedactKeySet(opts.RedactKeys)
+
 	return func(c *gin.Context) {
-		// Generate request ID if not present
-		requestID := c.GetHeader("X-Request-ID")
+		// Reuse the RequestID middleware's ID, or the header's, generating one if neither is set
+		requestID := c.GetString(RequestIDKey)
+		if requestID == "" {
+			requestID = c.GetHeader(RequestIDHeader)
+		}
 		if requestID == "" {
 			requestID = uuid.New().String()
-			c.Header("X-Request-ID", requestID)
+			c.Header(RequestIDHeader, requestID)
 		}
 
 		// Store request ID in context for later use
-		c.Set("request_id", requestID)
+		c.Set(RequestIDKey, requestID)
+		c.Set(contextKeyAudited, true)
 
 		// Capture request body
 		var requestBody json.RawMessage
@@ -54,6 +67,12 @@ func AuditMiddleware(auditService *audit.Service) gin.HandlerFunc {
 			}
 		}
 
+		// Tool calls are recorded with their arguments redacted
+		toolCall, isToolCall := parseAuditToolCall(c.Request.URL.Path, requestBody)
+		if isToolCall {
+			requestBody = redactBody(requestBody, redactKeys)
+		}
+
 		// Capture query params as JSON
 		var queryParams json.RawMessage
 		if len(c.Request.URL.RawQuery) > 0 {
@@ -120,6 +139,20 @@ func AuditMiddleware(auditService *audit.Service) gin.HandlerFunc {
 			ErrorMessage:   errorMessage,
 		}
 
+		if userID := GetUserID(c); userID != "" {
+			auditLog.UserID = &userID
+		}
+
+		if denial := GetAccessDenial(c); denial != nil {
+			setAccessDenial(auditLog, denial)
+		}
+
+		if isToolCall {
+			auditLog.ToolName = &toolCall.name
+			auditLog.ToolArguments = summarizeArguments(toolCall.arguments, redactKeys, opts.MaxArgumentBytes)
+			auditLog.ToolIsError, auditLog.ToolErrorCode = toolCallOutcome(c.Writer.Header().Get("Content-Type"), blw.body.Bytes())
+		}
+
 		// Log asynchronously to avoid blocking response
 		// Use background context since request context will be canceled
 		go func() {
@@ -1,19 +1,27 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/audit"
+	"github.com/waffles/waffles/pkg/logger"
 )
 
-// Note: Middleware tests are best done as integration tests with a real
-// audit service and database, since the middleware depends on concrete types.
-//
-// See test_gateway.sh for end-to-end testing of the audit middleware
-// in action.
-//
-// Unit tests here would require:
-// 1. Creating an interface for the audit service (refactor)
-// 2. Using dependency injection with mocks
-// 3. Or using integration tests with testcontainers
+// Note: Most of the audit middleware is best covered by integration tests with a real
+// audit service and database. See test_gateway.sh for end-to-end testing of the audit
+// middleware in action. Tool call auditing is unit tested below with a recording
+// repository.
 //
 // Integration tests should cover:
 // - RequestID generation
@@ -32,3 +40,113 @@ func TestPlaceholder(t *testing.T) {
 	// Real tests should be integration tests
 	t.Log("Audit middleware should be tested with integration tests")
 }
+
+// recordingAuditRepo hands each created audit log to a channel
+type recordingAuditRepo struct {
+	logs chan *domain.AuditLog
+}
+
+func (r *recordingAuditRepo) Create(ctx context.Context, log *domain.AuditLog) error {
+	r.logs <- log
+	return nil
+}
+
+func (r *recordingAuditRepo) Get(ctx context.Context, id string) (*domain.AuditLog, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *recordingAuditRepo) List(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLog, error) {
+	return nil, nil
+}
+
+// auditRequest sends body through the audit middleware to a handler returning response
+// with contentType, and returns the audit log written for it
+func auditRequest(t *testing.T, opts AuditOptions, path, body, contentType, response string) *domain.AuditLog {
+	t.Helper()
+
+	repo := &recordingAuditRepo{logs: make(chan *domain.AuditLog, 1)}
+	router := gin.New()
+	router.Use(AuditMiddlewareWithOptions(audit.NewService(repo, logger.NewNopLogger()), opts))
+	router.POST("/api/v1/gateway/:server_id/*rest", func(c *gin.Context) {
+		c.Data(http.StatusOK, contentType, []byte(response))
+	})
+	router.POST("/api/v1/gateway/:server_id", func(c *gin.Context) {
+		c.Data(http.StatusOK, contentType, []byte(response))
+	})
+
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case log := <-repo.logs:
+		return log
+	case <-time.After(time.Second):
+		t.Fatal("no audit log written")
+		return nil
+	}
+}
+
+func TestAuditMiddleware_ToolCalls(t *testing.T) {
+	opts := DefaultAuditOptions()
+
+	t.Run("records the tool, redacted arguments and result", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"deploy","arguments":{"env":"prod","credentials":{"API_KEY":"k-123"},"password":"hunter2"}}}`
+		log := auditRequest(t, opts, "/api/v1/gateway/server-1", body, "application/json",
+			`{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"failed"}],"isError":true}}`)
+
+		require.NotNil(t, log.ToolName)
+		assert.Equal(t, "deploy", *log.ToolName)
+		assert.JSONEq(t, `{"env":"prod","credentials":{"API_KEY":"[REDACTED]"},"password":"[REDACTED]"}`, string(log.ToolArguments))
+		require.NotNil(t, log.ToolIsError)
+		assert.True(t, *log.ToolIsError)
+		assert.Nil(t, log.ToolErrorCode)
+
+		// The stored request body is redacted too
+		assert.NotContains(t, string(log.RequestBody), "hunter2")
+		assert.NotContains(t, string(log.RequestBody), "k-123")
+		assert.Contains(t, string(log.RequestBody), `"env":"prod"`)
+	})
+
+	t.Run("records JSON-RPC errors from streamed responses", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"q":"x"}}}`
+		log := auditRequest(t, opts, "/api/v1/gateway/server-1", body, "text/event-stream",
+			"event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"error\":{\"code\":-32602,\"message\":\"bad args\"}}\n\n")
+
+		require.NotNil(t, log.ToolErrorCode)
+		assert.Equal(t, -32602, *log.ToolErrorCode)
+		assert.Nil(t, log.ToolIsError)
+	})
+
+	t.Run("records REST tool calls", func(t *testing.T) {
+		log := auditRequest(t, opts, "/api/v1/gateway/server-1/tools/call", `{"name":"search","arguments":{"q":"x"}}`, "application/json",
+			`{"content":[{"type":"text","text":"ok"}]}`)
+
+		require.NotNil(t, log.ToolName)
+		assert.Equal(t, "search", *log.ToolName)
+		require.NotNil(t, log.ToolIsError)
+		assert.False(t, *log.ToolIsError)
+	})
+
+	t.Run("truncates large arguments", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"write","arguments":{"path":"a.txt","content":"` + strings.Repeat("x", 1000) + `"}}}`
+
+		log := auditRequest(t, opts, "/api/v1/gateway/server-1", body, "application/json", `{"jsonrpc":"2.0","id":1,"result":{}}`)
+		assert.Less(t, len(log.ToolArguments), 400)
+		assert.Contains(t, string(log.ToolArguments), "(truncated)")
+
+		log = auditRequest(t, AuditOptions{MaxArgumentBytes: 64}, "/api/v1/gateway/server-1", body, "application/json", `{"jsonrpc":"2.0","id":1,"result":{}}`)
+		var summary map[string]interface{}
+		require.NoError(t, json.Unmarshal(log.ToolArguments, &summary))
+		assert.Equal(t, true, summary["_truncated"])
+		assert.ElementsMatch(t, []interface{}{"path", "content"}, summary["_keys"])
+	})
+
+	t.Run("other requests carry no tool context", func(t *testing.T) {
+		log := auditRequest(t, opts, "/api/v1/gateway/server-1", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, "application/json", `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`)
+
+		assert.Nil(t, log.ToolName)
+		assert.Nil(t, log.ToolArguments)
+		assert.Nil(t, log.ToolIsError)
+	})
+}
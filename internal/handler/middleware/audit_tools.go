@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+// AuditOptions controls what the audit middleware records about MCP tool calls
+type AuditOptions struct {
+	// RedactKeys are argument keys, matched case-insensitively at any depth, whose values
+	// are replaced before the request is stored
+	RedactKeys []string
+	// MaxArgumentBytes caps the stored argument summary; larger summaries keep only the
+	// argument names
+	MaxArgumentBytes int
+}
+
+// maxAuditStringLen is the longest argument string kept whole in the summary
+const maxAuditStringLen = 256
+
+// DefaultAuditOptions returns the options used when none are configured
+func DefaultAuditOptions() AuditOptions {
+	return AuditOptions{
+		RedactKeys:       []string{"password", "secret", "token", "api_key", "apikey", "authorization"},
+		MaxArgumentBytes: 2048,
+	}
+}
+
+// auditToolCall is the tools/call context recorded with an audit log
+type auditToolCall struct {
+	name      string
+	arguments json.RawMessage
+}
+
+// parseAuditToolCall extracts the tool call from a gateway request body: a JSON-RPC
+// tools/call request, or the {name, arguments} params of the REST tools/call endpoint
+func parseAuditToolCall(path string, body []byte) (*auditToolCall, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, false
+	}
+
+	params := req.Params
+	switch {
+	case req.Method == "tools/call":
+	case req.Method == "" && strings.HasSuffix(path, "/tools/call"):
+		if len(params) == 0 {
+			params = body
+		}
+	default:
+		return nil, false
+	}
+
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil || call.Name == "" {
+		return nil, false
+	}
+	return &auditToolCall{name: call.Name, arguments: call.Arguments}, true
+}
+
+// truncateJSONStrings shortens long strings in a decoded JSON value
+func truncateJSONStrings(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if len(v) > maxAuditStringLen {
+			return v[:maxAuditStringLen] + "...(truncated)"
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = truncateJSONStrings(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = truncateJSONStrings(item)
+		}
+	}
+	return value
+}
+
+// redactBody returns a JSON body with redacted keys replaced, or body unchanged when it
+// isn't JSON
+func redactBody(body json.RawMessage, keys map[string]bool) json.RawMessage {
+	if len(body) == 0 || len(keys) == 0 {
+		return body
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return body
+	}
+	redacted, err := json.Marshal(gateway.RedactJSON(decoded, keys))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// summarizeArguments returns the redacted tool arguments with long strings shortened.
+// A summary still over maxBytes is replaced by the argument names.
+func summarizeArguments(arguments json.RawMessage, keys map[string]bool, maxBytes int) json.RawMessage {
+	if len(arguments) == 0 {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(arguments, &decoded); err != nil {
+		return nil
+	}
+	summary, err := json.Marshal(truncateJSONStrings(gateway.RedactJSON(decoded, keys)))
+	if err != nil {
+		return nil
+	}
+	if maxBytes <= 0 || len(summary) <= maxBytes {
+		return summary
+	}
+
+	names := []string{}
+	if object, ok := decoded.(map[string]interface{}); ok {
+		for name := range object {
+			names = append(names, name)
+		}
+	}
+	summary, _ = json.Marshal(map[string]interface{}{
+		"_truncated": true,
+		"_bytes":     len(arguments),
+		"_keys":      names,
+	})
+	return summary
+}
+
+// toolCallOutcome reads how a tool call ended from the gateway's response: the result's
+// isError flag, or the JSON-RPC error code. Streamed responses are searched for the
+// message carrying the result.
+func toolCallOutcome(contentType string, body []byte) (isError *bool, errorCode *int) {
+	if strings.Contains(contentType, "text/event-stream") {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := []byte(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			if isErr, code, ok := parseToolCallOutcome(data); ok {
+				isError, errorCode = isErr, code
+			}
+		}
+		return isError, errorCode
+	}
+
+	isError, errorCode, _ = parseToolCallOutcome(body)
+	return isError, errorCode
+}
+
+// parseToolCallOutcome reads a JSON-RPC response, or a bare tools/call result as returned
+// by the REST endpoint
+func parseToolCallOutcome(data []byte) (*bool, *int, bool) {
+	var msg struct {
+		Result *struct {
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+		IsError *bool           `json:"isError"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, nil, false
+	}
+
+	switch {
+	case msg.Error != nil:
+		code := msg.Error.Code
+		return nil, &code, true
+	case msg.Result != nil:
+		isError := msg.Result.IsError
+		return &isError, nil, true
+	case msg.IsError != nil:
+		return msg.IsError, nil, true
+	case msg.Content != nil:
+		isError := false
+		return &isError, nil, true
+	}
+	return nil, nil, false
+}
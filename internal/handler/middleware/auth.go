@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -76,6 +78,12 @@ type AuthConfig struct {
 	OAuthValidator OAuthValidator
 	SessionName    string
 	MCPAuth        MCPAuthConfig
+
+	// APIKeyInactivityTimeout rejects keys unused for longer than this (0 = disabled)
+	APIKeyInactivityTimeout time.Duration
+
+	// SessionStore, when set, must still hold a cookie session for it to be honored
+	SessionStore SessionStore
 }
 
 // NewAuthConfig creates an AuthConfig from concrete repository types.
@@ -96,7 +104,7 @@ func SessionAuth(cfg *AuthConfig) gin.HandlerFunc {
 		session := sessions.Default(c)
 		userID := session.Get(ContextKeyUserID)
 
-		if userID == nil {
+		if userID == nil || !sessionActive(c, cfg, session, userID) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
 				"message": "Please log in to access this resource",
@@ -127,8 +135,7 @@ func APIKeyAuth(cfg *AuthConfig) gin.HandlerFunc {
 		}
 
 		// Validate the API key
-		keyHash := repository.HashAPIKey(apiKey)
-		key, err := cfg.APIKeyRepo.GetByHash(c.Request.Context(), keyHash)
+		key, err := lookupAPIKey(c.Request.Context(), cfg, apiKey)
 		if err != nil {
 			cfg.Logger.Warn().Err(err).Msg("Invalid API key attempt")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -182,6 +189,19 @@ func APIKeyAuth(cfg *AuthConfig) gin.HandlerFunc {
 	}
 }
 
+// lookupAPIKey resolves a plain API key to its record, rejecting keys past their expiry
+// (enforced by the repository) or unused for longer than the inactivity timeout
+func lookupAPIKey(ctx context.Context, cfg *AuthConfig, plainKey string) (*repository.APIKey, error) {
+	key, err := cfg.APIKeyRepo.GetByHash(ctx, repository.HashAPIKey(plainKey))
+	if err != nil {
+		return nil, err
+	}
+	if key.IsInactive(cfg.APIKeyInactivityTimeout, time.Now()) {
+		return nil, fmt.Errorf("%w: unused for more than %s", domain.ErrAPIKeyExpired, cfg.APIKeyInactivityTimeout)
+	}
+	return key, nil
+}
+
 // CombinedAuth creates a middleware that accepts session, API key, or OAuth bearer token authentication
 // This is useful for endpoints that should work for both browser and programmatic access (including MCP clients)
 func CombinedAuth(cfg *AuthConfig) gin.HandlerFunc {
@@ -191,8 +211,7 @@ func CombinedAuth(cfg *AuthConfig) gin.HandlerFunc {
 		apiKey := extractAPIKey(c)
 		if apiKey != "" && cfg.MCPAuth.APIKeyEnabled {
 			// Validate API key
-			keyHash := repository.HashAPIKey(apiKey)
-			key, err := cfg.APIKeyRepo.GetByHash(c.Request.Context(), keyHash)
+			key, err := lookupAPIKey(c.Request.Context(), cfg, apiKey)
 			if err == nil {
 				// Valid API key - get user info
 				user, err := cfg.UserRepo.GetByID(c.Request.Context(), key.UserID)
@@ -286,7 +305,7 @@ func CombinedAuth(cfg *AuthConfig) gin.HandlerFunc {
 			session := sessions.Default(c)
 			userID := session.Get(ContextKeyUserID)
 
-			if userID != nil {
+			if userID != nil && sessionActive(c, cfg, session, userID) {
 				// Set user context from session
 				c.Set(ContextKeyUserID, userID)
 				c.Set(ContextKeyUserEmail, session.Get(ContextKeyUserEmail))
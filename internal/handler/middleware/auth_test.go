@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -1242,6 +1243,83 @@ func TestAPIKeyAuth(t *testing.T) {
 	})
 }
 
+func TestAPIKeyAuth_Expiry(t *testing.T) {
+	activeUser := &mockUserRepo{
+		user:  &domain.User{ID: "user-123", Email: "test@example.com", IsActive: true},
+		roles: []string{"operator"},
+	}
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-100 * 24 * time.Hour)
+	soon := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name              string
+		repo              *mockAPIKeyRepo
+		inactivityTimeout time.Duration
+		expectedStatus    int
+	}{
+		{
+			name:           "expired key is rejected",
+			repo:           &mockAPIKeyRepo{getErr: domain.ErrAPIKeyExpired},
+			expectedStatus: 401,
+		},
+		{
+			name: "key near expiry is accepted",
+			repo: &mockAPIKeyRepo{key: &repository.APIKey{
+				ID: "key-123", UserID: "user-123", ExpiresAt: &soon, LastUsedAt: &recently, CreatedAt: longAgo,
+			}},
+			inactivityTimeout: 90 * 24 * time.Hour,
+			expectedStatus:    200,
+		},
+		{
+			name: "key unused past the inactivity timeout is rejected",
+			repo: &mockAPIKeyRepo{key: &repository.APIKey{
+				ID: "key-123", UserID: "user-123", LastUsedAt: &longAgo, CreatedAt: longAgo,
+			}},
+			inactivityTimeout: 90 * 24 * time.Hour,
+			expectedStatus:    401,
+		},
+		{
+			name: "idle key is accepted when inactivity expiry is disabled",
+			repo: &mockAPIKeyRepo{key: &repository.APIKey{
+				ID: "key-123", UserID: "user-123", LastUsedAt: &longAgo, CreatedAt: longAgo,
+			}},
+			expectedStatus: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		for name, auth := range map[string]func(*AuthConfig) gin.HandlerFunc{"APIKeyAuth": APIKeyAuth, "CombinedAuth": CombinedAuth} {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repo := *tt.repo // Each run gets its own mock; last-used updates are async
+				cfg := &AuthConfig{
+					Logger:                  logger.NewNopLogger(),
+					APIKeyRepo:              &repo,
+					UserRepo:                activeUser,
+					MCPAuth:                 MCPAuthConfig{APIKeyEnabled: true},
+					APIKeyInactivityTimeout: tt.inactivityTimeout,
+				}
+
+				w := httptest.NewRecorder()
+				router := gin.New()
+				router.Use(auth(cfg))
+				router.GET("/protected", func(c *gin.Context) {
+					c.JSON(200, gin.H{"ok": true})
+				})
+
+				req := httptest.NewRequest("GET", "/protected", nil)
+				req.Header.Set("Authorization", "Bearer mcpgw_testkey123")
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				if tt.expectedStatus == 401 {
+					assert.Contains(t, w.Body.String(), "Invalid or expired API key")
+				}
+			})
+		}
+	}
+}
+
 // Tests for CombinedAuth middleware.
 func TestCombinedAuth(t *testing.T) {
 	t.Run("authenticates with API key when enabled", func(t *testing.T) {
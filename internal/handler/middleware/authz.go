@@ -4,16 +4,22 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 
+	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
+// PolicyEnforcer decides whether a role may perform an action on a path.
+// Both casbin.Enforcer and casbin.SyncedEnforcer implement it.
+type PolicyEnforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
 // AuthzConfig contains configuration for authorization middleware
 type AuthzConfig struct {
 	Logger   logger.Logger
-	Enforcer *casbin.Enforcer
+	Enforcer PolicyEnforcer
 }
 
 // formatRoles converts a slice of roles to a comma-separated string for logging
@@ -34,6 +40,7 @@ func Authz(cfg *AuthzConfig) gin.HandlerFunc {
 				Str("path", path).
 				Str("method", method).
 				Msg("Access denied: no roles assigned")
+			RecordAccessDenied(c, domain.DenyReasonNoRoles, path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "forbidden",
 				"message": "No roles assigned to user",
@@ -65,6 +72,7 @@ func Authz(cfg *AuthzConfig) gin.HandlerFunc {
 				Str("path", path).
 				Str("method", method).
 				Msg("Access denied: insufficient permissions")
+			RecordAccessDenied(c, domain.DenyReasonPermission, path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "forbidden",
 				"message": "You don't have permission to access this resource",
@@ -100,6 +108,7 @@ func RequireRoles(cfg *AuthzConfig, requiredRoles ...string) gin.HandlerFunc {
 				Str("user_roles", formatRoles(userRoles)).
 				Str("required_roles", formatRoles(requiredRoles)).
 				Msg("Access denied: missing required role")
+			RecordAccessDenied(c, domain.DenyReasonMissingRole, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "forbidden",
 				"message": "This action requires specific roles",
@@ -140,6 +149,7 @@ func RequirePermission(cfg *AuthzConfig, resource, action string) gin.HandlerFun
 				Str("resource", resource).
 				Str("action", action).
 				Msg("Access denied: insufficient permissions")
+			RecordAccessDenied(c, domain.DenyReasonPermission, resource)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "forbidden",
 				"message": "You don't have permission to perform this action",
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// GatewayLoop rejects proxy requests that already passed through this gateway with
+// 508 Loop Detected. Requests it lets through carry this gateway's ID upstream, so a
+// backend that leads back here is caught on its second pass.
+func GatewayLoop(guard *gateway.LoopGuard, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hops := c.GetHeader(gateway.HeaderGatewayHops)
+		if guard.Seen(hops) {
+			log.Warn().
+				Str("path", c.Request.URL.Path).
+				Str("hops", hops).
+				Msg("Rejected request that looped back to the gateway")
+			c.AbortWithStatusJSON(http.StatusLoopDetected, gin.H{
+				"error":   "loop_detected",
+				"message": "Request already passed through this gateway; check for a server URL that points back at it",
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(gateway.WithGatewayHops(c.Request.Context(), guard.NextHops(hops)))
+		c.Next()
+	}
+}
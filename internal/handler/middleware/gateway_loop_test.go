@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// staticServerRepo serves a single server
+type staticServerRepo struct {
+	server *domain.MCPServer
+}
+
+func (r *staticServerRepo) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
+	return r.server, nil
+}
+
+func TestGatewayLoop_BreaksProxyLoop(t *testing.T) {
+	guard, err := gateway.NewLoopGuard("gateway-a")
+	require.NoError(t, err)
+
+	repo := &staticServerRepo{}
+	svc := gateway.NewServiceWithClients(repo, logger.NewNopLogger(), nil, nil, nil)
+
+	var proxied atomic.Int32
+	router := gin.New()
+	router.Any("/api/v1/gateway/:server_id", GatewayLoop(guard, logger.NewNopLogger()), func(c *gin.Context) {
+		proxied.Add(1)
+		proxy, _, err := svc.ProxyToServer(c.Request.Context(), c.Param("server_id"))
+		require.NoError(t, err)
+		proxy.ServeHTTP(c.Writer, c.Request)
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// The server's URL is the gateway's own proxy endpoint for it
+	repo.server = &domain.MCPServer{ID: "self", URL: ts.URL + "/api/v1/gateway/self", IsActive: true}
+
+	resp, err := http.Post(ts.URL+"/api/v1/gateway/self", "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusLoopDetected, resp.StatusCode)
+	assert.Equal(t, int32(1), proxied.Load(), "the looped request is rejected before being proxied again")
+}
+
+func TestGatewayLoop(t *testing.T) {
+	guard, err := gateway.NewLoopGuard("gateway-a")
+	require.NoError(t, err)
+
+	var upstreamHops string
+	router := gin.New()
+	router.GET("/gateway", GatewayLoop(guard, logger.NewNopLogger()), func(c *gin.Context) {
+		req, _ := http.NewRequestWithContext(c.Request.Context(), "GET", "http://backend.example.com", nil)
+		gateway.SetGatewayHops(req)
+		upstreamHops = req.Header.Get(gateway.HeaderGatewayHops)
+		c.Status(http.StatusOK)
+	})
+
+	for _, tt := range []struct {
+		name     string
+		hops     string
+		wantCode int
+		wantHops string
+	}{
+		{name: "first hop", wantCode: http.StatusOK, wantHops: "gateway-a"},
+		{name: "through another gateway", hops: "gateway-b", wantCode: http.StatusOK, wantHops: "gateway-b, gateway-a"},
+		{name: "back through this gateway", hops: "gateway-b, gateway-a", wantCode: http.StatusLoopDetected},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			upstreamHops = ""
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/gateway", nil)
+			if tt.hops != "" {
+				req.Header.Set(gateway.HeaderGatewayHops, tt.hops)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantHops, upstreamHops)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"math/rand/v2"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -8,8 +9,52 @@ import (
 	"github.com/waffles/waffles/pkg/logger"
 )
 
-// Logger returns a middleware that logs HTTP requests
+// AccessLogConfig controls which requests the Logger middleware logs and with which fields
+type AccessLogConfig struct {
+	// SampleRate is the fraction of successful (below 400) requests logged, from 0 to 1.
+	// Client and server errors are always logged.
+	SampleRate       float64
+	IncludeUserAgent bool
+	IncludeUserID    bool // Requires authentication to run after the logger sets it
+	// IncludeLatencyBucket adds a coarse latency_bucket field (e.g. "100ms-1s") that is
+	// easier to group by than the exact latency
+	IncludeLatencyBucket bool
+}
+
+// DefaultAccessLogConfig logs every request with the user agent
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{SampleRate: 1, IncludeUserAgent: true}
+}
+
+// latencyBuckets are the upper bounds of the latency_bucket values, with their names
+var latencyBuckets = []struct {
+	max  time.Duration
+	name string
+}{
+	{10 * time.Millisecond, "<10ms"},
+	{100 * time.Millisecond, "10ms-100ms"},
+	{time.Second, "100ms-1s"},
+	{10 * time.Second, "1s-10s"},
+}
+
+// latencyBucket returns the name of the bucket latency falls in
+func latencyBucket(latency time.Duration) string {
+	for _, bucket := range latencyBuckets {
+		if latency < bucket.max {
+			return bucket.name
+		}
+	}
+	return ">=10s"
+}
+
+// Logger returns a middleware that logs every HTTP request
 func Logger(log logger.Logger) gin.HandlerFunc {
+	return LoggerWithConfig(log, DefaultAccessLogConfig())
+}
+
+// LoggerWithConfig returns a middleware that logs HTTP requests, sampling successful ones
+// at cfg.SampleRate to keep the log manageable at high request rates
+func LoggerWithConfig(log logger.Logger, cfg AccessLogConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -22,6 +67,21 @@ func Logger(log logger.Logger) gin.HandlerFunc {
 
 		// Calculate latency
 		latency := time.Since(start)
+		status := c.Writer.Status()
+
+		// Log errors if any
+		if len(c.Errors) > 0 {
+			for _, err := range c.Errors {
+				log.Error().
+					Err(err.Err).
+					Any("type", err.Type).
+					Msg("Request error")
+			}
+		}
+
+		if status < 400 && !sampled(cfg.SampleRate) {
+			return
+		}
 
 		// Build log event
 		event := log.Info()
@@ -31,25 +91,35 @@ func Logger(log logger.Logger) gin.HandlerFunc {
 			event = event.Str("request_id", requestID.(string))
 		}
 
-		event.
+		event = event.
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Str("query", c.Request.URL.RawQuery).
-			Int("status", c.Writer.Status()).
+			Int("status", status).
 			Dur("latency", latency).
 			Str("ip", c.ClientIP()).
-			Str("user_agent", c.Request.UserAgent()).
-			Int("response_size", c.Writer.Size()).
-			Msg("HTTP request completed")
+			Int("response_size", c.Writer.Size())
 
-		// Log errors if any
-		if len(c.Errors) > 0 {
-			for _, err := range c.Errors {
-				log.Error().
-					Err(err.Err).
-					Any("type", err.Type).
-					Msg("Request error")
+		if cfg.IncludeUserAgent {
+			event = event.Str("user_agent", c.Request.UserAgent())
+		}
+		if cfg.IncludeUserID {
+			if userID := c.GetString(ContextKeyUserID); userID != "" {
+				event = event.Str("user_id", userID)
 			}
 		}
+		if cfg.IncludeLatencyBucket {
+			event = event.Str("latency_bucket", latencyBucket(latency))
+		}
+
+		event.Msg("HTTP request completed")
+	}
+}
+
+// sampled reports whether a request is picked for logging at rate
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
 	}
+	return rate > 0 && rand.Float64() < rate // #nosec G404 -- sampling doesn't need a secure source
 }
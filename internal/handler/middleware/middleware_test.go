@@ -1,15 +1,19 @@
 package middleware
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -69,6 +73,35 @@ func TestRequestID(t *testing.T) {
 		// UUID format: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
 		assert.Len(t, idStr, 36)
 		assert.Contains(t, idStr, "-")
+		_, err := uuid.Parse(idStr)
+		assert.NoError(t, err)
+	})
+
+	t.Run("stores the ID in the request context for upstream calls", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Request.Header.Set(RequestIDHeader, "trace-abc")
+
+		RequestID()(c)
+
+		assert.Equal(t, "trace-abc", gateway.RequestIDFromContext(c.Request.Context()))
+	})
+
+	t.Run("replaces unsafe inbound IDs", func(t *testing.T) {
+		for _, inbound := range []string{"has space", "line\nbreak", strings.Repeat("a", 129)} {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			c.Request.Header.Set(RequestIDHeader, inbound)
+
+			RequestID()(c)
+
+			requestID := c.GetString(RequestIDKey)
+			assert.NotEqual(t, inbound, requestID)
+			_, err := uuid.Parse(requestID)
+			assert.NoError(t, err)
+		}
 	})
 }
 
@@ -248,6 +281,51 @@ func TestLogger(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
+
+	t.Run("sample rate 0 only logs errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := logger.NewZerolog(logger.Config{Level: logger.InfoLevel, Format: "json", Output: &buf})
+
+		engine := gin.New()
+		engine.Use(LoggerWithConfig(log, AccessLogConfig{SampleRate: 0}))
+		engine.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+		engine.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+		for i := 0; i < 10; i++ {
+			engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+		}
+		assert.Empty(t, buf.String())
+
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+		assert.Contains(t, buf.String(), `"status":500`)
+		assert.Equal(t, 1, strings.Count(buf.String(), "HTTP request completed"))
+	})
+
+	t.Run("optional fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := logger.NewZerolog(logger.Config{Level: logger.InfoLevel, Format: "json", Output: &buf})
+
+		engine := gin.New()
+		engine.Use(LoggerWithConfig(log, AccessLogConfig{SampleRate: 1, IncludeUserID: true, IncludeLatencyBucket: true}))
+		engine.GET("/ok", func(c *gin.Context) {
+			c.Set(ContextKeyUserID, "user-1")
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/ok", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Contains(t, buf.String(), `"user_id":"user-1"`)
+		assert.Contains(t, buf.String(), `"latency_bucket":"<10ms"`)
+		assert.NotContains(t, buf.String(), "test-agent")
+	})
+}
+
+func TestLatencyBucket(t *testing.T) {
+	assert.Equal(t, "<10ms", latencyBucket(time.Millisecond))
+	assert.Equal(t, "100ms-1s", latencyBucket(250*time.Millisecond))
+	assert.Equal(t, ">=10s", latencyBucket(time.Minute))
 }
 
 // ==================== Metrics Tests ====================
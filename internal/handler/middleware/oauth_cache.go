@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// CachingOAuthValidator wraps an OAuthValidator and remembers successful bearer token
+// validations, so repeat requests with the same token skip the identity provider round
+// trip. Entries live for the configured TTL, never past the token's own exp claim, and
+// the least recently used entry is evicted when the cache is full. Failed validations
+// are not cached. It is safe for concurrent use.
+type CachingOAuthValidator struct {
+	OAuthValidator
+
+	ttl        time.Duration
+	maxEntries int
+	metrics    metrics.CacheMetrics
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Token hash -> element in order
+	order   *list.List               // Most recently used first
+}
+
+// oauthCacheEntry is a cached validation result
+type oauthCacheEntry struct {
+	key       string
+	userInfo  OAuthUserInfo
+	expiresAt time.Time
+}
+
+// NewCachingOAuthValidator creates a cache of up to maxEntries validations in front of validator
+func NewCachingOAuthValidator(validator OAuthValidator, ttl time.Duration, maxEntries int) *CachingOAuthValidator {
+	return &CachingOAuthValidator{
+		OAuthValidator: validator,
+		ttl:            ttl,
+		maxEntries:     maxEntries,
+		metrics:        metrics.NopCacheMetrics,
+		now:            time.Now,
+		entries:        make(map[string]*list.Element),
+		order:          list.New(),
+	}
+}
+
+// SetMetrics makes the cache report its effectiveness to metricsReg. Must be called before
+// the validator is used.
+func (v *CachingOAuthValidator) SetMetrics(metricsReg *metrics.Registry) {
+	v.metrics = metricsReg.Cache(metrics.CacheOAuthTokens)
+}
+
+// ValidateBearerToken returns the cached user info for token, validating it with the
+// wrapped validator on a miss
+func (v *CachingOAuthValidator) ValidateBearerToken(ctx context.Context, token string) (*OAuthUserInfo, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	if userInfo, ok := v.get(key); ok {
+		return userInfo, nil
+	}
+
+	userInfo, err := v.OAuthValidator.ValidateBearerToken(ctx, token)
+	if err != nil || userInfo == nil {
+		return userInfo, err
+	}
+
+	now := v.now()
+	expiresAt := now.Add(v.ttl)
+	if exp, ok := tokenExpiry(token); ok && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	if expiresAt.After(now) {
+		v.put(key, *userInfo, expiresAt)
+	}
+	return userInfo, nil
+}
+
+// get returns a copy of the unexpired entry for key
+func (v *CachingOAuthValidator) get(key string) (*OAuthUserInfo, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	elem, ok := v.entries[key]
+	if !ok {
+		v.metrics.Miss()
+		return nil, false
+	}
+	entry := elem.Value.(*oauthCacheEntry)
+	if !v.now().Before(entry.expiresAt) {
+		v.order.Remove(elem)
+		delete(v.entries, key)
+		v.metrics.Miss()
+		v.metrics.SetSize(v.order.Len())
+		return nil, false
+	}
+	v.metrics.Hit()
+	v.order.MoveToFront(elem)
+	userInfo := entry.userInfo
+	return &userInfo, true
+}
+
+// put stores an entry, evicting the least recently used ones beyond maxEntries
+func (v *CachingOAuthValidator) put(key string, userInfo OAuthUserInfo, expiresAt time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if elem, ok := v.entries[key]; ok {
+		elem.Value = &oauthCacheEntry{key: key, userInfo: userInfo, expiresAt: expiresAt}
+		v.order.MoveToFront(elem)
+		return
+	}
+	v.entries[key] = v.order.PushFront(&oauthCacheEntry{key: key, userInfo: userInfo, expiresAt: expiresAt})
+	for v.order.Len() > v.maxEntries {
+		oldest := v.order.Back()
+		v.order.Remove(oldest)
+		delete(v.entries, oldest.Value.(*oauthCacheEntry).key)
+		v.metrics.Evicted(1)
+	}
+	v.metrics.SetSize(v.order.Len())
+}
+
+// tokenExpiry reads the exp claim of a JWT access token. Opaque tokens and tokens without
+// exp report false. The signature isn't checked, so exp is only used to shorten how long
+// a validation is cached, never to extend it.
+func tokenExpiry(token string) (time.Time, bool) {
+	claims, ok := unverifiedClaims(token)
+	if !ok || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// tokenClaims are the JWT claims the gateway reads before a token is validated
+type tokenClaims struct {
+	Iss string       `json:"iss"`
+	Exp *json.Number `json:"exp"`
+}
+
+// unverifiedClaims decodes the payload of a JWT access token without checking its
+// signature. Opaque tokens report false.
+func unverifiedClaims(token string) (tokenClaims, bool) {
+	var claims tokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, false
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, false
+	}
+	return claims, true
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// countingOAuthValidator counts validations and accepts every token except "bad"
+type countingOAuthValidator struct {
+	mockOAuthValidator
+	calls atomic.Int32
+}
+
+func (v *countingOAuthValidator) ValidateBearerToken(ctx context.Context, token string) (*OAuthUserInfo, error) {
+	v.calls.Add(1)
+	if token == "bad" {
+		return nil, errors.New("invalid token")
+	}
+	return &OAuthUserInfo{ID: "ext-" + token, Email: token + "@example.com", Provider: "keycloak"}, nil
+}
+
+// jwtWithExp returns an unsigned JWT-shaped token whose payload has the given exp
+func jwtWithExp(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".sig"
+}
+
+func TestCachingOAuthValidator_CombinedAuthValidatesOnce(t *testing.T) {
+	inner := &countingOAuthValidator{mockOAuthValidator: mockOAuthValidator{enabled: true}}
+	cfg := &AuthConfig{
+		Logger:     logger.NewNopLogger(),
+		APIKeyRepo: &mockAPIKeyRepo{},
+		UserRepo: &mockUserRepo{
+			findOrCreateUser: &domain.User{ID: "user-123", Email: "oauth@example.com", IsActive: true},
+			roles:            []string{"user"},
+		},
+		OAuthValidator: NewCachingOAuthValidator(inner, time.Minute, 100),
+		MCPAuth:        MCPAuthConfig{APIKeyEnabled: true},
+	}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("test_session", cookie.NewStore([]byte("test-secret-key-32-bytes-long!!!"))))
+	router.Use(CombinedAuth(cfg))
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(200, gin.H{"auth_type": GetAuthType(c)})
+	})
+
+	token := jwtWithExp(time.Now().Add(time.Hour))
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		require.Equal(t, 200, w.Code, "request %d", i)
+	}
+
+	assert.Equal(t, int32(1), inner.calls.Load())
+}
+
+func TestCachingOAuthValidator(t *testing.T) {
+	newCache := func(ttl time.Duration, maxEntries int) (*CachingOAuthValidator, *countingOAuthValidator, *time.Time) {
+		inner := &countingOAuthValidator{}
+		cache := NewCachingOAuthValidator(inner, ttl, maxEntries)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		return cache, inner, &now
+	}
+	ctx := context.Background()
+
+	t.Run("hit returns the cached user info", func(t *testing.T) {
+		cache, inner, _ := newCache(time.Minute, 10)
+
+		first, err := cache.ValidateBearerToken(ctx, "alice")
+		require.NoError(t, err)
+		second, err := cache.ValidateBearerToken(ctx, "alice")
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.Equal(t, "alice@example.com", second.Email)
+		assert.Equal(t, int32(1), inner.calls.Load())
+	})
+
+	t.Run("entries expire after the TTL", func(t *testing.T) {
+		cache, inner, now := newCache(time.Minute, 10)
+
+		_, _ = cache.ValidateBearerToken(ctx, "alice")
+		*now = now.Add(59 * time.Second)
+		_, _ = cache.ValidateBearerToken(ctx, "alice")
+		assert.Equal(t, int32(1), inner.calls.Load())
+
+		*now = now.Add(2 * time.Second)
+		_, _ = cache.ValidateBearerToken(ctx, "alice")
+		assert.Equal(t, int32(2), inner.calls.Load())
+	})
+
+	t.Run("token exp bounds the TTL", func(t *testing.T) {
+		cache, inner, now := newCache(time.Hour, 10)
+		token := jwtWithExp(now.Add(30 * time.Second))
+
+		_, _ = cache.ValidateBearerToken(ctx, token)
+		*now = now.Add(20 * time.Second)
+		_, _ = cache.ValidateBearerToken(ctx, token)
+		assert.Equal(t, int32(1), inner.calls.Load())
+
+		*now = now.Add(15 * time.Second)
+		_, _ = cache.ValidateBearerToken(ctx, token)
+		assert.Equal(t, int32(2), inner.calls.Load(), "revalidated once the token's exp passed")
+	})
+
+	t.Run("expired tokens are not cached", func(t *testing.T) {
+		cache, inner, now := newCache(time.Hour, 10)
+		token := jwtWithExp(now.Add(-time.Minute))
+
+		_, _ = cache.ValidateBearerToken(ctx, token)
+		_, _ = cache.ValidateBearerToken(ctx, token)
+		assert.Equal(t, int32(2), inner.calls.Load())
+	})
+
+	t.Run("failures are not cached", func(t *testing.T) {
+		cache, inner, _ := newCache(time.Minute, 10)
+
+		_, err := cache.ValidateBearerToken(ctx, "bad")
+		assert.Error(t, err)
+		_, err = cache.ValidateBearerToken(ctx, "bad")
+		assert.Error(t, err)
+		assert.Equal(t, int32(2), inner.calls.Load())
+	})
+
+	t.Run("least recently used entry is evicted", func(t *testing.T) {
+		cache, inner, _ := newCache(time.Minute, 2)
+
+		_, _ = cache.ValidateBearerToken(ctx, "alice")
+		_, _ = cache.ValidateBearerToken(ctx, "bob")
+		_, _ = cache.ValidateBearerToken(ctx, "alice") // bob is now least recently used
+		_, _ = cache.ValidateBearerToken(ctx, "carol") // evicts bob
+		assert.Equal(t, int32(3), inner.calls.Load())
+		assert.Len(t, cache.entries, 2)
+
+		_, _ = cache.ValidateBearerToken(ctx, "alice")
+		assert.Equal(t, int32(3), inner.calls.Load(), "alice still cached")
+		_, _ = cache.ValidateBearerToken(ctx, "bob")
+		assert.Equal(t, int32(4), inner.calls.Load(), "bob was evicted")
+	})
+
+	t.Run("safe for concurrent use", func(t *testing.T) {
+		cache := NewCachingOAuthValidator(&countingOAuthValidator{}, time.Minute, 5)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					_, err := cache.ValidateBearerToken(ctx, fmt.Sprintf("token-%d", (i+j)%8))
+					assert.NoError(t, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, len(cache.entries), 5)
+	})
+
+	t.Run("delegates the rest of the validator", func(t *testing.T) {
+		cache := NewCachingOAuthValidator(&countingOAuthValidator{mockOAuthValidator: mockOAuthValidator{enabled: true, defaultRole: "viewer"}}, time.Minute, 5)
+
+		assert.True(t, cache.IsEnabled())
+		assert.Equal(t, "viewer", cache.GetDefaultRole())
+	})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1893456000, 0)
+
+	got, ok := tokenExpiry(jwtWithExp(exp))
+	assert.True(t, ok)
+	assert.True(t, exp.Equal(got))
+
+	for _, token := range []string{
+		"opaque-token",
+		"a.b.c",
+		"eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user"}`)) + ".sig",
+	} {
+		_, ok := tokenExpiry(token)
+		assert.False(t, ok, token)
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// OAuthProvider is one identity provider accepted by a MultiOAuthValidator
+type OAuthProvider struct {
+	// Name recorded as the provider of users it authenticates, so the same subject at
+	// two providers maps to two different users
+	Name      string
+	Validator OAuthValidator
+}
+
+// MultiOAuthValidator validates bearer tokens against several OIDC providers. A JWT is
+// sent only to the provider whose issuer matches its unverified iss claim, and is
+// rejected when no provider matches; the signature is still checked by that provider.
+// Opaque tokens carry no issuer and are validated by the primary (first) provider
+// only, so they're never disclosed to the others. Role and user creation settings come
+// from the primary provider.
+type MultiOAuthValidator struct {
+	providers []OAuthProvider
+}
+
+// NewMultiOAuthValidator creates a validator over providers, the first being the primary
+func NewMultiOAuthValidator(providers ...OAuthProvider) *MultiOAuthValidator {
+	return &MultiOAuthValidator{providers: providers}
+}
+
+// ValidateBearerToken validates token with the provider that issued it and records that
+// provider's name in the returned user info
+func (v *MultiOAuthValidator) ValidateBearerToken(ctx context.Context, token string) (*OAuthUserInfo, error) {
+	provider, err := v.providerFor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo, err := provider.Validator.ValidateBearerToken(ctx, token)
+	if err != nil || userInfo == nil {
+		return userInfo, err
+	}
+	if provider.Name != "" {
+		userInfo.Provider = provider.Name
+	}
+	return userInfo, nil
+}
+
+// providerFor returns the enabled provider that should validate token
+func (v *MultiOAuthValidator) providerFor(token string) (*OAuthProvider, error) {
+	claims, ok := unverifiedClaims(token)
+	if !ok || claims.Iss == "" {
+		primary := v.primary()
+		if primary == nil || !primary.Validator.IsEnabled() {
+			return nil, fmt.Errorf("no OAuth provider accepts tokens without an issuer")
+		}
+		return primary, nil
+	}
+
+	issuer := normalizeIssuer(claims.Iss)
+	for i := range v.providers {
+		provider := &v.providers[i]
+		if provider.Validator.IsEnabled() && normalizeIssuer(provider.Validator.GetIssuer()) == issuer {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("token issuer %s is not a configured OAuth provider", claims.Iss)
+}
+
+// primary returns the first provider, or nil when there are none
+func (v *MultiOAuthValidator) primary() *OAuthProvider {
+	if len(v.providers) == 0 {
+		return nil
+	}
+	return &v.providers[0]
+}
+
+// IsEnabled reports whether any provider is enabled
+func (v *MultiOAuthValidator) IsEnabled() bool {
+	for _, provider := range v.providers {
+		if provider.Validator.IsEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIssuer returns the primary provider's issuer URL
+func (v *MultiOAuthValidator) GetIssuer() string {
+	if primary := v.primary(); primary != nil {
+		return primary.Validator.GetIssuer()
+	}
+	return ""
+}
+
+// GetBaseURL returns the primary provider's OAuth base URL
+func (v *MultiOAuthValidator) GetBaseURL() string {
+	if primary := v.primary(); primary != nil {
+		return primary.Validator.GetBaseURL()
+	}
+	return ""
+}
+
+// GetDefaultRole returns the primary provider's default role for OAuth users
+func (v *MultiOAuthValidator) GetDefaultRole() string {
+	if primary := v.primary(); primary != nil {
+		return primary.Validator.GetDefaultRole()
+	}
+	return ""
+}
+
+// AutoCreateUsers returns whether the primary provider auto-creates users
+func (v *MultiOAuthValidator) AutoCreateUsers() bool {
+	if primary := v.primary(); primary != nil {
+		return primary.Validator.AutoCreateUsers()
+	}
+	return false
+}
+
+// normalizeIssuer makes issuers that differ only by a trailing slash compare equal
+func normalizeIssuer(issuer string) string {
+	return strings.TrimSuffix(issuer, "/")
+}
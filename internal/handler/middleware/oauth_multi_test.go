@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// issuerOAuthValidator accepts only tokens issued by its issuer and records the tokens it saw
+type issuerOAuthValidator struct {
+	issuer   string
+	disabled bool
+	err      error
+	tokens   []string
+}
+
+func (v *issuerOAuthValidator) ValidateBearerToken(ctx context.Context, token string) (*OAuthUserInfo, error) {
+	v.tokens = append(v.tokens, token)
+	if v.err != nil {
+		return nil, v.err
+	}
+	claims, ok := unverifiedClaims(token)
+	if ok && claims.Iss != "" && normalizeIssuer(claims.Iss) != normalizeIssuer(v.issuer) {
+		return nil, errors.New("invalid token")
+	}
+	return &OAuthUserInfo{ID: "subject-1", Email: "user@example.com", Name: "User", Provider: "oidc"}, nil
+}
+
+func (v *issuerOAuthValidator) IsEnabled() bool        { return !v.disabled }
+func (v *issuerOAuthValidator) GetIssuer() string      { return v.issuer }
+func (v *issuerOAuthValidator) GetBaseURL() string     { return "https://gateway.example.com" }
+func (v *issuerOAuthValidator) GetDefaultRole() string { return "viewer" }
+func (v *issuerOAuthValidator) AutoCreateUsers() bool  { return true }
+
+// providerUserRepo records which provider and subject each OAuth user was looked up by
+type providerUserRepo struct {
+	mockUserRepo
+	lookups []string
+}
+
+func (r *providerUserRepo) FindOrCreateOAuthUser(ctx context.Context, provider, externalID, email, name string) (*domain.User, bool, error) {
+	r.lookups = append(r.lookups, provider+":"+externalID)
+	return &domain.User{ID: provider + "-user", Email: email, IsActive: true}, false, nil
+}
+
+// jwtFromIssuer returns an unsigned JWT-shaped token whose payload has the given iss
+func jwtFromIssuer(issuer string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"subject-1","iss":%q}`, issuer)))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".sig"
+}
+
+func newTestMultiOAuthValidator() (*MultiOAuthValidator, *issuerOAuthValidator, *issuerOAuthValidator) {
+	keycloak := &issuerOAuthValidator{issuer: "https://keycloak.example.com/realms/waffles"}
+	okta := &issuerOAuthValidator{issuer: "https://example.okta.com/oauth2/default/"}
+	return NewMultiOAuthValidator(
+		OAuthProvider{Name: "oidc", Validator: keycloak},
+		OAuthProvider{Name: "okta", Validator: okta},
+	), keycloak, okta
+}
+
+func TestMultiOAuthValidator_CombinedAuthMapsUsersPerProvider(t *testing.T) {
+	validator, _, _ := newTestMultiOAuthValidator()
+	userRepo := &providerUserRepo{}
+	cfg := &AuthConfig{
+		Logger:         logger.NewNopLogger(),
+		APIKeyRepo:     &mockAPIKeyRepo{},
+		UserRepo:       userRepo,
+		OAuthValidator: validator,
+		MCPAuth:        MCPAuthConfig{APIKeyEnabled: true},
+	}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("test_session", cookie.NewStore([]byte("test-secret-key-32-bytes-long!!!"))))
+	router.Use(CombinedAuth(cfg))
+	router.GET("/protected", func(c *gin.Context) {
+		c.String(200, GetUserID(c))
+	})
+
+	for _, tt := range []struct {
+		issuer string
+		userID string
+	}{
+		{issuer: "https://keycloak.example.com/realms/waffles", userID: "oidc-user"},
+		{issuer: "https://example.okta.com/oauth2/default", userID: "okta-user"},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+jwtFromIssuer(tt.issuer))
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, 200, w.Code, tt.issuer)
+		assert.Equal(t, tt.userID, w.Body.String())
+	}
+
+	// The same subject at each provider is a different user
+	assert.Equal(t, []string{"oidc:subject-1", "okta:subject-1"}, userRepo.lookups)
+}
+
+func TestMultiOAuthValidator_ValidateBearerToken(t *testing.T) {
+	t.Run("tokens go only to the provider that issued them", func(t *testing.T) {
+		validator, keycloak, okta := newTestMultiOAuthValidator()
+
+		userInfo, err := validator.ValidateBearerToken(context.Background(), jwtFromIssuer("https://example.okta.com/oauth2/default"))
+		require.NoError(t, err)
+		assert.Equal(t, "okta", userInfo.Provider)
+		assert.Empty(t, keycloak.tokens)
+		assert.Len(t, okta.tokens, 1)
+
+		userInfo, err = validator.ValidateBearerToken(context.Background(), jwtFromIssuer("https://keycloak.example.com/realms/waffles/"))
+		require.NoError(t, err)
+		assert.Equal(t, "oidc", userInfo.Provider)
+		assert.Len(t, keycloak.tokens, 1)
+		assert.Len(t, okta.tokens, 1)
+	})
+
+	t.Run("unknown issuers are rejected without contacting any provider", func(t *testing.T) {
+		validator, keycloak, okta := newTestMultiOAuthValidator()
+
+		_, err := validator.ValidateBearerToken(context.Background(), jwtFromIssuer("https://evil.example.com"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "https://evil.example.com")
+		assert.Empty(t, keycloak.tokens)
+		assert.Empty(t, okta.tokens)
+	})
+
+	t.Run("opaque tokens are validated by the primary provider only", func(t *testing.T) {
+		validator, keycloak, okta := newTestMultiOAuthValidator()
+
+		userInfo, err := validator.ValidateBearerToken(context.Background(), "opaque-token")
+		require.NoError(t, err)
+		assert.Equal(t, "oidc", userInfo.Provider)
+		assert.Equal(t, []string{"opaque-token"}, keycloak.tokens)
+		assert.Empty(t, okta.tokens)
+	})
+
+	t.Run("disabled providers don't validate tokens", func(t *testing.T) {
+		validator, _, okta := newTestMultiOAuthValidator()
+		okta.disabled = true
+
+		_, err := validator.ValidateBearerToken(context.Background(), jwtFromIssuer("https://example.okta.com/oauth2/default"))
+		require.Error(t, err)
+		assert.Empty(t, okta.tokens)
+		assert.True(t, validator.IsEnabled())
+	})
+
+	t.Run("validation errors are returned", func(t *testing.T) {
+		validator, _, okta := newTestMultiOAuthValidator()
+		okta.err = errors.New("token expired")
+
+		_, err := validator.ValidateBearerToken(context.Background(), jwtFromIssuer("https://example.okta.com/oauth2/default"))
+		assert.EqualError(t, err, "token expired")
+	})
+}
+
+func TestMultiOAuthValidator_PrimarySettings(t *testing.T) {
+	validator, _, _ := newTestMultiOAuthValidator()
+
+	assert.True(t, validator.IsEnabled())
+	assert.Equal(t, "https://keycloak.example.com/realms/waffles", validator.GetIssuer())
+	assert.Equal(t, "https://gateway.example.com", validator.GetBaseURL())
+	assert.Equal(t, "viewer", validator.GetDefaultRole())
+	assert.True(t, validator.AutoCreateUsers())
+
+	empty := NewMultiOAuthValidator()
+	assert.False(t, empty.IsEnabled())
+	assert.Empty(t, empty.GetIssuer())
+	_, err := empty.ValidateBearerToken(context.Background(), "opaque-token")
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+// PriorityConfig controls the priority hint forwarded with each gateway request
+type PriorityConfig struct {
+	Header    string            // Header the client sets and the hint is forwarded in (empty = neither)
+	MetaKey   string            // Key of the hint in forwarded params' _meta (empty = not sent)
+	Levels    []string          // Allowed priorities, lowest first
+	Default   string            // Priority of callers without a role mapping
+	Roles     map[string]string // Priority by role; the highest of a caller's roles applies
+	ServerIDs []string          // Servers whose requests carry the hint (empty = all)
+}
+
+// Priority attaches a priority hint to gateway requests for the clients to forward to the
+// server. The caller's priority comes from their roles, or the default. A client may ask
+// for a lower one with the header, but not a higher one, so the header can't be used to
+// jump the queue.
+func Priority(cfg PriorityConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(cfg.ServerIDs) > 0 && !slices.Contains(cfg.ServerIDs, c.Param("server_id")) {
+			c.Next()
+			return
+		}
+
+		priority := cfg.rolePriority(GetUserRoles(c))
+		if cfg.Header != "" {
+			if requested := c.GetHeader(cfg.Header); cfg.rank(requested) >= 0 && cfg.rank(requested) < cfg.rank(priority) {
+				priority = requested
+			}
+		}
+
+		hint := gateway.PriorityHint{Value: priority, Header: cfg.Header, MetaKey: cfg.MetaKey}
+		c.Request = c.Request.WithContext(gateway.WithPriorityHint(c.Request.Context(), hint))
+		c.Next()
+	}
+}
+
+// rolePriority returns the highest priority mapped to roles, or the default
+func (cfg PriorityConfig) rolePriority(roles []string) string {
+	priority := cfg.Default
+	for _, role := range roles {
+		if mapped, ok := cfg.Roles[role]; ok && cfg.rank(mapped) > cfg.rank(priority) {
+			priority = mapped
+		}
+	}
+	return priority
+}
+
+// rank returns the position of priority among the levels, or -1 when it isn't one
+func (cfg PriorityConfig) rank(priority string) int {
+	return slices.Index(cfg.Levels, priority)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+func TestPriority(t *testing.T) {
+	cfg := PriorityConfig{
+		Header:    "X-MCP-Priority",
+		MetaKey:   "priority",
+		Levels:    []string{"low", "normal", "high"},
+		Default:   "normal",
+		Roles:     map[string]string{"admin": "high", "batch": "low"},
+		ServerIDs: []string{"server-1"},
+	}
+
+	tests := []struct {
+		name     string
+		serverID string
+		roles    []string
+		header   string
+		want     string // "" = no hint
+	}{
+		{name: "defaults when unset", serverID: "server-1", roles: []string{"user"}, want: "normal"},
+		{name: "role mapping", serverID: "server-1", roles: []string{"user", "admin"}, want: "high"},
+		{name: "highest role wins", serverID: "server-1", roles: []string{"batch", "admin"}, want: "high"},
+		{name: "header lowers priority", serverID: "server-1", roles: []string{"admin"}, header: "low", want: "low"},
+		{name: "header can't raise priority", serverID: "server-1", roles: []string{"user"}, header: "high", want: "normal"},
+		{name: "unknown header level ignored", serverID: "server-1", roles: []string{"admin"}, header: "urgent", want: "high"},
+		{name: "other servers get no hint", serverID: "server-2", roles: []string{"admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hint gateway.PriorityHint
+			var ok bool
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(ContextKeyUserRoles, tt.roles)
+				c.Next()
+			})
+			router.GET("/gateway/:server_id", Priority(cfg), func(c *gin.Context) {
+				hint, ok = gateway.PriorityHintFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/gateway/"+tt.serverID, nil)
+			if tt.header != "" {
+				req.Header.Set("X-MCP-Priority", tt.header)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.want == "" {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, gateway.PriorityHint{Value: tt.want, Header: "X-MCP-Priority", MetaKey: "priority"}, hint)
+		})
+	}
+}
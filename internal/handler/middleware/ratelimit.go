@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// RateLimiter decides whether a request identified by key may proceed.
+// MemoryRateLimiter is the default; a shared store (e.g. Redis) can implement
+// this interface to enforce limits across gateway instances.
+type RateLimiter interface {
+	// Allow consumes one request for key. When not allowed, retryAfter is how long
+	// the caller should wait before the next request can succeed.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Rate is a token bucket rate: a sustained requests per second with a burst allowance
+type Rate struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// tokenBucket tracks the tokens available to one key
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// MemoryRateLimiter is an in-process token bucket limiter keyed by caller
+type MemoryRateLimiter struct {
+	limit Rate
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// rateLimitSweepInterval is how often idle buckets are dropped from memory
+const rateLimitSweepInterval = time.Minute
+
+// NewMemoryRateLimiter creates an in-memory token bucket limiter
+func NewMemoryRateLimiter(limit Rate) *MemoryRateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &MemoryRateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow implements RateLimiter
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.limit.Burst), lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill for the time elapsed since the last request
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+elapsed*l.limit.RequestsPerSecond)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+
+	if l.limit.RequestsPerSecond <= 0 {
+		return false, rateLimitSweepInterval, nil
+	}
+	wait := (1 - bucket.tokens) / l.limit.RequestsPerSecond
+	return false, time.Duration(wait * float64(time.Second)), nil
+}
+
+// sweep drops buckets that have refilled completely, since they behave like new ones.
+// Must be called with mu held.
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	if l.limit.RequestsPerSecond <= 0 {
+		return
+	}
+	refill := time.Duration(float64(l.limit.Burst) / l.limit.RequestsPerSecond * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitConfig holds the limiters used by the RateLimit middleware
+type RateLimitConfig struct {
+	// Default applies to read-only and list routes
+	Default RateLimiter
+	// ToolCalls applies to the MCP proxy and tools/call routes (nil = use Default)
+	ToolCalls RateLimiter
+	// RetryAfterJitter is the most random extra wait added to Retry-After so throttled
+	// clients don't all retry at the same moment (0 = exact wait)
+	RetryAfterJitter time.Duration
+	Logger           logger.Logger
+}
+
+// RateLimit returns a middleware that throttles requests per user, falling back to the
+// API key and then the client IP when no user is set. Exhausted callers get 429 with a
+// Retry-After header. Must run after authentication so the caller is known.
+func RateLimit(cfg *RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, class := cfg.Default, "default"
+		if isToolCallRoute(c) && cfg.ToolCalls != nil {
+			limiter, class = cfg.ToolCalls, "call"
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		key := class + ":" + rateLimitKey(c)
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			// Fail open: an unavailable limiter store shouldn't take the gateway down
+			cfg.Logger.Error().Err(err).Str("key", key).Msg("Rate limiter unavailable, allowing request")
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			seconds := retryAfterSeconds(retryAfter, cfg.RetryAfterJitter)
+			cfg.Logger.Warn().
+				Str("key", key).
+				Str("path", c.Request.URL.Path).
+				Int("retry_after", seconds).
+				Msg("Rate limit exceeded")
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "rate_limited",
+				"message": "Rate limit exceeded, retry later",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// retryAfterSeconds converts the limiter's wait into a Retry-After value of at least one
+// second, adding a uniformly random extra wait of up to jitter
+func retryAfterSeconds(retryAfter, jitter time.Duration) int {
+	if jitter > 0 {
+		retryAfter += rand.N(jitter + 1) // #nosec G404 -- jitter doesn't need a secure source
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// rateLimitKey identifies the caller: the user, else the API key, else the client IP
+func rateLimitKey(c *gin.Context) string {
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+	if apiKey := GetAPIKeyFromContext(c); apiKey != nil {
+		return "apikey:" + apiKey.ID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// isToolCallRoute reports whether the request reaches an upstream server through the
+// MCP proxy or the tools/call endpoint
+func isToolCallRoute(c *gin.Context) bool {
+	route := c.FullPath()
+	return strings.HasSuffix(route, "/gateway/:server_id") || strings.HasSuffix(route, "/tools/call")
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestMemoryRateLimiter_Allow(t *testing.T) {
+	limiter := NewMemoryRateLimiter(Rate{RequestsPerSecond: 2, Burst: 2})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(ctx, "user:1")
+		require.NoError(t, err)
+		assert.True(t, allowed, "burst request %d", i)
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "user:1")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// Other callers have their own bucket
+	allowed, _, _ = limiter.Allow(ctx, "user:2")
+	assert.True(t, allowed)
+
+	// Tokens refill at the sustained rate
+	now = now.Add(500 * time.Millisecond)
+	allowed, _, _ = limiter.Allow(ctx, "user:1")
+	assert.True(t, allowed)
+}
+
+func TestMemoryRateLimiter_SweepsIdleBuckets(t *testing.T) {
+	limiter := NewMemoryRateLimiter(Rate{RequestsPerSecond: 10, Burst: 5})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	_, _, _ = limiter.Allow(context.Background(), "user:1")
+	now = now.Add(2 * time.Minute)
+	_, _, _ = limiter.Allow(context.Background(), "user:2")
+
+	assert.NotContains(t, limiter.buckets, "user:1")
+	assert.Contains(t, limiter.buckets, "user:2")
+}
+
+// errRateLimiter always fails, simulating an unavailable shared store
+type errRateLimiter struct{}
+
+func (errRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func newRateLimitRouter(cfg *RateLimitConfig, setup gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.Use(setup, RateLimit(cfg))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/servers", ok)
+	router.POST("/api/v1/gateway/:server_id", ok)
+	router.POST("/api/v1/gateway/:server_id/tools/call", ok)
+	return router
+}
+
+func serve(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	setUser := func(c *gin.Context) { c.Set(ContextKeyUserID, "user-1") }
+
+	t.Run("returns 429 with Retry-After when exhausted", func(t *testing.T) {
+		router := newRateLimitRouter(&RateLimitConfig{
+			Default: NewMemoryRateLimiter(Rate{RequestsPerSecond: 0.5, Burst: 1}),
+			Logger:  logger.NewNopLogger(),
+		}, setUser)
+
+		assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code)
+
+		w := serve(router, "GET", "/api/v1/servers")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("jitters Retry-After within the configured window", func(t *testing.T) {
+		router := newRateLimitRouter(&RateLimitConfig{
+			Default:          NewMemoryRateLimiter(Rate{RequestsPerSecond: 0.5, Burst: 1}),
+			RetryAfterJitter: 5 * time.Second,
+			Logger:           logger.NewNopLogger(),
+		}, setUser)
+
+		require.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code)
+
+		seen := make(map[int]bool)
+		for i := 0; i < 50; i++ {
+			w := serve(router, "GET", "/api/v1/servers")
+			require.Equal(t, http.StatusTooManyRequests, w.Code)
+			seconds, err := strconv.Atoi(w.Header().Get("Retry-After"))
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, seconds, 2, "never earlier than the bucket refills")
+			assert.LessOrEqual(t, seconds, 7, "never later than the wait plus the jitter")
+			seen[seconds] = true
+		}
+		assert.Greater(t, len(seen), 1, "Retry-After varies across responses")
+	})
+
+	t.Run("tool calls use their own limit", func(t *testing.T) {
+		router := newRateLimitRouter(&RateLimitConfig{
+			Default:   NewMemoryRateLimiter(Rate{RequestsPerSecond: 1, Burst: 5}),
+			ToolCalls: NewMemoryRateLimiter(Rate{RequestsPerSecond: 1, Burst: 1}),
+			Logger:    logger.NewNopLogger(),
+		}, setUser)
+
+		assert.Equal(t, http.StatusOK, serve(router, "POST", "/api/v1/gateway/s1/tools/call").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, "POST", "/api/v1/gateway/s1/tools/call").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, "POST", "/api/v1/gateway/s1").Code, "MCP proxy shares the tool call limit")
+		assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code, "list routes are unaffected")
+	})
+
+	t.Run("keys by API key when no user is set", func(t *testing.T) {
+		key := "key-1"
+		router := newRateLimitRouter(&RateLimitConfig{
+			Default: NewMemoryRateLimiter(Rate{RequestsPerSecond: 1, Burst: 1}),
+			Logger:  logger.NewNopLogger(),
+		}, func(c *gin.Context) { SetAPIKeyInContext(c, &domain.APIKey{ID: key}) })
+
+		assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, "GET", "/api/v1/servers").Code)
+
+		key = "key-2"
+		assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code)
+	})
+
+	t.Run("fails open when the limiter errors", func(t *testing.T) {
+		router := newRateLimitRouter(&RateLimitConfig{
+			Default: errRateLimiter{},
+			Logger:  logger.NewNopLogger(),
+		}, setUser)
+
+		assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/v1/servers").Code)
+	})
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, retryAfterSeconds(0, 0), "rounds up to at least one second")
+	assert.Equal(t, 2, retryAfterSeconds(1500*time.Millisecond, 0))
+
+	for i := 0; i < 100; i++ {
+		seconds := retryAfterSeconds(1500*time.Millisecond, 3*time.Second)
+		assert.GreaterOrEqual(t, seconds, 2)
+		assert.LessOrEqual(t, seconds, 5)
+	}
+}
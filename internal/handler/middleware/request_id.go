@@ -3,27 +3,34 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"github.com/waffles/waffles/internal/service/gateway"
 )
 
 const (
 	// RequestIDHeader is the header name for request ID
-	RequestIDHeader = "X-Request-ID"
+	RequestIDHeader = gateway.HeaderRequestID
 	// RequestIDKey is the context key for request ID
 	RequestIDKey = "request_id"
+	// maxRequestIDLength is the longest inbound request ID kept; longer ones are replaced
+	maxRequestIDLength = 128
 )
 
-// RequestID returns a middleware that adds a unique request ID
+// RequestID returns a middleware that adds a unique request ID. An inbound X-Request-ID is
+// kept when it is short printable ASCII. The ID is echoed on the response, logged, and
+// forwarded on the upstream requests made for the request.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check if request ID already exists in header
 		requestID := c.GetHeader(RequestIDHeader)
-		if requestID == "" {
+		if !validRequestID(requestID) {
 			// Generate new UUID if not provided
 			requestID = uuid.New().String()
 		}
 
-		// Set request ID in context
+		// Set request ID in context, and in the request's context for upstream calls
 		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(gateway.WithRequestID(c.Request.Context(), requestID))
 
 		// Set request ID in response header
 		c.Header(RequestIDHeader, requestID)
@@ -31,3 +38,16 @@ func RequestID() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// validRequestID reports whether an inbound request ID is safe to log and forward
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
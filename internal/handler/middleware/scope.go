@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -10,9 +11,15 @@ import (
 	"github.com/waffles/waffles/pkg/logger"
 )
 
+// ServerNamespaceLookup returns the IDs of the namespaces a server belongs to
+type ServerNamespaceLookup interface {
+	GetServerNamespaces(ctx context.Context, serverID string) ([]string, error)
+}
+
 // ScopeMiddleware provides scope-based access control for API keys
 type ScopeMiddleware struct {
-	logger logger.Logger
+	logger     logger.Logger
+	namespaces ServerNamespaceLookup // Resolves namespace-scoped keys (nil = server IDs only)
 }
 
 // NewScopeMiddleware creates a new scope middleware
@@ -29,6 +36,15 @@ func NewScopeMiddlewareWithLogger(log logger.Logger) *ScopeMiddleware {
 	}
 }
 
+// NewScopeMiddlewareWithNamespaces creates a new scope middleware that also lets keys
+// scoped to namespaces reach the servers in those namespaces
+func NewScopeMiddlewareWithNamespaces(log logger.Logger, namespaces ServerNamespaceLookup) *ScopeMiddleware {
+	return &ScopeMiddleware{
+		logger:     log,
+		namespaces: namespaces,
+	}
+}
+
 // RequireScope returns middleware that requires a specific scope
 func (m *ScopeMiddleware) RequireScope(scope string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -48,6 +64,7 @@ func (m *ScopeMiddleware) RequireScope(scope string) gin.HandlerFunc {
 				Str("path", c.Request.URL.Path).
 				Str("method", c.Request.Method).
 				Msg("API key scope validation failed")
+			RecordAccessDenied(c, domain.DenyReasonScope, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":          "Insufficient scope",
 				"required_scope": scope,
@@ -68,6 +85,7 @@ func (m *ScopeMiddleware) RequireScope(scope string) gin.HandlerFunc {
 				logEvent.Bool("parse_error", true)
 			}
 			logEvent.Msg("API key IP whitelist validation failed")
+			RecordAccessDenied(c, domain.DenyReasonIPNotAllowed, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "IP address not allowed for this API key",
 			})
@@ -81,6 +99,7 @@ func (m *ScopeMiddleware) RequireScope(scope string) gin.HandlerFunc {
 				Str("method", c.Request.Method).
 				Str("path", c.Request.URL.Path).
 				Msg("API key read-only restriction violated")
+			RecordAccessDenied(c, domain.DenyReasonReadOnly, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "API key is read-only",
 			})
@@ -108,6 +127,7 @@ func (m *ScopeMiddleware) RequireAnyScope(scopes ...string) gin.HandlerFunc {
 				Str("path", c.Request.URL.Path).
 				Str("method", c.Request.Method).
 				Msg("API key scope validation failed - none of required scopes present")
+			RecordAccessDenied(c, domain.DenyReasonScope, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":           "Insufficient scope",
 				"required_scopes": scopes,
@@ -127,6 +147,7 @@ func (m *ScopeMiddleware) RequireAnyScope(scopes ...string) gin.HandlerFunc {
 				logEvent.Bool("parse_error", true)
 			}
 			logEvent.Msg("API key IP whitelist validation failed")
+			RecordAccessDenied(c, domain.DenyReasonIPNotAllowed, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "IP address not allowed for this API key",
 			})
@@ -139,6 +160,7 @@ func (m *ScopeMiddleware) RequireAnyScope(scopes ...string) gin.HandlerFunc {
 				Str("method", c.Request.Method).
 				Str("path", c.Request.URL.Path).
 				Msg("API key read-only restriction violated")
+			RecordAccessDenied(c, domain.DenyReasonReadOnly, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "API key is read-only",
 			})
@@ -163,13 +185,32 @@ func (m *ScopeMiddleware) RequireServerAccess() gin.HandlerFunc {
 			serverID = c.Param("server_id")
 		}
 
-		if serverID != "" && !apiKey.IsServerAllowed(serverID) {
+		if serverID == "" {
+			c.Next()
+			return
+		}
+
+		allowed, err := m.serverAllowed(c.Request.Context(), apiKey, serverID)
+		if err != nil {
+			m.logger.Error().
+				Err(err).
+				Str("api_key_id", apiKey.ID).
+				Str("server_id", serverID).
+				Msg("Failed to resolve server namespaces for API key")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check API key server access",
+			})
+			return
+		}
+		if !allowed {
 			m.logger.Warn().
 				Str("api_key_id", apiKey.ID).
 				Str("server_id", serverID).
 				Any("allowed_servers", apiKey.AllowedServers).
+				Any("allowed_namespaces", apiKey.Namespaces).
 				Str("path", c.Request.URL.Path).
 				Msg("API key server access denied")
+			RecordAccessDenied(c, domain.DenyReasonServerNotInScope, "server:"+serverID)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":     "API key not authorized for this server",
 				"server_id": serverID,
@@ -181,6 +222,23 @@ func (m *ScopeMiddleware) RequireServerAccess() gin.HandlerFunc {
 	}
 }
 
+// serverAllowed checks a server against the key's server and namespace scopes. Without a
+// namespace lookup, a namespace-scoped key only reaches the servers it lists by ID.
+func (m *ScopeMiddleware) serverAllowed(ctx context.Context, apiKey *domain.APIKey, serverID string) (bool, error) {
+	if apiKey.AllowsServer(serverID, nil) {
+		return true, nil
+	}
+	if len(apiKey.Namespaces) == 0 || m.namespaces == nil {
+		return false, nil
+	}
+
+	serverNamespaces, err := m.namespaces.GetServerNamespaces(ctx, serverID)
+	if err != nil {
+		return false, err
+	}
+	return apiKey.AllowsServer(serverID, serverNamespaces), nil
+}
+
 // RequireNamespaceAccess returns middleware that checks if API key can access a specific namespace
 func (m *ScopeMiddleware) RequireNamespaceAccess() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -204,6 +262,7 @@ func (m *ScopeMiddleware) RequireNamespaceAccess() gin.HandlerFunc {
 					Any("allowed_namespaces", apiKey.Namespaces).
 					Str("path", c.Request.URL.Path).
 					Msg("API key namespace access denied")
+				RecordAccessDenied(c, domain.DenyReasonNamespaceNotInScope, "namespace:"+namespaceID)
 				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 					"error":        "API key not authorized for this namespace",
 					"namespace_id": namespaceID,
@@ -226,6 +285,7 @@ func (m *ScopeMiddleware) CheckReadOnly() gin.HandlerFunc {
 		}
 
 		if apiKey.ReadOnly && !isReadOnlyMethod(c.Request.Method) {
+			RecordAccessDenied(c, domain.DenyReasonReadOnly, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": "API key is read-only, write operations not allowed",
 			})
@@ -246,6 +306,7 @@ func (m *ScopeMiddleware) CheckIPWhitelist() gin.HandlerFunc {
 		}
 
 		if !apiKey.IsIPAllowed(c.ClientIP()) {
+			RecordAccessDenied(c, domain.DenyReasonIPNotAllowed, c.Request.URL.Path)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":     "IP address not allowed for this API key",
 				"client_ip": c.ClientIP(),
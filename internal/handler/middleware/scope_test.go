@@ -1,6 +1,8 @@
 package middleware_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/internal/handler/middleware"
+	"github.com/waffles/waffles/pkg/logger"
 )
 
 func init() {
@@ -206,6 +209,98 @@ func TestScopeMiddleware_RequireServerAccess(t *testing.T) {
 	}
 }
 
+// staticNamespaceLookup maps server IDs to the namespaces they belong to
+type staticNamespaceLookup map[string][]string
+
+func (l staticNamespaceLookup) GetServerNamespaces(ctx context.Context, serverID string) ([]string, error) {
+	if serverID == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	return l[serverID], nil
+}
+
+func TestScopeMiddleware_RequireServerAccess_Namespaces(t *testing.T) {
+	lookup := staticNamespaceLookup{
+		"server-1": {"ns-prod"},
+		"server-2": {"ns-dev"},
+		"server-3": {"ns-dev", "ns-prod"},
+	}
+	namespaceKey := &domain.APIKey{ID: "key-1", Namespaces: []string{"ns-prod"}}
+
+	tests := []struct {
+		name           string
+		middleware     *middleware.ScopeMiddleware
+		serverID       string
+		apiKey         *domain.APIKey
+		expectedStatus int
+	}{
+		{
+			name:           "namespace-scoped key reaches a server in its namespace",
+			middleware:     middleware.NewScopeMiddlewareWithNamespaces(logger.NewNop(), lookup),
+			serverID:       "server-1",
+			apiKey:         namespaceKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "namespace-scoped key reaches a server in several namespaces",
+			middleware:     middleware.NewScopeMiddlewareWithNamespaces(logger.NewNop(), lookup),
+			serverID:       "server-3",
+			apiKey:         namespaceKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "namespace-scoped key is denied a server outside its namespaces",
+			middleware:     middleware.NewScopeMiddlewareWithNamespaces(logger.NewNop(), lookup),
+			serverID:       "server-2",
+			apiKey:         namespaceKey,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:       "key scoped to servers and namespaces reaches both",
+			middleware: middleware.NewScopeMiddlewareWithNamespaces(logger.NewNop(), lookup),
+			serverID:   "server-2",
+			apiKey: &domain.APIKey{
+				ID:             "key-1",
+				AllowedServers: []string{"server-2"},
+				Namespaces:     []string{"ns-prod"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "namespace-scoped key is denied without a namespace lookup",
+			middleware:     middleware.NewScopeMiddleware(),
+			serverID:       "server-1",
+			apiKey:         namespaceKey,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "lookup failure is an error",
+			middleware:     middleware.NewScopeMiddlewareWithNamespaces(logger.NewNop(), lookup),
+			serverID:       "broken",
+			apiKey:         namespaceKey,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/gateway/"+tt.serverID+"/tools/call", nil)
+			c.Params = gin.Params{{Key: "server_id", Value: tt.serverID}}
+			middleware.SetAPIKeyInContext(c, tt.apiKey)
+
+			tt.middleware.RequireServerAccess()(c)
+
+			if tt.expectedStatus == http.StatusOK {
+				assert.False(t, c.IsAborted())
+			} else {
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
 func TestScopeMiddleware_CheckReadOnly(t *testing.T) {
 	scopeMiddleware := middleware.NewScopeMiddleware()
 
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// ContextKeySessionID is the cookie session value holding the server-side session ID
+const ContextKeySessionID = "session_id"
+
+// SessionStore tracks browser sessions server-side. With a store configured, a session
+// cookie is only honored while its session is in the store, so deleting a session
+// revokes it immediately. Implementations must be safe for concurrent use; a shared
+// backend (Postgres, Redis) is needed for revocation to reach every gateway replica.
+type SessionStore interface {
+	// Create starts a session for userID
+	Create(ctx context.Context, userID, ipAddress, userAgent string) (*domain.UserSession, error)
+	// Get returns an unexpired session, or domain.ErrSessionNotFound
+	Get(ctx context.Context, id string) (*domain.UserSession, error)
+	// ListByUser returns the user's unexpired sessions, oldest first
+	ListByUser(ctx context.Context, userID string) ([]*domain.UserSession, error)
+	// Revoke deletes a session, returning domain.ErrSessionNotFound if it doesn't exist
+	Revoke(ctx context.Context, id string) error
+	// RevokeUser deletes all of a user's sessions and returns how many there were
+	RevokeUser(ctx context.Context, userID string) (int, error)
+}
+
+// MemorySessionStore is a SessionStore for a single gateway instance. Sessions are lost
+// on restart, which logs every browser user out.
+type MemorySessionStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*domain.UserSession
+}
+
+// NewMemorySessionStore creates an in-memory store whose sessions expire after ttl
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[string]*domain.UserSession),
+	}
+}
+
+// Create starts a session for userID
+func (s *MemorySessionStore) Create(ctx context.Context, userID, ipAddress, userAgent string) (*domain.UserSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.pruneLocked(now)
+	session := &domain.UserSession{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	s.sessions[id] = session
+	copied := *session
+	return &copied, nil
+}
+
+// Get returns an unexpired session
+func (s *MemorySessionStore) Get(ctx context.Context, id string) (*domain.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || !s.now().Before(session.ExpiresAt) {
+		return nil, domain.ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// ListByUser returns the user's unexpired sessions, oldest first
+func (s *MemorySessionStore) ListByUser(ctx context.Context, userID string) ([]*domain.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(s.now())
+	sessions := []*domain.UserSession{}
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// Revoke deletes a session
+func (s *MemorySessionStore) Revoke(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; !ok {
+		return domain.ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+// RevokeUser deletes all of a user's sessions
+func (s *MemorySessionStore) RevokeUser(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// pruneLocked drops expired sessions. The caller must hold s.mu.
+func (s *MemorySessionStore) pruneLocked(now time.Time) {
+	for id, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// newSessionID returns a random session identifier
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartSession registers a login for userID with store and records the session ID in
+// the cookie session; the caller still saves the cookie session. It does nothing when
+// store is nil.
+func StartSession(c *gin.Context, store SessionStore, userID string) error {
+	if store == nil {
+		return nil
+	}
+	// A new login replaces any session the cookie already carried
+	if err := EndSession(c, store); err != nil {
+		return err
+	}
+	record, err := store.Create(c.Request.Context(), userID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		return err
+	}
+	sessions.Default(c).Set(ContextKeySessionID, record.ID)
+	return nil
+}
+
+// EndSession revokes the cookie session's server-side session, if any
+func EndSession(c *gin.Context, store SessionStore) error {
+	if store == nil {
+		return nil
+	}
+	id, _ := sessions.Default(c).Get(ContextKeySessionID).(string)
+	if id == "" {
+		return nil
+	}
+	if err := store.Revoke(c.Request.Context(), id); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// CurrentSessionID returns the server-side session ID of the request's cookie session,
+// or "" when there is none
+func CurrentSessionID(c *gin.Context) string {
+	if _, ok := c.Get(sessions.DefaultKey); !ok {
+		return ""
+	}
+	id, _ := sessions.Default(c).Get(ContextKeySessionID).(string)
+	return id
+}
+
+// sessionActive reports whether the cookie session for userID is still honored. Without
+// a store every cookie session is; with one, its session must exist and belong to userID.
+func sessionActive(c *gin.Context, cfg *AuthConfig, session sessions.Session, userID interface{}) bool {
+	if cfg.SessionStore == nil {
+		return true
+	}
+	id, _ := session.Get(ContextKeySessionID).(string)
+	if id == "" {
+		return false
+	}
+	record, err := cfg.SessionStore.Get(c.Request.Context(), id)
+	if err != nil {
+		if !errors.Is(err, domain.ErrSessionNotFound) {
+			cfg.Logger.Error().Err(err).Msg("Failed to look up session")
+		}
+		return false
+	}
+	return record.UserID == userID
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sessions expire after the TTL", func(t *testing.T) {
+		store := NewMemorySessionStore(time.Hour)
+		now := time.Now()
+		store.now = func() time.Time { return now }
+
+		session, err := store.Create(ctx, "user-1", "10.0.0.1", "browser")
+		require.NoError(t, err)
+		assert.Len(t, session.ID, 64)
+		assert.Equal(t, now.Add(time.Hour), session.ExpiresAt)
+
+		got, err := store.Get(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", got.UserID)
+
+		now = now.Add(time.Hour)
+		_, err = store.Get(ctx, session.ID)
+		assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	})
+
+	t.Run("revoking a session removes only that session", func(t *testing.T) {
+		store := NewMemorySessionStore(time.Hour)
+		first, err := store.Create(ctx, "user-1", "", "")
+		require.NoError(t, err)
+		second, err := store.Create(ctx, "user-1", "", "")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Revoke(ctx, first.ID))
+		assert.ErrorIs(t, store.Revoke(ctx, first.ID), domain.ErrSessionNotFound)
+
+		sessions, err := store.ListByUser(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, second.ID, sessions[0].ID)
+	})
+
+	t.Run("revoking a user removes all of their sessions", func(t *testing.T) {
+		store := NewMemorySessionStore(time.Hour)
+		for _, userID := range []string{"user-1", "user-1", "user-2"} {
+			_, err := store.Create(ctx, userID, "", "")
+			require.NoError(t, err)
+		}
+
+		revoked, err := store.RevokeUser(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, revoked)
+
+		sessions, err := store.ListByUser(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+		sessions, err = store.ListByUser(ctx, "user-2")
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+}
+
+func TestSessionAuth_SessionStore(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour)
+	cfg := &AuthConfig{Logger: logger.NewNopLogger(), SessionStore: store}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("test_session", cookie.NewStore([]byte("test-secret-key-32-bytes-long!!!"))))
+	router.GET("/login", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set(ContextKeyUserID, "user-123")
+		require.NoError(t, StartSession(c, store, "user-123"))
+		require.NoError(t, session.Save())
+		c.Status(http.StatusOK)
+	})
+	router.GET("/cookie-only", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set(ContextKeyUserID, "user-123")
+		require.NoError(t, session.Save())
+		c.Status(http.StatusOK)
+	})
+	router.GET("/protected", SessionAuth(cfg), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/mcp", CombinedAuth(&AuthConfig{Logger: logger.NewNopLogger(), SessionStore: store, MCPAuth: MCPAuthConfig{SessionEnabled: true}}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("a revoked session is rejected", func(t *testing.T) {
+		cookies := request("/login", nil).Result().Cookies()
+		assert.Equal(t, http.StatusOK, request("/protected", cookies).Code)
+		assert.Equal(t, http.StatusOK, request("/mcp", cookies).Code)
+
+		revoked, err := store.RevokeUser(context.Background(), "user-123")
+		require.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		assert.Equal(t, http.StatusUnauthorized, request("/protected", cookies).Code)
+		assert.Equal(t, http.StatusUnauthorized, request("/mcp", cookies).Code)
+	})
+
+	t.Run("a cookie session that was never registered is rejected", func(t *testing.T) {
+		cookies := request("/cookie-only", nil).Result().Cookies()
+		assert.Equal(t, http.StatusUnauthorized, request("/protected", cookies).Code)
+	})
+}
@@ -3,17 +3,23 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/handler/middleware"
 	"github.com/waffles/waffles/internal/repository"
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/internal/service/serveraccess"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
 // NamespaceHandler handles namespace API requests
 type NamespaceHandler struct {
 	namespaceRepo NamespaceRepoInterface
+	tools         NamespaceToolsInterface      // Aggregates tools across member servers (nil = unavailable)
+	accessService ServerAccessServiceInterface // Limits aggregated tools to servers the caller can view (nil = all)
 	logger        logger.Logger
 }
 
@@ -30,6 +36,18 @@ func NewNamespaceHandler(namespaceRepo *repository.NamespaceRepository, log logg
 	}
 }
 
+// NewNamespaceHandlerWithTools creates a namespace handler that can aggregate tools across member servers
+func NewNamespaceHandlerWithTools(namespaceRepo *repository.NamespaceRepository, gatewayService *gateway.Service, accessService *serveraccess.Service, log logger.Logger) *NamespaceHandler {
+	h := NewNamespaceHandler(namespaceRepo, log)
+	if gatewayService != nil {
+		h.tools = gatewayService
+	}
+	if accessService != nil {
+		h.accessService = accessService
+	}
+	return h
+}
+
 // NewNamespaceHandlerWithInterface creates a new namespace handler with interface (for testing).
 func NewNamespaceHandlerWithInterface(namespaceRepo NamespaceRepoInterface, log logger.Logger) *NamespaceHandler {
 	return &NamespaceHandler{
@@ -38,6 +56,18 @@ func NewNamespaceHandlerWithInterface(namespaceRepo NamespaceRepoInterface, log
 	}
 }
 
+// accessCacheInvalidator is implemented by access services that cache decisions
+type accessCacheInvalidator interface {
+	InvalidateCache()
+}
+
+// invalidateAccess drops cached access decisions after namespace access changes
+func (h *NamespaceHandler) invalidateAccess() {
+	if cache, ok := h.accessService.(accessCacheInvalidator); ok {
+		cache.InvalidateCache()
+	}
+}
+
 // ListNamespaces returns all namespaces
 // GET /api/v1/namespaces
 func (h *NamespaceHandler) ListNamespaces(c *gin.Context) {
@@ -132,6 +162,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 		return
 	}
 
+	h.invalidateAccess()
 	c.JSON(http.StatusOK, gin.H{"message": "Namespace deleted"})
 }
 
@@ -167,6 +198,7 @@ func (h *NamespaceHandler) AddServer(c *gin.Context) {
 		return
 	}
 
+	h.invalidateAccess()
 	c.JSON(http.StatusOK, gin.H{"message": "Server added to namespace"})
 }
 
@@ -189,6 +221,7 @@ func (h *NamespaceHandler) RemoveServer(c *gin.Context) {
 		return
 	}
 
+	h.invalidateAccess()
 	c.JSON(http.StatusOK, gin.H{"message": "Server removed from namespace"})
 }
 
@@ -210,6 +243,89 @@ func (h *NamespaceHandler) ListServers(c *gin.Context) {
 	})
 }
 
+// ListTools returns the tools of every server in a namespace the caller can view merged into
+// one list. The servers section reports each server's outcome and warnings lists the failures;
+// the optional mode query parameter (best_effort or fail_fast) overrides the configured
+// aggregation mode. When servers have more tools, next_cursor is set; pass it back as the
+// cursor query parameter for the next page.
+// GET /api/v1/namespaces/:id/tools
+func (h *NamespaceHandler) ListTools(c *gin.Context) {
+	namespaceID := c.Param("id")
+
+	if h.tools == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Tools aggregation is not available"})
+		return
+	}
+
+	mode := gateway.AggregationMode(c.Query("mode"))
+	if mode != "" && !gateway.ValidAggregationMode(mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be best_effort or fail_fast"})
+		return
+	}
+
+	members, err := h.namespaceRepo.GetNamespaceServers(c.Request.Context(), namespaceID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("namespace_id", namespaceID).Msg("Failed to list namespace servers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list namespace servers"})
+		return
+	}
+
+	// nil = admin, all servers; otherwise only the listed servers
+	var accessibleServerIDs []string
+	if h.accessService != nil {
+		roles := middleware.GetUserRoles(c)
+		accessibleServerIDs, err = h.accessService.GetAccessibleServerIDs(c.Request.Context(), roles, domain.AccessLevelView)
+		if err != nil {
+			h.logger.Error().Err(err).Any("roles", roles).Msg("Failed to get accessible servers")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check server access"})
+			return
+		}
+	}
+
+	// An API key limited to servers by ID only reaches the members it lists; one limited to
+	// namespaces already passed RequireNamespaceAccess for this one
+	apiKey := middleware.GetAPIKeyFromContext(c)
+
+	serverIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		if accessibleServerIDs != nil && !slices.Contains(accessibleServerIDs, member.ServerID) {
+			continue
+		}
+		if apiKey != nil && !apiKey.AllowsServer(member.ServerID, []string{namespaceID}) {
+			continue
+		}
+		serverIDs = append(serverIDs, member.ServerID)
+	}
+
+	result, err := h.tools.AggregateToolsPage(c.Request.Context(), serverIDs, mode, c.Query("cursor"))
+	if errors.Is(err, gateway.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	if err != nil {
+		h.logger.Warn().Err(err).Str("namespace_id", namespaceID).Msg("Failed to aggregate namespace tools")
+		response := gin.H{"error": err.Error()}
+		if result != nil {
+			response["servers"] = result.Servers
+		}
+		c.JSON(http.StatusBadGateway, response)
+		return
+	}
+
+	response := gin.H{
+		"tools":   result.Tools,
+		"servers": result.Servers,
+		"count":   len(result.Tools),
+	}
+	if len(result.Warnings) > 0 {
+		response["warnings"] = result.Warnings
+	}
+	if result.NextCursor != "" {
+		response["next_cursor"] = result.NextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // SetRoleAccess sets a role's access level to a namespace
 // POST /api/v1/namespaces/:id/access
 func (h *NamespaceHandler) SetRoleAccess(c *gin.Context) {
@@ -261,6 +377,7 @@ func (h *NamespaceHandler) SetRoleAccess(c *gin.Context) {
 		return
 	}
 
+	h.invalidateAccess()
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Role access set",
 		"role":         req.RoleName,
@@ -287,6 +404,7 @@ func (h *NamespaceHandler) RemoveRoleAccess(c *gin.Context) {
 		return
 	}
 
+	h.invalidateAccess()
 	c.JSON(http.StatusOK, gin.H{"message": "Role access removed"})
 }
 
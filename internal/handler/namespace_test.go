@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -707,6 +708,217 @@ func TestNamespaceHandler_RemoveServer(t *testing.T) {
 	})
 }
 
+// mockNamespaceTools implements NamespaceToolsInterface for testing
+type mockNamespaceTools struct {
+	result      *gateway.AggregatedToolsList
+	err         error
+	gotServers  []string
+	gotMode     gateway.AggregationMode
+	gotCursor   string
+	calledCount int
+}
+
+func (m *mockNamespaceTools) AggregateToolsPage(ctx context.Context, serverIDs []string, mode gateway.AggregationMode, cursor string) (*gateway.AggregatedToolsList, error) {
+	m.calledCount++
+	m.gotServers = serverIDs
+	m.gotMode = mode
+	m.gotCursor = cursor
+	return m.result, m.err
+}
+
+func TestNamespaceHandler_ListTools(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	newRequest := func(query string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/namespaces/ns-123/tools"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: "ns-123"}}
+		return w, c
+	}
+
+	t.Run("returns partial tools with per-server status", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1", "server-2"}
+		tools := &mockNamespaceTools{result: &gateway.AggregatedToolsList{
+			Tools: []map[string]json.RawMessage{
+				{"name": json.RawMessage(`"search"`), "server_id": json.RawMessage(`"server-1"`)},
+			},
+			Servers: []gateway.AggregatedServer{
+				{ServerID: "server-1", Status: gateway.AggregatedServerOK, ToolCount: 1},
+				{ServerID: "server-2", Status: gateway.AggregatedServerError, Error: "connection refused"},
+			},
+		}}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"server-1", "server-2"}, tools.gotServers)
+		assert.Equal(t, gateway.AggregationMode(""), tools.gotMode)
+
+		var response struct {
+			Tools   []map[string]string        `json:"tools"`
+			Servers []gateway.AggregatedServer `json:"servers"`
+			Count   int                        `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Count)
+		assert.Equal(t, "server-1", response.Tools[0]["server_id"])
+		require.Len(t, response.Servers, 2)
+		assert.Equal(t, "error", response.Servers[1].Status)
+		assert.Equal(t, "connection refused", response.Servers[1].Error)
+	})
+
+	t.Run("limits servers to those the caller can view and reports warnings", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1", "server-2", "server-3"}
+		tools := &mockNamespaceTools{result: &gateway.AggregatedToolsList{
+			Tools:    []map[string]json.RawMessage{},
+			Servers:  []gateway.AggregatedServer{{ServerID: "server-3", Status: gateway.AggregatedServerError, Error: "timeout"}},
+			Warnings: []string{"server server-3: timeout"},
+		}}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+		handler.accessService = &mockAccessService{accessibleServerIDs: []string{"server-3", "server-9"}}
+
+		w, c := newRequest("")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"server-3"}, tools.gotServers)
+		var response struct {
+			Warnings []string `json:"warnings"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []string{"server server-3: timeout"}, response.Warnings)
+	})
+
+	t.Run("limits servers to those the API key lists", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1", "server-2", "server-3"}
+		tools := &mockNamespaceTools{result: &gateway.AggregatedToolsList{Tools: []map[string]json.RawMessage{}}}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("")
+		c.Set("api_key", &domain.APIKey{ID: "key-1", AllowedServers: []string{"server-2"}})
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"server-2"}, tools.gotServers)
+	})
+
+	t.Run("API key scoped to the namespace reaches every member", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1", "server-2"}
+		tools := &mockNamespaceTools{result: &gateway.AggregatedToolsList{Tools: []map[string]json.RawMessage{}}}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("")
+		c.Set("api_key", &domain.APIKey{ID: "key-1", Namespaces: []string{"ns-123"}})
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"server-1", "server-2"}, tools.gotServers)
+	})
+
+	t.Run("access check failure", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1"}
+		tools := &mockNamespaceTools{}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+		handler.accessService = &mockAccessService{err: errors.New("database error")}
+
+		w, c := newRequest("")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, 0, tools.calledCount)
+	})
+
+	t.Run("fail fast returns bad gateway with server status", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1"}
+		tools := &mockNamespaceTools{
+			result: &gateway.AggregatedToolsList{Servers: []gateway.AggregatedServer{
+				{ServerID: "server-1", Status: gateway.AggregatedServerError, Error: "timeout"},
+			}},
+			err: gateway.ErrAggregationFailed,
+		}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("?mode=fail_fast")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Equal(t, gateway.AggregationFailFast, tools.gotMode)
+		assert.Contains(t, w.Body.String(), `"servers"`)
+	})
+
+	t.Run("forwards cursor and returns next cursor", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1"}
+		tools := &mockNamespaceTools{result: &gateway.AggregatedToolsList{
+			Tools:      []map[string]json.RawMessage{},
+			Servers:    []gateway.AggregatedServer{{ServerID: "server-1", Status: gateway.AggregatedServerOK}},
+			NextCursor: "page-3",
+		}}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("?cursor=page-2")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "page-2", tools.gotCursor)
+		var response struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "page-3", response.NextCursor)
+	})
+
+	t.Run("rejects invalid cursor", func(t *testing.T) {
+		mockRepo := newMockNamespaceRepo()
+		mockRepo.members["ns-123"] = []string{"server-1"}
+		tools := &mockNamespaceTools{err: gateway.ErrInvalidCursor}
+		handler := NewNamespaceHandlerWithInterface(mockRepo, log)
+		handler.tools = tools
+
+		w, c := newRequest("?cursor=bogus")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects unknown mode", func(t *testing.T) {
+		tools := &mockNamespaceTools{}
+		handler := NewNamespaceHandlerWithInterface(newMockNamespaceRepo(), log)
+		handler.tools = tools
+
+		w, c := newRequest("?mode=sometimes")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, 0, tools.calledCount)
+	})
+
+	t.Run("unavailable without aggregator", func(t *testing.T) {
+		handler := NewNamespaceHandlerWithInterface(newMockNamespaceRepo(), log)
+
+		w, c := newRequest("")
+		handler.ListTools(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
 func TestNamespaceHandler_ListServers(t *testing.T) {
 	log := logger.NewNopLogger()
 
@@ -22,6 +22,7 @@ const (
 type OAuthHandler struct {
 	oauthService OAuthServiceInterface
 	userRepo     OAuthUserRepoInterface
+	sessionStore middleware.SessionStore
 	logger       logger.Logger
 	frontendURL  string // URL to redirect to after successful login
 }
@@ -55,6 +56,11 @@ func NewOAuthHandlerWithInterface(oauthService OAuthServiceInterface, userRepo O
 	}
 }
 
+// SetSessionStore makes SSO logins tracked server-side so they can be revoked
+func (h *OAuthHandler) SetSessionStore(store middleware.SessionStore) {
+	h.sessionStore = store
+}
+
 // SSOStatusResponse represents the response for SSO status
 type SSOStatusResponse struct {
 	Enabled bool `json:"enabled"`
@@ -234,6 +240,12 @@ func (h *OAuthHandler) Callback(c *gin.Context) {
 	session.Set(middleware.ContextKeyUserEmail, user.Email)
 	session.Set(middleware.ContextKeyUserRoles, roles)
 
+	if err := middleware.StartSession(c, h.sessionStore, user.ID); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to start session")
+		h.redirectWithError(c, "Failed to create session")
+		return
+	}
+
 	if err := session.Save(); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to save session")
 		h.redirectWithError(c, "Failed to create session")
@@ -1,14 +1,24 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
 
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/internal/handler/middleware"
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/internal/service/registry"
 	"github.com/waffles/waffles/internal/service/serveraccess"
 	"github.com/waffles/waffles/pkg/logger"
@@ -20,6 +30,7 @@ import (
 type RegistryHandler struct {
 	service       RegistryServiceInterface
 	accessService ServerAccessServiceInterface
+	namespaces    NamespaceRepoInterface // Namespaces included in exports (nil = omitted)
 	logger        logger.Logger
 }
 
@@ -42,6 +53,11 @@ func NewRegistryHandler(service *registry.Service, accessService *serveraccess.S
 	}
 }
 
+// SetNamespaceRepository includes namespace membership and role access in exports
+func (h *RegistryHandler) SetNamespaceRepository(namespaces NamespaceRepoInterface) {
+	h.namespaces = namespaces
+}
+
 // NewRegistryHandlerWithInterfaces creates a new registry handler with interface dependencies (for testing).
 func NewRegistryHandlerWithInterfaces(service RegistryServiceInterface, accessService ServerAccessServiceInterface, log logger.Logger) *RegistryHandler {
 	return &RegistryHandler{
@@ -143,10 +159,35 @@ func (h *RegistryHandler) CreateServer(c *gin.Context) {
 		})
 		return
 	}
+	if err := validateServerCreate(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// Create server
 	server, err := h.service.CreateServer(c.Request.Context(), &req)
 	if err != nil {
+		if errors.Is(err, domain.ErrServerURLIsGateway) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points back at the gateway",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrServerURLBlocked) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points at an internal address",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrServerLimitReached) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Active server limit reached",
+			})
+			return
+		}
+
 		h.logger.Error().Err(err).Msg("Failed to create server")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create server",
@@ -157,6 +198,381 @@ func (h *RegistryHandler) CreateServer(c *gin.Context) {
 	c.JSON(http.StatusCreated, server)
 }
 
+// maxImportBytes caps the body of a server import
+const maxImportBytes = 1 << 20
+
+// ImportServers handles POST /api/v1/servers/import
+// Registers a JSON or YAML list of servers. The import is all-or-nothing unless
+// continue_on_error=true, which creates the valid servers and reports the rest.
+func (h *RegistryHandler) ImportServers(c *gin.Context) {
+	continueOnError := false
+	if raw := c.Query("continue_on_error"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "continue_on_error must be true or false",
+			})
+			return
+		}
+		continueOnError = parsed
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Import must be at most %d bytes", maxImportBytes),
+		})
+		return
+	}
+	reqs, err := parseServerImport(c.ContentType(), body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Import contains no servers",
+		})
+		return
+	}
+
+	summary := h.service.ImportServers(c.Request.Context(), reqs, validateImportedServer, continueOnError)
+
+	h.logger.Info().
+		Int("created", summary.Created).
+		Int("failed", summary.Failed).
+		Bool("continue_on_error", continueOnError).
+		Msg("Servers imported")
+
+	status := http.StatusCreated
+	switch {
+	case summary.Failed > 0 && summary.Created == 0:
+		status = http.StatusUnprocessableEntity
+	case summary.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, summary)
+}
+
+// parseServerImport decodes a list of servers, or the servers of an export document.
+// YAML uses the same field names as JSON.
+func parseServerImport(contentType string, body []byte) ([]*domain.ServerCreate, error) {
+	switch contentType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		var decoded interface{}
+		if err := yaml.Unmarshal(body, &decoded); err != nil {
+			return nil, err
+		}
+		// Round-trip through JSON so the json tags and json.RawMessage fields apply
+		converted, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, err
+		}
+		body = converted
+	}
+
+	var reqs []*domain.ServerCreate
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		// A document from the server export
+		var export struct {
+			Servers []*domain.ServerCreate `json:"servers"`
+		}
+		if err := json.Unmarshal(trimmed, &export); err != nil {
+			return nil, err
+		}
+		reqs = export.Servers
+	} else if err := json.Unmarshal(body, &reqs); err != nil {
+		return nil, err
+	}
+	for i, req := range reqs {
+		if req == nil {
+			return nil, fmt.Errorf("server %d is null", i)
+		}
+	}
+	return reqs, nil
+}
+
+// serverExportVersion is the format version of export documents
+const serverExportVersion = 1
+
+// ExportServers handles GET /api/v1/servers/export
+// Returns the servers the caller can view, with their namespaces and role access, as a
+// document the import endpoint accepts. secrets=omit (default) drops credentials,
+// secrets=placeholder replaces them, and secrets=include, for admins only, keeps them.
+func (h *RegistryHandler) ExportServers(c *gin.Context) {
+	secrets := domain.ServerExportSecrets(c.DefaultQuery("secrets", string(domain.ServerExportSecretsOmit)))
+	switch secrets {
+	case domain.ServerExportSecretsOmit, domain.ServerExportSecretsPlaceholder:
+	case domain.ServerExportSecretsInclude:
+		if !slices.Contains(middleware.GetUserRoles(c), "admin") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Only admins can export secrets",
+			})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "secrets must be omit, placeholder or include",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var accessibleServerIDs []string
+	if h.accessService != nil {
+		var err error
+		accessibleServerIDs, err = h.accessService.GetAccessibleServerIDs(ctx, middleware.GetUserRoles(c), domain.AccessLevelView)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("Failed to get accessible servers")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check server access",
+			})
+			return
+		}
+	}
+
+	servers, err := h.service.ListServersForUser(ctx, &domain.ServerFilter{}, accessibleServerIDs)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list servers")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list servers",
+		})
+		return
+	}
+
+	export := &domain.ServerExport{
+		Version:    serverExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Secrets:    secrets,
+		Servers:    make([]domain.ExportedServer, 0, len(servers)),
+		Namespaces: []domain.ExportedNamespace{},
+	}
+	byID := make(map[string]int, len(servers))
+	for _, server := range servers {
+		byID[server.ID] = len(export.Servers)
+		export.Servers = append(export.Servers, domain.ExportedServer{ServerCreate: exportServer(server, secrets)})
+	}
+
+	if h.namespaces != nil {
+		if err := h.exportNamespaces(ctx, export, byID, accessibleServerIDs == nil); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to export namespaces")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to export namespaces",
+			})
+			return
+		}
+	}
+
+	h.logger.Info().
+		Int("servers", len(export.Servers)).
+		Int("namespaces", len(export.Namespaces)).
+		Str("secrets", string(secrets)).
+		Msg("Servers exported")
+	c.JSON(http.StatusOK, export)
+}
+
+// exportNamespaces adds the namespaces holding exported servers, keeping only exported
+// members. Callers who can see every server get empty namespaces too.
+func (h *RegistryHandler) exportNamespaces(ctx context.Context, export *domain.ServerExport, byID map[string]int, all bool) error {
+	namespaces, err := h.namespaces.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		members, err := h.namespaces.GetNamespaceServers(ctx, ns.ID)
+		if err != nil {
+			return err
+		}
+		exported := domain.ExportedNamespace{Name: ns.Name, Description: ns.Description, AllowedTools: ns.AllowedTools, Servers: []string{}}
+		for _, member := range members {
+			i, ok := byID[member.ServerID]
+			if !ok {
+				continue
+			}
+			server := &export.Servers[i]
+			exported.Servers = append(exported.Servers, server.Name)
+			server.Namespaces = append(server.Namespaces, ns.Name)
+		}
+		if len(exported.Servers) == 0 && !all {
+			continue
+		}
+
+		access, err := h.namespaces.GetNamespaceRoleAccess(ctx, ns.ID)
+		if err != nil {
+			return err
+		}
+		for _, a := range access {
+			exported.RoleAccess = append(exported.RoleAccess, domain.ExportedNamespaceRole{Role: a.RoleName, AccessLevel: a.AccessLevel})
+		}
+		export.Namespaces = append(export.Namespaces, exported)
+	}
+	return nil
+}
+
+// exportServer returns the request that would recreate server, with its credentials
+// handled as secrets says
+func exportServer(server *domain.MCPServer, secrets domain.ServerExportSecrets) domain.ServerCreate {
+	return domain.ServerCreate{
+		Name:                     server.Name,
+		Description:              server.Description,
+		URL:                      server.URL,
+		ProtocolVersion:          server.ProtocolVersion,
+		Transport:                server.Transport,
+		AuthType:                 server.AuthType,
+		AuthConfig:               exportAuthConfig(server, secrets),
+		HealthCheckURL:           server.HealthCheckURL,
+		HealthCheckInterval:      server.HealthCheckInterval,
+		TimeoutSeconds:           server.TimeoutSeconds,
+		MaxConnections:           server.MaxConnections,
+		Tags:                     server.Tags,
+		AllowedTools:             server.AllowedTools,
+		Metadata:                 server.Metadata,
+		MaxRequestsPerMinute:     server.MaxRequestsPerMinute,
+		MaxToolRequestsPerMinute: server.MaxToolRequestsPerMinute,
+		ToolPrefix:               server.ToolPrefix,
+		TLSPins:                  server.TLSPins,
+		MaxResponseBytes:         server.MaxResponseBytes,
+		ReplicaGroup:             server.ReplicaGroup,
+		ForwardHeaders:           server.ForwardHeaders,
+		JSONRPCIDType:            server.JSONRPCIDType,
+		ElicitationPolicy:        server.ElicitationPolicy,
+		LatencyBudgetMs:          server.LatencyBudgetMs,
+		MaxBatchSize:             server.MaxBatchSize,
+		AcceptHeader:             server.AcceptHeader,
+		RequireInitialize:        server.RequireInitialize,
+		Quirks:                   server.Quirks,
+	}
+}
+
+// exportAuthConfig returns the server's auth_config for an export. Placeholders name
+// the server and key, e.g. ${WAFFLES_GITHUB_TOKEN}, for the operator to fill in.
+func exportAuthConfig(server *domain.MCPServer, secrets domain.ServerExportSecrets) json.RawMessage {
+	if len(server.AuthConfig) == 0 {
+		return nil
+	}
+	switch secrets {
+	case domain.ServerExportSecretsInclude:
+		return server.AuthConfig
+	case domain.ServerExportSecretsPlaceholder:
+		var config map[string]interface{}
+		if err := json.Unmarshal(server.AuthConfig, &config); err != nil {
+			return nil
+		}
+		for key := range config {
+			if !domain.AuthConfigPublicKeys[key] {
+				config[key] = "${" + placeholderName("WAFFLES_"+server.Name+"_"+key) + "}"
+			}
+		}
+		placeholders, err := json.Marshal(config)
+		if err != nil {
+			return nil
+		}
+		return placeholders
+	}
+	return nil
+}
+
+// placeholderName upper-cases name and replaces anything but letters and digits with _
+func placeholderName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// validateImportedServer checks an imported server like CreateServer does, plus the
+// fields the database would otherwise reject
+func validateImportedServer(req *domain.ServerCreate) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.URL == "" {
+		return errors.New("url is required")
+	}
+	return validateServerCreate(req)
+}
+
+// validateServerCreate checks the settings of a new server
+func validateServerCreate(req *domain.ServerCreate) error {
+	if err := validateTLSPins(req.TLSPins); err != nil {
+		return err
+	}
+	if err := validateForwardHeaders(req.ForwardHeaders); err != nil {
+		return err
+	}
+	if err := validateJSONRPCIDType(req.JSONRPCIDType); err != nil {
+		return err
+	}
+	if err := validateElicitationPolicy(req.ElicitationPolicy); err != nil {
+		return err
+	}
+	if err := validateQuirks(req.Quirks); err != nil {
+		return err
+	}
+	return validateAcceptHeader(req.AcceptHeader)
+}
+
+// validateTLSPins rejects pins the gateway could never match
+func validateTLSPins(pins []string) error {
+	for _, pin := range pins {
+		if err := gateway.ValidateSPKIPin(pin); err != nil {
+			return fmt.Errorf("invalid tls_pins: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateForwardHeaders rejects allowlist entries that aren't header names
+func validateForwardHeaders(names []string) error {
+	for _, name := range names {
+		if err := gateway.ValidateForwardHeader(name); err != nil {
+			return fmt.Errorf("invalid forward_headers: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateJSONRPCIDType rejects id types the gateway can't generate
+func validateJSONRPCIDType(idType domain.JSONRPCIDType) error {
+	switch idType {
+	case "", domain.JSONRPCIDNumber, domain.JSONRPCIDString:
+		return nil
+	}
+	return fmt.Errorf("invalid jsonrpc_id_type %q: must be %q or %q", idType, domain.JSONRPCIDNumber, domain.JSONRPCIDString)
+}
+
+// validateElicitationPolicy rejects unknown elicitation policies
+func validateElicitationPolicy(policy domain.ElicitationPolicy) error {
+	switch policy {
+	case "", domain.ElicitationDeny, domain.ElicitationAllow:
+		return nil
+	}
+	return fmt.Errorf("invalid elicitation_policy %q: must be %q or %q", policy, domain.ElicitationDeny, domain.ElicitationAllow)
+}
+
+// validateAcceptHeader rejects Accept overrides the gateway couldn't read a response for
+func validateAcceptHeader(value string) error {
+	if err := gateway.ValidateAcceptHeader(value); err != nil {
+		return fmt.Errorf("invalid accept_header: %w", err)
+	}
+	return nil
+}
+
+// validateQuirks rejects quirks the gateway can't apply
+func validateQuirks(quirks domain.ServerQuirks) error {
+	if err := gateway.ValidateQuirks(quirks); err != nil {
+		return fmt.Errorf("invalid quirks: %w", err)
+	}
+	return nil
+}
+
 // GetServer handles GET /api/v1/servers/:id
 func (h *RegistryHandler) GetServer(c *gin.Context) {
 	id := c.Param("id")
@@ -179,6 +595,7 @@ func (h *RegistryHandler) GetServer(c *gin.Context) {
 			return
 		}
 		if !canAccess {
+			middleware.RecordAccessDenied(c, domain.DenyReasonServerAccess, "server:"+id)
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Access denied to this server",
 			})
@@ -222,6 +639,54 @@ func (h *RegistryHandler) UpdateServer(c *gin.Context) {
 		})
 		return
 	}
+	if req.TLSPins != nil {
+		if err := validateTLSPins(*req.TLSPins); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.ForwardHeaders != nil {
+		if err := validateForwardHeaders(*req.ForwardHeaders); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.JSONRPCIDType != nil {
+		if err := validateJSONRPCIDType(*req.JSONRPCIDType); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.ElicitationPolicy != nil {
+		if err := validateElicitationPolicy(*req.ElicitationPolicy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.AcceptHeader != nil {
+		if err := validateAcceptHeader(*req.AcceptHeader); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.Quirks != nil {
+		if err := validateQuirks(*req.Quirks); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
 
 	server, err := h.service.UpdateServer(c.Request.Context(), id, &req)
 	if err != nil {
@@ -231,6 +696,18 @@ func (h *RegistryHandler) UpdateServer(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, domain.ErrServerURLIsGateway) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points back at the gateway",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrServerURLBlocked) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points at an internal address",
+			})
+			return
+		}
 
 		h.logger.Error().Err(err).Str("server_id", id).Msg("Failed to update server")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -302,6 +779,12 @@ func (h *RegistryHandler) ToggleServer(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, domain.ErrServerLimitReached) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Active server limit reached",
+			})
+			return
+		}
 
 		h.logger.Error().Err(err).Str("server_id", id).Msg("Failed to toggle server")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -375,6 +858,86 @@ func (h *RegistryHandler) CheckHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, health)
 }
 
+// GetHealthEvents handles GET /api/v1/servers/:id/health/events
+// Returns recent health status transitions, newest first
+func (h *RegistryHandler) GetHealthEvents(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Server ID is required",
+		})
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid limit parameter (must be 1-500)",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.service.ListHealthEvents(c.Request.Context(), id, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrServerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Server not found",
+			})
+			return
+		}
+
+		h.logger.Error().Err(err).Str("server_id", id).Msg("Failed to list health events")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list health events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"server_id": id,
+		"events":    events,
+		"count":     len(events),
+	})
+}
+
+// TestAuth handles POST /api/v1/servers/:id/test-auth
+// Checks the server accepts its configured backend auth by initializing, without calling a tool
+func (h *RegistryHandler) TestAuth(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Server ID is required",
+		})
+		return
+	}
+
+	result, err := h.service.TestAuth(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrServerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Server not found",
+			})
+		case errors.Is(err, domain.ErrAuthTestUnsupported):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.Error().Err(err).Str("server_id", id).Msg("Failed to test backend auth")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to test backend auth",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // TestConnection handles POST /api/v1/servers/test-connection
 // Tests connectivity to an MCP server without saving it
 func (h *RegistryHandler) TestConnection(c *gin.Context) {
@@ -396,10 +959,21 @@ func (h *RegistryHandler) TestConnection(c *gin.Context) {
 
 	result, err := h.service.TestConnection(c.Request.Context(), &req)
 	if err != nil {
-		h.logger.Error().Err(err).Str("url", req.URL).Msg("Connection test failed")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Connection test failed",
-		})
+		switch {
+		case errors.Is(err, domain.ErrServerURLIsGateway):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points back at the gateway",
+			})
+		case errors.Is(err, domain.ErrServerURLBlocked):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points at an internal address",
+			})
+		default:
+			h.logger.Error().Err(err).Str("url", req.URL).Msg("Connection test failed")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Connection test failed",
+			})
+		}
 		return
 	}
 
@@ -443,3 +1017,56 @@ func (h *RegistryHandler) CallTool(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// TestCallTool handles POST /api/v1/servers/test-call
+// Calls a tool on an MCP server that isn't registered, with optional auth
+func (h *RegistryHandler) TestCallTool(c *gin.Context) {
+	var req registry.TestCallRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	if req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "URL is required",
+		})
+		return
+	}
+
+	if req.ToolName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Tool name is required",
+		})
+		return
+	}
+
+	result, err := h.service.TestCallTool(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTestCallUnsupported):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, domain.ErrServerURLIsGateway):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points back at the gateway",
+			})
+		case errors.Is(err, domain.ErrServerURLBlocked):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Server URL points at an internal address",
+			})
+		default:
+			h.logger.Error().Err(err).Str("url", req.URL).Str("tool", req.ToolName).Msg("Test tool call failed")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Tool call failed",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
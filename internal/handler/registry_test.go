@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -32,6 +33,8 @@ type mockRegistryService struct {
 	healthRecords map[string]*domain.ServerHealth
 
 	createServerFunc       func(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error)
+	importContinueOnError  *bool                  // Mode of the last import
+	imported               []*domain.ServerCreate // Requests of the last import
 	listServersForUserFunc func(ctx context.Context, filter *domain.ServerFilter, accessibleServerIDs []string) ([]*domain.MCPServer, error)
 	getServerFunc          func(ctx context.Context, id string) (*domain.MCPServer, error)
 	updateServerFunc       func(ctx context.Context, id string, req *domain.ServerUpdate) (*domain.MCPServer, error)
@@ -39,8 +42,11 @@ type mockRegistryService struct {
 	toggleServerFunc       func(ctx context.Context, id string, enabled bool) (*domain.MCPServer, error)
 	getHealthStatusFunc    func(ctx context.Context, serverID string) (*domain.ServerHealth, error)
 	checkHealthFunc        func(ctx context.Context, serverID string) error
+	listHealthEventsFunc   func(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error)
+	testAuthFunc           func(ctx context.Context, serverID string) (*registry.AuthTestResult, error)
 	testConnectionFunc     func(ctx context.Context, req *registry.TestConnectionRequest) (*registry.TestConnectionResult, error)
 	callToolFunc           func(ctx context.Context, req *registry.CallToolRequest) (*registry.CallToolResult, error)
+	testCallToolFunc       func(ctx context.Context, req *registry.TestCallRequest) (*registry.CallToolResult, error)
 }
 
 func newMockRegistryService() *mockRegistryService {
@@ -69,6 +75,36 @@ func (m *mockRegistryService) CreateServer(ctx context.Context, req *domain.Serv
 	return server, nil
 }
 
+// ImportServers validates each server and creates the valid ones; with
+// continueOnError unset, one invalid server fails them all
+func (m *mockRegistryService) ImportServers(ctx context.Context, reqs []*domain.ServerCreate, validate func(*domain.ServerCreate) error, continueOnError bool) *domain.ServerImportSummary {
+	m.importContinueOnError = &continueOnError
+	m.imported = reqs
+	summary := &domain.ServerImportSummary{Results: make([]domain.ServerImportResult, len(reqs))}
+	invalid := false
+	for i, req := range reqs {
+		summary.Results[i] = domain.ServerImportResult{Index: i, Name: req.Name}
+		if err := validate(req); err != nil {
+			summary.Results[i].Error = err.Error()
+			invalid = true
+		}
+	}
+	for i := range summary.Results {
+		result := &summary.Results[i]
+		switch {
+		case result.Error != "":
+			summary.Failed++
+		case invalid && !continueOnError:
+			result.Error = "not imported"
+			summary.Failed++
+		default:
+			result.Server, _ = m.CreateServer(ctx, reqs[i])
+			summary.Created++
+		}
+	}
+	return summary
+}
+
 func (m *mockRegistryService) ListServersForUser(ctx context.Context, filter *domain.ServerFilter, accessibleServerIDs []string) ([]*domain.MCPServer, error) {
 	if m.listServersForUserFunc != nil {
 		return m.listServersForUserFunc(ctx, filter, accessibleServerIDs)
@@ -145,6 +181,14 @@ func (m *mockRegistryService) GetHealthStatus(ctx context.Context, serverID stri
 	return health, nil
 }
 
+func (m *mockRegistryService) ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+	if m.listHealthEventsFunc != nil {
+		return m.listHealthEventsFunc(ctx, serverID, limit)
+	}
+
+	return []*domain.ServerHealthEvent{}, nil
+}
+
 func (m *mockRegistryService) CheckHealth(ctx context.Context, serverID string) error {
 	if m.checkHealthFunc != nil {
 		return m.checkHealthFunc(ctx, serverID)
@@ -153,6 +197,13 @@ func (m *mockRegistryService) CheckHealth(ctx context.Context, serverID string)
 	return nil
 }
 
+func (m *mockRegistryService) TestAuth(ctx context.Context, serverID string) (*registry.AuthTestResult, error) {
+	if m.testAuthFunc != nil {
+		return m.testAuthFunc(ctx, serverID)
+	}
+	return &registry.AuthTestResult{ServerID: serverID, Result: registry.AuthTestAuthenticated, Authenticated: true}, nil
+}
+
 func (m *mockRegistryService) TestConnection(ctx context.Context, req *registry.TestConnectionRequest) (*registry.TestConnectionResult, error) {
 	if m.testConnectionFunc != nil {
 		return m.testConnectionFunc(ctx, req)
@@ -164,6 +215,13 @@ func (m *mockRegistryService) TestConnection(ctx context.Context, req *registry.
 	}, nil
 }
 
+func (m *mockRegistryService) TestCallTool(ctx context.Context, req *registry.TestCallRequest) (*registry.CallToolResult, error) {
+	if m.testCallToolFunc != nil {
+		return m.testCallToolFunc(ctx, req)
+	}
+	return &registry.CallToolResult{Success: true}, nil
+}
+
 func (m *mockRegistryService) CallTool(ctx context.Context, req *registry.CallToolRequest) (*registry.CallToolResult, error) {
 	if m.callToolFunc != nil {
 		return m.callToolFunc(ctx, req)
@@ -486,6 +544,78 @@ func TestRegistryHandler_CreateServer(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
+	t.Run("invalid TLS pin", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "tls_pins": ["md5/abc"]}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "tls_pins")
+	})
+
+	t.Run("unreadable accept header", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "accept_header": "text/html"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "accept_header")
+	})
+
+	t.Run("invalid quirks", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "quirks": {"session_header": "x-session"}}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "quirks")
+	})
+
+	t.Run("invalid forward header", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "forward_headers": ["X Tenant"]}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "forward_headers")
+	})
+
+	t.Run("invalid jsonrpc id type", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "jsonrpc_id_type": "uuid"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "jsonrpc_id_type")
+	})
+
+	t.Run("invalid elicitation policy", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp", "elicitation_policy": "ask"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "elicitation_policy")
+	})
+
 	t.Run("service error", func(t *testing.T) {
 		mockSvc := newMockRegistryService()
 		mockSvc.createServerFunc = func(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
@@ -501,10 +631,249 @@ func TestRegistryHandler_CreateServer(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+
+	t.Run("server limit reached", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.createServerFunc = func(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
+			return nil, fmt.Errorf("%w: 2 of 2", domain.ErrServerLimitReached)
+		}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		body := `{"name": "test-server", "url": "https://example.com/mcp"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("server URL points back at the gateway", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.createServerFunc = func(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrServerURLIsGateway, req.URL)
+		}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		body := `{"name": "test-server", "url": "http://localhost:8080/api/v1/gateway/server-1"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "points back at the gateway")
+	})
+
+	t.Run("server URL at a blocked internal address", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.createServerFunc = func(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrServerURLBlocked, req.URL)
+		}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		body := `{"name": "test-server", "url": "http://169.254.169.254/latest/meta-data"}`
+		c, w := createTestContext("POST", "/api/v1/servers", []byte(body))
+
+		handler.CreateServer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "internal address")
+	})
 }
 
 // Tests for GetServer
 
+func TestRegistryHandler_ImportServers(t *testing.T) {
+	log := logger.NewNopLogger()
+	mixed := `[
+		{"name": "alpha", "url": "https://alpha.example.com/mcp"},
+		{"name": "broken", "url": "https://broken.example.com/mcp", "tls_pins": ["md5/abc"]},
+		{"name": "gamma", "url": "https://gamma.example.com/mcp"}
+	]`
+
+	t.Run("all-or-nothing rejects a mixed batch", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("POST", "/api/v1/servers/import", []byte(mixed))
+		handler.ImportServers(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		require.NotNil(t, mockSvc.importContinueOnError)
+		assert.False(t, *mockSvc.importContinueOnError)
+
+		var summary domain.ServerImportSummary
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, 0, summary.Created)
+		assert.Equal(t, 3, summary.Failed)
+		assert.Contains(t, summary.Results[1].Error, "tls_pins")
+		assert.Empty(t, mockSvc.servers)
+	})
+
+	t.Run("continue_on_error creates the valid servers", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("POST", "/api/v1/servers/import?continue_on_error=true", []byte(mixed))
+		handler.ImportServers(c)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		var summary domain.ServerImportSummary
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, 2, summary.Created)
+		assert.Equal(t, 1, summary.Failed)
+		assert.Equal(t, "alpha", summary.Results[0].Server.Name)
+		assert.Contains(t, summary.Results[1].Error, "tls_pins")
+		assert.Nil(t, summary.Results[1].Server)
+		assert.Len(t, mockSvc.servers, 2)
+	})
+
+	t.Run("YAML with a missing url", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		body := "- name: alpha\n  url: https://alpha.example.com/mcp\n  timeout_seconds: 5\n- name: nourl\n"
+		c, w := createTestContext("POST", "/api/v1/servers/import?continue_on_error=true", []byte(body))
+		c.Request.Header.Set("Content-Type", "application/yaml")
+		handler.ImportServers(c)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		var summary domain.ServerImportSummary
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.Equal(t, "alpha", summary.Results[0].Name)
+		assert.Equal(t, "url is required", summary.Results[1].Error)
+	})
+
+	t.Run("all created", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		body := `[{"name": "alpha", "url": "https://alpha.example.com/mcp"}]`
+		c, w := createTestContext("POST", "/api/v1/servers/import", []byte(body))
+		handler.ImportServers(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		for _, body := range []string{`{"name": "alpha"}`, `[]`, `[null]`} {
+			c, w := createTestContext("POST", "/api/v1/servers/import", []byte(body))
+			handler.ImportServers(c)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+
+		c, w := createTestContext("POST", "/api/v1/servers/import?continue_on_error=maybe", []byte(mixed))
+		handler.ImportServers(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRegistryHandler_ExportServers(t *testing.T) {
+	log := logger.NewNopLogger()
+	servers := []*domain.MCPServer{
+		{
+			ID: "s1", Name: "github", URL: "https://github.example.com/mcp", ProtocolVersion: "2025-06-18",
+			AuthType: domain.ServerAuthBearer, AuthConfig: json.RawMessage(`{"token":"ghp_secret"}`),
+			Tags: []string{"scm"}, TLSPins: []string{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}, TimeoutSeconds: 10, RequireInitialize: true,
+			AcceptHeader: "application/json", IsActive: true,
+		},
+		{
+			ID: "s2", Name: "db-tools", URL: "https://db.example.com/mcp",
+			AuthType: domain.ServerAuthBasic, AuthConfig: json.RawMessage(`{"username":"svc","password":"hunter2"}`),
+			Metadata: json.RawMessage(`{"team":"data"}`), IsActive: true,
+		},
+	}
+	newHandler := func() *RegistryHandler {
+		mockSvc := newMockRegistryService()
+		mockSvc.listServersForUserFunc = func(ctx context.Context, filter *domain.ServerFilter, ids []string) ([]*domain.MCPServer, error) {
+			return servers, nil
+		}
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		namespaces := newMockNamespaceRepo()
+		namespaces.namespaces["ns-1"] = &domain.Namespace{ID: "ns-1", Name: "engineering"}
+		namespaces.members["ns-1"] = []string{"s1", "s2"}
+		namespaces.roleAccess["ns-1"] = map[string]domain.AccessLevel{"operator": domain.AccessLevelExecute}
+		handler.SetNamespaceRepository(namespaces)
+		return handler
+	}
+	export := func(t *testing.T, query string, roles ...string) (*httptest.ResponseRecorder, *domain.ServerExport) {
+		t.Helper()
+		c, w := createTestContext("GET", "/api/v1/servers/export"+query, nil)
+		c.Set(middleware.ContextKeyUserRoles, roles)
+		newHandler().ExportServers(c)
+		if w.Code != http.StatusOK {
+			return w, nil
+		}
+		var doc domain.ServerExport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		return w, &doc
+	}
+
+	t.Run("secrets are omitted by default", func(t *testing.T) {
+		w, doc := export(t, "", "viewer")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "ghp_secret")
+		assert.NotContains(t, w.Body.String(), "hunter2")
+		assert.Equal(t, domain.ServerExportSecretsOmit, doc.Secrets)
+		require.Len(t, doc.Servers, 2)
+		assert.Nil(t, doc.Servers[0].AuthConfig)
+		assert.Equal(t, domain.ServerAuthBearer, doc.Servers[0].AuthType)
+
+		// Namespace membership and role access come along
+		assert.Equal(t, []string{"engineering"}, doc.Servers[0].Namespaces)
+		require.Len(t, doc.Namespaces, 1)
+		assert.Equal(t, []string{"github", "db-tools"}, doc.Namespaces[0].Servers)
+		assert.Equal(t, []domain.ExportedNamespaceRole{{Role: "role-operator", AccessLevel: domain.AccessLevelExecute}}, doc.Namespaces[0].RoleAccess)
+	})
+
+	t.Run("placeholders replace credentials", func(t *testing.T) {
+		w, doc := export(t, "?secrets=placeholder", "viewer")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "ghp_secret")
+		assert.NotContains(t, w.Body.String(), "hunter2")
+		assert.JSONEq(t, `{"token":"${WAFFLES_GITHUB_TOKEN}"}`, string(doc.Servers[0].AuthConfig))
+		assert.JSONEq(t, `{"username":"svc","password":"${WAFFLES_DB_TOOLS_PASSWORD}"}`, string(doc.Servers[1].AuthConfig))
+	})
+
+	t.Run("only admins export secrets", func(t *testing.T) {
+		w, _ := export(t, "?secrets=include", "operator")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w, doc := export(t, "?secrets=include", "admin")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"token":"ghp_secret"}`, string(doc.Servers[0].AuthConfig))
+	})
+
+	t.Run("unknown secrets mode", func(t *testing.T) {
+		w, _ := export(t, "?secrets=all", "admin")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("the export imports back", func(t *testing.T) {
+		w, _ := export(t, "?secrets=include", "admin")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		mockSvc := newMockRegistryService()
+		importer := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+		c, iw := createTestContext("POST", "/api/v1/servers/import", w.Body.Bytes())
+		importer.ImportServers(c)
+
+		assert.Equal(t, http.StatusCreated, iw.Code, iw.Body.String())
+		require.Len(t, mockSvc.imported, 2)
+		for i, server := range servers {
+			assert.Equal(t, exportServer(server, domain.ServerExportSecretsInclude), *mockSvc.imported[i])
+		}
+		assert.Equal(t, "https://github.example.com/mcp", mockSvc.imported[0].URL)
+		assert.True(t, mockSvc.imported[0].RequireInitialize)
+		assert.JSONEq(t, `{"username":"svc","password":"hunter2"}`, string(mockSvc.imported[1].AuthConfig))
+		assert.JSONEq(t, `{"team":"data"}`, string(mockSvc.imported[1].Metadata))
+	})
+}
+
 func TestRegistryHandler_GetServer(t *testing.T) {
 	log := logger.NewNopLogger()
 
@@ -527,6 +896,33 @@ func TestRegistryHandler_GetServer(t *testing.T) {
 		assert.Equal(t, "server-1", response.ID)
 	})
 
+	t.Run("redacts auth config secrets", func(t *testing.T) {
+		authConfig := json.RawMessage(`{"username":"bot","password":"hunter2"}`)
+		mockSvc := newMockRegistryService()
+		mockSvc.servers["server-1"] = &domain.MCPServer{ID: "server-1", AuthType: domain.ServerAuthBasic, AuthConfig: authConfig}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("GET", "/api/v1/servers/server-1", nil)
+		c.Params = gin.Params{{Key: "id", Value: "server-1"}}
+
+		handler.GetServer(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "hunter2")
+		var response struct {
+			AuthConfig json.RawMessage `json:"auth_config"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.JSONEq(t, `{"username":"bot","password":"***"}`, string(response.AuthConfig))
+		assert.Equal(t, authConfig, mockSvc.servers["server-1"].AuthConfig, "the stored server keeps the real values")
+
+		var buf bytes.Buffer
+		logger.NewZerolog(logger.Config{Level: logger.InfoLevel, Format: "json", Output: &buf}).
+			Info().Any("server", mockSvc.servers["server-1"]).Msg("Server")
+		assert.NotContains(t, buf.String(), "hunter2", "logged servers are redacted too")
+	})
+
 	t.Run("empty ID", func(t *testing.T) {
 		handler := NewRegistryHandler(nil, nil, log)
 
@@ -863,8 +1259,119 @@ func TestRegistryHandler_GetHealthStatus(t *testing.T) {
 	})
 }
 
+func TestRegistryHandler_GetHealthEvents(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	t.Run("success", func(t *testing.T) {
+		var gotLimit int
+		mockSvc := newMockRegistryService()
+		mockSvc.listHealthEventsFunc = func(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+			gotLimit = limit
+			return []*domain.ServerHealthEvent{{
+				ServerID:       serverID,
+				PreviousStatus: domain.ServerStatusHealthy,
+				NewStatus:      domain.ServerStatusUnhealthy,
+				ErrorMessage:   "Server error: 503",
+				OccurredAt:     time.Now(),
+			}}, nil
+		}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("GET", "/api/v1/servers/server-1/health/events?limit=10", nil)
+		c.Params = gin.Params{{Key: "id", Value: "server-1"}}
+
+		handler.GetHealthEvents(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 10, gotLimit)
+		var response struct {
+			Events []domain.ServerHealthEvent `json:"events"`
+			Count  int                        `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 1, response.Count)
+		assert.Equal(t, domain.ServerStatusHealthy, response.Events[0].PreviousStatus)
+		assert.Equal(t, domain.ServerStatusUnhealthy, response.Events[0].NewStatus)
+		assert.Equal(t, "Server error: 503", response.Events[0].ErrorMessage)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		handler := NewRegistryHandlerWithInterfaces(newMockRegistryService(), nil, log)
+
+		c, w := createTestContext("GET", "/api/v1/servers/server-1/health/events?limit=0", nil)
+		c.Params = gin.Params{{Key: "id", Value: "server-1"}}
+
+		handler.GetHealthEvents(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("server not found", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.listHealthEventsFunc = func(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+			return nil, domain.ErrServerNotFound
+		}
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("GET", "/api/v1/servers/missing/health/events", nil)
+		c.Params = gin.Params{{Key: "id", Value: "missing"}}
+
+		handler.GetHealthEvents(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.listHealthEventsFunc = func(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+			return nil, errors.New("database error")
+		}
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		c, w := createTestContext("GET", "/api/v1/servers/server-1/health/events", nil)
+		c.Params = gin.Params{{Key: "id", Value: "server-1"}}
+
+		handler.GetHealthEvents(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
 // Tests for CheckHealth
 
+func TestRegistryHandler_TestAuth(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not found", domain.ErrServerNotFound, http.StatusNotFound},
+		{"unsupported transport", fmt.Errorf("%w: websocket", domain.ErrAuthTestUnsupported), http.StatusBadRequest},
+		{"internal error", errors.New("database error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockRegistryService()
+			if tt.err != nil {
+				mockSvc.testAuthFunc = func(ctx context.Context, serverID string) (*registry.AuthTestResult, error) {
+					return nil, tt.err
+				}
+			}
+			handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+			c, w := createTestContext("POST", "/api/v1/servers/server-1/test-auth", nil)
+			c.Params = gin.Params{{Key: "id", Value: "server-1"}}
+			handler.TestAuth(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
 func TestRegistryHandler_CheckHealth(t *testing.T) {
 	log := logger.NewNopLogger()
 
@@ -998,6 +1505,22 @@ func TestRegistryHandler_TestConnection(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+
+	t.Run("blocked URL", func(t *testing.T) {
+		mockSvc := newMockRegistryService()
+		mockSvc.testConnectionFunc = func(ctx context.Context, req *registry.TestConnectionRequest) (*registry.TestConnectionResult, error) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrServerURLBlocked, req.URL)
+		}
+
+		handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+		body := `{"url": "http://169.254.169.254/"}`
+		c, w := createTestContext("POST", "/api/v1/servers/test-connection", []byte(body))
+
+		handler.TestConnection(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
 
 // Tests for CallTool
@@ -1066,6 +1589,45 @@ func TestRegistryHandler_CallTool(t *testing.T) {
 	})
 }
 
+func TestRegistryHandler_TestCallTool(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	tests := []struct {
+		name       string
+		body       string
+		result     *registry.CallToolResult
+		err        error
+		wantStatus int
+	}{
+		{"success", `{"url":"https://example.com/mcp","tool_name":"echo"}`, &registry.CallToolResult{Success: true}, nil, http.StatusOK},
+		{"upstream error", `{"url":"https://example.com/mcp","tool_name":"echo"}`, &registry.CallToolResult{ErrorMessage: "Tool call failed: 502"}, nil, http.StatusOK},
+		{"missing url", `{"tool_name":"echo"}`, nil, nil, http.StatusBadRequest},
+		{"missing tool name", `{"url":"https://example.com/mcp"}`, nil, nil, http.StatusBadRequest},
+		{"unsupported transport", `{"url":"https://example.com/mcp","tool_name":"echo","transport":"websocket"}`, nil, fmt.Errorf("%w: websocket", domain.ErrTestCallUnsupported), http.StatusBadRequest},
+		{"blocked url", `{"url":"http://169.254.169.254/","tool_name":"echo"}`, nil, domain.ErrServerURLBlocked, http.StatusBadRequest},
+		{"internal error", `{"url":"https://example.com/mcp","tool_name":"echo"}`, nil, errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockRegistryService()
+			mockSvc.testCallToolFunc = func(ctx context.Context, req *registry.TestCallRequest) (*registry.CallToolResult, error) {
+				return tt.result, tt.err
+			}
+			handler := NewRegistryHandlerWithInterfaces(mockSvc, nil, log)
+
+			c, w := createTestContext("POST", "/api/v1/servers/test-call", []byte(tt.body))
+			handler.TestCallTool(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.result != nil {
+				var got registry.CallToolResult
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, tt.result.ErrorMessage, got.ErrorMessage)
+			}
+		})
+	}
+}
+
 // Test domain types.
 func TestRegistryHandler_DomainTypes(t *testing.T) {
 	t.Run("ServerFilter struct", func(t *testing.T) {
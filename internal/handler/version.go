@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+// BuildInfo identifies the running gateway build
+type BuildInfo struct {
+	Version   string
+	BuildTime string
+}
+
+// VersionResponse is the body returned by GET /api/v1/version
+type VersionResponse struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	// MCP protocol versions the gateway speaks with clients and servers, newest first
+	ProtocolVersions []string `json:"mcp_protocol_versions"`
+}
+
+// VersionHandler reports the gateway's version so clients can check compatibility
+type VersionHandler struct {
+	info BuildInfo
+}
+
+// NewVersionHandler creates a new version handler
+func NewVersionHandler(info BuildInfo) *VersionHandler {
+	return &VersionHandler{info: info}
+}
+
+// GetVersion returns the gateway version, build time and supported MCP protocol versions
+// @Summary Gateway version
+// @Description Get the gateway version, build time and supported MCP protocol versions
+// @Tags health
+// @Produce json
+// @Success 200 {object} VersionResponse
+// @Router /api/v1/version [get]
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, VersionResponse{
+		Version:          h.info.Version,
+		BuildTime:        h.info.BuildTime,
+		GoVersion:        runtime.Version(),
+		ProtocolVersions: gateway.SupportedProtocolVersions,
+	})
+}
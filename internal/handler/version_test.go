@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/service/gateway"
+)
+
+func TestVersionHandler_GetVersion(t *testing.T) {
+	handler := NewVersionHandler(BuildInfo{Version: "v1.4.2", BuildTime: "2026-10-01T12:00:00Z"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/version", nil)
+
+	handler.GetVersion(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response VersionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "v1.4.2", response.Version)
+	assert.Equal(t, "2026-10-01T12:00:00Z", response.BuildTime)
+	assert.NotEmpty(t, response.GoVersion)
+	assert.Equal(t, gateway.SupportedProtocolVersions, response.ProtocolVersions)
+}
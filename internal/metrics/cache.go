@@ -0,0 +1,65 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Cache names, the cache label of the cache metrics
+const (
+	CacheTools           = "tools"            // Gateway: tool names from each server's last tools/list
+	CacheAccessDecisions = "access_decisions" // Resource RBAC: recent server access decisions
+	CacheOAuthTokens     = "oauth_tokens"     // Auth: validated bearer tokens
+	CacheSecrets         = "secrets"          // Gateway: resolved auth config secret references
+)
+
+// CacheMetrics is how a cache reports its effectiveness. Every cache reports through it,
+// so their hits, misses, size and evictions are exported alike, labelled by cache name.
+type CacheMetrics interface {
+	// Hit records a lookup answered from the cache
+	Hit()
+	// Miss records a lookup that found nothing usable, including an expired entry
+	Miss()
+	// Evicted records n entries dropped to make room for new ones
+	Evicted(n int)
+	// SetSize reports the number of entries the cache holds
+	SetSize(n int)
+}
+
+// NopCacheMetrics reports nothing, for caches without metrics
+var NopCacheMetrics CacheMetrics = nopCacheMetrics{}
+
+type nopCacheMetrics struct{}
+
+func (nopCacheMetrics) Hit()        {}
+func (nopCacheMetrics) Miss()       {}
+func (nopCacheMetrics) Evicted(int) {}
+func (nopCacheMetrics) SetSize(int) {}
+
+// DisableCacheMetrics makes Cache return NopCacheMetrics. Must be called before the
+// caches are created.
+func (r *Registry) DisableCacheMetrics() {
+	r.cachesDisabled = true
+}
+
+// Cache returns the metrics the named cache reports to. It is safe to call on a nil
+// registry, which like a registry with cache metrics disabled returns NopCacheMetrics.
+func (r *Registry) Cache(name string) CacheMetrics {
+	if r == nil || r.cachesDisabled {
+		return NopCacheMetrics
+	}
+	return registryCacheMetrics{
+		hits:      r.CacheHits.WithLabelValues(name),
+		misses:    r.CacheMisses.WithLabelValues(name),
+		evictions: r.CacheEvictions.WithLabelValues(name),
+		entries:   r.CacheEntries.WithLabelValues(name),
+	}
+}
+
+// registryCacheMetrics reports one cache to the registry's cache metrics
+type registryCacheMetrics struct {
+	hits, misses, evictions prometheus.Counter
+	entries                 prometheus.Gauge
+}
+
+func (m registryCacheMetrics) Hit()          { m.hits.Inc() }
+func (m registryCacheMetrics) Miss()         { m.misses.Inc() }
+func (m registryCacheMetrics) Evicted(n int) { m.evictions.Add(float64(n)) }
+func (m registryCacheMetrics) SetSize(n int) { m.entries.Set(float64(n)) }
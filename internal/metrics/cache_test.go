@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleCache is a minimal cache holding up to two entries that reports through CacheMetrics
+type sampleCache struct {
+	entries []string
+	metrics CacheMetrics
+}
+
+func (c *sampleCache) get(key string) bool {
+	for _, entry := range c.entries {
+		if entry == key {
+			c.metrics.Hit()
+			return true
+		}
+	}
+	c.metrics.Miss()
+	return false
+}
+
+func (c *sampleCache) put(key string) {
+	c.entries = append(c.entries, key)
+	if len(c.entries) > 2 {
+		c.entries = c.entries[1:]
+		c.metrics.Evicted(1)
+	}
+	c.metrics.SetSize(len(c.entries))
+}
+
+func TestRegistry_Cache(t *testing.T) {
+	t.Run("counts hits and misses by cache name", func(t *testing.T) {
+		reg := NewRegistry()
+		sample := &sampleCache{metrics: reg.Cache("sample")}
+		other := &sampleCache{metrics: reg.Cache("other")}
+
+		sample.put("a")
+		sample.get("a")
+		sample.get("a")
+		sample.get("b")
+		other.get("a")
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(reg.CacheHits.WithLabelValues("sample")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.CacheMisses.WithLabelValues("sample")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(reg.CacheHits.WithLabelValues("other")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.CacheMisses.WithLabelValues("other")))
+	})
+
+	t.Run("reports size and evictions", func(t *testing.T) {
+		reg := NewRegistry()
+		sample := &sampleCache{metrics: reg.Cache("sample")}
+
+		for _, key := range []string{"a", "b", "c", "d"} {
+			sample.put(key)
+		}
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(reg.CacheEntries.WithLabelValues("sample")))
+		assert.Equal(t, float64(2), testutil.ToFloat64(reg.CacheEvictions.WithLabelValues("sample")))
+	})
+
+	t.Run("reports nothing when disabled or without a registry", func(t *testing.T) {
+		var nilReg *Registry
+		assert.Equal(t, NopCacheMetrics, nilReg.Cache("sample"))
+
+		reg := NewRegistry()
+		reg.DisableCacheMetrics()
+		sample := &sampleCache{metrics: reg.Cache("sample")}
+		sample.get("a")
+
+		assert.Equal(t, NopCacheMetrics, sample.metrics)
+		assert.Equal(t, 0, testutil.CollectAndCount(reg.CacheMisses))
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/waffles/waffles/internal/domain"
 )
 
 // DBStatsProvider is an interface for getting database pool stats
@@ -12,6 +15,12 @@ type DBStatsProvider interface {
 	Stats() *pgxpool.Stat
 }
 
+// DBPoolConfigProvider is implemented by stats providers that can report their pool
+// settings, so the configured limits are exported next to the usage they bound
+type DBPoolConfigProvider interface {
+	PoolConfig() *pgxpool.Config
+}
+
 // DBStatsCollector collects database connection pool statistics
 type DBStatsCollector struct {
 	registry   *Registry
@@ -46,6 +55,15 @@ func (c *DBStatsCollector) Collect() {
 	// pgxpool tracks acquire count and duration (similar to wait stats)
 	c.registry.DBConnectionWaitCount.Add(float64(stats.AcquireCount()))
 	c.registry.DBConnectionWaitDuration.Observe(stats.AcquireDuration().Seconds())
+
+	c.registry.DBPoolMaxConns.Set(float64(stats.MaxConns()))
+	if provider, ok := c.dbProvider.(DBPoolConfigProvider); ok {
+		if poolConfig := provider.PoolConfig(); poolConfig != nil {
+			c.registry.DBPoolMinConns.Set(float64(poolConfig.MinConns))
+			c.registry.DBPoolMaxConnLifetime.Set(poolConfig.MaxConnLifetime.Seconds())
+			c.registry.DBPoolMaxConnIdleTime.Set(poolConfig.MaxConnIdleTime.Seconds())
+		}
+	}
 }
 
 // ServerHealthProvider is an interface for getting server health status
@@ -125,3 +143,62 @@ func (c *ServerHealthCollector) Collect(ctx context.Context) error {
 
 	return nil
 }
+
+// MCPServerHealthCollector publishes mcp_server_up from the latest stored health check of
+// each registered server. Series for servers that are no longer registered are removed,
+// which keeps label cardinality bounded by the registry.
+type MCPServerHealthCollector struct {
+	registry *Registry
+	repo     ServerRepository
+	known    map[string]struct{} // Server IDs with series emitted on the last collection
+}
+
+// NewMCPServerHealthCollector creates a new MCP server health collector
+func NewMCPServerHealthCollector(registry *Registry, repo ServerRepository) *MCPServerHealthCollector {
+	return &MCPServerHealthCollector{
+		registry: registry,
+		repo:     repo,
+		known:    make(map[string]struct{}),
+	}
+}
+
+// Collect updates mcp_server_up for registered servers and drops series for removed ones.
+// Servers that have never been checked are not reported.
+func (c *MCPServerHealthCollector) Collect(ctx context.Context) error {
+	if c.repo == nil {
+		return nil
+	}
+
+	servers, err := c.repo.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	registered := make(map[string]struct{}, len(servers))
+	for _, server := range servers {
+		registered[server.ID] = struct{}{}
+
+		health, err := c.repo.GetHealthStatus(ctx, server.ID)
+		if err != nil || health == nil {
+			continue
+		}
+
+		// Drop series left behind by a rename before setting the current one
+		c.registry.MCPServerUp.DeletePartialMatch(prometheus.Labels{"server_id": server.ID})
+
+		var up float64
+		if health.Status == domain.ServerStatusHealthy || health.Status == domain.ServerStatusDegraded {
+			up = 1
+		}
+		c.registry.MCPServerUp.WithLabelValues(server.ID, server.Name).Set(up)
+	}
+
+	for id := range c.known {
+		if _, ok := registered[id]; !ok {
+			c.registry.DeleteMCPServerSeries(id)
+		}
+	}
+	c.known = registered
+
+	return nil
+}
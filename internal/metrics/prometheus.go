@@ -18,16 +18,48 @@ type Registry struct {
 	GatewayRequestsInFlight   *prometheus.GaugeVec
 	GatewayServerHealthStatus *prometheus.GaugeVec
 
+	// Gateway Concurrency Metrics (per-server MaxConnections limits)
+	GatewayConnectionsInUse   *prometheus.GaugeVec
+	GatewayConnectionQueue    *prometheus.GaugeVec
+	GatewayConnectionWaitTime *prometheus.HistogramVec
+
+	// Gateway Event Stream Metrics (resource update and elicitation subscribers)
+	GatewayEventSubscribers *prometheus.GaugeVec
+
+	// Gateway Tools Cache Metrics
+	GatewayToolsCacheEntries   prometheus.Gauge
+	GatewayToolsCacheEvictions prometheus.Counter
+
+	// Cache Metrics (every cache, labelled by cache name; see Cache)
+	CacheHits      *prometheus.CounterVec
+	CacheMisses    *prometheus.CounterVec
+	CacheEvictions *prometheus.CounterVec
+	CacheEntries   *prometheus.GaugeVec
+
+	// Gateway Session Metrics (MCP sessions with Streamable HTTP servers)
+	GatewaySessionsActive   prometheus.Gauge
+	GatewaySessionEvictions *prometheus.CounterVec
+
+	// MCP Server Health Metrics (health scheduler and MCPServerHealthCollector populate these)
+	MCPServerUp                *prometheus.GaugeVec
+	MCPServerResponseTime      *prometheus.HistogramVec
+	MCPServerHealthChecksTotal *prometheus.CounterVec
+
 	// Database Metrics (custom collectors will populate these)
 	DBConnectionsOpen        prometheus.Gauge
 	DBConnectionsInUse       prometheus.Gauge
 	DBConnectionsIdle        prometheus.Gauge
 	DBConnectionWaitCount    prometheus.Counter
 	DBConnectionWaitDuration prometheus.Histogram
+	DBPoolMaxConns           prometheus.Gauge
+	DBPoolMinConns           prometheus.Gauge
+	DBPoolMaxConnLifetime    prometheus.Gauge
+	DBPoolMaxConnIdleTime    prometheus.Gauge
 
 	// Audit Metrics
 	AuditLogsWrittenTotal  *prometheus.CounterVec
 	AuditLogsWriteDuration prometheus.Histogram
+	AuditLogsPrunedTotal   prometheus.Counter
 
 	// Registry Metrics
 	RegistryServersTotal      *prometheus.GaugeVec
@@ -35,6 +67,9 @@ type Registry struct {
 
 	// Prometheus registry
 	registry *prometheus.Registry
+
+	// cachesDisabled makes Cache hand out metrics that report nothing
+	cachesDisabled bool
 }
 
 // NewRegistry creates a new metrics registry with all metrics defined
@@ -104,6 +139,130 @@ func NewRegistry() *Registry {
 		[]string{"server_id", "server_name", "status"},
 	)
 
+	// Gateway Concurrency Metrics
+	r.GatewayConnectionsInUse = promauto.With(reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gateway_server_connections_in_use",
+			Help: "Current number of upstream calls holding a MaxConnections slot",
+		},
+		[]string{"server_id", "server_name"},
+	)
+
+	r.GatewayConnectionQueue = promauto.With(reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gateway_server_connection_queue_depth",
+			Help: "Current number of upstream calls waiting for a MaxConnections slot",
+		},
+		[]string{"server_id", "server_name"},
+	)
+
+	r.GatewayConnectionWaitTime = promauto.With(reg).NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gateway_server_connection_wait_seconds",
+			Help:    "Time spent waiting for a MaxConnections slot",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"server_id", "server_name"},
+	)
+
+	// Gateway Tools Cache Metrics
+	r.GatewayEventSubscribers = promauto.With(reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gateway_event_subscribers",
+			Help: "Current number of clients subscribed to a server's relayed resource updates and elicitations",
+		},
+		[]string{"server_id"},
+	)
+
+	r.GatewayToolsCacheEntries = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_tools_cache_entries",
+			Help: "Current number of servers with a cached tools/list",
+		},
+	)
+
+	r.GatewayToolsCacheEvictions = promauto.With(reg).NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_tools_cache_evictions_total",
+			Help: "Total number of cached tools/lists evicted to stay within tools_cache_max_entries",
+		},
+	)
+
+	// Cache Metrics
+	r.CacheHits = promauto.With(reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total number of cache lookups answered from the cache, by cache",
+		},
+		[]string{"cache"},
+	)
+
+	r.CacheMisses = promauto.With(reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total number of cache lookups that found nothing usable, by cache",
+		},
+		[]string{"cache"},
+	)
+
+	r.CacheEvictions = promauto.With(reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of entries dropped to make room in a full cache, by cache",
+		},
+		[]string{"cache"},
+	)
+
+	r.CacheEntries = promauto.With(reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cache_entries",
+			Help: "Current number of entries in a cache, by cache",
+		},
+		[]string{"cache"},
+	)
+
+	// Gateway Session Metrics
+	r.GatewaySessionsActive = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gateway_mcp_sessions_active",
+			Help: "Current number of MCP sessions held with Streamable HTTP servers",
+		},
+	)
+
+	r.GatewaySessionEvictions = promauto.With(reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_mcp_session_evictions_total",
+			Help: "Total number of MCP sessions evicted, by reason (idle or capacity)",
+		},
+		[]string{"reason"},
+	)
+
+	// MCP Server Health Metrics
+	r.MCPServerUp = promauto.With(reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_server_up",
+			Help: "Whether the MCP server passed its latest health check (1=up, 0=down)",
+		},
+		[]string{"server_id", "name"},
+	)
+
+	r.MCPServerResponseTime = promauto.With(reg).NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mcp_server_response_time_ms",
+			Help:    "MCP server health check response time in milliseconds",
+			Buckets: []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+		},
+		[]string{"server_id", "name"},
+	)
+
+	r.MCPServerHealthChecksTotal = promauto.With(reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_server_health_checks_total",
+			Help: "Total number of scheduled MCP server health checks by resulting status",
+		},
+		[]string{"server_id", "status"},
+	)
+
 	// Database Metrics
 	r.DBConnectionsOpen = promauto.With(reg).NewGauge(
 		prometheus.GaugeOpts{
@@ -141,6 +300,34 @@ func NewRegistry() *Registry {
 		},
 	)
 
+	r.DBPoolMaxConns = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "db_pool_max_connections",
+			Help: "Configured maximum size of the connection pool",
+		},
+	)
+
+	r.DBPoolMinConns = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "db_pool_min_connections",
+			Help: "Configured number of connections the pool keeps open",
+		},
+	)
+
+	r.DBPoolMaxConnLifetime = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "db_pool_max_connection_lifetime_seconds",
+			Help: "Configured time after which a connection is closed",
+		},
+	)
+
+	r.DBPoolMaxConnIdleTime = promauto.With(reg).NewGauge(
+		prometheus.GaugeOpts{
+			Name: "db_pool_max_connection_idle_time_seconds",
+			Help: "Configured time after which an idle connection is closed",
+		},
+	)
+
 	// Audit Metrics
 	r.AuditLogsWrittenTotal = promauto.With(reg).NewCounterVec(
 		prometheus.CounterOpts{
@@ -158,6 +345,13 @@ func NewRegistry() *Registry {
 		},
 	)
 
+	r.AuditLogsPrunedTotal = promauto.With(reg).NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_logs_pruned_total",
+			Help: "Total number of audit logs deleted by the retention pruner",
+		},
+	)
+
 	// Registry Metrics
 	r.RegistryServersTotal = promauto.With(reg).NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -178,6 +372,20 @@ func NewRegistry() *Registry {
 	return r
 }
 
+// RecordMCPServerHealthCheck records the outcome of a single MCP server health check
+func (r *Registry) RecordMCPServerHealthCheck(serverID, name, status string, responseTimeMs int) {
+	r.MCPServerHealthChecksTotal.WithLabelValues(serverID, status).Inc()
+	r.MCPServerResponseTime.WithLabelValues(serverID, name).Observe(float64(responseTimeMs))
+}
+
+// DeleteMCPServerSeries removes all MCP server health series for a server
+func (r *Registry) DeleteMCPServerSeries(serverID string) {
+	labels := prometheus.Labels{"server_id": serverID}
+	r.MCPServerUp.DeletePartialMatch(labels)
+	r.MCPServerResponseTime.DeletePartialMatch(labels)
+	r.MCPServerHealthChecksTotal.DeletePartialMatch(labels)
+}
+
 // GetRegistry returns the underlying Prometheus registry
 // This is needed for the HTTP handler to expose metrics
 func (r *Registry) GetRegistry() *prometheus.Registry {
@@ -23,6 +23,12 @@ func TestNewRegistry(t *testing.T) {
 	assert.NotNil(t, reg.GatewayRequestDuration)
 	assert.NotNil(t, reg.GatewayRequestsInFlight)
 	assert.NotNil(t, reg.GatewayServerHealthStatus)
+	assert.NotNil(t, reg.GatewayConnectionsInUse)
+	assert.NotNil(t, reg.GatewayConnectionQueue)
+	assert.NotNil(t, reg.GatewayConnectionWaitTime)
+	assert.NotNil(t, reg.GatewayEventSubscribers)
+	assert.NotNil(t, reg.GatewayToolsCacheEntries)
+	assert.NotNil(t, reg.GatewayToolsCacheEvictions)
 
 	// Verify Database metrics are initialized
 	assert.NotNil(t, reg.DBConnectionsOpen)
@@ -30,6 +36,10 @@ func TestNewRegistry(t *testing.T) {
 	assert.NotNil(t, reg.DBConnectionsIdle)
 	assert.NotNil(t, reg.DBConnectionWaitCount)
 	assert.NotNil(t, reg.DBConnectionWaitDuration)
+	assert.NotNil(t, reg.DBPoolMaxConns)
+	assert.NotNil(t, reg.DBPoolMinConns)
+	assert.NotNil(t, reg.DBPoolMaxConnLifetime)
+	assert.NotNil(t, reg.DBPoolMaxConnIdleTime)
 
 	// Verify Audit metrics are initialized
 	assert.NotNil(t, reg.AuditLogsWrittenTotal)
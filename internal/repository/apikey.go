@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/pkg/logger"
@@ -39,12 +38,12 @@ type APIKey struct {
 
 // APIKeyRepository handles API key data persistence
 type APIKeyRepository struct {
-	pool   *pgxpool.Pool
+	pool   DBTX
 	logger logger.Logger
 }
 
 // NewAPIKeyRepository creates a new API key repository
-func NewAPIKeyRepository(pool *pgxpool.Pool, log logger.Logger) *APIKeyRepository {
+func NewAPIKeyRepository(pool DBTX, log logger.Logger) *APIKeyRepository {
 	return &APIKeyRepository{
 		pool:   pool,
 		logger: log,
@@ -191,6 +190,7 @@ func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*APIK
 			COALESCE(namespaces, '{}'), COALESCE(ip_whitelist, '{}'), COALESCE(read_only, false)
 		FROM api_keys
 		WHERE key_hash = $1
+			OR (previous_key_hash = $1 AND previous_key_expires_at > NOW())
 	`
 
 	var apiKey APIKey
@@ -364,6 +364,67 @@ func (r *APIKeyRepository) AdminDelete(ctx context.Context, keyID string) error
 	return nil
 }
 
+// Rotate replaces an API key's secret, keeping its ID, owner, name, scopes and restrictions.
+// The old key stops authenticating immediately, or keeps working until previousValidUntil
+// when it is set. Returns the updated record and the new plain text key (only returned once!)
+func (r *APIKeyRepository) Rotate(ctx context.Context, keyID string, previousValidUntil *time.Time) (*APIKey, string, error) {
+	plainKey, keyHash, err := GenerateAPIKey()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to generate API key")
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	// The right-hand side of SET sees the row before the update, so previous_key_hash
+	// receives the hash being replaced
+	query := `
+		UPDATE api_keys
+		SET key_hash = $1,
+			key_prefix = $2,
+			previous_key_hash = CASE WHEN $3::timestamp IS NULL THEN NULL ELSE key_hash END,
+			previous_key_expires_at = $3
+		WHERE id = $4
+		RETURNING id, user_id, name, COALESCE(description, ''), key_hash, COALESCE(key_prefix, 'mcpgw_****'),
+			expires_at, last_used_at, created_at,
+			COALESCE(scopes, '{}'), COALESCE(allowed_servers, '{}'), COALESCE(allowed_tools, '{}'),
+			COALESCE(namespaces, '{}'), COALESCE(ip_whitelist, '{}'), COALESCE(read_only, false)
+	`
+
+	var apiKey APIKey
+	err = r.pool.QueryRow(ctx, query, keyHash, generateKeyPrefix(plainKey), previousValidUntil, keyID).Scan(
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.Name,
+		&apiKey.Description,
+		&apiKey.KeyHash,
+		&apiKey.KeyPrefix,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.CreatedAt,
+		&apiKey.Scopes,
+		&apiKey.AllowedServers,
+		&apiKey.AllowedTools,
+		&apiKey.Namespaces,
+		&apiKey.IPWhitelist,
+		&apiKey.ReadOnly,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Str("key_id", keyID).Msg("Failed to rotate API key")
+		return nil, "", fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	r.logger.Info().
+		Str("key_id", apiKey.ID).
+		Str("user_id", apiKey.UserID).
+		Bool("grace_period", previousValidUntil != nil).
+		Msg("API key rotated")
+
+	return &apiKey, plainKey, nil
+}
+
 // UpdateLastUsed updates the last_used_at timestamp for an API key
 func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, keyID string) error {
 	query := `
@@ -440,6 +501,19 @@ func (r *APIKeyRepository) GetByID(ctx context.Context, keyID string) (*APIKey,
 	return &apiKey, nil
 }
 
+// IsInactive reports whether the key has gone unused for longer than timeout as of now.
+// A key that was never used is measured from its creation. A timeout of 0 never expires keys.
+func (k *APIKey) IsInactive(timeout time.Duration, now time.Time) bool {
+	if timeout <= 0 {
+		return false
+	}
+	lastActivity := k.CreatedAt
+	if k.LastUsedAt != nil {
+		lastActivity = *k.LastUsedAt
+	}
+	return now.Sub(lastActivity) > timeout
+}
+
 // ToDomain converts the repository APIKey to domain.APIKey
 func (k *APIKey) ToDomain() *domain.APIKey {
 	return &domain.APIKey{
@@ -1,11 +1,19 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
 )
 
 func TestGenerateAPIKey(t *testing.T) {
@@ -182,6 +190,178 @@ func TestAPIKeyStruct(t *testing.T) {
 	assert.Equal(t, "mcpgw_abc1", apiKey.KeyPrefix)
 }
 
+func TestAPIKey_IsInactive(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastUsed := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		key     APIKey
+		timeout time.Duration
+		want    bool
+	}{
+		{
+			name:    "disabled timeout never expires",
+			key:     APIKey{CreatedAt: now.Add(-365 * 24 * time.Hour)},
+			timeout: 0,
+			want:    false,
+		},
+		{
+			name:    "recently used key is active",
+			key:     APIKey{CreatedAt: now.Add(-365 * 24 * time.Hour), LastUsedAt: lastUsed(time.Hour)},
+			timeout: 24 * time.Hour,
+			want:    false,
+		},
+		{
+			name:    "key unused past the timeout is inactive",
+			key:     APIKey{CreatedAt: now.Add(-365 * 24 * time.Hour), LastUsedAt: lastUsed(48 * time.Hour)},
+			timeout: 24 * time.Hour,
+			want:    true,
+		},
+		{
+			name:    "never-used key is measured from creation",
+			key:     APIKey{CreatedAt: now.Add(-48 * time.Hour)},
+			timeout: 24 * time.Hour,
+			want:    true,
+		},
+		{
+			name:    "new never-used key is active",
+			key:     APIKey{CreatedAt: now.Add(-time.Hour)},
+			timeout: 24 * time.Hour,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key.IsInactive(tt.timeout, now))
+		})
+	}
+}
+
+var apiKeyColumns = []string{
+	"id", "user_id", "name", "description", "key_hash", "key_prefix",
+	"expires_at", "last_used_at", "created_at",
+	"scopes", "allowed_servers", "allowed_tools", "namespaces", "ip_whitelist", "read_only",
+}
+
+func TestAPIKeyRepository_GetByHash(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewAPIKeyRepository(mock, logger.NewNopLogger())
+	now := time.Now()
+
+	t.Run("matches current or in-grace previous hash", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE key_hash = \$1\s+OR \(previous_key_hash = \$1 AND previous_key_expires_at > NOW\(\)\)`).
+			WithArgs("old-hash").
+			WillReturnRows(pgxmock.NewRows(apiKeyColumns).
+				AddRow("key-123", "user-123", "CI Key", "", "new-hash", "mcpgw_new1****abcd",
+					nil, nil, now, []string{"gateway:execute"}, []string{}, []string{}, []string{}, []string{}, false))
+
+		key, err := repo.GetByHash(context.Background(), "old-hash")
+
+		require.NoError(t, err)
+		assert.Equal(t, "key-123", key.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown or revoked hash is not found", func(t *testing.T) {
+		mock.ExpectQuery("FROM api_keys").
+			WithArgs("old-hash").
+			WillReturnError(pgx.ErrNoRows)
+
+		key, err := repo.GetByHash(context.Background(), "old-hash")
+
+		assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+		assert.Nil(t, key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("expired key is rejected", func(t *testing.T) {
+		expired := now.Add(-time.Hour)
+		mock.ExpectQuery("FROM api_keys").
+			WithArgs("hash").
+			WillReturnRows(pgxmock.NewRows(apiKeyColumns).
+				AddRow("key-123", "user-123", "CI Key", "", "hash", "mcpgw_old1****abcd",
+					&expired, nil, now, []string{}, []string{}, []string{}, []string{}, []string{}, false))
+
+		key, err := repo.GetByHash(context.Background(), "hash")
+
+		assert.ErrorIs(t, err, domain.ErrAPIKeyExpired)
+		assert.Nil(t, key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyRepository_Rotate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewAPIKeyRepository(mock, logger.NewNopLogger())
+	now := time.Now()
+
+	t.Run("replaces the hash and keeps scopes", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE api_keys").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), (*time.Time)(nil), "key-123").
+			WillReturnRows(pgxmock.NewRows(apiKeyColumns).
+				AddRow("key-123", "user-123", "CI Key", "", "new-hash", "mcpgw_new1****abcd",
+					nil, nil, now, []string{"gateway:execute"}, []string{"server-1"}, []string{}, []string{}, []string{}, true))
+
+		key, plainKey, err := repo.Rotate(context.Background(), "key-123", nil)
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plainKey, "mcpgw_"))
+		assert.Equal(t, "user-123", key.UserID)
+		assert.Equal(t, "CI Key", key.Name)
+		assert.Equal(t, []string{"gateway:execute"}, key.Scopes)
+		assert.Equal(t, []string{"server-1"}, key.AllowedServers)
+		assert.True(t, key.ReadOnly)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stores the grace period", func(t *testing.T) {
+		until := now.Add(30 * time.Minute)
+		mock.ExpectQuery("previous_key_hash = CASE WHEN").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), &until, "key-123").
+			WillReturnRows(pgxmock.NewRows(apiKeyColumns).
+				AddRow("key-123", "user-123", "CI Key", "", "new-hash", "mcpgw_new1****abcd",
+					nil, nil, now, []string{}, []string{}, []string{}, []string{}, []string{}, false))
+
+		_, _, err := repo.Rotate(context.Background(), "key-123", &until)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE api_keys").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), (*time.Time)(nil), "missing").
+			WillReturnError(pgx.ErrNoRows)
+
+		_, _, err := repo.Rotate(context.Background(), "missing", nil)
+
+		assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE api_keys").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), (*time.Time)(nil), "key-123").
+			WillReturnError(errors.New("connection refused"))
+
+		_, _, err := repo.Rotate(context.Background(), "key-123", nil)
+
+		assert.ErrorContains(t, err, "failed to rotate API key")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 // BenchmarkGenerateAPIKey measures API key generation performance
 func BenchmarkGenerateAPIKey(b *testing.B) {
 	for i := 0; i < b.N; i++ {
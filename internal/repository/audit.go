@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -25,11 +26,15 @@ func (r *AuditRepository) Create(ctx context.Context, log *domain.AuditLog) erro
 		INSERT INTO audit_logs (
 			user_id, server_id, request_id, method, path,
 			query_params, request_body, response_status, response_body,
-			latency_ms, ip_address, user_agent, error_message
+			latency_ms, ip_address, user_agent, error_message,
+			tool_name, tool_arguments, tool_is_error, tool_error_code,
+			access_denied, deny_reason, denied_resource
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9,
-			$10, $11, $12, $13
+			$10, $11, $12, $13,
+			$14, $15, $16, $17,
+			$18, $19, $20
 		)
 		RETURNING id, created_at
 	`
@@ -48,6 +53,13 @@ func (r *AuditRepository) Create(ctx context.Context, log *domain.AuditLog) erro
 		log.IPAddress,
 		log.UserAgent,
 		log.ErrorMessage,
+		log.ToolName,
+		log.ToolArguments,
+		log.ToolIsError,
+		log.ToolErrorCode,
+		log.AccessDenied,
+		log.DenyReason,
+		log.DeniedResource,
 	).Scan(&log.ID, &log.CreatedAt)
 
 	if err != nil {
@@ -63,7 +75,9 @@ func (r *AuditRepository) Get(ctx context.Context, id string) (*domain.AuditLog,
 		SELECT
 			id, user_id, server_id, request_id, method, path,
 			query_params, request_body, response_status, response_body,
-			latency_ms, ip_address::TEXT, user_agent, error_message, created_at
+			latency_ms, ip_address::TEXT, user_agent, error_message,
+			tool_name, tool_arguments, tool_is_error, tool_error_code,
+			access_denied, deny_reason, denied_resource, created_at
 		FROM audit_logs
 		WHERE id = $1
 	`
@@ -84,6 +98,13 @@ func (r *AuditRepository) Get(ctx context.Context, id string) (*domain.AuditLog,
 		&log.IPAddress,
 		&log.UserAgent,
 		&log.ErrorMessage,
+		&log.ToolName,
+		&log.ToolArguments,
+		&log.ToolIsError,
+		&log.ToolErrorCode,
+		&log.AccessDenied,
+		&log.DenyReason,
+		&log.DeniedResource,
 		&log.CreatedAt,
 	)
 
@@ -100,7 +121,9 @@ func (r *AuditRepository) List(ctx context.Context, filter domain.AuditLogFilter
 		SELECT
 			id, user_id, server_id, request_id, method, path,
 			query_params, request_body, response_status, response_body,
-			latency_ms, ip_address::TEXT, user_agent, error_message, created_at
+			latency_ms, ip_address::TEXT, user_agent, error_message,
+			tool_name, tool_arguments, tool_is_error, tool_error_code,
+			access_denied, deny_reason, denied_resource, created_at
 		FROM audit_logs
 		WHERE 1=1
 	`
@@ -139,6 +162,30 @@ func (r *AuditRepository) List(ctx context.Context, filter domain.AuditLogFilter
 		argIndex++
 	}
 
+	if filter.MinStatus != nil {
+		query += fmt.Sprintf(" AND response_status >= $%d", argIndex)
+		args = append(args, *filter.MinStatus)
+		argIndex++
+	}
+
+	if filter.MaxStatus != nil {
+		query += fmt.Sprintf(" AND response_status <= $%d", argIndex)
+		args = append(args, *filter.MaxStatus)
+		argIndex++
+	}
+
+	if filter.AccessDenied != nil {
+		query += fmt.Sprintf(" AND access_denied = $%d", argIndex)
+		args = append(args, *filter.AccessDenied)
+		argIndex++
+	}
+
+	if filter.DenyReason != nil {
+		query += fmt.Sprintf(" AND deny_reason = $%d", argIndex)
+		args = append(args, *filter.DenyReason)
+		argIndex++
+	}
+
 	if filter.FromDate != nil {
 		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
 		args = append(args, *filter.FromDate)
@@ -151,8 +198,15 @@ func (r *AuditRepository) List(ctx context.Context, filter domain.AuditLogFilter
 		argIndex++
 	}
 
-	// Order by created_at DESC
-	query += " ORDER BY created_at DESC"
+	// Keyset pagination: entries strictly after the cursor in listing order
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argIndex, argIndex+1)
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		argIndex += 2
+	}
+
+	// Newest first; id breaks ties between entries logged in the same microsecond
+	query += " ORDER BY created_at DESC, id DESC"
 
 	// Add limit and offset
 	if filter.Limit > 0 {
@@ -190,6 +244,13 @@ func (r *AuditRepository) List(ctx context.Context, filter domain.AuditLogFilter
 			&log.IPAddress,
 			&log.UserAgent,
 			&log.ErrorMessage,
+			&log.ToolName,
+			&log.ToolArguments,
+			&log.ToolIsError,
+			&log.ToolErrorCode,
+			&log.AccessDenied,
+			&log.DenyReason,
+			&log.DeniedResource,
 			&log.CreatedAt,
 		)
 		if err != nil {
@@ -200,3 +261,33 @@ func (r *AuditRepository) List(ctx context.Context, filter domain.AuditLogFilter
 
 	return logs, nil
 }
+
+// CountOlderThan returns how many audit logs were created before cutoff
+func (r *AuditRepository) CountOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs WHERE created_at < $1`, cutoff).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteOlderThan deletes up to limit of the oldest audit logs created before cutoff.
+// Deleting in bounded batches keeps each statement's locks short.
+func (r *AuditRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM audit_logs
+		WHERE id IN (
+			SELECT id FROM audit_logs
+			WHERE created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+		)
+	`
+
+	tag, err := r.pool.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
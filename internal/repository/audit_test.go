@@ -71,6 +71,8 @@ func TestAuditRepository_Create(t *testing.T) {
 	testServerID := createTestServer(t, pool)
 	defer cleanupTestServer(t, pool, testServerID)
 
+	toolIsError := true
+
 	tests := []struct {
 		name    string
 		log     *domain.AuditLog
@@ -103,6 +105,22 @@ func TestAuditRepository_Create(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "create tool call audit log",
+			log: &domain.AuditLog{
+				RequestID:      "req-tool",
+				Method:         "POST",
+				Path:           "/api/v1/gateway/server-123",
+				RequestBody:    json.RawMessage(`{"method":"tools/call","params":{"name":"deploy","arguments":{"password":"[REDACTED]"}}}`),
+				ResponseStatus: intPtr(200),
+				IPAddress:      "127.0.0.1",
+				UserAgent:      "test-client",
+				ToolName:       stringPtr("deploy"),
+				ToolArguments:  json.RawMessage(`{"password":"[REDACTED]"}`),
+				ToolIsError:    &toolIsError,
+			},
+			wantErr: false,
+		},
 		{
 			name: "create with server ID",
 			log: &domain.AuditLog{
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// encryptedAuthConfigPrefix marks an auth_config value that is encrypted. The value is a
+// JSON string, so it still fits the JSONB column: the prefix followed by the base64 of the
+// key version byte, the nonce and the AES-GCM sealed config.
+const encryptedAuthConfigPrefix = "enc:"
+
+// authConfigAAD binds ciphertexts to the column, so they can't be moved to another one
+// encrypted with the same key
+var authConfigAAD = []byte("mcp_servers.auth_config")
+
+// ErrAuthConfigDecrypt is returned for an encrypted auth config that can't be decrypted:
+// it was tampered with, is truncated, or its key isn't configured
+var ErrAuthConfigDecrypt = errors.New("failed to decrypt auth config")
+
+// AuthConfigCipher encrypts server auth configs at rest with AES-GCM. Each ciphertext
+// starts with the version of the key it was encrypted with, so keys can be rotated: new
+// configs use the current key while rows encrypted with older keys stay readable as long
+// as those keys are configured, and are re-encrypted when next updated.
+type AuthConfigCipher struct {
+	version byte
+	aeads   map[byte]cipher.AEAD // By key version
+}
+
+// NewAuthConfigCipher creates a cipher encrypting with the key of version and decrypting
+// with any of keys. Keys must be 16, 24 or 32 bytes, for AES-128, AES-192 or AES-256.
+func NewAuthConfigCipher(version byte, keys map[byte][]byte) (*AuthConfigCipher, error) {
+	if _, ok := keys[version]; !ok {
+		return nil, fmt.Errorf("no auth config key for version %d", version)
+	}
+	c := &AuthConfigCipher{version: version, aeads: make(map[byte]cipher.AEAD, len(keys))}
+	for v, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("auth config key version %d: %w", v, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("auth config key version %d: %w", v, err)
+		}
+		c.aeads[v] = aead
+	}
+	return c, nil
+}
+
+// NewAuthConfigCipherFromBase64 creates a cipher from base64-encoded keys: key is the
+// current one, of version, and previous holds older ones by version for decryption
+func NewAuthConfigCipherFromBase64(version int, key string, previous map[string]string) (*AuthConfigCipher, error) {
+	if version < 1 || version > 255 {
+		return nil, fmt.Errorf("auth config key version %d must be between 1 and 255", version)
+	}
+	keys := make(map[byte][]byte, len(previous)+1)
+	for v, encoded := range previous {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 255 {
+			return nil, fmt.Errorf("auth config key version %q must be between 1 and 255", v)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("auth config key version %d is not valid base64: %w", n, err)
+		}
+		keys[byte(n)] = decoded
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("auth config key is not valid base64: %w", err)
+	}
+	keys[byte(version)] = decoded
+	return NewAuthConfigCipher(byte(version), keys)
+}
+
+// Encrypt returns authConfig encrypted with the current key. Empty and null configs are
+// returned as they are.
+func (c *AuthConfigCipher) Encrypt(authConfig json.RawMessage) (json.RawMessage, error) {
+	if isEmptyAuthConfig(authConfig) {
+		return authConfig, nil
+	}
+	aead := c.aeads[c.version]
+	sealed := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(authConfig)+aead.Overhead())
+	sealed[0] = c.version
+	if _, err := rand.Read(sealed[1:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed = aead.Seal(sealed, sealed[1:], authConfig, authConfigAAD)
+	return json.Marshal(encryptedAuthConfigPrefix + base64.StdEncoding.EncodeToString(sealed))
+}
+
+// Decrypt returns the plaintext of an auth config stored by Encrypt. Configs stored before
+// encryption was enabled are returned as they are.
+func (c *AuthConfigCipher) Decrypt(stored json.RawMessage) (json.RawMessage, error) {
+	var value string
+	if isEmptyAuthConfig(stored) || json.Unmarshal(stored, &value) != nil || !strings.HasPrefix(value, encryptedAuthConfigPrefix) {
+		return stored, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedAuthConfigPrefix))
+	if err != nil || len(sealed) < 1 {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrAuthConfigDecrypt)
+	}
+	aead, ok := c.aeads[sealed[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key for version %d", ErrAuthConfigDecrypt, sealed[0])
+	}
+	if len(sealed) < 1+aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrAuthConfigDecrypt)
+	}
+	nonce, ciphertext := sealed[1:1+aead.NonceSize()], sealed[1+aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, authConfigAAD)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthConfigDecrypt, err)
+	}
+	return plaintext, nil
+}
+
+// isEmptyAuthConfig reports whether authConfig holds nothing worth encrypting
+func isEmptyAuthConfig(authConfig json.RawMessage) bool {
+	return len(authConfig) == 0 || string(authConfig) == "null"
+}
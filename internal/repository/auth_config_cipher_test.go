@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// testKey returns a 32-byte AES-256 key filled with b
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// capturedArg matches any query argument, keeping it
+type capturedArg struct {
+	value interface{}
+}
+
+func (a *capturedArg) Match(v interface{}) bool {
+	a.value = v
+	return true
+}
+
+func TestAuthConfigCipher(t *testing.T) {
+	cipher, err := NewAuthConfigCipher(1, map[byte][]byte{1: testKey(1)})
+	require.NoError(t, err)
+	plaintext := json.RawMessage(`{"token":"ghp_123"}`)
+
+	t.Run("round trips", func(t *testing.T) {
+		stored, err := cipher.Encrypt(plaintext)
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored), "ghp_123")
+		assert.True(t, json.Valid(stored), "ciphertexts fit the JSONB column")
+
+		decrypted, err := cipher.Decrypt(stored)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(plaintext), string(decrypted))
+	})
+
+	t.Run("uses a fresh nonce for each encryption", func(t *testing.T) {
+		first, err := cipher.Encrypt(plaintext)
+		require.NoError(t, err)
+		second, err := cipher.Encrypt(plaintext)
+		require.NoError(t, err)
+		assert.NotEqual(t, string(first), string(second))
+	})
+
+	t.Run("rejects tampered ciphertext", func(t *testing.T) {
+		stored, err := cipher.Encrypt(plaintext)
+		require.NoError(t, err)
+		var value string
+		require.NoError(t, json.Unmarshal(stored, &value))
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedAuthConfigPrefix))
+		require.NoError(t, err)
+		sealed[len(sealed)-1] ^= 0xff
+		tampered, err := json.Marshal(encryptedAuthConfigPrefix + base64.StdEncoding.EncodeToString(sealed))
+		require.NoError(t, err)
+
+		_, err = cipher.Decrypt(tampered)
+		assert.ErrorIs(t, err, ErrAuthConfigDecrypt)
+
+		_, err = cipher.Decrypt(json.RawMessage(`"enc:not base64"`))
+		assert.ErrorIs(t, err, ErrAuthConfigDecrypt)
+		_, err = cipher.Decrypt(json.RawMessage(`"enc:AQ=="`))
+		assert.ErrorIs(t, err, ErrAuthConfigDecrypt, "truncated")
+	})
+
+	t.Run("rejects ciphertext of another key", func(t *testing.T) {
+		other, err := NewAuthConfigCipher(1, map[byte][]byte{1: testKey(2)})
+		require.NoError(t, err)
+		stored, err := other.Encrypt(plaintext)
+		require.NoError(t, err)
+
+		_, err = cipher.Decrypt(stored)
+		assert.ErrorIs(t, err, ErrAuthConfigDecrypt)
+	})
+
+	t.Run("leaves plaintext and empty configs alone", func(t *testing.T) {
+		decrypted, err := cipher.Decrypt(plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		for _, empty := range []json.RawMessage{nil, json.RawMessage("null")} {
+			stored, err := cipher.Encrypt(empty)
+			require.NoError(t, err)
+			assert.Equal(t, empty, stored)
+		}
+	})
+
+	t.Run("decrypts with previous keys after rotation", func(t *testing.T) {
+		stored, err := cipher.Encrypt(plaintext)
+		require.NoError(t, err)
+
+		rotated, err := NewAuthConfigCipher(2, map[byte][]byte{1: testKey(1), 2: testKey(2)})
+		require.NoError(t, err)
+		decrypted, err := rotated.Decrypt(stored)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(plaintext), string(decrypted))
+
+		withoutOld, err := NewAuthConfigCipher(2, map[byte][]byte{2: testKey(2)})
+		require.NoError(t, err)
+		_, err = withoutOld.Decrypt(stored)
+		assert.ErrorContains(t, err, "no key for version 1")
+	})
+}
+
+func TestNewAuthConfigCipherFromBase64(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(testKey(1))
+
+	_, err := NewAuthConfigCipherFromBase64(2, key, map[string]string{"1": key})
+	assert.NoError(t, err)
+
+	_, err = NewAuthConfigCipherFromBase64(0, key, nil)
+	assert.ErrorContains(t, err, "must be between 1 and 255")
+
+	_, err = NewAuthConfigCipherFromBase64(1, base64.StdEncoding.EncodeToString([]byte("short")), nil)
+	assert.ErrorContains(t, err, "invalid key size")
+
+	_, err = NewAuthConfigCipherFromBase64(2, key, map[string]string{"old": key})
+	assert.ErrorContains(t, err, "must be between 1 and 255")
+}
+
+func TestServerRepository_AuthConfigEncryption(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	cipher, err := NewAuthConfigCipher(1, map[byte][]byte{1: testKey(1)})
+	require.NoError(t, err)
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+	repo.SetAuthConfigCipher(cipher)
+
+	req := &domain.ServerCreate{
+		Name:       "GitHub",
+		URL:        "https://example.com/mcp",
+		AuthType:   domain.ServerAuthBearer,
+		AuthConfig: json.RawMessage(`{"token":"ghp_123"}`),
+	}
+	now := time.Now()
+	stored := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO mcp_servers").
+		WithArgs(
+			req.Name, req.Description, req.URL, req.ProtocolVersion, domain.TransportHTTP,
+			req.AuthType, stored, req.HealthCheckURL, req.HealthCheckInterval,
+			req.TimeoutSeconds, req.MaxConnections, req.MaxRequestsPerMinute, req.MaxToolRequestsPerMinute, true, req.Tags, req.AllowedTools, req.ToolPrefix, req.TLSPins, req.MaxResponseBytes, req.ReplicaGroup, req.ForwardHeaders, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, req.Quirks, req.Metadata,
+		).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow("server-1", now, now))
+
+	server, err := repo.Create(context.Background(), req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"ghp_123"}`, string(server.AuthConfig), "callers see plaintext")
+	storedConfig, ok := stored.value.(json.RawMessage)
+	require.True(t, ok)
+	assert.NotContains(t, string(storedConfig), "ghp_123", "the database gets ciphertext")
+
+	getRows := func(authConfig json.RawMessage) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{
+			"id", "name", "description", "url", "protocol_version", "transport",
+			"auth_type", "auth_config", "health_check_url", "health_check_interval",
+			"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
+			"created_at", "updated_at",
+		}).AddRow(
+			"server-1", "GitHub", "", "https://example.com/mcp", "", domain.TransportHTTP,
+			domain.ServerAuthBearer, authConfig, "", 0,
+			0, 0, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil,
+			now, now,
+		)
+	}
+
+	mock.ExpectQuery("SELECT .+ FROM mcp_servers WHERE id = \\$1").
+		WithArgs("server-1").
+		WillReturnRows(getRows(storedConfig))
+	server, err = repo.Get(context.Background(), "server-1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"ghp_123"}`, string(server.AuthConfig))
+
+	tampered := json.RawMessage(strings.Replace(string(storedConfig), "enc:A", "enc:B", 1))
+	mock.ExpectQuery("SELECT .+ FROM mcp_servers WHERE id = \\$1").
+		WithArgs("server-1").
+		WillReturnRows(getRows(tampered))
+	_, err = repo.Get(context.Background(), "server-1")
+	assert.ErrorIs(t, err, ErrAuthConfigDecrypt)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
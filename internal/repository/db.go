@@ -13,3 +13,9 @@ type DBTX interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
+
+// txBeginner is implemented by pgxpool.Pool. Writes that must succeed or fail together
+// need it; a DBTX that is already a transaction doesn't.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
@@ -29,16 +29,22 @@ func NewNamespaceRepository(db DBTX, log logger.Logger) *NamespaceRepository {
 // Create creates a new namespace
 func (r *NamespaceRepository) Create(ctx context.Context, req *domain.NamespaceCreate) (*domain.Namespace, error) {
 	query := `
-		INSERT INTO namespaces (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description, created_at, updated_at
+		INSERT INTO namespaces (name, description, allowed_tools)
+		VALUES ($1, $2, $3)
+		RETURNING id, name, description, allowed_tools, created_at, updated_at
 	`
 
+	allowedTools := req.AllowedTools
+	if allowedTools == nil {
+		allowedTools = []string{}
+	}
+
 	var ns domain.Namespace
-	err := r.db.QueryRow(ctx, query, req.Name, req.Description).Scan(
+	err := r.db.QueryRow(ctx, query, req.Name, req.Description, allowedTools).Scan(
 		&ns.ID,
 		&ns.Name,
 		&ns.Description,
+		&ns.AllowedTools,
 		&ns.CreatedAt,
 		&ns.UpdatedAt,
 	)
@@ -54,7 +60,7 @@ func (r *NamespaceRepository) Create(ctx context.Context, req *domain.NamespaceC
 // getNamespaceBy is a helper that retrieves a namespace by a given column and value.
 func (r *NamespaceRepository) getNamespaceBy(ctx context.Context, column, value, logField string) (*domain.Namespace, error) {
 	query := fmt.Sprintf(`
-		SELECT n.id, n.name, n.description, n.created_at, n.updated_at,
+		SELECT n.id, n.name, n.description, n.allowed_tools, n.created_at, n.updated_at,
 			   (SELECT COUNT(*) FROM namespace_members WHERE namespace_id = n.id) as server_count
 		FROM namespaces n
 		WHERE n.%s = $1
@@ -65,6 +71,7 @@ func (r *NamespaceRepository) getNamespaceBy(ctx context.Context, column, value,
 		&ns.ID,
 		&ns.Name,
 		&ns.Description,
+		&ns.AllowedTools,
 		&ns.CreatedAt,
 		&ns.UpdatedAt,
 		&ns.ServerCount,
@@ -94,7 +101,7 @@ func (r *NamespaceRepository) GetByName(ctx context.Context, name string) (*doma
 // List retrieves all namespaces
 func (r *NamespaceRepository) List(ctx context.Context) ([]*domain.Namespace, error) {
 	query := `
-		SELECT n.id, n.name, n.description, n.created_at, n.updated_at,
+		SELECT n.id, n.name, n.description, n.allowed_tools, n.created_at, n.updated_at,
 			   (SELECT COUNT(*) FROM namespace_members WHERE namespace_id = n.id) as server_count
 		FROM namespaces n
 		ORDER BY n.name
@@ -114,6 +121,7 @@ func (r *NamespaceRepository) List(ctx context.Context) ([]*domain.Namespace, er
 			&ns.ID,
 			&ns.Name,
 			&ns.Description,
+			&ns.AllowedTools,
 			&ns.CreatedAt,
 			&ns.UpdatedAt,
 			&ns.ServerCount,
@@ -144,8 +152,17 @@ func (r *NamespaceRepository) Update(ctx context.Context, id string, req *domain
 		args = append(args, *req.Description)
 		argIndex++
 	}
+	if req.AllowedTools != nil {
+		allowedTools := *req.AllowedTools
+		if allowedTools == nil {
+			allowedTools = []string{}
+		}
+		query += fmt.Sprintf(", allowed_tools = $%d", argIndex)
+		args = append(args, allowedTools)
+		argIndex++
+	}
 
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, description, created_at, updated_at", argIndex)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, description, allowed_tools, created_at, updated_at", argIndex)
 	args = append(args, id)
 
 	var ns domain.Namespace
@@ -153,6 +170,7 @@ func (r *NamespaceRepository) Update(ctx context.Context, id string, req *domain
 		&ns.ID,
 		&ns.Name,
 		&ns.Description,
+		&ns.AllowedTools,
 		&ns.CreatedAt,
 		&ns.UpdatedAt,
 	)
@@ -257,6 +275,35 @@ func (r *NamespaceRepository) GetServerNamespaces(ctx context.Context, serverID
 	return namespaceIDs, nil
 }
 
+// GetServerToolAllowlists returns the tool allowlists of the namespaces a server belongs
+// to, skipping namespaces that allow all tools
+func (r *NamespaceRepository) GetServerToolAllowlists(ctx context.Context, serverID string) ([][]string, error) {
+	query := `
+		SELECT n.allowed_tools
+		FROM namespaces n
+		JOIN namespace_members nm ON nm.namespace_id = n.id
+		WHERE nm.server_id = $1 AND cardinality(n.allowed_tools) > 0
+	`
+
+	rows, err := r.db.Query(ctx, query, serverID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("server_id", serverID).Msg("Failed to get server tool allowlists")
+		return nil, fmt.Errorf("failed to get server tool allowlists: %w", err)
+	}
+	defer rows.Close()
+
+	var allowlists [][]string
+	for rows.Next() {
+		var allowedTools []string
+		if err := rows.Scan(&allowedTools); err != nil {
+			return nil, fmt.Errorf("failed to scan tool allowlist: %w", err)
+		}
+		allowlists = append(allowlists, allowedTools)
+	}
+
+	return allowlists, nil
+}
+
 // GetNamespaceServers returns all servers in a namespace
 func (r *NamespaceRepository) GetNamespaceServers(ctx context.Context, namespaceID string) ([]*domain.NamespaceMember, error) {
 	query := `
@@ -29,9 +29,9 @@ func TestNamespaceRepository_Create(t *testing.T) {
 		now := time.Now()
 
 		mock.ExpectQuery("INSERT INTO namespaces").
-			WithArgs(req.Name, req.Description).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-				AddRow("ns-123", req.Name, req.Description, now, now))
+			WithArgs(req.Name, req.Description, []string{}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "allowed_tools", "created_at", "updated_at"}).
+				AddRow("ns-123", req.Name, req.Description, []string{}, now, now))
 
 		ns, err := repo.Create(context.Background(), req)
 
@@ -49,7 +49,7 @@ func TestNamespaceRepository_Create(t *testing.T) {
 		}
 
 		mock.ExpectQuery("INSERT INTO namespaces").
-			WithArgs(req.Name, req.Description).
+			WithArgs(req.Name, req.Description, []string{}).
 			WillReturnError(errors.New("duplicate key"))
 
 		ns, err := repo.Create(context.Background(), req)
@@ -75,8 +75,8 @@ func TestNamespaceRepository_Get(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM namespaces n WHERE n.id = \\$1").
 			WithArgs(nsID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
-			}).AddRow(nsID, "test-ns", "desc", now, now, 5))
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
+			}).AddRow(nsID, "test-ns", "desc", []string{"search"}, now, now, 5))
 
 		ns, err := repo.Get(context.Background(), nsID)
 
@@ -85,6 +85,7 @@ func TestNamespaceRepository_Get(t *testing.T) {
 		assert.Equal(t, nsID, ns.ID)
 		assert.Equal(t, "test-ns", ns.Name)
 		assert.Equal(t, 5, ns.ServerCount)
+		assert.Equal(t, []string{"search"}, ns.AllowedTools)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -94,7 +95,7 @@ func TestNamespaceRepository_Get(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM namespaces n WHERE n.id = \\$1").
 			WithArgs(nsID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
 			}))
 
 		ns, err := repo.Get(context.Background(), nsID)
@@ -134,8 +135,8 @@ func TestNamespaceRepository_GetByName(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM namespaces n WHERE n.name = \\$1").
 			WithArgs(nsName).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
-			}).AddRow("ns-123", nsName, "desc", now, now, 3))
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
+			}).AddRow("ns-123", nsName, "desc", []string{}, now, now, 3))
 
 		ns, err := repo.GetByName(context.Background(), nsName)
 
@@ -149,7 +150,7 @@ func TestNamespaceRepository_GetByName(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM namespaces n WHERE n.name = \\$1").
 			WithArgs("unknown").
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
 			}))
 
 		ns, err := repo.GetByName(context.Background(), "unknown")
@@ -172,10 +173,10 @@ func TestNamespaceRepository_List(t *testing.T) {
 
 		mock.ExpectQuery("SELECT .+ FROM namespaces n ORDER BY n.name").
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
 			}).
-				AddRow("ns-1", "alpha", "Alpha namespace", now, now, 2).
-				AddRow("ns-2", "beta", "Beta namespace", now, now, 5))
+				AddRow("ns-1", "alpha", "Alpha namespace", []string{}, now, now, 2).
+				AddRow("ns-2", "beta", "Beta namespace", []string{}, now, now, 5))
 
 		namespaces, err := repo.List(context.Background())
 
@@ -189,7 +190,7 @@ func TestNamespaceRepository_List(t *testing.T) {
 	t.Run("returns empty list when no namespaces", func(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM namespaces n ORDER BY n.name").
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at", "server_count",
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at", "server_count",
 			}))
 
 		namespaces, err := repo.List(context.Background())
@@ -228,8 +229,8 @@ func TestNamespaceRepository_Update(t *testing.T) {
 		mock.ExpectQuery("UPDATE namespaces SET").
 			WithArgs(pgxmock.AnyArg(), newName, nsID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at",
-			}).AddRow(nsID, newName, "desc", now, now))
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at",
+			}).AddRow(nsID, newName, "desc", []string{}, now, now))
 
 		ns, err := repo.Update(context.Background(), nsID, req)
 
@@ -248,8 +249,8 @@ func TestNamespaceRepository_Update(t *testing.T) {
 		mock.ExpectQuery("UPDATE namespaces SET").
 			WithArgs(pgxmock.AnyArg(), newDesc, nsID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at",
-			}).AddRow(nsID, "name", newDesc, now, now))
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at",
+			}).AddRow(nsID, "name", newDesc, []string{}, now, now))
 
 		ns, err := repo.Update(context.Background(), nsID, req)
 
@@ -259,6 +260,26 @@ func TestNamespaceRepository_Update(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("successfully updates namespace allowed tools", func(t *testing.T) {
+		nsID := "ns-123"
+		tools := []string{"search", "fetch"}
+		now := time.Now()
+		req := &domain.NamespaceUpdate{AllowedTools: &tools}
+
+		mock.ExpectQuery("UPDATE namespaces SET .+ allowed_tools = \\$2").
+			WithArgs(pgxmock.AnyArg(), tools, nsID).
+			WillReturnRows(pgxmock.NewRows([]string{
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at",
+			}).AddRow(nsID, "name", "desc", tools, now, now))
+
+		ns, err := repo.Update(context.Background(), nsID, req)
+
+		require.NoError(t, err)
+		require.NotNil(t, ns)
+		assert.Equal(t, tools, ns.AllowedTools)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("returns ErrNotFound when namespace does not exist", func(t *testing.T) {
 		nsID := "nonexistent"
 		name := "name"
@@ -267,7 +288,7 @@ func TestNamespaceRepository_Update(t *testing.T) {
 		mock.ExpectQuery("UPDATE namespaces SET").
 			WithArgs(pgxmock.AnyArg(), name, nsID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "name", "description", "created_at", "updated_at",
+				"id", "name", "description", "allowed_tools", "created_at", "updated_at",
 			}))
 
 		ns, err := repo.Update(context.Background(), nsID, req)
@@ -456,6 +477,45 @@ func TestNamespaceRepository_GetServerNamespaces(t *testing.T) {
 	})
 }
 
+func TestNamespaceRepository_GetServerToolAllowlists(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewNamespaceRepository(mock, logger.NewNopLogger())
+
+	t.Run("successfully gets allowlists of the server's namespaces", func(t *testing.T) {
+		serverID := "server-123"
+
+		mock.ExpectQuery("SELECT n.allowed_tools FROM namespaces n .+ cardinality").
+			WithArgs(serverID).
+			WillReturnRows(pgxmock.NewRows([]string{"allowed_tools"}).
+				AddRow([]string{"search", "fetch"}).
+				AddRow([]string{"search"}))
+
+		allowlists, err := repo.GetServerToolAllowlists(context.Background(), serverID)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"search", "fetch"}, {"search"}}, allowlists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		serverID := "server-123"
+
+		mock.ExpectQuery("SELECT n.allowed_tools FROM namespaces n").
+			WithArgs(serverID).
+			WillReturnError(errors.New("query failed"))
+
+		allowlists, err := repo.GetServerToolAllowlists(context.Background(), serverID)
+
+		assert.Error(t, err)
+		assert.Nil(t, allowlists)
+		assert.Contains(t, err.Error(), "failed to get server tool allowlists")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestNamespaceRepository_GetNamespaceServers(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
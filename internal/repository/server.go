@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -16,6 +17,7 @@ import (
 type ServerRepository struct {
 	db     DBTX
 	logger logger.Logger
+	cipher *AuthConfigCipher // Encrypts auth configs at rest (nil = stored as they are)
 }
 
 // NewServerRepository creates a new server repository
@@ -26,14 +28,44 @@ func NewServerRepository(db DBTX, log logger.Logger) *ServerRepository {
 	}
 }
 
+// SetAuthConfigCipher encrypts server auth configs with cipher when they are stored, and
+// decrypts them when they are read. Configs stored before stay readable. Must be called
+// before the repository is used.
+func (r *ServerRepository) SetAuthConfigCipher(cipher *AuthConfigCipher) {
+	r.cipher = cipher
+}
+
+// encryptAuthConfig returns authConfig as it is stored
+func (r *ServerRepository) encryptAuthConfig(authConfig json.RawMessage) (json.RawMessage, error) {
+	if r.cipher == nil {
+		return authConfig, nil
+	}
+	return r.cipher.Encrypt(authConfig)
+}
+
+// decryptAuthConfig replaces the server's stored auth config with its plaintext
+func (r *ServerRepository) decryptAuthConfig(server *domain.MCPServer) error {
+	if r.cipher == nil {
+		return nil
+	}
+	authConfig, err := r.cipher.Decrypt(server.AuthConfig)
+	if err != nil {
+		r.logger.Error().Err(err).Str("server_id", server.ID).Msg("Failed to decrypt server auth config")
+		return fmt.Errorf("server %s: %w", server.ID, err)
+	}
+	server.AuthConfig = authConfig
+	return nil
+}
+
 // Create creates a new MCP server
 func (r *ServerRepository) Create(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
 	query := `
 		INSERT INTO mcp_servers (
 			name, description, url, protocol_version, transport,
 			auth_type, auth_config, health_check_url, health_check_interval,
-			timeout_seconds, max_connections, is_active, tags, allowed_tools, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			timeout_seconds, max_connections, max_requests_per_minute, max_tool_requests_per_minute,
+			is_active, tags, allowed_tools, tool_prefix, tls_pins, max_response_bytes, replica_group, forward_headers, jsonrpc_id_type, elicitation_policy, latency_budget_ms, max_batch_size, accept_header, require_initialize, quirks, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -42,23 +74,50 @@ func (r *ServerRepository) Create(ctx context.Context, req *domain.ServerCreate)
 	if transport == "" {
 		transport = domain.TransportHTTP
 	}
+	idType := req.JSONRPCIDType
+	if idType == "" {
+		idType = domain.JSONRPCIDNumber
+	}
+	elicitation := req.ElicitationPolicy
+	if elicitation == "" {
+		elicitation = domain.ElicitationDeny
+	}
+
+	authConfig, err := r.encryptAuthConfig(req.AuthConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server: %w", err)
+	}
 
 	var server domain.MCPServer
-	err := r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		req.Name,
 		req.Description,
 		req.URL,
 		req.ProtocolVersion,
 		transport,
 		req.AuthType,
-		req.AuthConfig,
+		authConfig,
 		req.HealthCheckURL,
 		req.HealthCheckInterval,
 		req.TimeoutSeconds,
 		req.MaxConnections,
+		req.MaxRequestsPerMinute,
+		req.MaxToolRequestsPerMinute,
 		true, // is_active defaults to true
 		req.Tags,
 		req.AllowedTools,
+		req.ToolPrefix,
+		req.TLSPins,
+		req.MaxResponseBytes,
+		req.ReplicaGroup,
+		req.ForwardHeaders,
+		idType,
+		elicitation,
+		req.LatencyBudgetMs,
+		req.MaxBatchSize,
+		req.AcceptHeader,
+		req.RequireInitialize,
+		req.Quirks,
 		req.Metadata,
 	).Scan(&server.ID, &server.CreatedAt, &server.UpdatedAt)
 
@@ -79,9 +138,23 @@ func (r *ServerRepository) Create(ctx context.Context, req *domain.ServerCreate)
 	server.HealthCheckInterval = req.HealthCheckInterval
 	server.TimeoutSeconds = req.TimeoutSeconds
 	server.MaxConnections = req.MaxConnections
+	server.MaxRequestsPerMinute = req.MaxRequestsPerMinute
+	server.MaxToolRequestsPerMinute = req.MaxToolRequestsPerMinute
 	server.IsActive = true // defaults to true
 	server.Tags = req.Tags
 	server.AllowedTools = req.AllowedTools
+	server.ToolPrefix = req.ToolPrefix
+	server.TLSPins = req.TLSPins
+	server.MaxResponseBytes = req.MaxResponseBytes
+	server.ReplicaGroup = req.ReplicaGroup
+	server.ForwardHeaders = req.ForwardHeaders
+	server.JSONRPCIDType = idType
+	server.ElicitationPolicy = elicitation
+	server.LatencyBudgetMs = req.LatencyBudgetMs
+	server.MaxBatchSize = req.MaxBatchSize
+	server.AcceptHeader = req.AcceptHeader
+	server.RequireInitialize = req.RequireInitialize
+	server.Quirks = req.Quirks
 	server.Metadata = req.Metadata
 
 	r.logger.Info().
@@ -99,7 +172,8 @@ func (r *ServerRepository) List(ctx context.Context, filter *domain.ServerFilter
 		SELECT
 			id, name, description, url, protocol_version, transport,
 			auth_type, auth_config, health_check_url, health_check_interval,
-			timeout_seconds, max_connections, is_active, tags, allowed_tools, metadata,
+			timeout_seconds, max_connections, max_requests_per_minute, max_tool_requests_per_minute,
+			is_active, tags, allowed_tools, tool_prefix, tls_pins, max_response_bytes, replica_group, forward_headers, jsonrpc_id_type, elicitation_policy, latency_budget_ms, max_batch_size, accept_header, require_initialize, quirks, system_tags, metadata,
 			created_at, updated_at
 		FROM mcp_servers
 		WHERE 1=1
@@ -120,7 +194,7 @@ func (r *ServerRepository) List(ctx context.Context, filter *domain.ServerFilter
 			argPos++
 		}
 		if len(filter.Tags) > 0 {
-			query += fmt.Sprintf(" AND tags && $%d", argPos)
+			query += fmt.Sprintf(" AND (tags || system_tags) && $%d", argPos)
 			args = append(args, filter.Tags)
 			argPos++
 		}
@@ -155,13 +229,17 @@ func (r *ServerRepository) List(ctx context.Context, filter *domain.ServerFilter
 		err := rows.Scan(
 			&s.ID, &s.Name, &s.Description, &s.URL, &s.ProtocolVersion, &s.Transport,
 			&s.AuthType, &s.AuthConfig, &s.HealthCheckURL, &s.HealthCheckInterval,
-			&s.TimeoutSeconds, &s.MaxConnections, &s.IsActive, &s.Tags, &s.AllowedTools, &s.Metadata,
+			&s.TimeoutSeconds, &s.MaxConnections, &s.MaxRequestsPerMinute, &s.MaxToolRequestsPerMinute,
+			&s.IsActive, &s.Tags, &s.AllowedTools, &s.ToolPrefix, &s.TLSPins, &s.MaxResponseBytes, &s.ReplicaGroup, &s.ForwardHeaders, &s.JSONRPCIDType, &s.ElicitationPolicy, &s.LatencyBudgetMs, &s.MaxBatchSize, &s.AcceptHeader, &s.RequireInitialize, &s.Quirks, &s.SystemTags, &s.Metadata,
 			&s.CreatedAt, &s.UpdatedAt,
 		)
 		if err != nil {
 			r.logger.Error().Err(err).Msg("Failed to scan server row")
 			continue
 		}
+		if err := r.decryptAuthConfig(&s); err != nil {
+			return nil, err
+		}
 		servers = append(servers, &s)
 	}
 
@@ -180,7 +258,8 @@ func (r *ServerRepository) Get(ctx context.Context, id string) (*domain.MCPServe
 		SELECT
 			id, name, description, url, protocol_version, transport,
 			auth_type, auth_config, health_check_url, health_check_interval,
-			timeout_seconds, max_connections, is_active, tags, allowed_tools, metadata,
+			timeout_seconds, max_connections, max_requests_per_minute, max_tool_requests_per_minute,
+			is_active, tags, allowed_tools, tool_prefix, tls_pins, max_response_bytes, replica_group, forward_headers, jsonrpc_id_type, elicitation_policy, latency_budget_ms, max_batch_size, accept_header, require_initialize, quirks, system_tags, metadata,
 			created_at, updated_at
 		FROM mcp_servers
 		WHERE id = $1
@@ -190,7 +269,8 @@ func (r *ServerRepository) Get(ctx context.Context, id string) (*domain.MCPServe
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&server.ID, &server.Name, &server.Description, &server.URL, &server.ProtocolVersion, &server.Transport,
 		&server.AuthType, &server.AuthConfig, &server.HealthCheckURL, &server.HealthCheckInterval,
-		&server.TimeoutSeconds, &server.MaxConnections, &server.IsActive, &server.Tags, &server.AllowedTools, &server.Metadata,
+		&server.TimeoutSeconds, &server.MaxConnections, &server.MaxRequestsPerMinute, &server.MaxToolRequestsPerMinute,
+		&server.IsActive, &server.Tags, &server.AllowedTools, &server.ToolPrefix, &server.TLSPins, &server.MaxResponseBytes, &server.ReplicaGroup, &server.ForwardHeaders, &server.JSONRPCIDType, &server.ElicitationPolicy, &server.LatencyBudgetMs, &server.MaxBatchSize, &server.AcceptHeader, &server.RequireInitialize, &server.Quirks, &server.SystemTags, &server.Metadata,
 		&server.CreatedAt, &server.UpdatedAt,
 	)
 
@@ -201,6 +281,9 @@ func (r *ServerRepository) Get(ctx context.Context, id string) (*domain.MCPServe
 		r.logger.Error().Err(err).Str("server_id", id).Msg("Failed to get server")
 		return nil, fmt.Errorf("failed to get server: %w", err)
 	}
+	if err := r.decryptAuthConfig(&server); err != nil {
+		return nil, err
+	}
 
 	r.logger.Debug().Str("server_id", id).Msg("Server retrieved")
 	return &server, nil
@@ -245,6 +328,12 @@ func (r *ServerRepository) Update(ctx context.Context, id string, req *domain.Se
 	if req.MaxConnections != nil {
 		current.MaxConnections = *req.MaxConnections
 	}
+	if req.MaxRequestsPerMinute != nil {
+		current.MaxRequestsPerMinute = *req.MaxRequestsPerMinute
+	}
+	if req.MaxToolRequestsPerMinute != nil {
+		current.MaxToolRequestsPerMinute = *req.MaxToolRequestsPerMinute
+	}
 	if req.IsActive != nil {
 		current.IsActive = *req.IsActive
 	}
@@ -254,6 +343,42 @@ func (r *ServerRepository) Update(ctx context.Context, id string, req *domain.Se
 	if req.AllowedTools != nil {
 		current.AllowedTools = *req.AllowedTools
 	}
+	if req.ToolPrefix != nil {
+		current.ToolPrefix = *req.ToolPrefix
+	}
+	if req.TLSPins != nil {
+		current.TLSPins = *req.TLSPins
+	}
+	if req.MaxResponseBytes != nil {
+		current.MaxResponseBytes = *req.MaxResponseBytes
+	}
+	if req.ReplicaGroup != nil {
+		current.ReplicaGroup = *req.ReplicaGroup
+	}
+	if req.ForwardHeaders != nil {
+		current.ForwardHeaders = *req.ForwardHeaders
+	}
+	if req.JSONRPCIDType != nil {
+		current.JSONRPCIDType = *req.JSONRPCIDType
+	}
+	if req.ElicitationPolicy != nil {
+		current.ElicitationPolicy = *req.ElicitationPolicy
+	}
+	if req.LatencyBudgetMs != nil {
+		current.LatencyBudgetMs = *req.LatencyBudgetMs
+	}
+	if req.MaxBatchSize != nil {
+		current.MaxBatchSize = *req.MaxBatchSize
+	}
+	if req.AcceptHeader != nil {
+		current.AcceptHeader = *req.AcceptHeader
+	}
+	if req.RequireInitialize != nil {
+		current.RequireInitialize = *req.RequireInitialize
+	}
+	if req.Quirks != nil {
+		current.Quirks = *req.Quirks
+	}
 	if req.Metadata != nil {
 		current.Metadata = req.Metadata
 	}
@@ -264,17 +389,28 @@ func (r *ServerRepository) Update(ctx context.Context, id string, req *domain.Se
 		SET name = $1, description = $2, url = $3, protocol_version = $4, transport = $5,
 		    auth_type = $6, auth_config = $7, health_check_url = $8,
 		    health_check_interval = $9, timeout_seconds = $10, max_connections = $11,
-		    is_active = $12, tags = $13, allowed_tools = $14, metadata = $15, updated_at = $16
-		WHERE id = $17
+		    max_requests_per_minute = $12, max_tool_requests_per_minute = $13,
+		    is_active = $14, tags = $15, allowed_tools = $16, tool_prefix = $17, tls_pins = $18,
+		    max_response_bytes = $19, replica_group = $20, forward_headers = $21,
+		    jsonrpc_id_type = $22, elicitation_policy = $23, latency_budget_ms = $24,
+		    max_batch_size = $25, accept_header = $26, require_initialize = $27,
+		    quirks = $28, metadata = $29, updated_at = $30
+		WHERE id = $31
 		RETURNING updated_at
 	`
 
+	authConfig, err := r.encryptAuthConfig(current.AuthConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update server: %w", err)
+	}
+
 	current.UpdatedAt = time.Now()
 	err = r.db.QueryRow(ctx, query,
 		current.Name, current.Description, current.URL, current.ProtocolVersion, current.Transport,
-		current.AuthType, current.AuthConfig, current.HealthCheckURL,
+		current.AuthType, authConfig, current.HealthCheckURL,
 		current.HealthCheckInterval, current.TimeoutSeconds, current.MaxConnections,
-		current.IsActive, current.Tags, current.AllowedTools, current.Metadata, current.UpdatedAt, id,
+		current.MaxRequestsPerMinute, current.MaxToolRequestsPerMinute,
+		current.IsActive, current.Tags, current.AllowedTools, current.ToolPrefix, current.TLSPins, current.MaxResponseBytes, current.ReplicaGroup, current.ForwardHeaders, current.JSONRPCIDType, current.ElicitationPolicy, current.LatencyBudgetMs, current.MaxBatchSize, current.AcceptHeader, current.RequireInitialize, current.Quirks, current.Metadata, current.UpdatedAt, id,
 	).Scan(&current.UpdatedAt)
 
 	if err != nil {
@@ -290,6 +426,104 @@ func (r *ServerRepository) Update(ctx context.Context, id string, req *domain.Se
 	return current, nil
 }
 
+// CreateBatch creates several MCP servers in one transaction: either all of them are
+// created or, when one fails, none are
+func (r *ServerRepository) CreateBatch(ctx context.Context, reqs []*domain.ServerCreate) ([]*domain.MCPServer, error) {
+	beginner, ok := r.db.(txBeginner)
+	if !ok {
+		return nil, fmt.Errorf("server repository cannot start a transaction")
+	}
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // No-op after commit
+
+	txRepo := &ServerRepository{db: tx, logger: r.logger, cipher: r.cipher}
+	servers := make([]*domain.MCPServer, 0, len(reqs))
+	for _, req := range reqs {
+		server, err := txRepo.Create(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("server %q: %w", req.Name, err)
+		}
+		servers = append(servers, server)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return servers, nil
+}
+
+// CountActive returns the number of active servers
+func (r *ServerRepository) CountActive(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM mcp_servers WHERE is_active = true`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active servers: %w", err)
+	}
+	return count, nil
+}
+
+// ListReplicas returns the active servers in a replica group, oldest first, each with its
+// latest health status in CurrentStatus (unknown when never checked)
+func (r *ServerRepository) ListReplicas(ctx context.Context, group string) ([]*domain.MCPServer, error) {
+	query := `
+		SELECT
+			s.id, s.name, s.description, s.url, s.protocol_version, s.transport,
+			s.auth_type, s.auth_config, s.health_check_url, s.health_check_interval,
+			s.timeout_seconds, s.max_connections, s.max_requests_per_minute, s.max_tool_requests_per_minute,
+			s.is_active, s.tags, s.allowed_tools, s.tool_prefix, s.tls_pins, s.max_response_bytes, s.replica_group, s.forward_headers, s.jsonrpc_id_type, s.elicitation_policy, s.latency_budget_ms, s.max_batch_size, s.accept_header, s.require_initialize, s.quirks, s.system_tags, s.metadata,
+			s.created_at, s.updated_at, h.status
+		FROM mcp_servers s
+		LEFT JOIN LATERAL (
+			SELECT status FROM server_health
+			WHERE server_id = s.id
+			ORDER BY checked_at DESC
+			LIMIT 1
+		) h ON true
+		WHERE s.replica_group = $1 AND s.is_active = true
+		ORDER BY s.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, group)
+	if err != nil {
+		r.logger.Error().Err(err).Str("replica_group", group).Msg("Failed to list replicas")
+		return nil, fmt.Errorf("failed to list replicas: %w", err)
+	}
+	defer rows.Close()
+
+	var servers []*domain.MCPServer
+	for rows.Next() {
+		var s domain.MCPServer
+		var status *string
+		err := rows.Scan(
+			&s.ID, &s.Name, &s.Description, &s.URL, &s.ProtocolVersion, &s.Transport,
+			&s.AuthType, &s.AuthConfig, &s.HealthCheckURL, &s.HealthCheckInterval,
+			&s.TimeoutSeconds, &s.MaxConnections, &s.MaxRequestsPerMinute, &s.MaxToolRequestsPerMinute,
+			&s.IsActive, &s.Tags, &s.AllowedTools, &s.ToolPrefix, &s.TLSPins, &s.MaxResponseBytes, &s.ReplicaGroup, &s.ForwardHeaders, &s.JSONRPCIDType, &s.ElicitationPolicy, &s.LatencyBudgetMs, &s.MaxBatchSize, &s.AcceptHeader, &s.RequireInitialize, &s.Quirks, &s.SystemTags, &s.Metadata,
+			&s.CreatedAt, &s.UpdatedAt, &status,
+		)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan replica row")
+			continue
+		}
+		if err := r.decryptAuthConfig(&s); err != nil {
+			return nil, err
+		}
+		s.CurrentStatus = &domain.ServerHealth{ServerID: s.ID, Status: domain.ServerStatusUnknown}
+		if status != nil {
+			s.CurrentStatus.Status = domain.ServerStatus(*status)
+		}
+		servers = append(servers, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating replicas: %w", err)
+	}
+	return servers, nil
+}
+
 // Delete deletes an MCP server by ID
 func (r *ServerRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM mcp_servers WHERE id = $1`
@@ -308,11 +542,32 @@ func (r *ServerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// SetSystemTags replaces a server's system tags. Its user tags and updated_at are left
+// alone, since the change comes from the gateway rather than an edit.
+func (r *ServerRepository) SetSystemTags(ctx context.Context, id string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	query := `UPDATE mcp_servers SET system_tags = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(ctx, query, tags, id)
+	if err != nil {
+		r.logger.Error().Err(err).Str("server_id", id).Msg("Failed to set system tags")
+		return fmt.Errorf("failed to set system tags: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrServerNotFound
+	}
+
+	return nil
+}
+
 // GetHealthStatus retrieves the latest health status for a server
 func (r *ServerRepository) GetHealthStatus(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
 	query := `
 		SELECT
-			id, server_id, status, response_time_ms, error_message, checked_at
+			id, server_id, status, response_time_ms, error_message, server_version, check_mode, checked_at
 		FROM server_health
 		WHERE server_id = $1
 		ORDER BY checked_at DESC
@@ -322,7 +577,7 @@ func (r *ServerRepository) GetHealthStatus(ctx context.Context, serverID string)
 	var health domain.ServerHealth
 	err := r.db.QueryRow(ctx, query, serverID).Scan(
 		&health.ID, &health.ServerID, &health.Status,
-		&health.ResponseTimeMs, &health.ErrorMessage, &health.CheckedAt,
+		&health.ResponseTimeMs, &health.ErrorMessage, &health.ServerVersion, &health.CheckMode, &health.CheckedAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -344,8 +599,8 @@ func (r *ServerRepository) GetHealthStatus(ctx context.Context, serverID string)
 // SaveHealthStatus saves a new health check result
 func (r *ServerRepository) SaveHealthStatus(ctx context.Context, health *domain.ServerHealth) error {
 	query := `
-		INSERT INTO server_health (server_id, status, response_time_ms, error_message, checked_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO server_health (server_id, status, response_time_ms, error_message, server_version, check_mode, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
@@ -354,6 +609,8 @@ func (r *ServerRepository) SaveHealthStatus(ctx context.Context, health *domain.
 		health.Status,
 		health.ResponseTimeMs,
 		health.ErrorMessage,
+		health.ServerVersion,
+		health.CheckMode,
 		health.CheckedAt,
 	).Scan(&health.ID)
 
@@ -370,6 +627,63 @@ func (r *ServerRepository) SaveHealthStatus(ctx context.Context, health *domain.
 	return nil
 }
 
+// SaveHealthEvent records a health status transition
+func (r *ServerRepository) SaveHealthEvent(ctx context.Context, event *domain.ServerHealthEvent) error {
+	query := `
+		INSERT INTO server_health_events (server_id, previous_status, new_status, error_message, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		event.ServerID,
+		event.PreviousStatus,
+		event.NewStatus,
+		event.ErrorMessage,
+		event.OccurredAt,
+	).Scan(&event.ID)
+
+	if err != nil {
+		r.logger.Error().Err(err).Str("server_id", event.ServerID).Msg("Failed to save health event")
+		return fmt.Errorf("failed to save health event: %w", err)
+	}
+
+	return nil
+}
+
+// ListHealthEvents retrieves the most recent health status transitions for a server, newest first
+func (r *ServerRepository) ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+	query := `
+		SELECT id, server_id, previous_status, new_status, error_message, occurred_at
+		FROM server_health_events
+		WHERE server_id = $1
+		ORDER BY occurred_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, serverID, limit)
+	if err != nil {
+		r.logger.Error().Err(err).Str("server_id", serverID).Msg("Failed to list health events")
+		return nil, fmt.Errorf("failed to list health events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.ServerHealthEvent, 0)
+	for rows.Next() {
+		var e domain.ServerHealthEvent
+		if err := rows.Scan(&e.ID, &e.ServerID, &e.PreviousStatus, &e.NewStatus, &e.ErrorMessage, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan health event: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating health events: %w", err)
+	}
+
+	return events, nil
+}
+
 // ListForUser retrieves MCP servers filtered by accessible server IDs
 // If accessibleServerIDs is nil, returns all servers (admin bypass)
 // If accessibleServerIDs is empty slice, returns no servers
@@ -379,7 +693,8 @@ func (r *ServerRepository) ListForUser(ctx context.Context, filter *domain.Serve
 		SELECT
 			id, name, description, url, protocol_version, transport,
 			auth_type, auth_config, health_check_url, health_check_interval,
-			timeout_seconds, max_connections, is_active, tags, allowed_tools, metadata,
+			timeout_seconds, max_connections, max_requests_per_minute, max_tool_requests_per_minute,
+			is_active, tags, allowed_tools, tool_prefix, tls_pins, max_response_bytes, replica_group, forward_headers, jsonrpc_id_type, elicitation_policy, latency_budget_ms, max_batch_size, accept_header, require_initialize, quirks, system_tags, metadata,
 			created_at, updated_at
 		FROM mcp_servers
 		WHERE 1=1
@@ -414,7 +729,7 @@ func (r *ServerRepository) ListForUser(ctx context.Context, filter *domain.Serve
 			argPos++
 		}
 		if len(filter.Tags) > 0 {
-			query += fmt.Sprintf(" AND tags && $%d", argPos)
+			query += fmt.Sprintf(" AND (tags || system_tags) && $%d", argPos)
 			args = append(args, filter.Tags)
 			argPos++
 		}
@@ -449,13 +764,17 @@ func (r *ServerRepository) ListForUser(ctx context.Context, filter *domain.Serve
 		err := rows.Scan(
 			&s.ID, &s.Name, &s.Description, &s.URL, &s.ProtocolVersion, &s.Transport,
 			&s.AuthType, &s.AuthConfig, &s.HealthCheckURL, &s.HealthCheckInterval,
-			&s.TimeoutSeconds, &s.MaxConnections, &s.IsActive, &s.Tags, &s.AllowedTools, &s.Metadata,
+			&s.TimeoutSeconds, &s.MaxConnections, &s.MaxRequestsPerMinute, &s.MaxToolRequestsPerMinute,
+			&s.IsActive, &s.Tags, &s.AllowedTools, &s.ToolPrefix, &s.TLSPins, &s.MaxResponseBytes, &s.ReplicaGroup, &s.ForwardHeaders, &s.JSONRPCIDType, &s.ElicitationPolicy, &s.LatencyBudgetMs, &s.MaxBatchSize, &s.AcceptHeader, &s.RequireInitialize, &s.Quirks, &s.SystemTags, &s.Metadata,
 			&s.CreatedAt, &s.UpdatedAt,
 		)
 		if err != nil {
 			r.logger.Error().Err(err).Msg("Failed to scan server row")
 			continue
 		}
+		if err := r.decryptAuthConfig(&s); err != nil {
+			return nil, err
+		}
 		servers = append(servers, &s)
 	}
 
@@ -42,7 +42,7 @@ func TestServerRepository_Create(t *testing.T) {
 			WithArgs(
 				req.Name, req.Description, req.URL, req.ProtocolVersion, req.Transport,
 				req.AuthType, req.AuthConfig, req.HealthCheckURL, req.HealthCheckInterval,
-				req.TimeoutSeconds, req.MaxConnections, true, req.Tags, req.AllowedTools, req.Metadata,
+				req.TimeoutSeconds, req.MaxConnections, req.MaxRequestsPerMinute, req.MaxToolRequestsPerMinute, true, req.Tags, req.AllowedTools, req.ToolPrefix, req.TLSPins, req.MaxResponseBytes, req.ReplicaGroup, req.ForwardHeaders, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, req.Quirks, req.Metadata,
 			).
 			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
 				AddRow(serverID, now, now))
@@ -71,7 +71,7 @@ func TestServerRepository_Create(t *testing.T) {
 			WithArgs(
 				req.Name, req.Description, req.URL, req.ProtocolVersion, domain.TransportHTTP,
 				req.AuthType, req.AuthConfig, req.HealthCheckURL, req.HealthCheckInterval,
-				req.TimeoutSeconds, req.MaxConnections, true, req.Tags, req.AllowedTools, req.Metadata,
+				req.TimeoutSeconds, req.MaxConnections, req.MaxRequestsPerMinute, req.MaxToolRequestsPerMinute, true, req.Tags, req.AllowedTools, req.ToolPrefix, req.TLSPins, req.MaxResponseBytes, req.ReplicaGroup, req.ForwardHeaders, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, req.Quirks, req.Metadata,
 			).
 			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
 				AddRow("server-456", now, now))
@@ -93,7 +93,7 @@ func TestServerRepository_Create(t *testing.T) {
 			WithArgs(
 				req.Name, req.Description, req.URL, req.ProtocolVersion, domain.TransportHTTP,
 				req.AuthType, req.AuthConfig, req.HealthCheckURL, req.HealthCheckInterval,
-				req.TimeoutSeconds, req.MaxConnections, true, req.Tags, req.AllowedTools, req.Metadata,
+				req.TimeoutSeconds, req.MaxConnections, req.MaxRequestsPerMinute, req.MaxToolRequestsPerMinute, true, req.Tags, req.AllowedTools, req.ToolPrefix, req.TLSPins, req.MaxResponseBytes, req.ReplicaGroup, req.ForwardHeaders, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, req.Quirks, req.Metadata,
 			).
 			WillReturnError(errors.New("database error"))
 
@@ -106,6 +106,57 @@ func TestServerRepository_Create(t *testing.T) {
 	})
 }
 
+func TestServerRepository_CreateBatch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+	insertArgs := make([]interface{}, 29)
+	for i := range insertArgs {
+		insertArgs[i] = pgxmock.AnyArg()
+	}
+	reqs := []*domain.ServerCreate{
+		{Name: "first", URL: "https://one.example.com/mcp"},
+		{Name: "second", URL: "https://two.example.com/mcp"},
+	}
+
+	t.Run("commits when every server is created", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO mcp_servers").WithArgs(insertArgs...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("server-1", now, now))
+		mock.ExpectQuery("INSERT INTO mcp_servers").WithArgs(insertArgs...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("server-2", now, now))
+		mock.ExpectCommit()
+
+		servers, err := repo.CreateBatch(context.Background(), reqs)
+
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		assert.Equal(t, "server-1", servers[0].ID)
+		assert.Equal(t, "second", servers[1].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when one server fails", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO mcp_servers").WithArgs(insertArgs...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("server-1", now, now))
+		mock.ExpectQuery("INSERT INTO mcp_servers").WithArgs(insertArgs...).
+			WillReturnError(errors.New("duplicate key value"))
+		mock.ExpectRollback()
+
+		servers, err := repo.CreateBatch(context.Background(), reqs)
+
+		require.Error(t, err)
+		assert.Nil(t, servers)
+		assert.Contains(t, err.Error(), `server "second"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestServerRepository_Get(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -122,12 +173,12 @@ func TestServerRepository_Get(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).AddRow(
 				serverID, "Test Server", "Description", "https://example.com", "1.0.0", domain.TransportHTTP,
 				domain.ServerAuthNone, nil, "", 60,
-				30, 100, true, []string{"test"}, nil, nil,
+				30, 100, 0, 0, true, []string{"test"}, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil,
 				now, now,
 			))
 
@@ -148,7 +199,7 @@ func TestServerRepository_Get(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			})) // Empty result
 
@@ -190,13 +241,13 @@ func TestServerRepository_List(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Server 1", "", "https://s1.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now).
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now).
 				AddRow("server-2", "Server 2", "", "https://s2.example.com", "1.0.0", domain.TransportSSE,
-					domain.ServerAuthBearer, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthBearer, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.List(context.Background(), nil)
 
@@ -216,11 +267,11 @@ func TestServerRepository_List(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Test Server", "", "https://test.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.List(context.Background(), filter)
 
@@ -240,11 +291,11 @@ func TestServerRepository_List(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Active Server", "", "https://active.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.List(context.Background(), filter)
 
@@ -263,11 +314,11 @@ func TestServerRepository_List(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-6", "Server 6", "", "https://s6.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.List(context.Background(), filter)
 
@@ -281,7 +332,7 @@ func TestServerRepository_List(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}))
 
@@ -305,6 +356,112 @@ func TestServerRepository_List(t *testing.T) {
 	})
 }
 
+func TestServerRepository_CountActive(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+
+	t.Run("counts active servers", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM mcp_servers WHERE is_active = true").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repo.CountActive(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT").
+			WillReturnError(errors.New("connection refused"))
+
+		_, err := repo.CountActive(context.Background())
+
+		assert.ErrorContains(t, err, "failed to count active servers")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestServerRepository_ListReplicas(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+
+	t.Run("lists active replicas with their latest health", func(t *testing.T) {
+		now := time.Now()
+		unhealthy := "unhealthy"
+		mock.ExpectQuery("SELECT .+ FROM mcp_servers s LEFT JOIN LATERAL .+ WHERE s.replica_group = \\$1 AND s.is_active = true").
+			WithArgs("search").
+			WillReturnRows(pgxmock.NewRows([]string{
+				"id", "name", "description", "url", "protocol_version", "transport",
+				"auth_type", "auth_config", "health_check_url", "health_check_interval",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
+				"created_at", "updated_at", "status",
+			}).
+				AddRow("server-1", "search-a", "", "http://a:8080", "", domain.TransportStreamableHTTP,
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "search", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now, &unhealthy).
+				AddRow("server-2", "search-b", "", "http://b:8080", "", domain.TransportStreamableHTTP,
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "search", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now, nil))
+
+		servers, err := repo.ListReplicas(context.Background(), "search")
+
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		assert.Equal(t, "search", servers[0].ReplicaGroup)
+		assert.Equal(t, domain.ServerStatusUnhealthy, servers[0].CurrentStatus.Status)
+		assert.Equal(t, domain.ServerStatusUnknown, servers[1].CurrentStatus.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM mcp_servers s").
+			WithArgs("search").
+			WillReturnError(errors.New("connection refused"))
+
+		_, err := repo.ListReplicas(context.Background(), "search")
+
+		assert.ErrorContains(t, err, "failed to list replicas")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestServerRepository_SetSystemTags(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+
+	t.Run("successfully sets system tags", func(t *testing.T) {
+		tags := []string{"has:resources", "has:tools"}
+
+		mock.ExpectExec("UPDATE mcp_servers SET system_tags = \\$1 WHERE id = \\$2").
+			WithArgs(tags, "server-123").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err := repo.SetSystemTags(context.Background(), "server-123", tags)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns ErrServerNotFound when server does not exist", func(t *testing.T) {
+		mock.ExpectExec("UPDATE mcp_servers SET system_tags").
+			WithArgs([]string{}, "nonexistent").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err := repo.SetSystemTags(context.Background(), "nonexistent", nil)
+
+		assert.ErrorIs(t, err, domain.ErrServerNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestServerRepository_Delete(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -367,8 +524,8 @@ func TestServerRepository_GetHealthStatus(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM server_health WHERE server_id = \\$1").
 			WithArgs(serverID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "server_id", "status", "response_time_ms", "error_message", "checked_at",
-			}).AddRow("health-1", serverID, domain.ServerStatusHealthy, 50, "", now))
+				"id", "server_id", "status", "response_time_ms", "error_message", "server_version", "check_mode", "checked_at",
+			}).AddRow("health-1", serverID, domain.ServerStatusHealthy, 50, "", "2.3.1", domain.HealthCheckModeMCP, now))
 
 		health, err := repo.GetHealthStatus(context.Background(), serverID)
 
@@ -376,6 +533,8 @@ func TestServerRepository_GetHealthStatus(t *testing.T) {
 		require.NotNil(t, health)
 		assert.Equal(t, domain.ServerStatusHealthy, health.Status)
 		assert.Equal(t, 50, health.ResponseTimeMs)
+		assert.Equal(t, "2.3.1", health.ServerVersion)
+		assert.Equal(t, domain.HealthCheckModeMCP, health.CheckMode)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -385,7 +544,7 @@ func TestServerRepository_GetHealthStatus(t *testing.T) {
 		mock.ExpectQuery("SELECT .+ FROM server_health WHERE server_id = \\$1").
 			WithArgs(serverID).
 			WillReturnRows(pgxmock.NewRows([]string{
-				"id", "server_id", "status", "response_time_ms", "error_message", "checked_at",
+				"id", "server_id", "status", "response_time_ms", "error_message", "server_version", "check_mode", "checked_at",
 			})) // Empty result
 
 		health, err := repo.GetHealthStatus(context.Background(), serverID)
@@ -430,7 +589,7 @@ func TestServerRepository_SaveHealthStatus(t *testing.T) {
 		}
 
 		mock.ExpectQuery("INSERT INTO server_health").
-			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.CheckedAt).
+			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.ServerVersion, health.CheckMode, health.CheckedAt).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow("health-new"))
 
 		err := repo.SaveHealthStatus(context.Background(), health)
@@ -450,7 +609,7 @@ func TestServerRepository_SaveHealthStatus(t *testing.T) {
 		}
 
 		mock.ExpectQuery("INSERT INTO server_health").
-			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.CheckedAt).
+			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.ServerVersion, health.CheckMode, health.CheckedAt).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow("health-err"))
 
 		err := repo.SaveHealthStatus(context.Background(), health)
@@ -468,7 +627,7 @@ func TestServerRepository_SaveHealthStatus(t *testing.T) {
 		}
 
 		mock.ExpectQuery("INSERT INTO server_health").
-			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.CheckedAt).
+			WithArgs(health.ServerID, health.Status, health.ResponseTimeMs, health.ErrorMessage, health.ServerVersion, health.CheckMode, health.CheckedAt).
 			WillReturnError(errors.New("insert failed"))
 
 		err := repo.SaveHealthStatus(context.Background(), health)
@@ -479,6 +638,100 @@ func TestServerRepository_SaveHealthStatus(t *testing.T) {
 	})
 }
 
+func TestServerRepository_SaveHealthEvent(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+
+	t.Run("successfully saves health event", func(t *testing.T) {
+		event := &domain.ServerHealthEvent{
+			ServerID:       "server-123",
+			PreviousStatus: domain.ServerStatusHealthy,
+			NewStatus:      domain.ServerStatusUnhealthy,
+			ErrorMessage:   "Connection refused",
+			OccurredAt:     time.Now(),
+		}
+
+		mock.ExpectQuery("INSERT INTO server_health_events").
+			WithArgs(event.ServerID, event.PreviousStatus, event.NewStatus, event.ErrorMessage, event.OccurredAt).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow("event-1"))
+
+		err := repo.SaveHealthEvent(context.Background(), event)
+
+		require.NoError(t, err)
+		assert.Equal(t, "event-1", event.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		event := &domain.ServerHealthEvent{ServerID: "server-123", OccurredAt: time.Now()}
+
+		mock.ExpectQuery("INSERT INTO server_health_events").
+			WithArgs(event.ServerID, event.PreviousStatus, event.NewStatus, event.ErrorMessage, event.OccurredAt).
+			WillReturnError(errors.New("database error"))
+
+		err := repo.SaveHealthEvent(context.Background(), event)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save health event")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestServerRepository_ListHealthEvents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewServerRepository(mock, logger.NewNopLogger())
+	columns := []string{"id", "server_id", "previous_status", "new_status", "error_message", "occurred_at"}
+
+	t.Run("returns events newest first", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT .+ FROM server_health_events WHERE server_id = \\$1").
+			WithArgs("server-123", 20).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("event-2", "server-123", domain.ServerStatusUnhealthy, domain.ServerStatusHealthy, "", now).
+				AddRow("event-1", "server-123", domain.ServerStatusHealthy, domain.ServerStatusUnhealthy, "timeout", now.Add(-time.Minute)))
+
+		events, err := repo.ListHealthEvents(context.Background(), "server-123", 20)
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "event-2", events[0].ID)
+		assert.Equal(t, domain.ServerStatusUnhealthy, events[0].PreviousStatus)
+		assert.Equal(t, "timeout", events[1].ErrorMessage)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns empty slice when no events", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM server_health_events").
+			WithArgs("server-456", 20).
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		events, err := repo.ListHealthEvents(context.Background(), "server-456", 20)
+
+		require.NoError(t, err)
+		assert.NotNil(t, events)
+		assert.Empty(t, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM server_health_events").
+			WithArgs("server-123", 20).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.ListHealthEvents(context.Background(), "server-123", 20)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list health events")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestServerRepository_ListForUser(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -493,13 +746,13 @@ func TestServerRepository_ListForUser(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Server 1", "", "https://s1.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now).
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now).
 				AddRow("server-2", "Server 2", "", "https://s2.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.ListForUser(context.Background(), nil, nil)
 
@@ -525,13 +778,13 @@ func TestServerRepository_ListForUser(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Server 1", "", "https://s1.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now).
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now).
 				AddRow("server-3", "Server 3", "", "https://s3.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.ListForUser(context.Background(), nil, accessibleIDs)
 
@@ -550,11 +803,11 @@ func TestServerRepository_ListForUser(t *testing.T) {
 			WillReturnRows(pgxmock.NewRows([]string{
 				"id", "name", "description", "url", "protocol_version", "transport",
 				"auth_type", "auth_config", "health_check_url", "health_check_interval",
-				"timeout_seconds", "max_connections", "is_active", "tags", "allowed_tools", "metadata",
+				"timeout_seconds", "max_connections", "max_requests_per_minute", "max_tool_requests_per_minute", "is_active", "tags", "allowed_tools", "tool_prefix", "tls_pins", "max_response_bytes", "replica_group", "forward_headers", "jsonrpc_id_type", "elicitation_policy", "latency_budget_ms", "max_batch_size", "accept_header", "require_initialize", "quirks", "system_tags", "metadata",
 				"created_at", "updated_at",
 			}).
 				AddRow("server-1", "Test Server", "", "https://test.example.com", "1.0.0", domain.TransportHTTP,
-					domain.ServerAuthNone, nil, "", 60, 30, 100, true, nil, nil, nil, now, now))
+					domain.ServerAuthNone, nil, "", 60, 30, 100, 0, 0, true, nil, nil, "", nil, int64(0), "", nil, domain.JSONRPCIDNumber, domain.ElicitationDeny, 0, 0, "", false, domain.ServerQuirks{}, nil, nil, now, now))
 
 		servers, err := repo.ListForUser(context.Background(), filter, accessibleIDs)
 
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// SessionRepository persists the gateway's MCP sessions with upstream servers
+type SessionRepository struct {
+	db     DBTX
+	logger logger.Logger
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db DBTX, log logger.Logger) *SessionRepository {
+	return &SessionRepository{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Save stores a server's session, replacing any previous one
+func (r *SessionRepository) Save(ctx context.Context, session *domain.PersistedSession) error {
+	query := `
+		INSERT INTO mcp_sessions (server_id, session_id, protocol_version, last_event_id, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (server_id) DO UPDATE
+		SET session_id = $2, protocol_version = $3, last_event_id = $4, updated_at = $5
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		session.ServerID, session.SessionID, session.ProtocolVersion, session.LastEventID, session.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a server's session. Deleting a missing session is not an error.
+func (r *SessionRepository) Delete(ctx context.Context, serverID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM mcp_sessions WHERE server_id = $1`, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// List returns every stored session
+func (r *SessionRepository) List(ctx context.Context) ([]*domain.PersistedSession, error) {
+	query := `
+		SELECT server_id, session_id, protocol_version, last_event_id, updated_at
+		FROM mcp_sessions
+		ORDER BY updated_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.PersistedSession
+	for rows.Next() {
+		var s domain.PersistedSession
+		if err := rows.Scan(&s.ServerID, &s.SessionID, &s.ProtocolVersion, &s.LastEventID, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestSessionRepository_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSessionRepository(mock, logger.NewNopLogger())
+	session := &domain.PersistedSession{
+		ServerID:        "server-1",
+		SessionID:       "session-1",
+		ProtocolVersion: "2025-06-18",
+		LastEventID:     "42",
+		UpdatedAt:       time.Now(),
+	}
+
+	t.Run("upserts the session", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO mcp_sessions .+ ON CONFLICT \\(server_id\\) DO UPDATE").
+			WithArgs(session.ServerID, session.SessionID, session.ProtocolVersion, session.LastEventID, session.UpdatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.Save(context.Background(), session))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO mcp_sessions").
+			WithArgs(session.ServerID, session.SessionID, session.ProtocolVersion, session.LastEventID, session.UpdatedAt).
+			WillReturnError(errors.New("connection refused"))
+
+		err := repo.Save(context.Background(), session)
+		assert.ErrorContains(t, err, "failed to save session")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSessionRepository(mock, logger.NewNopLogger())
+
+	mock.ExpectExec("DELETE FROM mcp_sessions WHERE server_id = \\$1").
+		WithArgs("server-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	require.NoError(t, repo.Delete(context.Background(), "server-1"), "deleting a missing session is not an error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionRepository_List(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSessionRepository(mock, logger.NewNopLogger())
+	now := time.Now()
+
+	t.Run("returns stored sessions", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM mcp_sessions").
+			WillReturnRows(pgxmock.NewRows([]string{
+				"server_id", "session_id", "protocol_version", "last_event_id", "updated_at",
+			}).
+				AddRow("server-1", "session-1", "2025-06-18", "42", now).
+				AddRow("server-2", "", "2024-11-05", "", now))
+
+		sessions, err := repo.List(context.Background())
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, &domain.PersistedSession{
+			ServerID: "server-1", SessionID: "session-1", ProtocolVersion: "2025-06-18", LastEventID: "42", UpdatedAt: now,
+		}, sessions[0])
+		assert.Equal(t, "server-2", sessions[1].ServerID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns error on database failure", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .+ FROM mcp_sessions").
+			WillReturnError(errors.New("connection refused"))
+
+		sessions, err := repo.List(context.Background())
+		assert.ErrorContains(t, err, "failed to list sessions")
+		assert.Nil(t, sessions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inflightRequests tracks the gateway requests in progress so shutdown can let them
+// finish. GET requests for an event stream are tracked separately: they only end when
+// the client leaves, so shutdown closes them instead of waiting.
+type inflightRequests struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   uint64
+	active   map[uint64]inflightRequest
+	calls    sync.WaitGroup // Requests other than event streams
+}
+
+// inflightRequest is a tracked request and the function that aborts it
+type inflightRequest struct {
+	cancel context.CancelFunc
+	stream bool
+}
+
+// drainResult counts how the requests in flight at shutdown ended
+type drainResult struct {
+	Drained int // Calls that finished within the grace period
+	Forced  int // Calls still running at the deadline, cancelled
+	Streams int // Event streams closed
+}
+
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{active: make(map[uint64]inflightRequest)}
+}
+
+// middleware tracks each request until its handlers return. Once draining has started,
+// new requests are refused with 503 so clients retry against another replica.
+func (r *inflightRequests) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stream := c.Request.Method == http.MethodGet && strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		id, ok := r.add(cancel, stream)
+		if !ok {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+			return
+		}
+		defer r.remove(id)
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// add starts tracking a request. Returns false if the server is draining.
+func (r *inflightRequests) add(cancel context.CancelFunc, stream bool) (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.draining {
+		return 0, false
+	}
+	r.nextID++
+	r.active[r.nextID] = inflightRequest{cancel: cancel, stream: stream}
+	if !stream {
+		r.calls.Add(1)
+	}
+	return r.nextID, true
+}
+
+// remove stops tracking a finished request
+func (r *inflightRequests) remove(id uint64) {
+	r.mu.Lock()
+	req, ok := r.active[id]
+	delete(r.active, id)
+	r.mu.Unlock()
+	if ok && !req.stream {
+		r.calls.Done()
+	}
+}
+
+// drain refuses new requests and waits up to grace for the calls in progress to finish.
+// Event streams, and calls still running at the deadline, are then cancelled.
+func (r *inflightRequests) drain(grace time.Duration) drainResult {
+	r.mu.Lock()
+	r.draining = true
+	calls := 0
+	for _, req := range r.active {
+		if !req.stream {
+			calls++
+		}
+	}
+	r.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		r.calls.Wait()
+		close(finished)
+	}()
+	timer := time.NewTimer(grace)
+	select {
+	case <-finished:
+	case <-timer.C:
+	}
+	timer.Stop()
+
+	var result drainResult
+	r.mu.Lock()
+	for _, req := range r.active {
+		req.cancel()
+		if req.stream {
+			result.Streams++
+		} else {
+			result.Forced++
+		}
+	}
+	r.mu.Unlock()
+	result.Drained = calls - result.Forced
+	return result
+}
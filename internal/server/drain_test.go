@@ -0,0 +1,116 @@
+package server
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/config"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// newDrainTestServer serves a gateway-like route group through a Server on a random port
+func newDrainTestServer(t *testing.T, drainTimeout time.Duration, routes func(*gin.RouterGroup)) (*Server, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Server: config.ServerConfig{
+		Environment:     "staging",
+		ShutdownTimeout: 5 * time.Second,
+		DrainTimeout:    drainTimeout,
+	}}
+	s := New(cfg, nil, logger.NewNopLogger(), nil, nil)
+	group := s.Router().Group("/gateway")
+	group.Use(s.inflight.middleware())
+	routes(group)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = s.httpServer.Serve(ln) }()
+	return s, "http://" + ln.Addr().String()
+}
+
+func TestShutdown_DrainsRequestInProgress(t *testing.T) {
+	started := make(chan struct{})
+	s, baseURL := newDrainTestServer(t, 5*time.Second, func(g *gin.RouterGroup) {
+		g.POST("/call", func(c *gin.Context) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			c.JSON(http.StatusOK, gin.H{"result": "done"})
+		})
+	})
+
+	type outcome struct {
+		status int
+		body   string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		resp, err := http.Post(baseURL+"/gateway/call", "application/json", nil)
+		if err != nil {
+			done <- outcome{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- outcome{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	require.NoError(t, s.Shutdown())
+
+	result := <-done
+	require.NoError(t, result.err)
+	assert.Equal(t, http.StatusOK, result.status)
+	assert.JSONEq(t, `{"result":"done"}`, result.body)
+}
+
+func TestShutdown_ClosesEventStreams(t *testing.T) {
+	streaming := make(chan struct{})
+	s, baseURL := newDrainTestServer(t, 50*time.Millisecond, func(g *gin.RouterGroup) {
+		g.GET("/events", func(c *gin.Context) {
+			c.Header("Content-Type", "text/event-stream")
+			c.Status(http.StatusOK)
+			c.Writer.Flush()
+			close(streaming)
+			<-c.Request.Context().Done()
+		})
+	})
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/gateway/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	<-streaming
+
+	start := time.Now()
+	require.NoError(t, s.Shutdown())
+	assert.Less(t, time.Since(start), 2*time.Second, "open streams don't hold up shutdown")
+
+	_, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err, "the stream ends cleanly")
+}
+
+func TestInflightRequests_RefusesRequestsWhileDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	inflight := newInflightRequests()
+	result := inflight.drain(time.Second)
+	assert.Equal(t, drainResult{}, result)
+
+	router := gin.New()
+	router.Use(inflight.middleware())
+	router.POST("/call", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/call", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -10,7 +11,9 @@ import (
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/waffles/waffles/internal/database"
 	"github.com/waffles/waffles/internal/handler"
 	"github.com/waffles/waffles/internal/handler/admin"
 	"github.com/waffles/waffles/internal/handler/middleware"
@@ -23,6 +26,7 @@ import (
 	"github.com/waffles/waffles/internal/service/role"
 	"github.com/waffles/waffles/internal/service/serveraccess"
 	"github.com/waffles/waffles/internal/service/user"
+	"github.com/waffles/waffles/pkg/logger"
 )
 
 // SetupRoutes configures all routes for the server
@@ -36,7 +40,16 @@ func (s *Server) SetupRoutes() {
 		s.router.Use(middleware.Metrics(s.metrics))
 	}
 	s.router.Use(middleware.RequestID())
-	s.router.Use(middleware.Logger(s.logger))
+	// Tracing middleware - after request ID so spans carry it
+	if s.config.Tracing.Enabled {
+		s.router.Use(middleware.Tracing())
+	}
+	s.router.Use(middleware.LoggerWithConfig(s.logger, middleware.AccessLogConfig{
+		SampleRate:           s.config.Logging.Access.SampleRate,
+		IncludeUserAgent:     s.config.Logging.Access.IncludeUserAgent,
+		IncludeUserID:        s.config.Logging.Access.IncludeUserID,
+		IncludeLatencyBucket: s.config.Logging.Access.IncludeLatencyBucket,
+	}))
 	s.router.Use(s.corsWithCredentials()) // Updated CORS for cookie auth
 	s.router.Use(middleware.Timeout(30 * time.Second))
 
@@ -45,22 +58,81 @@ func (s *Server) SetupRoutes() {
 	s.router.Use(sessions.Sessions("mcp_session", sessionStore))
 
 	// Create health handler
+	migrator := database.NewMigrator(database.BuildDSN(s.config.Database), s.logger)
 	healthHandler := handler.NewHealthHandler(s.db, s.logger)
+	healthHandler.SetMigrationCheck(migrator)
 
 	// Health check endpoints (public)
 	s.router.GET("/health", healthHandler.Health)
 	s.router.GET("/ready", healthHandler.Ready)
+	// Kubernetes probes: liveness doesn't touch the database, readiness also checks migrations
+	s.router.GET("/healthz", healthHandler.Health)
+	s.router.GET("/readyz", healthHandler.Readyz)
 
 	// Initialize repositories
 	serverRepo := repository.NewServerRepository(s.db.Pool, s.logger)
+	if enc := s.config.Secrets.AuthConfigEncryption; enc.Enabled {
+		cipher, err := repository.NewAuthConfigCipherFromBase64(enc.KeyVersion, enc.Key, enc.PreviousKeys)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("Failed to set up auth config encryption, auth configs are stored unencrypted")
+		} else {
+			serverRepo.SetAuthConfigCipher(cipher)
+		}
+	}
 	auditRepo := repository.NewAuditRepository(s.db.Pool)
 	userRepo := repository.NewUserRepository(s.db.Pool, s.logger)
 	apiKeyRepo := repository.NewAPIKeyRepository(s.db.Pool, s.logger)
 	namespaceRepo := repository.NewNamespaceRepository(s.db.Pool, s.logger)
 
+	// Proxy traffic and management APIs log at their own configured levels
+	gatewayLog := logger.ForSubsystem(s.logger, logger.SubsystemGateway)
+	apiLog := logger.ForSubsystem(s.logger, logger.SubsystemAPI)
+
 	// Initialize services
-	registryService := registry.NewService(serverRepo, s.logger)
-	gatewayService := gateway.NewService(serverRepo, s.logger, s.metrics)
+	gatewayService := gateway.NewServiceWithConfig(gateway.WithNamespaceAllowlists(serverRepo, namespaceRepo), gatewayLog, s.metrics, s.config.Gateway)
+	if s.config.Gateway.PersistSessions {
+		sessionRepo := repository.NewSessionRepository(s.db.Pool, s.logger)
+		if err := gatewayService.PersistSessions(context.Background(), sessionRepo); err != nil {
+			s.logger.Warn().Err(err).Msg("Failed to restore persisted MCP sessions")
+		}
+	}
+	addressGuard := s.newAddressGuard()
+	egress := s.newEgressPolicy(addressGuard)
+	if egress != nil {
+		gatewayService.SetEgressPolicy(egress)
+	}
+	if s.config.Gateway.DeadLetter.Enabled {
+		gatewayService.SetDeadLetters(gateway.NewMemoryDeadLetterStore(s.config.Gateway.DeadLetter.MaxEntries), s.config.Audit.RedactKeys)
+	}
+	if cfg := s.config.Gateway.DebugLog; cfg.Enabled {
+		gatewayService.SetDebugLog(gateway.NewDebugLog(cfg.MaxEntries, cfg.MaxResponseBytes, cfg.ServerIDs), s.config.Audit.RedactKeys)
+	}
+	var breakers registry.BreakerResetter
+	if s.config.Gateway.CircuitBreaker.Enabled && s.config.Gateway.CircuitBreaker.ResetOnManualHealthCheck {
+		breakers = gatewayService
+	}
+	registryService := registry.NewServiceWithConfig(serverRepo, apiLog, breakers, s.config.HealthCheck)
+	registryService.SetMaxActiveServers(s.config.Registry.MaxActiveServers)
+	registryService.SetMaxResponseBytes(s.config.Gateway.MaxResponseBytes)
+	registryService.SetSessionLimits(s.config.Gateway.SessionIdleTTL, s.config.Gateway.MaxSessions)
+	loopGuard := s.newLoopGuard()
+	if loopGuard != nil {
+		registryService.SetLoopGuard(loopGuard)
+	}
+	if egress != nil {
+		registryService.SetEgressPolicy(egress)
+	}
+	if addressGuard != nil {
+		registryService.SetAddressGuard(addressGuard)
+	}
+	if refs := s.config.Secrets.AuthRefs; refs.Enabled {
+		secrets := gateway.NewSecretStore(refs.CacheTTL)
+		secrets.SetMetrics(s.metrics)
+		secrets.Register("env", gateway.NewEnvSecretResolver(refs.EnvPrefix))
+		gatewayService.SetSecretStore(secrets)
+		registryService.SetSecretStore(secrets)
+	}
+	s.registry = registryService
 	auditService := audit.NewService(auditRepo, s.logger)
 
 	// Initialize server access service only if RBAC is enabled
@@ -69,16 +141,31 @@ func (s *Server) SetupRoutes() {
 	resourceRBACEnabled := s.config.Auth.ResourceRBACEnabled || s.config.Auth.ServerGroupRBACEnabled
 	if resourceRBACEnabled {
 		accessService = serveraccess.NewService(namespaceRepo, s.logger)
+		if cache := s.config.Auth.AccessCache; cache.Enabled {
+			accessService.EnableDecisionCache(cache.TTL, cache.MaxEntries, s.metrics)
+		}
 		s.logger.Info().Msg("Resource RBAC is ENABLED - users will only see servers they have access to")
 	} else {
 		s.logger.Info().Msg("Resource RBAC is DISABLED - all authenticated users see all servers")
 	}
 
-	// Initialize Casbin for authorization
-	casbinService, err := authz.NewCasbinServiceWithDefaults(s.logger)
+	// Initialize Casbin for authorization, from policy files when configured
+	var casbinService *authz.CasbinService
+	var err error
+	if s.config.Auth.CasbinModelPath != "" && s.config.Auth.CasbinPolicyPath != "" {
+		casbinService, err = authz.NewCasbinService(authz.Config{
+			ModelPath:  s.config.Auth.CasbinModelPath,
+			PolicyPath: s.config.Auth.CasbinPolicyPath,
+		}, s.logger)
+	} else {
+		casbinService, err = authz.NewCasbinServiceWithDefaults(s.logger)
+	}
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to initialize Casbin, using permissive mode")
 	}
+	if casbinService != nil && accessService != nil {
+		casbinService.OnReload(accessService.InvalidateCache)
+	}
 
 	// Initialize OAuth service (if enabled)
 	oauthService := oauth.NewService(s.config.Auth.OAuth, s.logger)
@@ -90,27 +177,47 @@ func (s *Server) SetupRoutes() {
 	}
 
 	// Initialize handlers
-	registryHandler := handler.NewRegistryHandler(registryService, accessService, s.logger)
-	gatewayHandler := handler.NewGatewayHandler(gatewayService, accessService, s.logger)
+	registryHandler := handler.NewRegistryHandler(registryService, accessService, apiLog)
+	registryHandler.SetNamespaceRepository(namespaceRepo)
+	gatewayHandler := handler.NewGatewayHandlerWithConfig(gatewayService, accessService, gatewayLog, s.config.Gateway)
 	authHandler := handler.NewAuthHandler(userRepo, s.logger)
 	oauthHandler := handler.NewOAuthHandler(oauthService, userRepo, s.logger, frontendURL)
-	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyRepo, s.logger)
-	namespaceHandler := handler.NewNamespaceHandler(namespaceRepo, s.logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyRepo, apiLog)
+	namespaceHandler := handler.NewNamespaceHandlerWithTools(namespaceRepo, gatewayService, accessService, apiLog)
+	auditHandler := handler.NewAuditHandler(auditService, apiLog)
 	oauthMetadataHandler := handler.NewOAuthMetadataHandler(s.config.Auth.OAuth, s.config.Auth.MCPAuth, s.logger)
 
 	// Create OAuth service adapter for bearer token validation
 	var oauthValidator middleware.OAuthValidator
 	if oauthService.IsEnabled() {
 		oauthValidator = middleware.NewOAuthServiceAdapter(oauthService)
+		if len(s.config.Auth.OAuth.Providers) > 0 {
+			oauthValidator = s.newMultiOAuthValidator(oauthValidator)
+		}
+		if cache := s.config.Auth.OAuth.TokenCache; cache.Enabled {
+			caching := middleware.NewCachingOAuthValidator(oauthValidator, cache.TTL, cache.MaxEntries)
+			caching.SetMetrics(s.metrics)
+			oauthValidator = caching
+		}
+	}
+
+	// Server-side session tracking (if enabled), so sessions can be revoked
+	var userSessions middleware.SessionStore
+	if s.config.Auth.SessionStore == "memory" {
+		userSessions = middleware.NewMemorySessionStore(s.sessionMaxAge())
+		authHandler.SetSessionStore(userSessions)
+		oauthHandler.SetSessionStore(userSessions)
 	}
 
 	// Auth middleware config
 	authConfig := &middleware.AuthConfig{
-		Logger:         s.logger,
-		UserRepo:       userRepo,
-		APIKeyRepo:     apiKeyRepo,
-		OAuthValidator: oauthValidator,
-		SessionName:    "mcp_session",
+		Logger:                  s.logger,
+		UserRepo:                userRepo,
+		APIKeyRepo:              apiKeyRepo,
+		OAuthValidator:          oauthValidator,
+		SessionName:             "mcp_session",
+		APIKeyInactivityTimeout: s.config.Auth.APIKeyInactivityTimeout,
+		SessionStore:            userSessions,
 		MCPAuth: middleware.MCPAuthConfig{
 			APIKeyEnabled:  s.config.Auth.MCPAuth.APIKeyEnabled,
 			SessionEnabled: s.config.Auth.MCPAuth.SessionEnabled,
@@ -127,7 +234,7 @@ func (s *Server) SetupRoutes() {
 	}
 
 	// Scope middleware for API key restriction enforcement
-	scopeMiddleware := middleware.NewScopeMiddleware()
+	scopeMiddleware := middleware.NewScopeMiddlewareWithNamespaces(apiLog, namespaceRepo)
 
 	// Check if authentication is enabled
 	authEnabled := s.config.Auth.Enabled
@@ -158,6 +265,9 @@ func (s *Server) SetupRoutes() {
 			auth.GET("/sso/callback", oauthHandler.Callback)
 		}
 
+		// Version endpoint (public) for client compatibility checks
+		v1.GET("/version", handler.NewVersionHandler(s.buildInfo).GetVersion)
+
 		// Status endpoint (public) - includes auth config for frontend
 		v1.GET("/status", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
@@ -179,6 +289,24 @@ func (s *Server) SetupRoutes() {
 		if authEnabled {
 			protected.Use(middleware.CombinedAuth(authConfig))
 		}
+		if s.config.RateLimit.Enabled {
+			protected.Use(middleware.RateLimit(&middleware.RateLimitConfig{
+				Default: middleware.NewMemoryRateLimiter(middleware.Rate{
+					RequestsPerSecond: s.config.RateLimit.RequestsPerSecond,
+					Burst:             s.config.RateLimit.Burst,
+				}),
+				ToolCalls: middleware.NewMemoryRateLimiter(middleware.Rate{
+					RequestsPerSecond: s.config.RateLimit.ToolCallRequestsPerSecond,
+					Burst:             s.config.RateLimit.ToolCallBurst,
+				}),
+				RetryAfterJitter: s.config.RateLimit.RetryAfterJitter,
+				Logger:           s.logger,
+			}))
+		}
+		// Requests denied by role, scope or namespace checks are audited on every route
+		if s.config.Audit.AccessDenied {
+			protected.Use(middleware.AuditAccessDenied(auditService))
+		}
 		{
 			// Current user info
 			protected.GET("/me", authHandler.GetCurrentUser)
@@ -191,6 +319,7 @@ func (s *Server) SetupRoutes() {
 				apiKeys.POST("", apiKeyHandler.CreateAPIKey)
 				apiKeys.GET("/:id", apiKeyHandler.GetAPIKey)
 				apiKeys.DELETE("/:id", apiKeyHandler.DeleteAPIKey)
+				apiKeys.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
 			}
 
 			// MCP Server Registry routes
@@ -205,19 +334,35 @@ func (s *Server) SetupRoutes() {
 			{
 				servers.GET("", scopeMiddleware.RequireScope("servers:read"), registryHandler.ListServers)
 				servers.POST("", scopeMiddleware.RequireScope("servers:write"), registryHandler.CreateServer)
+				servers.POST("/import", scopeMiddleware.RequireScope("servers:write"), registryHandler.ImportServers)
+				servers.GET("/export", scopeMiddleware.RequireScope("servers:read"), registryHandler.ExportServers)
 				servers.POST("/test-connection", scopeMiddleware.RequireScope("servers:write"), registryHandler.TestConnection) // Test connection without saving
 				servers.POST("/call-tool", scopeMiddleware.RequireScope("gateway:execute"), registryHandler.CallTool)           // Call tool for inspection
+				testCall := []gin.HandlerFunc{scopeMiddleware.RequireScope("servers:write")}
+				if authEnabled && authzConfig != nil {
+					testCall = append(testCall, middleware.RequireRoles(authzConfig, "admin", "operator"))
+				}
+				servers.POST("/test-call", append(testCall, registryHandler.TestCallTool)...) // Call a tool on an unregistered server
 				servers.GET("/:id", scopeMiddleware.RequireScope("servers:read"), registryHandler.GetServer)
 				servers.PUT("/:id", scopeMiddleware.RequireScope("servers:write"), registryHandler.UpdateServer)
 				servers.DELETE("/:id", scopeMiddleware.RequireScope("servers:write"), registryHandler.DeleteServer)
 				servers.PATCH("/:id/toggle", scopeMiddleware.RequireScope("servers:write"), registryHandler.ToggleServer)
 				servers.GET("/:id/health", scopeMiddleware.RequireScope("servers:read"), registryHandler.GetHealthStatus)
 				servers.POST("/:id/health", scopeMiddleware.RequireScope("servers:read"), registryHandler.CheckHealth)
+				servers.GET("/:id/health/events", scopeMiddleware.RequireScope("servers:read"), registryHandler.GetHealthEvents)
+				servers.POST("/:id/test-auth", scopeMiddleware.RequireScope("servers:write"), registryHandler.TestAuth)
 			}
 
 			// MCP Gateway Proxy routes (with audit middleware)
 			gatewayGroup := protected.Group("/gateway")
-			gatewayGroup.Use(middleware.AuditMiddleware(auditService))
+			gatewayGroup.Use(s.inflight.middleware())
+			gatewayGroup.Use(middleware.AuditMiddlewareWithOptions(auditService, middleware.AuditOptions{
+				RedactKeys:       s.config.Audit.RedactKeys,
+				MaxArgumentBytes: s.config.Audit.MaxArgumentBytes,
+			}))
+			if loopGuard != nil {
+				gatewayGroup.Use(middleware.GatewayLoop(loopGuard, gatewayLog))
+			}
 			if authEnabled && authzConfig != nil {
 				gatewayGroup.Use(middleware.Authz(authzConfig))
 			}
@@ -226,6 +371,16 @@ func (s *Server) SetupRoutes() {
 			gatewayGroup.Use(scopeMiddleware.CheckReadOnly())
 			gatewayGroup.Use(scopeMiddleware.CheckIPWhitelist())
 			gatewayGroup.Use(scopeMiddleware.RequireServerAccess())
+			if cfg := s.config.Gateway.Priority; cfg.Enabled {
+				gatewayGroup.Use(middleware.Priority(middleware.PriorityConfig{
+					Header:    cfg.Header,
+					MetaKey:   cfg.MetaKey,
+					Levels:    cfg.Levels,
+					Default:   cfg.Default,
+					Roles:     cfg.Roles,
+					ServerIDs: cfg.ServerIDs,
+				}))
+			}
 			{
 				// Native MCP proxy endpoint - allows MCP clients (Claude Code, etc.) to connect directly
 				// This proxies MCP JSON-RPC requests to the backend server
@@ -237,8 +392,27 @@ func (s *Server) SetupRoutes() {
 				gatewayGroup.POST("/:server_id/tools/call", gatewayHandler.CallTool)
 				gatewayGroup.GET("/:server_id/resources/list", gatewayHandler.ListResources)
 				gatewayGroup.GET("/:server_id/resources/read", gatewayHandler.ReadResource)
+				gatewayGroup.GET("/:server_id/resources/subscribe", gatewayHandler.SubscribeResource)
+				gatewayGroup.GET("/:server_id/elicitation/events", gatewayHandler.ElicitationEvents)
+				gatewayGroup.POST("/:server_id/elicitation/respond", gatewayHandler.RespondElicitation)
 				gatewayGroup.POST("/:server_id/prompts/list", gatewayHandler.ListPrompts)
 				gatewayGroup.POST("/:server_id/prompts/get", gatewayHandler.GetPrompt)
+
+				// Legacy SSE transport for clients that don't speak Streamable HTTP
+				if s.config.Gateway.LegacySSE.Enabled {
+					gatewayGroup.GET("/:server_id/sse", gatewayHandler.LegacySSE)
+					gatewayGroup.POST("/:server_id/sse/message", gatewayHandler.LegacySSEMessage)
+				}
+			}
+
+			// Audit log queries (admin only)
+			auditLogs := protected.Group("/audit-logs")
+			if authEnabled && authzConfig != nil {
+				auditLogs.Use(middleware.RequirePermission(authzConfig, "/api/v1/audit-logs", "GET"))
+			}
+			auditLogs.Use(scopeMiddleware.CheckIPWhitelist())
+			{
+				auditLogs.GET("", scopeMiddleware.RequireScope("audit:read"), auditHandler.ListAuditLogs)
 			}
 
 			// Namespaces routes (admin and operator can view, admin only can modify)
@@ -259,6 +433,7 @@ func (s *Server) SetupRoutes() {
 
 				// Server membership management
 				namespaces.GET("/:id/servers", scopeMiddleware.RequireScope("namespaces:read"), namespaceHandler.ListServers)
+				namespaces.GET("/:id/tools", scopeMiddleware.RequireScope("namespaces:read"), namespaceHandler.ListTools)
 				namespaces.POST("/:id/servers", scopeMiddleware.RequireScope("namespaces:write"), namespaceHandler.AddServer)
 				namespaces.DELETE("/:id/servers/:server_id", scopeMiddleware.RequireScope("namespaces:write"), namespaceHandler.RemoveServer)
 
@@ -278,13 +453,17 @@ func (s *Server) SetupRoutes() {
 			adminGroup.Use(scopeMiddleware.CheckIPWhitelist())
 			{
 				// Initialize admin services
-				userService := user.NewService(userRepo, s.logger)
-				roleService := role.NewService(s.db.Pool, s.logger)
+				userService := user.NewService(userRepo, apiLog)
+				roleService := role.NewService(s.db.Pool, apiLog)
 
 				// Initialize admin handlers
-				usersHandler := admin.NewUsersHandler(userService, s.logger)
-				sessionsHandler := admin.NewSessionsHandler(s.logger)
-				rolesHandler := admin.NewRolesHandler(roleService, s.logger)
+				usersHandler := admin.NewUsersHandler(userService, apiLog)
+				sessionsHandler := admin.NewSessionsHandler(apiLog)
+				if userSessions != nil {
+					usersHandler.SetSessionStore(userSessions)
+					sessionsHandler = admin.NewSessionsHandlerWithStore(userSessions, apiLog)
+				}
+				rolesHandler := admin.NewRolesHandler(roleService, apiLog)
 
 				// User management
 				users := adminGroup.Group("/users")
@@ -316,6 +495,30 @@ func (s *Server) SetupRoutes() {
 				// Permissions (read-only)
 				adminGroup.GET("/permissions", scopeMiddleware.RequireScope("roles:read"), rolesHandler.ListPermissions)
 
+				// RBAC policy reload from the configured policy files
+				if casbinService != nil {
+					rbacHandler := admin.NewRBACHandler(casbinService, apiLog)
+					adminGroup.POST("/rbac/reload", scopeMiddleware.RequireScope("roles:write"), rbacHandler.ReloadPolicy)
+				}
+
+				// Database schema migrations
+				migrationsHandler := admin.NewMigrationsHandler(migrator, apiLog)
+				adminGroup.GET("/migrations", scopeMiddleware.RequireScope("roles:read"), migrationsHandler.GetStatus)
+				adminGroup.POST("/migrations/up", scopeMiddleware.RequireScope("roles:write"), migrationsHandler.MigrateUp)
+
+				// Proxied requests that failed after retries
+				if deadLetters := gatewayService.DeadLetters(); deadLetters != nil {
+					deadLettersHandler := admin.NewDeadLettersHandler(deadLetters, apiLog)
+					adminGroup.GET("/dead-letters", scopeMiddleware.RequireScope("audit:read"), deadLettersHandler.List)
+				}
+
+				// Recent requests and responses per server, for live debugging
+				if debugLog := gatewayService.DebugLog(); debugLog != nil {
+					debugLogHandler := admin.NewDebugLogHandler(debugLog, apiLog)
+					adminGroup.GET("/debug-log/:server_id", scopeMiddleware.RequireScope("audit:read"), debugLogHandler.List)
+					adminGroup.DELETE("/debug-log/:server_id", scopeMiddleware.RequireScope("servers:write"), debugLogHandler.Clear)
+				}
+
 				// API Key management (admin can view/delete all keys)
 				apiKeysAdmin := adminGroup.Group("/api-keys")
 				{
@@ -353,10 +556,7 @@ func (s *Server) setupSessionStore() sessions.Store {
 	store := cookie.NewStore([]byte(secret))
 
 	// Configure session options
-	maxAge := int(s.config.Auth.SessionMaxAge.Seconds())
-	if maxAge == 0 {
-		maxAge = 86400 // Default: 24 hours
-	}
+	maxAge := int(s.sessionMaxAge().Seconds())
 
 	// Determine secure flag based on environment
 	secure := s.config.Auth.CookieSecure
@@ -391,6 +591,14 @@ func (s *Server) setupSessionStore() sessions.Store {
 	return store
 }
 
+// sessionMaxAge returns how long browser sessions last
+func (s *Server) sessionMaxAge() time.Duration {
+	if s.config.Auth.SessionMaxAge <= 0 {
+		return 24 * time.Hour // Default: 24 hours
+	}
+	return s.config.Auth.SessionMaxAge
+}
+
 // corsWithCredentials returns CORS middleware configured for cookie-based auth
 func (s *Server) corsWithCredentials() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -519,3 +727,91 @@ func (s *Server) setupNoRouteHandler(staticDir string) {
 		c.File(indexPath)
 	})
 }
+
+// newMultiOAuthValidator combines the primary OAuth provider with the additional ones
+// configured in auth.oauth.providers. Providers whose discovery fails are left out.
+func (s *Server) newMultiOAuthValidator(primary middleware.OAuthValidator) middleware.OAuthValidator {
+	providers := []middleware.OAuthProvider{{Name: oauth.ProviderOIDC, Validator: primary}}
+	for _, provider := range s.config.Auth.OAuth.Providers {
+		cfg := s.config.Auth.OAuth
+		cfg.Issuer = provider.Issuer
+		cfg.ClientID = provider.ClientID
+		cfg.ClientSecret = provider.ClientSecret
+		cfg.AllowedDomains = provider.AllowedDomains
+		cfg.Providers = nil
+
+		service := oauth.NewService(cfg, s.logger)
+		if !service.IsEnabled() {
+			s.logger.Warn().Str("provider", provider.Name).Str("issuer", provider.Issuer).Msg("OAuth provider unavailable, ignoring its tokens")
+			continue
+		}
+		providers = append(providers, middleware.OAuthProvider{Name: provider.Name, Validator: middleware.NewOAuthServiceAdapter(service)})
+	}
+	return middleware.NewMultiOAuthValidator(providers...)
+}
+
+// newEgressPolicy returns the proxy, host allowlist and address guard for upstream
+// requests, or nil when none is configured
+func (s *Server) newEgressPolicy(guard *gateway.AddressGuard) *gateway.EgressPolicy {
+	cfg := s.config.Gateway.Egress
+	if cfg.ProxyURL == "" && len(cfg.AllowedHosts) == 0 && guard == nil {
+		return nil
+	}
+
+	policy, err := gateway.NewEgressPolicy(cfg.ProxyURL, cfg.AllowedHosts)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to set up gateway egress policy, upstream requests are unrestricted")
+		return nil
+	}
+	if guard != nil {
+		policy.SetAddressGuard(guard)
+	}
+	s.logger.Info().
+		Bool("proxy", cfg.ProxyURL != ""). // The URL may carry credentials
+		Any("allowed_hosts", cfg.AllowedHosts).
+		Bool("block_private_addresses", guard != nil).
+		Msg("Gateway egress policy enabled")
+	return policy
+}
+
+// newAddressGuard returns the guard keeping server URLs and upstream connections away from
+// internal addresses, or nil when gateway.egress.block_private_addresses is disabled
+func (s *Server) newAddressGuard() *gateway.AddressGuard {
+	cfg := s.config.Gateway.Egress
+	if !cfg.BlockPrivateAddresses {
+		return nil
+	}
+
+	guard, err := gateway.NewAddressGuard(cfg.AllowedCIDRs)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to set up gateway address guard, internal addresses are reachable")
+		return nil
+	}
+	return guard
+}
+
+// newLoopGuard identifies this gateway for loop detection, or returns nil when
+// gateway.loop_detection is disabled
+func (s *Server) newLoopGuard() *gateway.LoopGuard {
+	cfg := s.config.Gateway.LoopDetection
+	if !cfg.Enabled {
+		return nil
+	}
+
+	id := cfg.GatewayID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	selfURLs := gateway.ListenURLs(s.config.Server.Host, s.config.Server.Port)
+	if s.config.Auth.OAuth.BaseURL != "" {
+		selfURLs = append(selfURLs, s.config.Auth.OAuth.BaseURL)
+	}
+	selfURLs = append(selfURLs, cfg.SelfURLs...)
+
+	guard, err := gateway.NewLoopGuard(id, selfURLs...)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to set up gateway loop detection, disabling it")
+		return nil
+	}
+	return guard
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/database"
+	"github.com/waffles/waffles/internal/handler"
 	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/internal/service/registry"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -22,6 +24,9 @@ type Server struct {
 	logger        logger.Logger
 	metrics       *metrics.Registry
 	metricsServer *metrics.Server
+	inflight      *inflightRequests // Gateway requests to drain on shutdown
+	buildInfo     handler.BuildInfo
+	registry      *registry.Service // Set up by SetupRoutes
 }
 
 // New creates a new HTTP server instance
@@ -53,9 +58,22 @@ func New(cfg *config.Config, db *database.DB, log logger.Logger, metricsReg *met
 		logger:        log,
 		metrics:       metricsReg,
 		metricsServer: metricsSrv,
+		inflight:      newInflightRequests(),
 	}
 }
 
+// SetBuildInfo sets the version reported by GET /api/v1/version. Must be called before
+// SetupRoutes.
+func (s *Server) SetBuildInfo(info handler.BuildInfo) {
+	s.buildInfo = info
+}
+
+// RegistryService returns the registry service SetupRoutes configured, with the same
+// guards, egress policy and secret store as the API, or nil before SetupRoutes
+func (s *Server) RegistryService() *registry.Service {
+	return s.registry
+}
+
 // Router returns the Gin router for route registration
 func (s *Server) Router() *gin.Engine {
 	return s.router
@@ -94,17 +112,34 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 }
 
-// Shutdown gracefully shuts down the server
+// Shutdown gracefully shuts down the server. It stops accepting connections, gives
+// in-flight gateway requests up to the drain timeout to finish, closes event streams and
+// any requests still running, and then waits up to the shutdown timeout for the HTTP
+// server to stop.
 func (s *Server) Shutdown() error {
 	s.logger.Info().Msg("Shutting down HTTP server gracefully...")
 
 	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.DrainTimeout+s.config.Server.ShutdownTimeout)
 	defer cancel()
 
-	// Shutdown HTTP server
-	if err := s.httpServer.Shutdown(ctx); err != nil {
+	// Shutdown HTTP server; this closes the listeners right away and then waits for
+	// active connections, which the drain below lets finish
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- s.httpServer.Shutdown(ctx)
+	}()
+
+	result := s.inflight.drain(s.config.Server.DrainTimeout)
+	s.logger.Info().
+		Int("drained", result.Drained).
+		Int("forced", result.Forced).
+		Int("streams_closed", result.Streams).
+		Msg("In-flight gateway requests drained")
+
+	if err := <-shutdownErr; err != nil {
 		s.logger.Error().Err(err).Msg("HTTP server shutdown error")
+		_ = s.httpServer.Close()
 		return err
 	}
 
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// PruneRepository defines the audit log deletes used by the pruner.
+type PruneRepository interface {
+	// CountOlderThan returns how many audit logs were created before cutoff
+	CountOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	// DeleteOlderThan deletes up to limit of the oldest audit logs created before cutoff
+	// and returns how many it deleted
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// PrunerConfig controls the background audit log pruner
+type PrunerConfig struct {
+	// Retention is how long audit logs are kept
+	Retention time.Duration
+	// Interval is how often the pruner runs
+	Interval time.Duration
+	// BatchSize bounds the rows deleted per statement, so no delete holds locks for long
+	BatchSize int
+	// DryRun only logs how many rows would be deleted
+	DryRun bool
+}
+
+// Pruner deletes audit logs older than the retention period
+type Pruner struct {
+	repo    PruneRepository
+	config  PrunerConfig
+	logger  logger.Logger
+	metrics *metrics.Registry
+	now     func() time.Time
+}
+
+// NewPruner creates a new audit log pruner. metricsReg may be nil.
+func NewPruner(repo PruneRepository, cfg PrunerConfig, log logger.Logger, metricsReg *metrics.Registry) *Pruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	return &Pruner{
+		repo:    repo,
+		config:  cfg,
+		logger:  log,
+		metrics: metricsReg,
+		now:     time.Now,
+	}
+}
+
+// Run prunes on every interval until ctx is cancelled
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	// Prune immediately on startup
+	p.prune(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			p.prune(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// prune runs PruneOnce and logs a failure
+func (p *Pruner) prune(ctx context.Context) {
+	if _, err := p.PruneOnce(ctx); err != nil && ctx.Err() == nil {
+		p.logger.Warn().Err(err).Msg("Failed to prune audit logs")
+	}
+}
+
+// PruneOnce deletes every audit log older than the retention period, one batch at a
+// time, and returns how many rows were deleted. In dry-run mode nothing is deleted and
+// the count is of the rows that would have been.
+func (p *Pruner) PruneOnce(ctx context.Context) (int64, error) {
+	cutoff := p.now().Add(-p.config.Retention)
+
+	if p.config.DryRun {
+		count, err := p.repo.CountOlderThan(ctx, cutoff)
+		if err != nil {
+			return 0, err
+		}
+		p.logger.Info().
+			Any("rows", count).
+			Str("cutoff", cutoff.Format(time.RFC3339)).
+			Msg("Audit log pruning dry run: rows that would be deleted")
+		return count, nil
+	}
+
+	var total int64
+	for {
+		deleted, err := p.repo.DeleteOlderThan(ctx, cutoff, p.config.BatchSize)
+		total += deleted
+		if p.metrics != nil && deleted > 0 {
+			p.metrics.AuditLogsPrunedTotal.Add(float64(deleted))
+		}
+		if err != nil {
+			return total, err
+		}
+		// A short batch means nothing older is left
+		if deleted < int64(p.config.BatchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		p.logger.Info().
+			Any("rows", total).
+			Str("cutoff", cutoff.Format(time.RFC3339)).
+			Msg("Pruned audit logs")
+	}
+	return total, nil
+}
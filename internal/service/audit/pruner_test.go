@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// mockPruneRepository deletes from a fixed number of old rows and records its calls
+type mockPruneRepository struct {
+	mu        sync.Mutex
+	oldRows   int64
+	deleteErr error
+	cutoffs   []time.Time
+	limits    []int
+	counts    int
+}
+
+func (m *mockPruneRepository) CountOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts++
+	m.cutoffs = append(m.cutoffs, cutoff)
+	return m.oldRows, nil
+}
+
+func (m *mockPruneRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cutoffs = append(m.cutoffs, cutoff)
+	m.limits = append(m.limits, limit)
+	if m.deleteErr != nil {
+		return 0, m.deleteErr
+	}
+	deleted := min(m.oldRows, int64(limit))
+	m.oldRows -= deleted
+	return deleted, nil
+}
+
+func (m *mockPruneRepository) remaining() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.oldRows
+}
+
+func newTestPruner(repo PruneRepository, cfg PrunerConfig, reg *metrics.Registry) *Pruner {
+	p := NewPruner(repo, cfg, logger.NewNopLogger(), reg)
+	p.now = func() time.Time { return time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC) }
+	return p
+}
+
+func TestPruner_PruneOnce(t *testing.T) {
+	t.Run("deletes old rows in batches", func(t *testing.T) {
+		repo := &mockPruneRepository{oldRows: 250}
+		reg := metrics.NewRegistry()
+		p := newTestPruner(repo, PrunerConfig{Retention: 24 * time.Hour, BatchSize: 100}, reg)
+
+		deleted, err := p.PruneOnce(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(250), deleted)
+		assert.Equal(t, []int{100, 100, 100}, repo.limits)
+		assert.Equal(t, int64(0), repo.oldRows)
+		assert.Equal(t, 250.0, testutil.ToFloat64(reg.AuditLogsPrunedTotal))
+	})
+
+	t.Run("cutoff is the retention period before now", func(t *testing.T) {
+		repo := &mockPruneRepository{}
+		p := newTestPruner(repo, PrunerConfig{Retention: 24 * time.Hour}, nil)
+
+		_, err := p.PruneOnce(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, repo.cutoffs, 1)
+		assert.Equal(t, time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC), repo.cutoffs[0])
+		assert.Equal(t, []int{1000}, repo.limits, "batch size defaults to 1000")
+	})
+
+	t.Run("dry run counts without deleting", func(t *testing.T) {
+		repo := &mockPruneRepository{oldRows: 42}
+		reg := metrics.NewRegistry()
+		p := newTestPruner(repo, PrunerConfig{Retention: time.Hour, BatchSize: 10, DryRun: true}, reg)
+
+		count, err := p.PruneOnce(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(42), count)
+		assert.Equal(t, 1, repo.counts)
+		assert.Empty(t, repo.limits)
+		assert.Equal(t, int64(42), repo.oldRows)
+		assert.Equal(t, 0.0, testutil.ToFloat64(reg.AuditLogsPrunedTotal))
+	})
+
+	t.Run("returns delete errors", func(t *testing.T) {
+		repo := &mockPruneRepository{oldRows: 10, deleteErr: errors.New("database error")}
+		p := newTestPruner(repo, PrunerConfig{Retention: time.Hour}, nil)
+
+		_, err := p.PruneOnce(context.Background())
+		assert.EqualError(t, err, "database error")
+	})
+}
+
+func TestPruner_RunPrunesOnStartup(t *testing.T) {
+	repo := &mockPruneRepository{oldRows: 5}
+	p := newTestPruner(repo, PrunerConfig{Retention: time.Hour, Interval: time.Hour}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return repo.remaining() == 0 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -2,6 +2,10 @@ package audit
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/pkg/logger"
@@ -90,3 +94,69 @@ func (s *Service) List(ctx context.Context, filter domain.AuditLogFilter) ([]*do
 
 	return logs, nil
 }
+
+// MaxPageSize is the most audit logs returned by one Query
+const MaxPageSize = 500
+
+// ErrInvalidCursor is returned for a page cursor that wasn't produced by Query
+var ErrInvalidCursor = errors.New("invalid audit log cursor")
+
+// Query retrieves one page of audit logs, newest first, and the cursor of the next page.
+// The cursor is empty on the last page. Limit defaults to 100 and is capped at MaxPageSize.
+func (s *Service) Query(ctx context.Context, filter domain.AuditLogFilter, cursor string) ([]*domain.AuditLog, string, error) {
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = after
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+
+	// Fetch one extra entry to learn whether another page follows
+	pageSize := filter.Limit
+	filter.Limit++
+	filter.Offset = 0
+	logs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Failed to query audit logs")
+		return nil, "", err
+	}
+
+	next := ""
+	if len(logs) > pageSize {
+		logs = logs[:pageSize]
+		next = EncodeCursor(logs[pageSize-1])
+	}
+	return logs, next, nil
+}
+
+// EncodeCursor returns an opaque cursor for the page following log
+func EncodeCursor(log *domain.AuditLog) string {
+	raw := log.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + log.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(cursor string) (*domain.AuditLogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &domain.AuditLogCursor{CreatedAt: t, ID: id}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -305,6 +306,66 @@ func TestService_List(t *testing.T) {
 	})
 }
 
+func TestService_Query(t *testing.T) {
+	log := logger.NewNopLogger()
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 123456000, time.UTC)
+
+	t.Run("returns a cursor when another page follows", func(t *testing.T) {
+		var capturedFilter domain.AuditLogFilter
+		mockRepo := &mockAuditRepository{
+			listFunc: func(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLog, error) {
+				capturedFilter = filter
+				return []*domain.AuditLog{
+					{ID: "log-1", CreatedAt: createdAt},
+					{ID: "log-2", CreatedAt: createdAt.Add(-time.Second)},
+					{ID: "log-3", CreatedAt: createdAt.Add(-2 * time.Second)},
+				}, nil
+			},
+		}
+		svc := NewService(mockRepo, log)
+
+		logs, next, err := svc.Query(context.Background(), domain.AuditLogFilter{Limit: 2}, "")
+		require.NoError(t, err)
+		assert.Len(t, logs, 2)
+		assert.Equal(t, 3, capturedFilter.Limit, "one extra entry is fetched to detect the next page")
+
+		cursor, err := DecodeCursor(next)
+		require.NoError(t, err)
+		assert.Equal(t, "log-2", cursor.ID)
+		assert.True(t, createdAt.Add(-time.Second).Equal(cursor.CreatedAt))
+
+		_, _, err = svc.Query(context.Background(), domain.AuditLogFilter{Limit: 2}, next)
+		require.NoError(t, err)
+		assert.Equal(t, cursor, capturedFilter.After)
+	})
+
+	t.Run("last page has no cursor and the limit is capped", func(t *testing.T) {
+		var capturedFilter domain.AuditLogFilter
+		mockRepo := &mockAuditRepository{
+			listFunc: func(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLog, error) {
+				capturedFilter = filter
+				return []*domain.AuditLog{{ID: "log-1", CreatedAt: createdAt}}, nil
+			},
+		}
+		svc := NewService(mockRepo, log)
+
+		logs, next, err := svc.Query(context.Background(), domain.AuditLogFilter{Limit: 10000}, "")
+		require.NoError(t, err)
+		assert.Len(t, logs, 1)
+		assert.Empty(t, next)
+		assert.Equal(t, MaxPageSize+1, capturedFilter.Limit)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		svc := NewService(&mockAuditRepository{}, log)
+
+		for _, cursor := range []string{"%%%", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fGxvZy0x"} {
+			_, _, err := svc.Query(context.Background(), domain.AuditLogFilter{}, cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+		}
+	})
+}
+
 func TestNewService(t *testing.T) {
 	t.Run("creates service with nil repository", func(t *testing.T) {
 		log := logger.NewNopLogger()
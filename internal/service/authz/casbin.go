@@ -1,8 +1,10 @@
 package authz
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
@@ -11,10 +13,20 @@ import (
 	"github.com/waffles/waffles/pkg/logger"
 )
 
+// ErrNoPolicySource is returned when reloading policies that were not loaded from a file
+var ErrNoPolicySource = errors.New("policies are not loaded from a file")
+
+// ErrReloadInProgress is returned when a policy reload is requested while one is running
+var ErrReloadInProgress = errors.New("policy reload already in progress")
+
 // CasbinService wraps the Casbin enforcer with additional functionality
 type CasbinService struct {
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer // Safe to enforce while policies reload
 	logger   logger.Logger
+
+	reloadMu   sync.Mutex // Held for the duration of a reload; guards policyPath
+	policyPath string     // Policy file the enforcer loads from (empty = embedded defaults)
+	onReload   []func()   // Called after policies are reloaded; guarded by reloadMu
 }
 
 // Config contains configuration for the Casbin service
@@ -26,7 +38,7 @@ type Config struct {
 // NewCasbinService creates a new Casbin service with file-based policies
 func NewCasbinService(cfg Config, log logger.Logger) (*CasbinService, error) {
 	// Load the model from file
-	enforcer, err := casbin.NewEnforcer(cfg.ModelPath, cfg.PolicyPath)
+	enforcer, err := casbin.NewSyncedEnforcer(cfg.ModelPath, cfg.PolicyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Casbin enforcer: %w", err)
 	}
@@ -37,8 +49,9 @@ func NewCasbinService(cfg Config, log logger.Logger) (*CasbinService, error) {
 		Msg("Casbin enforcer initialized")
 
 	return &CasbinService{
-		enforcer: enforcer,
-		logger:   log,
+		enforcer:   enforcer,
+		logger:     log,
+		policyPath: cfg.PolicyPath,
 	}, nil
 }
 
@@ -68,7 +81,7 @@ m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == "*
 		return nil, fmt.Errorf("failed to create Casbin model: %w", err)
 	}
 
-	enforcer, err := casbin.NewEnforcer(m)
+	enforcer, err := casbin.NewSyncedEnforcer(m)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Casbin enforcer: %w", err)
 	}
@@ -99,6 +112,7 @@ m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == "*
 		{"user", "/api/v1/api-keys", "GET"},
 		{"user", "/api/v1/api-keys", "POST"},
 		{"user", "/api/v1/api-keys/*", "DELETE"},
+		{"user", "/api/v1/api-keys/*/rotate", "POST"},
 	}
 
 	for _, p := range policies {
@@ -131,7 +145,7 @@ m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == "*
 }
 
 // GetEnforcer returns the underlying Casbin enforcer
-func (s *CasbinService) GetEnforcer() *casbin.Enforcer {
+func (s *CasbinService) GetEnforcer() *casbin.SyncedEnforcer {
 	return s.enforcer
 }
 
@@ -170,9 +184,52 @@ func (s *CasbinService) HasRoleForUser(user, role string) (bool, error) {
 	return s.enforcer.HasRoleForUser(user, role)
 }
 
-// ReloadPolicy reloads the policy from file
+// ReloadPolicy reloads the policy from file, replacing policies added at runtime.
+// Requests are enforced against the old policy until the new one is in place. Returns
+// ErrNoPolicySource for embedded default policies and ErrReloadInProgress when another
+// reload is running.
 func (s *CasbinService) ReloadPolicy() error {
-	return s.enforcer.LoadPolicy()
+	if !s.reloadMu.TryLock() {
+		return ErrReloadInProgress
+	}
+	defer s.reloadMu.Unlock()
+
+	if s.policyPath == "" {
+		return ErrNoPolicySource
+	}
+
+	if err := s.enforcer.LoadPolicy(); err != nil {
+		s.logger.Error().Err(err).Str("policy_path", s.policyPath).Msg("Failed to reload Casbin policy")
+		return fmt.Errorf("failed to reload policy: %w", err)
+	}
+
+	s.logger.Info().Str("policy_path", s.policyPath).Msg("Casbin policy reloaded")
+	s.notifyReload()
+	return nil
+}
+
+// OnReload registers fn to be called after each successful policy reload, e.g. to drop
+// cached authorization decisions
+func (s *CasbinService) OnReload(fn func()) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.onReload = append(s.onReload, fn)
+}
+
+// notifyReload calls the reload callbacks. Must be called with reloadMu held.
+func (s *CasbinService) notifyReload() {
+	for _, fn := range s.onReload {
+		fn()
+	}
+}
+
+// PolicyCount returns the number of policy rules currently enforced
+func (s *CasbinService) PolicyCount() (int, error) {
+	policies, err := s.enforcer.GetPolicy()
+	if err != nil {
+		return 0, err
+	}
+	return len(policies), nil
 }
 
 // SavePolicy saves the current policy to file
@@ -182,7 +239,15 @@ func (s *CasbinService) SavePolicy() error {
 
 // LoadPolicyFromFile loads policies from a CSV file
 func (s *CasbinService) LoadPolicyFromFile(path string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
 	adapter := fileadapter.NewAdapter(path)
 	s.enforcer.SetAdapter(adapter)
-	return s.enforcer.LoadPolicy()
+	s.policyPath = path
+	if err := s.enforcer.LoadPolicy(); err != nil {
+		return err
+	}
+	s.notifyReload()
+	return nil
 }
@@ -1,6 +1,8 @@
 package authz
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/casbin/casbin/v2"
@@ -165,6 +167,13 @@ func TestCasbinService_Enforce(t *testing.T) {
 			act:      "DELETE",
 			expected: true,
 		},
+		{
+			name:     "user can rotate own api keys",
+			sub:      "user",
+			obj:      "/api/v1/api-keys/key-123/rotate",
+			act:      "POST",
+			expected: true,
+		},
 		{
 			name:     "user cannot access servers",
 			sub:      "user",
@@ -348,6 +357,131 @@ func TestNewCasbinService(t *testing.T) {
 	})
 }
 
+// newFileCasbinService creates a service backed by policy files in a temp directory and
+// returns the policy file path
+func newFileCasbinService(t *testing.T, policy string) (*CasbinService, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "model.conf")
+	policyPath := filepath.Join(dir, "policy.csv")
+	require.NoError(t, os.WriteFile(modelPath, []byte(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == "*")
+`), 0o600))
+	require.NoError(t, os.WriteFile(policyPath, []byte(policy), 0o600))
+
+	svc, err := NewCasbinService(Config{ModelPath: modelPath, PolicyPath: policyPath}, logger.NewNopLogger())
+	require.NoError(t, err)
+	return svc, policyPath
+}
+
+func TestCasbinService_ReloadPolicy(t *testing.T) {
+	t.Run("policy added to the file takes effect after reload", func(t *testing.T) {
+		svc, policyPath := newFileCasbinService(t, "p, viewer, /api/v1/servers, GET\n")
+
+		allowed, err := svc.Enforce("viewer", "/api/v1/audit", "GET")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		f, err := os.OpenFile(policyPath, os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString("p, viewer, /api/v1/audit, GET\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		// Not applied until reloaded
+		allowed, err = svc.Enforce("viewer", "/api/v1/audit", "GET")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		require.NoError(t, svc.ReloadPolicy())
+
+		allowed, err = svc.Enforce("viewer", "/api/v1/audit", "GET")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, err = svc.Enforce("viewer", "/api/v1/servers", "GET")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		count, err := svc.PolicyCount()
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("policy removed from the file is revoked after reload", func(t *testing.T) {
+		svc, policyPath := newFileCasbinService(t, "p, viewer, /api/v1/servers, GET\np, viewer, /api/v1/audit, GET\n")
+
+		require.NoError(t, os.WriteFile(policyPath, []byte("p, viewer, /api/v1/servers, GET\n"), 0o600))
+		require.NoError(t, svc.ReloadPolicy())
+
+		allowed, err := svc.Enforce("viewer", "/api/v1/audit", "GET")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("rejects a reload while another is running", func(t *testing.T) {
+		svc, _ := newFileCasbinService(t, "p, viewer, /api/v1/servers, GET\n")
+
+		svc.reloadMu.Lock()
+		err := svc.ReloadPolicy()
+		svc.reloadMu.Unlock()
+
+		assert.ErrorIs(t, err, ErrReloadInProgress)
+	})
+
+	t.Run("enforces during concurrent reloads", func(t *testing.T) {
+		svc, _ := newFileCasbinService(t, "p, viewer, /api/v1/servers, GET\n")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				_ = svc.ReloadPolicy()
+			}
+		}()
+		for i := 0; i < 200; i++ {
+			allowed, err := svc.Enforce("viewer", "/api/v1/servers", "GET")
+			require.NoError(t, err)
+			assert.True(t, allowed)
+		}
+		<-done
+	})
+
+	t.Run("runs reload callbacks after a reload", func(t *testing.T) {
+		svc, _ := newFileCasbinService(t, "p, viewer, /api/v1/servers, GET\n")
+		reloads := 0
+		svc.OnReload(func() { reloads++ })
+
+		require.NoError(t, svc.ReloadPolicy())
+		assert.Equal(t, 1, reloads)
+
+		svc.reloadMu.Lock()
+		_ = svc.ReloadPolicy()
+		svc.reloadMu.Unlock()
+		assert.Equal(t, 1, reloads, "not run when the reload is rejected")
+	})
+
+	t.Run("built-in policies have no source to reload", func(t *testing.T) {
+		svc, err := NewCasbinServiceWithDefaults(logger.NewNopLogger())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, svc.ReloadPolicy(), ErrNoPolicySource)
+	})
+}
+
 func TestConfig(t *testing.T) {
 	cfg := Config{
 		ModelPath:  "/path/to/model.conf",
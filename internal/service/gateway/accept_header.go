@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// DefaultAcceptHeader is the Accept header sent with POSTs to Streamable HTTP servers
+// that don't override it
+const DefaultAcceptHeader = ContentTypeJSON + ", " + ContentTypeEventStream
+
+// AcceptHeader returns the Accept header for POSTs to server: its AcceptHeader when
+// set, otherwise DefaultAcceptHeader
+func AcceptHeader(server *domain.MCPServer) string {
+	if server != nil && server.AcceptHeader != "" {
+		return server.AcceptHeader
+	}
+	return DefaultAcceptHeader
+}
+
+// ValidateAcceptHeader checks that value is a list of media ranges and that at least one
+// of them covers a response type the gateway reads, JSON or an event stream
+func ValidateAcceptHeader(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	readable := false
+	for _, item := range strings.Split(value, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			return fmt.Errorf("%q is not a media range: %w", strings.TrimSpace(item), err)
+		}
+		switch mediaType {
+		case ContentTypeJSON, ContentTypeEventStream, "application/*", "text/*", "*/*":
+			readable = true
+		}
+	}
+	if !readable {
+		return fmt.Errorf("%q accepts neither %s nor %s", value, ContentTypeJSON, ContentTypeEventStream)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// acceptRecorder is a JSON-only Streamable HTTP backend that records the Accept header of
+// each POST by method
+type acceptRecorder struct {
+	mu      sync.Mutex
+	accepts map[string]string
+}
+
+func (b *acceptRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+
+	b.mu.Lock()
+	b.accepts[msg.Method] = r.Header.Get(HeaderAccept)
+	b.mu.Unlock()
+
+	w.Header().Set(HeaderMCPSessionID, "session-1")
+	if isNotification(msg.Method) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set(HeaderContentType, ContentTypeJSON)
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+}
+
+func (b *acceptRecorder) accept(method string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.accepts[method]
+}
+
+func TestStreamableHTTPClient_AcceptHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		want     string
+	}{
+		{name: "default accepts JSON and SSE", want: "application/json, text/event-stream"},
+		{name: "per-server override", override: "application/json", want: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &acceptRecorder{accepts: make(map[string]string)}
+			ts := httptest.NewServer(backend)
+			defer ts.Close()
+
+			server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true, AcceptHeader: tt.override}
+			client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+			_, err := client.Initialize(context.Background(), server)
+			require.NoError(t, err)
+			_, err = client.Call(context.Background(), server, "tools/list", nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, backend.accept("initialize"))
+			assert.Equal(t, tt.want, backend.accept("notifications/initialized"))
+			assert.Equal(t, tt.want, backend.accept("tools/list"))
+		})
+	}
+}
+
+func TestProxyToServer_AcceptHeaderOverride(t *testing.T) {
+	clientHeaders := map[string]string{"Accept": "application/json, text/event-stream"}
+
+	got := proxyHeaders(t, &domain.MCPServer{ID: "server-1", AcceptHeader: "text/event-stream"}, clientHeaders)
+	assert.Equal(t, "text/event-stream", got.Get(HeaderAccept))
+
+	got = proxyHeaders(t, &domain.MCPServer{ID: "server-1"}, clientHeaders)
+	assert.Equal(t, "application/json, text/event-stream", got.Get(HeaderAccept), "the client's Accept is kept without an override")
+}
+
+func TestValidateAcceptHeader(t *testing.T) {
+	for _, value := range []string{"", "application/json", "text/event-stream", "application/json;q=0.9, text/event-stream", "*/*"} {
+		assert.NoError(t, ValidateAcceptHeader(value), value)
+	}
+	for _, value := range []string{"text/html", "application/json,,", "not a media type"} {
+		assert.Error(t, ValidateAcceptHeader(value), value)
+	}
+}
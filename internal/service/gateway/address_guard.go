@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// ErrAddressBlocked is returned for upstream connections to a loopback, link-local, private
+// or other internal address that the address guard's allowlist doesn't permit
+var ErrAddressBlocked = errors.New("upstream address blocked")
+
+// metadataIPs are cloud instance metadata services outside the ranges blocked by type
+var metadataIPs = []net.IP{
+	net.ParseIP("168.63.129.16"), // Azure
+}
+
+// AddressGuard keeps upstream connections away from internal addresses, so a registered
+// server URL can't be used to reach the gateway's own network (SSRF). Host names are
+// resolved and checked when dialing, and the checked address is the one dialed, so a
+// name can't resolve to a public address when checked and an internal one when used.
+type AddressGuard struct {
+	allowed []*net.IPNet
+	dialer  *net.Dialer
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewAddressGuard creates a guard blocking loopback, link-local, private, unspecified and
+// multicast addresses, except those in allowedCIDRs (e.g. "127.0.0.1/32" or "10.1.0.0/16")
+func NewAddressGuard(allowedCIDRs []string) (*AddressGuard, error) {
+	guard := &AddressGuard{
+		dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		lookup: net.DefaultResolver.LookupIPAddr,
+	}
+	for _, cidr := range allowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed CIDR %q: %w", cidr, err)
+		}
+		guard.allowed = append(guard.allowed, network)
+	}
+	return guard, nil
+}
+
+// Allows reports whether upstream connections may go to ip
+func (g *AddressGuard) Allows(ip net.IP) bool {
+	for _, network := range g.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	for _, metadata := range metadataIPs {
+		if ip.Equal(metadata) {
+			return false
+		}
+	}
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// resolve returns the addresses of host, or ErrAddressBlocked when any of them is blocked
+func (g *AddressGuard) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if !g.Allows(ip) {
+			return nil, fmt.Errorf("%w: %s", ErrAddressBlocked, ip)
+		}
+		return []net.IP{ip}, nil
+	}
+
+	addrs, err := g.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if !g.Allows(addr.IP) {
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrAddressBlocked, host, addr.IP)
+		}
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// CheckURL returns ErrAddressBlocked when the host of rawURL is, or resolves to, a blocked
+// address. Host names that don't resolve pass; connecting to them is checked again.
+func (g *AddressGuard) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil // Left to URL validation
+	}
+	_, err = g.resolve(ctx, u.Hostname())
+	if errors.Is(err, ErrAddressBlocked) {
+		return err
+	}
+	return nil
+}
+
+// DialContext connects to addr after checking every address its host resolves to, trying
+// each in turn. It can replace the DialContext of an http.Transport or websocket.Dialer.
+func (g *AddressGuard) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	return nil, dialErr
+}
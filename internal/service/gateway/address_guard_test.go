@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// newStubGuard creates a guard resolving host names from hosts instead of DNS
+func newStubGuard(t *testing.T, allowedCIDRs []string, hosts map[string]string) *AddressGuard {
+	t.Helper()
+	guard, err := NewAddressGuard(allowedCIDRs)
+	require.NoError(t, err)
+	guard.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := hosts[host]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	return guard
+}
+
+func TestAddressGuard_Allows(t *testing.T) {
+	guard, err := NewAddressGuard([]string{"10.1.0.0/16"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"168.63.129.16", false},
+		{"fe80::1", false},
+		{"10.0.0.5", false},
+		{"172.16.3.4", false},
+		{"192.168.1.1", false},
+		{"fd00:ec2::254", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+		{"10.1.2.3", true}, // In the allowlist
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, guard.Allows(net.ParseIP(tt.ip)))
+		})
+	}
+
+	_, err = NewAddressGuard([]string{"10.0.0.1"})
+	assert.Error(t, err)
+}
+
+func TestAddressGuard_CheckURL(t *testing.T) {
+	guard := newStubGuard(t, nil, map[string]string{
+		"mcp.example.com":  "93.184.216.34",
+		"metadata.example": "169.254.169.254",
+	})
+	ctx := context.Background()
+
+	assert.NoError(t, guard.CheckURL(ctx, "https://mcp.example.com/mcp"))
+	assert.NoError(t, guard.CheckURL(ctx, "https://unresolvable.example/mcp"), "checked again when dialing")
+	assert.ErrorIs(t, guard.CheckURL(ctx, "http://169.254.169.254/latest/meta-data"), ErrAddressBlocked)
+	assert.ErrorIs(t, guard.CheckURL(ctx, "ws://metadata.example/ws"), ErrAddressBlocked)
+	assert.ErrorIs(t, guard.CheckURL(ctx, "http://[::1]:9090/mcp"), ErrAddressBlocked)
+}
+
+func TestAddressGuard_DialContext(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(backend.URL, "http://"))
+	require.NoError(t, err)
+
+	get := func(guard *AddressGuard, host string) error {
+		client := &http.Client{Transport: &http.Transport{DialContext: guard.DialContext}, Timeout: 5 * time.Second}
+		resp, err := client.Get("http://" + net.JoinHostPort(host, port) + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("loopback is blocked", func(t *testing.T) {
+		err := get(newStubGuard(t, nil, nil), "127.0.0.1")
+		assert.ErrorIs(t, err, ErrAddressBlocked)
+	})
+
+	t.Run("a name resolving to loopback is blocked", func(t *testing.T) {
+		err := get(newStubGuard(t, nil, map[string]string{"rebind.example": "127.0.0.1"}), "rebind.example")
+		assert.ErrorIs(t, err, ErrAddressBlocked)
+	})
+
+	t.Run("allowlisted range is dialed at the checked address", func(t *testing.T) {
+		guard := newStubGuard(t, []string{"127.0.0.1/32"}, map[string]string{"local.example": "127.0.0.1"})
+		assert.NoError(t, get(guard, "local.example"))
+	})
+}
+
+func TestEgressPolicy_AddressGuard(t *testing.T) {
+	t.Run("blocks connections to internal addresses", func(t *testing.T) {
+		backend := httptest.NewServer(&acceptRecorder{accepts: make(map[string]string)})
+		defer backend.Close()
+
+		policy, err := NewEgressPolicy("", nil)
+		require.NoError(t, err)
+		policy.SetAddressGuard(newStubGuard(t, nil, nil))
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		client.SetEgressPolicy(policy)
+
+		_, err = client.Initialize(context.Background(), &domain.MCPServer{ID: "local", URL: backend.URL + "/mcp", IsActive: true})
+		assert.ErrorIs(t, err, ErrAddressBlocked)
+	})
+
+	t.Run("checks the upstream rather than the proxy", func(t *testing.T) {
+		proxy := &forwardProxy{}
+		ts := httptest.NewServer(proxy)
+		defer ts.Close()
+
+		policy, err := NewEgressPolicy(ts.URL, nil)
+		require.NoError(t, err)
+		policy.SetAddressGuard(newStubGuard(t, nil, map[string]string{
+			"mcp.example.com":  "93.184.216.34",
+			"metadata.example": "169.254.169.254",
+		}))
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		client.SetEgressPolicy(policy)
+
+		_, err = client.Initialize(context.Background(), &domain.MCPServer{ID: "public", URL: "http://mcp.example.com/mcp", IsActive: true})
+		require.NoError(t, err, "the proxy's own loopback address isn't blocked")
+
+		_, err = client.Initialize(context.Background(), &domain.MCPServer{ID: "metadata", URL: "http://metadata.example/mcp", IsActive: true})
+		assert.ErrorIs(t, err, ErrAddressBlocked)
+		assert.Equal(t, []string{"mcp.example.com", "mcp.example.com"}, proxy.requested())
+	})
+
+	t.Run("covers the WebSocket client", func(t *testing.T) {
+		policy, err := NewEgressPolicy("", nil)
+		require.NoError(t, err)
+		policy.SetAddressGuard(newStubGuard(t, nil, nil))
+		svc := NewService(multiServerRepository{
+			"ws": {ID: "ws", URL: "ws://127.0.0.1:1/ws", Transport: domain.TransportWebSocket, IsActive: true},
+		}, logger.NewNopLogger(), nil)
+		t.Cleanup(svc.webSocketClient.(*WebSocketClient).Close)
+		svc.SetEgressPolicy(policy)
+
+		_, err = svc.CallWebSocket(context.Background(), "ws", "tools/list", nil)
+		assert.ErrorIs(t, err, ErrAddressBlocked)
+	})
+}
@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// AggregationMode controls how failures on individual servers affect an aggregated response
+type AggregationMode string
+
+const (
+	// AggregationBestEffort returns results from the servers that succeeded and reports the failures
+	AggregationBestEffort AggregationMode = "best_effort"
+	// AggregationFailFast fails the whole aggregation as soon as any server fails
+	AggregationFailFast AggregationMode = "fail_fast"
+)
+
+// Per-server aggregation statuses
+const (
+	AggregatedServerOK    = "ok"
+	AggregatedServerError = "error"
+)
+
+// ErrAggregationFailed is returned when a fail-fast aggregation hits a server error
+var ErrAggregationFailed = errors.New("aggregation failed")
+
+// ErrInvalidCursor is returned for an aggregation cursor the gateway didn't issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// defaultAggregationConcurrency caps concurrent upstream calls when none is configured
+const defaultAggregationConcurrency = 8
+
+// AggregatedServer reports the outcome of one server in an aggregated response
+type AggregatedServer struct {
+	ServerID  string `json:"server_id"`
+	Status    string `json:"status"`
+	ToolCount int    `json:"tool_count"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AggregatedToolsList is a tools/list merged across several servers. Each tool carries
+// the server_id it came from. Warnings has one message per failed server. NextCursor is
+// set when any server has more tools; pass it back to fetch the next page.
+type AggregatedToolsList struct {
+	Tools      []map[string]json.RawMessage `json:"tools"`
+	Servers    []AggregatedServer           `json:"servers"`
+	Warnings   []string                     `json:"warnings,omitempty"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+// aggregateCursor is the position of each server that has more tools, keyed by server ID.
+// Clients see it as an opaque string.
+type aggregateCursor map[string]string
+
+func (c aggregateCursor) encode() string {
+	if len(c) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(c) // #nosec G104 -- marshaling a string map cannot fail
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAggregateCursor(cursor string) (aggregateCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var decoded aggregateCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return decoded, nil
+}
+
+// ValidAggregationMode reports whether mode is a known aggregation mode
+func ValidAggregationMode(mode AggregationMode) bool {
+	return mode == AggregationBestEffort || mode == AggregationFailFast
+}
+
+// AggregateToolsList fetches tools/list from every server concurrently, at most the configured
+// aggregation concurrency at a time, and merges the tools. An empty mode uses the configured default. In best-effort mode failed servers are reported
+// in Servers; in fail-fast mode the first failure cancels the remaining calls and is
+// returned wrapped in ErrAggregationFailed.
+func (s *Service) AggregateToolsList(ctx context.Context, serverIDs []string, mode AggregationMode) (*AggregatedToolsList, error) {
+	return s.AggregateToolsPage(ctx, serverIDs, mode, "")
+}
+
+// AggregateToolsPage is AggregateToolsList starting at cursor, the NextCursor of a previous
+// page. An empty cursor fetches the first page of every server; otherwise only the servers
+// that had more tools are asked for their next page. Servers in the cursor that are not in
+// serverIDs are skipped. Returns ErrInvalidCursor for a cursor the gateway didn't issue.
+func (s *Service) AggregateToolsPage(ctx context.Context, serverIDs []string, mode AggregationMode, cursor string) (*AggregatedToolsList, error) {
+	var positions aggregateCursor
+	if cursor != "" {
+		var err error
+		if positions, err = decodeAggregateCursor(cursor); err != nil {
+			return nil, err
+		}
+		serverIDs = slices.DeleteFunc(slices.Clone(serverIDs), func(id string) bool {
+			_, ok := positions[id]
+			return !ok
+		})
+	}
+
+	if mode == "" {
+		mode = s.aggregationMode
+	}
+	if mode == "" {
+		mode = AggregationBestEffort
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type serverTools struct {
+		tools      []map[string]json.RawMessage
+		nextCursor string
+		err        error
+	}
+	results := make([]serverTools, len(serverIDs))
+
+	concurrency := s.aggregationConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultAggregationConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, serverID := range serverIDs {
+		wg.Add(1)
+		go func(i int, serverID string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = serverTools{err: ctx.Err()}
+				return
+			}
+			tools, nextCursor, err := s.listServerTools(ctx, serverID, positions[serverID])
+			results[i] = serverTools{tools: tools, nextCursor: nextCursor, err: err}
+			if err != nil && mode == AggregationFailFast {
+				cancel()
+			}
+		}(i, serverID)
+	}
+	wg.Wait()
+
+	aggregated := &AggregatedToolsList{
+		Tools:   make([]map[string]json.RawMessage, 0),
+		Servers: make([]AggregatedServer, 0, len(serverIDs)),
+	}
+	next := aggregateCursor{}
+	var firstErr error
+	for i, serverID := range serverIDs {
+		result := results[i]
+		if result.err != nil {
+			aggregated.Servers = append(aggregated.Servers, AggregatedServer{
+				ServerID: serverID,
+				Status:   AggregatedServerError,
+				Error:    result.err.Error(),
+			})
+			aggregated.Warnings = append(aggregated.Warnings, fmt.Sprintf("server %s: %v", serverID, result.err))
+			// Prefer the root cause over calls cancelled because of it
+			if firstErr == nil || (errors.Is(firstErr, context.Canceled) && !errors.Is(result.err, context.Canceled)) {
+				firstErr = fmt.Errorf("%w: server %s: %w", ErrAggregationFailed, serverID, result.err)
+			}
+			continue
+		}
+
+		aggregated.Tools = append(aggregated.Tools, result.tools...)
+		if result.nextCursor != "" {
+			next[serverID] = result.nextCursor
+		}
+		aggregated.Servers = append(aggregated.Servers, AggregatedServer{
+			ServerID:  serverID,
+			Status:    AggregatedServerOK,
+			ToolCount: len(result.tools),
+		})
+	}
+
+	if firstErr != nil && mode == AggregationFailFast {
+		return aggregated, firstErr
+	}
+	aggregated.NextCursor = next.encode()
+	return aggregated, nil
+}
+
+// listServerTools calls tools/list on a server over its transport, from cursor when set, and
+// tags each tool with the server ID. Tools the server or its namespaces don't allow are
+// dropped and names carry its ToolPrefix, so tools with the same name on different servers
+// can be told apart. Also returns the server's nextCursor.
+func (s *Service) listServerTools(ctx context.Context, serverID, cursor string) ([]map[string]json.RawMessage, string, error) {
+	transport, server, err := s.GetTransportType(ctx, serverID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var params interface{}
+	if cursor != "" {
+		params = map[string]interface{}{"cursor": cursor}
+	}
+
+	var result json.RawMessage
+	switch transport {
+	case domain.TransportStreamableHTTP:
+		result, err = s.CallStreamableHTTP(ctx, serverID, "tools/list", params)
+	case domain.TransportSSE:
+		result, err = s.CallSSE(ctx, serverID, "tools/list", params)
+	case domain.TransportWebSocket:
+		result, err = s.CallWebSocket(ctx, serverID, "tools/list", params)
+	default:
+		return nil, "", fmt.Errorf("transport %s does not support tools aggregation", transport)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Tools      []map[string]json.RawMessage `json:"tools"`
+		NextCursor string                       `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, "", fmt.Errorf("failed to parse tools/list result: %w", err)
+	}
+
+	tag, _ := json.Marshal(serverID) // #nosec G104 -- marshaling a string cannot fail
+	tools := make([]map[string]json.RawMessage, 0, len(page.Tools))
+	for _, tool := range page.Tools {
+		if tool == nil {
+			continue
+		}
+		var name string
+		_ = json.Unmarshal(tool["name"], &name) // #nosec G104 -- tools without a name are never allowed
+		if !server.AllowsTool(name) {
+			continue
+		}
+		tool["name"], _ = json.Marshal(server.ExposedToolName(name)) // #nosec G104 -- marshaling a string cannot fail
+		tool["server_id"] = tag
+		tools = append(tools, tool)
+	}
+	return tools, page.NextCursor, nil
+}
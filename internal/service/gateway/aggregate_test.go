@@ -0,0 +1,237 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// multiServerRepository serves several servers by ID
+type multiServerRepository map[string]*domain.MCPServer
+
+func (m multiServerRepository) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
+	server, ok := m[id]
+	if !ok {
+		return nil, errors.New("server not found")
+	}
+	return server, nil
+}
+
+func newAggregateTestService(t *testing.T) *Service {
+	t.Helper()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search","description":"Search"},{"name":"fetch"}]}}`))
+	}))
+	t.Cleanup(healthy.Close)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("backend exploded"))
+	}))
+	t.Cleanup(failing.Close)
+
+	repo := multiServerRepository{
+		"server-ok":   {ID: "server-ok", URL: healthy.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+		"server-down": {ID: "server-down", URL: failing.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+	}
+	log := logger.NewNopLogger()
+	return NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+}
+
+func TestAggregateToolsList_BestEffort(t *testing.T) {
+	svc := newAggregateTestService(t)
+
+	result, err := svc.AggregateToolsList(context.Background(), []string{"server-ok", "server-down"}, AggregationBestEffort)
+
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 2, "tools from the healthy server are returned")
+	assert.JSONEq(t, `"search"`, string(result.Tools[0]["name"]))
+	assert.JSONEq(t, `"server-ok"`, string(result.Tools[0]["server_id"]))
+	assert.JSONEq(t, `"Search"`, string(result.Tools[0]["description"]), "tool fields are preserved")
+
+	require.Len(t, result.Servers, 2)
+	assert.Equal(t, AggregatedServer{ServerID: "server-ok", Status: AggregatedServerOK, ToolCount: 2}, result.Servers[0])
+	assert.Equal(t, "server-down", result.Servers[1].ServerID)
+	assert.Equal(t, AggregatedServerError, result.Servers[1].Status)
+	assert.Contains(t, result.Servers[1].Error, "500")
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "server-down")
+}
+
+func TestAggregateToolsList_FailFast(t *testing.T) {
+	svc := newAggregateTestService(t)
+
+	result, err := svc.AggregateToolsList(context.Background(), []string{"server-ok", "server-down"}, AggregationFailFast)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrAggregationFailed)
+	assert.Contains(t, err.Error(), "server-down")
+	require.NotNil(t, result)
+	assert.Len(t, result.Servers, 2)
+}
+
+func TestAggregateToolsList_DefaultMode(t *testing.T) {
+	svc := newAggregateTestService(t)
+	svc.aggregationMode = AggregationFailFast
+
+	_, err := svc.AggregateToolsList(context.Background(), []string{"server-down"}, "")
+	assert.ErrorIs(t, err, ErrAggregationFailed, "configured mode applies when none is requested")
+
+	result, err := svc.AggregateToolsList(context.Background(), []string{"server-down"}, AggregationBestEffort)
+	require.NoError(t, err)
+	assert.Empty(t, result.Tools)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tools":[]`)
+}
+
+func TestAggregateToolsList_ToolPrefixAndAllowlist(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search"},{"name":"delete"}]}}`))
+	}))
+	defer backend.Close()
+
+	repo := multiServerRepository{
+		"github": {ID: "github", URL: backend.URL, Transport: domain.TransportStreamableHTTP, ToolPrefix: "github", IsActive: true},
+		"jira":   {ID: "jira", URL: backend.URL, Transport: domain.TransportStreamableHTTP, ToolPrefix: "jira", AllowedTools: []string{"search"}, IsActive: true},
+	}
+	log := logger.NewNopLogger()
+	svc := NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+
+	result, err := svc.AggregateToolsList(context.Background(), []string{"github", "jira"}, AggregationBestEffort)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range result.Tools {
+		var name string
+		require.NoError(t, json.Unmarshal(tool["name"], &name))
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"github__search", "github__delete", "jira__search"}, names)
+}
+
+func TestAggregateToolsList_ConcurrencyCap(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search"}]}}`))
+	}))
+	defer backend.Close()
+
+	repo := multiServerRepository{}
+	serverIDs := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("server-%d", i)
+		repo[id] = &domain.MCPServer{ID: id, URL: backend.URL, Transport: domain.TransportStreamableHTTP, IsActive: true}
+		serverIDs = append(serverIDs, id)
+	}
+	log := logger.NewNopLogger()
+	svc := NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+	svc.aggregationConcurrency = 2
+
+	result, err := svc.AggregateToolsList(context.Background(), serverIDs, AggregationBestEffort)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Tools, 6)
+	assert.Empty(t, result.Warnings)
+	// Each server also gets an initialize request, but never more than two servers at once
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestAggregateToolsPage_CompositeCursor(t *testing.T) {
+	var mu sync.Mutex
+	var gotCursors []string
+	paged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Params struct {
+				Cursor string `json:"cursor"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		gotCursors = append(gotCursors, msg.Params.Cursor)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if msg.Params.Cursor == "" {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"one"}],"nextCursor":"page-2"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"two"}]}}`))
+	}))
+	defer paged.Close()
+
+	var singleCalls atomic.Int32
+	single := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		singleCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"only"}]}}`))
+	}))
+	defer single.Close()
+
+	repo := multiServerRepository{
+		"paged":  {ID: "paged", URL: paged.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+		"single": {ID: "single", URL: single.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+	}
+	log := logger.NewNopLogger()
+	svc := NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+	serverIDs := []string{"paged", "single"}
+
+	first, err := svc.AggregateToolsPage(context.Background(), serverIDs, AggregationBestEffort, "")
+	require.NoError(t, err)
+	assert.Len(t, first.Tools, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := svc.AggregateToolsPage(context.Background(), serverIDs, AggregationBestEffort, first.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, second.Tools, 1)
+	assert.JSONEq(t, `"two"`, string(second.Tools[0]["name"]))
+	assert.JSONEq(t, `"paged"`, string(second.Tools[0]["server_id"]))
+	assert.Empty(t, second.NextCursor, "no server has more tools")
+
+	mu.Lock()
+	assert.Equal(t, []string{"", "page-2"}, gotCursors, "the server's own cursor is forwarded")
+	mu.Unlock()
+	assert.Equal(t, int32(1), singleCalls.Load(), "finished servers aren't asked again")
+
+	// A server the caller can no longer access is skipped
+	third, err := svc.AggregateToolsPage(context.Background(), []string{"single"}, AggregationBestEffort, first.NextCursor)
+	require.NoError(t, err)
+	assert.Empty(t, third.Tools)
+	assert.Empty(t, third.Servers)
+}
+
+func TestAggregateToolsPage_InvalidCursor(t *testing.T) {
+	svc := newAggregateTestService(t)
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := svc.AggregateToolsPage(context.Background(), []string{"server-ok"}, AggregationBestEffort, cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// JitterStrategy spreads out retry delays so gateway instances that fail together don't
+// retry in lockstep. See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type JitterStrategy string
+
+const (
+	// JitterNone waits the exponential delay exactly
+	JitterNone JitterStrategy = "none"
+	// JitterFull waits a random time up to the exponential delay
+	JitterFull JitterStrategy = "full"
+	// JitterEqual waits half the exponential delay plus a random time up to the other half
+	JitterEqual JitterStrategy = "equal"
+	// JitterDecorrelated waits a random time between the base delay and three times the
+	// previous delay
+	JitterDecorrelated JitterStrategy = "decorrelated"
+)
+
+// Backoff computes the delays between successive retries. It is not safe for concurrent
+// use; each retry loop creates its own.
+type Backoff struct {
+	strategy JitterStrategy
+	base     time.Duration
+	max      time.Duration
+
+	attempt int
+	prev    time.Duration
+	// randN returns a random duration in [0, n); replaced in tests
+	randN func(n time.Duration) time.Duration
+}
+
+// NewBackoff creates a backoff whose delays start at base and grow exponentially up to max.
+// An empty strategy means JitterNone.
+func NewBackoff(strategy JitterStrategy, base, max time.Duration) *Backoff {
+	if strategy == "" {
+		strategy = JitterNone
+	}
+	if max < base {
+		max = base
+	}
+	return &Backoff{
+		strategy: strategy,
+		base:     base,
+		max:      max,
+		randN:    rand.N[time.Duration], // #nosec G404 -- jitter doesn't need a secure source
+	}
+}
+
+// Next returns the delay before the next retry
+func (b *Backoff) Next() time.Duration {
+	// base * 2^attempt, capped at max
+	ceiling := b.base
+	for i := 0; i < b.attempt && ceiling < b.max; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, b.max)
+	b.attempt++
+
+	var delay time.Duration
+	switch b.strategy {
+	case JitterFull:
+		delay = b.random(ceiling + 1)
+	case JitterEqual:
+		delay = ceiling/2 + b.random(ceiling-ceiling/2+1)
+	case JitterDecorrelated:
+		prev := max(b.prev, b.base)
+		delay = min(b.base+b.random(prev*3-b.base+1), b.max)
+	default:
+		delay = ceiling
+	}
+	b.prev = delay
+	return delay
+}
+
+// Reset starts the delays over from base, after a success
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.prev = 0
+}
+
+func (b *Backoff) random(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return b.randN(n)
+}
+
+// RetryPolicy controls how upstream requests that fail to connect are retried: SSE
+// messages and initialize requests. Only connection failures are retried, since the
+// server never saw the request.
+type RetryPolicy struct {
+	Jitter     JitterStrategy
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	MaxRetries int // 0 = no retries
+	// Retries of a Streamable HTTP notifications/initialized handshake that failed
+	// transiently (0 = no retries)
+	InitializedRetries int
+}
+
+// retryConnect runs fn, retrying with backoff while it fails to connect, at most
+// policy.MaxRetries times or until ctx is done
+func retryConnect(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	return retryWhile(ctx, policy, policy.MaxRetries, isConnectError, fn)
+}
+
+// retryWhile runs fn, retrying with the policy's backoff while its error is retryable,
+// at most maxRetries times or until ctx is done
+func retryWhile(ctx context.Context, policy RetryPolicy, maxRetries int, retryable func(error) bool, fn func() error) error {
+	var backoff *Backoff
+	for retries := 0; ; retries++ {
+		err := fn()
+		if err == nil || retries >= maxRetries || !retryable(err) {
+			return err
+		}
+		if backoff == nil {
+			backoff = NewBackoff(policy.Jitter, policy.BaseDelay, policy.MaxDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Next()):
+		}
+	}
+}
+
+// isConnectError reports whether err is a failure to connect to the server
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isTransientError reports whether err is a failure that may pass on retry: a failure to
+// connect, a timeout, or a status the server answers with while overloaded or starting
+func isTransientError(err error) bool {
+	if isConnectError(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// delayRange is the interval a jittered delay must fall in
+type delayRange struct{ min, max time.Duration }
+
+func TestBackoff_DelaysWithinJitterRanges(t *testing.T) {
+	const base, maxDelay = 100 * time.Millisecond, 1600 * time.Millisecond
+	// Exponential ceilings: 100ms, 200ms, ..., capped at 1.6s
+	ceilings := []time.Duration{100, 200, 400, 800, 1600, 1600, 1600}
+	for i := range ceilings {
+		ceilings[i] *= time.Millisecond
+	}
+
+	tests := []struct {
+		strategy JitterStrategy
+		expected func(ceiling time.Duration) delayRange
+	}{
+		{JitterNone, func(c time.Duration) delayRange { return delayRange{c, c} }},
+		{JitterFull, func(c time.Duration) delayRange { return delayRange{0, c} }},
+		{JitterEqual, func(c time.Duration) delayRange { return delayRange{c / 2, c} }},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			for run := 0; run < 200; run++ {
+				b := NewBackoff(tt.strategy, base, maxDelay)
+				for i, ceiling := range ceilings {
+					delay := b.Next()
+					r := tt.expected(ceiling)
+					assert.GreaterOrEqual(t, delay, r.min, "retry %d", i)
+					assert.LessOrEqual(t, delay, r.max, "retry %d", i)
+				}
+			}
+		})
+	}
+
+	t.Run(string(JitterDecorrelated), func(t *testing.T) {
+		for run := 0; run < 200; run++ {
+			b := NewBackoff(JitterDecorrelated, base, maxDelay)
+			prev := base
+			for i := 0; i < len(ceilings); i++ {
+				delay := b.Next()
+				assert.GreaterOrEqual(t, delay, base, "retry %d", i)
+				assert.LessOrEqual(t, delay, min(3*prev, maxDelay), "retry %d", i)
+				prev = delay
+			}
+		}
+	})
+}
+
+func TestBackoff_Bounds(t *testing.T) {
+	const base, maxDelay = 100 * time.Millisecond, 800 * time.Millisecond
+	randMax := func(n time.Duration) time.Duration { return n - 1 }
+	randZero := func(time.Duration) time.Duration { return 0 }
+
+	tests := []struct {
+		strategy JitterStrategy
+		randN    func(time.Duration) time.Duration
+		expected []time.Duration
+	}{
+		{JitterFull, randMax, []time.Duration{100, 200, 400, 800, 800}},
+		{JitterFull, randZero, []time.Duration{0, 0, 0, 0, 0}},
+		{JitterEqual, randMax, []time.Duration{100, 200, 400, 800, 800}},
+		{JitterEqual, randZero, []time.Duration{50, 100, 200, 400, 400}},
+		{JitterDecorrelated, randMax, []time.Duration{300, 800, 800, 800, 800}},
+		{JitterDecorrelated, randZero, []time.Duration{100, 100, 100, 100, 100}},
+	}
+	for _, tt := range tests {
+		b := NewBackoff(tt.strategy, base, maxDelay)
+		b.randN = tt.randN
+		for i, expected := range tt.expected {
+			assert.Equal(t, expected*time.Millisecond, b.Next(), "%s retry %d", tt.strategy, i)
+		}
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := NewBackoff("", 10*time.Millisecond, time.Second)
+	assert.Equal(t, 10*time.Millisecond, b.Next())
+	assert.Equal(t, 20*time.Millisecond, b.Next())
+	b.Reset()
+	assert.Equal(t, 10*time.Millisecond, b.Next())
+}
+
+func TestRetryConnect(t *testing.T) {
+	policy := RetryPolicy{Jitter: JitterFull, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRetries: 2}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	t.Run("retries connection failures", func(t *testing.T) {
+		calls := 0
+		err := retryConnect(context.Background(), policy, func() error {
+			calls++
+			if calls < 3 {
+				return dialErr
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		err := retryConnect(context.Background(), policy, func() error {
+			calls++
+			return dialErr
+		})
+		assert.ErrorIs(t, err, dialErr)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry requests the server saw", func(t *testing.T) {
+		calls := 0
+		err := retryConnect(context.Background(), policy, func() error {
+			calls++
+			return errors.New("server returned 500")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, isTransientError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}))
+	assert.True(t, isTransientError(fmt.Errorf("request failed: %w", &net.DNSError{IsTimeout: true})))
+	assert.True(t, isTransientError(&StatusError{StatusCode: 503}))
+	assert.True(t, isTransientError(&StatusError{StatusCode: 429}))
+	assert.False(t, isTransientError(&StatusError{StatusCode: 500}))
+	assert.False(t, isTransientError(errors.New("bad request (400): nope")))
+}
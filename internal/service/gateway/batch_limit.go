@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// DefaultMaxBatchSize is the largest JSON-RPC batch forwarded when neither the server
+// nor the gateway configuration sets a limit
+const DefaultMaxBatchSize = 100
+
+// BatchLimit returns the batch size limit for a server: its MaxBatchSize when set,
+// otherwise fallback. A limit of zero or less means unlimited.
+func BatchLimit(server *domain.MCPServer, fallback int) int {
+	if server != nil && server.MaxBatchSize > 0 {
+		return server.MaxBatchSize
+	}
+	return fallback
+}
+
+// BatchSize returns the number of messages in a JSON-RPC batch body. Returns false when
+// body isn't a JSON array.
+func BatchSize(body []byte) (int, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return 0, false
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(trimmed, &messages); err != nil {
+		return 0, false
+	}
+	return len(messages), true
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// DefaultMaxResponseBytes is the largest upstream response body read when neither the
+// server nor the gateway configuration sets a limit
+const DefaultMaxResponseBytes int64 = 4 << 20
+
+// ErrResponseTooLarge is returned when an upstream response body exceeds its size limit
+var ErrResponseTooLarge = errors.New("upstream response exceeds the size limit")
+
+// ResponseLimit returns the response size limit for a server: its MaxResponseBytes when
+// set, otherwise fallback. A limit of zero or less means unlimited.
+func ResponseLimit(server *domain.MCPServer, fallback int64) int64 {
+	if server != nil && server.MaxResponseBytes > 0 {
+		return server.MaxResponseBytes
+	}
+	return fallback
+}
+
+// LimitResponseBody replaces resp.Body with a reader that fails with ErrResponseTooLarge
+// once more than limit bytes are read. Call it after decompressing so the limit applies
+// to what is actually buffered. A limit of zero or less leaves the body unchanged.
+func LimitResponseBody(resp *http.Response, limit int64) {
+	if limit <= 0 {
+		return
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: limit}
+}
+
+// limitedBody is an io.LimitReader that reports overflow instead of a silent EOF
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// Read one byte past the limit to tell an exact fit from an overflow
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestLimitResponseBody(t *testing.T) {
+	read := func(body string, limit int64) (string, error) {
+		resp := &http.Response{Body: io.NopCloser(strings.NewReader(body))}
+		LimitResponseBody(resp, limit)
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	data, err := read("0123456789", 10)
+	require.NoError(t, err, "a body exactly at the limit is allowed")
+	assert.Equal(t, "0123456789", data)
+
+	data, err = read("0123456789a", 10)
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.Equal(t, "0123456789", data, "nothing past the limit is returned")
+
+	data, err = read("0123456789a", 0)
+	require.NoError(t, err, "zero means unlimited")
+	assert.Equal(t, "0123456789a", data)
+}
+
+func TestResponseLimit(t *testing.T) {
+	assert.Equal(t, int64(100), ResponseLimit(&domain.MCPServer{}, 100))
+	assert.Equal(t, int64(50), ResponseLimit(&domain.MCPServer{MaxResponseBytes: 50}, 100), "the server's limit overrides the default")
+	assert.Equal(t, int64(100), ResponseLimit(nil, 100))
+}
+
+func TestStreamableHTTPClient_MaxResponseBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderMCPSessionID) == "" {
+			w.Header().Set(HeaderMCPSessionID, "session-1")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"%s"}]}}`, strings.Repeat("x", 4096))
+	}))
+	defer ts.Close()
+
+	log := logger.NewNopLogger()
+
+	t.Run("oversized response fails cleanly", func(t *testing.T) {
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+		client.SetMaxResponseBytes(1024)
+		server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+		client.sessions[server.ID] = &MCPSession{SessionID: "session-1", ServerID: server.ID, Initialized: true}
+
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("per-server limit overrides the default", func(t *testing.T) {
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+		client.SetMaxResponseBytes(1024)
+		server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true, MaxResponseBytes: 1 << 20}
+		client.sessions[server.ID] = &MCPSession{SessionID: "session-1", ServerID: server.ID, Initialized: true}
+
+		result, err := client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Contains(t, string(result), "xxxx")
+	})
+}
@@ -0,0 +1,258 @@
+package gateway
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a call is rejected because the server's breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState represents the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerConfig holds circuit breaker thresholds
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before allowing a trial call
+	OpenTimeout time.Duration
+	// LatencyBudget is the response time above which a successful call counts as slow
+	// (0 = slow calls are only tracked for servers with their own budget)
+	LatencyBudget time.Duration
+	// SlowCallRatio is the fraction of the last SlowCallWindow successful calls that must be
+	// slow to open the breaker
+	SlowCallRatio float64
+	// SlowCallWindow is the number of recent successful calls the slow call ratio covers
+	SlowCallWindow int
+}
+
+// CircuitBreaker tracks consecutive upstream failures for a single server, and optionally
+// how many of its recent successful calls were slower than a latency budget.
+// Closed: calls pass through. Open: calls are rejected until OpenTimeout elapses.
+// Half-open: one trial call is allowed; a fast success closes, failure or a slow success reopens.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool // a half-open trial call is in flight
+	now      func() time.Time
+
+	// Ring of the last SlowCallWindow successful calls, true for slow ones
+	slowCalls []bool
+	calls     int // Entries filled in slowCalls
+	next      int // Index the next call is written to
+	slow      int // Slow entries in slowCalls
+}
+
+// NewCircuitBreaker creates a new closed circuit breaker
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.SlowCallRatio <= 0 || cfg.SlowCallRatio > 1 {
+		cfg.SlowCallRatio = 0.5
+	}
+	if cfg.SlowCallWindow <= 0 {
+		cfg.SlowCallWindow = 20
+	}
+	return &CircuitBreaker{
+		config:    cfg,
+		state:     BreakerClosed,
+		now:       time.Now,
+		slowCalls: make([]bool, cfg.SlowCallWindow),
+	}
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen if not
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess records a successful call and closes the breaker
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// RecordLatency records a successful call that took elapsed. budget replaces the
+// configured LatencyBudget when positive; with no budget this is RecordSuccess. The breaker
+// opens once SlowCallRatio of the last SlowCallWindow successful calls were over budget,
+// and a slow half-open trial reopens it. Returns true if this call opened the breaker.
+func (b *CircuitBreaker) RecordLatency(elapsed, budget time.Duration) bool {
+	if budget <= 0 {
+		budget = b.config.LatencyBudget
+	}
+	if budget <= 0 {
+		b.RecordSuccess()
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slow := elapsed > budget
+	b.failures = 0
+	b.trial = false
+	if b.state == BreakerHalfOpen {
+		if slow {
+			b.openLocked()
+			return true
+		}
+		b.state = BreakerClosed
+		return false
+	}
+	b.state = BreakerClosed
+
+	if b.calls == len(b.slowCalls) && b.slowCalls[b.next] {
+		b.slow--
+	}
+	b.slowCalls[b.next] = slow
+	b.next = (b.next + 1) % len(b.slowCalls)
+	if b.calls < len(b.slowCalls) {
+		b.calls++
+	}
+	if slow {
+		b.slow++
+	}
+
+	if b.calls == len(b.slowCalls) && float64(b.slow) >= b.config.SlowCallRatio*float64(b.calls) {
+		b.openLocked()
+		return true
+	}
+	return false
+}
+
+// openLocked opens the breaker and starts a new slow call window. The caller must hold b.mu.
+func (b *CircuitBreaker) openLocked() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.resetSlowCallsLocked()
+}
+
+// resetSlowCallsLocked forgets the recorded call latencies. The caller must hold b.mu.
+func (b *CircuitBreaker) resetSlowCallsLocked() {
+	clear(b.slowCalls)
+	b.calls, b.next, b.slow = 0, 0, 0
+}
+
+// RecordFailure records a failed call, opening the breaker when the threshold is reached
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.openLocked()
+	}
+}
+
+// Release ends a half-open trial call without recording an outcome,
+// e.g. when the caller cancelled the request
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Reset forces the breaker closed. Returns true if it was not already closed.
+func (b *CircuitBreaker) Reset() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasClosed := b.state == BreakerClosed
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+	b.resetSlowCallsLocked()
+	return !wasClosed
+}
+
+// State returns the current breaker state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Report an expired open breaker as half-open without consuming the trial call
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// BreakerRegistry holds one circuit breaker per server
+type BreakerRegistry struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	breakers map[string]*CircuitBreaker
+}
+
+// NewBreakerRegistry creates a new breaker registry
+func NewBreakerRegistry(cfg BreakerConfig) *BreakerRegistry {
+	return &BreakerRegistry{
+		config:   cfg,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Get returns the breaker for a server, creating it if needed
+func (r *BreakerRegistry) Get(serverID string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[serverID]
+	if !ok {
+		b = NewCircuitBreaker(r.config)
+		r.breakers[serverID] = b
+	}
+	return b
+}
+
+// Reset closes the breaker for a server. Returns true if it was not already closed.
+func (r *BreakerRegistry) Reset(serverID string) bool {
+	r.mu.Lock()
+	b, ok := r.breakers[serverID]
+	r.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	return b.Reset()
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.Allow())
+		b.RecordFailure()
+	}
+	assert.Equal(t, BreakerClosed, b.State())
+
+	assert.NoError(t, b.Allow())
+	b.RecordFailure()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_SuccessResetsFailureCount(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	b.RecordFailure()
+	b.RecordSuccess()
+	b.RecordFailure()
+
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_HalfOpenTrial(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+
+	b.RecordFailure()
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// After the open timeout a single trial call is allowed
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	t.Run("failed trial reopens", func(t *testing.T) {
+		b.RecordFailure()
+		assert.Equal(t, BreakerOpen, b.State())
+	})
+
+	t.Run("successful trial closes", func(t *testing.T) {
+		now = now.Add(11 * time.Second)
+		assert.NoError(t, b.Allow())
+		b.RecordSuccess()
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+
+	t.Run("released trial allows another", func(t *testing.T) {
+		b.RecordFailure()
+		now = now.Add(11 * time.Second)
+		assert.NoError(t, b.Allow())
+		b.Release()
+		assert.NoError(t, b.Allow())
+	})
+}
+
+func TestCircuitBreaker_SlowCalls(t *testing.T) {
+	const budget = 100 * time.Millisecond
+	newBreaker := func() *CircuitBreaker {
+		return NewCircuitBreaker(BreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      10 * time.Second,
+			LatencyBudget:    budget,
+			SlowCallRatio:    0.5,
+			SlowCallWindow:   4,
+		})
+	}
+
+	t.Run("opens when the ratio of slow calls is reached", func(t *testing.T) {
+		b := newBreaker()
+
+		assert.False(t, b.RecordLatency(150*time.Millisecond, 0))
+		assert.False(t, b.RecordLatency(20*time.Millisecond, 0))
+		assert.False(t, b.RecordLatency(30*time.Millisecond, 0))
+		assert.Equal(t, BreakerClosed, b.State(), "window not full yet")
+
+		assert.True(t, b.RecordLatency(250*time.Millisecond, 0))
+		assert.Equal(t, BreakerOpen, b.State())
+		assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	})
+
+	t.Run("old calls leave the window", func(t *testing.T) {
+		b := newBreaker()
+
+		b.RecordLatency(150*time.Millisecond, 0)
+		for i := 0; i < 4; i++ {
+			assert.False(t, b.RecordLatency(10*time.Millisecond, 0))
+		}
+		// The first slow call has left the window, so one more isn't enough
+		assert.False(t, b.RecordLatency(150*time.Millisecond, 0))
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+
+	t.Run("calls within budget keep it closed", func(t *testing.T) {
+		b := newBreaker()
+
+		for i := 0; i < 10; i++ {
+			assert.False(t, b.RecordLatency(budget, 0))
+		}
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+
+	t.Run("server budget overrides the configured one", func(t *testing.T) {
+		b := newBreaker()
+
+		for i := 0; i < 4; i++ {
+			b.RecordLatency(50*time.Millisecond, 20*time.Millisecond)
+		}
+		assert.Equal(t, BreakerOpen, b.State())
+	})
+
+	t.Run("slow half-open trial reopens", func(t *testing.T) {
+		now := time.Now()
+		b := newBreaker()
+		b.now = func() time.Time { return now }
+		for i := 0; i < 4; i++ {
+			b.RecordLatency(time.Second, 0)
+		}
+		require.Equal(t, BreakerOpen, b.State())
+
+		now = now.Add(11 * time.Second)
+		require.NoError(t, b.Allow())
+		assert.True(t, b.RecordLatency(time.Second, 0))
+		assert.Equal(t, BreakerOpen, b.State())
+
+		now = now.Add(11 * time.Second)
+		require.NoError(t, b.Allow())
+		assert.False(t, b.RecordLatency(10*time.Millisecond, 0))
+		assert.Equal(t, BreakerClosed, b.State())
+
+		// The window starts over after the breaker closes
+		for i := 0; i < 3; i++ {
+			assert.False(t, b.RecordLatency(time.Second, 0))
+		}
+	})
+
+	t.Run("no budget records a plain success", func(t *testing.T) {
+		b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, SlowCallWindow: 1})
+
+		b.RecordFailure()
+		assert.False(t, b.RecordLatency(time.Hour, 0))
+		b.RecordFailure()
+		assert.Equal(t, BreakerClosed, b.State())
+	})
+}
+
+func TestCircuitBreaker_Reset(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	assert.False(t, b.Reset(), "closed breaker reports no change")
+
+	b.RecordFailure()
+	assert.True(t, b.Reset())
+	assert.Equal(t, BreakerClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{})
+
+	assert.Equal(t, 5, b.config.FailureThreshold)
+	assert.Equal(t, 30*time.Second, b.config.OpenTimeout)
+	assert.Equal(t, 0.5, b.config.SlowCallRatio)
+	assert.Equal(t, 20, b.config.SlowCallWindow)
+}
+
+func TestBreakerRegistry(t *testing.T) {
+	r := NewBreakerRegistry(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	assert.Same(t, r.Get("server-1"), r.Get("server-1"))
+	assert.NotSame(t, r.Get("server-1"), r.Get("server-2"))
+
+	assert.False(t, r.Reset("unknown"))
+
+	r.Get("server-1").RecordFailure()
+	assert.True(t, r.Reset("server-1"))
+	assert.Equal(t, BreakerClosed, r.Get("server-1").State())
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// serverSemaphore bounds concurrent upstream calls for one server
+type serverSemaphore struct {
+	slots chan struct{}
+}
+
+// ConnectionLimiter enforces each server's MaxConnections as a semaphore on upstream
+// calls and exports in-use, queue depth and wait time metrics per server
+type ConnectionLimiter struct {
+	mu         sync.Mutex
+	semaphores map[string]*serverSemaphore
+	metrics    *metrics.Registry
+}
+
+// NewConnectionLimiter creates a new connection limiter. metricsReg may be nil.
+func NewConnectionLimiter(metricsReg *metrics.Registry) *ConnectionLimiter {
+	return &ConnectionLimiter{
+		semaphores: make(map[string]*serverSemaphore),
+		metrics:    metricsReg,
+	}
+}
+
+// Acquire waits for a free slot on the server, returning a release func that must be
+// called when the upstream call completes. Servers without MaxConnections are not limited.
+func (l *ConnectionLimiter) Acquire(ctx context.Context, server *domain.MCPServer) (func(), error) {
+	if server.MaxConnections <= 0 {
+		return func() {}, nil
+	}
+
+	sem := l.semaphore(server.ID, server.MaxConnections)
+	labels := []string{server.ID, server.Name}
+
+	start := time.Now()
+	select {
+	case sem.slots <- struct{}{}:
+	default:
+		// No free slot, queue until one is released
+		l.addQueued(labels, 1)
+		select {
+		case sem.slots <- struct{}{}:
+			l.addQueued(labels, -1)
+		case <-ctx.Done():
+			l.addQueued(labels, -1)
+			return nil, fmt.Errorf("waiting for connection to server %s: %w", server.ID, ctx.Err())
+		}
+	}
+
+	if l.metrics != nil {
+		l.metrics.GatewayConnectionWaitTime.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		l.metrics.GatewayConnectionsInUse.WithLabelValues(labels...).Inc()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-sem.slots
+			if l.metrics != nil {
+				l.metrics.GatewayConnectionsInUse.WithLabelValues(labels...).Dec()
+			}
+		})
+	}, nil
+}
+
+// semaphore returns the server's semaphore, replacing it if MaxConnections changed.
+// Calls holding a slot on a replaced semaphore release it normally.
+func (l *ConnectionLimiter) semaphore(serverID string, maxConnections int) *serverSemaphore {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.semaphores[serverID]
+	if !ok || cap(sem.slots) != maxConnections {
+		sem = &serverSemaphore{slots: make(chan struct{}, maxConnections)}
+		l.semaphores[serverID] = sem
+	}
+	return sem
+}
+
+func (l *ConnectionLimiter) addQueued(labels []string, delta float64) {
+	if l.metrics != nil {
+		l.metrics.GatewayConnectionQueue.WithLabelValues(labels...).Add(delta)
+	}
+}
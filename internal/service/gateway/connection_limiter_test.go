@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+func TestConnectionLimiter_Gauges(t *testing.T) {
+	reg := metrics.NewRegistry()
+	limiter := NewConnectionLimiter(reg)
+	server := &domain.MCPServer{ID: "server-1", Name: "test", MaxConnections: 1}
+
+	inUse := reg.GatewayConnectionsInUse.WithLabelValues("server-1", "test")
+	queued := reg.GatewayConnectionQueue.WithLabelValues("server-1", "test")
+
+	release, err := limiter.Acquire(context.Background(), server)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(inUse))
+	assert.Equal(t, 0.0, testutil.ToFloat64(queued))
+
+	acquired := make(chan func())
+	go func() {
+		r, err := limiter.Acquire(context.Background(), server)
+		assert.NoError(t, err)
+		acquired <- r
+	}()
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(queued) == 1
+	}, time.Second, 5*time.Millisecond, "second caller should be queued")
+	assert.Equal(t, 1.0, testutil.ToFloat64(inUse))
+
+	release()
+	var release2 func()
+	select {
+	case release2 = <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("queued caller did not acquire after release")
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(inUse))
+	assert.Equal(t, 0.0, testutil.ToFloat64(queued))
+
+	release2()
+	release2() // Releasing twice is a no-op
+	assert.Equal(t, 0.0, testutil.ToFloat64(inUse))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg.GatewayConnectionWaitTime), "wait time observed for the server")
+}
+
+func TestConnectionLimiter_CancelWhileQueued(t *testing.T) {
+	reg := metrics.NewRegistry()
+	limiter := NewConnectionLimiter(reg)
+	server := &domain.MCPServer{ID: "server-1", Name: "test", MaxConnections: 1}
+
+	release, err := limiter.Acquire(context.Background(), server)
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = limiter.Acquire(ctx, server)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0.0, testutil.ToFloat64(reg.GatewayConnectionQueue.WithLabelValues("server-1", "test")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reg.GatewayConnectionsInUse.WithLabelValues("server-1", "test")))
+}
+
+func TestConnectionLimiter_Unlimited(t *testing.T) {
+	limiter := NewConnectionLimiter(nil)
+	server := &domain.MCPServer{ID: "server-1"}
+
+	for i := 0; i < 3; i++ {
+		release, err := limiter.Acquire(context.Background(), server)
+		require.NoError(t, err)
+		defer release()
+	}
+}
+
+func TestConnectionLimiter_ResizesOnMaxConnectionsChange(t *testing.T) {
+	limiter := NewConnectionLimiter(nil)
+	server := &domain.MCPServer{ID: "server-1", MaxConnections: 1}
+
+	release, err := limiter.Acquire(context.Background(), server)
+	require.NoError(t, err)
+
+	server.MaxConnections = 2
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release2, err := limiter.Acquire(ctx, server)
+	require.NoError(t, err)
+
+	release()
+	release2()
+}
@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Content block types in tool results
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResource     = "resource"      // Embedded resource contents
+	ContentTypeResourceLink = "resource_link" // Reference to a resource the client can read
+)
+
+// ErrInvalidToolResult is returned for a tools/call result whose content blocks are
+// malformed, such as an image whose data isn't base64
+var ErrInvalidToolResult = errors.New("invalid tool result")
+
+// ContentBlock is one item of a tools/call result's content. Only the fields of its Type
+// are set. A block marshals back to exactly the JSON it was parsed from, so fields the
+// gateway doesn't know about and base64 data reach the client unchanged.
+type ContentBlock struct {
+	Type string `json:"type"`
+	// Text is set for text blocks
+	Text string `json:"text,omitempty"`
+	// Data is the base64-encoded bytes of image and audio blocks
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	// Resource is set for embedded resource blocks
+	Resource *ResourceContents `json:"resource,omitempty"`
+	// URI and Name identify the resource of a resource_link block
+	URI         string          `json:"uri,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+
+	raw json.RawMessage
+}
+
+// UnmarshalJSON decodes the block and keeps its original JSON
+func (b *ContentBlock) UnmarshalJSON(data []byte) error {
+	type plain ContentBlock
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*b = ContentBlock(decoded)
+	b.raw = bytes.Clone(data)
+	return nil
+}
+
+// MarshalJSON returns the block's original JSON when it was parsed, so forwarding it
+// loses nothing
+func (b ContentBlock) MarshalJSON() ([]byte, error) {
+	if b.raw != nil {
+		return b.raw, nil
+	}
+	type plain ContentBlock
+	return json.Marshal(plain(b))
+}
+
+// IsBinary reports whether the block carries base64 data: an image, audio, or an
+// embedded binary resource
+func (b *ContentBlock) IsBinary() bool {
+	switch b.Type {
+	case ContentTypeImage, ContentTypeAudio:
+		return true
+	case ContentTypeResource:
+		return b.Resource != nil && b.Resource.IsBinary()
+	}
+	return false
+}
+
+// Bytes returns the decoded data of a binary block, or the text of a text block or
+// embedded text resource
+func (b *ContentBlock) Bytes() ([]byte, error) {
+	switch b.Type {
+	case ContentTypeImage, ContentTypeAudio:
+		data, err := base64.StdEncoding.DecodeString(b.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s data is not valid base64: %v", ErrInvalidToolResult, b.Type, err)
+		}
+		return data, nil
+	case ContentTypeResource:
+		if b.Resource == nil {
+			return nil, fmt.Errorf("%w: resource block has no resource", ErrInvalidToolResult)
+		}
+		return b.Resource.Bytes()
+	}
+	return []byte(b.Text), nil
+}
+
+// ToolResult is the result of tools/call
+type ToolResult struct {
+	Content           []ContentBlock  `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// ParseToolResult decodes a tools/call result and checks that its binary blocks hold
+// valid base64 and its resource blocks are well formed
+func ParseToolResult(raw json.RawMessage) (*ToolResult, error) {
+	var result ToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToolResult, err)
+	}
+	for i := range result.Content {
+		block := &result.Content[i]
+		switch block.Type {
+		case ContentTypeImage, ContentTypeAudio, ContentTypeResource:
+			if _, err := block.Bytes(); err != nil {
+				return nil, fmt.Errorf("content block %d: %w", i, err)
+			}
+		case ContentTypeResourceLink:
+			if block.URI == "" {
+				return nil, fmt.Errorf("%w: content block %d: resource_link has no uri", ErrInvalidToolResult, i)
+			}
+		}
+	}
+	return &result, nil
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// newToolResultService proxies to a Streamable HTTP server whose tools/call returns result
+func newToolResultService(t *testing.T, result string, maxResponseBytes int64) *Service {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		w.Header().Set(HeaderMCPSessionID, "session-1")
+		switch msg.Method {
+		case "initialize":
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{}}}}`, msg.ID)
+		case "tools/call":
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, msg.ID, result)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	repo := multiServerRepository{
+		"server-1": {
+			ID:               "server-1",
+			URL:              ts.URL,
+			Transport:        domain.TransportStreamableHTTP,
+			IsActive:         true,
+			MaxResponseBytes: maxResponseBytes,
+		},
+	}
+	return NewService(repo, logger.NewNopLogger(), nil)
+}
+
+func TestToolResult_TextAndImageForwarded(t *testing.T) {
+	image := make([]byte, 300)
+	for i := range image {
+		image[i] = byte(255 - i)
+	}
+	data := base64.StdEncoding.EncodeToString(image)
+	result := `{"content":[{"type":"text","text":"rendered"},{"type":"image","data":"` + data + `","mimeType":"image/png","annotations":{"priority":0.5},"_meta":{"x":1}}]}`
+	svc := newToolResultService(t, result, 0)
+
+	raw, err := svc.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "render"})
+	require.NoError(t, err)
+
+	parsed, err := ParseToolResult(raw)
+	require.NoError(t, err)
+	require.Len(t, parsed.Content, 2)
+	assert.Equal(t, ContentTypeText, parsed.Content[0].Type)
+	assert.Equal(t, "rendered", parsed.Content[0].Text)
+
+	block := parsed.Content[1]
+	assert.Equal(t, ContentTypeImage, block.Type)
+	assert.True(t, block.IsBinary())
+	decoded, err := block.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, image, decoded)
+
+	// Re-encoding keeps fields the gateway doesn't model
+	encoded, err := json.Marshal(parsed.Content)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"type":"text","text":"rendered"},{"type":"image","data":"`+data+`","mimeType":"image/png","annotations":{"priority":0.5},"_meta":{"x":1}}]`, string(encoded))
+}
+
+func TestToolResult_LargeImageRespectsResponseLimit(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString(make([]byte, 8192))
+	svc := newToolResultService(t, `{"content":[{"type":"image","data":"`+data+`","mimeType":"image/png"}]}`, 4096)
+
+	_, err := svc.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "render"})
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+}
+
+func TestParseToolResult_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"image data not base64":   `{"content":[{"type":"image","data":"%%%","mimeType":"image/png"}]}`,
+		"audio data not base64":   `{"content":[{"type":"audio","data":"***","mimeType":"audio/wav"}]}`,
+		"resource without body":   `{"content":[{"type":"resource"}]}`,
+		"resource blob invalid":   `{"content":[{"type":"resource","resource":{"uri":"file:///a","blob":"%%%"}}]}`,
+		"resource_link no uri":    `{"content":[{"type":"resource_link","name":"a"}]}`,
+		"content is not an array": `{"content":"text"}`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseToolResult(json.RawMessage(raw))
+			assert.Error(t, err)
+		})
+	}
+}
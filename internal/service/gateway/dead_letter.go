@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// FailureKind classifies why a proxied request failed for good
+type FailureKind string
+
+const (
+	// FailureConnect means the server couldn't be reached, after any retries
+	FailureConnect FailureKind = "connect"
+	// FailureTimeout means the call's deadline passed before the server answered
+	FailureTimeout FailureKind = "timeout"
+	// FailureSession means no MCP session could be initialized or re-initialized
+	FailureSession FailureKind = "session"
+	// FailureUpstreamStatus means the server answered with an HTTP error status
+	FailureUpstreamStatus FailureKind = "upstream_status"
+	// FailureResponseTooLarge means the response exceeded the size limit
+	FailureResponseTooLarge FailureKind = "response_too_large"
+	// FailureProtocol means the response couldn't be read as JSON-RPC
+	FailureProtocol FailureKind = "protocol"
+	// FailureOther is any other failure
+	FailureOther FailureKind = "other"
+)
+
+// ClassifyFailure returns the kind of a proxied request's failure
+func ClassifyFailure(err error) FailureKind {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, ErrResponseTooLarge):
+		return FailureResponseTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case strings.Contains(msg, "initialize"):
+		// Checked before connect: a failed initialize wraps the underlying error
+		return FailureSession
+	case isConnectError(err):
+		return FailureConnect
+	case strings.Contains(msg, "server returned"), strings.Contains(msg, "bad request (400)"), strings.Contains(msg, "session not found (404)"):
+		return FailureUpstreamStatus
+	case strings.Contains(msg, "failed to parse"), strings.Contains(msg, "failed to read"), strings.Contains(msg, "no data received"):
+		return FailureProtocol
+	}
+	return FailureOther
+}
+
+// DeadLetter records a proxied request that failed after retries were exhausted, for
+// later analysis or replay
+type DeadLetter struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	ServerID   string          `json:"server_id"`
+	ServerName string          `json:"server_name"`
+	Transport  string          `json:"transport"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"` // With redacted keys replaced
+	Kind       FailureKind     `json:"kind"`
+	Error      string          `json:"error"`
+}
+
+// DeadLetterStore keeps dead letters
+type DeadLetterStore interface {
+	Add(letter DeadLetter)
+	// List returns up to limit dead letters, newest first (limit <= 0 = all)
+	List(limit int) []DeadLetter
+}
+
+// MemoryDeadLetterStore keeps the most recent dead letters in memory, dropping the oldest
+// once it is full
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	entries *ring[DeadLetter]
+}
+
+// NewMemoryDeadLetterStore creates a store that keeps up to maxEntries dead letters
+func NewMemoryDeadLetterStore(maxEntries int) *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{entries: newRing[DeadLetter](maxEntries)}
+}
+
+// Add stores letter, replacing the oldest when full
+func (s *MemoryDeadLetterStore) Add(letter DeadLetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.add(letter)
+}
+
+// List returns up to limit dead letters, newest first
+func (s *MemoryDeadLetterStore) List(limit int) []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries.list(limit)
+}
+
+// SetDeadLetters records requests that fail for good in store, with the values of
+// redactKeys in their params replaced. Must be called before the service is used.
+func (s *Service) SetDeadLetters(store DeadLetterStore, redactKeys []string) {
+	s.deadLetters = store
+	s.deadLetterRedactKeys = RedactKeySet(redactKeys)
+}
+
+// DeadLetters returns the service's dead letter store, or nil when disabled
+func (s *Service) DeadLetters() DeadLetterStore {
+	return s.deadLetters
+}
+
+// recordDeadLetter stores a request whose upstream call failed. Errors the server sent as
+// a JSON-RPC response, and calls the client gave up on, aren't dead letters.
+func (s *Service) recordDeadLetter(ctx context.Context, server *domain.MCPServer, method string, params interface{}, err error) {
+	if s.deadLetters == nil || err == nil {
+		return
+	}
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	letter := DeadLetter{
+		ID:         uuid.NewString(),
+		Time:       time.Now().UTC(),
+		ServerID:   server.ID,
+		ServerName: server.Name,
+		Transport:  string(server.Transport),
+		Method:     method,
+		Params:     s.redactParams(params),
+		Kind:       ClassifyFailure(err),
+		Error:      err.Error(),
+	}
+	s.deadLetters.Add(letter)
+
+	s.logger.Warn().
+		Str("server_id", server.ID).
+		Str("method", method).
+		Str("kind", string(letter.Kind)).
+		Err(err).
+		Msg("Proxied request failed, recorded as dead letter")
+}
+
+// redactParams encodes params with the values of redacted keys replaced
+func (s *Service) redactParams(params interface{}) json.RawMessage {
+	return redactValue(params, s.deadLetterRedactKeys)
+}
+
+// redactValue encodes value with the values of keys replaced, or returns nil when value
+// is nil or can't be encoded
+func redactValue(value interface{}, keys map[string]bool) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(RedactJSON(decoded, keys))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func newDeadLetterService(server *domain.MCPServer) (*Service, *MemoryDeadLetterStore) {
+	svc := NewService(multiServerRepository{server.ID: server}, logger.NewNopLogger(), nil)
+	svc.sseClient.(*SSEClient).SetRetryPolicy(RetryPolicy{Jitter: JitterFull, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRetries: 2})
+	store := NewMemoryDeadLetterStore(10)
+	svc.SetDeadLetters(store, []string{"token"})
+	return svc, store
+}
+
+func TestDeadLetter_ExhaustedRetriesRecorded(t *testing.T) {
+	// A server that is gone: every connection attempt is refused
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	svc, store := newDeadLetterService(&domain.MCPServer{ID: "server-1", Name: "gone", URL: url, Transport: domain.TransportSSE, IsActive: true})
+
+	params := map[string]interface{}{"name": "search", "arguments": map[string]interface{}{"query": "q", "Token": "s3cret"}}
+	_, err := svc.CallSSE(context.Background(), "server-1", "tools/call", params)
+	require.Error(t, err)
+
+	letters := store.List(0)
+	require.Len(t, letters, 1)
+	letter := letters[0]
+	assert.Equal(t, FailureConnect, letter.Kind)
+	assert.Equal(t, "server-1", letter.ServerID)
+	assert.Equal(t, "gone", letter.ServerName)
+	assert.Equal(t, "sse", letter.Transport)
+	assert.Equal(t, "tools/call", letter.Method)
+	assert.NotEmpty(t, letter.ID)
+	assert.Contains(t, letter.Error, "connection refused")
+	assert.JSONEq(t, `{"name":"search","arguments":{"query":"q","Token":"[REDACTED]"}}`, string(letter.Params))
+}
+
+func TestDeadLetter_FailureKinds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		switch msg.Method {
+		case "fail":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "garbled":
+			fmt.Fprint(w, "not json")
+		case "rpc_error":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32602,"message":"bad params"}}`, msg.ID)
+		}
+	}))
+	defer ts.Close()
+
+	svc, store := newDeadLetterService(&domain.MCPServer{ID: "server-1", URL: ts.URL, Transport: domain.TransportStreamableHTTP, IsActive: true})
+
+	tests := []struct {
+		method string
+		kind   FailureKind // Empty when no dead letter is expected
+	}{
+		{method: "fail", kind: FailureUpstreamStatus},
+		{method: "slow", kind: FailureTimeout},
+		{method: "garbled", kind: FailureProtocol},
+		{method: "rpc_error"}, // The server answered; not a failed request
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			before := len(store.List(0))
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := svc.CallStreamableHTTP(ctx, "server-1", tt.method, nil)
+			require.Error(t, err)
+
+			letters := store.List(0)
+			if tt.kind == "" {
+				assert.Len(t, letters, before)
+				return
+			}
+			require.Len(t, letters, before+1)
+			assert.Equal(t, tt.kind, letters[0].Kind, letters[0].Error)
+			assert.Equal(t, tt.method, letters[0].Method)
+		})
+	}
+}
+
+func TestDeadLetter_CancelledCallNotRecorded(t *testing.T) {
+	stop := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer ts.Close()
+	defer close(stop)
+
+	svc, store := newDeadLetterService(&domain.MCPServer{ID: "server-1", URL: ts.URL, Transport: domain.TransportStreamableHTTP, IsActive: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := svc.CallStreamableHTTP(ctx, "server-1", "tools/list", nil)
+	require.Error(t, err)
+	assert.Empty(t, store.List(0))
+}
+
+func TestMemoryDeadLetterStore_Bounded(t *testing.T) {
+	store := NewMemoryDeadLetterStore(3)
+	for i := 1; i <= 5; i++ {
+		store.Add(DeadLetter{ID: fmt.Sprint(i)})
+	}
+
+	ids := []string{}
+	for _, letter := range store.List(0) {
+		ids = append(ids, letter.ID)
+	}
+	assert.Equal(t, []string{"5", "4", "3"}, ids)
+	assert.Len(t, store.List(2), 2)
+}
+
+func TestClassifyFailure(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	tests := []struct {
+		err  error
+		kind FailureKind
+	}{
+		{fmt.Errorf("request failed: %w", dialErr), FailureConnect},
+		{fmt.Errorf("request failed: %w", context.DeadlineExceeded), FailureTimeout},
+		{fmt.Errorf("initialize failed: request failed: %w", dialErr), FailureSession},
+		{fmt.Errorf("failed to reinitialize session: %w", errors.New("server returned 500: boom")), FailureSession},
+		{errors.New("server returned 503: unavailable"), FailureUpstreamStatus},
+		{fmt.Errorf("read: %w", ErrResponseTooLarge), FailureResponseTooLarge},
+		{errors.New("failed to parse JSON-RPC response: invalid character"), FailureProtocol},
+		{errors.New("something else"), FailureOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.kind, ClassifyFailure(tt.err), tt.err.Error())
+	}
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// DebugEntry is one request the gateway sent to a server and the response it got, kept
+// for live troubleshooting
+type DebugEntry struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	ServerID   string          `json:"server_id"`
+	Transport  string          `json:"transport"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`   // With redacted keys replaced
+	Response   json.RawMessage `json:"response,omitempty"` // With redacted keys replaced; left out when truncated
+	Truncated  bool            `json:"truncated,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// DebugLog keeps the most recent requests and responses of each server in memory. Nothing
+// is persisted: entries are lost on restart, and the oldest are dropped once a server's
+// buffer is full.
+type DebugLog struct {
+	maxEntries      int      // Entries kept per server
+	maxResponseSize int      // Largest response kept (0 = unlimited)
+	serverIDs       []string // Servers whose traffic is kept (empty = all)
+
+	mu      sync.Mutex
+	servers map[string]*ring[DebugEntry]
+}
+
+// NewDebugLog creates a log keeping the last maxEntries requests of each server in
+// serverIDs, or of every server when serverIDs is empty. Responses larger than
+// maxResponseSize bytes are recorded without their body.
+func NewDebugLog(maxEntries, maxResponseSize int, serverIDs []string) *DebugLog {
+	return &DebugLog{
+		maxEntries:      maxEntries,
+		maxResponseSize: maxResponseSize,
+		serverIDs:       serverIDs,
+		servers:         make(map[string]*ring[DebugEntry]),
+	}
+}
+
+// Captures reports whether the log keeps the server's traffic
+func (l *DebugLog) Captures(serverID string) bool {
+	return len(l.serverIDs) == 0 || slices.Contains(l.serverIDs, serverID)
+}
+
+// Add stores entry in its server's buffer, replacing the oldest when full
+func (l *DebugLog) Add(entry DebugEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, ok := l.servers[entry.ServerID]
+	if !ok {
+		entries = newRing[DebugEntry](l.maxEntries)
+		l.servers[entry.ServerID] = entries
+	}
+	entries.add(entry)
+}
+
+// List returns up to limit of the server's entries, newest first (limit <= 0 = all)
+func (l *DebugLog) List(serverID string, limit int) []DebugEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, ok := l.servers[serverID]
+	if !ok {
+		return []DebugEntry{}
+	}
+	return entries.list(limit)
+}
+
+// Clear drops the server's entries
+func (l *DebugLog) Clear(serverID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.servers, serverID)
+}
+
+// SetDebugLog keeps each request sent to a server, and its response, in debugLog, with the
+// values of redactKeys replaced. Must be called before the service is used.
+func (s *Service) SetDebugLog(debugLog *DebugLog, redactKeys []string) {
+	s.debugLog = debugLog
+	s.debugLogRedactKeys = RedactKeySet(redactKeys)
+}
+
+// DebugLog returns the service's debug log, or nil when disabled
+func (s *Service) DebugLog() *DebugLog {
+	return s.debugLog
+}
+
+// recordDebugEntry keeps a request sent to server and its outcome in the debug log
+func (s *Service) recordDebugEntry(server *domain.MCPServer, method string, params interface{}, result json.RawMessage, err error, elapsed time.Duration) {
+	if s.debugLog == nil || !s.debugLog.Captures(server.ID) {
+		return
+	}
+
+	entry := DebugEntry{
+		ID:         uuid.NewString(),
+		Time:       time.Now().UTC(),
+		ServerID:   server.ID,
+		Transport:  string(server.Transport),
+		Method:     method,
+		Params:     redactValue(params, s.debugLogRedactKeys),
+		DurationMS: elapsed.Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if len(result) > 0 {
+		if limit := s.debugLog.maxResponseSize; limit > 0 && len(result) > limit {
+			entry.Truncated = true
+		} else {
+			entry.Response = redactValue(result, s.debugLogRedactKeys)
+		}
+	}
+	s.debugLog.Add(entry)
+}
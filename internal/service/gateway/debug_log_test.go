@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestDebugLog_KeepsLastEntriesPerServer(t *testing.T) {
+	debugLog := NewDebugLog(3, 0, nil)
+	for i := 1; i <= 5; i++ {
+		debugLog.Add(DebugEntry{ID: fmt.Sprint(i), ServerID: "a"})
+	}
+	debugLog.Add(DebugEntry{ID: "b1", ServerID: "b"})
+
+	ids := func(entries []DebugEntry) []string {
+		out := []string{}
+		for _, entry := range entries {
+			out = append(out, entry.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"5", "4", "3"}, ids(debugLog.List("a", 0)), "older entries are evicted")
+	assert.Equal(t, []string{"5", "4"}, ids(debugLog.List("a", 2)))
+	assert.Equal(t, []string{"b1"}, ids(debugLog.List("b", 0)), "each server has its own buffer")
+	assert.Empty(t, debugLog.List("c", 0))
+
+	debugLog.Clear("a")
+	assert.Empty(t, debugLog.List("a", 0))
+	assert.Equal(t, []string{"b1"}, ids(debugLog.List("b", 0)))
+}
+
+func TestDebugLog_Captures(t *testing.T) {
+	assert.True(t, NewDebugLog(1, 0, nil).Captures("any"))
+
+	debugLog := NewDebugLog(1, 0, []string{"a"})
+	assert.True(t, debugLog.Captures("a"))
+	assert.False(t, debugLog.Captures("b"))
+}
+
+func TestService_RecordsDebugEntries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		w.Header().Set("Content-Type", "application/json")
+		result := `{"session":{"token":"abc"},"ok":true}`
+		if msg.Method == "resources/read" {
+			result = `{"contents":[{"text":"` + strings.Repeat("x", 100) + `"}]}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, msg.ID, result)
+	}))
+	defer ts.Close()
+
+	server := &domain.MCPServer{ID: "server-1", URL: ts.URL + "/sse", Transport: domain.TransportSSE, IsActive: true}
+	svc := NewService(multiServerRepository{server.ID: server}, logger.NewNopLogger(), nil)
+	debugLog := NewDebugLog(10, 64, nil)
+	svc.SetDebugLog(debugLog, []string{"token"})
+
+	_, err := svc.CallSSE(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "login", "arguments": map[string]interface{}{"token": "s3cret"}})
+	require.NoError(t, err)
+	_, err = svc.CallSSE(context.Background(), "server-1", "resources/read", map[string]interface{}{"uri": "file:///big.txt"})
+	require.NoError(t, err)
+
+	entries := debugLog.List("server-1", 0)
+	require.Len(t, entries, 2)
+
+	big := entries[0]
+	assert.Equal(t, "resources/read", big.Method)
+	assert.True(t, big.Truncated)
+	assert.Nil(t, big.Response)
+
+	call := entries[1]
+	assert.Equal(t, "tools/call", call.Method)
+	assert.Equal(t, "sse", call.Transport)
+	assert.NotEmpty(t, call.ID)
+	assert.Empty(t, call.Error)
+	assert.JSONEq(t, `{"name":"login","arguments":{"token":"[REDACTED]"}}`, string(call.Params))
+	assert.JSONEq(t, `{"session":{"token":"[REDACTED]"},"ok":true}`, string(call.Response))
+}
@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrEgressDenied is returned for upstream requests to a host outside the egress allowlist
+var ErrEgressDenied = errors.New("upstream host not allowed by egress policy")
+
+// EgressPolicy controls where the gateway's upstream requests go: through which proxy, and
+// to which hosts. Since admins can register any URL, the allowlist keeps the gateway from
+// being used to reach internal services.
+type EgressPolicy struct {
+	proxy        func(*http.Request) (*url.URL, error)
+	allowedHosts []string
+	transport    *http.Transport
+	guard        *AddressGuard // Blocks internal upstream addresses (nil = any address)
+	proxyAddrs   sync.Map      // host:port of proxies returned by Proxy, dialed unguarded
+}
+
+// NewEgressPolicy creates a policy sending upstream requests through proxyURL (empty = the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment) and only to allowedHosts (empty = any
+// host). A host entry of "*.example.com" matches every subdomain of example.com.
+func NewEgressPolicy(proxyURL string, allowedHosts []string) (*EgressPolicy, error) {
+	policy := &EgressPolicy{proxy: http.ProxyFromEnvironment}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress proxy URL: %w", err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid egress proxy URL %q: no host", proxyURL)
+		}
+		policy.proxy = http.ProxyURL(u)
+	}
+	for _, host := range allowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			policy.allowedHosts = append(policy.allowedHosts, host)
+		}
+	}
+	policy.transport = http.DefaultTransport.(*http.Transport).Clone()
+	policy.transport.Proxy = policy.Proxy
+	return policy, nil
+}
+
+// AllowsHost reports whether upstream requests may go to host
+func (p *EgressPolicy) AllowsHost(host string) bool {
+	if len(p.allowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.allowedHosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Proxy returns the proxy for req, or ErrEgressDenied when its host isn't allowed. Transports
+// and dialers call it before connecting, so a refused request never reaches the network.
+// With an address guard, a request sent through a proxy is checked here, since the
+// connection the gateway dials is to the proxy rather than the upstream.
+func (p *EgressPolicy) Proxy(req *http.Request) (*url.URL, error) {
+	if !p.AllowsHost(req.URL.Hostname()) {
+		return nil, fmt.Errorf("%w: %s", ErrEgressDenied, req.URL.Hostname())
+	}
+	proxyURL, err := p.proxy(req)
+	if err != nil || proxyURL == nil || p.guard == nil {
+		return proxyURL, err
+	}
+	if err := p.guard.CheckURL(req.Context(), req.URL.String()); err != nil {
+		return nil, err
+	}
+	p.proxyAddrs.Store(proxyAddr(proxyURL), true)
+	return proxyURL, nil
+}
+
+// proxyAddr returns the host:port a transport dials to reach proxyURL
+func proxyAddr(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		port = "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// SetAddressGuard blocks upstream connections to internal addresses the guard doesn't allow.
+// Proxies the policy sends requests through are exempt. Must be called before the policy
+// is used.
+func (p *EgressPolicy) SetAddressGuard(guard *AddressGuard) {
+	p.guard = guard
+	p.transport.DialContext = p.DialContext
+}
+
+// DialContext connects to addr, checking it with the address guard unless it is a proxy
+func (p *EgressPolicy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if p.guard == nil {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
+	}
+	if _, isProxy := p.proxyAddrs.Load(addr); isProxy {
+		return p.guard.dialer.DialContext(ctx, network, addr)
+	}
+	return p.guard.DialContext(ctx, network, addr)
+}
+
+// apply sends transport's requests through the policy
+func (p *EgressPolicy) apply(transport *http.Transport) {
+	transport.Proxy = p.Proxy
+	if p.guard != nil {
+		transport.DialContext = p.DialContext
+	}
+}
+
+// Transport returns the HTTP transport applying the policy, shared by the clients using it
+func (p *EgressPolicy) Transport() *http.Transport {
+	return p.transport
+}
+
+// SetEgressPolicy routes every upstream request the service and its clients send through
+// policy. Must be called before the service is used.
+func (s *Service) SetEgressPolicy(policy *EgressPolicy) {
+	s.egress = policy
+	if client, ok := s.streamableHTTPClient.(*StreamableHTTPClient); ok {
+		client.SetEgressPolicy(policy)
+	}
+	if client, ok := s.sseClient.(*SSEClient); ok {
+		client.SetEgressPolicy(policy)
+	}
+	if client, ok := s.webSocketClient.(*WebSocketClient); ok {
+		client.SetEgressPolicy(policy)
+	}
+}
+
+// SetEgressPolicy sends the client's requests through policy. Must be called before the
+// client is used.
+func (c *StreamableHTTPClient) SetEgressPolicy(policy *EgressPolicy) {
+	c.httpClient.Transport = policy.Transport()
+}
+
+// SetEgressPolicy sends the client's requests through policy. Must be called before the
+// client is used.
+func (c *SSEClient) SetEgressPolicy(policy *EgressPolicy) {
+	c.httpClient.Transport = policy.Transport()
+}
+
+// SetEgressPolicy sends the client's connections through policy. Must be called before the
+// client is used.
+func (c *WebSocketClient) SetEgressPolicy(policy *EgressPolicy) {
+	c.dialer.Proxy = policy.Proxy
+	if policy.guard != nil {
+		c.dialer.NetDialContext = policy.DialContext
+	}
+}
@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// forwardProxy is an HTTP proxy that records the hosts requested through it and answers
+// for them as an MCP server
+type forwardProxy struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.hosts = append(p.hosts, r.URL.Host)
+	p.mu.Unlock()
+	(&acceptRecorder{accepts: make(map[string]string)}).ServeHTTP(w, r)
+}
+
+func (p *forwardProxy) requested() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.hosts...)
+}
+
+func TestEgressPolicy_AllowsHost(t *testing.T) {
+	policy, err := NewEgressPolicy("", []string{"mcp.internal", "*.Example.com"})
+	require.NoError(t, err)
+
+	assert.True(t, policy.AllowsHost("mcp.internal"))
+	assert.True(t, policy.AllowsHost("MCP.internal."))
+	assert.True(t, policy.AllowsHost("api.example.com"))
+	assert.True(t, policy.AllowsHost("a.b.example.com"))
+	assert.False(t, policy.AllowsHost("example.com"), "a wildcard matches subdomains only")
+	assert.False(t, policy.AllowsHost("badexample.com"))
+	assert.False(t, policy.AllowsHost("169.254.169.254"))
+
+	open, err := NewEgressPolicy("", nil)
+	require.NoError(t, err)
+	assert.True(t, open.AllowsHost("169.254.169.254"), "no allowlist allows any host")
+
+	_, err = NewEgressPolicy("://bad", nil)
+	assert.Error(t, err)
+}
+
+func TestStreamableHTTPClient_EgressPolicy(t *testing.T) {
+	proxy := &forwardProxy{}
+	ts := httptest.NewServer(proxy)
+	defer ts.Close()
+
+	policy, err := NewEgressPolicy(ts.URL, []string{"mcp.internal"})
+	require.NoError(t, err)
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	client.SetEgressPolicy(policy)
+
+	t.Run("allowed host goes through the proxy", func(t *testing.T) {
+		server := &domain.MCPServer{ID: "allowed", URL: "http://mcp.internal/mcp", IsActive: true}
+
+		_, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		_, err = client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"mcp.internal", "mcp.internal", "mcp.internal"}, proxy.requested())
+	})
+
+	t.Run("disallowed host is refused before connecting", func(t *testing.T) {
+		server := &domain.MCPServer{ID: "blocked", URL: "http://metadata.internal/latest", IsActive: true}
+		before := len(proxy.requested())
+
+		_, err := client.Initialize(context.Background(), server)
+
+		require.ErrorIs(t, err, ErrEgressDenied)
+		assert.Len(t, proxy.requested(), before, "nothing reaches the proxy")
+	})
+}
+
+func TestService_EgressPolicyCoversEveryClient(t *testing.T) {
+	policy, err := NewEgressPolicy("", []string{"mcp.internal"})
+	require.NoError(t, err)
+	repo := multiServerRepository{
+		"sse": {ID: "sse", URL: "http://metadata.internal/sse", Transport: domain.TransportSSE, IsActive: true},
+		"ws":  {ID: "ws", URL: "ws://metadata.internal/ws", Transport: domain.TransportWebSocket, IsActive: true},
+	}
+	svc := NewService(repo, logger.NewNopLogger(), nil)
+	t.Cleanup(svc.webSocketClient.(*WebSocketClient).Close)
+	svc.SetEgressPolicy(policy)
+
+	_, err = svc.CallSSE(context.Background(), "sse", "tools/list", nil)
+	assert.ErrorIs(t, err, ErrEgressDenied)
+
+	_, err = svc.CallWebSocket(context.Background(), "ws", "tools/list", nil)
+	assert.ErrorIs(t, err, ErrEgressDenied)
+
+	_, err = svc.HTTPClient(repo["sse"]).Get(repo["sse"].URL)
+	assert.ErrorIs(t, err, ErrEgressDenied)
+}
@@ -0,0 +1,238 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// MethodElicitationCreate is the request a server sends to ask the user for input
+const MethodElicitationCreate = "elicitation/create"
+
+// elicitationPendingTTL is how long a relayed elicitation waits for a client's answer
+// before it is forgotten
+const elicitationPendingTTL = 10 * time.Minute
+
+var (
+	// ErrElicitationDenied is returned when a server's policy doesn't allow elicitation
+	ErrElicitationDenied = errors.New("elicitation is not allowed for this server")
+	// ErrElicitationNotPending is returned when answering an elicitation that wasn't relayed,
+	// has already been answered or has expired
+	ErrElicitationNotPending = errors.New("no pending elicitation with this id")
+)
+
+// elicitationCancelled answers an elicitation no client is listening for
+var elicitationCancelled = json.RawMessage(`{"action":"cancel"}`)
+
+// ElicitationSubscription receives the elicitation/create requests a server sends
+type ElicitationSubscription struct {
+	ServerID string
+
+	requests chan json.RawMessage
+	owner    *resourceSubscriptions
+	closed   bool // Guarded by owner.mu
+}
+
+// Requests returns the channel elicitation requests are delivered on, as full JSON-RPC
+// messages. It is closed when the subscription is closed or the server's session is
+// terminated.
+func (s *ElicitationSubscription) Requests() <-chan json.RawMessage {
+	return s.requests
+}
+
+// Close ends the subscription
+func (s *ElicitationSubscription) Close() {
+	s.owner.removeElicitation(s)
+}
+
+// SubscribeElicitations relays the elicitation/create requests of a Streamable HTTP server
+// whose policy allows elicitation; answer them with RespondElicitation. The server's event
+// stream is opened so requests it sends outside a call's response are relayed too.
+func (s *Service) SubscribeElicitations(ctx context.Context, serverID string) (*ElicitationSubscription, error) {
+	transport, server, err := s.GetTransportType(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if !server.AllowsElicitation() {
+		return nil, ErrElicitationDenied
+	}
+	if transport != domain.TransportStreamableHTTP {
+		return nil, fmt.Errorf("elicitation requires the %s transport", domain.TransportStreamableHTTP)
+	}
+	return s.subscriptions.addElicitation(serverID)
+}
+
+// RespondElicitation sends a client's answer to a relayed elicitation back to the server.
+// Each elicitation takes one answer; later ones get ErrElicitationNotPending.
+func (s *Service) RespondElicitation(ctx context.Context, serverID string, id, result json.RawMessage, rpcErr *JSONRPCError) error {
+	if !s.elicitations.take(serverID, id) {
+		return ErrElicitationNotPending
+	}
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	return s.streamableHTTPClient.Respond(ctx, server, id, result, rpcErr)
+}
+
+// HandleServerRequest reacts to a request a server sends the gateway as its client.
+// elicitation/create is relayed to the server's subscribers when its policy allows;
+// other requests are ignored.
+func (s *Service) HandleServerRequest(serverID, method string, id, msg json.RawMessage) {
+	if method != MethodElicitationCreate {
+		s.logger.Debug().Str("server_id", serverID).Str("method", method).Msg("Ignoring request from server")
+		return
+	}
+	// Called while the server's stream is read, so the lookup and answer happen elsewhere
+	go s.relayElicitation(serverID, id, msg)
+}
+
+// relayElicitation hands an elicitation request to the server's subscribers. The server
+// gets an error if its policy denies elicitation, and a cancel if nobody is listening, so
+// it isn't left waiting for an answer that can't come.
+func (s *Service) relayElicitation(serverID string, id, msg json.RawMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("server_id", serverID).Msg("Failed to look up server for elicitation")
+		return
+	}
+	if !server.AllowsElicitation() {
+		s.logger.Warn().Str("server_id", serverID).Msg("Rejected elicitation from server whose policy denies it")
+		s.answerServerRequest(ctx, server, id, nil, &JSONRPCError{Code: -32601, Message: ErrElicitationDenied.Error()})
+		return
+	}
+
+	s.elicitations.add(serverID, id)
+	if s.subscriptions.dispatchElicitation(serverID, msg) > 0 {
+		return
+	}
+	if s.elicitations.take(serverID, id) {
+		s.logger.Info().Str("server_id", serverID).Msg("Cancelled elicitation, no client is subscribed")
+		s.answerServerRequest(ctx, server, id, elicitationCancelled, nil)
+	}
+}
+
+// answerServerRequest sends the gateway's own answer to a server request
+func (s *Service) answerServerRequest(ctx context.Context, server *domain.MCPServer, id, result json.RawMessage, rpcErr *JSONRPCError) {
+	if err := s.streamableHTTPClient.Respond(ctx, server, id, result, rpcErr); err != nil {
+		s.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Failed to answer server request")
+	}
+}
+
+// addElicitation registers an elicitation subscriber, opening the server's event stream if
+// it isn't already open. Returns ErrTooManySubscribers when the server is at its limit.
+func (r *resourceSubscriptions) addElicitation(serverID string) (*ElicitationSubscription, error) {
+	sub := &ElicitationSubscription{
+		ServerID: serverID,
+		requests: make(chan json.RawMessage, subscriptionBuffer),
+		owner:    r,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fullLocked(serverID) {
+		return nil, ErrTooManySubscribers
+	}
+	server := r.serverLocked(serverID)
+	server.elicitations[sub] = struct{}{}
+	r.recordSubscribers(serverID, server.count())
+	return sub, nil
+}
+
+// removeElicitation drops an elicitation subscriber, stopping the event stream when the
+// server has no subscribers left
+func (r *resourceSubscriptions) removeElicitation(sub *ElicitationSubscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.requests)
+
+	server := r.servers[sub.ServerID]
+	delete(server.elicitations, sub)
+	r.recordSubscribers(sub.ServerID, server.count())
+	if server.empty() {
+		delete(r.servers, sub.ServerID)
+		server.cancel()
+	}
+}
+
+// dispatchElicitation delivers an elicitation request to the server's subscribers and
+// returns how many received it
+func (r *resourceSubscriptions) dispatchElicitation(serverID string, msg json.RawMessage) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	server, ok := r.servers[serverID]
+	if !ok {
+		return 0
+	}
+	delivered := 0
+	for sub := range server.elicitations {
+		select {
+		case sub.requests <- msg:
+			delivered++
+		default:
+			r.service.logger.Debug().Str("server_id", serverID).Msg("Dropped elicitation for slow subscriber")
+		}
+	}
+	return delivered
+}
+
+// pendingElicitations remembers the elicitations relayed to clients, so an answer is only
+// forwarded for a request the server actually sent, and only once
+type pendingElicitations struct {
+	mu      sync.Mutex
+	pending map[string]time.Time // Keyed by server ID and request id, valued by expiry
+	now     func() time.Time
+}
+
+func newPendingElicitations() *pendingElicitations {
+	return &pendingElicitations{
+		pending: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// pendingKey identifies a server's request id
+func pendingKey(serverID string, id json.RawMessage) string {
+	return serverID + "\x00" + string(bytes.TrimSpace(id))
+}
+
+// add records a relayed elicitation, dropping expired ones
+func (p *pendingElicitations) add(serverID string, id json.RawMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for key, expiry := range p.pending {
+		if !now.Before(expiry) {
+			delete(p.pending, key)
+		}
+	}
+	p.pending[pendingKey(serverID, id)] = now.Add(elicitationPendingTTL)
+}
+
+// take removes a pending elicitation, returning false if it wasn't pending or had expired
+func (p *pendingElicitations) take(serverID string, id json.RawMessage) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := pendingKey(serverID, id)
+	expiry, ok := p.pending[key]
+	delete(p.pending, key)
+	return ok && p.now().Before(expiry)
+}
@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// elicitationBackend is a Streamable HTTP server whose tools/call asks the client for
+// input: it streams an elicitation/create request and finishes the call with the answer
+// it gets back
+type elicitationBackend struct {
+	answers chan json.RawMessage
+	stop    chan struct{} // Ends calls still waiting for an answer
+
+	mu           sync.Mutex
+	capabilities json.RawMessage // From the last initialize
+}
+
+func (b *elicitationBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Capabilities json.RawMessage `json:"capabilities"`
+		} `json:"params"`
+	}
+	body := json.RawMessage{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	_ = json.Unmarshal(body, &msg)
+
+	w.Header().Set(HeaderMCPSessionID, "session-1")
+	switch {
+	case msg.Method == "":
+		// The client's answer to our elicitation
+		b.answers <- body
+		w.WriteHeader(http.StatusAccepted)
+	case isNotification(msg.Method):
+		w.WriteHeader(http.StatusAccepted)
+	case msg.Method == "initialize":
+		b.mu.Lock()
+		b.capabilities = msg.Params.Capabilities
+		b.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18","capabilities":{},"serverInfo":{"name":"elicit","version":"1"}}}`, msg.ID)
+	case msg.Method == "tools/call":
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":\"elicit-1\",\"method\":\"elicitation/create\",\"params\":{\"message\":\"What is your name?\",\"requestedSchema\":{\"type\":\"object\",\"properties\":{\"name\":{\"type\":\"string\"}}}}}\n\n")
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.stop:
+			return
+		case answer := <-b.answers:
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"answer\":%s}}\n\n", msg.ID, answer)
+		}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{}}`, msg.ID)
+	}
+}
+
+func (b *elicitationBackend) initializeCapabilities() json.RawMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.capabilities
+}
+
+func newElicitationTestService(t *testing.T, policy domain.ElicitationPolicy) (*Service, *elicitationBackend) {
+	t.Helper()
+	backend := &elicitationBackend{answers: make(chan json.RawMessage, 1), stop: make(chan struct{})}
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+	t.Cleanup(func() { close(backend.stop) })
+
+	repo := multiServerRepository{
+		"server-1": {
+			ID:                "server-1",
+			URL:               ts.URL + "/mcp",
+			Transport:         domain.TransportStreamableHTTP,
+			IsActive:          true,
+			ElicitationPolicy: policy,
+		},
+	}
+	svc := NewService(repo, logger.NewNopLogger(), nil)
+	_, err := svc.InitializeStreamableHTTP(context.Background(), "server-1")
+	require.NoError(t, err)
+	return svc, backend
+}
+
+// callTool runs tools/call in the background and returns a channel with its outcome
+func callTool(svc *Service) <-chan json.RawMessage {
+	done := make(chan json.RawMessage, 1)
+	go func() {
+		result, err := svc.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "ask"})
+		if err != nil {
+			result = json.RawMessage(fmt.Sprintf(`{"error":%q}`, err.Error()))
+		}
+		done <- result
+	}()
+	return done
+}
+
+func receiveResult(t *testing.T, done <-chan json.RawMessage) json.RawMessage {
+	t.Helper()
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for tools/call to finish")
+		return nil
+	}
+}
+
+func TestElicitation_RelayedToSubscriber(t *testing.T) {
+	svc, backend := newElicitationTestService(t, domain.ElicitationAllow)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeElicitations(ctx, "server-1")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	done := callTool(svc)
+
+	var request json.RawMessage
+	select {
+	case request = <-sub.Requests():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for elicitation request")
+	}
+	var msg struct {
+		Method string `json:"method"`
+	}
+	require.NoError(t, json.Unmarshal(request, &msg))
+	assert.Equal(t, MethodElicitationCreate, msg.Method)
+	assert.Contains(t, string(request), `"id":"elicit-1"`)
+	assert.Contains(t, string(request), "What is your name?")
+
+	require.NoError(t, svc.RespondElicitation(ctx, "server-1", json.RawMessage(`"elicit-1"`),
+		json.RawMessage(`{"action":"accept","content":{"name":"Ada"}}`), nil))
+
+	result := receiveResult(t, done)
+	assert.JSONEq(t, `{"answer":{"jsonrpc":"2.0","id":"elicit-1","result":{"action":"accept","content":{"name":"Ada"}}}}`, string(result))
+
+	// Each elicitation takes one answer
+	err = svc.RespondElicitation(ctx, "server-1", json.RawMessage(`"elicit-1"`), json.RawMessage(`{"action":"decline"}`), nil)
+	assert.ErrorIs(t, err, ErrElicitationNotPending)
+
+	// The gateway only advertises elicitation for servers that may use it
+	assert.JSONEq(t, `{"elicitation":{}}`, string(backend.initializeCapabilities()))
+}
+
+func TestElicitation_CancelledWithoutSubscriber(t *testing.T) {
+	svc, _ := newElicitationTestService(t, domain.ElicitationAllow)
+
+	result := receiveResult(t, callTool(svc))
+	assert.JSONEq(t, `{"answer":{"jsonrpc":"2.0","id":"elicit-1","result":{"action":"cancel"}}}`, string(result))
+}
+
+func TestElicitation_DeniedByPolicy(t *testing.T) {
+	svc, backend := newElicitationTestService(t, domain.ElicitationDeny)
+
+	_, err := svc.SubscribeElicitations(context.Background(), "server-1")
+	assert.ErrorIs(t, err, ErrElicitationDenied)
+
+	result := receiveResult(t, callTool(svc))
+	var answer struct {
+		Answer struct {
+			ID    string        `json:"id"`
+			Error *JSONRPCError `json:"error"`
+		} `json:"answer"`
+	}
+	require.NoError(t, json.Unmarshal(result, &answer), string(result))
+	assert.Equal(t, "elicit-1", answer.Answer.ID)
+	require.NotNil(t, answer.Answer.Error)
+	assert.Equal(t, -32601, answer.Answer.Error.Code)
+
+	assert.JSONEq(t, `{}`, string(backend.initializeCapabilities()))
+}
+
+func TestPendingElicitations_Expire(t *testing.T) {
+	pending := newPendingElicitations()
+	now := time.Now()
+	pending.now = func() time.Time { return now }
+
+	pending.add("server-1", json.RawMessage(`1`))
+	pending.add("server-1", json.RawMessage(`2`))
+	assert.False(t, pending.take("server-2", json.RawMessage(`1`)), "ids are per server")
+	assert.True(t, pending.take("server-1", json.RawMessage(` 1 `)))
+	assert.False(t, pending.take("server-1", json.RawMessage(`1`)))
+
+	now = now.Add(elicitationPendingTTL)
+	assert.False(t, pending.take("server-1", json.RawMessage(`2`)))
+}
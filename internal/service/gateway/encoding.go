@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderAcceptEncoding and HeaderContentEncoding negotiate compressed upstream responses
+	HeaderAcceptEncoding  = "Accept-Encoding"
+	HeaderContentEncoding = "Content-Encoding"
+
+	// acceptEncoding is advertised on upstream requests. Setting Accept-Encoding explicitly
+	// disables net/http's transparent decompression, so responses go through decompressBody.
+	acceptEncoding = "gzip, deflate"
+)
+
+// decompressBody replaces resp.Body with a decompressing reader based on Content-Encoding.
+// Absent and identity encodings leave the body unchanged.
+func decompressBody(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get(HeaderContentEncoding)))
+
+	var reader io.ReadCloser
+	switch encoding {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if errors.Is(err, io.EOF) {
+			return nil // Empty body, e.g. 202 Accepted
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decompress gzip response: %w", err)
+		}
+		reader = zr
+	case "deflate":
+		// "deflate" should be zlib-wrapped, but some servers send raw deflate
+		br := bufio.NewReader(resp.Body)
+		header, err := br.Peek(2)
+		if len(header) == 0 && errors.Is(err, io.EOF) {
+			return nil
+		}
+		if isZlibHeader(header) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return fmt.Errorf("failed to decompress deflate response: %w", err)
+			}
+			reader = zr
+		} else {
+			reader = flate.NewReader(br)
+		}
+	default:
+		return fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	resp.Body = &decompressedBody{ReadCloser: reader, raw: resp.Body}
+	resp.Header.Del(HeaderContentEncoding)
+	resp.ContentLength = -1
+	return nil
+}
+
+// isZlibHeader reports whether b starts with a valid zlib (RFC 1950) header
+func isZlibHeader(b []byte) bool {
+	if len(b) < 2 {
+		return false
+	}
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decompressedBody closes both the decompressor and the underlying response body
+type decompressedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+	return err
+}
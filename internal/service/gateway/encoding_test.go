@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// gzipServer serves body gzip-compressed with the given content type
+func gzipServer(t *testing.T, contentType, body string) *httptest.Server {
+	compressed := gzipBytes(t, body)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get(HeaderAcceptEncoding), "gzip")
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set(HeaderContentEncoding, "gzip")
+		w.WriteHeader(http.StatusOK)
+		w.Write(compressed)
+	}))
+}
+
+func TestStreamableHTTPClient_GzipResponse(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	t.Run("JSON response", func(t *testing.T) {
+		ts := gzipServer(t, ContentTypeJSON, `{"jsonrpc":"2.0","result":{"tools":[{"name":"echo"}]},"id":1}`)
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		result, err := client.Call(context.Background(), &domain.MCPServer{ID: "s1", URL: ts.URL}, "tools/list", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"tools":[{"name":"echo"}]}`, string(result))
+	})
+
+	t.Run("SSE response", func(t *testing.T) {
+		ts := gzipServer(t, ContentTypeEventStream, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"ok\":true},\"id\":1}\n\n")
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		result, err := client.Call(context.Background(), &domain.MCPServer{ID: "s1", URL: ts.URL}, "tools/list", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ok":true}`, string(result))
+	})
+}
+
+func TestSSEClient_GzipResponse(t *testing.T) {
+	ts := gzipServer(t, "application/json", `{"jsonrpc":"2.0","result":{"tools":[]},"id":1}`)
+	defer ts.Close()
+
+	client := NewSSEClient(logger.NewNopLogger(), 30*time.Second)
+	result, err := client.Call(context.Background(), &domain.MCPServer{ID: "s1", URL: ts.URL}, "tools/list", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tools":[]}`, string(result))
+}
+
+func TestDecompressBody(t *testing.T) {
+	const payload = `{"jsonrpc":"2.0","result":{},"id":1}`
+
+	zlibData := func() []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write([]byte(payload))
+		zw.Close()
+		return buf.Bytes()
+	}
+	rawDeflate := func() []byte {
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		fw.Write([]byte(payload))
+		fw.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     string
+		wantErr  bool
+	}{
+		{name: "absent", body: []byte(payload), want: payload},
+		{name: "identity", encoding: "identity", body: []byte(payload), want: payload},
+		{name: "gzip", encoding: "gzip", body: gzipBytes(t, payload), want: payload},
+		{name: "deflate zlib", encoding: "deflate", body: zlibData(), want: payload},
+		{name: "deflate raw", encoding: "deflate", body: rawDeflate(), want: payload},
+		{name: "gzip empty body", encoding: "gzip", body: nil, want: ""},
+		{name: "gzip corrupt", encoding: "gzip", body: []byte("not gzip"), wantErr: true},
+		{name: "unsupported", encoding: "br", body: []byte("x"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			if tt.encoding != "" {
+				resp.Header.Set(HeaderContentEncoding, strings.ToUpper(tt.encoding))
+			}
+
+			err := decompressBody(resp)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+			assert.NoError(t, resp.Body.Close())
+		})
+	}
+}
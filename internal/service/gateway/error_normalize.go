@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Gateway JSON-RPC error codes, in the -32000 to -32099 range the spec leaves to
+// implementations. Clients can rely on them staying the same across releases.
+const (
+	CodePayloadTooLarge     = -32000 // A request or upstream response exceeded its size limit
+	CodeUpstreamUnavailable = -32001 // The server couldn't be reached
+	CodeUpstreamTimeout     = -32002 // The server didn't answer in time
+	CodeUpstreamHTTPError   = -32003 // The server answered with an HTTP error status
+	CodeUpstreamRefused     = -32004 // Egress policy or certificate pinning refused the server
+	CodeRateLimited         = -32029 // The server's request limit was exceeded
+)
+
+// errorKinds names each gateway error code in the data of normalized errors
+var errorKinds = map[int]string{
+	CodeParseError:          "parse_error",
+	CodeInvalidRequest:      "invalid_request",
+	CodeMethodNotFound:      "method_not_found",
+	CodeInvalidParams:       "invalid_params",
+	CodeInternalError:       "internal_error",
+	CodePayloadTooLarge:     "payload_too_large",
+	CodeUpstreamUnavailable: "upstream_unavailable",
+	CodeUpstreamTimeout:     "upstream_timeout",
+	CodeUpstreamHTTPError:   "upstream_http_error",
+	CodeUpstreamRefused:     "upstream_refused",
+	CodeRateLimited:         "rate_limited",
+}
+
+// errorMessages are the messages of normalized errors, by code
+var errorMessages = map[int]string{
+	CodeParseError:          "Parse error",
+	CodeInvalidRequest:      "Invalid request",
+	CodeMethodNotFound:      "Method not found",
+	CodeInvalidParams:       "Invalid params",
+	CodeInternalError:       "Internal error",
+	CodePayloadTooLarge:     "Payload too large",
+	CodeUpstreamUnavailable: "Upstream server unavailable",
+	CodeUpstreamTimeout:     "Upstream server timed out",
+	CodeUpstreamHTTPError:   "Upstream server returned an HTTP error",
+	CodeUpstreamRefused:     "Upstream server refused by gateway policy",
+	CodeRateLimited:         "Rate limit exceeded",
+}
+
+// ErrorCode is the JSON-RPC error code for a failed upstream call: the server's own code
+// when it answered with an error, otherwise the gateway code for the kind of failure
+func ErrorCode(err error) int {
+	if rpcErr, ok := AsJSONRPCError(err); ok {
+		return rpcErr.Code
+	}
+
+	var statusErr *StatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrUnknownTool):
+		return CodeMethodNotFound
+	case errors.Is(err, ErrResponseTooLarge):
+		return CodePayloadTooLarge
+	case errors.Is(err, ErrRateLimited):
+		return CodeRateLimited
+	case errors.Is(err, ErrAddressBlocked), errors.Is(err, ErrEgressDenied), errors.Is(err, ErrCertificatePinMismatch):
+		return CodeUpstreamRefused
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return CodeUpstreamTimeout
+	case errors.As(err, &statusErr):
+		return CodeUpstreamHTTPError
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrWebSocketClosed), errors.As(err, new(*net.OpError)), errors.As(err, new(*net.DNSError)):
+		return CodeUpstreamUnavailable
+	}
+	return CodeInternalError
+}
+
+// NormalizeError converts a failed upstream call into a well-formed JSON-RPC error with a
+// stable code and message. Errors the server answered with keep their code and data; other
+// failures get data naming their kind, plus the underlying error as detail when
+// includeDetail is set (it can reveal upstream host names and addresses).
+func NormalizeError(err error, includeDetail bool) *JSONRPCError {
+	if rpcErr, ok := AsJSONRPCError(err); ok {
+		normalized := *rpcErr
+		if normalized.Message == "" {
+			normalized.Message = errorMessage(rpcErr.Code)
+		}
+		return &normalized
+	}
+
+	code := ErrorCode(err)
+	data := map[string]any{"kind": errorKinds[code]}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		data["status"] = statusErr.StatusCode
+	}
+	if includeDetail {
+		data["detail"] = err.Error()
+	}
+	return &JSONRPCError{Code: code, Message: errorMessage(code), Data: data}
+}
+
+// errorMessage is the standard message for code
+func errorMessage(code int) string {
+	if message, ok := errorMessages[code]; ok {
+		return message
+	}
+	return "Server error"
+}
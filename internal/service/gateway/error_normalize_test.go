@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestNormalizeError_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close()) // Nothing listens on addr now
+
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	_, callErr := client.Call(context.Background(), &domain.MCPServer{ID: "down", URL: "http://" + addr + "/mcp", IsActive: true}, "tools/list", nil)
+	require.Error(t, callErr)
+
+	rpcErr := NormalizeError(callErr, true)
+	assert.Equal(t, CodeUpstreamUnavailable, rpcErr.Code)
+	assert.Equal(t, "Upstream server unavailable", rpcErr.Message)
+	assert.Equal(t, map[string]any{"kind": "upstream_unavailable", "detail": callErr.Error()}, rpcErr.Data)
+
+	rpcErr = NormalizeError(callErr, false)
+	assert.Equal(t, map[string]any{"kind": "upstream_unavailable"}, rpcErr.Data, "detail can reveal the upstream address")
+}
+
+func TestNormalizeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	_, statusErr := NewSSEClient(logger.NewNopLogger(), 5*time.Second).Call(context.Background(), &domain.MCPServer{ID: "s", URL: ts.URL + "/mcp", IsActive: true}, "tools/list", nil)
+	require.Error(t, statusErr)
+
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		message string
+		data    any
+	}{
+		{
+			name:    "HTTP error status",
+			err:     statusErr,
+			code:    CodeUpstreamHTTPError,
+			message: "Upstream server returned an HTTP error",
+			data:    map[string]any{"kind": "upstream_http_error", "status": http.StatusServiceUnavailable},
+		},
+		{
+			name:    "timeout",
+			err:     fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			code:    CodeUpstreamTimeout,
+			message: "Upstream server timed out",
+			data:    map[string]any{"kind": "upstream_timeout"},
+		},
+		{
+			name:    "blocked address",
+			err:     fmt.Errorf("%w: 127.0.0.1", ErrAddressBlocked),
+			code:    CodeUpstreamRefused,
+			message: "Upstream server refused by gateway policy",
+			data:    map[string]any{"kind": "upstream_refused"},
+		},
+		{
+			name:    "rate limited",
+			err:     ErrInitializeThrottled,
+			code:    CodeRateLimited,
+			message: "Rate limit exceeded",
+			data:    map[string]any{"kind": "rate_limited"},
+		},
+		{
+			name:    "unclassified",
+			err:     fmt.Errorf("failed to parse response: unexpected EOF"),
+			code:    CodeInternalError,
+			message: "Internal error",
+			data:    map[string]any{"kind": "internal_error"},
+		},
+		{
+			name:    "server error keeps its code and data",
+			err:     fmt.Errorf("call failed: %w", &JSONRPCError{Code: -32042, Message: "Quota exhausted", Data: "retry tomorrow"}),
+			code:    -32042,
+			message: "Quota exhausted",
+			data:    "retry tomorrow",
+		},
+		{
+			name:    "server error without a message gets the standard one",
+			err:     &JSONRPCError{Code: CodeInvalidParams},
+			code:    CodeInvalidParams,
+			message: "Invalid params",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcErr := NormalizeError(tt.err, false)
+			assert.Equal(t, tt.code, rpcErr.Code)
+			assert.Equal(t, tt.message, rpcErr.Message)
+			assert.Equal(t, tt.data, rpcErr.Data)
+		})
+	}
+}
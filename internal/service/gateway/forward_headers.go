@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// requiredForwardHeaders are client headers MCP needs end to end, forwarded even when a
+// server has a header allowlist
+var requiredForwardHeaders = []string{
+	"Accept",
+	"Content-Type",
+	"Content-Encoding",
+	"Mcp-Session-Id",
+	"Mcp-Protocol-Version",
+	"Last-Event-Id",
+}
+
+// filterForwardHeaders strips the client headers a server didn't allowlist in
+// ForwardHeaders. Servers without an allowlist receive every client header.
+func filterForwardHeaders(header http.Header, server *domain.MCPServer) {
+	if len(server.ForwardHeaders) == 0 {
+		return
+	}
+
+	allowed := make(map[string]bool, len(requiredForwardHeaders)+len(server.ForwardHeaders))
+	for _, name := range requiredForwardHeaders {
+		allowed[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range server.ForwardHeaders {
+		allowed[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range header {
+		if !allowed[http.CanonicalHeaderKey(name)] {
+			header.Del(name)
+		}
+	}
+}
+
+// ValidateForwardHeader rejects names that can't be HTTP header field names
+func ValidateForwardHeader(name string) error {
+	if name == "" {
+		return fmt.Errorf("header name is empty")
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return fmt.Errorf("%q is not a valid header name", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// proxyHeaders sends a request with the given client headers through the proxy for server
+// and returns the headers the backend received
+func proxyHeaders(t *testing.T, server *domain.MCPServer, clientHeaders map[string]string) http.Header {
+	t.Helper()
+
+	received := make(chan http.Header, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	}))
+	defer backend.Close()
+
+	server.URL = backend.URL
+	server.IsActive = true
+	svc := NewServiceWithClients(&mockServerRepository{server: server}, logger.NewNopLogger(), nil, nil, nil)
+	proxy, _, err := svc.ProxyToServer(context.Background(), server.ID)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v1/gateway/"+server.ID+"/mcp", nil)
+	for name, value := range clientHeaders {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	return <-received
+}
+
+func TestProxyToServer_ForwardHeaders(t *testing.T) {
+	clientHeaders := map[string]string{
+		"X-Tenant-Id":    "tenant-1",
+		"X-Debug":        "true",
+		"Cookie":         "mcp_session=secret",
+		"Authorization":  "Bearer gateway-api-key",
+		"Content-Type":   "application/json",
+		"Mcp-Session-Id": "session-1",
+	}
+
+	t.Run("only allowlisted and required headers reach the backend", func(t *testing.T) {
+		got := proxyHeaders(t, &domain.MCPServer{ID: "server-1", ForwardHeaders: []string{"x-tenant-id"}}, clientHeaders)
+
+		assert.Equal(t, "tenant-1", got.Get("X-Tenant-Id"))
+		assert.Equal(t, "application/json", got.Get("Content-Type"))
+		assert.Equal(t, "session-1", got.Get("Mcp-Session-Id"))
+		assert.Empty(t, got.Get("X-Debug"))
+		assert.Empty(t, got.Get("Cookie"))
+		assert.Empty(t, got.Get("Authorization"))
+	})
+
+	t.Run("the server's own auth replaces the stripped client auth", func(t *testing.T) {
+		got := proxyHeaders(t, &domain.MCPServer{
+			ID:             "server-1",
+			ForwardHeaders: []string{"X-Tenant-Id"},
+			AuthType:       domain.ServerAuthBearer,
+			AuthConfig:     []byte(`{"token":"backend-token"}`),
+		}, clientHeaders)
+
+		assert.Equal(t, "Bearer backend-token", got.Get("Authorization"))
+	})
+
+	t.Run("without an allowlist every client header is forwarded", func(t *testing.T) {
+		got := proxyHeaders(t, &domain.MCPServer{ID: "server-1"}, clientHeaders)
+
+		assert.Equal(t, "tenant-1", got.Get("X-Tenant-Id"))
+		assert.Equal(t, "true", got.Get("X-Debug"))
+	})
+}
+
+func TestValidateForwardHeader(t *testing.T) {
+	assert.NoError(t, ValidateForwardHeader("X-Tenant-Id"))
+	assert.NoError(t, ValidateForwardHeader("x_custom.header"))
+	assert.Error(t, ValidateForwardHeader(""))
+	assert.Error(t, ValidateForwardHeader("X Tenant"))
+	assert.Error(t, ValidateForwardHeader("X-Tenant:"))
+}
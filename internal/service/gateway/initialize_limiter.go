@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInitializeThrottled is returned when a server has been initialized too often in the
+// last minute. It wraps ErrRateLimited.
+var ErrInitializeThrottled = fmt.Errorf("initialize %w", ErrRateLimited)
+
+// initializeLimiter caps how often each server is initialized over a sliding one-minute
+// window, so a backend that keeps dropping sessions isn't re-initialized in a tight loop.
+// It is safe for concurrent use.
+type initializeLimiter struct {
+	limit int // Initializes per server per minute (0 = unlimited)
+	now   func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+}
+
+func newInitializeLimiter(limit int) *initializeLimiter {
+	return &initializeLimiter{
+		limit:    limit,
+		now:      time.Now,
+		counters: make(map[string]*windowCounter),
+	}
+}
+
+// allow records an initialize of the server, or returns an error wrapping
+// ErrInitializeThrottled without recording it if the server is over its limit
+func (l *initializeLimiter) allow(serverID string) error {
+	if l == nil || l.limit <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= requestWindow {
+		l.lastSweep = now
+		for id, w := range l.counters {
+			if now.Sub(w.start) >= 2*requestWindow {
+				delete(l.counters, id)
+			}
+		}
+	}
+
+	w, ok := l.counters[serverID]
+	if !ok {
+		w = &windowCounter{start: now}
+		l.counters[serverID] = w
+	}
+	w.advance(now)
+	if w.estimate(now)+1 > float64(l.limit) {
+		return fmt.Errorf("%w: server %s allows %d initializes per minute", ErrInitializeThrottled, serverID, l.limit)
+	}
+	w.current++
+	return nil
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestInitializeLimiter(t *testing.T) {
+	limiter := newInitializeLimiter(3)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.allow("server-1"), "initialize %d", i)
+	}
+	err := limiter.allow("server-1")
+	assert.ErrorIs(t, err, ErrInitializeThrottled)
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// Each server has its own budget
+	assert.NoError(t, limiter.allow("server-2"))
+
+	// Once the window has slid past the burst, initializes are allowed again
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, limiter.allow("server-1"))
+}
+
+func TestInitializeLimiter_Unlimited(t *testing.T) {
+	limiter := newInitializeLimiter(0)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, limiter.allow("server-1"))
+	}
+	assert.Empty(t, limiter.counters)
+
+	var nilLimiter *initializeLimiter
+	assert.NoError(t, nilLimiter.allow("server-1"))
+}
+
+func TestStreamableHTTPClient_ThrottlesReinitialize(t *testing.T) {
+	// A backend that forgets every session: each call gets 404 and triggers a re-initialize
+	var initializes atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(string(body), `"initialize"`):
+			initializes.Add(1)
+			w.Header().Set(HeaderMCPSessionID, "session-1")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25"}}`))
+		case strings.Contains(string(body), `"notifications/initialized"`):
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	client.SetMaxInitializesPerMinute(3)
+	server := &domain.MCPServer{ID: "server-1", URL: ts.URL, Transport: domain.TransportStreamableHTTP, RequireInitialize: true}
+
+	// The first initialize, then each call re-initializes once before giving up on the 404
+	for i := 0; i < 2; i++ {
+		_, err := client.Call(context.Background(), server, "tools/call", map[string]interface{}{"name": "search"})
+		assert.ErrorIs(t, err, ErrSessionExpired)
+	}
+	_, err := client.Call(context.Background(), server, "tools/call", map[string]interface{}{"name": "search"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInitializeThrottled)
+	assert.Equal(t, int32(3), initializes.Load(), "re-initializes stop at the configured rate")
+
+	// Further rapid attempts within the window don't reach the backend's initialize
+	for i := 0; i < 5; i++ {
+		_, err = client.Call(context.Background(), server, "tools/call", map[string]interface{}{"name": "search"})
+		assert.ErrorIs(t, err, ErrInitializeThrottled)
+		_, err = client.Initialize(context.Background(), server)
+		assert.ErrorIs(t, err, ErrInitializeThrottled)
+	}
+	assert.Equal(t, int32(3), initializes.Load())
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// handshakeBackend answers initialize and tools/list, failing the initialized
+// notification with status for its first failures deliveries (-1 = always)
+type handshakeBackend struct {
+	status   int
+	failures int
+
+	mu      sync.Mutex
+	methods []string
+}
+
+func (b *handshakeBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+
+	b.mu.Lock()
+	b.methods = append(b.methods, msg.Method)
+	fail := msg.Method == "notifications/initialized" && b.failures != 0
+	if fail && b.failures > 0 {
+		b.failures--
+	}
+	b.mu.Unlock()
+
+	switch {
+	case fail:
+		http.Error(w, "not ready", b.status)
+	case msg.Method == "initialize":
+		w.Header().Set(HeaderMCPSessionID, "session-1")
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+	case isNotification(msg.Method):
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID)
+	}
+}
+
+func (b *handshakeBackend) received() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.methods...)
+}
+
+func newHandshakeClient(t *testing.T, backend *handshakeBackend) (*StreamableHTTPClient, *domain.MCPServer) {
+	t.Helper()
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	client.SetRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, InitializedRetries: 2})
+	return client, &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+}
+
+func TestStreamableHTTPClient_InitializedNotificationRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries a transient failure", func(t *testing.T) {
+		backend := &handshakeBackend{status: http.StatusServiceUnavailable, failures: 1}
+		client, server := newHandshakeClient(t, backend)
+
+		session, err := client.Initialize(ctx, server)
+		require.NoError(t, err)
+		assert.True(t, session.isInitialized())
+		assert.Equal(t, []string{"initialize", "notifications/initialized", "notifications/initialized"}, backend.received())
+
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("marks the session not initialized when it keeps failing", func(t *testing.T) {
+		backend := &handshakeBackend{status: http.StatusServiceUnavailable, failures: -1}
+		client, server := newHandshakeClient(t, backend)
+
+		session, err := client.Initialize(ctx, server)
+		require.NoError(t, err)
+		assert.False(t, session.isInitialized())
+		assert.Len(t, backend.received(), 4, "initialize and three notifications")
+
+		// The notification is sent again before the next request, which isn't sent
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		assert.ErrorIs(t, err, ErrSessionNotInitialized)
+		assert.NotContains(t, backend.received(), "tools/list")
+
+		// Once the backend is ready the session is usable
+		backend.mu.Lock()
+		backend.failures = 0
+		backend.mu.Unlock()
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.True(t, session.isInitialized())
+	})
+
+	t.Run("other failures are not retried", func(t *testing.T) {
+		backend := &handshakeBackend{status: http.StatusMethodNotAllowed, failures: -1}
+		client, server := newHandshakeClient(t, backend)
+
+		session, err := client.Initialize(ctx, server)
+		require.NoError(t, err)
+		assert.True(t, session.isInitialized(), "some servers reject the notification")
+		assert.Equal(t, []string{"initialize", "notifications/initialized"}, backend.received())
+	})
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// JSONRPCError represents a JSON-RPC 2.0 error. The clients' Call methods return it, as is
+// or wrapped, when the server answers with an error response.
+type JSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Error implements error so callers can inspect the code and data with errors.As
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// AsJSONRPCError returns the JSON-RPC error the server answered with, if err carries one
+func AsJSONRPCError(err error) (*JSONRPCError, bool) {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr, true
+	}
+	return nil, false
+}
+
+// StatusError is returned when a server answers a JSON-RPC request with an HTTP error
+// status instead of a JSON-RPC response
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.StatusCode, e.Body)
+}
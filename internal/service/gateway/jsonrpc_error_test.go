@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestAsJSONRPCError(t *testing.T) {
+	rpcErr := &JSONRPCError{Code: CodeInvalidParams, Message: "Invalid params", Data: "missing query"}
+
+	got, ok := AsJSONRPCError(fmt.Errorf("call failed: %w", rpcErr))
+	require.True(t, ok)
+	assert.Same(t, rpcErr, got)
+	assert.Equal(t, "MCP error -32602: Invalid params", rpcErr.Error())
+
+	_, ok = AsJSONRPCError(fmt.Errorf("server returned %d", http.StatusBadGateway))
+	assert.False(t, ok)
+}
+
+func TestClients_ReturnTypedJSONRPCErrors(t *testing.T) {
+	// Answers initialize, and every other request with an error carrying data
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     *int   `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case msg.ID == nil:
+			w.WriteHeader(http.StatusAccepted)
+		case msg.Method == "initialize":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"protocolVersion":"2025-11-25","capabilities":{},"serverInfo":{"name":"mock","version":"1"}}}`, *msg.ID)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32602,"message":"Invalid params","data":{"field":"query"}}}`, *msg.ID)
+		}
+	}))
+	defer ts.Close()
+
+	clients := map[string]func(server *domain.MCPServer) error{
+		"sse": func(server *domain.MCPServer) error {
+			_, err := NewSSEClient(logger.NewNopLogger(), 5*time.Second).Call(context.Background(), server, "tools/call", nil)
+			return err
+		},
+		"streamable_http": func(server *domain.MCPServer) error {
+			_, err := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second).Call(context.Background(), server, "tools/call", nil)
+			return err
+		},
+	}
+	for name, call := range clients {
+		t.Run(name, func(t *testing.T) {
+			err := call(&domain.MCPServer{ID: name, URL: ts.URL + "/mcp", IsActive: true})
+
+			rpcErr, ok := AsJSONRPCError(err)
+			require.True(t, ok, "got %v", err)
+			assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+			assert.Equal(t, "Invalid params", rpcErr.Message)
+			assert.Equal(t, map[string]interface{}{"field": "query"}, rpcErr.Data)
+			assert.EqualError(t, err, "MCP error -32602: Invalid params")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// idRecordingBackend answers every JSON-RPC request, echoing its id, and records the raw
+// id of each request by method
+func idRecordingBackend(t *testing.T) (*httptest.Server, func(method string) json.RawMessage) {
+	t.Helper()
+
+	var mu sync.Mutex
+	ids := make(map[string]json.RawMessage)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		ids[req.Method] = req.ID
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":{}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, func(method string) json.RawMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return ids[method]
+	}
+}
+
+// assertIDType checks that a raw JSON-RPC id is a JSON number or string as configured
+func assertIDType(t *testing.T, idType domain.JSONRPCIDType, id json.RawMessage) {
+	t.Helper()
+
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal(id, &decoded), "id %s", id)
+	switch idType {
+	case domain.JSONRPCIDString:
+		assert.IsType(t, "", decoded, "id %s", id)
+	default:
+		assert.IsType(t, float64(0), decoded, "id %s", id)
+	}
+}
+
+func TestJSONRPCIDType(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	for _, idType := range []domain.JSONRPCIDType{"", domain.JSONRPCIDNumber, domain.JSONRPCIDString} {
+		t.Run("streamable http "+string(idType), func(t *testing.T) {
+			backend, idOf := idRecordingBackend(t)
+			server := &domain.MCPServer{ID: "server-1", URL: backend.URL, JSONRPCIDType: idType}
+			client := NewStreamableHTTPClient(log, 5*time.Second)
+
+			_, err := client.Initialize(context.Background(), server)
+			require.NoError(t, err)
+			_, err = client.Call(context.Background(), server, "tools/list", nil)
+			require.NoError(t, err)
+
+			assertIDType(t, idType, idOf("initialize"))
+			assertIDType(t, idType, idOf("tools/list"))
+		})
+
+		t.Run("streamable http batch "+string(idType), func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var batch []struct {
+					ID json.RawMessage `json:"id"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+				require.Len(t, batch, 1)
+				assertIDType(t, idType, batch[0].ID)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"jsonrpc":"2.0","id":` + string(batch[0].ID) + `,"result":{}}]`))
+			}))
+			defer backend.Close()
+
+			server := &domain.MCPServer{ID: "server-1", URL: backend.URL, JSONRPCIDType: idType}
+			responses, err := NewStreamableHTTPClient(log, 5*time.Second).CallBatch(context.Background(), server, []JSONRPCRequest{{Method: "tools/list"}})
+			require.NoError(t, err)
+			require.Len(t, responses, 1)
+			assert.Nil(t, responses[0].Error)
+		})
+
+		t.Run("sse "+string(idType), func(t *testing.T) {
+			backend, idOf := idRecordingBackend(t)
+			server := &domain.MCPServer{ID: "server-1", URL: backend.URL, JSONRPCIDType: idType}
+
+			_, err := NewSSEClient(log, 5*time.Second).Call(context.Background(), server, "tools/list", nil)
+			require.NoError(t, err)
+
+			assertIDType(t, idType, idOf("tools/list"))
+		})
+	}
+}
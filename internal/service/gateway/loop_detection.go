@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HeaderGatewayHops lists the IDs of the gateways a proxied request has passed through.
+// Each gateway appends its own ID before sending the request upstream and rejects
+// requests that already carry it, so a backend URL that leads back to the gateway,
+// directly or through other gateways, fails on the second pass instead of looping.
+const HeaderGatewayHops = "X-Waffles-Gateway-Hops"
+
+// gatewayHopsKey is the context key for the hops value sent with upstream requests
+type gatewayHopsKey struct{}
+
+// LoopGuard identifies this gateway to detect requests that loop back through it
+type LoopGuard struct {
+	id        string
+	selfHosts map[string]bool
+}
+
+// NewLoopGuard creates a guard for the gateway identified by id. selfURLs are addresses
+// that reach this gateway; servers whose URL has the same host and port are rejected.
+// Replicas behind one address should share an id so a loop through another replica is
+// also caught.
+func NewLoopGuard(id string, selfURLs ...string) (*LoopGuard, error) {
+	if id == "" {
+		return nil, fmt.Errorf("gateway id is empty")
+	}
+	if strings.ContainsAny(id, ", ") {
+		return nil, fmt.Errorf("gateway id %q contains a comma or space", id)
+	}
+
+	g := &LoopGuard{id: id, selfHosts: make(map[string]bool)}
+	for _, raw := range selfURLs {
+		key, ok := hostKey(raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid gateway address %q", raw)
+		}
+		g.selfHosts[key] = true
+	}
+	return g, nil
+}
+
+// ID returns the identifier this gateway adds to HeaderGatewayHops
+func (g *LoopGuard) ID() string {
+	return g.id
+}
+
+// Seen reports whether a request's HeaderGatewayHops value shows it already passed
+// through this gateway
+func (g *LoopGuard) Seen(hops string) bool {
+	for _, hop := range strings.Split(hops, ",") {
+		if strings.TrimSpace(hop) == g.id {
+			return true
+		}
+	}
+	return false
+}
+
+// NextHops returns the HeaderGatewayHops value for requests this gateway sends upstream
+// while handling a request that carried hops
+func (g *LoopGuard) NextHops(hops string) string {
+	if strings.TrimSpace(hops) == "" {
+		return g.id
+	}
+	return hops + ", " + g.id
+}
+
+// IsSelf reports whether serverURL points at one of this gateway's own addresses
+func (g *LoopGuard) IsSelf(serverURL string) bool {
+	key, ok := hostKey(serverURL)
+	return ok && g.selfHosts[key]
+}
+
+// ListenURLs returns the URLs that reach a gateway listening on host:port. A wildcard
+// host is reachable through the loopback names.
+func ListenURLs(host string, port int) []string {
+	hosts := []string{host}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+
+	urls := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		urls = append(urls, "http://"+net.JoinHostPort(h, strconv.Itoa(port)))
+	}
+	return urls
+}
+
+// hostKey returns the lowercase host and port of rawURL, filling in the scheme's
+// default port
+func hostKey(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http", "ws":
+			port = "80"
+		case "https", "wss":
+			port = "443"
+		default:
+			return "", false
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port), true
+}
+
+// WithGatewayHops returns a context whose upstream requests carry hops in
+// HeaderGatewayHops
+func WithGatewayHops(ctx context.Context, hops string) context.Context {
+	return context.WithValue(ctx, gatewayHopsKey{}, hops)
+}
+
+// SetGatewayHops adds the hops recorded in the request's context, if any, to req
+func SetGatewayHops(req *http.Request) {
+	if hops, ok := req.Context().Value(gatewayHopsKey{}).(string); ok && hops != "" {
+		req.Header.Set(HeaderGatewayHops, hops)
+	}
+}
@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopGuard_IsSelf(t *testing.T) {
+	guard, err := NewLoopGuard("gateway-a", append(ListenURLs("0.0.0.0", 8080), "https://gateway.example.com")...)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		url  string
+		want bool
+	}{
+		{url: "http://localhost:8080/api/v1/gateway/server-1", want: true},
+		{url: "http://127.0.0.1:8080/mcp", want: true},
+		{url: "http://[::1]:8080", want: true},
+		{url: "https://Gateway.Example.com:443/api/v1/gateway/server-1", want: true},
+		{url: "https://gateway.example.com/mcp", want: true},
+		{url: "http://localhost:9090/mcp", want: false},
+		{url: "http://gateway.example.com/mcp", want: false},
+		{url: "https://mcp.example.com/mcp", want: false},
+		{url: "not a url", want: false},
+	} {
+		assert.Equal(t, tt.want, guard.IsSelf(tt.url), tt.url)
+	}
+}
+
+func TestLoopGuard_Hops(t *testing.T) {
+	guard, err := NewLoopGuard("gateway-a")
+	require.NoError(t, err)
+
+	assert.Equal(t, "gateway-a", guard.NextHops(""))
+	assert.Equal(t, "gateway-b, gateway-a", guard.NextHops("gateway-b"))
+	assert.False(t, guard.Seen(""))
+	assert.False(t, guard.Seen("gateway-b, gateway-ab"))
+	assert.True(t, guard.Seen("gateway-b,gateway-a"))
+}
+
+func TestNewLoopGuard_Invalid(t *testing.T) {
+	_, err := NewLoopGuard("")
+	assert.Error(t, err)
+	_, err = NewLoopGuard("gateway a")
+	assert.Error(t, err)
+	_, err = NewLoopGuard("gateway-a", "ftp://gateway.example.com")
+	assert.Error(t, err)
+}
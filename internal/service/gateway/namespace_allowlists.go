@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// NamespaceAllowlistLookup returns the tool allowlists of the namespaces a server belongs
+// to, leaving out namespaces that allow all tools
+type NamespaceAllowlistLookup interface {
+	GetServerToolAllowlists(ctx context.Context, serverID string) ([][]string, error)
+}
+
+// namespaceAllowlistRepository fills in NamespaceAllowedTools on the servers it returns
+type namespaceAllowlistRepository struct {
+	ServerRepository
+	namespaces NamespaceAllowlistLookup
+}
+
+// replicaNamespaceAllowlistRepository keeps the wrapped repository's ReplicaLister
+type replicaNamespaceAllowlistRepository struct {
+	*namespaceAllowlistRepository
+	ReplicaLister
+}
+
+// WithNamespaceAllowlists wraps repo so every server it returns carries the tool allowlists
+// of its namespaces, which the gateway enforces on top of the server's own AllowedTools
+func WithNamespaceAllowlists(repo ServerRepository, namespaces NamespaceAllowlistLookup) ServerRepository {
+	wrapped := &namespaceAllowlistRepository{ServerRepository: repo, namespaces: namespaces}
+	if lister, ok := repo.(ReplicaLister); ok {
+		return &replicaNamespaceAllowlistRepository{namespaceAllowlistRepository: wrapped, ReplicaLister: lister}
+	}
+	return wrapped
+}
+
+// Get returns the server with its namespace allowlists. A failed lookup fails the Get
+// rather than returning a server whose tools look unrestricted.
+func (r *namespaceAllowlistRepository) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
+	server, err := r.ServerRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowlists, err := r.namespaces.GetServerToolAllowlists(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace tool allowlists: %w", err)
+	}
+	scoped := *server
+	scoped.NamespaceAllowedTools = allowlists
+	return &scoped, nil
+}
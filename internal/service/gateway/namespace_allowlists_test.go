@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// namespaceAllowlists serves tool allowlists by server ID
+type namespaceAllowlists struct {
+	lists map[string][][]string
+	err   error
+}
+
+func (n *namespaceAllowlists) GetServerToolAllowlists(ctx context.Context, serverID string) ([][]string, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	return n.lists[serverID], nil
+}
+
+func TestWithNamespaceAllowlists(t *testing.T) {
+	servers := multiServerRepository{
+		"github": {ID: "github", AllowedTools: []string{"search", "delete"}},
+	}
+	namespaces := &namespaceAllowlists{lists: map[string][][]string{"github": {{"search"}}}}
+	repo := WithNamespaceAllowlists(servers, namespaces)
+
+	server, err := repo.Get(context.Background(), "github")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"search"}}, server.NamespaceAllowedTools)
+	assert.Nil(t, servers["github"].NamespaceAllowedTools, "the wrapped repository's server is left alone")
+	_, isLister := repo.(ReplicaLister)
+	assert.False(t, isLister)
+
+	namespaces.err = errors.New("db down")
+	_, err = repo.Get(context.Background(), "github")
+	assert.Error(t, err, "a failed lookup doesn't leave the server unrestricted")
+
+	replicated := WithNamespaceAllowlists(&replicaRepository{servers: servers}, namespaces)
+	_, isLister = replicated.(ReplicaLister)
+	assert.True(t, isLister, "replica listing is kept")
+}
+
+func TestAggregateToolsList_NamespaceAllowlist(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search"},{"name":"delete"}]}}`))
+	}))
+	defer backend.Close()
+
+	servers := multiServerRepository{
+		// The server itself allows delete; its namespace doesn't
+		"github": {ID: "github", URL: backend.URL, Transport: domain.TransportStreamableHTTP, AllowedTools: []string{"search", "delete"}, IsActive: true},
+		"jira":   {ID: "jira", URL: backend.URL, Transport: domain.TransportStreamableHTTP, ToolPrefix: "jira", IsActive: true},
+	}
+	namespaces := &namespaceAllowlists{lists: map[string][][]string{
+		"github": {{"search"}},
+		"jira":   {{"search", "fetch"}},
+	}}
+	log := logger.NewNopLogger()
+	svc := NewServiceWithClients(WithNamespaceAllowlists(servers, namespaces), log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+
+	result, err := svc.AggregateToolsList(context.Background(), []string{"github", "jira"}, AggregationBestEffort)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range result.Tools {
+		var name string
+		require.NoError(t, json.Unmarshal(tool["name"], &name))
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"search", "jira__search"}, names)
+}
@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// MethodToolsListChanged is the notification a server sends when its tools list changes
+const MethodToolsListChanged = "notifications/tools/list_changed"
+
+// maxNotificationLine bounds how much of a single SSE line or event is buffered while reading
+const maxNotificationLine = 1 << 20
+
+// NotificationFunc is called for each JSON-RPC notification received from a server, with
+// the notification's method and full message
+type NotificationFunc func(serverID, method string, msg json.RawMessage)
+
+// ServerRequestFunc is called for each JSON-RPC request a server sends to the gateway as
+// its client, such as elicitation/create, with the request's method, id and full message
+type ServerRequestFunc func(serverID, method string, id, msg json.RawMessage)
+
+// notificationReader passes an SSE stream through unchanged while reporting the
+// JSON-RPC notifications and server requests it carries
+type notificationReader struct {
+	body      io.ReadCloser
+	serverID  string
+	notify    NotificationFunc
+	onRequest ServerRequestFunc
+	line      []byte
+	skipping  bool // Current line exceeded maxNotificationLine and is ignored
+}
+
+// watchNotifications wraps an SSE response body so notify is called for every notification in it
+func watchNotifications(body io.ReadCloser, serverID string, notify NotificationFunc) io.ReadCloser {
+	return watchServerMessages(body, serverID, notify, nil)
+}
+
+// watchServerMessages wraps an SSE response body so notify is called for every
+// notification in it and onRequest for every request the server makes of its client.
+// Either func may be nil.
+func watchServerMessages(body io.ReadCloser, serverID string, notify NotificationFunc, onRequest ServerRequestFunc) io.ReadCloser {
+	if notify == nil && onRequest == nil {
+		return body
+	}
+	return &notificationReader{body: body, serverID: serverID, notify: notify, onRequest: onRequest}
+}
+
+func (r *notificationReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.scan(p[:n])
+	return n, err
+}
+
+func (r *notificationReader) Close() error {
+	return r.body.Close()
+}
+
+// scan splits data into lines and inspects each complete "data:" line
+func (r *notificationReader) scan(data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.buffer(data)
+			return
+		}
+		r.buffer(data[:i])
+		if !r.skipping {
+			r.inspect(r.line)
+		}
+		r.line = r.line[:0]
+		r.skipping = false
+		data = data[i+1:]
+	}
+}
+
+func (r *notificationReader) buffer(data []byte) {
+	if r.skipping {
+		return
+	}
+	if len(r.line)+len(data) > maxNotificationLine {
+		r.line = r.line[:0]
+		r.skipping = true
+		return
+	}
+	r.line = append(r.line, data...)
+}
+
+// inspect reports the line's message if it is a JSON-RPC notification (a method without
+// an id) or a server request (a method with an id)
+func (r *notificationReader) inspect(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if !bytes.HasPrefix(line, []byte("data:")) {
+		return
+	}
+
+	data := bytes.TrimSpace(line[len("data:"):])
+	var msg struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+	switch {
+	case msg.Method == "":
+	case msg.ID == nil && r.notify != nil:
+		r.notify(r.serverID, msg.Method, append(json.RawMessage(nil), data...))
+	case msg.ID != nil && r.onRequest != nil:
+		r.onRequest(r.serverID, msg.Method, msg.ID, append(json.RawMessage(nil), data...))
+	}
+}
@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestWatchNotifications(t *testing.T) {
+	stream := "event: message\n" +
+		"data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n" +
+		"data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"tools\":[]}}\r\n\r\n" +
+		"data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n"
+
+	var mu sync.Mutex
+	var methods []string
+	body := watchNotifications(io.NopCloser(iotest.OneByteReader(strings.NewReader(stream))), "server-1", func(serverID, method string, _ json.RawMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "server-1", serverID)
+		methods = append(methods, method)
+	})
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+
+	assert.Equal(t, stream, string(data), "stream passes through unchanged")
+	assert.Equal(t, []string{MethodToolsListChanged, "notifications/progress"}, methods)
+}
+
+func TestWatchNotifications_NilFunc(t *testing.T) {
+	body := io.NopCloser(strings.NewReader("data: {}\n"))
+	assert.Equal(t, body, watchNotifications(body, "server-1", nil))
+}
+
+func TestHandleNotification_InvalidatesToolsCache(t *testing.T) {
+	mockStreamable := &mockStreamableHTTPClient{}
+	svc := NewServiceWithClients(&mockServerRepository{}, logger.NewNopLogger(), nil, nil, mockStreamable)
+	svc.tools.Set("server-1", []string{"search"})
+
+	svc.HandleNotification("server-1", MethodToolsListChanged, nil)
+
+	_, cached := svc.tools.Lookup("server-1", "search")
+	assert.False(t, cached)
+	assert.Equal(t, 0, mockStreamable.callCount(), "no refetch without warming")
+}
+
+func TestProxyToServer_WarmsToolsCacheOnListChanged(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		w.Write([]byte("data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n"))
+	}))
+	defer backend.Close()
+
+	server := &domain.MCPServer{
+		ID:        "server-1",
+		Name:      "backend",
+		URL:       backend.URL,
+		Transport: domain.TransportStreamableHTTP,
+		IsActive:  true,
+	}
+	mockStreamable := &mockStreamableHTTPClient{
+		callResult: json.RawMessage(`{"tools":[{"name":"search"},{"name":"fetch"}]}`),
+	}
+	svc := NewServiceWithClients(&mockServerRepository{server: server}, logger.NewNopLogger(), nil, nil, mockStreamable)
+	svc.warmToolsList = true
+	svc.tools.Set("server-1", []string{"search"})
+
+	proxy, _, err := svc.ProxyToServer(context.Background(), "server-1")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/gateway/server-1/mcp", nil)
+	proxy.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The backend is refetched before any client asks for tools/list again
+	require.Eventually(t, func() bool {
+		found, cached := svc.tools.Lookup("server-1", "fetch")
+		return cached && found
+	}, time.Second, 5*time.Millisecond, "tools cache should be refreshed proactively")
+	assert.Equal(t, 1, mockStreamable.callCount())
+}
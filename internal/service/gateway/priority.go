@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// PriorityHint is a request priority forwarded to servers that schedule work by it
+type PriorityHint struct {
+	Value   string // e.g. "high"
+	Header  string // Header carrying Value (empty = not sent as a header)
+	MetaKey string // Key of Value in the request params' _meta (empty = not sent in _meta)
+}
+
+type priorityHintKey struct{}
+
+// WithPriorityHint returns a context whose upstream requests carry hint
+func WithPriorityHint(ctx context.Context, hint PriorityHint) context.Context {
+	return context.WithValue(ctx, priorityHintKey{}, hint)
+}
+
+// PriorityHintFromContext returns the hint stored by WithPriorityHint
+func PriorityHintFromContext(ctx context.Context) (PriorityHint, bool) {
+	hint, ok := ctx.Value(priorityHintKey{}).(PriorityHint)
+	return hint, ok && hint.Value != ""
+}
+
+// SetPriorityHint adds the priority hint recorded in the request's context, if any, to
+// req's headers
+func SetPriorityHint(req *http.Request) {
+	if hint, ok := PriorityHintFromContext(req.Context()); ok && hint.Header != "" {
+		req.Header.Set(hint.Header, hint.Value)
+	}
+}
+
+// withPriorityMeta returns params with the context's priority hint added to its _meta.
+// params is returned unchanged when there is no hint for _meta or params isn't an object.
+func withPriorityMeta(ctx context.Context, params interface{}) interface{} {
+	hint, ok := PriorityHintFromContext(ctx)
+	if !ok || hint.MetaKey == "" {
+		return params
+	}
+
+	values := map[string]interface{}{}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil || json.Unmarshal(raw, &values) != nil || values == nil {
+			return params
+		}
+	}
+	meta, _ := values["_meta"].(map[string]interface{})
+	if meta == nil {
+		meta = map[string]interface{}{}
+	}
+	meta[hint.MetaKey] = hint.Value
+	values["_meta"] = meta
+	return values
+}
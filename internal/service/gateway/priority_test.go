@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestPriorityHint_ForwardedUpstream(t *testing.T) {
+	var header string
+	var body map[string]json.RawMessage
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-MCP-Priority")
+		raw, _ := io.ReadAll(r.Body)
+		body = nil
+		_ = json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	}))
+	defer backend.Close()
+
+	server := &domain.MCPServer{ID: "server-1", URL: backend.URL, IsActive: true}
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+	t.Run("header and _meta carry the hint", func(t *testing.T) {
+		ctx := WithPriorityHint(context.Background(), PriorityHint{Value: "high", Header: "X-MCP-Priority", MetaKey: "priority"})
+		params := map[string]interface{}{
+			"name":  "search",
+			"_meta": map[string]interface{}{"progressToken": "t1"},
+		}
+
+		_, err := client.Call(ctx, server, "tools/call", params)
+		require.NoError(t, err)
+		assert.Equal(t, "high", header)
+		assert.JSONEq(t, `{"name":"search","_meta":{"progressToken":"t1","priority":"high"}}`, string(body["params"]))
+		assert.Equal(t, map[string]interface{}{"progressToken": "t1"}, params["_meta"], "the caller's params aren't modified")
+	})
+
+	t.Run("params are created for the hint", func(t *testing.T) {
+		ctx := WithPriorityHint(context.Background(), PriorityHint{Value: "low", MetaKey: "priority"})
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Empty(t, header, "no header is configured")
+		assert.JSONEq(t, `{"_meta":{"priority":"low"}}`, string(body["params"]))
+	})
+
+	t.Run("nothing is sent without a hint", func(t *testing.T) {
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Empty(t, header)
+		assert.NotContains(t, body, "params")
+	})
+}
+
+func TestPriorityHint_ForwardedByProxy(t *testing.T) {
+	var header string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-MCP-Priority")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	svc := NewService(multiServerRepository{"server-1": {ID: "server-1", URL: backend.URL, IsActive: true}}, logger.NewNopLogger(), nil)
+	ctx := WithPriorityHint(context.Background(), PriorityHint{Value: "normal", Header: "X-MCP-Priority"})
+	proxy, _, err := svc.ProxyToServer(ctx, "server-1")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gateway/server-1", nil).WithContext(ctx)
+	req.Header.Set("X-MCP-Priority", "high") // Replaced by the gateway's own priority
+	proxy.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "normal", header)
+}
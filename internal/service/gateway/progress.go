@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// MethodProgress is the notification a server sends to report progress on a request
+const MethodProgress = "notifications/progress"
+
+// ProgressFunc receives a progress notification for a call, with the progress token the
+// client chose
+type ProgressFunc func(notification json.RawMessage)
+
+// ProgressToken returns the _meta.progressToken of request params, if the client set one
+func ProgressToken(params map[string]interface{}) (interface{}, bool) {
+	meta, _ := params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	return token, ok && token != nil
+}
+
+// progressWatch is a call waiting for progress notifications
+type progressWatch struct {
+	serverID    string
+	clientToken interface{}
+	fn          ProgressFunc
+
+	mu   sync.Mutex // Held while fn runs so it never runs after the watch ends
+	done bool
+}
+
+// progressRelay routes progress notifications back to the call that asked for them. Calls
+// from many clients share one upstream session, so each call's progress token is replaced
+// upstream with one unique to the gateway; the relay maps it back to the client's token.
+type progressRelay struct {
+	next atomic.Int64
+
+	mu      sync.Mutex
+	watches map[string]*progressWatch // By upstream token
+}
+
+func newProgressRelay() *progressRelay {
+	return &progressRelay{watches: make(map[string]*progressWatch)}
+}
+
+// watch registers fn for a call's progress and returns the token to send upstream along
+// with a func that ends the watch. After the returned func returns, fn is never called.
+func (r *progressRelay) watch(serverID string, clientToken interface{}, fn ProgressFunc) (string, func()) {
+	token := fmt.Sprintf("waffles-progress-%d", r.next.Add(1))
+	w := &progressWatch{serverID: serverID, clientToken: clientToken, fn: fn}
+
+	r.mu.Lock()
+	r.watches[token] = w
+	r.mu.Unlock()
+
+	return token, func() {
+		r.mu.Lock()
+		delete(r.watches, token)
+		r.mu.Unlock()
+
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	}
+}
+
+// dispatch delivers a progress notification from a server to the call its token belongs to.
+// Notifications for unknown tokens, or tokens issued for another server, are dropped.
+func (r *progressRelay) dispatch(serverID string, msg json.RawMessage) {
+	var notification map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &notification); err != nil {
+		return
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(notification["params"], &params); err != nil {
+		return
+	}
+	var token string
+	if err := json.Unmarshal(params["progressToken"], &token); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	w, ok := r.watches[token]
+	r.mu.Unlock()
+	if !ok || w.serverID != serverID {
+		return
+	}
+
+	// Restore the client's token so the notification matches what it sent
+	clientToken, err := json.Marshal(w.clientToken)
+	if err != nil {
+		return
+	}
+	params["progressToken"] = clientToken
+	notification["params"], _ = json.Marshal(params) // #nosec G104 -- re-marshaling parsed JSON cannot fail
+	rewritten, _ := json.Marshal(notification)       // #nosec G104 -- re-marshaling parsed JSON cannot fail
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.fn(rewritten)
+	}
+}
+
+// CallStreamableHTTPWithProgress is CallStreamableHTTP for a call whose params carry a
+// progress token: the server's progress notifications for the call, from its response
+// stream or the session's event stream, are passed to onProgress as they arrive.
+// Without a progress token it behaves exactly like CallStreamableHTTP.
+func (s *Service) CallStreamableHTTPWithProgress(ctx context.Context, serverID, method string, params map[string]interface{}, onProgress ProgressFunc) (json.RawMessage, error) {
+	clientToken, ok := ProgressToken(params)
+	if !ok || onProgress == nil {
+		return s.CallStreamableHTTP(ctx, serverID, method, params)
+	}
+
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if !server.IsActive {
+		return nil, fmt.Errorf("server %s is inactive", serverID)
+	}
+	// Progress arrives from the replica the call is sent to
+	server = s.selectReplica(ctx, server)
+
+	token, stop := s.progress.watch(server.ID, clientToken, onProgress)
+	defer stop()
+
+	// Copy rather than modify the caller's params
+	upstream := maps.Clone(params)
+	meta := maps.Clone(params["_meta"].(map[string]interface{}))
+	meta["progressToken"] = token
+	upstream["_meta"] = meta
+
+	return s.callStreamableHTTP(ctx, server, method, upstream)
+}
@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// progressBackend answers tools/call over SSE with two progress notifications for the
+// call's token, once both test calls are in flight so they overlap on the shared session
+type progressBackend struct {
+	arrived chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *progressBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int64 `json:"id"`
+		Method string
+		Params struct {
+			Name string
+			Meta struct {
+				ProgressToken string `json:"progressToken"`
+			} `json:"_meta"`
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	w.Header().Set(HeaderMCPSessionID, "session-1")
+	if req.Method != "tools/call" {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, req.ID)
+		return
+	}
+
+	b.arrived <- struct{}{}
+	<-b.release
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	for i := 1; i <= 2; i++ {
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progressToken\":%q,\"progress\":%d,\"message\":%q}}\n\n",
+			req.Params.Meta.ProgressToken, i, req.Params.Name)
+		w.(http.Flusher).Flush()
+	}
+	fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":{\"content\":[{\"type\":\"text\",\"text\":%q}]}}\n\n", req.ID, req.Params.Name)
+}
+
+func TestCallStreamableHTTPWithProgress_RoutesByToken(t *testing.T) {
+	backend := &progressBackend{arrived: make(chan struct{}, 2), release: make(chan struct{})}
+	ts := httptest.NewServer(backend)
+	defer ts.Close()
+
+	repo := multiServerRepository{
+		"server-1": {ID: "server-1", URL: ts.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+	}
+	log := logger.NewNopLogger()
+	client := NewStreamableHTTPClient(log, 5*time.Second)
+	svc := NewServiceWithClients(repo, log, nil, nil, client)
+	client.OnNotification(svc.HandleNotification)
+
+	type progressParams struct {
+		ProgressToken interface{} `json:"progressToken"`
+		Progress      int         `json:"progress"`
+		Message       string      `json:"message"`
+	}
+	call := func(tool string, token interface{}, got *[]progressParams) (json.RawMessage, error) {
+		params := map[string]interface{}{
+			"name":  tool,
+			"_meta": map[string]interface{}{"progressToken": token},
+		}
+		result, err := svc.CallStreamableHTTPWithProgress(context.Background(), "server-1", "tools/call", params, func(msg json.RawMessage) {
+			var n struct{ Params progressParams }
+			require.NoError(t, json.Unmarshal(msg, &n))
+			*got = append(*got, n.Params)
+		})
+		assert.Equal(t, token, params["_meta"].(map[string]interface{})["progressToken"], "caller's params are not modified")
+		return result, err
+	}
+
+	var progressA, progressB []progressParams
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		result, err := call("alpha", "token-a", &progressA)
+		assert.NoError(t, err)
+		assert.Contains(t, string(result), "alpha")
+	}()
+	go func() {
+		defer wg.Done()
+		result, err := call("beta", float64(7), &progressB)
+		assert.NoError(t, err)
+		assert.Contains(t, string(result), "beta")
+	}()
+
+	// Hold both calls until they are in flight together
+	<-backend.arrived
+	<-backend.arrived
+	close(backend.release)
+	wg.Wait()
+
+	assert.Equal(t, []progressParams{
+		{ProgressToken: "token-a", Progress: 1, Message: "alpha"},
+		{ProgressToken: "token-a", Progress: 2, Message: "alpha"},
+	}, progressA)
+	assert.Equal(t, []progressParams{
+		{ProgressToken: float64(7), Progress: 1, Message: "beta"},
+		{ProgressToken: float64(7), Progress: 2, Message: "beta"},
+	}, progressB)
+}
+
+func TestProgressRelay(t *testing.T) {
+	relay := newProgressRelay()
+
+	var gotA, gotB []string
+	tokenA, stopA := relay.watch("server-1", "a", func(msg json.RawMessage) { gotA = append(gotA, string(msg)) })
+	tokenB, stopB := relay.watch("server-1", "a", func(msg json.RawMessage) { gotB = append(gotB, string(msg)) })
+	defer stopB()
+	assert.NotEqual(t, tokenA, tokenB, "clients reusing a token get distinct upstream tokens")
+
+	notify := func(serverID, token string) {
+		relay.dispatch(serverID, json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":%q,"progress":1}}`, token)))
+	}
+
+	// Routed by token, whichever stream the notification arrived on
+	notify("server-1", tokenB)
+	assert.Empty(t, gotA)
+	require.Len(t, gotB, 1)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"a","progress":1}}`, gotB[0])
+
+	notify("server-2", tokenA)
+	notify("server-1", "unknown")
+	assert.Empty(t, gotA, "tokens are scoped to their server")
+
+	stopA()
+	notify("server-1", tokenA)
+	assert.Empty(t, gotA, "no progress after the call ends")
+}
+
+func TestProgressToken(t *testing.T) {
+	token, ok := ProgressToken(map[string]interface{}{"_meta": map[string]interface{}{"progressToken": "abc"}})
+	assert.True(t, ok)
+	assert.Equal(t, "abc", token)
+
+	_, ok = ProgressToken(map[string]interface{}{"name": "echo"})
+	assert.False(t, ok)
+	_, ok = ProgressToken(nil)
+	assert.False(t, ok)
+}
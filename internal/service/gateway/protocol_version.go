@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// SupportedProtocolVersions are the MCP protocol versions the gateway can speak, newest first
+var SupportedProtocolVersions = []string{MCPProtocolVersion, "2025-06-18", "2025-03-26", "2024-11-05"}
+
+// ErrUnsupportedProtocolVersion is returned when the gateway and a server share no protocol version
+var ErrUnsupportedProtocolVersion = errors.New("no mutually supported MCP protocol version")
+
+// IsSupportedProtocolVersion reports whether the gateway can speak an MCP protocol version
+func IsSupportedProtocolVersion(version string) bool {
+	return slices.Contains(SupportedProtocolVersions, version)
+}
+
+// requestedProtocolVersion is the version to offer a server in initialize: its configured
+// ProtocolVersion when the gateway supports it, otherwise the latest version
+func requestedProtocolVersion(server *domain.MCPServer) string {
+	if IsSupportedProtocolVersion(server.ProtocolVersion) {
+		return server.ProtocolVersion
+	}
+	return MCPProtocolVersion
+}
+
+// nextProtocolVersion picks the version to retry initialize with after the server rejected
+// the ones in tried: the newest version the server said it supports, else the newest
+// untried version older than the last one rejected. It returns "" when none is left.
+func nextProtocolVersion(offered, tried []string) string {
+	for _, version := range SupportedProtocolVersions {
+		if slices.Contains(offered, version) && !slices.Contains(tried, version) {
+			return version
+		}
+	}
+	if len(offered) > 0 {
+		return ""
+	}
+
+	last := slices.Index(SupportedProtocolVersions, tried[len(tried)-1])
+	for _, version := range SupportedProtocolVersions[last+1:] {
+		if !slices.Contains(tried, version) {
+			return version
+		}
+	}
+	return ""
+}
+
+// unsupportedVersionOffer reports whether err is a server rejecting the requested protocol
+// version, along with the versions the server says it supports, if it listed any
+func unsupportedVersionOffer(err error) ([]string, bool) {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		data, _ := rpcErr.Data.(map[string]interface{})
+		supported, listed := data["supported"].([]interface{})
+		if !listed && !strings.Contains(strings.ToLower(rpcErr.Message), "protocol version") {
+			return nil, false
+		}
+		offered := make([]string, 0, len(supported))
+		for _, v := range supported {
+			if version, ok := v.(string); ok {
+				offered = append(offered, version)
+			}
+		}
+		return offered, true
+	}
+
+	// Some servers reject the MCP-Protocol-Version header with a plain 400
+	msg := strings.ToLower(err.Error())
+	return nil, strings.Contains(msg, "(400)") && strings.Contains(msg, "protocol version")
+}
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// versionedBackend accepts only the listed protocol versions in initialize and records the
+// MCP-Protocol-Version header sent with each method
+type versionedBackend struct {
+	accepts []string
+	reply   string // Version to answer initialize with (empty = echo the request)
+
+	mu      sync.Mutex
+	headers map[string][]string
+}
+
+func (b *versionedBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string          `json:"method"`
+		ID     int64           `json:"id"`
+		Params json.RawMessage `json:"params"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	b.mu.Lock()
+	b.headers[req.Method] = append(b.headers[req.Method], r.Header.Get(HeaderMCPProtocolVersion))
+	b.mu.Unlock()
+
+	if req.ID == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if req.Method != "initialize" {
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, req.ID)
+		return
+	}
+
+	var params InitializeParams
+	_ = json.Unmarshal(req.Params, &params)
+	accepted := false
+	for _, v := range b.accepts {
+		accepted = accepted || v == params.ProtocolVersion
+	}
+	if !accepted {
+		supported, _ := json.Marshal(b.accepts)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32602,"message":"Unsupported protocol version","data":{"supported":%s,"requested":%q}}}`,
+			req.ID, supported, params.ProtocolVersion)
+		return
+	}
+	version := params.ProtocolVersion
+	if b.reply != "" {
+		version = b.reply
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"protocolVersion":%q,"serverInfo":{"name":"test","version":"1"}}}`, req.ID, version)
+}
+
+func (b *versionedBackend) sent(method string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.headers[method]
+}
+
+func newVersionedServer(t *testing.T, backend *versionedBackend, configured string) *domain.MCPServer {
+	t.Helper()
+	backend.headers = make(map[string][]string)
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+	return &domain.MCPServer{ID: "server-1", URL: ts.URL, ProtocolVersion: configured, IsActive: true}
+}
+
+func TestStreamableHTTPClient_ProtocolVersion(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	t.Run("sends the configured version and keeps using it", func(t *testing.T) {
+		backend := &versionedBackend{accepts: []string{"2024-11-05"}}
+		server := newVersionedServer(t, backend, "2024-11-05")
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+
+		session, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-11-05", session.ProtocolVersion)
+
+		_, err = client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2024-11-05"}, backend.sent("initialize"))
+		assert.Equal(t, []string{"2024-11-05"}, backend.sent("tools/list"))
+	})
+
+	t.Run("unknown configured version requests the latest", func(t *testing.T) {
+		backend := &versionedBackend{accepts: SupportedProtocolVersions}
+		server := newVersionedServer(t, backend, "1.0.0")
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+
+		session, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		assert.Equal(t, MCPProtocolVersion, session.ProtocolVersion)
+	})
+
+	t.Run("negotiates down to a version the server lists", func(t *testing.T) {
+		backend := &versionedBackend{accepts: []string{"2024-11-05"}}
+		server := newVersionedServer(t, backend, "")
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+
+		session, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-11-05", session.ProtocolVersion)
+		assert.Equal(t, []string{MCPProtocolVersion, "2024-11-05"}, backend.sent("initialize"))
+
+		_, err = client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2024-11-05"}, backend.sent("tools/list"), "later calls use the negotiated version")
+		assert.Equal(t, []string{"2024-11-05"}, backend.sent("notifications/initialized"))
+	})
+
+	t.Run("records the version the server chose", func(t *testing.T) {
+		backend := &versionedBackend{accepts: SupportedProtocolVersions, reply: "2025-06-18"}
+		server := newVersionedServer(t, backend, "")
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+
+		session, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-06-18", session.ProtocolVersion)
+	})
+
+	t.Run("fails without a common version", func(t *testing.T) {
+		backend := &versionedBackend{accepts: []string{"2023-01-01"}}
+		server := newVersionedServer(t, backend, "")
+		client := NewStreamableHTTPClient(log, 5*time.Second)
+
+		_, err := client.Initialize(context.Background(), server)
+		assert.ErrorIs(t, err, ErrUnsupportedProtocolVersion)
+		assert.Len(t, backend.sent("initialize"), 1, "nothing left to try once the server's list is known")
+	})
+}
+
+func TestNextProtocolVersion(t *testing.T) {
+	assert.Equal(t, "2025-03-26", nextProtocolVersion([]string{"2025-03-26", "2024-11-05"}, []string{MCPProtocolVersion}), "newest offered version")
+	assert.Equal(t, "2025-06-18", nextProtocolVersion(nil, []string{MCPProtocolVersion}), "next older version without an offer")
+	assert.Equal(t, "", nextProtocolVersion(nil, []string{"2024-11-05"}), "nothing older than the oldest")
+	assert.Equal(t, "", nextProtocolVersion([]string{"1999-01-01"}, []string{MCPProtocolVersion}))
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// sniffBytes is how much leading whitespace of a response body is skipped looking for
+// the byte that tells SSE from JSON
+const sniffBytes = 512
+
+// ValidateQuirks rejects quirks the gateway can't apply
+func ValidateQuirks(quirks domain.ServerQuirks) error {
+	if quirks.SessionHeader != "" && !strings.EqualFold(quirks.SessionHeader, HeaderMCPSessionID) {
+		return fmt.Errorf("session_header %q is not a spelling of %s", quirks.SessionHeader, HeaderMCPSessionID)
+	}
+	switch quirks.TrailingSlash {
+	case "", domain.TrailingSlashAdd, domain.TrailingSlashRemove:
+	default:
+		return fmt.Errorf("trailing_slash %q: must be %q or %q", quirks.TrailingSlash, domain.TrailingSlashAdd, domain.TrailingSlashRemove)
+	}
+	switch quirks.Parsing {
+	case "", domain.ParsingStrict, domain.ParsingLenient:
+	default:
+		return fmt.Errorf("parsing %q: must be %q or %q", quirks.Parsing, domain.ParsingStrict, domain.ParsingLenient)
+	}
+	return nil
+}
+
+// ServerURL returns the URL requests to server are sent to: its URL, with the trailing
+// slash of the path added or removed as its TrailingSlash quirk asks
+func ServerURL(server *domain.MCPServer) string {
+	if server.Quirks.TrailingSlash == "" {
+		return server.URL
+	}
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		return server.URL // Left for the request to report
+	}
+	normalize := func(path string) string {
+		path = strings.TrimRight(path, "/")
+		if server.Quirks.TrailingSlash == domain.TrailingSlashAdd {
+			path += "/"
+		}
+		return path
+	}
+	u.Path = normalize(u.Path)
+	if u.RawPath != "" {
+		u.RawPath = normalize(u.RawPath)
+	}
+	return u.String()
+}
+
+// setSessionHeader sets the session id header of req, spelled as server's SessionHeader
+// quirk gives it. Go would canonicalize the name, so it is set on the map directly.
+func setSessionHeader(req *http.Request, server *domain.MCPServer, sessionID string) {
+	name := server.Quirks.SessionHeader
+	if name == "" {
+		req.Header.Set(HeaderMCPSessionID, sessionID)
+		return
+	}
+	req.Header.Del(HeaderMCPSessionID)
+	req.Header[name] = []string{sessionID}
+}
+
+// responseBody returns the body of a successful response and whether it is an SSE
+// stream. That is decided by the Content-Type, which strict parsing requires to be JSON or
+// an event stream, or, when parsing is lenient, by the body itself.
+func responseBody(resp *http.Response, parsing domain.ParsingQuirk) (io.ReadCloser, bool, error) {
+	contentType := resp.Header.Get(HeaderContentType)
+	switch parsing {
+	case domain.ParsingLenient:
+		body, isSSE := sniffEventStream(resp.Body)
+		return body, isSSE, nil
+	case domain.ParsingStrict:
+		if !strings.Contains(contentType, ContentTypeEventStream) && !strings.Contains(contentType, ContentTypeJSON) {
+			return nil, false, fmt.Errorf("unexpected response Content-Type %q", contentType)
+		}
+	}
+	return resp.Body, strings.Contains(contentType, ContentTypeEventStream), nil
+}
+
+// sniffEventStream tells from its first non-blank byte whether body is an SSE stream, for
+// servers whose Content-Type can't be trusted: a JSON body starts with an object or array,
+// an event stream with a field name or comment. Only as much as that takes is read before
+// returning, so a stream that is slow to start isn't waited on. The returned reader still
+// reads the whole body.
+func sniffEventStream(body io.ReadCloser) (io.ReadCloser, bool) {
+	br := bufio.NewReaderSize(body, sniffBytes)
+	sniffed := struct {
+		io.Reader
+		io.Closer
+	}{br, body}
+
+	for n := 1; n <= sniffBytes; n++ {
+		head, _ := br.Peek(n)
+		if len(head) < n {
+			return sniffed, false // Empty body, or a read error that parsing reports
+		}
+		switch b := head[n-1]; b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return sniffed, b != '{' && b != '['
+		}
+	}
+	return sniffed, false
+}
+
+// checkStrictResponse rejects a response that isn't well-formed JSON-RPC 2.0
+func checkStrictResponse(resp JSONRPCResponse) error {
+	if resp.JSONRPC != "2.0" {
+		return fmt.Errorf("not a JSON-RPC 2.0 response: jsonrpc is %q", resp.JSONRPC)
+	}
+	if (resp.Result == nil) == (resp.Error == nil) {
+		return errors.New("JSON-RPC response must have exactly one of result and error")
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// headerRecorder records the header names of the requests it sends exactly as the
+// client set them, before they are written to the wire
+type headerRecorder struct {
+	mu    sync.Mutex
+	names [][]string
+}
+
+func (r *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var names []string
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	r.mu.Lock()
+	r.names = append(r.names, names)
+	r.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (r *headerRecorder) requests() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.names...)
+}
+
+// quirkyBackend answers initialize and tools/list like a backend that only serves path
+func quirkyBackend(t *testing.T, path string) string {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		switch {
+		case msg.Method == "initialize":
+			// Sent as is, not canonicalized
+			w.Header()["mcp-session-id"] = []string{"session-1"}
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+		case r.Header.Get(HeaderMCPSessionID) != "session-1":
+			http.Error(w, "missing session", http.StatusBadRequest)
+		case isNotification(msg.Method):
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestStreamableHTTPClient_Quirks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends the session header in the configured case", func(t *testing.T) {
+		url := quirkyBackend(t, "/mcp")
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		recorder := &headerRecorder{}
+		client.httpClient.Transport = recorder
+		server := &domain.MCPServer{
+			ID: "server-1", URL: url + "/mcp", IsActive: true, RequireInitialize: true,
+			Quirks: domain.ServerQuirks{SessionHeader: "mcp-session-id"},
+		}
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+
+		requests := recorder.requests()
+		require.Len(t, requests, 3, "initialize, notifications/initialized, tools/list")
+		for _, names := range requests[1:] {
+			assert.Contains(t, names, "mcp-session-id")
+			assert.NotContains(t, names, HeaderMCPSessionID)
+		}
+	})
+
+	t.Run("adds the trailing slash a backend requires", func(t *testing.T) {
+		url := quirkyBackend(t, "/mcp/")
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		server := &domain.MCPServer{ID: "server-1", URL: url + "/mcp", IsActive: true, RequireInitialize: true}
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.Error(t, err, "the backend doesn't serve the URL as registered")
+
+		server.Quirks.TrailingSlash = domain.TrailingSlashAdd
+		client.clearSession(server.ID)
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("parses by content when lenient", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// An event stream labelled as JSON
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"ok\":true}}\n\n")
+		}))
+		defer ts.Close()
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+
+		_, err := client.Call(ctx, server, "tools/call", nil)
+		require.Error(t, err)
+
+		server.Quirks.Parsing = domain.ParsingLenient
+		result, err := client.Call(ctx, server, "tools/call", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ok":true}`, string(result))
+	})
+
+	t.Run("rejects malformed responses when strict", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderContentType, ContentTypeJSON)
+			fmt.Fprint(w, `{"id":1,"result":{"ok":true}}`)
+		}))
+		defer ts.Close()
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+
+		_, err := client.Call(ctx, server, "tools/call", nil)
+		require.NoError(t, err)
+
+		server.Quirks.Parsing = domain.ParsingStrict
+		_, err = client.Call(ctx, server, "tools/call", nil)
+		assert.ErrorContains(t, err, "not a JSON-RPC 2.0 response")
+	})
+}
+
+func TestServerURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		slash domain.TrailingSlashQuirk
+		want  string
+	}{
+		{"as registered", "https://mcp.example.com/mcp", "", "https://mcp.example.com/mcp"},
+		{"adds a slash", "https://mcp.example.com/mcp", domain.TrailingSlashAdd, "https://mcp.example.com/mcp/"},
+		{"keeps an existing slash", "https://mcp.example.com/mcp/", domain.TrailingSlashAdd, "https://mcp.example.com/mcp/"},
+		{"adds a slash before the query", "https://mcp.example.com/mcp?tenant=a", domain.TrailingSlashAdd, "https://mcp.example.com/mcp/?tenant=a"},
+		{"removes slashes", "https://mcp.example.com/mcp//", domain.TrailingSlashRemove, "https://mcp.example.com/mcp"},
+		{"adds a root path", "https://mcp.example.com", domain.TrailingSlashAdd, "https://mcp.example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &domain.MCPServer{URL: tt.url, Quirks: domain.ServerQuirks{TrailingSlash: tt.slash}}
+			assert.Equal(t, tt.want, ServerURL(server))
+		})
+	}
+}
+
+func TestValidateQuirks(t *testing.T) {
+	assert.NoError(t, ValidateQuirks(domain.ServerQuirks{}))
+	assert.NoError(t, ValidateQuirks(domain.ServerQuirks{SessionHeader: "mcp-session-id", TrailingSlash: domain.TrailingSlashAdd, Parsing: domain.ParsingLenient}))
+	assert.Error(t, ValidateQuirks(domain.ServerQuirks{SessionHeader: "X-Session"}))
+	assert.Error(t, ValidateQuirks(domain.ServerQuirks{TrailingSlash: "always"}))
+	assert.Error(t, ValidateQuirks(domain.ServerQuirks{Parsing: "loose"}))
+}
@@ -0,0 +1,35 @@
+package gateway
+
+import "strings"
+
+// RedactedValue replaces the value of a redacted key
+const RedactedValue = "[REDACTED]"
+
+// RedactKeySet lowercases keys for lookup by RedactJSON
+func RedactKeySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return set
+}
+
+// RedactJSON replaces the values of keys in a decoded JSON value, matched
+// case-insensitively at any depth. Maps and slices are modified in place.
+func RedactJSON(value interface{}, keys map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if keys[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = RedactJSON(item, keys)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = RedactJSON(item, keys)
+		}
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// ReplicaLister lists the active servers in a replica group, each with its latest health
+// in CurrentStatus. Calls are load balanced across replicas when the gateway's repository
+// implements it.
+type ReplicaLister interface {
+	ListReplicas(ctx context.Context, group string) ([]*domain.MCPServer, error)
+}
+
+// replicaBalancer rotates through the members of each replica group
+type replicaBalancer struct {
+	mu   sync.Mutex
+	next map[string]int // Next member index per group
+}
+
+func newReplicaBalancer() *replicaBalancer {
+	return &replicaBalancer{next: make(map[string]int)}
+}
+
+// pick returns the next of n members of a group, round robin
+func (b *replicaBalancer) pick(group string, n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.next[group] % n
+	b.next[group] = i + 1
+	return i
+}
+
+// selectReplica returns the server a call addressed to server should be sent to. Servers
+// outside a replica group are returned as is. With health gating, replicas whose latest
+// health check failed or whose breaker is open are skipped; since membership and health
+// are read for every call, traffic shifts back as soon as a replica recovers. When no
+// replica is available the call goes to the requested server, which reports the outage.
+func (s *Service) selectReplica(ctx context.Context, server *domain.MCPServer) *domain.MCPServer {
+	if server.ReplicaGroup == "" {
+		return server
+	}
+	lister, ok := s.repo.(ReplicaLister)
+	if !ok {
+		return server
+	}
+
+	replicas, err := lister.ListReplicas(ctx, server.ReplicaGroup)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("server_id", server.ID).
+			Str("replica_group", server.ReplicaGroup).
+			Msg("Failed to list replicas, calling requested server")
+		return server
+	}
+
+	candidates := replicas
+	if s.replicaHealthGating {
+		candidates = make([]*domain.MCPServer, 0, len(replicas))
+		for _, replica := range replicas {
+			if s.replicaAvailable(replica) {
+				candidates = append(candidates, replica)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		s.logger.Warn().
+			Str("server_id", server.ID).
+			Str("replica_group", server.ReplicaGroup).
+			Msg("No available replica, calling requested server")
+		return server
+	}
+
+	selected := candidates[s.replicas.pick(server.ReplicaGroup, len(candidates))]
+	if selected.ID != server.ID {
+		s.logger.Debug().
+			Str("server_id", server.ID).
+			Str("replica_id", selected.ID).
+			Str("replica_group", server.ReplicaGroup).
+			Msg("Routing call to replica")
+	}
+	return selected
+}
+
+// replicaAvailable reports whether a replica may take traffic: its latest health check
+// didn't fail and its breaker isn't open. A never-checked replica is available.
+func (s *Service) replicaAvailable(replica *domain.MCPServer) bool {
+	if replica.CurrentStatus != nil && replica.CurrentStatus.Status == domain.ServerStatusUnhealthy {
+		return false
+	}
+	if s.breakers != nil && s.breakers.Get(replica.ID).State() == BreakerOpen {
+		return false
+	}
+	return true
+}
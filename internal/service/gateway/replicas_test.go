@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// replicaRepository serves a replica group whose members' health can change between calls
+type replicaRepository struct {
+	servers multiServerRepository
+
+	mu     sync.Mutex
+	health map[string]domain.ServerStatus
+	err    error
+}
+
+func (r *replicaRepository) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
+	return r.servers.Get(ctx, id)
+}
+
+func (r *replicaRepository) ListReplicas(ctx context.Context, group string) ([]*domain.MCPServer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	var replicas []*domain.MCPServer
+	for _, id := range []string{"replica-1", "replica-2"} {
+		server := *r.servers[id]
+		status, ok := r.health[id]
+		if !ok {
+			status = domain.ServerStatusUnknown
+		}
+		server.CurrentStatus = &domain.ServerHealth{ServerID: id, Status: status}
+		replicas = append(replicas, &server)
+	}
+	return replicas, nil
+}
+
+func (r *replicaRepository) setHealth(id string, status domain.ServerStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.health[id] = status
+}
+
+// newReplicaTestService returns a service over a two-replica group and the number of
+// tools/call requests each replica has received
+func newReplicaTestService(t *testing.T) (*Service, *replicaRepository, map[string]*atomic.Int32) {
+	t.Helper()
+
+	hits := map[string]*atomic.Int32{"replica-1": {}, "replica-2": {}}
+	backend := func(id string) string {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `"tools/call"`) {
+				hits[id].Add(1)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"` + id + `"}]}}`))
+		}))
+		t.Cleanup(ts.Close)
+		return ts.URL
+	}
+
+	repo := &replicaRepository{
+		servers: multiServerRepository{
+			"replica-1": {ID: "replica-1", URL: backend("replica-1"), Transport: domain.TransportStreamableHTTP, IsActive: true, ReplicaGroup: "search"},
+			"replica-2": {ID: "replica-2", URL: backend("replica-2"), Transport: domain.TransportStreamableHTTP, IsActive: true, ReplicaGroup: "search"},
+		},
+		health: make(map[string]domain.ServerStatus),
+	}
+	log := logger.NewNopLogger()
+	svc := NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+	svc.breakers = NewBreakerRegistry(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	return svc, repo, hits
+}
+
+// callReplicas sends n tool calls addressed to replica-1 and returns the per-replica hits they added
+func callReplicas(t *testing.T, svc *Service, hits map[string]*atomic.Int32, n int) map[string]int32 {
+	t.Helper()
+
+	before := map[string]int32{"replica-1": hits["replica-1"].Load(), "replica-2": hits["replica-2"].Load()}
+	for i := 0; i < n; i++ {
+		_, err := svc.CallStreamableHTTP(context.Background(), "replica-1", "tools/call", map[string]interface{}{"name": "search"})
+		require.NoError(t, err)
+	}
+	return map[string]int32{
+		"replica-1": hits["replica-1"].Load() - before["replica-1"],
+		"replica-2": hits["replica-2"].Load() - before["replica-2"],
+	}
+}
+
+func TestSelectReplica_ShiftsTrafficWithHealth(t *testing.T) {
+	svc, repo, hits := newReplicaTestService(t)
+
+	// Both replicas healthy: calls alternate
+	got := callReplicas(t, svc, hits, 4)
+	assert.Equal(t, got["replica-1"], got["replica-2"], "healthy replicas share traffic")
+	assert.NotZero(t, got["replica-2"])
+
+	// replica-2 fails its health check: all traffic goes to replica-1
+	repo.setHealth("replica-2", domain.ServerStatusUnhealthy)
+	got = callReplicas(t, svc, hits, 4)
+	assert.Equal(t, map[string]int32{"replica-1": 4, "replica-2": 0}, got)
+
+	// replica-2 recovers and replica-1's breaker opens: traffic shifts to replica-2
+	repo.setHealth("replica-2", domain.ServerStatusHealthy)
+	svc.breakers.Get("replica-1").RecordFailure()
+	got = callReplicas(t, svc, hits, 4)
+	assert.Equal(t, map[string]int32{"replica-1": 0, "replica-2": 4}, got)
+
+	// Both available again: traffic rebalances
+	svc.breakers.Get("replica-1").Reset()
+	got = callReplicas(t, svc, hits, 4)
+	assert.Equal(t, map[string]int32{"replica-1": 2, "replica-2": 2}, got)
+}
+
+func TestSelectReplica(t *testing.T) {
+	t.Run("degraded replicas still take traffic", func(t *testing.T) {
+		svc, repo, _ := newReplicaTestService(t)
+		repo.setHealth("replica-2", domain.ServerStatusDegraded)
+
+		seen := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			seen[svc.selectReplica(context.Background(), repo.servers["replica-1"]).ID] = true
+		}
+		assert.True(t, seen["replica-2"])
+	})
+
+	t.Run("without health gating every replica takes traffic", func(t *testing.T) {
+		svc, repo, _ := newReplicaTestService(t)
+		svc.replicaHealthGating = false
+		repo.setHealth("replica-2", domain.ServerStatusUnhealthy)
+
+		seen := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			seen[svc.selectReplica(context.Background(), repo.servers["replica-1"]).ID] = true
+		}
+		assert.True(t, seen["replica-2"])
+	})
+
+	t.Run("falls back to the requested server when no replica is available", func(t *testing.T) {
+		svc, repo, _ := newReplicaTestService(t)
+		repo.setHealth("replica-1", domain.ServerStatusUnhealthy)
+		repo.setHealth("replica-2", domain.ServerStatusUnhealthy)
+
+		selected := svc.selectReplica(context.Background(), repo.servers["replica-2"])
+		assert.Equal(t, "replica-2", selected.ID)
+	})
+
+	t.Run("falls back to the requested server when replicas can't be listed", func(t *testing.T) {
+		svc, repo, _ := newReplicaTestService(t)
+		repo.err = errors.New("connection refused")
+
+		selected := svc.selectReplica(context.Background(), repo.servers["replica-2"])
+		assert.Equal(t, "replica-2", selected.ID)
+	})
+
+	t.Run("servers outside a replica group are not balanced", func(t *testing.T) {
+		svc, _, _ := newReplicaTestService(t)
+		server := &domain.MCPServer{ID: "server-1"}
+
+		assert.Same(t, server, svc.selectReplica(context.Background(), server))
+	})
+}
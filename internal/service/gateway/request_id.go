@@ -0,0 +1,30 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+)
+
+// HeaderRequestID carries the ID correlating a client request with the upstream requests
+// made for it
+const HeaderRequestID = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose upstream requests carry id in HeaderRequestID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// SetRequestID adds the request ID recorded in the request's context, if any, to req
+func SetRequestID(req *http.Request) {
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(HeaderRequestID, id)
+	}
+}
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestClients_ForwardRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get(HeaderRequestID))
+		mu.Unlock()
+
+		var msg struct {
+			ID *int `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		if msg.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, *msg.ID)
+	}))
+	defer ts.Close()
+
+	ctx := WithRequestID(context.Background(), "trace-123")
+	server := &domain.MCPServer{ID: "s1", URL: ts.URL + "/mcp", IsActive: true}
+
+	_, err := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second).Call(ctx, server, "tools/list", nil)
+	require.NoError(t, err)
+	_, err = NewSSEClient(logger.NewNopLogger(), 5*time.Second).Call(ctx, server, "tools/list", nil)
+	require.NoError(t, err)
+	_, err = NewSSEClient(logger.NewNopLogger(), 5*time.Second).Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, seen)
+	for _, id := range seen[:len(seen)-1] {
+		assert.Equal(t, "trace-123", id)
+	}
+	assert.Empty(t, seen[len(seen)-1], "no ID is sent without one in the context")
+}
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// ErrRateLimited is returned when a server or tool has used up its requests for the window
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// requestWindow is the window MaxRequestsPerMinute and MaxToolRequestsPerMinute apply to
+const requestWindow = time.Minute
+
+// windowCounter counts requests for one key in the current and previous fixed windows
+type windowCounter struct {
+	start    time.Time // Start of the current window
+	current  int
+	previous int
+}
+
+// advance rolls the counter forward so the current window contains now
+func (w *windowCounter) advance(now time.Time) {
+	elapsed := now.Sub(w.start)
+	if elapsed < requestWindow {
+		return
+	}
+	periods := elapsed / requestWindow
+	if periods == 1 {
+		w.previous = w.current
+	} else {
+		w.previous = 0
+	}
+	w.current = 0
+	w.start = w.start.Add(periods * requestWindow)
+}
+
+// estimate approximates the requests in the sliding window ending at now by weighting
+// the previous window by how much of it still overlaps
+func (w *windowCounter) estimate(now time.Time) float64 {
+	overlap := 1 - float64(now.Sub(w.start))/float64(requestWindow)
+	return float64(w.previous)*overlap + float64(w.current)
+}
+
+// RequestLimiter enforces each server's MaxRequestsPerMinute and MaxToolRequestsPerMinute
+// over a sliding one-minute window. It is safe for concurrent use.
+type RequestLimiter struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+}
+
+// NewRequestLimiter creates a new request limiter
+func NewRequestLimiter() *RequestLimiter {
+	return &RequestLimiter{
+		now:      time.Now,
+		counters: make(map[string]*windowCounter),
+	}
+}
+
+// Allow records a request to the server, and to toolName when it is not empty.
+// It returns an error wrapping ErrRateLimited without recording anything if either
+// the server or the tool limit would be exceeded. Servers without limits always pass.
+func (l *RequestLimiter) Allow(server *domain.MCPServer, toolName string) error {
+	return l.AllowBatch(server, []string{toolName})
+}
+
+// AllowBatch records a batch of requests to the server, one per entry of toolNames, each
+// also counting against its tool when the name is not empty. Like Allow, it records
+// nothing if any limit would be exceeded, so a batch is held to the same limits as its
+// messages sent one at a time.
+func (l *RequestLimiter) AllowBatch(server *domain.MCPServer, toolNames []string) error {
+	serverLimit := server.MaxRequestsPerMinute
+	toolLimit := server.MaxToolRequestsPerMinute
+	toolCalls := make(map[string]int)
+	var tools []string // In order of first call, so the error names the first tool over
+	if toolLimit > 0 {
+		for _, name := range toolNames {
+			if name == "" {
+				continue
+			}
+			if toolCalls[name] == 0 {
+				tools = append(tools, name)
+			}
+			toolCalls[name]++
+		}
+	}
+	if serverLimit <= 0 && len(tools) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	var serverCounter *windowCounter
+	if serverLimit > 0 {
+		serverCounter = l.counter(server.ID, now)
+		if serverCounter.estimate(now)+float64(len(toolNames)) > float64(serverLimit) {
+			return fmt.Errorf("%w: server %s allows %d requests per minute", ErrRateLimited, server.ID, serverLimit)
+		}
+	}
+	toolCounters := make([]*windowCounter, len(tools))
+	for i, name := range tools {
+		toolCounters[i] = l.counter(server.ID+"/"+name, now)
+		if toolCounters[i].estimate(now)+float64(toolCalls[name]) > float64(toolLimit) {
+			return fmt.Errorf("%w: tool %q on server %s allows %d requests per minute", ErrRateLimited, name, server.ID, toolLimit)
+		}
+	}
+
+	if serverCounter != nil {
+		serverCounter.current += len(toolNames)
+	}
+	for i, name := range tools {
+		toolCounters[i].current += toolCalls[name]
+	}
+	return nil
+}
+
+// counter returns the key's counter advanced to now. Must be called with mu held.
+func (l *RequestLimiter) counter(key string, now time.Time) *windowCounter {
+	w, ok := l.counters[key]
+	if !ok {
+		w = &windowCounter{start: now}
+		l.counters[key] = w
+	}
+	w.advance(now)
+	return w
+}
+
+// sweep drops counters with no requests in the sliding window. Must be called with mu held.
+func (l *RequestLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < requestWindow {
+		return
+	}
+	l.lastSweep = now
+
+	for key, w := range l.counters {
+		if now.Sub(w.start) >= 2*requestWindow {
+			delete(l.counters, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+func TestRequestLimiter_SlidingWindow(t *testing.T) {
+	limiter := NewRequestLimiter()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	server := &domain.MCPServer{ID: "server-1", MaxRequestsPerMinute: 4}
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.Allow(server, ""), "request %d", i)
+	}
+	assert.ErrorIs(t, limiter.Allow(server, ""), ErrRateLimited)
+
+	// Halfway through the next window half of the previous window still counts
+	now = now.Add(90 * time.Second)
+	assert.NoError(t, limiter.Allow(server, ""))
+	assert.NoError(t, limiter.Allow(server, ""))
+	assert.ErrorIs(t, limiter.Allow(server, ""), ErrRateLimited)
+
+	// After two idle windows the counter starts over
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.Allow(server, ""), "request %d after reset", i)
+	}
+}
+
+func TestRequestLimiter_ToolLimit(t *testing.T) {
+	limiter := NewRequestLimiter()
+	server := &domain.MCPServer{ID: "server-1", MaxRequestsPerMinute: 3, MaxToolRequestsPerMinute: 1}
+
+	require.NoError(t, limiter.Allow(server, "search"))
+	assert.ErrorIs(t, limiter.Allow(server, "search"), ErrRateLimited)
+
+	// A rejected tool call doesn't use up the server limit, and other tools have their own count
+	require.NoError(t, limiter.Allow(server, "fetch"))
+	require.NoError(t, limiter.Allow(server, ""))
+	assert.ErrorIs(t, limiter.Allow(server, "other"), ErrRateLimited, "server limit applies across tools")
+}
+
+func TestRequestLimiter_AllowBatch(t *testing.T) {
+	limiter := NewRequestLimiter()
+	server := &domain.MCPServer{ID: "server-1", MaxRequestsPerMinute: 4, MaxToolRequestsPerMinute: 2}
+
+	// Three calls to one tool are over its limit however they are batched
+	err := limiter.AllowBatch(server, []string{"search", "", "search", "search"})
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.ErrorContains(t, err, `tool "search"`)
+
+	// A rejected batch records nothing
+	require.NoError(t, limiter.AllowBatch(server, []string{"search", "search", "fetch"}))
+	assert.ErrorIs(t, limiter.AllowBatch(server, []string{"", ""}), ErrRateLimited, "each message counts against the server")
+	require.NoError(t, limiter.Allow(server, ""))
+}
+
+func TestRequestLimiter_Unlimited(t *testing.T) {
+	limiter := NewRequestLimiter()
+	server := &domain.MCPServer{ID: "server-1"}
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, limiter.Allow(server, "search"))
+	}
+	assert.Empty(t, limiter.counters)
+}
+
+func TestRequestLimiter_Concurrent(t *testing.T) {
+	limiter := NewRequestLimiter()
+	server := &domain.MCPServer{ID: "server-1", MaxRequestsPerMinute: 25}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Allow(server, "") == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(25), allowed.Load())
+}
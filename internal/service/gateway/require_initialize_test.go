@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// strictBackend rejects every request but initialize that comes without a session, and
+// records the methods it receives in order
+type strictBackend struct {
+	mu      sync.Mutex
+	methods []string
+}
+
+func (b *strictBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if len(body) > 0 && body[0] == '[' {
+		msg.Method = "batch"
+	} else {
+		_ = json.Unmarshal(body, &msg)
+	}
+
+	b.mu.Lock()
+	b.methods = append(b.methods, msg.Method)
+	b.mu.Unlock()
+
+	switch {
+	case msg.Method == "initialize":
+		w.Header().Set(HeaderMCPSessionID, "session-1")
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+	case r.Header.Get(HeaderMCPSessionID) != "session-1":
+		http.Error(w, "initialize first", http.StatusBadRequest)
+	case isNotification(msg.Method):
+		w.WriteHeader(http.StatusAccepted)
+	case msg.Method == "batch":
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprint(w, `[{"jsonrpc":"2.0","id":1,"result":{}},{"jsonrpc":"2.0","id":2,"result":{}}]`)
+	default:
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID)
+	}
+}
+
+func (b *strictBackend) received() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.methods...)
+}
+
+func newStrictServer(t *testing.T, requireInitialize bool) (*domain.MCPServer, *strictBackend) {
+	t.Helper()
+	backend := &strictBackend{}
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+	return &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true, RequireInitialize: requireInitialize}, backend
+}
+
+func TestStreamableHTTPClient_RequireInitialize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("tools/list on a strict server initializes first", func(t *testing.T) {
+		server, backend := newStrictServer(t, true)
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+		result, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"tools":[]}`, string(result))
+		assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/list"}, backend.received())
+
+		// The session is reused afterwards
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Len(t, backend.received(), 4)
+	})
+
+	t.Run("batches on a strict server initialize first", func(t *testing.T) {
+		server, backend := newStrictServer(t, true)
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+		_, err := client.CallBatch(ctx, server, []JSONRPCRequest{{ID: 1, Method: "tools/list"}, {ID: 2, Method: "prompts/list"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"initialize", "notifications/initialized", "batch"}, backend.received())
+	})
+
+	t.Run("other servers are called without a session", func(t *testing.T) {
+		server, backend := newStrictServer(t, false)
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.Error(t, err)
+		assert.Equal(t, []string{"tools/list"}, backend.received())
+	})
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidResourceContent is returned for a resources/read result whose contents are
+// neither text nor valid base64 binary
+var ErrInvalidResourceContent = errors.New("invalid resource content")
+
+// ResourceContents is one item of a resources/read result. Text resources carry Text;
+// binary resources carry Blob, the base64-encoded bytes.
+type ResourceContents struct {
+	URI      string  `json:"uri"`
+	MimeType string  `json:"mimeType,omitempty"`
+	Text     *string `json:"text,omitempty"`
+	Blob     *string `json:"blob,omitempty"`
+}
+
+// ReadResourceResult is the result of resources/read
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// IsBinary reports whether the contents are a base64 blob rather than text
+func (r *ResourceContents) IsBinary() bool {
+	return r.Blob != nil
+}
+
+// Bytes returns the resource's content: the decoded blob, or the text as UTF-8
+func (r *ResourceContents) Bytes() ([]byte, error) {
+	if r.Blob == nil {
+		if r.Text == nil {
+			return nil, nil
+		}
+		return []byte(*r.Text), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(*r.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("%w: blob for %q is not valid base64: %v", ErrInvalidResourceContent, r.URI, err)
+	}
+	return data, nil
+}
+
+// ParseReadResourceResult decodes a resources/read result and checks each item is either
+// text or a blob that decodes as base64. The caller should forward the original result
+// rather than re-encoding this one, so blobs reach the client exactly as the server sent
+// them.
+func ParseReadResourceResult(raw json.RawMessage) (*ReadResourceResult, error) {
+	var result ReadResourceResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResourceContent, err)
+	}
+	for i := range result.Contents {
+		item := &result.Contents[i]
+		if item.Text != nil && item.Blob != nil {
+			return nil, fmt.Errorf("%w: %q has both text and blob", ErrInvalidResourceContent, item.URI)
+		}
+		if _, err := item.Bytes(); err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// binaryResource holds every byte value, including NUL and bytes that are invalid UTF-8
+func binaryResource() []byte {
+	data := make([]byte, 0, 512)
+	for i := 0; i < 512; i++ {
+		data = append(data, byte(i*7))
+	}
+	return data
+}
+
+// newResourceBackend serves resources/read with a binary and a text item, as plain JSON or
+// as an SSE stream
+func newResourceBackend(t *testing.T, stream bool) *httptest.Server {
+	t.Helper()
+	blob := base64.StdEncoding.EncodeToString(binaryResource())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+
+		w.Header().Set(HeaderMCPSessionID, "session-1")
+		var response string
+		switch msg.Method {
+		case "initialize":
+			response = fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18","capabilities":{"resources":{}}}}`, msg.ID)
+		case "resources/read":
+			response = fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"contents":[{"uri":"file:///logo.png","mimeType":"image/png","blob":%q},{"uri":"file:///readme","text":"héllo"}]}}`, msg.ID, blob)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		if stream {
+			w.Header().Set(HeaderContentType, "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", response)
+			return
+		}
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestReadResource_BinaryContentForwardedIntact(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			ts := newResourceBackend(t, stream)
+			repo := multiServerRepository{
+				"server-1": {ID: "server-1", URL: ts.URL, Transport: domain.TransportStreamableHTTP, IsActive: true},
+			}
+			svc := NewService(repo, logger.NewNopLogger(), nil)
+
+			raw, err := svc.CallStreamableHTTP(context.Background(), "server-1", "resources/read", map[string]interface{}{"uri": "file:///logo.png"})
+			require.NoError(t, err)
+
+			result, err := ParseReadResourceResult(raw)
+			require.NoError(t, err)
+			require.Len(t, result.Contents, 2)
+
+			binary := result.Contents[0]
+			assert.True(t, binary.IsBinary())
+			assert.Equal(t, "image/png", binary.MimeType)
+			data, err := binary.Bytes()
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(binaryResource(), data), "blob changed on the way through the gateway")
+
+			text := result.Contents[1]
+			assert.False(t, text.IsBinary())
+			data, err = text.Bytes()
+			require.NoError(t, err)
+			assert.Equal(t, "héllo", string(data))
+		})
+	}
+}
+
+func TestParseReadResourceResult_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"blob is not base64":   `{"contents":[{"uri":"file:///a","blob":"not base64!"}]}`,
+		"both text and blob":   `{"contents":[{"uri":"file:///a","text":"a","blob":"YQ=="}]}`,
+		"contents not a list":  `{"contents":"a"}`,
+		"result is not object": `"a"`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseReadResourceResult(json.RawMessage(raw))
+			assert.ErrorIs(t, err, ErrInvalidResourceContent)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gateway
+
+// ring keeps the most recent entries added to it, overwriting the oldest once full. It
+// isn't safe for concurrent use.
+type ring[T any] struct {
+	entries []T
+	next    int // Index the next entry is written to
+	full    bool
+}
+
+// newRing creates a ring holding up to size entries
+func newRing[T any](size int) *ring[T] {
+	return &ring[T]{entries: make([]T, max(size, 1))}
+}
+
+// add stores entry, replacing the oldest when full
+func (r *ring[T]) add(entry T) {
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns up to limit entries, newest first (limit <= 0 = all)
+func (r *ring[T]) list(limit int) []T {
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	entries := make([]T, 0, count)
+	for i := 1; i <= count; i++ {
+		entries = append(entries, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return entries
+}
@@ -0,0 +1,261 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// ErrInvalidSecretRef is returned for a secret reference that can't be parsed, such as
+// "env://" without a variable name or "vault://path" without a key
+var ErrInvalidSecretRef = errors.New("invalid secret reference")
+
+// ErrSecretUnavailable is returned when a server's auth config references a secret that
+// can't be resolved. The upstream call fails rather than being sent without credentials.
+var ErrSecretUnavailable = errors.New("secret unavailable")
+
+// SecretResolver resolves secret references of one scheme, such as "env://NAME" or
+// "vault://path#key", to the secret's value
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// EnvSecretResolver resolves "env://NAME" references from the gateway's environment. Only
+// variables starting with its prefix resolve, so a server's auth config can't be used to
+// read the gateway's own settings, such as its database password.
+type EnvSecretResolver struct {
+	prefix string
+}
+
+// NewEnvSecretResolver creates a resolver for environment variables starting with prefix
+func NewEnvSecretResolver(prefix string) *EnvSecretResolver {
+	return &EnvSecretResolver{prefix: prefix}
+}
+
+// Resolve returns the value of the variable ref names
+func (r *EnvSecretResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	if name == "" || name == ref {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSecretRef, ref)
+	}
+	if !strings.HasPrefix(name, r.prefix) {
+		return "", fmt.Errorf("environment variable %s does not start with %s", name, r.prefix)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// VaultReader reads the key/value data of a secret at a path, e.g. from HashiCorp Vault's
+// KV engine. The gateway doesn't ship a Vault client; deployments provide one.
+type VaultReader interface {
+	ReadSecret(ctx context.Context, path string) (map[string]string, error)
+}
+
+// VaultSecretResolver resolves "vault://path#key" references through a VaultReader
+type VaultSecretResolver struct {
+	reader VaultReader
+}
+
+// NewVaultSecretResolver creates a resolver reading secrets with reader
+func NewVaultSecretResolver(reader VaultReader) *VaultSecretResolver {
+	return &VaultSecretResolver{reader: reader}
+}
+
+// Resolve returns the value of the key at the path ref names
+func (r *VaultSecretResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(strings.TrimPrefix(ref, "vault://"), "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSecretRef, ref)
+	}
+	data, err := r.reader.ReadSecret(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %s", path, key)
+	}
+	return value, nil
+}
+
+// SecretStore resolves the secret references in server auth configs. A string value of the
+// form "<scheme>://..." with a registered scheme is replaced by the secret it names;
+// other values are used as they are. Resolved values are cached for a short TTL so every
+// upstream request doesn't reach the secret backend. Failed resolutions aren't cached.
+type SecretStore struct {
+	ttl     time.Duration
+	metrics metrics.CacheMetrics
+	now     func() time.Time
+
+	mu        sync.Mutex
+	resolvers map[string]SecretResolver // By scheme
+	cache     map[string]cachedSecret   // By reference
+}
+
+// cachedSecret is a resolved secret value
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewSecretStore creates a store caching resolved secrets for ttl (0 = not cached), with
+// no resolvers registered
+func NewSecretStore(ttl time.Duration) *SecretStore {
+	return &SecretStore{
+		ttl:       ttl,
+		metrics:   metrics.NopCacheMetrics,
+		now:       time.Now,
+		resolvers: make(map[string]SecretResolver),
+		cache:     make(map[string]cachedSecret),
+	}
+}
+
+// SetMetrics makes the store report its cache's effectiveness to metricsReg. Must be
+// called before the store is used.
+func (s *SecretStore) SetMetrics(metricsReg *metrics.Registry) {
+	s.metrics = metricsReg.Cache(metrics.CacheSecrets)
+}
+
+// Register resolves references of scheme (e.g. "env" or "vault") with resolver
+func (s *SecretStore) Register(scheme string, resolver SecretResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolvers[scheme] = resolver
+}
+
+// resolverFor returns the resolver for value when it is a reference of a registered scheme
+func (s *SecretStore) resolverFor(value string) (SecretResolver, bool) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resolver, ok := s.resolvers[scheme]
+	return resolver, ok
+}
+
+// Resolve returns the secret ref names, from the cache when it was resolved recently
+func (s *SecretStore) Resolve(ctx context.Context, ref string) (string, error) {
+	resolver, ok := s.resolverFor(ref)
+	if !ok {
+		return "", fmt.Errorf("%w: no resolver for %q", ErrInvalidSecretRef, ref)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[ref]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		s.metrics.Hit()
+		return cached.value, nil
+	}
+	if s.ttl > 0 {
+		s.metrics.Miss()
+	}
+
+	value, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.cache[ref] = cachedSecret{value: value, expiresAt: s.now().Add(s.ttl)}
+		s.metrics.SetSize(len(s.cache))
+		s.mu.Unlock()
+	}
+	return value, nil
+}
+
+// resolveAuthConfig returns authConfig with each top-level string value that is a secret
+// reference replaced by its secret, and whether there were any
+func (s *SecretStore) resolveAuthConfig(ctx context.Context, authConfig json.RawMessage) (json.RawMessage, bool, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(authConfig, &values); err != nil {
+		return nil, false, nil // Reported when the auth is applied
+	}
+
+	resolved := false
+	for key, value := range values {
+		ref, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if _, ok := s.resolverFor(ref); !ok {
+			continue
+		}
+		secret, err := s.Resolve(ctx, ref)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: auth config %s: %w", ErrSecretUnavailable, key, err)
+		}
+		values[key] = secret
+		resolved = true
+	}
+	if !resolved {
+		return nil, false, nil
+	}
+	raw, err := json.Marshal(values)
+	return raw, err == nil, err
+}
+
+// resolveServerAuth returns server with the secret references in its auth config resolved
+// through secrets. The server itself is returned when secrets is nil or there is nothing
+// to resolve; otherwise a copy is, so resolved secrets aren't kept on the shared server.
+func resolveServerAuth(ctx context.Context, server *domain.MCPServer, secrets *SecretStore) (*domain.MCPServer, error) {
+	if secrets == nil || len(server.AuthConfig) == 0 {
+		return server, nil
+	}
+	authConfig, resolved, err := secrets.resolveAuthConfig(ctx, server.AuthConfig)
+	if err != nil {
+		return nil, fmt.Errorf("server %s: %w", server.ID, err)
+	}
+	if !resolved {
+		return server, nil
+	}
+	withSecrets := *server
+	withSecrets.AuthConfig = authConfig
+	return &withSecrets, nil
+}
+
+// SetSecretStore resolves secret references in server auth configs through secrets before
+// authenticating upstream requests. Must be called before the service is used.
+func (s *Service) SetSecretStore(secrets *SecretStore) {
+	s.secrets = secrets
+	if client, ok := s.streamableHTTPClient.(*StreamableHTTPClient); ok {
+		client.SetSecretStore(secrets)
+	}
+	if client, ok := s.sseClient.(*SSEClient); ok {
+		client.SetSecretStore(secrets)
+	}
+	if client, ok := s.webSocketClient.(*WebSocketClient); ok {
+		client.SetSecretStore(secrets)
+	}
+}
+
+// SetSecretStore resolves secret references in server auth configs through secrets. Must
+// be called before the client is used.
+func (c *StreamableHTTPClient) SetSecretStore(secrets *SecretStore) {
+	c.secrets = secrets
+}
+
+// SetSecretStore resolves secret references in server auth configs through secrets. Must
+// be called before the client is used.
+func (c *SSEClient) SetSecretStore(secrets *SecretStore) {
+	c.secrets = secrets
+}
+
+// SetSecretStore resolves secret references in server auth configs through secrets. Must
+// be called before the client is used.
+func (c *WebSocketClient) SetSecretStore(secrets *SecretStore) {
+	c.secrets = secrets
+}
@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// countingResolver resolves every reference to value, counting the lookups
+type countingResolver struct {
+	value   string
+	lookups int
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	r.lookups++
+	return r.value, nil
+}
+
+// staticVault is a VaultReader serving fixed secrets
+type staticVault map[string]map[string]string
+
+func (v staticVault) ReadSecret(ctx context.Context, path string) (map[string]string, error) {
+	return v[path], nil
+}
+
+// newEnvSecretStore creates an uncached store resolving env:// references
+func newEnvSecretStore() *SecretStore {
+	secrets := NewSecretStore(0)
+	secrets.Register("env", NewEnvSecretResolver("MCP_SECRET_"))
+	return secrets
+}
+
+func TestEnvSecretResolver(t *testing.T) {
+	t.Setenv("MCP_SECRET_TOKEN", "s3cret")
+	t.Setenv("DATABASE_PASSWORD", "postgres")
+	resolver := NewEnvSecretResolver("MCP_SECRET_")
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, "env://MCP_SECRET_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = resolver.Resolve(ctx, "env://MCP_SECRET_MISSING")
+	assert.ErrorContains(t, err, "not set")
+
+	_, err = resolver.Resolve(ctx, "env://DATABASE_PASSWORD")
+	assert.ErrorContains(t, err, "does not start with MCP_SECRET_")
+
+	_, err = resolver.Resolve(ctx, "env://")
+	assert.ErrorIs(t, err, ErrInvalidSecretRef)
+}
+
+func TestVaultSecretResolver(t *testing.T) {
+	resolver := NewVaultSecretResolver(staticVault{"mcp/github": {"token": "ghp_123"}})
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, "vault://mcp/github#token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_123", value)
+
+	_, err = resolver.Resolve(ctx, "vault://mcp/github")
+	assert.ErrorIs(t, err, ErrInvalidSecretRef)
+
+	_, err = resolver.Resolve(ctx, "vault://mcp/github#password")
+	assert.ErrorContains(t, err, "has no key password")
+}
+
+func TestSecretStore_Resolve(t *testing.T) {
+	t.Run("caches resolved secrets for the TTL", func(t *testing.T) {
+		resolver := &countingResolver{value: "s3cret"}
+		secrets := NewSecretStore(time.Minute)
+		secrets.Register("test", resolver)
+		now := time.Now()
+		secrets.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			value, err := secrets.Resolve(context.Background(), "test://token")
+			require.NoError(t, err)
+			assert.Equal(t, "s3cret", value)
+		}
+		assert.Equal(t, 1, resolver.lookups)
+
+		now = now.Add(2 * time.Minute)
+		_, err := secrets.Resolve(context.Background(), "test://token")
+		require.NoError(t, err)
+		assert.Equal(t, 2, resolver.lookups)
+	})
+
+	t.Run("leaves plain values and unknown schemes alone", func(t *testing.T) {
+		server := &domain.MCPServer{
+			ID:         "server-1",
+			AuthType:   domain.ServerAuthAPIKey,
+			AuthConfig: json.RawMessage(`{"header":"X-API-Key","value":"https://not-a-reference"}`),
+		}
+
+		resolved, err := resolveServerAuth(context.Background(), server, newEnvSecretStore())
+		require.NoError(t, err)
+		assert.Same(t, server, resolved)
+	})
+}
+
+func TestSecretRefs_AuthenticateUpstreamRequests(t *testing.T) {
+	t.Setenv("MCP_SECRET_TOKEN", "s3cret")
+
+	var authorization string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`))
+	}))
+	defer backend.Close()
+
+	newServer := func(token string) *domain.MCPServer {
+		return &domain.MCPServer{
+			ID:         "server-1",
+			URL:        backend.URL,
+			IsActive:   true,
+			AuthType:   domain.ServerAuthBearer,
+			AuthConfig: json.RawMessage(`{"token":"` + token + `"}`),
+		}
+	}
+
+	t.Run("env reference is resolved", func(t *testing.T) {
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		client.SetSecretStore(newEnvSecretStore())
+		server := newServer("env://MCP_SECRET_TOKEN")
+
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer s3cret", authorization)
+		assert.JSONEq(t, `{"token":"env://MCP_SECRET_TOKEN"}`, string(server.AuthConfig), "the stored reference is kept")
+	})
+
+	t.Run("malformed reference fails the call", func(t *testing.T) {
+		authorization = ""
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		client.SetSecretStore(newEnvSecretStore())
+
+		_, err := client.Call(context.Background(), newServer("env://"), "tools/list", nil)
+		assert.ErrorIs(t, err, ErrSecretUnavailable)
+		assert.ErrorIs(t, err, ErrInvalidSecretRef)
+		assert.Empty(t, authorization, "nothing is sent without credentials")
+	})
+
+	t.Run("unresolvable reference fails the proxy", func(t *testing.T) {
+		svc := NewService(multiServerRepository{"server-1": newServer("env://MCP_SECRET_MISSING")}, logger.NewNopLogger(), nil)
+		svc.SetSecretStore(newEnvSecretStore())
+
+		_, _, err := svc.ProxyToServer(context.Background(), "server-1")
+		assert.ErrorIs(t, err, ErrSecretUnavailable)
+	})
+
+	t.Run("references are used as they are without a store", func(t *testing.T) {
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+
+		_, err := client.Call(context.Background(), newServer("env://MCP_SECRET_TOKEN"), "tools/list", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer env://MCP_SECRET_TOKEN", authorization)
+	})
+}
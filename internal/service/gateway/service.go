@@ -3,15 +3,20 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/internal/tracing"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -36,6 +41,15 @@ type StreamableHTTPClientInterface interface {
 	Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error)
 	Initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error)
 	TerminateSession(ctx context.Context, server *domain.MCPServer) error
+	OpenEventStream(ctx context.Context, server *domain.MCPServer) (io.ReadCloser, error)
+	Respond(ctx context.Context, server *domain.MCPServer, id, result json.RawMessage, rpcErr *JSONRPCError) error
+}
+
+// WebSocketClientInterface defines the interface for WebSocket client operations.
+type WebSocketClientInterface interface {
+	Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error)
+	Initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error)
+	TerminateSession(ctx context.Context, server *domain.MCPServer) error
 }
 
 // Service handles MCP gateway operations using ReverseProxy
@@ -45,28 +59,150 @@ type Service struct {
 	metrics              *metrics.Registry
 	sseClient            SSEClientInterface            // Legacy SSE client (deprecated)
 	streamableHTTPClient StreamableHTTPClientInterface // Streamable HTTP client (MCP 2025-11-25)
+	webSocketClient      WebSocketClientInterface      // Persistent WebSocket connections
+	breakers             *BreakerRegistry              // Per-server circuit breakers (nil = disabled)
+	tools                *ToolsCache                   // Tool names from the last tools/list per server
+	rejectUnknownTools   bool                          // Reject tools/call for tools missing from a warm cache
+	limiter              *ConnectionLimiter            // Per-server MaxConnections semaphores (nil = unlimited)
+	warmToolsList        bool                          // Refetch tools/list as soon as a server reports list_changed
+	warming              sync.Map                      // Server IDs with a tools/list refetch in flight
+	aggregationMode      AggregationMode               // Default failure handling for aggregated responses
+
+	aggregationConcurrency int // Max concurrent upstream calls per aggregated response (0 = default)
+
+	subscriptions *resourceSubscriptions // Resource update subscribers per server
+	progress      *progressRelay         // Calls waiting for progress notifications
+	elicitations  *pendingElicitations   // Elicitation requests relayed to clients and not yet answered
+
+	replicas            *replicaBalancer // Round robin position per replica group
+	replicaHealthGating bool             // Skip unhealthy or breaker-open replicas
+
+	probeTransports bool                // Probe servers whose transport the URL doesn't reveal
+	detected        *detectedTransports // Transports found by probing
+
+	reconnectJitter JitterStrategy // Jitter applied to event stream reconnect delays
+
+	deadLetters          DeadLetterStore // Requests that failed for good (nil = not recorded)
+	deadLetterRedactKeys map[string]bool // Param keys whose values are redacted in dead letters
+	debugLog             *DebugLog       // Recent requests and responses per server (nil = not kept)
+	debugLogRedactKeys   map[string]bool // Keys whose values are redacted in the debug log
+
+	egress  *EgressPolicy // Proxy and host allowlist for upstream requests (nil = unrestricted)
+	secrets *SecretStore  // Resolves secret references in auth configs (nil = used as stored)
+	pinned  pinnedClients // HTTPClient clients for servers with TLSPins
 }
 
 // NewService creates a new gateway service
 func NewService(repo ServerRepository, log logger.Logger, metricsReg *metrics.Registry) *Service {
-	return &Service{
+	streamableHTTPClient := NewStreamableHTTPClient(log, 30*time.Second)
+	webSocketClient := NewWebSocketClient(log, 30*time.Second)
+	s := &Service{
 		repo:                 repo,
 		logger:               log,
 		metrics:              metricsReg,
 		sseClient:            NewSSEClient(log, 30*time.Second),
-		streamableHTTPClient: NewStreamableHTTPClient(log, 30*time.Second),
+		streamableHTTPClient: streamableHTTPClient,
+		webSocketClient:      webSocketClient,
+		tools:                NewToolsCache(),
+		replicaHealthGating:  true,
 	}
+	s.subscriptions = newResourceSubscriptions(s)
+	s.progress = newProgressRelay()
+	s.elicitations = newPendingElicitations()
+	s.replicas = newReplicaBalancer()
+	s.detected = newDetectedTransports()
+	streamableHTTPClient.SetMetrics(metricsReg)
+	streamableHTTPClient.OnNotification(s.HandleNotification)
+	streamableHTTPClient.OnServerRequest(s.HandleServerRequest)
+	webSocketClient.OnNotification(s.HandleNotification)
+	return s
+}
+
+// NewServiceWithConfig creates a new gateway service using gateway configuration
+func NewServiceWithConfig(repo ServerRepository, log logger.Logger, metricsReg *metrics.Registry, cfg config.GatewayConfig) *Service {
+	s := NewService(repo, log, metricsReg)
+	if cfg.CircuitBreaker.Enabled {
+		s.breakers = NewBreakerRegistry(BreakerConfig{
+			FailureThreshold: cfg.CircuitBreaker.FailureThreshold,
+			OpenTimeout:      cfg.CircuitBreaker.OpenTimeout,
+			LatencyBudget:    cfg.CircuitBreaker.LatencyBudget,
+			SlowCallRatio:    cfg.CircuitBreaker.SlowCallRatio,
+			SlowCallWindow:   cfg.CircuitBreaker.SlowCallWindow,
+		})
+	}
+	s.rejectUnknownTools = cfg.RejectUnknownTools
+	s.tools = NewToolsCacheWithConfig(ToolsCacheConfig{
+		MaxAge:     cfg.ToolsCacheMaxAge,
+		MaxEntries: cfg.ToolsCacheMaxEntries,
+	}, metricsReg)
+	if cfg.EnforceMaxConnections {
+		s.limiter = NewConnectionLimiter(metricsReg)
+	}
+	s.warmToolsList = cfg.WarmToolsOnListChanged
+	s.aggregationMode = AggregationMode(cfg.AggregationMode)
+	s.aggregationConcurrency = cfg.AggregationConcurrency
+	s.replicaHealthGating = cfg.ReplicaHealthGating
+	s.probeTransports = cfg.ProbeTransport
+	s.subscriptions.maxPerServer = cfg.MaxEventSubscribers
+	retry := RetryPolicy{
+		Jitter:     JitterStrategy(cfg.Retry.Jitter),
+		BaseDelay:  cfg.Retry.BaseDelay,
+		MaxDelay:   cfg.Retry.MaxDelay,
+		MaxRetries: cfg.Retry.MaxRetries,
+
+		InitializedRetries: cfg.Retry.InitializedRetries,
+	}
+	s.reconnectJitter = retry.Jitter
+	if client, ok := s.streamableHTTPClient.(*StreamableHTTPClient); ok {
+		client.SetMaxResponseBytes(cfg.MaxResponseBytes)
+		client.SetMaxInitializesPerMinute(cfg.MaxInitializesPerMinute)
+		client.SetSessionLimits(cfg.SessionIdleTTL, cfg.MaxSessions)
+		client.SetRetryPolicy(retry)
+		client.SetListTimeout(cfg.ListTimeout)
+	}
+	if client, ok := s.sseClient.(*SSEClient); ok {
+		client.SetMaxResponseBytes(cfg.MaxResponseBytes)
+		client.SetRetryPolicy(retry)
+		client.SetListTimeout(cfg.ListTimeout)
+	}
+	if client, ok := s.webSocketClient.(*WebSocketClient); ok {
+		client.SetMaxResponseBytes(cfg.MaxResponseBytes)
+		client.SetRetryPolicy(retry)
+		client.SetListTimeout(cfg.ListTimeout)
+		client.SetKeepAlive(cfg.WebSocket.PingInterval, cfg.WebSocket.PongTimeout)
+	}
+	return s
 }
 
 // NewServiceWithClients creates a new gateway service with custom clients (useful for testing).
 func NewServiceWithClients(repo ServerRepository, log logger.Logger, metricsReg *metrics.Registry, sseClient SSEClientInterface, streamableHTTPClient StreamableHTTPClientInterface) *Service {
-	return &Service{
+	s := &Service{
 		repo:                 repo,
 		logger:               log,
 		metrics:              metricsReg,
 		sseClient:            sseClient,
 		streamableHTTPClient: streamableHTTPClient,
+		webSocketClient:      NewWebSocketClient(log, 30*time.Second),
+		tools:                NewToolsCache(),
+		replicaHealthGating:  true,
+	}
+	s.subscriptions = newResourceSubscriptions(s)
+	s.progress = newProgressRelay()
+	s.elicitations = newPendingElicitations()
+	s.replicas = newReplicaBalancer()
+	s.detected = newDetectedTransports()
+	return s
+}
+
+// HTTPClient returns a client for requests sent to server directly rather than through
+// one of the MCP clients. Like proxied requests, they go through the egress policy and
+// must match the server's TLS pins.
+func (s *Service) HTTPClient(server *domain.MCPServer) *http.Client {
+	base := &http.Client{Timeout: 30 * time.Second}
+	if s.egress != nil {
+		base.Transport = s.egress.Transport()
 	}
+	return s.pinned.clientFor(base, server)
 }
 
 // ProxyToServer creates a reverse proxy for a registered MCP server
@@ -84,6 +220,11 @@ func (s *Service) ProxyToServer(
 	if !server.IsActive {
 		return nil, nil, fmt.Errorf("server %s is inactive", serverID)
 	}
+	server = s.selectReplica(ctx, server)
+	authServer, err := resolveServerAuth(ctx, server, s.secrets)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Parse server URL
 	target, err := url.Parse(server.URL)
@@ -91,6 +232,19 @@ func (s *Service) ProxyToServer(
 		return nil, nil, fmt.Errorf("invalid server URL %s: %w", server.URL, err)
 	}
 
+	transport := &http.Transport{
+		MaxIdleConns:        server.MaxConnections,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     time.Duration(server.TimeoutSeconds) * time.Second,
+		DisableKeepAlives:   false,
+	}
+	if s.egress != nil {
+		s.egress.apply(transport)
+	}
+	if len(server.TLSPins) > 0 {
+		transport = pinnedTransport(transport, server)
+	}
+
 	// Create reverse proxy with custom Director
 	proxy := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
@@ -128,8 +282,15 @@ func (s *Service) ProxyToServer(
 			req.URL.Path = finalPath
 			req.Host = target.Host
 
-			// Add MCP-specific auth if configured
-			s.injectAuth(req, server)
+			// Drop client headers the server doesn't accept, then add its own auth
+			filterForwardHeaders(req.Header, server)
+			if req.Method == http.MethodPost && server.AcceptHeader != "" {
+				req.Header.Set(HeaderAccept, server.AcceptHeader)
+			}
+			SetGatewayHops(req)
+			SetRequestID(req)
+			SetPriorityHint(req)
+			s.applyAuth(req, authServer)
 
 			// Log the proxied request
 			s.logger.Info().
@@ -141,12 +302,7 @@ func (s *Service) ProxyToServer(
 				Str("target_url", target.String()).
 				Msg("Proxying request to MCP server")
 		},
-		Transport: &http.Transport{
-			MaxIdleConns:        server.MaxConnections,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     time.Duration(server.TimeoutSeconds) * time.Second,
-			DisableKeepAlives:   false,
-		},
+		Transport: &tracingTransport{base: transport, server: server},
 	}
 
 	// Hook ModifyResponse for logging responses and metrics
@@ -171,6 +327,11 @@ func (s *Service) ProxyToServer(
 			Int("status", resp.StatusCode).
 			Str("content_type", resp.Header.Get("Content-Type")).
 			Msg("MCP server responded")
+
+		// Watch streamed responses for list_changed notifications from the server
+		if strings.Contains(resp.Header.Get("Content-Type"), ContentTypeEventStream) {
+			resp.Body = watchNotifications(resp.Body, serverID, s.HandleNotification)
+		}
 		return nil
 	}
 
@@ -206,8 +367,19 @@ func (s *Service) ProxyToServer(
 	return proxy, server, nil
 }
 
-// injectAuth adds authentication to requests based on server config
-func (s *Service) injectAuth(req *http.Request, server *domain.MCPServer) {
+// injectAuth adds authentication to requests based on server config, resolving secret
+// references in it first
+func (s *Service) injectAuth(req *http.Request, server *domain.MCPServer) error {
+	server, err := resolveServerAuth(req.Context(), server, s.secrets)
+	if err != nil {
+		return err
+	}
+	s.applyAuth(req, server)
+	return nil
+}
+
+// applyAuth adds authentication to requests based on server config
+func (s *Service) applyAuth(req *http.Request, server *domain.MCPServer) {
 	// AuthConfig is json.RawMessage ([]byte), needs to be unmarshaled
 	if len(server.AuthConfig) == 0 {
 		s.logger.Debug().
@@ -247,6 +419,15 @@ func (s *Service) injectAuth(req *http.Request, server *domain.MCPServer) {
 				Msg("Added Basic authentication")
 		}
 
+	case domain.ServerAuthAPIKey:
+		// API key in a configurable header (defaults to Authorization)
+		if header, ok := applyAPIKeyAuth(req, authConfig); ok {
+			s.logger.Debug().
+				Str("server_id", server.ID).
+				Str("header", header).
+				Msg("Added API key authentication")
+		}
+
 	case domain.ServerAuthNone:
 		// No authentication needed
 		s.logger.Debug().
@@ -261,6 +442,25 @@ func (s *Service) injectAuth(req *http.Request, server *domain.MCPServer) {
 	}
 }
 
+// applyAPIKeyAuth sets an API key header from an api_key auth config.
+// Config keys: "header" (default Authorization), "value", and optional "prefix".
+// Returns the header name and whether a value was set.
+func applyAPIKeyAuth(req *http.Request, authConfig map[string]interface{}) (string, bool) {
+	value, _ := authConfig["value"].(string)
+	if value == "" {
+		return "", false
+	}
+
+	header, _ := authConfig["header"].(string)
+	if header == "" {
+		header = "Authorization"
+	}
+	prefix, _ := authConfig["prefix"].(string)
+
+	req.Header.Set(header, prefix+value)
+	return header, true
+}
+
 // Initialize sends an initialize request to an MCP server (direct call, not proxied)
 func (s *Service) Initialize(ctx context.Context, serverID string) (*domain.MCPServer, error) {
 	server, err := s.repo.Get(ctx, serverID)
@@ -298,14 +498,119 @@ func (s *Service) CallSSE(ctx context.Context, serverID string, method string, p
 	if !server.IsActive {
 		return nil, fmt.Errorf("server %s is inactive", serverID)
 	}
+	server = s.selectReplica(ctx, server)
 
 	s.logger.Info().
-		Str("server_id", serverID).
+		Str("server_id", server.ID).
 		Str("server_name", server.Name).
 		Str("method", method).
 		Msg("Calling SSE-based MCP server")
 
-	return s.sseClient.Call(ctx, server, method, params)
+	if method == "tools/call" {
+		if err := s.CheckToolCall(ctx, server.ID, stringParam(params, "name")); err != nil {
+			return nil, err
+		}
+	}
+	release, err := s.acquireConnection(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.allowCall(server.ID); err != nil {
+		return nil, err
+	}
+	callCtx, span := startSpan(ctx, "gateway.Call", server, domain.TransportSSE, method)
+	start := time.Now()
+	result, err := s.sseClient.Call(callCtx, server, method, params)
+	elapsed := time.Since(start)
+	tracing.End(span, err)
+	s.recordCallResult(server, elapsed, err)
+	s.recordDeadLetter(ctx, server, method, params, err)
+	s.recordDebugEntry(server, method, params, result, err, elapsed)
+	if err == nil && method == "tools/list" && stringParam(params, "cursor") == "" {
+		s.RecordToolsList(server.ID, result)
+	}
+	return result, err
+}
+
+// CallWebSocket sends a JSON-RPC request to an MCP server over its WebSocket connection
+func (s *Service) CallWebSocket(ctx context.Context, serverID string, method string, params interface{}) (json.RawMessage, error) {
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !server.IsActive {
+		return nil, fmt.Errorf("server %s is inactive", serverID)
+	}
+	server = s.selectReplica(ctx, server)
+
+	s.logger.Info().
+		Str("server_id", server.ID).
+		Str("server_name", server.Name).
+		Str("method", method).
+		Msg("Calling WebSocket MCP server")
+
+	if method == "tools/call" {
+		if err := s.CheckToolCall(ctx, server.ID, stringParam(params, "name")); err != nil {
+			return nil, err
+		}
+	}
+	release, err := s.acquireConnection(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.allowCall(server.ID); err != nil {
+		return nil, err
+	}
+	callCtx, span := startSpan(ctx, "gateway.Call", server, domain.TransportWebSocket, method)
+	start := time.Now()
+	result, err := s.webSocketClient.Call(callCtx, server, method, params)
+	elapsed := time.Since(start)
+	tracing.End(span, err)
+	s.recordCallResult(server, elapsed, err)
+	s.recordDeadLetter(ctx, server, method, params, err)
+	s.recordDebugEntry(server, method, params, result, err, elapsed)
+	if err == nil && method == "tools/list" && stringParam(params, "cursor") == "" {
+		s.RecordToolsList(server.ID, result)
+	}
+	return result, err
+}
+
+// InitializeWebSocket opens a WebSocket connection to a server and initializes an MCP
+// session on it
+func (s *Service) InitializeWebSocket(ctx context.Context, serverID string) (*MCPSession, error) {
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !server.IsActive {
+		return nil, fmt.Errorf("server %s is inactive", serverID)
+	}
+
+	s.logger.Info().
+		Str("server_id", serverID).
+		Str("server_name", server.Name).
+		Str("url", server.URL).
+		Msg("Initializing WebSocket MCP session")
+
+	ctx, span := startSpan(ctx, "gateway.Initialize", server, domain.TransportWebSocket, "initialize")
+	session, err := s.webSocketClient.Initialize(ctx, server)
+	tracing.End(span, err)
+	return session, err
+}
+
+// TerminateWebSocket closes a server's WebSocket connection
+func (s *Service) TerminateWebSocket(ctx context.Context, serverID string) error {
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	return s.webSocketClient.TerminateSession(ctx, server)
 }
 
 // IsSSEServer checks if a server uses SSE transport
@@ -336,14 +641,43 @@ func (s *Service) CallStreamableHTTP(ctx context.Context, serverID string, metho
 	if !server.IsActive {
 		return nil, fmt.Errorf("server %s is inactive", serverID)
 	}
+	return s.callStreamableHTTP(ctx, s.selectReplica(ctx, server), method, params)
+}
 
+// callStreamableHTTP sends a JSON-RPC request to the Streamable HTTP server chosen for a call
+func (s *Service) callStreamableHTTP(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
 	s.logger.Info().
-		Str("server_id", serverID).
+		Str("server_id", server.ID).
 		Str("server_name", server.Name).
 		Str("method", method).
 		Msg("Calling Streamable HTTP MCP server")
 
-	return s.streamableHTTPClient.Call(ctx, server, method, params)
+	if method == "tools/call" {
+		if err := s.CheckToolCall(ctx, server.ID, stringParam(params, "name")); err != nil {
+			return nil, err
+		}
+	}
+	release, err := s.acquireConnection(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.allowCall(server.ID); err != nil {
+		return nil, err
+	}
+	callCtx, span := startSpan(ctx, "gateway.Call", server, domain.TransportStreamableHTTP, method)
+	start := time.Now()
+	result, err := s.streamableHTTPClient.Call(callCtx, server, method, params)
+	elapsed := time.Since(start)
+	tracing.End(span, err)
+	s.recordCallResult(server, elapsed, err)
+	s.recordDeadLetter(ctx, server, method, params, err)
+	s.recordDebugEntry(server, method, params, result, err, elapsed)
+	if err == nil && method == "tools/list" && stringParam(params, "cursor") == "" {
+		s.RecordToolsList(server.ID, result)
+	}
+	return result, err
 }
 
 // InitializeStreamableHTTP initializes an MCP session with a Streamable HTTP server
@@ -363,19 +697,179 @@ func (s *Service) InitializeStreamableHTTP(ctx context.Context, serverID string)
 		Str("url", server.URL).
 		Msg("Initializing Streamable HTTP MCP session")
 
-	return s.streamableHTTPClient.Initialize(ctx, server)
+	ctx, span := startSpan(ctx, "gateway.Initialize", server, domain.TransportStreamableHTTP, "initialize")
+	session, err := s.streamableHTTPClient.Initialize(ctx, server)
+	tracing.End(span, err)
+	return session, err
 }
 
-// TerminateStreamableHTTP terminates an MCP session with a Streamable HTTP server
+// TerminateStreamableHTTP terminates an MCP session with a Streamable HTTP server.
+// Resource subscriptions on the session are closed.
 func (s *Service) TerminateStreamableHTTP(ctx context.Context, serverID string) error {
 	server, err := s.repo.Get(ctx, serverID)
 	if err != nil {
 		return err
 	}
 
+	s.subscriptions.closeServer(serverID)
 	return s.streamableHTTPClient.TerminateSession(ctx, server)
 }
 
+// CheckToolCall returns ErrUnknownTool when unknown-tool rejection is enabled and the
+// server's cached tools list does not contain the tool. A stale list is refreshed before
+// deciding; a cache miss, including a failed refresh, always passes.
+func (s *Service) CheckToolCall(ctx context.Context, serverID, toolName string) error {
+	if !s.rejectUnknownTools || s.tools == nil {
+		return nil
+	}
+	if s.tools.Stale(serverID) {
+		s.refreshToolsList(ctx, serverID)
+		if s.tools.Stale(serverID) {
+			// Don't enforce a list the server may no longer advertise
+			s.tools.Invalidate(serverID)
+		}
+	}
+	if found, cached := s.tools.Lookup(serverID, toolName); cached && !found {
+		s.logger.Warn().
+			Str("server_id", serverID).
+			Str("tool_name", toolName).
+			Msg("Rejecting call to tool not advertised by server")
+		return fmt.Errorf("%w: %q is not advertised by server %s", ErrUnknownTool, toolName, serverID)
+	}
+	return nil
+}
+
+// RecordToolsList caches the tool names from a complete tools/list result.
+// Paginated results are ignored since they do not list every tool.
+func (s *Service) RecordToolsList(serverID string, result json.RawMessage) {
+	if s.tools == nil {
+		return
+	}
+	if names, ok := parseToolNames(result); ok {
+		s.tools.Set(serverID, names)
+	}
+}
+
+// HandleNotification reacts to a notification sent by a server. Progress notifications are
+// relayed to the call they belong to. On tools list_changed the cached tools list is
+// dropped and, when warming is enabled, refetched in the background so the next client
+// doesn't pay for the round trip.
+func (s *Service) HandleNotification(serverID, method string, msg json.RawMessage) {
+	if method == MethodProgress {
+		s.progress.dispatch(serverID, msg)
+		return
+	}
+	if method != MethodToolsListChanged {
+		return
+	}
+	if s.tools != nil {
+		s.tools.Invalidate(serverID)
+	}
+	if !s.warmToolsList {
+		return
+	}
+	if _, inFlight := s.warming.LoadOrStore(serverID, struct{}{}); inFlight {
+		return
+	}
+	go func() {
+		defer s.warming.Delete(serverID)
+		s.refreshToolsList(context.Background(), serverID)
+	}()
+}
+
+// refreshToolsList fetches tools/list from the server over its transport, recording the result
+func (s *Service) refreshToolsList(ctx context.Context, serverID string) {
+	transport, _, err := s.GetTransportType(ctx, serverID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("server_id", serverID).Msg("Failed to look up server for tools list refresh")
+		return
+	}
+
+	switch transport {
+	case domain.TransportStreamableHTTP:
+		_, err = s.CallStreamableHTTP(ctx, serverID, "tools/list", nil)
+	case domain.TransportSSE:
+		_, err = s.CallSSE(ctx, serverID, "tools/list", nil)
+	case domain.TransportWebSocket:
+		_, err = s.CallWebSocket(ctx, serverID, "tools/list", nil)
+	default:
+		return
+	}
+	if err != nil {
+		s.logger.Warn().Err(err).Str("server_id", serverID).Msg("Failed to refresh tools list after list_changed")
+		return
+	}
+	s.logger.Debug().Str("server_id", serverID).Msg("Refreshed tools list after list_changed")
+}
+
+// acquireConnection waits for a MaxConnections slot on the server when limits are enforced
+func (s *Service) acquireConnection(ctx context.Context, server *domain.MCPServer) (func(), error) {
+	if s.limiter == nil {
+		return func() {}, nil
+	}
+	return s.limiter.Acquire(ctx, server)
+}
+
+// allowCall checks the server's circuit breaker before an upstream call
+func (s *Service) allowCall(serverID string) error {
+	if s.breakers == nil {
+		return nil
+	}
+	if err := s.breakers.Get(serverID).Allow(); err != nil {
+		s.logger.Warn().Str("server_id", serverID).Msg("Circuit breaker open, rejecting call")
+		return fmt.Errorf("server %s unavailable: %w", serverID, err)
+	}
+	return nil
+}
+
+// isJSONRPCError reports whether err is the server answering with a JSON-RPC error, which
+// means it is up and handling requests
+func isJSONRPCError(err error) bool {
+	_, ok := AsJSONRPCError(err)
+	return ok
+}
+
+// recordCallResult feeds the outcome of an upstream call into the server's circuit breaker.
+// JSON-RPC errors returned by a reachable server don't count as failures; successful calls
+// slower than the latency budget count toward the slow call ratio.
+func (s *Service) recordCallResult(server *domain.MCPServer, elapsed time.Duration, err error) {
+	if s.breakers == nil {
+		return
+	}
+	breaker := s.breakers.Get(server.ID)
+	switch {
+	case errors.Is(err, context.Canceled):
+		breaker.Release()
+		return
+	case err == nil || isJSONRPCError(err):
+		budget := time.Duration(server.LatencyBudgetMs) * time.Millisecond
+		if breaker.RecordLatency(elapsed, budget) {
+			s.logger.Warn().
+				Str("server_id", server.ID).
+				Dur("elapsed", elapsed).
+				Msg("Circuit breaker opened, too many calls over the latency budget")
+		}
+		return
+	}
+	breaker.RecordFailure()
+	if breaker.State() == BreakerOpen {
+		s.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Circuit breaker opened")
+	}
+}
+
+// ResetBreaker closes the circuit breaker for a server.
+// Returns true if the breaker was open or half-open.
+func (s *Service) ResetBreaker(serverID string) bool {
+	if s.breakers == nil {
+		return false
+	}
+	if !s.breakers.Reset(serverID) {
+		return false
+	}
+	s.logger.Info().Str("server_id", serverID).Msg("Circuit breaker reset")
+	return true
+}
+
 // GetTransportType determines the transport type for a server
 func (s *Service) GetTransportType(ctx context.Context, serverID string) (domain.TransportType, *domain.MCPServer, error) {
 	server, err := s.repo.Get(ctx, serverID)
@@ -389,6 +883,9 @@ func (s *Service) GetTransportType(ctx context.Context, serverID string) (domain
 	}
 
 	// Auto-detect based on URL patterns
+	if IsWebSocketServer(server) {
+		return domain.TransportWebSocket, server, nil
+	}
 	if IsStreamableHTTPServer(server) {
 		return domain.TransportStreamableHTTP, server, nil
 	}
@@ -396,6 +893,15 @@ func (s *Service) GetTransportType(ctx context.Context, serverID string) (domain
 		return domain.TransportSSE, server, nil
 	}
 
+	// Ask the server itself
+	if s.probeTransports {
+		transport, err := s.probeTransport(ctx, server)
+		if err != nil {
+			return "", nil, err
+		}
+		return transport, server, nil
+	}
+
 	// Default to HTTP
 	return domain.TransportHTTP, server, nil
 }
@@ -403,10 +909,15 @@ func (s *Service) GetTransportType(ctx context.Context, serverID string) (domain
 // rewriteProxyPath strips the gateway prefix from the path
 // Example: /api/v1/gateway/SERVER_ID/tools/list -> /tools/list
 func rewriteProxyPath(originalPath, serverID string) string {
-	// Remove /api/v1/gateway/:server_id prefix
+	// Remove /api/v1/gateway/:server_id prefix. It only matches a whole path segment, so
+	// server-1 doesn't match /api/v1/gateway/server-12/...
 	prefix := fmt.Sprintf("/api/v1/gateway/%s", serverID)
-	if strings.HasPrefix(originalPath, prefix) {
-		return strings.TrimPrefix(originalPath, prefix)
+	rest, ok := strings.CutPrefix(originalPath, prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return originalPath
+	}
+	if rest == "" {
+		return "/"
 	}
-	return originalPath
+	return rest
 }
@@ -3,16 +3,19 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
 	"github.com/waffles/waffles/pkg/logger"
 )
@@ -39,7 +42,7 @@ func TestNewSSEClient(t *testing.T) {
 		require.NotNil(t, client)
 		assert.NotNil(t, client.httpClient)
 		assert.NotNil(t, client.logger)
-		assert.Equal(t, 30*time.Second, client.httpClient.Timeout)
+		assert.Equal(t, 30*time.Second, client.timeout)
 	})
 
 	t.Run("creates client with custom timeout", func(t *testing.T) {
@@ -47,7 +50,7 @@ func TestNewSSEClient(t *testing.T) {
 		client := NewSSEClient(log, 60*time.Second)
 
 		require.NotNil(t, client)
-		assert.Equal(t, 60*time.Second, client.httpClient.Timeout)
+		assert.Equal(t, 60*time.Second, client.timeout)
 	})
 }
 
@@ -528,6 +531,24 @@ func TestRewriteProxyPath_EdgeCases(t *testing.T) {
 			serverID: "server-123",
 			wantPath: "",
 		},
+		{
+			name:     "server ID that is a prefix of another returns unchanged",
+			path:     "/api/v1/gateway/server-12/tools/list",
+			serverID: "server-1",
+			wantPath: "/api/v1/gateway/server-12/tools/list",
+		},
+		{
+			name:     "path equal to the prefix becomes root",
+			path:     "/api/v1/gateway/server-123",
+			serverID: "server-123",
+			wantPath: "/",
+		},
+		{
+			name:     "prefix with trailing slash",
+			path:     "/api/v1/gateway/server-123/",
+			serverID: "server-123",
+			wantPath: "/",
+		},
 	}
 
 	for _, tt := range tests {
@@ -722,6 +743,22 @@ func TestSSEClient_ParseSSEResponse(t *testing.T) {
 			wantErr:     true,
 			errContains: "failed to parse JSON-RPC response",
 		},
+		{
+			name:       "SSE response after ping comments",
+			body:       ": ping\n\n: ping\n\n: ping\n\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"tools\":[]},\"id\":1}\n\n",
+			wantResult: true,
+		},
+		{
+			name:       "empty keep-alive after the response is ignored",
+			body:       "data: {\"jsonrpc\":\"2.0\",\"result\":{\"tools\":[]},\"id\":1}\n\n: ping\n\ndata:\n\n",
+			wantResult: true,
+		},
+		{
+			name:        "SSE response with only ping comments",
+			body:        ": ping\n\n: ping\n\n",
+			wantErr:     true,
+			errContains: "no data received",
+		},
 		{
 			name:       "SSE response with multiple events",
 			body:       "event: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"first\":true},\"id\":1}\n\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"last\":true},\"id\":2}\n\n",
@@ -806,6 +843,46 @@ func TestSSEClient_InjectAuth(t *testing.T) {
 			expectedHeader: "",
 			expectedValue:  "",
 		},
+		{
+			name: "api key with custom header",
+			server: &domain.MCPServer{
+				ID:         "server-6",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"header":"X-API-Key","value":"key-123"}`),
+			},
+			expectedHeader: "X-API-Key",
+			expectedValue:  "key-123",
+		},
+		{
+			name: "api key defaults to Authorization header",
+			server: &domain.MCPServer{
+				ID:         "server-7",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"value":"key-123"}`),
+			},
+			expectedHeader: "Authorization",
+			expectedValue:  "key-123",
+		},
+		{
+			name: "api key with prefix",
+			server: &domain.MCPServer{
+				ID:         "server-8",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"header":"Authorization","value":"key-123","prefix":"ApiKey "}`),
+			},
+			expectedHeader: "Authorization",
+			expectedValue:  "ApiKey key-123",
+		},
+		{
+			name: "api key without value",
+			server: &domain.MCPServer{
+				ID:         "server-9",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"header":"Authorization"}`),
+			},
+			expectedHeader: "",
+			expectedValue:  "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -856,7 +933,7 @@ func TestStreamableHTTPClient_ParseJSONResponse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, _, err := client.parseJSONResponse(strings.NewReader(tt.body))
+			result, _, err := client.parseJSONResponse(strings.NewReader(tt.body), "")
 			if tt.wantErr {
 				require.Error(t, err)
 				if tt.errContains != "" {
@@ -909,6 +986,17 @@ func TestStreamableHTTPClient_ParseSSEStream(t *testing.T) {
 			wantErr:     true,
 			errContains: "MCP error -32602",
 		},
+		{
+			name:       "SSE stream with interleaved ping comments",
+			body:       ": ping\n\n: ping\n\nid: event-1\n: ping\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"tools\":[]},\"id\":1}\n\n: ping\n\n",
+			wantResult: true,
+		},
+		{
+			name:        "SSE stream with only keep-alives",
+			body:        ": ping\n\ndata:\n\n: ping\n\n",
+			wantErr:     true,
+			errContains: "no data received",
+		},
 		{
 			name:       "SSE stream with multiple events uses last",
 			body:       "data: {\"jsonrpc\":\"2.0\",\"result\":{\"first\":true},\"id\":1}\n\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"last\":true},\"id\":2}\n\n",
@@ -919,7 +1007,7 @@ func TestStreamableHTTPClient_ParseSSEStream(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, _, err := client.parseSSEStream(strings.NewReader(tt.body))
+			result, _, err := client.parseSSEStream(strings.NewReader(tt.body), "")
 			if tt.wantErr {
 				require.Error(t, err)
 				if tt.errContains != "" {
@@ -984,6 +1072,26 @@ func TestStreamableHTTPClient_InjectAuth(t *testing.T) {
 			expectedHeader: "",
 			expectedValue:  "",
 		},
+		{
+			name: "api key with custom header",
+			server: &domain.MCPServer{
+				ID:         "server-5",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"header":"X-Vendor-Key","value":"vendor-456"}`),
+			},
+			expectedHeader: "X-Vendor-Key",
+			expectedValue:  "vendor-456",
+		},
+		{
+			name: "api key defaults to Authorization header",
+			server: &domain.MCPServer{
+				ID:         "server-6",
+				AuthType:   domain.ServerAuthAPIKey,
+				AuthConfig: json.RawMessage(`{"header":"","value":"vendor-456","prefix":"Token "}`),
+			},
+			expectedHeader: "Authorization",
+			expectedValue:  "Token vendor-456",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1086,6 +1194,9 @@ func TestSSEClient_Call(t *testing.T) {
 		_, err := client.Call(context.Background(), server, "unknown/method", nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "MCP error -32601")
+		rpcErr, ok := AsJSONRPCError(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeMethodNotFound, rpcErr.Code)
 	})
 
 	t.Run("call with params", func(t *testing.T) {
@@ -1150,6 +1261,32 @@ func TestStreamableHTTPClient_Initialize(t *testing.T) {
 		assert.Equal(t, ts.URL, session.ServerURL)
 	})
 
+	t.Run("captures server info and instructions", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "notifications/initialized") {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{"protocolVersion":"2025-11-25","capabilities":{},"serverInfo":{"name":"backend","version":"3.0.2"},"instructions":"Prefer batch lookups"},"id":1}`))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{
+			ID:  "test-server",
+			URL: ts.URL,
+		}
+
+		session, err := client.Initialize(context.Background(), server)
+		require.NoError(t, err)
+		assert.Equal(t, "backend", session.ServerInfo.Name)
+		assert.Equal(t, "3.0.2", session.ServerInfo.Version)
+		assert.Equal(t, "Prefer batch lookups", session.Instructions)
+	})
+
 	t.Run("initialization failure", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -1327,6 +1464,29 @@ func TestStreamableHTTPClient_Call(t *testing.T) {
 		assert.NotNil(t, result)
 	})
 
+	t.Run("call with streamed response after ping comments", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < 3; i++ {
+				w.Write([]byte(": ping\n\n"))
+				w.(http.Flusher).Flush()
+			}
+			w.Write([]byte("event: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"data\":\"test\"},\"id\":1}\n\n"))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{
+			ID:  "test-server",
+			URL: ts.URL,
+		}
+
+		result, err := client.Call(context.Background(), server, "test/method", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data":"test"}`, string(result))
+	})
+
 	t.Run("call without existing session", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
@@ -1581,9 +1741,14 @@ type mockStreamableHTTPClient struct {
 	initSession     *MCPSession
 	callResult      json.RawMessage
 	terminateCalled bool
+	calls           int
+	mu              sync.Mutex
 }
 
 func (m *mockStreamableHTTPClient) Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
 	if m.callErr != nil {
 		return nil, m.callErr
 	}
@@ -1591,6 +1756,12 @@ func (m *mockStreamableHTTPClient) Call(ctx context.Context, server *domain.MCPS
 	return m.callResult, nil
 }
 
+func (m *mockStreamableHTTPClient) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
 func (m *mockStreamableHTTPClient) Initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error) {
 	if m.initErr != nil {
 		return nil, m.initErr
@@ -1605,6 +1776,166 @@ func (m *mockStreamableHTTPClient) TerminateSession(ctx context.Context, server
 	return m.terminateErr
 }
 
+func (m *mockStreamableHTTPClient) OpenEventStream(ctx context.Context, server *domain.MCPServer) (io.ReadCloser, error) {
+	return nil, ErrEventStreamUnsupported
+}
+
+func (m *mockStreamableHTTPClient) Respond(ctx context.Context, server *domain.MCPServer, id, result json.RawMessage, rpcErr *JSONRPCError) error {
+	return nil
+}
+
+func TestStreamableHTTPClient_CallBatch(t *testing.T) {
+	log := logger.NewNopLogger()
+
+	t.Run("correlates out-of-order responses by id", func(t *testing.T) {
+		var received []map[string]interface{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &received))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[
+				{"jsonrpc":"2.0","result":{"prompts":[]},"id":3},
+				{"jsonrpc":"2.0","result":{"tools":[]},"id":1},
+				{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2}
+			]`))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		responses, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{
+			{Method: "tools/list", ID: 1},
+			{Method: "resources/list", ID: 2},
+			{Method: "prompts/list", ID: 3},
+		})
+		require.NoError(t, err)
+		require.Len(t, responses, 3)
+		require.Len(t, received, 3)
+
+		assert.JSONEq(t, `{"tools":[]}`, string(responses[0].Result))
+		require.NotNil(t, responses[1].Error)
+		assert.Equal(t, -32601, responses[1].Error.Code)
+		assert.JSONEq(t, `{"prompts":[]}`, string(responses[2].Result))
+	})
+
+	t.Run("accepts single object reply", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{"tools":[]},"id":7}`))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		responses, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{
+			{Method: "tools/list", ID: 7},
+		})
+		require.NoError(t, err)
+		require.Len(t, responses, 1)
+		assert.JSONEq(t, `{"tools":[]}`, string(responses[0].Result))
+	})
+
+	t.Run("sends notifications without id", func(t *testing.T) {
+		var received []map[string]interface{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &received))
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("data: {\"jsonrpc\":\"2.0\",\"result\":{},\"id\":5}\n\n"))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		responses, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{
+			{Method: "notifications/initialized"},
+			{Method: "ping", ID: 5},
+		})
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		require.Len(t, received, 2)
+
+		_, hasID := received[0]["id"]
+		assert.False(t, hasID)
+		assert.Nil(t, responses[0].Result)
+		assert.Nil(t, responses[0].Error)
+		assert.JSONEq(t, `{}`, string(responses[1].Result))
+	})
+
+	t.Run("only notifications accepted with 202", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		responses, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{
+			{Method: "notifications/initialized"},
+		})
+		require.NoError(t, err)
+		assert.Len(t, responses, 1)
+	})
+
+	t.Run("missing response yields error entry", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`[{"jsonrpc":"2.0","result":{},"id":1}]`))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		responses, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{
+			{Method: "tools/list", ID: 1},
+			{Method: "resources/list", ID: 2},
+		})
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		assert.Nil(t, responses[0].Error)
+		require.NotNil(t, responses[1].Error)
+		assert.Equal(t, -32603, responses[1].Error.Code)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		_, err := client.CallBatch(context.Background(), &domain.MCPServer{ID: "batch-server"}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		_, err := client.CallBatch(context.Background(), &domain.MCPServer{ID: "batch-server"}, []JSONRPCRequest{
+			{Method: "tools/list", ID: 1},
+			{Method: "resources/list", ID: 1},
+		})
+		assert.ErrorContains(t, err, "duplicate request id")
+	})
+
+	t.Run("server error status", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("boom"))
+		}))
+		defer ts.Close()
+
+		client := NewStreamableHTTPClient(log, 30*time.Second)
+		server := &domain.MCPServer{ID: "batch-server", URL: ts.URL}
+
+		_, err := client.CallBatch(context.Background(), server, []JSONRPCRequest{{Method: "tools/list"}})
+		assert.ErrorContains(t, err, "500")
+	})
+}
+
 // Service method tests using mocks
 
 func TestNewServiceWithClients(t *testing.T) {
@@ -1949,6 +2280,104 @@ func TestService_CallStreamableHTTP(t *testing.T) {
 	})
 }
 
+func TestService_CircuitBreaker(t *testing.T) {
+	newService := func(client *mockStreamableHTTPClient) *Service {
+		mockRepo := &mockServerRepository{
+			server: &domain.MCPServer{ID: "server-123", IsActive: true},
+		}
+		svc := NewServiceWithClients(mockRepo, logger.NewNopLogger(), nil, nil, client)
+		svc.breakers = NewBreakerRegistry(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+		return svc
+	}
+
+	t.Run("opens after consecutive failures and rejects calls", func(t *testing.T) {
+		svc := newService(&mockStreamableHTTPClient{callErr: errors.New("connection refused")})
+
+		for i := 0; i < 2; i++ {
+			_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+			assert.ErrorContains(t, err, "connection refused")
+		}
+
+		_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("JSON-RPC errors do not count as failures", func(t *testing.T) {
+		svc := newService(&mockStreamableHTTPClient{callErr: &JSONRPCError{Code: CodeInvalidParams, Message: "invalid params"}})
+
+		for i := 0; i < 3; i++ {
+			_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/call", nil)
+			assert.ErrorContains(t, err, "MCP error")
+		}
+		assert.Equal(t, BreakerClosed, svc.breakers.Get("server-123").State())
+	})
+
+	t.Run("reset closes an open breaker", func(t *testing.T) {
+		client := &mockStreamableHTTPClient{callErr: errors.New("connection refused")}
+		svc := newService(client)
+		for i := 0; i < 2; i++ {
+			_, _ = svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+		}
+		require.Equal(t, BreakerOpen, svc.breakers.Get("server-123").State())
+
+		assert.True(t, svc.ResetBreaker("server-123"))
+		assert.False(t, svc.ResetBreaker("server-123"))
+
+		client.callErr = nil
+		client.callResult = json.RawMessage(`{"tools":[]}`)
+		_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("opens when successful calls are over the latency budget", func(t *testing.T) {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(30 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`))
+		}))
+		defer backend.Close()
+
+		mockRepo := &mockServerRepository{
+			server: &domain.MCPServer{ID: "server-123", URL: backend.URL, IsActive: true, LatencyBudgetMs: 10},
+		}
+		svc := NewServiceWithClients(mockRepo, logger.NewNopLogger(), nil, nil, NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second))
+		svc.breakers = NewBreakerRegistry(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute, SlowCallRatio: 1, SlowCallWindow: 3})
+
+		for i := 0; i < 3; i++ {
+			_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, BreakerOpen, svc.breakers.Get("server-123").State())
+
+		_, err := svc.CallStreamableHTTP(context.Background(), "server-123", "tools/list", nil)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("disabled when no breakers configured", func(t *testing.T) {
+		svc := NewServiceWithClients(nil, logger.NewNopLogger(), nil, nil, nil)
+
+		assert.NoError(t, svc.allowCall("server-123"))
+		assert.False(t, svc.ResetBreaker("server-123"))
+	})
+}
+
+func TestNewServiceWithConfig(t *testing.T) {
+	t.Run("enables circuit breakers", func(t *testing.T) {
+		svc := NewServiceWithConfig(nil, logger.NewNopLogger(), nil, config.GatewayConfig{
+			CircuitBreaker: config.CircuitBreakerConfig{Enabled: true, FailureThreshold: 3, OpenTimeout: time.Second},
+		})
+
+		require.NotNil(t, svc.breakers)
+		assert.Equal(t, 3, svc.breakers.Get("server-1").config.FailureThreshold)
+	})
+
+	t.Run("breakers disabled", func(t *testing.T) {
+		svc := NewServiceWithConfig(nil, logger.NewNopLogger(), nil, config.GatewayConfig{})
+
+		assert.Nil(t, svc.breakers)
+	})
+}
+
 func TestService_InitializeStreamableHTTP(t *testing.T) {
 	t.Run("returns error when server not found", func(t *testing.T) {
 		mockRepo := &mockServerRepository{
@@ -2156,6 +2585,35 @@ func TestService_InjectAuth(t *testing.T) {
 		assert.Equal(t, "pass", pass)
 	})
 
+	t.Run("api key with custom header", func(t *testing.T) {
+		svc := NewServiceWithClients(nil, log, nil, nil, nil)
+		req := httptest.NewRequest("GET", "/test", nil)
+		server := &domain.MCPServer{
+			ID:         "server-123",
+			AuthType:   domain.ServerAuthAPIKey,
+			AuthConfig: json.RawMessage(`{"header":"X-API-Key","value":"my-api-key"}`),
+		}
+
+		svc.injectAuth(req, server)
+
+		assert.Equal(t, "my-api-key", req.Header.Get("X-API-Key"))
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+
+	t.Run("api key defaults to Authorization with prefix", func(t *testing.T) {
+		svc := NewServiceWithClients(nil, log, nil, nil, nil)
+		req := httptest.NewRequest("GET", "/test", nil)
+		server := &domain.MCPServer{
+			ID:         "server-123",
+			AuthType:   domain.ServerAuthAPIKey,
+			AuthConfig: json.RawMessage(`{"value":"my-api-key","prefix":"ApiKey "}`),
+		}
+
+		svc.injectAuth(req, server)
+
+		assert.Equal(t, "ApiKey my-api-key", req.Header.Get("Authorization"))
+	})
+
 	t.Run("invalid auth config JSON", func(t *testing.T) {
 		svc := NewServiceWithClients(nil, log, nil, nil, nil)
 		req := httptest.NewRequest("GET", "/test", nil)
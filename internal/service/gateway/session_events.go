@@ -0,0 +1,27 @@
+package gateway
+
+// SessionEvent names a transition in the lifecycle of an MCP session with a server
+type SessionEvent string
+
+// Session lifecycle events, logged at debug level with the server ID, session ID and the
+// reason for the transition. Enable them with logging.level or logging.subsystems.gateway
+// set to debug.
+const (
+	SessionEventCreate       SessionEvent = "create"       // The server accepted initialize
+	SessionEventInitialize   SessionEvent = "initialize"   // The initialized notification was sent
+	SessionEventReinitialize SessionEvent = "reinitialize" // A lost session is being replaced
+	SessionEventExpire       SessionEvent = "expire"       // The server no longer knows the session
+	SessionEventTerminate    SessionEvent = "terminate"    // The gateway ended the session
+	SessionEventResume       SessionEvent = "resume"       // A persisted session was restored
+	SessionEventEvict        SessionEvent = "evict"        // The session was idle or least recently used past the cap
+)
+
+// logSessionEvent records a session lifecycle transition
+func (c *StreamableHTTPClient) logSessionEvent(event SessionEvent, serverID, sessionID, reason string) {
+	c.logger.Debug().
+		Str("session_event", string(event)).
+		Str("server_id", serverID).
+		Str("session_id", sessionID).
+		Str("reason", reason).
+		Msg("MCP session lifecycle event")
+}
@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// loggedSessionEvent is the part of a session lifecycle log entry the tests check
+type loggedSessionEvent struct {
+	Level     string `json:"level"`
+	Event     string `json:"session_event"`
+	ServerID  string `json:"server_id"`
+	SessionID string `json:"session_id"`
+	Reason    string `json:"reason"`
+}
+
+// sessionEvents returns the session lifecycle entries in JSON log output
+func sessionEvents(t *testing.T, output *bytes.Buffer) []loggedSessionEvent {
+	t.Helper()
+	var events []loggedSessionEvent
+	scanner := bufio.NewScanner(bytes.NewReader(output.Bytes()))
+	for scanner.Scan() {
+		var entry loggedSessionEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		if entry.Event != "" {
+			events = append(events, entry)
+		}
+	}
+	return events
+}
+
+func TestStreamableHTTPClient_SessionLifecycleLogs(t *testing.T) {
+	var output bytes.Buffer
+	log := logger.NewZerolog(logger.Config{Level: logger.DebugLevel, Format: "json", Output: &output})
+
+	ts := httptest.NewServer(&sessionBackend{})
+	defer ts.Close()
+	server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+	client := NewStreamableHTTPClient(log, 5*time.Second)
+
+	_, err := client.Initialize(context.Background(), server)
+	require.NoError(t, err)
+
+	events := sessionEvents(t, &output)
+	require.Len(t, events, 2)
+	assert.Equal(t, "create", events[0].Event)
+	assert.Equal(t, "initialize", events[1].Event)
+	for _, event := range events {
+		assert.Equal(t, "debug", event.Level)
+		assert.Equal(t, "server-1", event.ServerID)
+		assert.Equal(t, "session-1", event.SessionID)
+		assert.NotEmpty(t, event.Reason)
+	}
+
+	output.Reset()
+	require.NoError(t, client.TerminateSession(context.Background(), server))
+
+	events = sessionEvents(t, &output)
+	require.Len(t, events, 1)
+	assert.Equal(t, loggedSessionEvent{
+		Level:     "debug",
+		Event:     "terminate",
+		ServerID:  "server-1",
+		SessionID: "session-1",
+		Reason:    "DELETE returned 200",
+	}, events[0])
+}
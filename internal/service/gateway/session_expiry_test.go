@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// expiringBackend hands out a new session for every initialize and answers requests in
+// the sessions it has expired with 404
+type expiringBackend struct {
+	mu          sync.Mutex
+	initializes int
+	expired     map[string]bool
+	expireAll   bool     // Expire every session as soon as it is used
+	sessions    []string // Session of each tools/list received
+}
+
+func (b *expiringBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+	sessionID := r.Header.Get(HeaderMCPSessionID)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case msg.Method == "initialize":
+		b.initializes++
+		w.Header().Set(HeaderMCPSessionID, fmt.Sprintf("session-%d", b.initializes))
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+	case isNotification(msg.Method):
+		w.WriteHeader(http.StatusAccepted)
+	case b.expireAll || b.expired[sessionID]:
+		b.sessions = append(b.sessions, sessionID)
+		http.Error(w, "Session not found", http.StatusNotFound)
+	default:
+		b.sessions = append(b.sessions, sessionID)
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID)
+	}
+}
+
+func TestStreamableHTTPClient_ReinitializesExpiredSession(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(t *testing.T, backend *expiringBackend) (*StreamableHTTPClient, *domain.MCPServer) {
+		ts := httptest.NewServer(backend)
+		t.Cleanup(ts.Close)
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		return client, &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true, RequireInitialize: true}
+	}
+
+	t.Run("retries once in a new session", func(t *testing.T) {
+		backend := &expiringBackend{expired: map[string]bool{}}
+		client, server := newClient(t, backend)
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+
+		// The server restarts and forgets session-1
+		backend.mu.Lock()
+		backend.expired["session-1"] = true
+		backend.mu.Unlock()
+
+		result, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"tools":[]}`, string(result))
+		assert.Equal(t, 2, backend.initializes)
+		assert.Equal(t, []string{"session-1", "session-1", "session-2"}, backend.sessions)
+		assert.Equal(t, "session-2", client.getSession(server.ID).SessionID)
+	})
+
+	t.Run("gives up on a persistent 404", func(t *testing.T) {
+		backend := &expiringBackend{expireAll: true}
+		client, server := newClient(t, backend)
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		assert.ErrorIs(t, err, ErrSessionExpired)
+		assert.Equal(t, 2, backend.initializes, "the first session and one re-initialize")
+		assert.Equal(t, []string{"session-1", "session-2"}, backend.sessions)
+	})
+
+	t.Run("a 404 without a session is not an expiry", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.NotFound(w, r)
+		}))
+		defer ts.Close()
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		server := &domain.MCPServer{ID: "server-1", URL: ts.URL + "/wrong-path", IsActive: true}
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.NotErrorIs(t, err, ErrSessionExpired)
+		assert.Equal(t, int32(1), requests.Load(), "no initialize or retry")
+	})
+}
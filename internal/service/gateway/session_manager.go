@@ -0,0 +1,264 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// Reasons a session is evicted, reported as the reason label of the eviction metric
+const (
+	sessionEvictIdle     = "idle"     // Unused for longer than the idle TTL
+	sessionEvictCapacity = "capacity" // Least recently used when over the session cap
+)
+
+// sessionTerminateTimeout bounds the DELETE that ends an evicted session
+const sessionTerminateTimeout = 10 * time.Second
+
+// initFlight is an initialize, or a retried handshake, in progress for one server.
+// Callers that need the server's session while it runs wait for its result instead of
+// starting their own.
+type initFlight struct {
+	done    chan struct{}
+	session *MCPSession
+	err     error
+}
+
+// wait returns the flight's result once it is done, or ctx's error if that comes first
+func (f *initFlight) wait(ctx context.Context) (*MCPSession, error) {
+	select {
+	case <-f.done:
+		return f.session, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SetSessionLimits bounds the sessions the client keeps. A session unused for idleTTL is
+// evicted (0 = never), and past maxSessions the least recently used one is (0 = unlimited).
+// Evicted sessions are terminated on their server. Must be called before the client is used.
+func (c *StreamableHTTPClient) SetSessionLimits(idleTTL time.Duration, maxSessions int) {
+	c.sessionTTL = idleTTL
+	c.maxSessions = maxSessions
+}
+
+// SetMetrics makes the client report its active sessions and evictions to metricsReg.
+// Must be called before the client is used.
+func (c *StreamableHTTPClient) SetMetrics(metricsReg *metrics.Registry) {
+	c.metrics = metricsReg
+}
+
+// initFlightLocked returns the server's initialize in progress, or registers a new one
+// that the caller leads by running it with runInitFlight. The caller must hold sessionsMu.
+func (c *StreamableHTTPClient) initFlightLocked(serverID string) (flight *initFlight, leader bool) {
+	if flight, ok := c.initFlights[serverID]; ok {
+		return flight, false
+	}
+	flight = &initFlight{done: make(chan struct{})}
+	c.initFlights[serverID] = flight
+	return flight, true
+}
+
+// runInitFlight runs fn for a flight the caller leads and hands its result to the callers
+// waiting on it. The callers waiting shouldn't fail because the leader gave up, so fn runs
+// detached from the cancellation of ctx, bounded by the server's call timeout instead;
+// the leader itself stops waiting when ctx is done, like the others.
+func (c *StreamableHTTPClient) runInitFlight(ctx context.Context, server *domain.MCPServer, flight *initFlight, fn func(ctx context.Context) (*MCPSession, error)) (*MCPSession, error) {
+	flightCtx, cancel := withCallTimeout(context.WithoutCancel(ctx), server, "initialize", c.timeout, 0)
+	go func() {
+		defer func() {
+			cancel()
+			c.sessionsMu.Lock()
+			delete(c.initFlights, server.ID)
+			c.sessionsMu.Unlock()
+			close(flight.done)
+		}()
+		flight.session, flight.err = fn(flightCtx)
+	}()
+	return flight.wait(ctx)
+}
+
+// ensureSession returns the server's initialized session, initializing the server or
+// retrying the session's handshake if needed. Concurrent callers share one attempt, so a
+// burst of first requests creates a single session.
+func (c *StreamableHTTPClient) ensureSession(ctx context.Context, server *domain.MCPServer) (*MCPSession, error) {
+	c.sessionsMu.Lock()
+	session := c.sessions[server.ID]
+	if _, running := c.initFlights[server.ID]; !running && session != nil && session.isInitialized() {
+		c.sessionsMu.Unlock()
+		return session, nil
+	}
+	flight, leader := c.initFlightLocked(server.ID)
+	c.sessionsMu.Unlock()
+	if !leader {
+		return flight.wait(ctx)
+	}
+
+	return c.runInitFlight(ctx, server, flight, func(ctx context.Context) (*MCPSession, error) {
+		if session == nil {
+			return c.initialize(ctx, server)
+		}
+		// The handshake didn't complete; the backend may not have been ready
+		if !c.completeHandshake(ctx, server, session) {
+			return nil, fmt.Errorf("%w: initialized notification failed", ErrSessionNotInitialized)
+		}
+		return session, nil
+	})
+}
+
+// getSession returns the session for a server if it exists, marking it used. A session
+// idle for longer than the TTL is evicted instead.
+func (c *StreamableHTTPClient) getSession(serverID string) *MCPSession {
+	c.sessionsMu.RLock()
+	session := c.sessions[serverID]
+	c.sessionsMu.RUnlock()
+	if session == nil {
+		return nil
+	}
+
+	now := c.now()
+	if c.sessionIdle(session, now) {
+		c.evictSession(session, sessionEvictIdle)
+		return nil
+	}
+	session.touch(now)
+	return session
+}
+
+// storeSession makes session its server's current session, evicting idle sessions and,
+// over the cap, the least recently used ones
+func (c *StreamableHTTPClient) storeSession(session *MCPSession) {
+	now := c.now()
+	session.touch(now)
+
+	c.sessionsMu.Lock()
+	c.sessions[session.ServerID] = session
+	var evicted []*MCPSession
+	var reasons []string
+	for _, s := range c.sessions {
+		if c.sessionIdle(s, now) {
+			evicted, reasons = append(evicted, s), append(reasons, sessionEvictIdle)
+			delete(c.sessions, s.ServerID)
+		}
+	}
+	for c.maxSessions > 0 && len(c.sessions) > c.maxSessions {
+		oldest := c.leastRecentlyUsedLocked()
+		evicted, reasons = append(evicted, oldest), append(reasons, sessionEvictCapacity)
+		delete(c.sessions, oldest.ServerID)
+	}
+	c.reportSessionsLocked()
+	c.sessionsMu.Unlock()
+
+	for i, s := range evicted {
+		c.endEvictedSession(s, reasons[i])
+	}
+}
+
+// leastRecentlyUsedLocked returns the session unused for the longest. The caller must hold
+// sessionsMu, and there must be a session.
+func (c *StreamableHTTPClient) leastRecentlyUsedLocked() *MCPSession {
+	var oldest *MCPSession
+	var oldestUse time.Time
+	for _, s := range c.sessions {
+		if used := s.lastUsedAt(); oldest == nil || used.Before(oldestUse) {
+			oldest, oldestUse = s, used
+		}
+	}
+	return oldest
+}
+
+// evictSession drops session, if it is still its server's current one, and terminates it
+func (c *StreamableHTTPClient) evictSession(session *MCPSession, reason string) {
+	c.sessionsMu.Lock()
+	current := c.sessions[session.ServerID] == session
+	if current {
+		delete(c.sessions, session.ServerID)
+		c.reportSessionsLocked()
+	}
+	c.sessionsMu.Unlock()
+	if current {
+		c.endEvictedSession(session, reason)
+	}
+}
+
+// endEvictedSession records an eviction and terminates the session on its server in the
+// background, so the request that caused the eviction isn't held up
+func (c *StreamableHTTPClient) endEvictedSession(session *MCPSession, reason string) {
+	c.forgetSession(session.ServerID)
+	c.logSessionEvent(SessionEventEvict, session.ServerID, session.SessionID, "evicted: "+reason)
+	if c.metrics != nil {
+		c.metrics.GatewaySessionEvictions.WithLabelValues(reason).Inc()
+	}
+	if session.server == nil || session.SessionID == "" {
+		return // Restored from the session store; nothing to terminate it with
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionTerminateTimeout)
+		defer cancel()
+		if err := c.terminate(ctx, session.server, session); err != nil {
+			c.logger.Debug().Err(err).Str("server_id", session.ServerID).Msg("Failed to terminate evicted MCP session")
+		}
+	}()
+}
+
+// removeSession drops session if it is still its server's current one
+func (c *StreamableHTTPClient) removeSession(session *MCPSession) {
+	c.sessionsMu.Lock()
+	current := c.sessions[session.ServerID] == session
+	if current {
+		delete(c.sessions, session.ServerID)
+		c.reportSessionsLocked()
+	}
+	c.sessionsMu.Unlock()
+	if current {
+		c.forgetSession(session.ServerID)
+	}
+}
+
+// ForgetSession drops the client's session with a server without terminating it on the
+// server, for a server ID that won't be called again
+func (c *StreamableHTTPClient) ForgetSession(serverID string) {
+	c.clearSession(serverID)
+}
+
+// clearSession removes a session for a server
+func (c *StreamableHTTPClient) clearSession(serverID string) {
+	c.sessionsMu.Lock()
+	delete(c.sessions, serverID)
+	c.reportSessionsLocked()
+	c.sessionsMu.Unlock()
+	c.forgetSession(serverID)
+}
+
+// sessionIdle reports whether session has gone unused for longer than the idle TTL
+func (c *StreamableHTTPClient) sessionIdle(session *MCPSession, now time.Time) bool {
+	return c.sessionTTL > 0 && now.Sub(session.lastUsedAt()) > c.sessionTTL
+}
+
+// reportSessionsLocked updates the active session gauge. The caller must hold sessionsMu.
+func (c *StreamableHTTPClient) reportSessionsLocked() {
+	if c.metrics != nil {
+		c.metrics.GatewaySessionsActive.Set(float64(len(c.sessions)))
+	}
+}
+
+// touch marks the session used at now
+func (s *MCPSession) touch(now time.Time) {
+	s.mu.Lock()
+	s.lastUsed = now
+	s.mu.Unlock()
+}
+
+// lastUsedAt returns when the session was last used, or created if it never was
+func (s *MCPSession) lastUsedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lastUsed.IsZero() {
+		return s.CreatedAt
+	}
+	return s.lastUsed
+}
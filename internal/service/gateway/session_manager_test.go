@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// evictionBackend hands out a new session ID for every initialize and records the
+// initializes and the sessions terminated with DELETE, with the credentials each DELETE carried
+type evictionBackend struct {
+	mu             sync.Mutex
+	initializes    int
+	terminated     []string
+	terminateAuths []string
+}
+
+func (b *evictionBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		b.mu.Lock()
+		b.terminated = append(b.terminated, r.Header.Get(HeaderMCPSessionID))
+		b.terminateAuths = append(b.terminateAuths, r.Header.Get("Authorization"))
+		b.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+	switch {
+	case msg.Method == "initialize":
+		// Slow enough that concurrent first calls overlap
+		time.Sleep(20 * time.Millisecond)
+		b.mu.Lock()
+		b.initializes++
+		sessionID := fmt.Sprintf("session-%d", b.initializes)
+		b.mu.Unlock()
+		w.Header().Set(HeaderMCPSessionID, sessionID)
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-06-18"}}`, msg.ID)
+	case r.Header.Get(HeaderMCPSessionID) == "":
+		http.Error(w, "initialize first", http.StatusBadRequest)
+	case isNotification(msg.Method):
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID)
+	}
+}
+
+func (b *evictionBackend) counts() (int, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.initializes, append([]string(nil), b.terminated...)
+}
+
+func (b *evictionBackend) terminateAuthorizations() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.terminateAuths...)
+}
+
+func newEvictionBackend(t *testing.T) (*evictionBackend, string) {
+	t.Helper()
+	backend := &evictionBackend{}
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+	return backend, ts.URL
+}
+
+func TestStreamableHTTPClient_ConcurrentFirstCallsShareOneInitialize(t *testing.T) {
+	backend, url := newEvictionBackend(t)
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	server := &domain.MCPServer{ID: "server-1", URL: url, IsActive: true, RequireInitialize: true}
+
+	const calls = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Call(context.Background(), server, "tools/list", nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	initializes, _ := backend.counts()
+	assert.Equal(t, 1, initializes)
+}
+
+func TestStreamableHTTPClient_SharedInitializeOutlivesLeader(t *testing.T) {
+	backend, url := newEvictionBackend(t)
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	server := &domain.MCPServer{ID: "server-1", URL: url, IsActive: true, RequireInitialize: true}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.Initialize(leaderCtx, server)
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool {
+		client.sessionsMu.Lock()
+		defer client.sessionsMu.Unlock()
+		return client.initFlights[server.ID] != nil
+	}, time.Second, time.Millisecond)
+
+	waiterSession := make(chan *MCPSession, 1)
+	go func() {
+		session, err := client.Initialize(context.Background(), server)
+		assert.NoError(t, err)
+		waiterSession <- session
+	}()
+	cancel()
+
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	session := <-waiterSession
+	require.NotNil(t, session)
+	assert.Equal(t, "session-1", session.SessionID)
+	initializes, _ := backend.counts()
+	assert.Equal(t, 1, initializes)
+}
+
+func TestStreamableHTTPClient_SessionEviction(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(idleTTL time.Duration, maxSessions int) (*StreamableHTTPClient, *metrics.Registry, *time.Time) {
+		client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+		client.SetSessionLimits(idleTTL, maxSessions)
+		reg := metrics.NewRegistry()
+		client.SetMetrics(reg)
+		now := time.Now()
+		client.now = func() time.Time { return now }
+		return client, reg, &now
+	}
+
+	t.Run("evicts and terminates idle sessions", func(t *testing.T) {
+		backend, url := newEvictionBackend(t)
+		client, reg, now := newClient(time.Minute, 0)
+		server := &domain.MCPServer{ID: "server-1", URL: url, IsActive: true, RequireInitialize: true}
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.GatewaySessionsActive))
+
+		*now = now.Add(2 * time.Minute)
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+
+		initializes, _ := backend.counts()
+		assert.Equal(t, 2, initializes, "the idle session was replaced")
+		assert.Eventually(t, func() bool {
+			_, terminated := backend.counts()
+			return assert.ObjectsAreEqual([]string{"session-1"}, terminated)
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.GatewaySessionEvictions.WithLabelValues(sessionEvictIdle)))
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.GatewaySessionsActive))
+	})
+
+	t.Run("evicts the least recently used session over the cap", func(t *testing.T) {
+		backend, url := newEvictionBackend(t)
+		client, reg, now := newClient(0, 2)
+		servers := make([]*domain.MCPServer, 3)
+		for i := range servers {
+			servers[i] = &domain.MCPServer{ID: fmt.Sprintf("server-%d", i+1), URL: url, IsActive: true, RequireInitialize: true}
+		}
+
+		for _, server := range servers[:2] {
+			_, err := client.Call(ctx, server, "tools/list", nil)
+			require.NoError(t, err)
+			*now = now.Add(time.Second)
+		}
+		// server-1 is used again, leaving server-2 least recently used
+		_, err := client.Call(ctx, servers[0], "tools/list", nil)
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+
+		_, err = client.Call(ctx, servers[2], "tools/list", nil)
+		require.NoError(t, err)
+
+		assert.NotNil(t, client.getSession("server-1"))
+		assert.Nil(t, client.getSession("server-2"))
+		assert.NotNil(t, client.getSession("server-3"))
+		assert.Eventually(t, func() bool {
+			_, terminated := backend.counts()
+			return assert.ObjectsAreEqual([]string{"session-2"}, terminated)
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, float64(1), testutil.ToFloat64(reg.GatewaySessionEvictions.WithLabelValues(sessionEvictCapacity)))
+		assert.Equal(t, float64(2), testutil.ToFloat64(reg.GatewaySessionsActive))
+	})
+
+	t.Run("terminates evicted sessions with the server's credentials", func(t *testing.T) {
+		backend, url := newEvictionBackend(t)
+		client, _, now := newClient(time.Minute, 0)
+		server := &domain.MCPServer{
+			ID:                "server-1",
+			URL:               url,
+			IsActive:          true,
+			RequireInitialize: true,
+			AuthType:          domain.ServerAuthBearer,
+			AuthConfig:        json.RawMessage(`{"token":"secret"}`),
+		}
+
+		_, err := client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+		*now = now.Add(2 * time.Minute)
+		_, err = client.Call(ctx, server, "tools/list", nil)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			return assert.ObjectsAreEqual([]string{"Bearer secret"}, backend.terminateAuthorizations())
+		}, time.Second, 5*time.Millisecond)
+	})
+}
@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// sessionStoreTimeout bounds each session store operation so a slow database can't stall proxying
+const sessionStoreTimeout = 5 * time.Second
+
+// SessionStore persists MCP sessions so they survive a gateway restart
+type SessionStore interface {
+	Save(ctx context.Context, session *domain.PersistedSession) error
+	Delete(ctx context.Context, serverID string) error
+	List(ctx context.Context) ([]*domain.PersistedSession, error)
+}
+
+// SetSessionStore makes the client save its sessions to store as they are created and
+// updated, and delete them when they end. Must be called before the client is used.
+func (c *StreamableHTTPClient) SetSessionStore(store SessionStore) {
+	c.store = store
+}
+
+// LoadSessions restores the sessions saved in the session store, so requests resume them
+// without re-initializing. A session the server no longer honors is re-initialized on its
+// first 404 as usual. It returns the number of sessions restored.
+func (c *StreamableHTTPClient) LoadSessions(ctx context.Context) (int, error) {
+	if c.store == nil {
+		return 0, nil
+	}
+	saved, err := c.store.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	restored := 0
+	for _, s := range saved {
+		// A version the gateway no longer speaks means the session can't be resumed
+		if !IsSupportedProtocolVersion(s.ProtocolVersion) {
+			continue
+		}
+		if _, ok := c.sessions[s.ServerID]; ok {
+			continue
+		}
+		c.sessions[s.ServerID] = &MCPSession{
+			SessionID:       s.SessionID,
+			ServerID:        s.ServerID,
+			Initialized:     true,
+			ProtocolVersion: s.ProtocolVersion,
+			LastEventID:     s.LastEventID,
+			CreatedAt:       s.UpdatedAt,
+			lastUsed:        c.now(),
+		}
+		c.logSessionEvent(SessionEventResume, s.ServerID, s.SessionID, "restored from session store")
+		restored++
+	}
+	c.reportSessionsLocked()
+	return restored, nil
+}
+
+// persistSession saves a session to the session store, if there is one. Failures are
+// logged rather than returned: the session still works, it just won't survive a restart.
+func (c *StreamableHTTPClient) persistSession(session *MCPSession) {
+	if c.store == nil {
+		return
+	}
+	session.mu.RLock()
+	saved := &domain.PersistedSession{
+		ServerID:        session.ServerID,
+		SessionID:       session.SessionID,
+		ProtocolVersion: session.ProtocolVersion,
+		LastEventID:     session.LastEventID,
+		UpdatedAt:       time.Now(),
+	}
+	session.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTimeout)
+	defer cancel()
+	if err := c.store.Save(ctx, saved); err != nil {
+		c.logger.Warn().Err(err).Str("server_id", saved.ServerID).Msg("Failed to persist MCP session")
+	}
+}
+
+// forgetSession deletes a server's session from the session store, if there is one
+func (c *StreamableHTTPClient) forgetSession(serverID string) {
+	if c.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTimeout)
+	defer cancel()
+	if err := c.store.Delete(ctx, serverID); err != nil {
+		c.logger.Warn().Err(err).Str("server_id", serverID).Msg("Failed to delete persisted MCP session")
+	}
+}
+
+// PersistSessions saves Streamable HTTP sessions to store and restores the ones saved
+// before the last restart. It is a no-op when the service uses a different client.
+func (s *Service) PersistSessions(ctx context.Context, store SessionStore) error {
+	client, ok := s.streamableHTTPClient.(*StreamableHTTPClient)
+	if !ok {
+		return nil
+	}
+	client.SetSessionStore(store)
+	restored, err := client.LoadSessions(ctx)
+	if err != nil {
+		return err
+	}
+	s.logger.Info().Int("sessions", restored).Msg("Restored persisted MCP sessions")
+	return nil
+}
@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// memorySessionStore is a SessionStore backed by a map, standing in for the database
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]domain.PersistedSession
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]domain.PersistedSession)}
+}
+
+func (m *memorySessionStore) Save(_ context.Context, session *domain.PersistedSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ServerID] = *session
+	return nil
+}
+
+func (m *memorySessionStore) Delete(_ context.Context, serverID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, serverID)
+	return nil
+}
+
+func (m *memorySessionStore) List(_ context.Context) ([]*domain.PersistedSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessions := make([]*domain.PersistedSession, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, &s)
+	}
+	return sessions, nil
+}
+
+func (m *memorySessionStore) get(serverID string) (domain.PersistedSession, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[serverID]
+	return s, ok
+}
+
+// sessionBackend hands out session-1 on initialize and records the session and protocol
+// version headers of every other request
+type sessionBackend struct {
+	mu        sync.Mutex
+	methods   []string
+	sessionID []string
+	versions  []string
+}
+
+func (b *sessionBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req JSONRPCRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	b.mu.Lock()
+	b.methods = append(b.methods, req.Method)
+	b.sessionID = append(b.sessionID, r.Header.Get(HeaderMCPSessionID))
+	b.versions = append(b.versions, r.Header.Get(HeaderMCPProtocolVersion))
+	b.mu.Unlock()
+
+	if isNotification(req.Method) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if req.Method == "initialize" {
+		w.Header().Set(HeaderMCPSessionID, "session-1")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"protocolVersion":"2025-06-18"}}`, req.ID)
+		return
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, req.ID)
+}
+
+func TestStreamableHTTPClient_PersistedSessions(t *testing.T) {
+	log := logger.NewNopLogger()
+	backend := &sessionBackend{}
+	ts := httptest.NewServer(backend)
+	defer ts.Close()
+	server := &domain.MCPServer{ID: "server-1", URL: ts.URL, IsActive: true}
+	store := newMemorySessionStore()
+
+	client := NewStreamableHTTPClient(log, 5*time.Second)
+	client.SetSessionStore(store)
+	_, err := client.Initialize(context.Background(), server)
+	require.NoError(t, err)
+
+	saved, ok := store.get("server-1")
+	require.True(t, ok, "session is persisted on initialize")
+	assert.Equal(t, "session-1", saved.SessionID)
+	assert.Equal(t, "2025-06-18", saved.ProtocolVersion)
+
+	// Simulate a restart: a fresh client with the same store
+	restarted := NewStreamableHTTPClient(log, 5*time.Second)
+	restarted.SetSessionStore(store)
+	n, err := restarted.LoadSessions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	session := restarted.getSession("server-1")
+	require.NotNil(t, session)
+	assert.Equal(t, "session-1", session.SessionID)
+	assert.True(t, session.Initialized)
+
+	_, err = restarted.Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/list"}, backend.methods, "the restored session is resumed without re-initializing")
+	assert.Equal(t, "session-1", backend.sessionID[2])
+	assert.Equal(t, "2025-06-18", backend.versions[2])
+}
+
+func TestStreamableHTTPClient_PersistedSessionLifecycle(t *testing.T) {
+	log := logger.NewNopLogger()
+	store := newMemorySessionStore()
+	client := NewStreamableHTTPClient(log, 5*time.Second)
+	client.SetSessionStore(store)
+
+	t.Run("skips sessions with an unsupported protocol version", func(t *testing.T) {
+		_ = store.Save(context.Background(), &domain.PersistedSession{ServerID: "old", SessionID: "s", ProtocolVersion: "2023-01-01"})
+		n, err := client.LoadSessions(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Nil(t, client.getSession("old"))
+	})
+
+	t.Run("event IDs are persisted and cleared sessions deleted", func(t *testing.T) {
+		_ = store.Save(context.Background(), &domain.PersistedSession{ServerID: "server-1", SessionID: "s", ProtocolVersion: MCPProtocolVersion})
+		_, err := client.LoadSessions(context.Background())
+		require.NoError(t, err)
+
+		client.recordLastEventID("server-1", "evt-7")
+		saved, _ := store.get("server-1")
+		assert.Equal(t, "evt-7", saved.LastEventID)
+
+		client.clearSession("server-1")
+		_, ok := store.get("server-1")
+		assert.False(t, ok)
+	})
+}
@@ -1,13 +1,14 @@
 package gateway
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -18,9 +19,16 @@ import (
 
 // SSEClient handles communication with SSE-based MCP servers
 type SSEClient struct {
-	httpClient *http.Client
-	logger     logger.Logger
-	requestID  atomic.Int64
+	httpClient  *http.Client
+	pinned      pinnedClients // Clients for servers with TLSPins
+	timeout     time.Duration // Fallback per-call timeout
+	listTimeout time.Duration // Per-call timeout of list methods (0 = timeout)
+	logger      logger.Logger
+	requestID   atomic.Int64
+
+	maxResponseBytes int64        // Response size limit for servers without their own (0 = unlimited)
+	retry            RetryPolicy  // Retries of messages that fail to connect
+	secrets          *SecretStore // Resolves secret references in auth configs (nil = used as stored)
 }
 
 // JSONRPCRequest represents a JSON-RPC 2.0 request
@@ -31,6 +39,23 @@ type JSONRPCRequest struct {
 	ID      int64       `json:"id"`
 }
 
+// outgoingRequest is a JSON-RPC request generated by the gateway, with its id in the
+// type the server expects
+type outgoingRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      interface{} `json:"id"`
+}
+
+// RequestID returns a gateway-generated request id in the server's JSONRPCIDType
+func RequestID(server *domain.MCPServer, id int64) interface{} {
+	if server.JSONRPCIDType == domain.JSONRPCIDString {
+		return strconv.FormatInt(id, 10)
+	}
+	return id
+}
+
 // JSONRPCResponse represents a JSON-RPC 2.0 response
 type JSONRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -39,34 +64,40 @@ type JSONRPCResponse struct {
 	ID      interface{}     `json:"id"`
 }
 
-// JSONRPCError represents a JSON-RPC 2.0 error
-type JSONRPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
-}
-
 // NewSSEClient creates a new SSE MCP client
 func NewSSEClient(log logger.Logger, timeout time.Duration) *SSEClient {
 	return &SSEClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		logger: log,
+		httpClient: &http.Client{},
+		timeout:    timeout,
+		logger:     log,
+
+		maxResponseBytes: DefaultMaxResponseBytes,
 	}
 }
 
+// SetMaxResponseBytes sets the response size limit for servers without a MaxResponseBytes
+// of their own. Zero means unlimited. Must be called before the client is used.
+func (c *SSEClient) SetMaxResponseBytes(limit int64) {
+	c.maxResponseBytes = limit
+}
+
+// SetRetryPolicy sets how messages that fail to connect are retried. Must be called
+// before the client is used.
+func (c *SSEClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
 // Call sends a JSON-RPC request to an SSE-based MCP server and returns the response
 // For legacy SSE transport, messages are sent to /message endpoint (relative to SSE stream URL)
 func (c *SSEClient) Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
 	reqID := c.requestID.Add(1)
 
 	// Build JSON-RPC request
-	rpcReq := JSONRPCRequest{
+	rpcReq := outgoingRequest{
 		JSONRPC: "2.0",
 		Method:  method,
-		Params:  params,
-		ID:      reqID,
+		Params:  withPriorityMeta(ctx, params),
+		ID:      RequestID(server, reqID),
 	}
 
 	reqBody, err := json.Marshal(rpcReq)
@@ -85,29 +116,50 @@ func (c *SSEClient) Call(ctx context.Context, server *domain.MCPServer, method s
 		Int("request_id", int(reqID)).
 		Msg("Sending SSE MCP request")
 
-	// Create HTTP request to the message endpoint
-	req, err := http.NewRequestWithContext(ctx, "POST", messageURL, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	ctx, cancel := withCallTimeout(ctx, server, method, c.timeout, c.listTimeout)
+	defer cancel()
 
-	// SSE-based MCP servers require these headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	// Send the request to the message endpoint, retrying if it can't connect
+	var resp *http.Response
+	err = retryConnect(ctx, c.retry, func() error {
+		req, err := http.NewRequestWithContext(ctx, "POST", messageURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
 
-	// Add authentication if configured
-	c.injectAuth(req, server)
+		// SSE-based MCP servers require these headers
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set(HeaderAcceptEncoding, acceptEncoding)
+
+		// Add authentication if configured
+		SetGatewayHops(req)
+		SetRequestID(req)
+		SetPriorityHint(req)
+		SetTraceContext(req)
+		if err := c.injectAuth(req, server); err != nil {
+			return err
+		}
 
-	// Send request
-	resp, err := c.httpClient.Do(req)
+		resp, err = c.pinned.clientFor(c.httpClient, server).Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if err := decompressBody(resp); err != nil {
+		return nil, err
+	}
+	LimitResponseBody(resp, ResponseLimit(server, c.maxResponseBytes))
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse JSON response (SSE message endpoint returns JSON, not SSE stream)
@@ -128,7 +180,7 @@ func (c *SSEClient) parseJSONResponse(body io.Reader) (json.RawMessage, error) {
 
 	// Check for JSON-RPC error
 	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("MCP error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, rpcResp.Error
 	}
 
 	c.logger.Debug().
@@ -141,21 +193,21 @@ func (c *SSEClient) parseJSONResponse(body io.Reader) (json.RawMessage, error) {
 // parseSSEResponse parses the SSE response format (for streaming responses)
 // SSE format: "event: message\ndata: {...json...}\n\n"
 func (c *SSEClient) parseSSEResponse(body io.Reader) (json.RawMessage, error) {
-	scanner := bufio.NewScanner(body)
+	reader := NewSSEReader(body, 0)
 	var dataLine string
 
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Look for "data:" prefix
-		if strings.HasPrefix(line, "data:") {
-			dataLine = strings.TrimPrefix(line, "data:")
-			dataLine = strings.TrimSpace(dataLine)
+	for {
+		// Keep the last message; keep-alives in between are skipped
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if msgs := event.Messages(); len(msgs) > 0 {
+			dataLine = string(msgs[len(msgs)-1])
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if dataLine == "" {
@@ -170,7 +222,7 @@ func (c *SSEClient) parseSSEResponse(body io.Reader) (json.RawMessage, error) {
 
 	// Check for JSON-RPC error
 	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("MCP error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, rpcResp.Error
 	}
 
 	c.logger.Debug().
@@ -180,16 +232,21 @@ func (c *SSEClient) parseSSEResponse(body io.Reader) (json.RawMessage, error) {
 	return rpcResp.Result, nil
 }
 
-// injectAuth adds authentication headers based on server config
-func (c *SSEClient) injectAuth(req *http.Request, server *domain.MCPServer) {
+// injectAuth adds authentication headers based on server config, resolving secret
+// references in it first
+func (c *SSEClient) injectAuth(req *http.Request, server *domain.MCPServer) error {
+	server, err := resolveServerAuth(req.Context(), server, c.secrets)
+	if err != nil {
+		return err
+	}
 	if len(server.AuthConfig) == 0 {
-		return
+		return nil
 	}
 
 	var authConfig map[string]interface{}
 	if err := json.Unmarshal(server.AuthConfig, &authConfig); err != nil {
 		c.logger.Error().Err(err).Str("server_id", server.ID).Msg("Failed to parse auth config")
-		return
+		return nil
 	}
 
 	switch server.AuthType {
@@ -203,7 +260,10 @@ func (c *SSEClient) injectAuth(req *http.Request, server *domain.MCPServer) {
 		if username != "" && password != "" {
 			req.SetBasicAuth(username, password)
 		}
+	case domain.ServerAuthAPIKey:
+		applyAPIKeyAuth(req, authConfig)
 	}
+	return nil
 }
 
 // IsSSEServer determines if a server uses SSE transport
@@ -240,9 +300,15 @@ type PromptsGetParams struct {
 
 // InitializeParams represents parameters for initialize
 type InitializeParams struct {
-	ProtocolVersion string     `json:"protocolVersion"`
-	ClientInfo      ClientInfo `json:"clientInfo"`
-	Capabilities    struct{}   `json:"capabilities,omitempty"`
+	ProtocolVersion string             `json:"protocolVersion"`
+	ClientInfo      ClientInfo         `json:"clientInfo"`
+	Capabilities    ClientCapabilities `json:"capabilities,omitempty"`
+}
+
+// ClientCapabilities are the optional features the gateway offers a server as its client
+type ClientCapabilities struct {
+	// Elicitation is offered to servers whose policy allows it
+	Elicitation *struct{} `json:"elicitation,omitempty"`
 }
 
 // ClientInfo represents MCP client info
@@ -250,3 +316,17 @@ type ClientInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 }
+
+// InitializeResult represents the result of an initialize request
+type InitializeResult struct {
+	ProtocolVersion string          `json:"protocolVersion"`
+	Capabilities    json.RawMessage `json:"capabilities,omitempty"`
+	ServerInfo      ServerInfo      `json:"serverInfo"`
+	Instructions    string          `json:"instructions,omitempty"`
+}
+
+// ServerInfo represents MCP server info reported during initialize
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
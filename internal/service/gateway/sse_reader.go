@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrSSEEventTooLarge is returned by SSEReader for an event bigger than its limit
+var ErrSSEEventTooLarge = errors.New("SSE event too large")
+
+// SSEEvent is one event of a server-sent event stream
+type SSEEvent struct {
+	Event string // Event type (empty = "message")
+	Data  string // Data lines joined with "\n"
+	ID    string // Last event ID when the event was dispatched
+}
+
+// Messages returns the messages the event carries: its data when that is one JSON value,
+// possibly spread over several data lines, or otherwise each data line that is one, as
+// some servers send several messages as data lines of a single event. Data that isn't
+// JSON at all is returned as it is, for the caller to report.
+func (e SSEEvent) Messages() []json.RawMessage {
+	data := bytes.TrimSpace([]byte(e.Data))
+	if json.Valid(data) {
+		return []json.RawMessage{data}
+	}
+	var messages []json.RawMessage
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); json.Valid(line) {
+			messages = append(messages, line)
+		}
+	}
+	if len(messages) == 0 {
+		return []json.RawMessage{data}
+	}
+	return messages
+}
+
+// SSEReader reads events from a server-sent event stream. Lines of any length are read
+// whole, however the stream is split across reads, and an event's data lines are joined
+// as the SSE spec describes, so large and multi-line messages are reassembled intact.
+type SSEReader struct {
+	br            *bufio.Reader
+	maxEventBytes int // Largest event accepted (0 = unlimited)
+	lastEventID   string
+	line          []byte
+}
+
+// NewSSEReader creates a reader for the stream in body, failing events larger than
+// maxEventBytes with ErrSSEEventTooLarge (0 = unlimited)
+func NewSSEReader(body io.Reader, maxEventBytes int) *SSEReader {
+	return &SSEReader{br: bufio.NewReader(body), maxEventBytes: maxEventBytes}
+}
+
+// LastEventID returns the last event ID the stream set, or ""
+func (r *SSEReader) LastEventID() string {
+	return r.lastEventID
+}
+
+// Next returns the stream's next event, or io.EOF at its end. Events without data, such
+// as keep-alives, are skipped. An event still open when the stream ends is returned.
+func (r *SSEReader) Next() (SSEEvent, error) {
+	var event SSEEvent
+	var data []byte
+	hasData := false
+
+	for {
+		line, err := r.readLine(len(data))
+		if errors.Is(err, io.EOF) && hasData && len(bytes.TrimSpace(data)) > 0 {
+			event.Data = string(data)
+			event.ID = r.lastEventID
+			return event, nil
+		}
+		if err != nil {
+			return SSEEvent{}, err
+		}
+
+		if len(line) == 0 {
+			// A blank line dispatches the event
+			if hasData && len(bytes.TrimSpace(data)) > 0 {
+				event.Data = string(data)
+				event.ID = r.lastEventID
+				return event, nil
+			}
+			event, data, hasData = SSEEvent{}, data[:0], false
+			continue
+		}
+		if line[0] == ':' {
+			continue // Comment
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+		case "event":
+			event.Event = string(value)
+		case "id":
+			if !bytes.ContainsRune(value, 0) {
+				r.lastEventID = string(value)
+			}
+		}
+	}
+}
+
+// readLine returns the next line without its line ending. buffered is the size of the
+// event read so far, counted against the limit.
+func (r *SSEReader) readLine(buffered int) ([]byte, error) {
+	r.line = r.line[:0]
+	for {
+		chunk, err := r.br.ReadSlice('\n')
+		if r.maxEventBytes > 0 && buffered+len(r.line)+len(chunk) > r.maxEventBytes {
+			return nil, ErrSSEEventTooLarge
+		}
+		r.line = append(r.line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue // The line is longer than the buffer; keep reading
+		}
+		if err != nil && (!errors.Is(err, io.EOF) || len(r.line) == 0) {
+			return nil, err
+		}
+		line := bytes.TrimSuffix(r.line, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		return line, nil
+	}
+}
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// readAllEvents returns every event of stream
+func readAllEvents(t *testing.T, reader *SSEReader) []SSEEvent {
+	t.Helper()
+	var events []SSEEvent
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestSSEReader(t *testing.T) {
+	t.Run("reassembles a data line larger than the scanner buffer", func(t *testing.T) {
+		payload := `{"text":"` + strings.Repeat("x", 200*1024) + `"}`
+		stream := "event: message\ndata: " + payload + "\n\n"
+
+		// One byte per read, so the line is split across every read boundary
+		events := readAllEvents(t, NewSSEReader(iotest.OneByteReader(strings.NewReader(stream)), 0))
+
+		require.Len(t, events, 1)
+		assert.Equal(t, "message", events[0].Event)
+		assert.Equal(t, payload, events[0].Data)
+	})
+
+	t.Run("joins multi-line data", func(t *testing.T) {
+		stream := "data: {\"a\":\ndata: 1}\n\n"
+
+		events := readAllEvents(t, NewSSEReader(strings.NewReader(stream), 0))
+
+		require.Len(t, events, 1)
+		assert.Equal(t, "{\"a\":\n1}", events[0].Data)
+	})
+
+	t.Run("splits messages sent as data lines of one event", func(t *testing.T) {
+		event := SSEEvent{Data: "{\"id\":1}\n{\"id\":2}"}
+		assert.Equal(t, []json.RawMessage{json.RawMessage(`{"id":1}`), json.RawMessage(`{"id":2}`)}, event.Messages())
+
+		event = SSEEvent{Data: "{\"a\":\n1}"}
+		assert.Equal(t, []json.RawMessage{json.RawMessage("{\"a\":\n1}")}, event.Messages())
+	})
+
+	t.Run("handles CRLF, comments, keep-alives and ids", func(t *testing.T) {
+		stream := ": ping\r\n\r\ndata:\r\n\r\nid: 7\r\nevent: message\r\ndata: {\"id\":1}\r\n\r\nid: 8\r\n\r\n"
+
+		reader := NewSSEReader(strings.NewReader(stream), 0)
+		events := readAllEvents(t, reader)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, SSEEvent{Event: "message", Data: `{"id":1}`, ID: "7"}, events[0])
+		assert.Equal(t, "8", reader.LastEventID())
+	})
+
+	t.Run("returns an event the stream ends in", func(t *testing.T) {
+		events := readAllEvents(t, NewSSEReader(strings.NewReader("data: {\"last\":true}"), 0))
+
+		require.Len(t, events, 1)
+		assert.Equal(t, `{"last":true}`, events[0].Data)
+	})
+
+	t.Run("fails events over the limit", func(t *testing.T) {
+		stream := "data: " + strings.Repeat("x", 100) + "\n\n"
+
+		_, err := NewSSEReader(strings.NewReader(stream), 64).Next()
+		assert.ErrorIs(t, err, ErrSSEEventTooLarge)
+	})
+}
+
+func TestStreamableHTTPClient_LargeSSEResponse(t *testing.T) {
+	text := strings.Repeat("a", 256*1024)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		body := `event: message` + "\n" + `data: {"jsonrpc":"2.0","id":1,"result":{"text":"` + text + `"}}` + "\n\n"
+		// Flush in small pieces so the event spans many reads
+		for len(body) > 0 {
+			n := min(1000, len(body))
+			_, _ = w.Write([]byte(body[:n]))
+			flusher.Flush()
+			body = body[n:]
+		}
+	}))
+	defer backend.Close()
+
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+	server := &domain.MCPServer{ID: "server-1", URL: backend.URL, IsActive: true}
+
+	result, err := client.Call(context.Background(), server, "tools/call", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"`+text+`"}`, string(result))
+}
@@ -1,22 +1,32 @@
 package gateway
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
+// ErrSessionNotInitialized is returned for requests on a session whose initialized
+// notification could not be delivered
+var ErrSessionNotInitialized = errors.New("MCP session not initialized")
+
+// ErrSessionExpired is returned when a server no longer knows the session a request was
+// sent in, answering 404 as the Streamable HTTP spec describes
+var ErrSessionExpired = errors.New("session not found (404)")
+
 const (
 	// MCPProtocolVersion is the MCP protocol version supported
 	MCPProtocolVersion = "2025-11-25"
@@ -26,6 +36,7 @@ const (
 	HeaderMCPSessionID       = "MCP-Session-Id"
 	HeaderAccept             = "Accept"
 	HeaderContentType        = "Content-Type"
+	HeaderLastEventID        = "Last-Event-ID"
 
 	// Content types
 	ContentTypeJSON        = "application/json"
@@ -35,13 +46,31 @@ const (
 // StreamableHTTPClient handles communication with MCP servers using the Streamable HTTP transport
 // Per MCP spec 2025-11-25: https://modelcontextprotocol.io/specification/2025-11-25/basic/transports
 type StreamableHTTPClient struct {
-	httpClient *http.Client
-	logger     logger.Logger
-	requestID  atomic.Int64
+	httpClient  *http.Client
+	pinned      pinnedClients // Clients for servers with TLSPins
+	timeout     time.Duration // Fallback per-call timeout
+	listTimeout time.Duration // Per-call timeout of list methods (0 = timeout)
+	logger      logger.Logger
+	requestID   atomic.Int64
+
+	maxResponseBytes int64 // Response size limit for servers without their own (0 = unlimited)
 
 	// Session management per server
-	sessions   map[string]*MCPSession
-	sessionsMu sync.RWMutex
+	sessions    map[string]*MCPSession
+	sessionsMu  sync.RWMutex
+	initFlights map[string]*initFlight // Initializes in progress, guarded by sessionsMu
+	sessionTTL  time.Duration          // Idle time before a session is evicted (0 = never)
+	maxSessions int                    // Most sessions kept (0 = unlimited)
+	metrics     *metrics.Registry      // Reports active sessions and evictions (nil = none)
+	now         func() time.Time
+
+	initializes *initializeLimiter // Caps initialize attempts per server (nil = unlimited)
+	retry       RetryPolicy        // Retries of initializes that fail to connect
+
+	onNotification  NotificationFunc  // Called for notifications in SSE responses (nil = ignored)
+	onServerRequest ServerRequestFunc // Called for server requests in SSE responses (nil = ignored)
+	store           SessionStore      // Persists sessions across restarts (nil = in-memory only)
+	secrets         *SecretStore      // Resolves secret references in auth configs (nil = used as stored)
 }
 
 // MCPSession represents an MCP session with a server
@@ -51,24 +80,91 @@ type MCPSession struct {
 	ServerURL       string
 	Initialized     bool
 	ProtocolVersion string
+	ServerInfo      ServerInfo
+	Instructions    string          // Optional usage hints from the server for the client
+	Capabilities    json.RawMessage // Capabilities the server advertised in initialize
 	LastEventID     string
 	CreatedAt       time.Time
 	mu              sync.RWMutex
+
+	server   *domain.MCPServer // Server the session was initialized with, to terminate it on eviction
+	lastUsed time.Time
+}
+
+// setInitialized records whether the session's handshake completed
+func (s *MCPSession) setInitialized(initialized bool) {
+	s.mu.Lock()
+	s.Initialized = initialized
+	s.mu.Unlock()
+}
+
+// isInitialized reports whether the session's handshake completed
+func (s *MCPSession) isInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Initialized
 }
 
 // NewStreamableHTTPClient creates a new Streamable HTTP MCP client
 func NewStreamableHTTPClient(log logger.Logger, timeout time.Duration) *StreamableHTTPClient {
 	return &StreamableHTTPClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		logger:   log,
-		sessions: make(map[string]*MCPSession),
+		httpClient:  &http.Client{},
+		timeout:     timeout,
+		logger:      log,
+		sessions:    make(map[string]*MCPSession),
+		initFlights: make(map[string]*initFlight),
+		now:         time.Now,
+
+		maxResponseBytes: DefaultMaxResponseBytes,
 	}
 }
 
-// Initialize sends an initialize request to establish an MCP session
+// SetMaxResponseBytes sets the response size limit for servers without a MaxResponseBytes
+// of their own. Zero means unlimited. Must be called before the client is used.
+func (c *StreamableHTTPClient) SetMaxResponseBytes(limit int64) {
+	c.maxResponseBytes = limit
+}
+
+// SetMaxInitializesPerMinute caps how often each server may be initialized, including
+// re-initializes after an expired session. Initializes over the limit fail with
+// ErrInitializeThrottled. Zero means unlimited. Must be called before the client is used.
+func (c *StreamableHTTPClient) SetMaxInitializesPerMinute(limit int) {
+	c.initializes = newInitializeLimiter(limit)
+}
+
+// SetRetryPolicy sets how initialize requests that fail to connect are retried. Must be
+// called before the client is used.
+func (c *StreamableHTTPClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// Initialize sends an initialize request to establish an MCP session. The server's configured
+// ProtocolVersion is offered first; if the server rejects it as unsupported, initialize is
+// retried with an older version, preferring one the server listed. The version the server
+// agrees to is recorded on the session and sent with every later request. Concurrent
+// initializes of one server share a single handshake.
 func (c *StreamableHTTPClient) Initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error) {
+	c.sessionsMu.Lock()
+	flight, leader := c.initFlightLocked(server.ID)
+	c.sessionsMu.Unlock()
+	if !leader {
+		return flight.wait(ctx)
+	}
+	return c.runInitFlight(ctx, server, flight, func(ctx context.Context) (*MCPSession, error) {
+		return c.initialize(ctx, server)
+	})
+}
+
+// initialize runs the initialize handshake with the server and stores the new session
+func (c *StreamableHTTPClient) initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error) {
+	if err := c.initializes.allow(server.ID); err != nil {
+		c.logger.Warn().
+			Err(err).
+			Str("server_id", server.ID).
+			Msg("Not initializing MCP session, server initialized too often")
+		return nil, err
+	}
+
 	c.logger.Info().
 		Str("server_id", server.ID).
 		Str("url", server.URL).
@@ -76,72 +172,149 @@ func (c *StreamableHTTPClient) Initialize(ctx context.Context, server *domain.MC
 
 	// Build initialize request
 	params := InitializeParams{
-		ProtocolVersion: MCPProtocolVersion,
+		ProtocolVersion: requestedProtocolVersion(server),
 		ClientInfo: ClientInfo{
 			Name:    "waffles",
 			Version: "1.0.0",
 		},
 	}
+	if server.AllowsElicitation() {
+		params.Capabilities.Elicitation = &struct{}{}
+	}
 
-	result, sessionID, err := c.callWithSessionHandling(ctx, server, "", "initialize", params)
-	if err != nil {
-		return nil, fmt.Errorf("initialize failed: %w", err)
+	var tried []string
+	var result json.RawMessage
+	var sessionID string
+	for {
+		var err error
+		tried = append(tried, params.ProtocolVersion)
+		err = retryConnect(ctx, c.retry, func() error {
+			var err error
+			result, sessionID, err = c.callWithSessionHandling(ctx, server, "", "initialize", params)
+			return err
+		})
+		if err == nil {
+			break
+		}
+		offered, unsupported := unsupportedVersionOffer(err)
+		next := ""
+		if unsupported {
+			next = nextProtocolVersion(offered, tried)
+		}
+		if next == "" {
+			if unsupported {
+				return nil, fmt.Errorf("initialize failed: %w: tried %s: %w", ErrUnsupportedProtocolVersion, strings.Join(tried, ", "), err)
+			}
+			return nil, fmt.Errorf("initialize failed: %w", err)
+		}
+		c.logger.Info().
+			Str("server_id", server.ID).
+			Str("rejected_version", params.ProtocolVersion).
+			Str("next_version", next).
+			Msg("Server rejected MCP protocol version, negotiating down")
+		params.ProtocolVersion = next
 	}
 
-	// Create session
+	// Create session; it is initialized once the handshake completes
 	session := &MCPSession{
 		SessionID:       sessionID,
 		ServerID:        server.ID,
 		ServerURL:       server.URL,
-		Initialized:     true,
-		ProtocolVersion: MCPProtocolVersion,
-		CreatedAt:       time.Now(),
+		ProtocolVersion: params.ProtocolVersion,
+		CreatedAt:       c.now(),
+		server:          server,
 	}
 
-	// Store session
-	c.sessionsMu.Lock()
-	c.sessions[server.ID] = session
-	c.sessionsMu.Unlock()
+	// Capture server identity and the agreed version from the initialize result
+	if len(result) > 0 {
+		var initResult InitializeResult
+		if err := json.Unmarshal(result, &initResult); err != nil {
+			c.logger.Debug().Err(err).Str("server_id", server.ID).Msg("Failed to parse initialize result")
+		} else {
+			session.ServerInfo = initResult.ServerInfo
+			session.Instructions = initResult.Instructions
+			session.Capabilities = initResult.Capabilities
+			if initResult.ProtocolVersion != "" {
+				if !IsSupportedProtocolVersion(initResult.ProtocolVersion) {
+					return nil, fmt.Errorf("initialize failed: %w: server chose %s", ErrUnsupportedProtocolVersion, initResult.ProtocolVersion)
+				}
+				session.ProtocolVersion = initResult.ProtocolVersion
+			}
+		}
+	}
+
+	c.storeSession(session)
+	c.logSessionEvent(SessionEventCreate, server.ID, sessionID, "initialize accepted with protocol version "+session.ProtocolVersion)
 
 	c.logger.Info().
 		Str("server_id", server.ID).
 		Str("session_id", sessionID).
+		Str("protocol_version", session.ProtocolVersion).
 		Str("result", string(result)).
 		Msg("MCP session initialized")
 
-	// Send initialized notification
-	_, _, err = c.callWithSessionHandling(ctx, server, sessionID, "notifications/initialized", nil)
-	if err != nil {
-		c.logger.Warn().Err(err).Msg("Failed to send initialized notification")
-		// Don't fail - some servers may not require this
-	}
-
+	c.completeHandshake(ctx, server, session)
 	return session, nil
 }
 
+// completeHandshake sends the initialized notification that ends the handshake, retrying
+// transient failures. A session whose notification fails transiently every time is marked
+// not initialized, so the notification is sent again before the session's next request.
+// Other failures leave it initialized, as some servers reject the notification.
+func (c *StreamableHTTPClient) completeHandshake(ctx context.Context, server *domain.MCPServer, session *MCPSession) bool {
+	err := retryWhile(ctx, c.retry, c.retry.InitializedRetries, isTransientError, func() error {
+		_, _, err := c.callWithSessionHandling(ctx, server, session.SessionID, "notifications/initialized", nil)
+		return err
+	})
+	initialized := err == nil || !isTransientError(err)
+	session.setInitialized(initialized)
+
+	switch {
+	case err == nil:
+		c.logSessionEvent(SessionEventInitialize, server.ID, session.SessionID, "initialized notification sent")
+	case initialized:
+		c.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Failed to send initialized notification")
+		c.logSessionEvent(SessionEventInitialize, server.ID, session.SessionID, "initialized notification failed: "+err.Error())
+	default:
+		c.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Initialized notification kept failing, session not initialized")
+		c.logSessionEvent(SessionEventInitialize, server.ID, session.SessionID, "initialized notification failed, session not initialized: "+err.Error())
+	}
+	if initialized {
+		c.persistSession(session)
+	}
+	return initialized
+}
+
 // Call sends a JSON-RPC request to an MCP server and returns the response
 func (c *StreamableHTTPClient) Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
 	// Get or create session
-	session := c.getSession(server.ID)
+	session, err := c.requiredSession(ctx, server, method)
+	if err != nil {
+		return nil, err
+	}
 	sessionID := ""
 	if session != nil {
 		sessionID = session.SessionID
 	}
 
 	result, newSessionID, err := c.callWithSessionHandling(ctx, server, sessionID, method, params)
-	if err != nil {
-		// Check if session expired (404)
-		if strings.Contains(err.Error(), "404") {
-			c.logger.Info().Str("server_id", server.ID).Msg("Session expired, reinitializing")
-			c.clearSession(server.ID)
+	if errors.Is(err, ErrSessionExpired) {
+		// The server forgot the session: start a new session and retry once.
+		// A second 404 is returned, so a server that always answers 404 isn't looped on.
+		c.logger.Info().Str("server_id", server.ID).Msg("Session expired, reinitializing")
+		c.logSessionEvent(SessionEventExpire, server.ID, sessionID, err.Error())
+		if session != nil {
+			c.removeSession(session) // Unless a concurrent call already replaced it
+		}
+		c.logSessionEvent(SessionEventReinitialize, server.ID, sessionID, "session expired during "+method)
 
-			// Re-initialize and retry
-			_, err = c.Initialize(ctx, server)
-			if err != nil {
-				return nil, fmt.Errorf("failed to reinitialize session: %w", err)
-			}
-			return c.Call(ctx, server, method, params)
+		if session, err = c.ensureSession(ctx, server); err != nil {
+			return nil, fmt.Errorf("failed to reinitialize session: %w", err)
 		}
+		sessionID = session.SessionID
+		result, newSessionID, err = c.callWithSessionHandling(ctx, server, sessionID, method, params)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -150,11 +323,24 @@ func (c *StreamableHTTPClient) Call(ctx context.Context, server *domain.MCPServe
 		session.mu.Lock()
 		session.SessionID = newSessionID
 		session.mu.Unlock()
+		c.persistSession(session)
 	}
 
 	return result, nil
 }
 
+// OnNotification registers fn to be called for server notifications received
+// alongside responses. Must be called before the client is used.
+func (c *StreamableHTTPClient) OnNotification(fn NotificationFunc) {
+	c.onNotification = fn
+}
+
+// OnServerRequest registers fn to be called for requests a server sends alongside
+// responses, such as elicitation/create. Must be called before the client is used.
+func (c *StreamableHTTPClient) OnServerRequest(fn ServerRequestFunc) {
+	c.onServerRequest = fn
+}
+
 // callWithSessionHandling performs the actual HTTP request with session management
 func (c *StreamableHTTPClient) callWithSessionHandling(
 	ctx context.Context,
@@ -166,11 +352,11 @@ func (c *StreamableHTTPClient) callWithSessionHandling(
 	reqID := c.requestID.Add(1)
 
 	// Build JSON-RPC request
-	rpcReq := JSONRPCRequest{
+	rpcReq := outgoingRequest{
 		JSONRPC: "2.0",
 		Method:  method,
-		Params:  params,
-		ID:      reqID,
+		Params:  withPriorityMeta(ctx, params),
+		ID:      RequestID(server, reqID),
 	}
 
 	reqBody, err := json.Marshal(rpcReq)
@@ -185,32 +371,30 @@ func (c *StreamableHTTPClient) callWithSessionHandling(
 		Str("session_id", sessionID).
 		Msg("Sending Streamable HTTP MCP request")
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", server.URL, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set required headers per MCP spec 2025-11-25
-	req.Header.Set(HeaderContentType, ContentTypeJSON)
-	req.Header.Set(HeaderAccept, ContentTypeJSON+", "+ContentTypeEventStream)
-	req.Header.Set(HeaderMCPProtocolVersion, MCPProtocolVersion)
+	ctx, cancel := withCallTimeout(ctx, server, method, c.timeout, c.listTimeout)
+	defer cancel()
 
-	// Add session ID if we have one
-	if sessionID != "" {
-		req.Header.Set(HeaderMCPSessionID, sessionID)
+	version := c.protocolVersion(server)
+	if init, ok := params.(InitializeParams); ok {
+		version = init.ProtocolVersion
+	}
+	req, err := c.newPostRequest(ctx, server, sessionID, version, reqBody)
+	if err != nil {
+		return nil, "", err
 	}
-
-	// Add authentication if configured
-	c.injectAuth(req, server)
 
 	// Send request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.pinned.clientFor(c.httpClient, server).Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if err := decompressBody(resp); err != nil {
+		return nil, "", err
+	}
+	LimitResponseBody(resp, ResponseLimit(server, c.maxResponseBytes))
+
 	// Get session ID from response (may be set during initialize)
 	respSessionID := resp.Header.Get(HeaderMCPSessionID)
 
@@ -218,46 +402,309 @@ func (c *StreamableHTTPClient) callWithSessionHandling(
 	switch resp.StatusCode {
 	case http.StatusOK:
 		// Success - parse response based on content type
-		contentType := resp.Header.Get(HeaderContentType)
-		if strings.Contains(contentType, ContentTypeEventStream) {
-			return c.parseSSEStream(resp.Body)
+		body, isSSE, err := responseBody(resp, server.Quirks.Parsing)
+		if err != nil {
+			return nil, "", err
 		}
-		return c.parseJSONResponse(resp.Body)
+		if isSSE {
+			result, lastEventID, err := c.parseSSEStream(watchServerMessages(body, server.ID, c.onNotification, c.onServerRequest), server.Quirks.Parsing)
+			c.recordLastEventID(server.ID, lastEventID)
+			return result, respSessionID, err
+		}
+		result, _, err := c.parseJSONResponse(body, server.Quirks.Parsing)
+		return result, respSessionID, err
 
 	case http.StatusAccepted:
 		// 202 Accepted - for notifications/responses (no body expected)
 		return nil, respSessionID, nil
 
 	case http.StatusBadRequest:
-		// Session ID missing or invalid
+		// Session ID missing or invalid. Some servers answer an expired session this way.
 		body, _ := io.ReadAll(resp.Body)
+		if sessionID != "" && strings.Contains(strings.ToLower(string(body)), "session not found") {
+			return nil, "", fmt.Errorf("%w: %s", ErrSessionExpired, string(body))
+		}
 		return nil, "", fmt.Errorf("bad request (400): %s", string(body))
 
 	case http.StatusNotFound:
-		// Session expired
+		// Session expired, if there was one; otherwise the URL is wrong
 		body, _ := io.ReadAll(resp.Body)
-		return nil, "", fmt.Errorf("session not found (404): %s", string(body))
+		if sessionID == "" {
+			return nil, "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		return nil, "", fmt.Errorf("%w: %s", ErrSessionExpired, string(body))
 
 	default:
 		body, _ := io.ReadAll(resp.Body)
-		return nil, "", fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+		return nil, "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+}
+
+// newPostRequest builds a POST request with the headers required by MCP spec 2025-11-25
+func (c *StreamableHTTPClient) newPostRequest(ctx context.Context, server *domain.MCPServer, sessionID, protocolVersion string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", ServerURL(server), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set(HeaderContentType, ContentTypeJSON)
+	req.Header.Set(HeaderAccept, AcceptHeader(server))
+	req.Header.Set(HeaderAcceptEncoding, acceptEncoding)
+	req.Header.Set(HeaderMCPProtocolVersion, protocolVersion)
+
+	// Add session ID if we have one
+	if sessionID != "" {
+		setSessionHeader(req, server, sessionID)
+	}
+
+	// Add authentication if configured
+	SetGatewayHops(req)
+	SetRequestID(req)
+	SetPriorityHint(req)
+	SetTraceContext(req)
+	if err := c.injectAuth(req, server); err != nil {
+		return nil, err
 	}
+
+	return req, nil
 }
 
-// parseJSONResponse parses a single JSON-RPC response
-func (c *StreamableHTTPClient) parseJSONResponse(body io.Reader) (json.RawMessage, string, error) {
+// batchEntry is the wire form of a batch element; notifications omit the id
+type batchEntry struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      interface{} `json:"id,omitempty"`
+}
+
+// isNotification reports whether a JSON-RPC method is a notification (no response expected)
+func isNotification(method string) bool {
+	return strings.HasPrefix(method, "notifications/")
+}
+
+// CallBatch sends multiple JSON-RPC requests in a single POST and returns the
+// responses aligned to the input slice. Responses are correlated by ID, so the
+// server may reply in any order. Requests with a zero ID are assigned one.
+// Notifications (methods under "notifications/") are sent without an ID and
+// get a zero-value response in their slot. Requests the server did not answer
+// get a response carrying an internal error.
+func (c *StreamableHTTPClient) CallBatch(ctx context.Context, server *domain.MCPServer, requests []JSONRPCRequest) ([]JSONRPCResponse, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("batch must contain at least one request")
+	}
+
+	entries := make([]batchEntry, len(requests))
+	pending := make(map[string]int, len(requests))
+	for i, r := range requests {
+		entries[i] = batchEntry{JSONRPC: "2.0", Method: r.Method, Params: r.Params}
+		if isNotification(r.Method) {
+			continue
+		}
+		id := r.ID
+		if id == 0 {
+			id = c.requestID.Add(1)
+		}
+		key := strconv.FormatInt(id, 10)
+		if _, dup := pending[key]; dup {
+			return nil, fmt.Errorf("duplicate request id %d in batch", id)
+		}
+		entries[i].ID = RequestID(server, id)
+		pending[key] = i
+	}
+
+	reqBody, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	session, err := c.requiredSession(ctx, server, "batch")
+	if err != nil {
+		return nil, err
+	}
+	sessionID := ""
+	if session != nil {
+		sessionID = session.SessionID
+	}
+
+	c.logger.Debug().
+		Str("server_id", server.ID).
+		Int("batch_size", len(requests)).
+		Str("session_id", sessionID).
+		Msg("Sending Streamable HTTP MCP batch request")
+
+	ctx, cancel := withCallTimeout(ctx, server, "", c.timeout, 0)
+	defer cancel()
+
+	req, err := c.newPostRequest(ctx, server, sessionID, c.protocolVersion(server), reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.pinned.clientFor(c.httpClient, server).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("batch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := decompressBody(resp); err != nil {
+		return nil, err
+	}
+	LimitResponseBody(resp, ResponseLimit(server, c.maxResponseBytes))
+
+	responses := make([]JSONRPCResponse, len(requests))
+
+	var messages []json.RawMessage
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, isSSE, err := responseBody(resp, server.Quirks.Parsing)
+		if err != nil {
+			return nil, err
+		}
+		if isSSE {
+			messages, err = readSSEMessages(body)
+		} else {
+			var data []byte
+			data, err = io.ReadAll(body)
+			if err == nil {
+				messages, err = splitBatchMessages(data)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case http.StatusAccepted:
+		// 202 Accepted - no body, only valid when the batch had no requests
+		if len(pending) > 0 {
+			return nil, fmt.Errorf("server accepted batch without responses for %d request(s)", len(pending))
+		}
+		return responses, nil
+
+	case http.StatusNotFound:
+		body, _ := io.ReadAll(resp.Body)
+		if sessionID == "" {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		c.logSessionEvent(SessionEventExpire, server.ID, sessionID, "session not found (404) during batch")
+		c.clearSession(server.ID)
+		return nil, fmt.Errorf("%w: %s", ErrSessionExpired, string(body))
+
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	for _, msg := range messages {
+		rpcResp, key, err := decodeBatchResponse(msg)
+		if err != nil {
+			return nil, err
+		}
+		idx, ok := pending[key]
+		if !ok {
+			c.logger.Warn().
+				Str("server_id", server.ID).
+				Str("id", key).
+				Msg("Ignoring batch response with unknown id")
+			continue
+		}
+		responses[idx] = rpcResp
+		delete(pending, key)
+	}
+
+	for key, idx := range pending {
+		responses[idx] = JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      entries[idx].ID,
+			Error:   &JSONRPCError{Code: -32603, Message: "no response received for request " + key},
+		}
+	}
+
+	return responses, nil
+}
+
+// splitBatchMessages splits a JSON body into individual messages.
+// Servers may reply to a batch with an array or with a single object.
+func splitBatchMessages(data []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	if trimmed[0] != '[' {
+		return []json.RawMessage{trimmed}, nil
+	}
+
+	var messages []json.RawMessage
+	if err := json.Unmarshal(trimmed, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON-RPC batch response: %w", err)
+	}
+	return messages, nil
+}
+
+// readSSEMessages collects every JSON-RPC message carried by an SSE stream
+func readSSEMessages(body io.Reader) ([]json.RawMessage, error) {
+	reader := NewSSEReader(body, 0)
+	var messages []json.RawMessage
+
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSE stream: %w", err)
+		}
+		for _, msg := range event.Messages() {
+			msgs, err := splitBatchMessages(msg)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msgs...)
+		}
+	}
+}
+
+// decodeBatchResponse decodes a single response and returns its ID as a correlation key
+func decodeBatchResponse(msg json.RawMessage) (JSONRPCResponse, string, error) {
+	var rpcResp JSONRPCResponse
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	dec.UseNumber()
+	if err := dec.Decode(&rpcResp); err != nil {
+		return rpcResp, "", fmt.Errorf("failed to parse JSON-RPC response: %w", err)
+	}
+
+	switch id := rpcResp.ID.(type) {
+	case json.Number:
+		return rpcResp, id.String(), nil
+	case string:
+		return rpcResp, id, nil
+	default:
+		return rpcResp, fmt.Sprintf("%v", id), nil
+	}
+}
+
+// parseJSONResponse parses a single JSON-RPC response. Strict parsing rejects one that
+// isn't well-formed JSON-RPC 2.0, and lenient parsing takes an empty body as no result.
+func (c *StreamableHTTPClient) parseJSONResponse(body io.Reader, parsing domain.ParsingQuirk) (json.RawMessage, string, error) {
 	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read response body: %w", err)
 	}
+	if parsing == domain.ParsingLenient && len(bytes.TrimSpace(data)) == 0 {
+		return nil, "", nil
+	}
 
 	var rpcResp JSONRPCResponse
 	if err := json.Unmarshal(data, &rpcResp); err != nil {
 		return nil, "", fmt.Errorf("failed to parse JSON-RPC response: %w", err)
 	}
+	if parsing == domain.ParsingStrict {
+		if err := checkStrictResponse(rpcResp); err != nil {
+			return nil, "", err
+		}
+	}
 
 	if rpcResp.Error != nil {
-		return nil, "", fmt.Errorf("MCP error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, "", rpcResp.Error
 	}
 
 	c.logger.Debug().
@@ -267,32 +714,26 @@ func (c *StreamableHTTPClient) parseJSONResponse(body io.Reader) (json.RawMessag
 	return rpcResp.Result, "", nil
 }
 
-// parseSSEStream parses an SSE stream and extracts the JSON-RPC response
-func (c *StreamableHTTPClient) parseSSEStream(body io.Reader) (json.RawMessage, string, error) {
-	scanner := bufio.NewScanner(body)
+// parseSSEStream parses an SSE stream and extracts the JSON-RPC response, which strict
+// parsing requires to be well-formed JSON-RPC 2.0
+func (c *StreamableHTTPClient) parseSSEStream(body io.Reader, parsing domain.ParsingQuirk) (json.RawMessage, string, error) {
+	reader := NewSSEReader(body, 0)
 	var lastData string
-	var lastEventID string
-
-	for scanner.Scan() {
-		line := scanner.Text()
 
-		// Parse SSE fields
-		if strings.HasPrefix(line, "data:") {
-			data := strings.TrimPrefix(line, "data:")
-			data = strings.TrimSpace(data)
-			if data != "" {
-				lastData = data
-			}
-		} else if strings.HasPrefix(line, "id:") {
-			lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+	// The response is the last event; comments and keep-alives carry no message
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read SSE stream: %w", err)
+		}
+		if msgs := event.Messages(); len(msgs) > 0 {
+			lastData = string(msgs[len(msgs)-1])
 		}
-		// Empty line signals end of event
-		// We accumulate data and process the last complete event
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, "", fmt.Errorf("failed to read SSE stream: %w", err)
 	}
+	lastEventID := reader.LastEventID()
 
 	if lastData == "" {
 		return nil, lastEventID, fmt.Errorf("no data received in SSE stream")
@@ -303,9 +744,14 @@ func (c *StreamableHTTPClient) parseSSEStream(body io.Reader) (json.RawMessage,
 	if err := json.Unmarshal([]byte(lastData), &rpcResp); err != nil {
 		return nil, lastEventID, fmt.Errorf("failed to parse JSON-RPC response from SSE: %w", err)
 	}
+	if parsing == domain.ParsingStrict {
+		if err := checkStrictResponse(rpcResp); err != nil {
+			return nil, lastEventID, err
+		}
+	}
 
 	if rpcResp.Error != nil {
-		return nil, lastEventID, fmt.Errorf("MCP error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return nil, lastEventID, rpcResp.Error
 	}
 
 	c.logger.Debug().
@@ -316,16 +762,21 @@ func (c *StreamableHTTPClient) parseSSEStream(body io.Reader) (json.RawMessage,
 	return rpcResp.Result, lastEventID, nil
 }
 
-// injectAuth adds authentication headers based on server config
-func (c *StreamableHTTPClient) injectAuth(req *http.Request, server *domain.MCPServer) {
+// injectAuth adds authentication headers based on server config, resolving secret
+// references in it first
+func (c *StreamableHTTPClient) injectAuth(req *http.Request, server *domain.MCPServer) error {
+	server, err := resolveServerAuth(req.Context(), server, c.secrets)
+	if err != nil {
+		return err
+	}
 	if len(server.AuthConfig) == 0 {
-		return
+		return nil
 	}
 
 	var authConfig map[string]interface{}
 	if err := json.Unmarshal(server.AuthConfig, &authConfig); err != nil {
 		c.logger.Error().Err(err).Str("server_id", server.ID).Msg("Failed to parse auth config")
-		return
+		return nil
 	}
 
 	switch server.AuthType {
@@ -339,21 +790,64 @@ func (c *StreamableHTTPClient) injectAuth(req *http.Request, server *domain.MCPS
 		if username != "" && password != "" {
 			req.SetBasicAuth(username, password)
 		}
+	case domain.ServerAuthAPIKey:
+		applyAPIKeyAuth(req, authConfig)
+	}
+	return nil
+}
+
+// requiredSession returns the server's session. A server with RequireInitialize that
+// has none is initialized first, so method isn't sent without a session; other servers
+// get nil and are called without one. A session whose handshake is still running, or
+// didn't complete, is waited on or has its handshake retried first.
+func (c *StreamableHTTPClient) requiredSession(ctx context.Context, server *domain.MCPServer, method string) (*MCPSession, error) {
+	session := c.getSession(server.ID)
+	if method == "initialize" || (session != nil && session.isInitialized()) {
+		return session, nil
+	}
+	if session == nil && !server.RequireInitialize {
+		return nil, nil
+	}
+
+	if session == nil {
+		c.logger.Debug().
+			Str("server_id", server.ID).
+			Str("method", method).
+			Msg("Initializing MCP session before first request")
+	}
+	session, err := c.ensureSession(ctx, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize session before %s: %w", method, err)
+	}
+	if !session.isInitialized() {
+		return nil, fmt.Errorf("%w: initialized notification failed before %s", ErrSessionNotInitialized, method)
 	}
+	return session, nil
 }
 
-// getSession returns the session for a server if it exists
-func (c *StreamableHTTPClient) getSession(serverID string) *MCPSession {
-	c.sessionsMu.RLock()
-	defer c.sessionsMu.RUnlock()
-	return c.sessions[serverID]
+// protocolVersion returns the MCP protocol version agreed for the server's session, or
+// the version that would be requested when there is no session yet
+func (c *StreamableHTTPClient) protocolVersion(server *domain.MCPServer) string {
+	if session := c.getSession(server.ID); session != nil && session.ProtocolVersion != "" {
+		return session.ProtocolVersion
+	}
+	return requestedProtocolVersion(server)
 }
 
-// clearSession removes a session for a server
-func (c *StreamableHTTPClient) clearSession(serverID string) {
-	c.sessionsMu.Lock()
-	defer c.sessionsMu.Unlock()
-	delete(c.sessions, serverID)
+// recordLastEventID remembers the last SSE event ID a server sent on its session, so the
+// event stream can resume from it after a reconnect
+func (c *StreamableHTTPClient) recordLastEventID(serverID, eventID string) {
+	session := c.getSession(serverID)
+	if eventID == "" || session == nil {
+		return
+	}
+	session.mu.Lock()
+	changed := session.LastEventID != eventID
+	session.LastEventID = eventID
+	session.mu.Unlock()
+	if changed {
+		c.persistSession(session)
+	}
 }
 
 // IsStreamableHTTPServer determines if a server uses Streamable HTTP transport
@@ -363,28 +857,146 @@ func IsStreamableHTTPServer(server *domain.MCPServer) bool {
 	return strings.HasSuffix(server.URL, "/mcp")
 }
 
+// OpenEventStream opens the session's GET event stream, over which the server sends
+// requests and notifications not tied to a client request. A session is initialized
+// first if there is none. The caller must close the returned body.
+func (c *StreamableHTTPClient) OpenEventStream(ctx context.Context, server *domain.MCPServer) (io.ReadCloser, error) {
+	session := c.getSession(server.ID)
+	if session == nil {
+		var err error
+		if session, err = c.Initialize(ctx, server); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ServerURL(server), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event stream request: %w", err)
+	}
+	req.Header.Set(HeaderAccept, ContentTypeEventStream)
+	req.Header.Set(HeaderMCPProtocolVersion, c.protocolVersion(server))
+	session.mu.RLock()
+	if session.SessionID != "" {
+		setSessionHeader(req, server, session.SessionID)
+	}
+	if session.LastEventID != "" {
+		req.Header.Set(HeaderLastEventID, session.LastEventID)
+	}
+	session.mu.RUnlock()
+	SetGatewayHops(req)
+	SetRequestID(req)
+	SetPriorityHint(req)
+	SetTraceContext(req)
+	if err := c.injectAuth(req, server); err != nil {
+		return nil, err
+	}
+
+	// No call timeout: the stream stays open until ctx is cancelled or the server closes it
+	resp, err := c.pinned.clientFor(c.httpClient, server).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("event stream request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && strings.Contains(resp.Header.Get(HeaderContentType), ContentTypeEventStream):
+		return resp.Body, nil
+	case resp.StatusCode == http.StatusMethodNotAllowed:
+		resp.Body.Close()
+		return nil, ErrEventStreamUnsupported
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		c.logSessionEvent(SessionEventExpire, server.ID, session.SessionID, "session not found (404) opening event stream")
+		c.clearSession(server.ID)
+		return nil, fmt.Errorf("session not found (404) opening event stream")
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("event stream returned %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// outgoingResponse is a JSON-RPC response answering a request a server sent the gateway
+type outgoingResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// Respond answers a request the server sent in its current session, such as
+// elicitation/create. Exactly one of result and rpcErr should be set.
+func (c *StreamableHTTPClient) Respond(ctx context.Context, server *domain.MCPServer, id, result json.RawMessage, rpcErr *JSONRPCError) error {
+	sessionID := ""
+	if session := c.getSession(server.ID); session != nil {
+		sessionID = session.SessionID
+	}
+
+	body, err := json.Marshal(outgoingResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	ctx, cancel := withCallTimeout(ctx, server, "", c.timeout, 0)
+	defer cancel()
+
+	req, err := c.newPostRequest(ctx, server, sessionID, c.protocolVersion(server), body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.pinned.clientFor(c.httpClient, server).Do(req)
+	if err != nil {
+		return fmt.Errorf("response failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server rejected response with %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
 // TerminateSession sends a DELETE request to terminate an MCP session
 func (c *StreamableHTTPClient) TerminateSession(ctx context.Context, server *domain.MCPServer) error {
 	session := c.getSession(server.ID)
 	if session == nil || session.SessionID == "" {
 		return nil // No session to terminate
 	}
+	return c.terminate(ctx, server, session)
+}
+
+// terminate sends the DELETE ending session and drops it, unless it has been replaced
+func (c *StreamableHTTPClient) terminate(ctx context.Context, server *domain.MCPServer, session *MCPSession) error {
+	ctx, cancel := withCallTimeout(ctx, server, "", c.timeout, 0)
+	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "DELETE", server.URL, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", ServerURL(server), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create terminate request: %w", err)
 	}
 
-	req.Header.Set(HeaderMCPSessionID, session.SessionID)
-	req.Header.Set(HeaderMCPProtocolVersion, MCPProtocolVersion)
+	version := session.ProtocolVersion
+	if version == "" {
+		version = requestedProtocolVersion(server)
+	}
+	setSessionHeader(req, server, session.SessionID)
+	req.Header.Set(HeaderMCPProtocolVersion, version)
+	SetGatewayHops(req)
+	SetRequestID(req)
+	SetPriorityHint(req)
+	SetTraceContext(req)
+	if err := c.injectAuth(req, server); err != nil {
+		return fmt.Errorf("failed to create terminate request: %w", err)
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.pinned.clientFor(c.httpClient, server).Do(req)
 	if err != nil {
 		return fmt.Errorf("terminate request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	c.clearSession(server.ID)
+	c.removeSession(session)
+	c.logSessionEvent(SessionEventTerminate, server.ID, session.SessionID, fmt.Sprintf("DELETE returned %d", resp.StatusCode))
 
 	// 405 Method Not Allowed is acceptable - server doesn't support client termination
 	if resp.StatusCode == http.StatusMethodNotAllowed {
@@ -0,0 +1,378 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// MethodResourceUpdated is the notification a server sends when a subscribed resource changes
+const MethodResourceUpdated = "notifications/resources/updated"
+
+// subscriptionBuffer is how many undelivered updates a subscriber may fall behind by
+// before further updates to it are dropped
+const subscriptionBuffer = 16
+
+// Delays between attempts to reopen a server's event stream, before jitter
+const (
+	eventStreamMinBackoff = time.Second
+	eventStreamMaxBackoff = 30 * time.Second
+)
+
+// ErrEventStreamUnsupported is returned when a server doesn't offer a GET event stream
+var ErrEventStreamUnsupported = errors.New("server does not offer an event stream")
+
+// ErrTooManySubscribers is returned when a server already has as many resource update and
+// elicitation subscribers as allowed
+var ErrTooManySubscribers = errors.New("too many event subscribers for server")
+
+// ResourceSubscription receives the notifications/resources/updated messages a server
+// sends for one resource URI
+type ResourceSubscription struct {
+	ServerID string
+	URI      string
+
+	updates chan json.RawMessage
+	owner   *resourceSubscriptions
+	closed  bool // Guarded by owner.mu
+}
+
+// Updates returns the channel update notifications are delivered on. It is closed when
+// the subscription is closed or the server's session is terminated.
+func (s *ResourceSubscription) Updates() <-chan json.RawMessage {
+	return s.updates
+}
+
+// Close ends the subscription. The server is unsubscribed from the URI once its last
+// subscriber is gone.
+func (s *ResourceSubscription) Close() {
+	s.owner.remove(s)
+}
+
+// serverSubscriptions tracks the subscribers to one server and the event stream feeding them
+type serverSubscriptions struct {
+	byURI        map[string]map[*ResourceSubscription]struct{}
+	elicitations map[*ElicitationSubscription]struct{}
+	cancel       context.CancelFunc // Stops the event stream
+}
+
+// empty reports whether the server has no subscribers left
+func (s *serverSubscriptions) empty() bool {
+	return len(s.byURI) == 0 && len(s.elicitations) == 0
+}
+
+// count returns the number of the server's subscribers
+func (s *serverSubscriptions) count() int {
+	n := len(s.elicitations)
+	for _, subscribers := range s.byURI {
+		n += len(subscribers)
+	}
+	return n
+}
+
+// resourceSubscriptions fans resource update notifications and elicitation requests out to
+// subscribers. Each server with at least one subscriber has a single event stream open,
+// shared by all its subscribers.
+type resourceSubscriptions struct {
+	service      *Service
+	maxPerServer int // Most subscribers per server (0 = unlimited)
+
+	mu      sync.Mutex
+	servers map[string]*serverSubscriptions
+}
+
+func newResourceSubscriptions(s *Service) *resourceSubscriptions {
+	return &resourceSubscriptions{
+		service: s,
+		servers: make(map[string]*serverSubscriptions),
+	}
+}
+
+// SubscribeResource subscribes to updates of a resource on a Streamable HTTP server. The
+// server is sent resources/subscribe for the URI's first subscriber, and its event stream
+// is opened so notifications/resources/updated can be forwarded to the subscription.
+func (s *Service) SubscribeResource(ctx context.Context, serverID, uri string) (*ResourceSubscription, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("resource uri is required")
+	}
+	transport, _, err := s.GetTransportType(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if transport != domain.TransportStreamableHTTP {
+		return nil, fmt.Errorf("resource subscriptions require the %s transport", domain.TransportStreamableHTTP)
+	}
+
+	subs := s.subscriptions
+	subs.mu.Lock()
+	first := subs.subscriberCount(serverID, uri) == 0
+	full := subs.fullLocked(serverID)
+	subs.mu.Unlock()
+	if full {
+		return nil, ErrTooManySubscribers
+	}
+
+	if first {
+		if _, err := s.CallStreamableHTTP(ctx, serverID, "resources/subscribe", map[string]string{"uri": uri}); err != nil {
+			return nil, fmt.Errorf("resources/subscribe failed: %w", err)
+		}
+	}
+	sub, err := subs.add(serverID, uri)
+	if err != nil {
+		// Filled up while subscribing
+		if first {
+			go s.unsubscribeResource(serverID, uri)
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// closeServer closes every subscription to a server and stops its event stream
+func (r *resourceSubscriptions) closeServer(serverID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	server, ok := r.servers[serverID]
+	if !ok {
+		return
+	}
+	delete(r.servers, serverID)
+	r.recordSubscribers(serverID, 0)
+	server.cancel()
+	for _, subscribers := range server.byURI {
+		for sub := range subscribers {
+			sub.closed = true
+			close(sub.updates)
+		}
+	}
+	for sub := range server.elicitations {
+		sub.closed = true
+		close(sub.requests)
+	}
+}
+
+// subscriberCount returns the number of subscribers to a URI. Must be called with mu held.
+func (r *resourceSubscriptions) subscriberCount(serverID, uri string) int {
+	server, ok := r.servers[serverID]
+	if !ok {
+		return 0
+	}
+	return len(server.byURI[uri])
+}
+
+// fullLocked reports whether the server has as many subscribers as allowed. Must be called
+// with mu held.
+func (r *resourceSubscriptions) fullLocked(serverID string) bool {
+	server, ok := r.servers[serverID]
+	return ok && r.maxPerServer > 0 && server.count() >= r.maxPerServer
+}
+
+// recordSubscribers updates the server's subscriber gauge. Must be called with mu held.
+func (r *resourceSubscriptions) recordSubscribers(serverID string, n int) {
+	if r.service.metrics != nil {
+		r.service.metrics.GatewayEventSubscribers.WithLabelValues(serverID).Set(float64(n))
+	}
+}
+
+// add registers a subscriber, opening the server's event stream if it isn't already open.
+// Returns ErrTooManySubscribers when the server is at its limit.
+func (r *resourceSubscriptions) add(serverID, uri string) (*ResourceSubscription, error) {
+	sub := &ResourceSubscription{
+		ServerID: serverID,
+		URI:      uri,
+		updates:  make(chan json.RawMessage, subscriptionBuffer),
+		owner:    r,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fullLocked(serverID) {
+		return nil, ErrTooManySubscribers
+	}
+	server := r.serverLocked(serverID)
+	if server.byURI[uri] == nil {
+		server.byURI[uri] = make(map[*ResourceSubscription]struct{})
+	}
+	server.byURI[uri][sub] = struct{}{}
+	r.recordSubscribers(serverID, server.count())
+	return sub, nil
+}
+
+// serverLocked returns the server's subscribers, opening its event stream for the first
+// one. Must be called with mu held.
+func (r *resourceSubscriptions) serverLocked(serverID string) *serverSubscriptions {
+	server, ok := r.servers[serverID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		server = &serverSubscriptions{
+			byURI:        make(map[string]map[*ResourceSubscription]struct{}),
+			elicitations: make(map[*ElicitationSubscription]struct{}),
+			cancel:       cancel,
+		}
+		r.servers[serverID] = server
+		go r.service.runEventStream(ctx, serverID)
+	}
+	return server
+}
+
+// remove drops a subscriber, unsubscribing the server from the URI when it was the last one
+// and stopping the event stream when the server has no subscribers left
+func (r *resourceSubscriptions) remove(sub *ResourceSubscription) {
+	r.mu.Lock()
+	if sub.closed {
+		r.mu.Unlock()
+		return
+	}
+	sub.closed = true
+	close(sub.updates)
+
+	server := r.servers[sub.ServerID]
+	delete(server.byURI[sub.URI], sub)
+	lastForURI := len(server.byURI[sub.URI]) == 0
+	if lastForURI {
+		delete(server.byURI, sub.URI)
+	}
+	r.recordSubscribers(sub.ServerID, server.count())
+	if server.empty() {
+		delete(r.servers, sub.ServerID)
+		server.cancel()
+	}
+	r.mu.Unlock()
+
+	if lastForURI {
+		go r.service.unsubscribeResource(sub.ServerID, sub.URI)
+	}
+}
+
+// dispatch delivers a resources/updated notification to the subscribers of its URI.
+// Subscribers that have fallen behind miss the update rather than stalling the stream.
+func (r *resourceSubscriptions) dispatch(serverID, uri string, msg json.RawMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	server, ok := r.servers[serverID]
+	if !ok {
+		return
+	}
+	for sub := range server.byURI[uri] {
+		select {
+		case sub.updates <- msg:
+		default:
+			r.service.logger.Debug().Str("server_id", serverID).Str("uri", uri).Msg("Dropped resource update for slow subscriber")
+		}
+	}
+}
+
+// uris returns the URIs a server has subscribers for
+func (r *resourceSubscriptions) uris(serverID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	server, ok := r.servers[serverID]
+	if !ok {
+		return nil
+	}
+	uris := make([]string, 0, len(server.byURI))
+	for uri := range server.byURI {
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// unsubscribeResource sends resources/unsubscribe for a URI nobody is subscribed to anymore
+func (s *Service) unsubscribeResource(serverID, uri string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := s.CallStreamableHTTP(ctx, serverID, "resources/unsubscribe", map[string]string{"uri": uri}); err != nil {
+		s.logger.Debug().Err(err).Str("server_id", serverID).Str("uri", uri).Msg("Failed to unsubscribe from resource")
+	}
+}
+
+// runEventStream reads the server's event stream until ctx is cancelled, reopening it with
+// jittered backoff when it ends. After a reconnect the server is resubscribed to every URI, since a
+// new session won't carry the old subscriptions.
+func (s *Service) runEventStream(ctx context.Context, serverID string) {
+	backoff := NewBackoff(s.reconnectJitter, eventStreamMinBackoff, eventStreamMaxBackoff)
+	delay := backoff.Next()
+	for attempt := 0; ; attempt++ {
+		server, err := s.repo.Get(ctx, serverID)
+		if err == nil {
+			if attempt > 0 {
+				for _, uri := range s.subscriptions.uris(serverID) {
+					_, _ = s.CallStreamableHTTP(ctx, serverID, "resources/subscribe", map[string]string{"uri": uri}) // #nosec G104 -- retried on the next reconnect
+				}
+			}
+			var body io.ReadCloser
+			body, err = s.streamableHTTPClient.OpenEventStream(ctx, server)
+			if err == nil {
+				backoff.Reset()
+				delay = backoff.Next()
+				s.readEventStream(serverID, body)
+				body.Close()
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, ErrEventStreamUnsupported) {
+			s.logger.Warn().Str("server_id", serverID).Msg("Server has no event stream, resource updates and elicitations won't be forwarded")
+			return
+		}
+		if err != nil {
+			s.logger.Warn().Err(err).Str("server_id", serverID).Dur("retry_in", delay).Msg("Failed to open event stream")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = backoff.Next()
+	}
+}
+
+// readEventStream forwards resource updates from an SSE stream to subscribers, passes
+// other notifications to HandleNotification and server requests to HandleServerRequest
+func (s *Service) readEventStream(serverID string, body io.Reader) {
+	reader := NewSSEReader(body, maxNotificationLine)
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			return
+		}
+		for _, msg := range event.Messages() {
+			s.handleEventStreamMessage(serverID, msg)
+		}
+	}
+}
+
+// handleEventStreamMessage passes a message from a server's event stream to its handler
+func (s *Service) handleEventStreamMessage(serverID string, msg json.RawMessage) {
+	var notification struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(msg, &notification); err != nil || notification.Method == "" {
+		return
+	}
+	switch {
+	case notification.ID != nil:
+		s.HandleServerRequest(serverID, notification.Method, notification.ID, msg)
+	case notification.Method == MethodResourceUpdated:
+		s.subscriptions.dispatch(serverID, notification.Params.URI, msg)
+	default:
+		s.HandleNotification(serverID, notification.Method, msg)
+	}
+}
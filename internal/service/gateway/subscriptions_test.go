@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// subscriptionBackend is a Streamable HTTP server that records subscribe calls and pushes
+// whatever is sent on events down its GET event stream
+type subscriptionBackend struct {
+	events chan string
+
+	mu      sync.Mutex
+	methods []string
+}
+
+func (b *subscriptionBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-b.events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+				w.(http.Flusher).Flush()
+			}
+		}
+	}
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req JSONRPCRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	b.mu.Lock()
+	b.methods = append(b.methods, req.Method)
+	b.mu.Unlock()
+
+	w.Header().Set(HeaderMCPSessionID, "session-1")
+	if isNotification(req.Method) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, req.ID)
+}
+
+func (b *subscriptionBackend) calls(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, m := range b.methods {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
+
+func newSubscriptionTestService(t *testing.T) (*Service, *subscriptionBackend) {
+	t.Helper()
+	backend := &subscriptionBackend{events: make(chan string, 4)}
+	ts := httptest.NewServer(backend)
+	t.Cleanup(ts.Close)
+
+	repo := multiServerRepository{
+		"server-1": {ID: "server-1", URL: ts.URL + "/mcp", Transport: domain.TransportStreamableHTTP, IsActive: true},
+	}
+	log := logger.NewNopLogger()
+	return NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second)), backend
+}
+
+func receiveUpdate(t *testing.T, sub *ResourceSubscription) json.RawMessage {
+	t.Helper()
+	select {
+	case msg := <-sub.Updates():
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resource update")
+		return nil
+	}
+}
+
+func TestSubscribeResource_ForwardsUpdates(t *testing.T) {
+	svc, backend := newSubscriptionTestService(t)
+	ctx := context.Background()
+
+	first, err := svc.SubscribeResource(ctx, "server-1", "file:///a.txt")
+	require.NoError(t, err)
+	second, err := svc.SubscribeResource(ctx, "server-1", "file:///a.txt")
+	require.NoError(t, err)
+	other, err := svc.SubscribeResource(ctx, "server-1", "file:///b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.calls("resources/subscribe"), "one upstream subscribe per URI")
+
+	backend.events <- `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a.txt"}}`
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a.txt"}}`, string(receiveUpdate(t, first)))
+	receiveUpdate(t, second)
+	select {
+	case msg := <-other.Updates():
+		t.Fatalf("update for another URI delivered: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The URI is unsubscribed upstream only once its last subscriber leaves
+	first.Close()
+	first.Close()
+	second.Close()
+	assert.Eventually(t, func() bool { return backend.calls("resources/unsubscribe") == 1 }, 2*time.Second, 10*time.Millisecond)
+	_, open := <-first.Updates()
+	assert.False(t, open)
+	other.Close()
+}
+
+func TestSubscribeResource_ClosedOnSessionTerminate(t *testing.T) {
+	svc, _ := newSubscriptionTestService(t)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeResource(ctx, "server-1", "file:///a.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.TerminateStreamableHTTP(ctx, "server-1"))
+
+	select {
+	case _, open := <-sub.Updates():
+		assert.False(t, open, "updates channel is closed")
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+	sub.Close() // Closing after termination is a no-op
+
+	svc.subscriptions.mu.Lock()
+	defer svc.subscriptions.mu.Unlock()
+	assert.Empty(t, svc.subscriptions.servers)
+}
+
+func TestSubscribeResource_MaxSubscribers(t *testing.T) {
+	svc, backend := newSubscriptionTestService(t)
+	svc.metrics = metrics.NewRegistry()
+	svc.subscriptions.maxPerServer = 2
+	svc.repo.(multiServerRepository)["server-1"].ElicitationPolicy = domain.ElicitationAllow
+	ctx := context.Background()
+	subscribers := func() float64 {
+		return testutil.ToFloat64(svc.metrics.GatewayEventSubscribers.WithLabelValues("server-1"))
+	}
+
+	first, err := svc.SubscribeResource(ctx, "server-1", "file:///a.txt")
+	require.NoError(t, err)
+	elicitations, err := svc.SubscribeElicitations(ctx, "server-1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), subscribers())
+
+	_, err = svc.SubscribeResource(ctx, "server-1", "file:///b.txt")
+	assert.ErrorIs(t, err, ErrTooManySubscribers)
+	_, err = svc.SubscribeElicitations(ctx, "server-1")
+	assert.ErrorIs(t, err, ErrTooManySubscribers)
+	assert.Equal(t, 1, backend.calls("resources/subscribe"), "a refused subscriber isn't subscribed upstream")
+	assert.Equal(t, float64(2), subscribers())
+
+	// A slot frees up when a subscriber leaves
+	elicitations.Close()
+	assert.Equal(t, float64(1), subscribers())
+	second, err := svc.SubscribeResource(ctx, "server-1", "file:///a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), subscribers())
+
+	first.Close()
+	second.Close()
+	assert.Equal(t, float64(0), subscribers())
+}
+
+func TestSubscribeResource_Validation(t *testing.T) {
+	svc, _ := newSubscriptionTestService(t)
+	svc.repo.(multiServerRepository)["server-sse"] = &domain.MCPServer{ID: "server-sse", URL: "http://localhost/sse", Transport: domain.TransportSSE, IsActive: true}
+
+	_, err := svc.SubscribeResource(context.Background(), "server-1", "")
+	assert.Error(t, err)
+
+	_, err = svc.SubscribeResource(context.Background(), "server-sse", "file:///a.txt")
+	assert.ErrorContains(t, err, "streamable_http")
+}
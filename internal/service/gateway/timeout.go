@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// MetaTimeoutKey is the params._meta field a client uses to request a timeout, in milliseconds
+const MetaTimeoutKey = "timeoutMs"
+
+// minTimeoutHint is the shortest timeout a client hint can set
+const minTimeoutHint = time.Second
+
+type timeoutHintKey struct{}
+
+// WithTimeoutHint returns a context carrying a client-requested timeout for upstream calls.
+// The caller is responsible for bounding the hint by role; server limits apply when it is used.
+func WithTimeoutHint(ctx context.Context, hint time.Duration) context.Context {
+	if hint <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, timeoutHintKey{}, hint)
+}
+
+// TimeoutHintFromContext returns the client timeout hint stored by WithTimeoutHint, or 0
+func TimeoutHintFromContext(ctx context.Context) time.Duration {
+	hint, _ := ctx.Value(timeoutHintKey{}).(time.Duration)
+	return hint
+}
+
+// TimeoutHintFromMeta reads the timeout hint from a JSON-RPC params object.
+// Returns 0 when params has no valid positive _meta.timeoutMs.
+func TimeoutHintFromMeta(params json.RawMessage) time.Duration {
+	var p struct {
+		Meta map[string]json.RawMessage `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &p) != nil {
+		return 0
+	}
+	var ms float64
+	if err := json.Unmarshal(p.Meta[MetaTimeoutKey], &ms); err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// ClampTimeoutHint bounds a client timeout hint by the server's timeout and the minimum hint
+func ClampTimeoutHint(server *domain.MCPServer, hint time.Duration) time.Duration {
+	if limit := time.Duration(server.TimeoutSeconds) * time.Second; limit > 0 && hint > limit {
+		hint = limit
+	}
+	if hint < minTimeoutHint {
+		hint = minTimeoutHint
+	}
+	return hint
+}
+
+// callTimeout returns the timeout for a single upstream call.
+// Order of precedence: server.TimeoutSeconds, the client's list timeout for list
+// methods when one is set, then the client-wide fallback.
+func callTimeout(server *domain.MCPServer, method string, fallback, listTimeout time.Duration) time.Duration {
+	if server.TimeoutSeconds > 0 {
+		return time.Duration(server.TimeoutSeconds) * time.Second
+	}
+	if listTimeout > 0 && strings.HasSuffix(method, "/list") {
+		return listTimeout
+	}
+	return fallback
+}
+
+// withCallTimeout derives a request context bounded by the call timeout, or by the
+// client's timeout hint in ctx when there is one.
+// Using the context rather than http.Client.Timeout lets cancellation
+// propagate to response body reads and to the caller.
+func withCallTimeout(ctx context.Context, server *domain.MCPServer, method string, fallback, listTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := callTimeout(server, method, fallback, listTimeout)
+	if hint := TimeoutHintFromContext(ctx); hint > 0 {
+		timeout = ClampTimeoutHint(server, hint)
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// SetListTimeout sets the per-call timeout of list methods (tools/list, resources/list,
+// ...) on servers without a timeout of their own. Zero means the client timeout. Must be
+// called before the client is used.
+func (c *StreamableHTTPClient) SetListTimeout(timeout time.Duration) {
+	c.listTimeout = timeout
+}
+
+// SetListTimeout sets the per-call timeout of list methods on servers without a timeout of
+// their own. Zero means the client timeout. Must be called before the client is used.
+func (c *SSEClient) SetListTimeout(timeout time.Duration) {
+	c.listTimeout = timeout
+}
+
+// SetListTimeout sets the per-call timeout of list methods on servers without a timeout of
+// their own. Zero means the client timeout. Must be called before the client is used.
+func (c *WebSocketClient) SetListTimeout(timeout time.Duration) {
+	c.listTimeout = timeout
+}
@@ -0,0 +1,185 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestCallTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		server      *domain.MCPServer
+		method      string
+		fallback    time.Duration
+		listTimeout time.Duration
+		expected    time.Duration
+	}{
+		{
+			name:        "server timeout takes precedence",
+			server:      &domain.MCPServer{TimeoutSeconds: 45},
+			method:      "tools/list",
+			fallback:    30 * time.Second,
+			listTimeout: 10 * time.Second,
+			expected:    45 * time.Second,
+		},
+		{
+			name:        "list methods use the list timeout",
+			server:      &domain.MCPServer{},
+			method:      "resources/list",
+			fallback:    30 * time.Second,
+			listTimeout: 10 * time.Second,
+			expected:    10 * time.Second,
+		},
+		{
+			name:     "list methods keep a longer fallback without a list timeout",
+			server:   &domain.MCPServer{},
+			method:   "tools/list",
+			fallback: 30 * time.Second,
+			expected: 30 * time.Second,
+		},
+		{
+			name:        "tool calls use client fallback",
+			server:      &domain.MCPServer{},
+			method:      "tools/call",
+			fallback:    30 * time.Second,
+			listTimeout: 10 * time.Second,
+			expected:    30 * time.Second,
+		},
+		{
+			name:     "no override and no fallback",
+			server:   &domain.MCPServer{},
+			method:   "tools/call",
+			fallback: 0,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, callTimeout(tt.server, tt.method, tt.fallback, tt.listTimeout))
+		})
+	}
+}
+
+func TestWithCallTimeout(t *testing.T) {
+	t.Run("sets deadline from server timeout", func(t *testing.T) {
+		ctx, cancel := withCallTimeout(context.Background(), &domain.MCPServer{TimeoutSeconds: 5}, "tools/call", time.Minute, 0)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+	})
+
+	t.Run("no deadline without timeout", func(t *testing.T) {
+		ctx, cancel := withCallTimeout(context.Background(), &domain.MCPServer{}, "tools/call", 0, 0)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("client hint replaces the default timeout", func(t *testing.T) {
+		hinted := WithTimeoutHint(context.Background(), 2*time.Minute)
+		ctx, cancel := withCallTimeout(hinted, &domain.MCPServer{}, "tools/call", 30*time.Second, 0)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), deadline, time.Second)
+	})
+
+	t.Run("client hint is clamped by server timeout", func(t *testing.T) {
+		hinted := WithTimeoutHint(context.Background(), time.Hour)
+		ctx, cancel := withCallTimeout(hinted, &domain.MCPServer{TimeoutSeconds: 5}, "tools/call", time.Minute, 0)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+	})
+}
+
+func TestTimeoutHintFromMeta(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		expected time.Duration
+	}{
+		{name: "milliseconds hint", params: `{"name":"search","_meta":{"timeoutMs":1500}}`, expected: 1500 * time.Millisecond},
+		{name: "no _meta", params: `{"name":"search"}`, expected: 0},
+		{name: "negative hint", params: `{"_meta":{"timeoutMs":-1}}`, expected: 0},
+		{name: "non-numeric hint", params: `{"_meta":{"timeoutMs":"soon"}}`, expected: 0},
+		{name: "not an object", params: `[1,2]`, expected: 0},
+		{name: "empty params", params: ``, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TimeoutHintFromMeta(json.RawMessage(tt.params)))
+		})
+	}
+}
+
+func TestClampTimeoutHint(t *testing.T) {
+	assert.Equal(t, 3*time.Second, ClampTimeoutHint(&domain.MCPServer{TimeoutSeconds: 10}, 3*time.Second), "within bounds")
+	assert.Equal(t, 10*time.Second, ClampTimeoutHint(&domain.MCPServer{TimeoutSeconds: 10}, time.Hour), "capped by server timeout")
+	assert.Equal(t, time.Hour, ClampTimeoutHint(&domain.MCPServer{}, time.Hour), "no server timeout")
+	assert.Equal(t, minTimeoutHint, ClampTimeoutHint(&domain.MCPServer{}, time.Millisecond), "raised to the minimum")
+}
+
+func TestStreamableHTTPClient_Call_PerCallTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Block until the client gives up or the test ends
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	// Client-wide timeout is long; the server's 1s timeout must win
+	client := NewStreamableHTTPClient(logger.NewNopLogger(), time.Minute)
+	server := &domain.MCPServer{ID: "slow-server", URL: ts.URL, TimeoutSeconds: 1}
+
+	start := time.Now()
+	_, err := client.Call(context.Background(), server, "tools/call", nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestSSEClient_Call_PerCallTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	client := NewSSEClient(logger.NewNopLogger(), time.Minute)
+	server := &domain.MCPServer{ID: "slow-server", URL: ts.URL, TimeoutSeconds: 1}
+
+	start := time.Now()
+	_, err := client.Call(context.Background(), server, "tools/call", nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// spkiPinPrefix identifies the hash in a pin: "sha256/<base64 SHA-256 of the SubjectPublicKeyInfo>"
+const spkiPinPrefix = "sha256/"
+
+// ErrCertificatePinMismatch is returned when no certificate presented by a server matches its pins
+var ErrCertificatePinMismatch = errors.New("certificate does not match any pinned public key")
+
+// SPKIPin returns the pin of a certificate's public key in "sha256/<base64>" form
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return spkiPinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ValidateSPKIPin checks that pin is a "sha256/" prefixed base64 SHA-256 digest
+func ValidateSPKIPin(pin string) error {
+	digest, ok := strings.CutPrefix(pin, spkiPinPrefix)
+	if !ok {
+		return fmt.Errorf("pin %q must start with %q", pin, spkiPinPrefix)
+	}
+	raw, err := base64.StdEncoding.DecodeString(digest)
+	if err != nil {
+		return fmt.Errorf("pin %q is not valid base64: %w", pin, err)
+	}
+	if len(raw) != sha256.Size {
+		return fmt.Errorf("pin %q is not a SHA-256 digest", pin)
+	}
+	return nil
+}
+
+// verifySPKIPins returns a VerifyPeerCertificate func that accepts the handshake only if a
+// certificate in the presented chain matches one of the pins. It runs after normal chain
+// verification, so pinning narrows trust rather than replacing it.
+func verifySPKIPins(serverID string, pins []string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				continue
+			}
+			if slices.Contains(pins, SPKIPin(cert)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("server %s: %w", serverID, ErrCertificatePinMismatch)
+	}
+}
+
+// pinnedTransport returns a clone of base whose TLS handshakes enforce the server's pins
+func pinnedTransport(base *http.Transport, server *domain.MCPServer) *http.Transport {
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.VerifyPeerCertificate = verifySPKIPins(server.ID, server.TLSPins)
+	return transport
+}
+
+// pinnedClients caches an HTTP client per server for servers with TLS pins, rebuilding
+// it when the pins change. Servers without pins use the shared base client.
+type pinnedClients struct {
+	mu      sync.Mutex
+	clients map[string]pinnedClient
+}
+
+type pinnedClient struct {
+	pins   string
+	client *http.Client
+}
+
+// clientFor returns the client to use for calls to server
+func (p *pinnedClients) clientFor(base *http.Client, server *domain.MCPServer) *http.Client {
+	if len(server.TLSPins) == 0 {
+		return base
+	}
+	pins := strings.Join(server.TLSPins, ",")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.clients[server.ID]; ok && cached.pins == pins {
+		return cached.client
+	}
+
+	baseTransport, ok := base.Transport.(*http.Transport)
+	if !ok {
+		baseTransport = http.DefaultTransport.(*http.Transport)
+	}
+	client := *base
+	client.Transport = pinnedTransport(baseTransport, server)
+
+	if p.clients == nil {
+		p.clients = make(map[string]pinnedClient)
+	}
+	p.clients[server.ID] = pinnedClient{pins: pins, client: &client}
+	return &client
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func newPinnedTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestTLSPinning_StreamableHTTP(t *testing.T) {
+	ts := newPinnedTestServer(t)
+	otherPin := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr error
+	}{
+		{name: "no pins", pins: nil},
+		{name: "matching pin", pins: []string{otherPin, SPKIPin(ts.Certificate())}},
+		{name: "mismatched pin", pins: []string{otherPin}, wantErr: ErrCertificatePinMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewStreamableHTTPClient(logger.NewNopLogger(), 5*time.Second)
+			client.httpClient = ts.Client() // trusts the test server's CA
+			server := &domain.MCPServer{ID: "pinned", URL: ts.URL, TLSPins: tt.pins}
+
+			_, err := client.Call(context.Background(), server, "tools/list", nil)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTLSPinning_SSE(t *testing.T) {
+	ts := newPinnedTestServer(t)
+
+	client := NewSSEClient(logger.NewNopLogger(), 5*time.Second)
+	client.httpClient = ts.Client()
+	server := &domain.MCPServer{ID: "pinned", URL: ts.URL, TLSPins: []string{SPKIPin(ts.Certificate())}}
+
+	_, err := client.Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+
+	server.TLSPins = []string{"sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))}
+	_, err = client.Call(context.Background(), server, "tools/list", nil)
+	assert.ErrorIs(t, err, ErrCertificatePinMismatch, "changed pins must rebuild the cached client")
+}
+
+func TestValidateSPKIPin(t *testing.T) {
+	valid := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	assert.NoError(t, ValidateSPKIPin(valid))
+	assert.Error(t, ValidateSPKIPin("md5/"+base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))))
+	assert.Error(t, ValidateSPKIPin("sha256/not base64!"))
+	assert.Error(t, ValidateSPKIPin("sha256/"+base64.StdEncoding.EncodeToString([]byte("short"))))
+}
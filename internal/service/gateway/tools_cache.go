@@ -0,0 +1,209 @@
+package gateway
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// ErrUnknownTool is returned when a tools/call names a tool the backend has not advertised
+var ErrUnknownTool = errors.New("tool not found")
+
+// toolsEntry is one server's cached tool names
+type toolsEntry struct {
+	serverID  string
+	names     map[string]struct{}
+	fetchedAt time.Time
+	element   *list.Element // Position in ToolsCache.lru
+}
+
+// ToolsCacheConfig holds tools cache limits
+type ToolsCacheConfig struct {
+	// MaxAge is how old an entry may get before it is stale (0 = never)
+	MaxAge time.Duration
+	// MaxEntries caps the number of servers cached; the least recently used entry is
+	// evicted to make room (0 = unlimited)
+	MaxEntries int
+}
+
+// ToolsCache holds the tool names each backend server advertised in its last complete tools/list
+type ToolsCache struct {
+	mu         sync.Mutex
+	tools      map[string]*toolsEntry
+	lru        *list.List // Entries, most recently used first
+	maxAge     time.Duration
+	maxEntries int
+	metrics    *metrics.Registry
+	cache      metrics.CacheMetrics
+	now        func() time.Time
+}
+
+// NewToolsCache creates an empty, unbounded tools cache whose entries never go stale
+func NewToolsCache() *ToolsCache {
+	return NewToolsCacheWithMaxAge(0)
+}
+
+// NewToolsCacheWithMaxAge creates an empty, unbounded tools cache whose entries go stale
+// after maxAge
+func NewToolsCacheWithMaxAge(maxAge time.Duration) *ToolsCache {
+	return NewToolsCacheWithConfig(ToolsCacheConfig{MaxAge: maxAge}, nil)
+}
+
+// NewToolsCacheWithConfig creates an empty tools cache that reports its size, evictions
+// and lookups to metricsReg. metricsReg may be nil.
+func NewToolsCacheWithConfig(cfg ToolsCacheConfig, metricsReg *metrics.Registry) *ToolsCache {
+	return &ToolsCache{
+		tools:      make(map[string]*toolsEntry),
+		lru:        list.New(),
+		maxAge:     cfg.MaxAge,
+		maxEntries: cfg.MaxEntries,
+		metrics:    metricsReg,
+		cache:      metricsReg.Cache(metrics.CacheTools),
+		now:        time.Now,
+	}
+}
+
+// Set replaces the cached tool names for a server, evicting the least recently used
+// servers if the cache is full
+func (c *ToolsCache) Set(serverID string, names []string) {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.tools[serverID]; ok {
+		entry.names = set
+		entry.fetchedAt = c.now()
+		c.lru.MoveToFront(entry.element)
+		return
+	}
+
+	entry := &toolsEntry{serverID: serverID, names: set, fetchedAt: c.now()}
+	entry.element = c.lru.PushFront(entry)
+	c.tools[serverID] = entry
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.removeLocked(c.lru.Back().Value.(*toolsEntry))
+		c.cache.Evicted(1)
+		if c.metrics != nil {
+			c.metrics.GatewayToolsCacheEvictions.Inc()
+		}
+	}
+	c.reportSizeLocked()
+}
+
+// Lookup reports whether a tool is in the server's cached list, marking the list as
+// recently used. cached is false when nothing is cached for the server, in which case
+// found is meaningless.
+func (c *ToolsCache) Lookup(serverID, name string) (found bool, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.tools[serverID]
+	if !ok {
+		c.cache.Miss()
+		return false, false
+	}
+	c.cache.Hit()
+	c.lru.MoveToFront(entry.element)
+	_, found = entry.names[name]
+	return found, true
+}
+
+// Len returns the number of servers with cached tools
+func (c *ToolsCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stale reports whether the server's cached list is older than the max age
+func (c *ToolsCache) Stale(serverID string) bool {
+	if c.maxAge <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.tools[serverID]
+	return ok && c.now().Sub(entry.fetchedAt) > c.maxAge
+}
+
+// Invalidate drops the cached tool names for a server
+func (c *ToolsCache) Invalidate(serverID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.tools[serverID]; ok {
+		c.removeLocked(entry)
+		c.reportSizeLocked()
+	}
+}
+
+// removeLocked drops an entry. The caller must hold c.mu.
+func (c *ToolsCache) removeLocked(entry *toolsEntry) {
+	c.lru.Remove(entry.element)
+	delete(c.tools, entry.serverID)
+}
+
+// reportSizeLocked exports the number of cached servers. The caller must hold c.mu.
+func (c *ToolsCache) reportSizeLocked() {
+	c.cache.SetSize(c.lru.Len())
+	if c.metrics != nil {
+		c.metrics.GatewayToolsCacheEntries.Set(float64(c.lru.Len()))
+	}
+}
+
+// toolsListPage is the subset of a tools/list result needed for caching
+type toolsListPage struct {
+	Tools []struct {
+		Name string `json:"name"`
+	} `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// parseToolNames extracts tool names from a tools/list result.
+// Returns ok=false for unparseable or paginated results, which are not a complete list.
+func parseToolNames(result json.RawMessage) (names []string, ok bool) {
+	var page toolsListPage
+	if err := json.Unmarshal(result, &page); err != nil || page.NextCursor != "" {
+		return nil, false
+	}
+	names = make([]string, 0, len(page.Tools))
+	for _, tool := range page.Tools {
+		names = append(names, tool.Name)
+	}
+	return names, true
+}
+
+// stringParam extracts a top-level string field from request params
+func stringParam(params interface{}, key string) string {
+	switch p := params.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}:
+		value, _ := p[key].(string)
+		return value
+	}
+
+	data, ok := params.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(params); err != nil {
+			return ""
+		}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	value, _ := fields[key].(string)
+	return value
+}
@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func TestToolsCache(t *testing.T) {
+	c := NewToolsCache()
+
+	_, cached := c.Lookup("server-1", "echo")
+	assert.False(t, cached)
+
+	c.Set("server-1", []string{"echo", "add"})
+	found, cached := c.Lookup("server-1", "echo")
+	assert.True(t, cached)
+	assert.True(t, found)
+
+	found, cached = c.Lookup("server-1", "missing")
+	assert.True(t, cached)
+	assert.False(t, found)
+
+	c.Invalidate("server-1")
+	_, cached = c.Lookup("server-1", "echo")
+	assert.False(t, cached)
+}
+
+func TestToolsCache_Stale(t *testing.T) {
+	c := NewToolsCacheWithMaxAge(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	assert.False(t, c.Stale("server-1"), "missing entries are not stale")
+	c.Set("server-1", []string{"echo"})
+	assert.False(t, c.Stale("server-1"))
+
+	now = now.Add(61 * time.Second)
+	assert.True(t, c.Stale("server-1"))
+
+	assert.False(t, NewToolsCache().Stale("server-1"), "no max age never goes stale")
+}
+
+func TestToolsCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	metricsReg := metrics.NewRegistry()
+	c := NewToolsCacheWithConfig(ToolsCacheConfig{MaxEntries: 3}, metricsReg)
+
+	for _, serverID := range []string{"server-1", "server-2", "server-3"} {
+		c.Set(serverID, []string{"echo"})
+	}
+	// Using server-1 and refreshing server-2 leaves server-3 least recently used
+	c.Lookup("server-1", "echo")
+	c.Set("server-2", []string{"echo", "add"})
+
+	c.Set("server-4", []string{"echo"})
+	c.Set("server-5", []string{"echo"})
+
+	assert.Equal(t, 3, c.Len())
+	for serverID, wantCached := range map[string]bool{
+		"server-1": false, // Evicted second: least recently used once server-3 was gone
+		"server-2": true,
+		"server-3": false,
+		"server-4": true,
+		"server-5": true,
+	} {
+		_, cached := c.Lookup(serverID, "echo")
+		assert.Equal(t, wantCached, cached, serverID)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsReg.GatewayToolsCacheEvictions))
+	assert.Equal(t, float64(3), testutil.ToFloat64(metricsReg.GatewayToolsCacheEntries))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsReg.CacheEvictions.WithLabelValues(metrics.CacheTools)))
+	assert.Equal(t, float64(4), testutil.ToFloat64(metricsReg.CacheHits.WithLabelValues(metrics.CacheTools)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsReg.CacheMisses.WithLabelValues(metrics.CacheTools)))
+
+	c.Invalidate("server-5")
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsReg.GatewayToolsCacheEntries))
+}
+
+func TestToolsCache_Unbounded(t *testing.T) {
+	c := NewToolsCache()
+
+	for i := 0; i < 50; i++ {
+		c.Set(fmt.Sprintf("server-%d", i), []string{"echo"})
+	}
+	assert.Equal(t, 50, c.Len())
+}
+
+func TestParseToolNames(t *testing.T) {
+	names, ok := parseToolNames(json.RawMessage(`{"tools":[{"name":"echo"},{"name":"add"}]}`))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"echo", "add"}, names)
+
+	_, ok = parseToolNames(json.RawMessage(`{"tools":[{"name":"echo"}],"nextCursor":"page-2"}`))
+	assert.False(t, ok, "paginated results are not a complete list")
+
+	_, ok = parseToolNames(json.RawMessage(`not json`))
+	assert.False(t, ok)
+}
+
+func TestStringParam(t *testing.T) {
+	assert.Equal(t, "echo", stringParam(map[string]interface{}{"name": "echo"}, "name"))
+	assert.Equal(t, "echo", stringParam(json.RawMessage(`{"name":"echo"}`), "name"))
+	assert.Equal(t, "echo", stringParam(struct {
+		Name string `json:"name"`
+	}{Name: "echo"}, "name"))
+	assert.Empty(t, stringParam(nil, "name"))
+	assert.Empty(t, stringParam(map[string]interface{}{"name": 42}, "name"))
+}
+
+func TestService_RejectUnknownTools(t *testing.T) {
+	server := &domain.MCPServer{
+		ID:        "server-1",
+		Name:      "test",
+		URL:       "http://localhost:8080",
+		IsActive:  true,
+		Transport: domain.TransportStreamableHTTP,
+	}
+	toolsList := json.RawMessage(`{"tools":[{"name":"echo"}]}`)
+
+	newService := func(reject bool) (*Service, *mockStreamableHTTPClient) {
+		client := &mockStreamableHTTPClient{callResult: toolsList}
+		s := NewServiceWithClients(&mockServerRepository{server: server}, logger.NewNopLogger(), nil, nil, client)
+		s.rejectUnknownTools = reject
+		return s, client
+	}
+
+	t.Run("rejects nonexistent tool when cache is warm", func(t *testing.T) {
+		s, client := newService(true)
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", nil)
+		require.NoError(t, err)
+
+		_, err = s.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "ehco"})
+		assert.ErrorIs(t, err, ErrUnknownTool)
+		assert.Equal(t, 1, client.calls, "rejected call must not reach the backend")
+	})
+
+	t.Run("allows advertised tool when cache is warm", func(t *testing.T) {
+		s, client := newService(true)
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", nil)
+		require.NoError(t, err)
+
+		_, err = s.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "echo"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, client.calls)
+	})
+
+	t.Run("passes through on cache miss", func(t *testing.T) {
+		s, client := newService(true)
+
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "ehco"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, client.calls)
+	})
+
+	t.Run("passes through when disabled", func(t *testing.T) {
+		s, client := newService(false)
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", nil)
+		require.NoError(t, err)
+
+		_, err = s.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "ehco"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, client.calls)
+	})
+
+	t.Run("refreshes a stale cache before deciding", func(t *testing.T) {
+		s, client := newService(true)
+		now := time.Now()
+		s.tools = NewToolsCacheWithMaxAge(time.Minute)
+		s.tools.now = func() time.Time { return now }
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", nil)
+		require.NoError(t, err)
+
+		// The server has since added the tool; the cached list is past its max age
+		client.callResult = json.RawMessage(`{"tools":[{"name":"echo"},{"name":"ehco"}]}`)
+		now = now.Add(2 * time.Minute)
+
+		_, err = s.CallStreamableHTTP(context.Background(), "server-1", "tools/call", map[string]interface{}{"name": "ehco"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, client.calls, "tools/list refetched before the call")
+		assert.False(t, s.tools.Stale("server-1"))
+	})
+
+	t.Run("stale cache is not enforced when the refresh fails", func(t *testing.T) {
+		s, client := newService(true)
+		now := time.Now()
+		s.tools = NewToolsCacheWithMaxAge(time.Minute)
+		s.tools.now = func() time.Time { return now }
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", nil)
+		require.NoError(t, err)
+
+		client.callErr = errors.New("connection refused")
+		now = now.Add(2 * time.Minute)
+
+		assert.NoError(t, s.CheckToolCall(context.Background(), "server-1", "ehco"))
+		_, cached := s.tools.Lookup("server-1", "ehco")
+		assert.False(t, cached)
+	})
+
+	t.Run("later pages do not populate the cache", func(t *testing.T) {
+		s, _ := newService(true)
+		_, err := s.CallStreamableHTTP(context.Background(), "server-1", "tools/list", map[string]interface{}{"cursor": "page-2"})
+		require.NoError(t, err)
+
+		assert.NoError(t, s.CheckToolCall(context.Background(), "server-1", "ehco"))
+	})
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/tracing"
+)
+
+// startSpan starts a client span for an operation on server over transport. method is the
+// JSON-RPC method, if any.
+func startSpan(ctx context.Context, name string, server *domain.MCPServer, transport domain.TransportType, method string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		tracing.AttrServerID.String(server.ID),
+		tracing.AttrTransport.String(string(transport)),
+	}
+	if method != "" {
+		attrs = append(attrs, tracing.AttrRPCMethod.String(method))
+	}
+	return tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// SetTraceContext adds the trace context of the request's context, if any, to req
+func SetTraceContext(req *http.Request) {
+	tracing.Inject(req.Context(), req.Header)
+}
+
+// tracingTransport wraps the transport of a server's reverse proxy with a span per proxied
+// request, whose trace context is sent to the server
+type tracingTransport struct {
+	base   http.RoundTripper
+	server *domain.MCPServer
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := startSpan(req.Context(), "gateway.ProxyToServer", t.server, t.server.Transport, "")
+	span.SetAttributes(
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.path", req.URL.Path),
+	)
+	req = req.WithContext(ctx)
+	SetTraceContext(req)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, fmt.Sprintf("server returned %d", resp.StatusCode))
+	}
+	span.End()
+	return resp, nil
+}
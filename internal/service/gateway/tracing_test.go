@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/tracing"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// useSpanRecorder installs a global tracer provider recording spans in memory for the test
+func useSpanRecorder(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder, provider
+}
+
+// endedSpan returns the ended span named name
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no ended span named %q", name)
+	return nil
+}
+
+// spanAttributes returns the span's attributes as a map
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestTracing_ProxyToServer(t *testing.T) {
+	recorder, provider := useSpanRecorder(t)
+
+	var traceparent string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`)
+	}))
+	defer backend.Close()
+
+	svc := NewService(multiServerRepository{
+		"s1": {ID: "s1", URL: backend.URL, Transport: domain.TransportHTTP, IsActive: true},
+	}, logger.NewNopLogger(), nil)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	proxy, _, err := svc.ProxyToServer(ctx, "s1")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v1/gateway/s1/tools/list", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, req)
+	parent.End()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	span := endedSpan(t, recorder, "gateway.ProxyToServer")
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	attrs := spanAttributes(span)
+	assert.Equal(t, "s1", attrs[tracing.AttrServerID])
+	assert.Equal(t, "http", attrs[tracing.AttrTransport])
+	assert.Equal(t, "200", attrs["http.response.status_code"])
+
+	// The server joins the trace as a child of the proxy span
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()), traceparent)
+}
+
+func TestTracing_Call(t *testing.T) {
+	recorder, _ := useSpanRecorder(t)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			ID     *int   `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		if msg.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if msg.Method == "tools/call" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32602,"message":"Invalid params"}}`, *msg.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{}}`, *msg.ID)
+	}))
+	defer backend.Close()
+
+	svc := NewService(multiServerRepository{
+		"s1": {ID: "s1", URL: backend.URL + "/mcp", Transport: domain.TransportStreamableHTTP, IsActive: true},
+	}, logger.NewNopLogger(), nil)
+
+	_, err := svc.InitializeStreamableHTTP(context.Background(), "s1")
+	require.NoError(t, err)
+	_, err = svc.CallStreamableHTTP(context.Background(), "s1", "tools/call", map[string]interface{}{"name": "echo"})
+	require.Error(t, err)
+
+	initialize := endedSpan(t, recorder, "gateway.Initialize")
+	assert.Equal(t, "initialize", spanAttributes(initialize)[tracing.AttrRPCMethod])
+
+	call := endedSpan(t, recorder, "gateway.Call")
+	attrs := spanAttributes(call)
+	assert.Equal(t, "s1", attrs[tracing.AttrServerID])
+	assert.Equal(t, "streamable_http", attrs[tracing.AttrTransport])
+	assert.Equal(t, "tools/call", attrs[tracing.AttrRPCMethod])
+	assert.Equal(t, "MCP error -32602: Invalid params", call.Status().Description)
+}
@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+)
+
+// ErrTransportDetectionFailed is returned when a server without an explicit transport
+// answers neither the Streamable HTTP nor the SSE probe
+var ErrTransportDetectionFailed = errors.New("transport auto-detection failed")
+
+// transportProbeTimeout bounds each probe when the caller's context has no earlier deadline
+const transportProbeTimeout = 10 * time.Second
+
+// detectedTransports remembers the transport probing found for each server, keyed by
+// server ID. An entry only applies while the server's URL is unchanged.
+type detectedTransports struct {
+	mu      sync.Mutex
+	servers map[string]detectedTransport
+}
+
+type detectedTransport struct {
+	url       string
+	transport domain.TransportType
+}
+
+func newDetectedTransports() *detectedTransports {
+	return &detectedTransports{servers: make(map[string]detectedTransport)}
+}
+
+func (d *detectedTransports) get(server *domain.MCPServer) (domain.TransportType, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	detected, ok := d.servers[server.ID]
+	if !ok || detected.url != server.URL {
+		return "", false
+	}
+	return detected.transport, true
+}
+
+func (d *detectedTransports) set(server *domain.MCPServer, transport domain.TransportType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.servers[server.ID] = detectedTransport{url: server.URL, transport: transport}
+}
+
+// probeTransport finds a server's transport by trying a Streamable HTTP initialize and
+// then an SSE stream request. When both fail, the error wraps
+// ErrTransportDetectionFailed and both probe errors, each labeled with its transport.
+func (s *Service) probeTransport(ctx context.Context, server *domain.MCPServer) (domain.TransportType, error) {
+	if transport, ok := s.detected.get(server); ok {
+		return transport, nil
+	}
+
+	streamableErr := s.probeStreamableHTTP(ctx, server)
+	if streamableErr == nil {
+		s.detected.set(server, domain.TransportStreamableHTTP)
+		return domain.TransportStreamableHTTP, nil
+	}
+	sseErr := s.probeSSE(ctx, server)
+	if sseErr == nil {
+		s.detected.set(server, domain.TransportSSE)
+		return domain.TransportSSE, nil
+	}
+
+	s.logger.Warn().
+		Str("server_id", server.ID).
+		Str("url", server.URL).
+		Str("streamable_http_error", streamableErr.Error()).
+		Str("sse_error", sseErr.Error()).
+		Msg("Server answered neither transport probe")
+	return "", fmt.Errorf("%w for server %s: %s probe: %w; %s probe: %w",
+		ErrTransportDetectionFailed, server.ID,
+		domain.TransportStreamableHTTP, streamableErr,
+		domain.TransportSSE, sseErr)
+}
+
+// probeStreamableHTTP initializes a Streamable HTTP session, which the next call reuses
+func (s *Service) probeStreamableHTTP(ctx context.Context, server *domain.MCPServer) error {
+	if s.streamableHTTPClient == nil {
+		return errors.New("no Streamable HTTP client")
+	}
+	ctx, cancel := context.WithTimeout(ctx, transportProbeTimeout)
+	defer cancel()
+
+	_, err := s.streamableHTTPClient.Initialize(ctx, server)
+	return err
+}
+
+// probeSSE checks that the server answers a GET with an event stream
+func (s *Service) probeSSE(ctx context.Context, server *domain.MCPServer) error {
+	ctx, cancel := context.WithTimeout(ctx, transportProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(HeaderAccept, ContentTypeEventStream)
+	SetGatewayHops(req)
+	SetRequestID(req)
+	SetPriorityHint(req)
+	SetTraceContext(req)
+	if err := s.injectAuth(req, server); err != nil {
+		return err
+	}
+
+	client := http.DefaultClient
+	if s.egress != nil {
+		client = &http.Client{Transport: s.egress.Transport()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(HeaderContentType))
+	if mediaType != ContentTypeEventStream {
+		return fmt.Errorf("server returned content type %q, not an event stream", resp.Header.Get(HeaderContentType))
+	}
+	return nil
+}
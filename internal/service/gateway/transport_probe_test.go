@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// newProbeTestService returns a probing service for a server at url with no explicit transport
+func newProbeTestService(url string) *Service {
+	log := logger.NewNopLogger()
+	repo := &mockServerRepository{server: &domain.MCPServer{ID: "server-1", URL: url, IsActive: true}}
+	svc := NewServiceWithClients(repo, log, nil, nil, NewStreamableHTTPClient(log, 5*time.Second))
+	svc.probeTransports = true
+	return svc
+}
+
+func TestService_GetTransportType_Probing(t *testing.T) {
+	t.Run("both probes fail", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+		svc := newProbeTestService(ts.URL + "/api")
+
+		transport, server, err := svc.GetTransportType(context.Background(), "server-1")
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTransportDetectionFailed)
+		assert.Empty(t, transport)
+		assert.Nil(t, server)
+		assert.Contains(t, err.Error(), "streamable_http probe:")
+		assert.Contains(t, err.Error(), "500")
+		assert.Contains(t, err.Error(), "sse probe: server returned status 404")
+	})
+
+	t.Run("both probes fail to connect", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		url := ts.URL
+		ts.Close()
+		svc := newProbeTestService(url)
+
+		_, _, err := svc.GetTransportType(context.Background(), "server-1")
+
+		require.ErrorIs(t, err, ErrTransportDetectionFailed)
+		assert.Regexp(t, `streamable_http probe: .*connection refused.*; sse probe: request failed: .*connection refused`, err.Error())
+	})
+
+	t.Run("detects and remembers Streamable HTTP", func(t *testing.T) {
+		var posts atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			posts.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25"}}`))
+		}))
+		defer ts.Close()
+		svc := newProbeTestService(ts.URL + "/api")
+
+		transport, server, err := svc.GetTransportType(context.Background(), "server-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransportStreamableHTTP, transport)
+		assert.NotNil(t, server)
+
+		probes := posts.Load()
+		transport, _, err = svc.GetTransportType(context.Background(), "server-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransportStreamableHTTP, transport)
+		assert.Equal(t, probes, posts.Load(), "detected transport is reused")
+	})
+
+	t.Run("falls back to SSE", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		svc := newProbeTestService(ts.URL + "/api")
+
+		transport, _, err := svc.GetTransportType(context.Background(), "server-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransportSSE, transport)
+	})
+
+	t.Run("explicit transport is not probed", func(t *testing.T) {
+		svc := newProbeTestService("http://127.0.0.1:1/api")
+		svc.repo.(*mockServerRepository).server.Transport = domain.TransportHTTP
+
+		transport, _, err := svc.GetTransportType(context.Background(), "server-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransportHTTP, transport)
+	})
+}
@@ -0,0 +1,652 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/tracing"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+const (
+	// defaultWebSocketPingInterval is how often a connection is pinged to keep it open
+	// and notice dead servers, unless SetKeepAlive changes it
+	defaultWebSocketPingInterval = 30 * time.Second
+	// defaultWebSocketPongWait is how long a connection may go without any message or
+	// pong before it is considered dead, unless SetKeepAlive changes it
+	defaultWebSocketPongWait = 2 * defaultWebSocketPingInterval
+	// webSocketReconnectAttempts caps background reconnects after a connection drops.
+	// Once they run out the next call connects again.
+	webSocketReconnectAttempts = 5
+)
+
+// Reconnect delays used when the retry policy doesn't set them
+const (
+	defaultWebSocketReconnectBase = time.Second
+	defaultWebSocketReconnectMax  = 30 * time.Second
+)
+
+// ErrWebSocketClosed is returned for calls on a connection that closed before the
+// server answered
+var ErrWebSocketClosed = errors.New("websocket connection closed")
+
+// WebSocketClient handles communication with MCP servers that speak JSON-RPC over a
+// persistent WebSocket. Each server has one connection, initialized once and shared by
+// concurrent calls; responses are matched to requests by id. A connection that drops is
+// reconnected in the background with backoff so server notifications keep arriving.
+type WebSocketClient struct {
+	dialer      *websocket.Dialer
+	timeout     time.Duration // Fallback per-call timeout
+	listTimeout time.Duration // Per-call timeout of list methods (0 = timeout)
+	logger      logger.Logger
+	requestID   atomic.Int64
+
+	maxResponseBytes int64         // Message size limit for servers without their own (0 = unlimited)
+	retry            RetryPolicy   // Retries of dials that fail to connect, and reconnect delays
+	pingInterval     time.Duration // Keepalive pings (0 = disabled)
+	pongWait         time.Duration // Silence after which a connection is dead (0 = never)
+
+	mu           sync.Mutex
+	conns        map[string]*webSocketConn     // Open connection per server
+	locks        map[string]*sync.Mutex        // Serializes connecting per server
+	reconnecting map[string]context.CancelFunc // Background reconnects in progress
+
+	onNotification NotificationFunc // Called for server notifications (nil = ignored)
+	secrets        *SecretStore     // Resolves secret references in auth configs (nil = used as stored)
+}
+
+// outgoingNotification is a JSON-RPC notification generated by the gateway; unlike a
+// request it has no id
+type outgoingNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// webSocketConn is one server's connection and the calls waiting for its responses
+type webSocketConn struct {
+	server  *domain.MCPServer
+	ws      *websocket.Conn
+	session *MCPSession
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan JSONRPCResponse // Waiting calls by encoded request id
+	done    chan struct{}                   // Closed once the connection is closed
+	err     error                           // Why the connection closed
+}
+
+// NewWebSocketClient creates a new WebSocket MCP client
+func NewWebSocketClient(log logger.Logger, timeout time.Duration) *WebSocketClient {
+	return &WebSocketClient{
+		dialer:       &websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: timeout},
+		timeout:      timeout,
+		logger:       log,
+		pingInterval: defaultWebSocketPingInterval,
+		pongWait:     defaultWebSocketPongWait,
+		conns:        make(map[string]*webSocketConn),
+		locks:        make(map[string]*sync.Mutex),
+		reconnecting: make(map[string]context.CancelFunc),
+
+		maxResponseBytes: DefaultMaxResponseBytes,
+	}
+}
+
+// SetMaxResponseBytes sets the message size limit for servers without a MaxResponseBytes
+// of their own. Zero means unlimited. Must be called before the client is used.
+func (c *WebSocketClient) SetMaxResponseBytes(limit int64) {
+	c.maxResponseBytes = limit
+}
+
+// SetRetryPolicy sets how dials that fail to connect are retried, and the delays between
+// background reconnects. Must be called before the client is used.
+func (c *WebSocketClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetKeepAlive pings each connection every pingInterval (0 = never) and considers it dead,
+// and reconnects it, once nothing has arrived on it for pongTimeout, not even a pong. A
+// zero pongTimeout is twice the ping interval. Must be called before the client is used.
+func (c *WebSocketClient) SetKeepAlive(pingInterval, pongTimeout time.Duration) {
+	if pongTimeout == 0 {
+		pongTimeout = 2 * pingInterval
+	}
+	c.pingInterval = pingInterval
+	c.pongWait = pongTimeout
+}
+
+// OnNotification registers fn to be called for notifications servers send over their
+// connection. Must be called before the client is used.
+func (c *WebSocketClient) OnNotification(fn NotificationFunc) {
+	c.onNotification = fn
+}
+
+// IsWebSocketServer determines if a server uses the WebSocket transport from its ws:// or
+// wss:// URL
+func IsWebSocketServer(server *domain.MCPServer) bool {
+	url := strings.ToLower(server.URL)
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// Initialize opens a new connection to the server and runs the initialize handshake on it,
+// replacing any connection the server already had
+func (c *WebSocketClient) Initialize(ctx context.Context, server *domain.MCPServer) (*MCPSession, error) {
+	lock := c.serverLock(server.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	conn, err := c.connect(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	return conn.session, nil
+}
+
+// Call sends a JSON-RPC request over the server's connection, connecting and initializing
+// first when there is none, and waits for the response with the same id
+func (c *WebSocketClient) Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := withCallTimeout(ctx, server, method, c.timeout, c.listTimeout)
+	defer cancel()
+
+	conn, err := c.connection(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("server_id", server.ID).
+		Str("method", method).
+		Msg("Sending MCP request over WebSocket")
+
+	if isNotification(method) {
+		return nil, conn.write(ctx, outgoingNotification{JSONRPC: "2.0", Method: method, Params: params})
+	}
+	return conn.call(ctx, RequestID(server, c.requestID.Add(1)), method, params)
+}
+
+// TerminateSession closes the server's connection. Calls waiting on it fail and it isn't
+// reconnected.
+func (c *WebSocketClient) TerminateSession(ctx context.Context, server *domain.MCPServer) error {
+	c.mu.Lock()
+	if cancel, ok := c.reconnecting[server.ID]; ok {
+		cancel()
+		delete(c.reconnecting, server.ID)
+	}
+	conn := c.conns[server.ID]
+	delete(c.conns, server.ID)
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	conn.close(websocket.CloseNormalClosure, ErrWebSocketClosed)
+	c.logger.Info().Str("server_id", server.ID).Msg("WebSocket MCP session terminated")
+	return nil
+}
+
+// Close closes every connection
+func (c *WebSocketClient) Close() {
+	c.mu.Lock()
+	servers := make([]*domain.MCPServer, 0, len(c.conns)+len(c.reconnecting))
+	for _, conn := range c.conns {
+		servers = append(servers, conn.server)
+	}
+	for serverID := range c.reconnecting {
+		servers = append(servers, &domain.MCPServer{ID: serverID})
+	}
+	c.mu.Unlock()
+
+	for _, server := range servers {
+		_ = c.TerminateSession(context.Background(), server) // #nosec G104 -- closing never fails
+	}
+}
+
+// connection returns the server's open connection, connecting when there is none
+func (c *WebSocketClient) connection(ctx context.Context, server *domain.MCPServer) (*webSocketConn, error) {
+	lock := c.serverLock(server.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	conn := c.conns[server.ID]
+	c.mu.Unlock()
+	if conn != nil && conn.open() {
+		return conn, nil
+	}
+	return c.connect(ctx, server)
+}
+
+// serverLock returns the mutex that serializes connecting to a server
+func (c *WebSocketClient) serverLock(serverID string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lock, ok := c.locks[serverID]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[serverID] = lock
+	}
+	return lock
+}
+
+// connect dials the server, runs the initialize handshake and makes the connection the
+// server's current one. The caller holds the server's lock.
+func (c *WebSocketClient) connect(ctx context.Context, server *domain.MCPServer) (*webSocketConn, error) {
+	c.logger.Info().
+		Str("server_id", server.ID).
+		Str("url", server.URL).
+		Msg("Initializing MCP session with WebSocket transport")
+
+	header := http.Header{}
+	if id := RequestIDFromContext(ctx); id != "" {
+		header.Set(HeaderRequestID, id) // The request that opened the connection
+	}
+	tracing.Inject(ctx, header)
+	if err := c.injectAuth(ctx, header, server); err != nil {
+		return nil, err
+	}
+
+	var ws *websocket.Conn
+	err := retryConnect(ctx, c.retry, func() error {
+		var resp *http.Response
+		var err error
+		ws, resp, err = c.dialer.DialContext(ctx, server.URL, header)
+		if err != nil && resp != nil {
+			return fmt.Errorf("%w: server returned %d", err, resp.StatusCode)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if limit := ResponseLimit(server, c.maxResponseBytes); limit > 0 {
+		ws.SetReadLimit(limit)
+	}
+
+	conn := &webSocketConn{
+		server:  server,
+		ws:      ws,
+		pending: make(map[string]chan JSONRPCResponse),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(conn)
+	if c.pingInterval > 0 {
+		go c.pingLoop(conn)
+	}
+
+	session, err := c.initialize(ctx, conn)
+	if err != nil {
+		conn.close(websocket.CloseNormalClosure, ErrWebSocketClosed)
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	conn.session = session
+
+	c.mu.Lock()
+	previous := c.conns[server.ID]
+	c.conns[server.ID] = conn
+	c.mu.Unlock()
+	if previous != nil && previous != conn {
+		previous.close(websocket.CloseNormalClosure, ErrWebSocketClosed)
+	}
+
+	c.logger.Info().
+		Str("server_id", server.ID).
+		Str("protocol_version", session.ProtocolVersion).
+		Msg("WebSocket MCP session initialized")
+	return conn, nil
+}
+
+// initialize runs the initialize handshake on a new connection
+func (c *WebSocketClient) initialize(ctx context.Context, conn *webSocketConn) (*MCPSession, error) {
+	server := conn.server
+	params := InitializeParams{
+		ProtocolVersion: requestedProtocolVersion(server),
+		ClientInfo: ClientInfo{
+			Name:    "waffles",
+			Version: "1.0.0",
+		},
+	}
+	result, err := conn.call(ctx, RequestID(server, c.requestID.Add(1)), "initialize", params)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &MCPSession{
+		ServerID:        server.ID,
+		ServerURL:       server.URL,
+		Initialized:     true,
+		ProtocolVersion: params.ProtocolVersion,
+		CreatedAt:       time.Now(),
+	}
+	var initResult InitializeResult
+	if err := json.Unmarshal(result, &initResult); err != nil {
+		c.logger.Debug().Err(err).Str("server_id", server.ID).Msg("Failed to parse initialize result")
+	} else {
+		session.ServerInfo = initResult.ServerInfo
+		session.Instructions = initResult.Instructions
+		session.Capabilities = initResult.Capabilities
+		if initResult.ProtocolVersion != "" {
+			if !IsSupportedProtocolVersion(initResult.ProtocolVersion) {
+				return nil, fmt.Errorf("%w: server chose %s", ErrUnsupportedProtocolVersion, initResult.ProtocolVersion)
+			}
+			session.ProtocolVersion = initResult.ProtocolVersion
+		}
+	}
+
+	if err := conn.write(ctx, outgoingNotification{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+	}
+	return session, nil
+}
+
+// readLoop dispatches the connection's messages until it closes, then reconnects unless
+// it was closed on purpose
+func (c *WebSocketClient) readLoop(conn *webSocketConn) {
+	_ = conn.ws.SetReadDeadline(c.readDeadline()) // #nosec G104 -- a failed deadline surfaces on read
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(c.readDeadline())
+	})
+
+	for {
+		_, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			if conn.close(websocket.CloseGoingAway, fmt.Errorf("%w: %w", ErrWebSocketClosed, err)) {
+				c.logger.Warn().Err(err).Str("server_id", conn.server.ID).Msg("WebSocket connection lost")
+				c.reconnect(conn)
+			}
+			return
+		}
+		_ = conn.ws.SetReadDeadline(c.readDeadline()) // #nosec G104 -- a failed deadline surfaces on read
+
+		messages, err := splitBatchMessages(data)
+		if err != nil {
+			c.logger.Warn().Err(err).Str("server_id", conn.server.ID).Msg("Ignoring invalid WebSocket message")
+			continue
+		}
+		for _, msg := range messages {
+			c.dispatch(conn, msg)
+		}
+	}
+}
+
+// readDeadline is when a connection that receives nothing more is considered dead; zero
+// means never
+func (c *WebSocketClient) readDeadline() time.Time {
+	if c.pongWait <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(c.pongWait)
+}
+
+// dispatch routes one message: a response goes to the call waiting for it, a notification
+// to the notification callback, and a server request is answered
+func (c *WebSocketClient) dispatch(conn *webSocketConn, msg json.RawMessage) {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		c.logger.Warn().Err(err).Str("server_id", conn.server.ID).Msg("Ignoring invalid WebSocket message")
+		return
+	}
+	hasID := len(envelope.ID) > 0 && string(envelope.ID) != "null"
+
+	switch {
+	case envelope.Method != "" && !hasID:
+		if c.onNotification != nil {
+			c.onNotification(conn.server.ID, envelope.Method, msg)
+		}
+	case envelope.Method != "":
+		c.answerServerRequest(conn, envelope.ID, envelope.Method)
+	case hasID:
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(msg, &resp); err != nil {
+			c.logger.Warn().Err(err).Str("server_id", conn.server.ID).Msg("Ignoring invalid WebSocket response")
+			return
+		}
+		if !conn.resolve(envelope.ID, resp) {
+			c.logger.Debug().
+				Str("server_id", conn.server.ID).
+				Str("id", string(envelope.ID)).
+				Msg("Ignoring WebSocket response nobody is waiting for")
+		}
+	}
+}
+
+// answerServerRequest replies to a request the server sent. Pings are answered; the
+// gateway has no client to relay anything else to.
+func (c *WebSocketClient) answerServerRequest(conn *webSocketConn, id json.RawMessage, method string) {
+	response := outgoingResponse{JSONRPC: "2.0", ID: id}
+	if method == "ping" {
+		response.Result = json.RawMessage(`{}`)
+	} else {
+		response.Error = &JSONRPCError{Code: -32601, Message: "Method not found: " + method}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if err := conn.write(ctx, response); err != nil {
+		c.logger.Debug().Err(err).Str("server_id", conn.server.ID).Str("method", method).Msg("Failed to answer server request")
+	}
+}
+
+// pingLoop pings the connection until it closes
+func (c *WebSocketClient) pingLoop(conn *webSocketConn) {
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+			conn.writeMu.Lock()
+			err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.timeout))
+			conn.writeMu.Unlock()
+			if err != nil {
+				c.logger.Debug().Err(err).Str("server_id", conn.server.ID).Msg("WebSocket ping failed")
+			}
+		}
+	}
+}
+
+// reconnect reopens a server's dropped connection in the background, waiting with
+// backoff between attempts. It gives up after webSocketReconnectAttempts, or when the
+// session is terminated or another call has already reconnected.
+func (c *WebSocketClient) reconnect(lost *webSocketConn) {
+	server := lost.server
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.mu.Lock()
+	if c.conns[server.ID] != lost {
+		c.mu.Unlock()
+		cancel()
+		return
+	}
+	delete(c.conns, server.ID)
+	if previous, ok := c.reconnecting[server.ID]; ok {
+		previous()
+	}
+	c.reconnecting[server.ID] = cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			c.mu.Lock()
+			delete(c.reconnecting, server.ID)
+			c.mu.Unlock()
+		}()
+
+		base, maxDelay := c.retry.BaseDelay, c.retry.MaxDelay
+		if base <= 0 {
+			base = defaultWebSocketReconnectBase
+		}
+		if maxDelay <= 0 {
+			maxDelay = defaultWebSocketReconnectMax
+		}
+		backoff := NewBackoff(c.retry.Jitter, base, maxDelay)
+
+		for attempt := 1; attempt <= webSocketReconnectAttempts; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff.Next()):
+			}
+
+			connectCtx, connectCancel := context.WithTimeout(ctx, c.timeout)
+			_, err := c.connection(connectCtx, server)
+			connectCancel()
+			if err == nil {
+				c.logger.Info().Str("server_id", server.ID).Int("attempt", attempt).Msg("WebSocket connection restored")
+				return
+			}
+			c.logger.Warn().Err(err).Str("server_id", server.ID).Int("attempt", attempt).Msg("WebSocket reconnect failed")
+		}
+	}()
+}
+
+// injectAuth adds authentication headers to the WebSocket handshake based on server
+// config, resolving secret references in it first
+func (c *WebSocketClient) injectAuth(ctx context.Context, header http.Header, server *domain.MCPServer) error {
+	server, err := resolveServerAuth(ctx, server, c.secrets)
+	if err != nil {
+		return err
+	}
+	if len(server.AuthConfig) == 0 {
+		return nil
+	}
+
+	var authConfig map[string]interface{}
+	if err := json.Unmarshal(server.AuthConfig, &authConfig); err != nil {
+		c.logger.Error().Err(err).Str("server_id", server.ID).Msg("Failed to parse auth config")
+		return nil
+	}
+
+	// The helpers work on requests; only the headers are used
+	req := &http.Request{Header: header}
+	switch server.AuthType {
+	case domain.ServerAuthBearer:
+		if token, ok := authConfig["token"].(string); ok && token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		}
+	case domain.ServerAuthBasic:
+		username, _ := authConfig["username"].(string)
+		password, _ := authConfig["password"].(string)
+		if username != "" && password != "" {
+			req.SetBasicAuth(username, password)
+		}
+	case domain.ServerAuthAPIKey:
+		applyAPIKeyAuth(req, authConfig)
+	}
+	return nil
+}
+
+// call sends a request and waits for the response with its id
+func (w *webSocketConn) call(ctx context.Context, id interface{}, method string, params interface{}) (json.RawMessage, error) {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request id: %w", err)
+	}
+	responses := make(chan JSONRPCResponse, 1)
+
+	w.mu.Lock()
+	if w.err != nil {
+		err := w.err
+		w.mu.Unlock()
+		return nil, err
+	}
+	w.pending[string(key)] = responses
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.pending, string(key))
+		w.mu.Unlock()
+	}()
+
+	if err := w.write(ctx, outgoingRequest{JSONRPC: "2.0", Method: method, Params: withPriorityMeta(ctx, params), ID: id}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Result, nil
+	case <-w.done:
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return nil, w.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// write sends one message, waiting at most until ctx's deadline
+func (w *webSocketConn) write(ctx context.Context, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultWebSocketPongWait)
+	}
+	_ = w.ws.SetWriteDeadline(deadline) // #nosec G104 -- a failed deadline surfaces on write
+	if err := w.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return nil
+}
+
+// resolve hands a response to the call waiting for id, reporting whether there was one
+func (w *webSocketConn) resolve(id json.RawMessage, resp JSONRPCResponse) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	responses, ok := w.pending[string(bytes.TrimSpace(id))]
+	if ok {
+		delete(w.pending, string(bytes.TrimSpace(id)))
+		responses <- resp
+	}
+	return ok
+}
+
+// open reports whether the connection can still carry calls
+func (w *webSocketConn) open() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err == nil
+}
+
+// close closes the connection with a close frame, failing waiting calls with err. Returns
+// whether this call closed it and it wasn't being closed on purpose, i.e. whether it was lost.
+func (w *webSocketConn) close(code int, err error) bool {
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return false
+	}
+	w.err = err
+	close(w.done)
+	w.mu.Unlock()
+
+	w.writeMu.Lock()
+	_ = w.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), time.Now().Add(time.Second)) // #nosec G104 -- best effort
+	w.writeMu.Unlock()
+	_ = w.ws.Close() // #nosec G104 -- nothing to do about a failed close
+	return code != websocket.CloseNormalClosure
+}
@@ -0,0 +1,381 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// wsTestMessage is a JSON-RPC message as the test backend sees it
+type wsTestMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// wsPeer is the backend's side of one connection
+type wsPeer struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (p *wsPeer) send(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = p.conn.WriteMessage(websocket.TextMessage, []byte(msg))
+}
+
+// webSocketBackend is an MCP server speaking JSON-RPC over WebSocket. It answers
+// initialize itself and hands every other message to handle, each on its own goroutine.
+type webSocketBackend struct {
+	URL         string
+	connections atomic.Int32
+	initializes atomic.Int32
+	authHeader  atomic.Value
+	ignorePings atomic.Bool // Leave pings unanswered, like a server that has hung
+
+	mu       sync.Mutex
+	peer     *wsPeer
+	received []wsTestMessage
+}
+
+func newWebSocketBackend(t *testing.T, handle func(peer *wsPeer, msg wsTestMessage)) *webSocketBackend {
+	t.Helper()
+	backend := &webSocketBackend{}
+	upgrader := websocket.Upgrader{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backend.authHeader.Store(r.Header.Get("Authorization"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetPingHandler(func(data string) error {
+			if backend.ignorePings.Load() {
+				return nil
+			}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		backend.connections.Add(1)
+		peer := &wsPeer{conn: conn}
+		backend.mu.Lock()
+		backend.peer = peer
+		backend.mu.Unlock()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg wsTestMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+			backend.mu.Lock()
+			backend.received = append(backend.received, msg)
+			backend.mu.Unlock()
+
+			if msg.Method == "initialize" {
+				backend.initializes.Add(1)
+				peer.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":%q,"serverInfo":{"name":"ws-server","version":"2.1.0"},"capabilities":{"tools":{}}}}`, msg.ID, MCPProtocolVersion))
+				continue
+			}
+			if handle != nil {
+				go handle(peer, msg)
+			}
+		}
+	}))
+	t.Cleanup(ts.Close)
+	backend.URL = "ws" + strings.TrimPrefix(ts.URL, "http")
+	return backend
+}
+
+// drop closes the backend's current connection without a close handshake
+func (b *webSocketBackend) drop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.peer != nil {
+		_ = b.peer.conn.Close()
+	}
+}
+
+// methods returns the methods the backend received, in order
+func (b *webSocketBackend) methods() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var methods []string
+	for _, msg := range b.received {
+		methods = append(methods, msg.Method)
+	}
+	return methods
+}
+
+// echoTools answers tools/call with the call's "n" argument, after waiting that many
+// milliseconds so responses come back out of order
+func echoTools(peer *wsPeer, msg wsTestMessage) {
+	switch msg.Method {
+	case "tools/list":
+		peer.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"tools":[{"name":"echo"}]}}`, msg.ID))
+	case "tools/call":
+		var params struct {
+			Arguments struct {
+				N int `json:"n"`
+			} `json:"arguments"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		time.Sleep(time.Duration(params.Arguments.N) * time.Millisecond)
+		peer.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"content":[{"type":"text","text":"%d"}]}}`, msg.ID, params.Arguments.N))
+	case "fail":
+		peer.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"nope"}}`, msg.ID))
+	}
+}
+
+func newTestWebSocketClient(t *testing.T) *WebSocketClient {
+	t.Helper()
+	client := NewWebSocketClient(logger.NewNopLogger(), 5*time.Second)
+	client.SetRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestWebSocketClient_CorrelatesConcurrentCalls(t *testing.T) {
+	backend := newWebSocketBackend(t, echoTools)
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{ID: "ws-1", URL: backend.URL, Transport: domain.TransportWebSocket}
+
+	const calls = 8
+	results := make([]string, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Later calls answer first
+			n := (calls - i) * 5
+			raw, err := client.Call(context.Background(), server, "tools/call", map[string]interface{}{
+				"name":      "echo",
+				"arguments": map[string]interface{}{"n": n},
+			})
+			errs[i] = err
+			results[i] = string(raw)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < calls; i++ {
+		require.NoError(t, errs[i])
+		assert.JSONEq(t, fmt.Sprintf(`{"content":[{"type":"text","text":"%d"}]}`, (calls-i)*5), results[i])
+	}
+	assert.Equal(t, int32(1), backend.connections.Load(), "calls share one connection")
+	assert.Equal(t, int32(1), backend.initializes.Load())
+	assert.Equal(t, "notifications/initialized", backend.methods()[1])
+}
+
+func TestWebSocketClient_Initialize(t *testing.T) {
+	backend := newWebSocketBackend(t, nil)
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{
+		ID:         "ws-1",
+		URL:        backend.URL,
+		AuthType:   domain.ServerAuthBearer,
+		AuthConfig: json.RawMessage(`{"token":"secret"}`),
+	}
+
+	session, err := client.Initialize(context.Background(), server)
+	require.NoError(t, err)
+	assert.Equal(t, "ws-server", session.ServerInfo.Name)
+	assert.Equal(t, "2.1.0", session.ServerInfo.Version)
+	assert.Equal(t, MCPProtocolVersion, session.ProtocolVersion)
+	assert.Equal(t, "Bearer secret", backend.authHeader.Load())
+}
+
+func TestWebSocketClient_RPCError(t *testing.T) {
+	backend := newWebSocketBackend(t, echoTools)
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+	_, err := client.Call(context.Background(), server, "fail", nil)
+
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32000, rpcErr.Code)
+}
+
+func TestWebSocketClient_ServerNotificationsAndRequests(t *testing.T) {
+	backend := newWebSocketBackend(t, func(peer *wsPeer, msg wsTestMessage) {
+		if msg.Method != "tools/list" {
+			return
+		}
+		peer.send(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)
+		peer.send(`{"jsonrpc":"2.0","id":"srv-1","method":"ping"}`)
+		peer.send(`{"jsonrpc":"2.0","id":"srv-2","method":"sampling/createMessage","params":{}}`)
+		peer.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}`, msg.ID))
+	})
+	client := newTestWebSocketClient(t)
+	notified := make(chan string, 1)
+	client.OnNotification(func(serverID, method string, msg json.RawMessage) {
+		notified <- serverID + " " + method
+	})
+	server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+	_, err := client.Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+
+	select {
+	case got := <-notified:
+		assert.Equal(t, "ws-1 notifications/tools/list_changed", got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification was not delivered")
+	}
+
+	// The server's requests are answered: ping with an empty result, others with an error
+	require.Eventually(t, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		var answered int
+		for _, msg := range backend.received {
+			switch string(msg.ID) {
+			case `"srv-1"`:
+				answered++
+				assert.JSONEq(t, `{}`, string(msg.Result))
+			case `"srv-2"`:
+				answered++
+				assert.Empty(t, msg.Result)
+			}
+		}
+		return answered == 2
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketClient_ReconnectsAfterDrop(t *testing.T) {
+	backend := newWebSocketBackend(t, echoTools)
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+	_, err := client.Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+
+	backend.drop()
+
+	// Reconnected in the background, without waiting for a call
+	require.Eventually(t, func() bool {
+		return backend.initializes.Load() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = client.Call(context.Background(), server, "tools/list", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.connections.Load())
+}
+
+func TestWebSocketClient_KeepAlive(t *testing.T) {
+	newClient := func(t *testing.T) *WebSocketClient {
+		client := newTestWebSocketClient(t)
+		client.SetKeepAlive(20*time.Millisecond, 100*time.Millisecond)
+		return client
+	}
+
+	t.Run("answered pings keep the connection", func(t *testing.T) {
+		backend := newWebSocketBackend(t, echoTools)
+		client := newClient(t)
+		server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, int32(1), backend.connections.Load())
+	})
+
+	t.Run("reconnects after the pong timeout", func(t *testing.T) {
+		backend := newWebSocketBackend(t, echoTools)
+		backend.ignorePings.Store(true)
+		client := newClient(t)
+		server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		require.NoError(t, err)
+
+		// The backend stays connected but stops answering pings
+		require.Eventually(t, func() bool {
+			return backend.initializes.Load() >= 2
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.GreaterOrEqual(t, backend.connections.Load(), int32(2))
+	})
+}
+
+func TestWebSocketClient_TerminateSession(t *testing.T) {
+	backend := newWebSocketBackend(t, func(peer *wsPeer, msg wsTestMessage) {
+		// Never answers
+	})
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{ID: "ws-1", URL: backend.URL}
+
+	_, err := client.Initialize(context.Background(), server)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Call(context.Background(), server, "tools/list", nil)
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		return len(backend.methods()) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, client.TerminateSession(context.Background(), server))
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrWebSocketClosed, "waiting calls fail")
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not fail after terminate")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), backend.connections.Load(), "a terminated session isn't reconnected")
+}
+
+func TestWebSocketClient_DialFailure(t *testing.T) {
+	client := newTestWebSocketClient(t)
+	server := &domain.MCPServer{ID: "ws-1", URL: "ws://127.0.0.1:1/mcp"}
+
+	_, err := client.Call(context.Background(), server, "tools/list", nil)
+
+	require.Error(t, err)
+	assert.Equal(t, FailureConnect, ClassifyFailure(err))
+}
+
+func TestService_CallWebSocket(t *testing.T) {
+	backend := newWebSocketBackend(t, echoTools)
+	repo := multiServerRepository{
+		"ws-1": {ID: "ws-1", URL: backend.URL, IsActive: true},
+	}
+	svc := NewService(repo, logger.NewNopLogger(), nil)
+	t.Cleanup(svc.webSocketClient.(*WebSocketClient).Close)
+
+	transport, _, err := svc.GetTransportType(context.Background(), "ws-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransportWebSocket, transport, "detected from the ws:// scheme")
+
+	result, err := svc.CallWebSocket(context.Background(), "ws-1", "tools/list", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tools":[{"name":"echo"}]}`, string(result))
+	found, cached := svc.tools.Lookup("ws-1", "echo")
+	assert.True(t, found && cached, "tools list is cached")
+}
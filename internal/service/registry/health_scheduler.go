@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+// defaultHealthCheckInterval is used for servers without a HealthCheckInterval
+const defaultHealthCheckInterval = 60 * time.Second
+
+// HealthSchedulerConfig controls the background health check scheduler
+type HealthSchedulerConfig struct {
+	// TickInterval is how often the scheduler looks for servers that are due
+	TickInterval time.Duration
+	// Workers bounds how many health checks run concurrently
+	Workers int
+}
+
+// HealthScheduler periodically runs health checks against all active servers,
+// honoring each server's HealthCheckInterval
+type HealthScheduler struct {
+	service *Service
+	config  HealthSchedulerConfig
+	logger  logger.Logger
+	metrics *metrics.Registry
+	now     func() time.Time
+
+	mu          sync.Mutex
+	lastChecked map[string]time.Time
+}
+
+// NewHealthScheduler creates a new health check scheduler. metricsReg may be nil.
+func NewHealthScheduler(service *Service, cfg HealthSchedulerConfig, log logger.Logger, metricsReg *metrics.Registry) *HealthScheduler {
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = 10 * time.Second
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	return &HealthScheduler{
+		service:     service,
+		config:      cfg,
+		logger:      log,
+		metrics:     metricsReg,
+		now:         time.Now,
+		lastChecked: make(map[string]time.Time),
+	}
+}
+
+// Run checks due servers on every tick until ctx is cancelled
+func (h *HealthScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.config.TickInterval)
+	defer ticker.Stop()
+
+	// Check immediately on startup
+	h.RunOnce(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			h.RunOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce checks every active server whose interval has elapsed and waits for the checks to finish
+func (h *HealthScheduler) RunOnce(ctx context.Context) {
+	active := true
+	servers, err := h.service.repo.List(ctx, &domain.ServerFilter{IsActive: &active})
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Failed to list servers for scheduled health checks")
+		return
+	}
+
+	due := h.dueServers(servers)
+	if len(due) == 0 {
+		return
+	}
+
+	jobs := make(chan *domain.MCPServer)
+	var wg sync.WaitGroup
+	for i := 0; i < h.config.Workers && i < len(due); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for server := range jobs {
+				h.check(ctx, server)
+			}
+		}()
+	}
+
+	for _, server := range due {
+		select {
+		case jobs <- server:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	h.logger.Debug().Int("checked", len(due)).Int("active", len(servers)).Msg("Scheduled health checks completed")
+}
+
+// dueServers returns the servers whose health check interval has elapsed and marks them as checked
+func (h *HealthScheduler) dueServers(servers []*domain.MCPServer) []*domain.MCPServer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	seen := make(map[string]struct{}, len(servers))
+	due := make([]*domain.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		seen[server.ID] = struct{}{}
+
+		interval := time.Duration(server.HealthCheckInterval) * time.Second
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+		if last, ok := h.lastChecked[server.ID]; ok && now.Sub(last) < interval {
+			continue
+		}
+		h.lastChecked[server.ID] = now
+		due = append(due, server)
+	}
+
+	// Forget servers that were deleted or deactivated
+	for id := range h.lastChecked {
+		if _, ok := seen[id]; !ok {
+			delete(h.lastChecked, id)
+		}
+	}
+
+	return due
+}
+
+// check runs and stores a single scheduled health check
+func (h *HealthScheduler) check(ctx context.Context, server *domain.MCPServer) {
+	health, err := h.service.runHealthCheck(ctx, server.ID)
+	if err != nil {
+		h.logger.Warn().
+			Err(err).
+			Str("server_id", server.ID).
+			Str("server_name", server.Name).
+			Msg("Scheduled health check failed")
+		return
+	}
+
+	if h.metrics != nil {
+		h.metrics.RecordMCPServerHealthCheck(server.ID, server.Name, string(health.Status), health.ResponseTimeMs)
+	}
+}
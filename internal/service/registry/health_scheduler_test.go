@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+	"github.com/waffles/waffles/pkg/logger"
+)
+
+func newSchedulerTestServer(id, url string, active bool) *domain.MCPServer {
+	return &domain.MCPServer{
+		ID:                  id,
+		Name:                id,
+		URL:                 url,
+		Transport:           domain.TransportHTTP,
+		HealthCheckInterval: 60,
+		TimeoutSeconds:      5,
+		IsActive:            active,
+	}
+}
+
+func TestHealthScheduler_RunOnce(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["active"] = newSchedulerTestServer("active", ts.URL, true)
+	mockRepo.servers["inactive"] = newSchedulerTestServer("inactive", ts.URL, false)
+
+	log := logger.NewNopLogger()
+	scheduler := NewHealthScheduler(NewService(mockRepo, log), HealthSchedulerConfig{Workers: 2}, log, nil)
+
+	now := time.Now()
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.RunOnce(context.Background())
+	assert.Equal(t, int32(1), hits.Load(), "only active servers are checked")
+	require.Contains(t, mockRepo.healthRecords, "active")
+	assert.Equal(t, domain.ServerStatusHealthy, mockRepo.healthRecords["active"].Status)
+	assert.NotContains(t, mockRepo.healthRecords, "inactive")
+
+	t.Run("skips servers whose interval has not elapsed", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		scheduler.RunOnce(context.Background())
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("checks again once the interval elapses", func(t *testing.T) {
+		now = now.Add(31 * time.Second)
+		scheduler.RunOnce(context.Background())
+		assert.Equal(t, int32(2), hits.Load())
+	})
+}
+
+func TestHealthScheduler_RecordsMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = newSchedulerTestServer("server-1", ts.URL, true)
+
+	log := logger.NewNopLogger()
+	reg := metrics.NewRegistry()
+	scheduler := NewHealthScheduler(NewService(mockRepo, log), HealthSchedulerConfig{}, log, reg)
+	scheduler.RunOnce(context.Background())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(reg.MCPServerHealthChecksTotal.WithLabelValues("server-1", "unhealthy")))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg.MCPServerResponseTime))
+}
+
+func TestHealthScheduler_BoundsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("server-%d", i)
+		mockRepo.servers[id] = newSchedulerTestServer(id, ts.URL, true)
+	}
+
+	log := logger.NewNopLogger()
+	scheduler := NewHealthScheduler(NewService(mockRepo, log), HealthSchedulerConfig{Workers: 2}, log, nil)
+	scheduler.RunOnce(context.Background())
+
+	assert.Len(t, mockRepo.healthRecords, 6)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestHealthScheduler_ListError(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.listErr = fmt.Errorf("database unavailable")
+
+	log := logger.NewNopLogger()
+	scheduler := NewHealthScheduler(NewService(mockRepo, log), HealthSchedulerConfig{}, log, nil)
+
+	assert.NotPanics(t, func() { scheduler.RunOnce(context.Background()) })
+	assert.Empty(t, mockRepo.healthRecords)
+}
+
+func TestHealthScheduler_RunStopsOnCancel(t *testing.T) {
+	log := logger.NewNopLogger()
+	scheduler := NewHealthScheduler(NewService(newMockRepository(), log), HealthSchedulerConfig{TickInterval: time.Millisecond}, log, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+}
+
+func TestNewHealthScheduler_Defaults(t *testing.T) {
+	scheduler := NewHealthScheduler(nil, HealthSchedulerConfig{}, logger.NewNopLogger(), nil)
+
+	assert.Equal(t, 10*time.Second, scheduler.config.TickInterval)
+	assert.Equal(t, 4, scheduler.config.Workers)
+}
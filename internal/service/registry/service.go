@@ -4,34 +4,140 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
-	"github.com/waffles/waffles/internal/repository"
+	"github.com/waffles/waffles/internal/service/gateway"
+	"github.com/waffles/waffles/internal/tracing"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
+// ServerRepository defines the server persistence operations used by the registry service.
+// This allows for easier testing with mock implementations.
+type ServerRepository interface {
+	Create(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error)
+	CreateBatch(ctx context.Context, reqs []*domain.ServerCreate) ([]*domain.MCPServer, error)
+	List(ctx context.Context, filter *domain.ServerFilter) ([]*domain.MCPServer, error)
+	ListForUser(ctx context.Context, filter *domain.ServerFilter, accessibleServerIDs []string) ([]*domain.MCPServer, error)
+	Get(ctx context.Context, id string) (*domain.MCPServer, error)
+	Update(ctx context.Context, id string, req *domain.ServerUpdate) (*domain.MCPServer, error)
+	Delete(ctx context.Context, id string) error
+	CountActive(ctx context.Context) (int, error)
+	GetHealthStatus(ctx context.Context, serverID string) (*domain.ServerHealth, error)
+	SaveHealthStatus(ctx context.Context, health *domain.ServerHealth) error
+	SaveHealthEvent(ctx context.Context, event *domain.ServerHealthEvent) error
+	ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error)
+	SetSystemTags(ctx context.Context, id string, tags []string) error
+}
+
+// MCPClient defines the MCP operations used for protocol-level health checks.
+type MCPClient interface {
+	Initialize(ctx context.Context, server *domain.MCPServer) (*gateway.MCPSession, error)
+	Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error)
+	TerminateSession(ctx context.Context, server *domain.MCPServer) error
+	ForgetSession(serverID string)
+}
+
+// SSECaller defines the JSON-RPC call used for health checks on legacy SSE servers.
+type SSECaller interface {
+	Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error)
+}
+
+// BreakerResetter resets a server's circuit breaker to closed.
+type BreakerResetter interface {
+	ResetBreaker(serverID string) bool
+}
+
+// HealthTransitionFunc is called after a server's health status changes
+type HealthTransitionFunc func(event *domain.ServerHealthEvent)
+
 // Service handles MCP server registry business logic
 type Service struct {
-	repo   *repository.ServerRepository
-	logger logger.Logger
+	repo      ServerRepository
+	mcpClient MCPClient
+	sseClient SSECaller
+	breakers  BreakerResetter // Reset on successful manual health checks (nil = disabled)
+	logger    logger.Logger
+
+	httpHealthChecksOnly bool                  // Never use the MCP handshake health check
+	maxActiveServers     int                   // Most active servers allowed (0 = unlimited)
+	loopGuard            *gateway.LoopGuard    // Rejects server URLs that point back at the gateway (nil = disabled)
+	addressGuard         *gateway.AddressGuard // Rejects server URLs at internal addresses (nil = disabled)
+	egress               *gateway.EgressPolicy // Routes every upstream connection (nil = direct)
+	maxResponseBytes     int64                 // Largest tool call response read (0 = unlimited)
+	autoTag              bool                  // Set system tags from capabilities found by MCP health checks
+
+	healthMu    sync.Mutex // Serializes status comparison and persistence so transitions aren't duplicated
+	subsMu      sync.RWMutex
+	transitions []HealthTransitionFunc
 }
 
 // NewService creates a new registry service
-func NewService(repo *repository.ServerRepository, log logger.Logger) *Service {
+func NewService(repo ServerRepository, log logger.Logger) *Service {
 	return &Service{
-		repo:   repo,
-		logger: log,
+		repo:      repo,
+		mcpClient: gateway.NewStreamableHTTPClient(log, 30*time.Second),
+		sseClient: gateway.NewSSEClient(log, 30*time.Second),
+		logger:    log,
+
+		maxResponseBytes: gateway.DefaultMaxResponseBytes,
 	}
 }
 
+// NewServiceWithBreakers creates a registry service that closes a server's
+// circuit breaker when a manual health check succeeds
+func NewServiceWithBreakers(repo ServerRepository, log logger.Logger, breakers BreakerResetter) *Service {
+	s := NewService(repo, log)
+	s.breakers = breakers
+	return s
+}
+
+// NewServiceWithConfig creates a registry service using health check configuration.
+// breakers may be nil to disable breaker reset on manual health checks.
+func NewServiceWithConfig(repo ServerRepository, log logger.Logger, breakers BreakerResetter, cfg config.HealthCheckConfig) *Service {
+	s := NewService(repo, log)
+	s.breakers = breakers
+	s.httpHealthChecksOnly = cfg.Mode == config.HealthCheckModeHTTP
+	s.autoTag = cfg.AutoTag
+	return s
+}
+
 // CreateServer registers a new MCP server
 func (s *Service) CreateServer(ctx context.Context, req *domain.ServerCreate) (*domain.MCPServer, error) {
-	// Set defaults if not provided
+	applyServerDefaults(req)
+
+	if err := s.checkServerURLs(ctx, req.URL, req.HealthCheckURL); err != nil {
+		return nil, err
+	}
+	if err := s.checkServerLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	// Create server in database
+	server, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.serverRegistered(server)
+	return server, nil
+}
+
+// applyServerDefaults fills in the settings a new server request left out
+func applyServerDefaults(req *domain.ServerCreate) {
 	if req.ProtocolVersion == "" {
 		req.ProtocolVersion = "1.0.0"
 	}
@@ -44,13 +150,10 @@ func (s *Service) CreateServer(ctx context.Context, req *domain.ServerCreate) (*
 	if req.MaxConnections == 0 {
 		req.MaxConnections = 100 // Default: 100 connections
 	}
+}
 
-	// Create server in database
-	server, err := s.repo.Create(ctx, req)
-	if err != nil {
-		return nil, err
-	}
-
+// serverRegistered logs a new server and starts its initial health check
+func (s *Service) serverRegistered(server *domain.MCPServer) {
 	s.logger.Info().
 		Str("server_id", server.ID).
 		Str("name", server.Name).
@@ -60,12 +163,113 @@ func (s *Service) CreateServer(ctx context.Context, req *domain.ServerCreate) (*
 	go func() {
 		healthCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		if err := s.CheckHealth(healthCtx, server.ID); err != nil {
+		if _, err := s.runHealthCheck(healthCtx, server.ID); err != nil {
 			s.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Initial health check failed")
 		}
 	}()
+}
 
-	return server, nil
+// ImportServers registers several servers at once. Each request gets CreateServer's
+// defaults and checks, plus validate when it is not nil. Without continueOnError the
+// import is all-or-nothing: one invalid request, or a failed insert, creates none of
+// them. With it, each server is created on its own and failures are reported per item.
+func (s *Service) ImportServers(ctx context.Context, reqs []*domain.ServerCreate, validate func(*domain.ServerCreate) error, continueOnError bool) *domain.ServerImportSummary {
+	summary := &domain.ServerImportSummary{Results: make([]domain.ServerImportResult, len(reqs))}
+	invalid := make([]bool, len(reqs))
+	for i, req := range reqs {
+		summary.Results[i] = domain.ServerImportResult{Index: i, Name: req.Name}
+		applyServerDefaults(req)
+
+		var err error
+		if validate != nil {
+			err = validate(req)
+		}
+		if err == nil {
+			err = s.checkServerURLs(ctx, req.URL, req.HealthCheckURL)
+		}
+		if err != nil {
+			summary.Results[i].Error = err.Error()
+			summary.Failed++
+			invalid[i] = true
+		}
+	}
+
+	if continueOnError {
+		for i, req := range reqs {
+			if invalid[i] {
+				continue
+			}
+			server, err := s.CreateServer(ctx, req)
+			if err != nil {
+				summary.Results[i].Error = s.importError(req, err)
+				summary.Failed++
+				continue
+			}
+			summary.Results[i].Server = server
+			summary.Created++
+		}
+		return summary
+	}
+
+	if summary.Failed > 0 {
+		return failImport(summary, "not imported: another server in the import is invalid")
+	}
+	if err := s.checkImportLimit(ctx, len(reqs)); err != nil {
+		return failImport(summary, s.importError(nil, err))
+	}
+	servers, err := s.repo.CreateBatch(ctx, reqs)
+	if err != nil {
+		s.logger.Error().Err(err).Int("servers", len(reqs)).Msg("Server import rolled back")
+		return failImport(summary, "not imported: the import was rolled back after a server failed to save")
+	}
+	for i, server := range servers {
+		summary.Results[i].Server = server
+		summary.Created++
+		s.serverRegistered(server)
+	}
+	return summary
+}
+
+// checkImportLimit returns domain.ErrServerLimitReached when adding n active servers
+// would exceed the cap
+func (s *Service) checkImportLimit(ctx context.Context, n int) error {
+	if s.maxActiveServers <= 0 {
+		return nil
+	}
+	count, err := s.repo.CountActive(ctx)
+	if err != nil {
+		return err
+	}
+	if count+n > s.maxActiveServers {
+		return fmt.Errorf("%w: %d of %d, importing %d", domain.ErrServerLimitReached, count, s.maxActiveServers, n)
+	}
+	return nil
+}
+
+// importError describes why a server wasn't imported. Storage errors are logged rather
+// than returned, as CreateServer's handler does.
+func (s *Service) importError(req *domain.ServerCreate, err error) string {
+	if errors.Is(err, domain.ErrServerLimitReached) || errors.Is(err, domain.ErrServerURLIsGateway) || errors.Is(err, domain.ErrServerURLBlocked) {
+		return err.Error()
+	}
+	event := s.logger.Error().Err(err)
+	if req != nil {
+		event = event.Str("name", req.Name)
+	}
+	event.Msg("Failed to import server")
+	return "failed to create server"
+}
+
+// failImport marks every server of an all-or-nothing import that has no error of its own
+// with reason
+func failImport(summary *domain.ServerImportSummary, reason string) *domain.ServerImportSummary {
+	for i := range summary.Results {
+		if summary.Results[i].Error == "" {
+			summary.Results[i].Error = reason
+			summary.Failed++
+		}
+	}
+	return summary
 }
 
 // ListServers retrieves all MCP servers with filtering
@@ -116,6 +320,28 @@ func (s *Service) GetServer(ctx context.Context, id string) (*domain.MCPServer,
 
 // UpdateServer updates an existing MCP server
 func (s *Service) UpdateServer(ctx context.Context, id string, req *domain.ServerUpdate) (*domain.MCPServer, error) {
+	if req.URL != nil {
+		if err := s.checkServerURL(ctx, *req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.HealthCheckURL != nil && *req.HealthCheckURL != "" {
+		if err := s.checkServerURL(ctx, *req.HealthCheckURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.AuthConfig != nil && bytes.Contains(req.AuthConfig, []byte(`"`+domain.RedactedValue+`"`)) {
+		// An auth config read from the API has its credentials redacted; keep the stored ones
+		current, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		restored := *req
+		restored.AuthConfig = domain.RestoreRedactedAuthConfig(req.AuthConfig, current.AuthConfig)
+		req = &restored
+	}
+
 	server, err := s.repo.Update(ctx, id, req)
 	if err != nil {
 		return nil, err
@@ -129,6 +355,125 @@ func (s *Service) UpdateServer(ctx context.Context, id string, req *domain.Serve
 	return server, nil
 }
 
+// SetMaxActiveServers caps the number of active servers; creating or enabling a server
+// beyond it fails with domain.ErrServerLimitReached. Zero means unlimited.
+func (s *Service) SetMaxActiveServers(limit int) {
+	s.maxActiveServers = limit
+}
+
+// SetMaxResponseBytes caps the tool call responses read when inspecting a server, so a
+// large base64 image can't exhaust memory. Zero means unlimited.
+func (s *Service) SetMaxResponseBytes(limit int64) {
+	s.maxResponseBytes = limit
+}
+
+// SetSessionLimits bounds the MCP sessions kept with servers for health checks, as
+// gateway.StreamableHTTPClient.SetSessionLimits does. Must be called before the service
+// is used.
+func (s *Service) SetSessionLimits(idleTTL time.Duration, maxSessions int) {
+	if client, ok := s.mcpClient.(*gateway.StreamableHTTPClient); ok {
+		client.SetSessionLimits(idleTTL, maxSessions)
+	}
+}
+
+// SetLoopGuard rejects creating or updating servers whose URL is one of the gateway's
+// own addresses, which would make the gateway proxy to itself
+func (s *Service) SetLoopGuard(guard *gateway.LoopGuard) {
+	s.loopGuard = guard
+}
+
+// SetAddressGuard rejects creating or updating servers whose URL is, or resolves to, an
+// internal address the guard blocks. Upstream connections are checked by the guard too,
+// when they are dialed, so a name rebound to an internal address after the URL check
+// still can't be reached. An egress policy set earlier is expected to carry the guard.
+func (s *Service) SetAddressGuard(guard *gateway.AddressGuard) {
+	s.addressGuard = guard
+	if s.egress == nil {
+		policy, err := gateway.NewEgressPolicy("", nil)
+		if err != nil {
+			return // Can't happen without a proxy URL
+		}
+		policy.SetAddressGuard(guard)
+		s.SetEgressPolicy(policy)
+	}
+}
+
+// SetEgressPolicy routes the health checks, connection tests and tool calls the service
+// sends upstream through policy, which should carry the address guard if there is one.
+// Must be called before the service is used.
+func (s *Service) SetEgressPolicy(policy *gateway.EgressPolicy) {
+	s.egress = policy
+	if client, ok := s.mcpClient.(*gateway.StreamableHTTPClient); ok {
+		client.SetEgressPolicy(policy)
+	}
+	if client, ok := s.sseClient.(*gateway.SSEClient); ok {
+		client.SetEgressPolicy(policy)
+	}
+}
+
+// newHTTPClient returns a client for one-off upstream requests that connects through the
+// egress policy, if there is one
+func (s *Service) newHTTPClient(timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if s.egress != nil {
+		client.Transport = s.egress.Transport()
+	}
+	return client
+}
+
+// SetSecretStore resolves secret references in server auth configs through secrets for
+// health checks and auth tests. Must be called before the service is used.
+func (s *Service) SetSecretStore(secrets *gateway.SecretStore) {
+	if client, ok := s.mcpClient.(*gateway.StreamableHTTPClient); ok {
+		client.SetSecretStore(secrets)
+	}
+	if client, ok := s.sseClient.(*gateway.SSEClient); ok {
+		client.SetSecretStore(secrets)
+	}
+}
+
+// checkServerURL returns domain.ErrServerURLIsGateway when serverURL points back at the
+// gateway, and domain.ErrServerURLBlocked when it points at a blocked internal address
+func (s *Service) checkServerURL(ctx context.Context, serverURL string) error {
+	if s.loopGuard != nil && s.loopGuard.IsSelf(serverURL) {
+		return fmt.Errorf("%w: %s", domain.ErrServerURLIsGateway, serverURL)
+	}
+	if s.addressGuard != nil {
+		if err := s.addressGuard.CheckURL(ctx, serverURL); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrServerURLBlocked, err)
+		}
+	}
+	return nil
+}
+
+// checkServerURLs checks the URL of a server being registered and, when it has one, the
+// health check URL the scheduler polls
+func (s *Service) checkServerURLs(ctx context.Context, serverURL, healthCheckURL string) error {
+	if err := s.checkServerURL(ctx, serverURL); err != nil {
+		return err
+	}
+	if healthCheckURL != "" {
+		return s.checkServerURL(ctx, healthCheckURL)
+	}
+	return nil
+}
+
+// checkServerLimit returns domain.ErrServerLimitReached when one more active server would
+// exceed the cap. Concurrent creates may overshoot it by the number racing.
+func (s *Service) checkServerLimit(ctx context.Context) error {
+	if s.maxActiveServers <= 0 {
+		return nil
+	}
+	count, err := s.repo.CountActive(ctx)
+	if err != nil {
+		return err
+	}
+	if count >= s.maxActiveServers {
+		return fmt.Errorf("%w: %d of %d", domain.ErrServerLimitReached, count, s.maxActiveServers)
+	}
+	return nil
+}
+
 // DeleteServer deletes an MCP server by ID
 func (s *Service) DeleteServer(ctx context.Context, id string) error {
 	err := s.repo.Delete(ctx, id)
@@ -142,6 +487,18 @@ func (s *Service) DeleteServer(ctx context.Context, id string) error {
 
 // ToggleServer enables/disables an MCP server
 func (s *Service) ToggleServer(ctx context.Context, id string, enabled bool) (*domain.MCPServer, error) {
+	if enabled {
+		current, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !current.IsActive {
+			if err := s.checkServerLimit(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	update := &domain.ServerUpdate{
 		IsActive: &enabled,
 	}
@@ -165,12 +522,32 @@ func (s *Service) ToggleServer(ctx context.Context, id string, enabled bool) (*d
 	return server, nil
 }
 
-// CheckHealth performs a health check on an MCP server
+// CheckHealth performs a manually triggered health check on an MCP server.
+// If the check succeeds and breaker reset is enabled, the server's circuit
+// breaker is closed so traffic recovers immediately.
 func (s *Service) CheckHealth(ctx context.Context, serverID string) error {
+	health, err := s.runHealthCheck(ctx, serverID)
+	if err != nil {
+		return err
+	}
+
+	if s.breakers != nil && health.Status == domain.ServerStatusHealthy {
+		if s.breakers.ResetBreaker(serverID) {
+			s.logger.Info().
+				Str("server_id", serverID).
+				Msg("Circuit breaker closed after successful manual health check")
+		}
+	}
+
+	return nil
+}
+
+// runHealthCheck probes an MCP server and saves the result
+func (s *Service) runHealthCheck(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
 	// Get server details
 	server, err := s.repo.Get(ctx, serverID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Determine health check URL
@@ -180,12 +557,36 @@ func (s *Service) CheckHealth(ctx context.Context, serverID string) error {
 		healthURL = server.URL + "/health"
 	}
 
+	ctx, span := tracing.Tracer().Start(ctx, "registry.HealthCheck", trace.WithAttributes(
+		tracing.AttrServerID.String(server.ID),
+		tracing.AttrTransport.String(string(server.Transport)),
+	))
+	defer span.End()
+
 	// Perform health check with timeout
 	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(server.TimeoutSeconds)*time.Second)
 	defer cancel()
 
 	start := time.Now()
-	status, responseTimeMs, errorMsg := s.performHealthCheck(checkCtx, healthURL)
+	var result mcpHealthResult
+	mode := domain.HealthCheckModeHTTP
+	if s.useMCPHealthCheck(server) {
+		mode = domain.HealthCheckModeMCP
+		result = s.performMCPHealthCheck(checkCtx, server)
+		if s.autoTag && result.status != domain.ServerStatusUnhealthy {
+			s.updateSystemTags(ctx, server, result.capabilities)
+		}
+	} else {
+		result.status, result.responseTimeMs, result.errorMsg = s.performHealthCheck(checkCtx, healthURL)
+	}
+	status, responseTimeMs, errorMsg, serverVersion := result.status, result.responseTimeMs, result.errorMsg, result.serverVersion
+	span.SetAttributes(
+		attribute.String("health_check.mode", string(mode)),
+		attribute.String("health_check.status", string(status)),
+	)
+	if status == domain.ServerStatusUnhealthy {
+		span.SetStatus(codes.Error, errorMsg)
+	}
 	if responseTimeMs == 0 {
 		responseTimeMs = int(time.Since(start).Milliseconds())
 	}
@@ -196,23 +597,307 @@ func (s *Service) CheckHealth(ctx context.Context, serverID string) error {
 		Status:         status,
 		ResponseTimeMs: responseTimeMs,
 		ErrorMessage:   errorMsg,
+		ServerVersion:  serverVersion,
+		CheckMode:      mode,
 		CheckedAt:      time.Now(),
 	}
 
-	if err := s.repo.SaveHealthStatus(ctx, health); err != nil {
-		s.logger.Error().Err(err).Str("server_id", serverID).Msg("Failed to save health status")
-		return err
+	if err := s.saveHealth(ctx, health); err != nil {
+		return nil, err
 	}
 
 	s.logger.Debug().
 		Str("server_id", serverID).
 		Str("status", string(status)).
+		Str("mode", string(mode)).
 		Int("response_time_ms", responseTimeMs).
 		Msg("Health check completed")
 
+	return health, nil
+}
+
+// saveHealth persists a health check result and records a transition event
+// when the status differs from the previously stored one
+func (s *Service) saveHealth(ctx context.Context, health *domain.ServerHealth) error {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	previous := domain.ServerStatusUnknown
+	if last, err := s.repo.GetHealthStatus(ctx, health.ServerID); err == nil && last != nil {
+		previous = last.Status
+	}
+
+	if err := s.repo.SaveHealthStatus(ctx, health); err != nil {
+		s.logger.Error().Err(err).Str("server_id", health.ServerID).Msg("Failed to save health status")
+		return err
+	}
+
+	if previous == health.Status {
+		return nil
+	}
+
+	event := &domain.ServerHealthEvent{
+		ServerID:       health.ServerID,
+		PreviousStatus: previous,
+		NewStatus:      health.Status,
+		ErrorMessage:   health.ErrorMessage,
+		OccurredAt:     health.CheckedAt,
+	}
+	if err := s.repo.SaveHealthEvent(ctx, event); err != nil {
+		// The health result is already stored; a missing event shouldn't fail the check
+		s.logger.Error().Err(err).Str("server_id", health.ServerID).Msg("Failed to save health event")
+	}
+
+	s.logger.Info().
+		Str("server_id", health.ServerID).
+		Str("previous_status", string(previous)).
+		Str("new_status", string(health.Status)).
+		Msg("Server health status changed")
+
+	s.notifyHealthTransition(event)
 	return nil
 }
 
+// OnHealthTransition registers a callback fired after each server health status change.
+// Callbacks run synchronously on the health check goroutine and should not block.
+func (s *Service) OnHealthTransition(fn HealthTransitionFunc) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	s.transitions = append(s.transitions, fn)
+}
+
+// notifyHealthTransition calls every registered transition callback
+func (s *Service) notifyHealthTransition(event *domain.ServerHealthEvent) {
+	s.subsMu.RLock()
+	subs := make([]HealthTransitionFunc, len(s.transitions))
+	copy(subs, s.transitions)
+	s.subsMu.RUnlock()
+
+	for _, fn := range subs {
+		fn(event)
+	}
+}
+
+// ListHealthEvents retrieves recent health status transitions for a server, newest first
+func (s *Service) ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+	if _, err := s.repo.Get(ctx, serverID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.repo.ListHealthEvents(ctx, serverID, limit)
+}
+
+// Outcomes of a backend auth test
+const (
+	AuthTestAuthenticated = "authenticated" // The server accepted the configured credentials
+	AuthTestRejected      = "rejected"      // The server answered 401 or 403
+	AuthTestError         = "error"         // The test failed for another reason, so auth is unverified
+)
+
+// AuthTestResult reports whether a server accepted its configured backend auth
+type AuthTestResult struct {
+	ServerID       string `json:"server_id"`
+	AuthType       string `json:"auth_type"`
+	Result         string `json:"result"` // authenticated, rejected or error
+	Authenticated  bool   `json:"authenticated"`
+	StatusCode     int    `json:"status_code,omitempty"` // Status the server answered with when it failed the request
+	Error          string `json:"error,omitempty"`
+	ResponseTimeMs int    `json:"response_time_ms"`
+}
+
+// TestAuth sends an MCP initialize to the server with its configured auth and reports
+// whether the server accepted it. A 401 or 403 answer is reported as rejected; any other
+// failure leaves auth unverified. No tool is called and the session is closed afterwards.
+// Returns domain.ErrAuthTestUnsupported for transports other than Streamable HTTP and SSE.
+func (s *Service) TestAuth(ctx context.Context, serverID string) (*AuthTestResult, error) {
+	server, err := s.repo.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if server.Transport != domain.TransportStreamableHTTP && server.Transport != domain.TransportSSE {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAuthTestUnsupported, server.Transport)
+	}
+
+	authType := server.AuthType
+	if authType == "" {
+		authType = domain.ServerAuthNone
+	}
+	result := &AuthTestResult{ServerID: server.ID, AuthType: string(authType)}
+
+	timeout := time.Duration(server.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if server.Transport == domain.TransportSSE {
+		_, err = s.sseClient.Call(testCtx, server, "initialize", gateway.InitializeParams{
+			ProtocolVersion: gateway.MCPProtocolVersion,
+			ClientInfo:      gateway.ClientInfo{Name: "waffles", Version: "1.0.0"},
+		})
+	} else {
+		_, err = s.mcpClient.Initialize(testCtx, server)
+		if err == nil {
+			if termErr := s.mcpClient.TerminateSession(ctx, server); termErr != nil {
+				s.logger.Debug().Err(termErr).Str("server_id", server.ID).Msg("Failed to terminate auth test session")
+			}
+		}
+	}
+	result.ResponseTimeMs = int(time.Since(start).Milliseconds())
+
+	var statusErr *gateway.StatusError
+	switch {
+	case err == nil:
+		result.Result, result.Authenticated = AuthTestAuthenticated, true
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden):
+		result.Result, result.StatusCode, result.Error = AuthTestRejected, statusErr.StatusCode, err.Error()
+	default:
+		result.Result, result.Error = AuthTestError, err.Error()
+		if errors.As(err, &statusErr) {
+			result.StatusCode = statusErr.StatusCode
+		}
+	}
+
+	s.logger.Info().
+		Str("server_id", server.ID).
+		Str("auth_type", result.AuthType).
+		Str("result", result.Result).
+		Msg("Backend auth test completed")
+	return result, nil
+}
+
+// useMCPHealthCheck reports whether a server should be checked with an MCP initialize
+// handshake instead of an HTTP GET. Servers with a dedicated health check URL keep the
+// cheap HTTP GET; MCP transports without one are probed over the protocol itself.
+func (s *Service) useMCPHealthCheck(server *domain.MCPServer) bool {
+	if s.httpHealthChecksOnly || server.HealthCheckURL != "" {
+		return false
+	}
+	switch server.Transport {
+	case domain.TransportStreamableHTTP:
+		return s.mcpClient != nil
+	case domain.TransportSSE:
+		return s.sseClient != nil
+	default:
+		return false
+	}
+}
+
+// mcpHealthResult is the outcome of an MCP health check
+type mcpHealthResult struct {
+	status         domain.ServerStatus
+	responseTimeMs int // Initialize round trip time
+	errorMsg       string
+	serverVersion  string          // Reported in serverInfo
+	capabilities   json.RawMessage // Advertised in initialize
+}
+
+// performMCPHealthCheck runs an MCP initialize handshake against the server followed by
+// tools/list. A server that initializes but cannot list tools is degraded.
+func (s *Service) performMCPHealthCheck(ctx context.Context, server *domain.MCPServer) mcpHealthResult {
+	if server.Transport == domain.TransportSSE {
+		return s.performSSEHealthCheck(ctx, server)
+	}
+
+	start := time.Now()
+	session, err := s.mcpClient.Initialize(ctx, server)
+	responseTimeMs := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return mcpHealthResult{status: domain.ServerStatusUnhealthy, responseTimeMs: responseTimeMs, errorMsg: fmt.Sprintf("MCP initialize failed: %v", err)}
+	}
+
+	result := mcpHealthResult{
+		status:         domain.ServerStatusHealthy,
+		responseTimeMs: responseTimeMs,
+		serverVersion:  session.ServerInfo.Version,
+		capabilities:   session.Capabilities,
+	}
+	if _, err := s.mcpClient.Call(ctx, server, "tools/list", nil); err != nil {
+		result.status, result.errorMsg = domain.ServerStatusDegraded, fmt.Sprintf("MCP tools/list failed: %v", err)
+	}
+
+	// Don't leave health check sessions open on the backend
+	if err := s.mcpClient.TerminateSession(ctx, server); err != nil {
+		s.logger.Debug().Err(err).Str("server_id", server.ID).Msg("Failed to terminate health check session")
+	}
+
+	return result
+}
+
+// performSSEHealthCheck runs the initialize and tools/list health check against a legacy SSE server
+func (s *Service) performSSEHealthCheck(ctx context.Context, server *domain.MCPServer) mcpHealthResult {
+	params := gateway.InitializeParams{
+		ProtocolVersion: gateway.MCPProtocolVersion,
+		ClientInfo:      gateway.ClientInfo{Name: "waffles", Version: "1.0.0"},
+	}
+
+	start := time.Now()
+	raw, err := s.sseClient.Call(ctx, server, "initialize", params)
+	responseTimeMs := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return mcpHealthResult{status: domain.ServerStatusUnhealthy, responseTimeMs: responseTimeMs, errorMsg: fmt.Sprintf("MCP initialize failed: %v", err)}
+	}
+
+	var initResult gateway.InitializeResult
+	_ = json.Unmarshal(raw, &initResult) // #nosec G104 -- version and capabilities are optional
+
+	result := mcpHealthResult{
+		status:         domain.ServerStatusHealthy,
+		responseTimeMs: responseTimeMs,
+		serverVersion:  initResult.ServerInfo.Version,
+		capabilities:   initResult.Capabilities,
+	}
+	if _, err := s.sseClient.Call(ctx, server, "tools/list", nil); err != nil {
+		result.status, result.errorMsg = domain.ServerStatusDegraded, fmt.Sprintf("MCP tools/list failed: %v", err)
+	}
+	return result
+}
+
+// SystemTagCapabilityPrefix starts the system tag of each capability a server advertises
+const SystemTagCapabilityPrefix = "has:"
+
+// CapabilityTags returns the system tags for an initialize capabilities object: "has:<name>"
+// for each capability present, sorted
+func CapabilityTags(capabilities json.RawMessage) []string {
+	var caps map[string]json.RawMessage
+	if err := json.Unmarshal(capabilities, &caps); err != nil {
+		return []string{}
+	}
+	tags := make([]string, 0, len(caps))
+	for name, value := range caps {
+		if string(value) == "null" {
+			continue
+		}
+		tags = append(tags, SystemTagCapabilityPrefix+name)
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// updateSystemTags stores the tags for the server's advertised capabilities when they
+// changed. Failures are logged; they don't fail the health check.
+func (s *Service) updateSystemTags(ctx context.Context, server *domain.MCPServer, capabilities json.RawMessage) {
+	tags := CapabilityTags(capabilities)
+	current := slices.Clone(server.SystemTags)
+	slices.Sort(current)
+	if slices.Equal(tags, current) {
+		return
+	}
+
+	if err := s.repo.SetSystemTags(ctx, server.ID, tags); err != nil {
+		s.logger.Warn().Err(err).Str("server_id", server.ID).Msg("Failed to update system tags")
+		return
+	}
+	s.logger.Info().
+		Str("server_id", server.ID).
+		Any("system_tags", tags).
+		Msg("Server system tags updated from capabilities")
+}
+
 // performHealthCheck executes the actual HTTP health check
 func (s *Service) performHealthCheck(ctx context.Context, url string) (domain.ServerStatus, int, string) {
 	start := time.Now()
@@ -221,10 +906,9 @@ func (s *Service) performHealthCheck(ctx context.Context, url string) (domain.Se
 	if err != nil {
 		return domain.ServerStatusUnhealthy, 0, fmt.Sprintf("Failed to create request: %v", err)
 	}
+	gateway.SetTraceContext(req)
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := s.newHTTPClient(30 * time.Second)
 
 	resp, err := client.Do(req)
 	responseTimeMs := int(time.Since(start).Milliseconds())
@@ -265,7 +949,7 @@ func (s *Service) GetHealthStatus(ctx context.Context, serverID string) (*domain
 // TestConnectionRequest represents a connection test request
 type TestConnectionRequest struct {
 	URL             string `json:"url"`
-	Transport       string `json:"transport"`
+	Transport       string `json:"transport"` // Empty tries each supported transport in turn
 	ProtocolVersion string `json:"protocol_version"`
 	TimeoutSeconds  int    `json:"timeout"`
 }
@@ -280,10 +964,71 @@ type TestConnectionResult struct {
 	Resources      []any  `json:"resources,omitempty"`
 	ResourceCount  int    `json:"resource_count,omitempty"`
 	ErrorMessage   string `json:"error_message,omitempty"`
+
+	// Error describes why the test could not run, for UIs to guide the user
+	Error *TestConnectionError `json:"error,omitempty"`
+
+	// Transport is the transport that was tested; when auto-detecting, the one that worked
+	Transport       string                      `json:"transport,omitempty"`
+	ProtocolVersion string                      `json:"protocol_version,omitempty"` // Agreed in initialize
+	ServerName      string                      `json:"server_name,omitempty"`
+	ServerVersion   string                      `json:"server_version,omitempty"`
+	Capabilities    *TestConnectionCapabilities `json:"capabilities,omitempty"`
+	// Attempts lists each transport tried, in order
+	Attempts []TestConnectionAttempt `json:"attempts,omitempty"`
+
+	negotiated bool // The server answered initialize with a JSON-RPC result
+}
+
+// TestConnectionCapabilities are the server capabilities advertised in initialize
+type TestConnectionCapabilities struct {
+	Tools     bool `json:"tools"`
+	Resources bool `json:"resources"`
+	Prompts   bool `json:"prompts"`
+}
+
+// TestConnectionAttempt is the outcome of testing one transport
+type TestConnectionAttempt struct {
+	Transport      string `json:"transport"`
+	Success        bool   `json:"success"`
+	ResponseTimeMs int    `json:"response_time_ms"`
+	ErrorMessage   string `json:"error_message,omitempty"`
+}
+
+// TestConnectionError is the structured form of a connection test failure
+type TestConnectionError struct {
+	Code                string   `json:"code"`
+	RequestedTransport  string   `json:"requested_transport,omitempty"`
+	SupportedTransports []string `json:"supported_transports,omitempty"`
+}
+
+// ErrorCodeUnsupportedTransport is reported when a test requests a transport it can't exercise
+const ErrorCodeUnsupportedTransport = "unsupported_transport"
+
+// SupportedTestTransports returns the transports TestConnection can exercise
+func SupportedTestTransports() []string {
+	return []string{
+		string(domain.TransportHTTP),
+		string(domain.TransportStreamableHTTP),
+		string(domain.TransportSSE),
+	}
 }
 
-// TestConnection tests connectivity to an MCP server without saving it
+// autoDetectTransports are tried in order by TestConnection when no transport is given
+var autoDetectTransports = []string{
+	string(domain.TransportStreamableHTTP),
+	string(domain.TransportSSE),
+	string(domain.TransportHTTP),
+}
+
+// TestConnection tests connectivity to an MCP server without saving it. Without a
+// transport, each of autoDetectTransports is tried until one works; Streamable HTTP and
+// SSE only count as working when the server answers initialize.
 func (s *Service) TestConnection(ctx context.Context, req *TestConnectionRequest) (*TestConnectionResult, error) {
+	if err := s.checkServerURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
 	timeout := req.TimeoutSeconds
 	if timeout <= 0 {
 		timeout = 10
@@ -293,43 +1038,109 @@ func (s *Service) TestConnection(ctx context.Context, req *TestConnectionRequest
 	defer cancel()
 
 	start := time.Now()
-	result := &TestConnectionResult{}
-
-	// Determine transport type
-	transport := req.Transport
-	if transport == "" {
-		transport = "http"
+	var result *TestConnectionResult
+	var attempts []TestConnectionAttempt
+
+	if req.Transport == "" {
+		for _, transport := range autoDetectTransports {
+			result = s.testTransport(testCtx, req, transport, &attempts)
+			if result.Success {
+				break
+			}
+		}
+		if !result.Success {
+			result.ErrorMessage = fmt.Sprintf("No transport worked (tried %s): %s", strings.Join(autoDetectTransports, ", "), result.ErrorMessage)
+		}
+	} else {
+		result = s.testTransport(testCtx, req, req.Transport, &attempts)
 	}
 
-	// Try to initialize and get tools/resources based on transport
+	result.Attempts = attempts
+	result.ResponseTimeMs = int(time.Since(start).Milliseconds())
+
+	s.logger.Info().
+		Str("url", req.URL).
+		Str("transport", result.Transport).
+		Int("attempts", len(attempts)).
+		Bool("success", result.Success).
+		Int("response_time_ms", result.ResponseTimeMs).
+		Msg("Connection test completed")
+
+	return result, nil
+}
+
+// testTransport tests one transport and records the attempt. When auto-detecting, a
+// JSON-RPC transport whose server never answered initialize is reported as failed.
+func (s *Service) testTransport(ctx context.Context, req *TestConnectionRequest, transport string, attempts *[]TestConnectionAttempt) *TestConnectionResult {
+	start := time.Now()
+	var result *TestConnectionResult
+
 	switch transport {
 	case "http":
-		result = s.testHTTPTransport(testCtx, req.URL)
+		result = s.testHTTPTransport(ctx, req.URL)
 	case "streamable_http":
-		result = s.testStreamableHTTPTransport(testCtx, req.URL, req.ProtocolVersion)
+		result = s.testStreamableHTTPTransport(ctx, req.URL, req.ProtocolVersion)
 	case "sse":
-		result = s.testSSETransport(testCtx, req.URL)
+		result = s.testSSETransport(ctx, req.URL)
 	default:
-		result.Success = false
-		result.ErrorMessage = fmt.Sprintf("Unsupported transport type: %s", transport)
+		supported := SupportedTestTransports()
+		result = &TestConnectionResult{
+			ErrorMessage: fmt.Sprintf("Unsupported transport type: %s (supported: %s)", transport, strings.Join(supported, ", ")),
+			Error: &TestConnectionError{
+				Code:                ErrorCodeUnsupportedTransport,
+				RequestedTransport:  transport,
+				SupportedTransports: supported,
+			},
+		}
 	}
+	result.Transport = transport
 
-	result.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if req.Transport == "" && transport != "http" && result.Success && !result.negotiated {
+		result = &TestConnectionResult{Transport: transport, ErrorMessage: "Server did not answer initialize"}
+	}
 
-	s.logger.Info().
+	attempt := TestConnectionAttempt{
+		Transport:      transport,
+		Success:        result.Success,
+		ResponseTimeMs: int(time.Since(start).Milliseconds()),
+	}
+	if !result.Success {
+		attempt.ErrorMessage = result.ErrorMessage
+	}
+	*attempts = append(*attempts, attempt)
+
+	s.logger.Debug().
 		Str("url", req.URL).
 		Str("transport", transport).
-		Bool("success", result.Success).
-		Int("response_time_ms", result.ResponseTimeMs).
-		Msg("Connection test completed")
+		Bool("success", attempt.Success).
+		Str("error", attempt.ErrorMessage).
+		Msg("Connection test attempt finished")
 
-	return result, nil
+	return result
+}
+
+// applyInitializeResult records what the server reported in its initialize result
+func (r *TestConnectionResult) applyInitializeResult(init map[string]interface{}) {
+	r.negotiated = true
+	r.ServerInfo = init["serverInfo"]
+	if info, ok := init["serverInfo"].(map[string]interface{}); ok {
+		r.ServerName, _ = info["name"].(string)
+		r.ServerVersion, _ = info["version"].(string)
+	}
+	r.ProtocolVersion, _ = init["protocolVersion"].(string)
+	if caps, ok := init["capabilities"].(map[string]interface{}); ok {
+		r.Capabilities = &TestConnectionCapabilities{
+			Tools:     caps["tools"] != nil,
+			Resources: caps["resources"] != nil,
+			Prompts:   caps["prompts"] != nil,
+		}
+	}
 }
 
 // testHTTPTransport tests HTTP transport connectivity
 func (s *Service) testHTTPTransport(ctx context.Context, baseURL string) *TestConnectionResult {
 	result := &TestConnectionResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	// Try initialize endpoint
 	initURL := baseURL + "/initialize"
@@ -384,7 +1195,7 @@ func (s *Service) testHTTPTransport(ctx context.Context, baseURL string) *TestCo
 // Note: Streamable HTTP servers may return SSE format responses
 func (s *Service) testStreamableHTTPTransport(ctx context.Context, baseURL string, protocolVersion string) *TestConnectionResult {
 	result := &TestConnectionResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	if protocolVersion == "" {
 		protocolVersion = "2025-11-25"
@@ -458,7 +1269,7 @@ func (s *Service) testStreamableHTTPTransport(ctx context.Context, baseURL strin
 
 	result.Success = true
 	if rpcResult, ok := initResult["result"].(map[string]interface{}); ok {
-		result.ServerInfo = rpcResult["serverInfo"]
+		result.applyInitializeResult(rpcResult)
 	}
 
 	// Get session ID if provided (check both header name variants)
@@ -537,10 +1348,54 @@ type CallToolRequest struct {
 
 // CallToolResult represents the result of calling a tool
 type CallToolResult struct {
-	Success      bool        `json:"success"`
-	Content      interface{} `json:"content,omitempty"`
-	IsError      bool        `json:"is_error,omitempty"`
-	ErrorMessage string      `json:"error_message,omitempty"`
+	Success bool `json:"success"`
+	// Content is the result's []gateway.ContentBlock when the server returned a valid MCP
+	// tool result; otherwise whatever it returned
+	Content           interface{}     `json:"content,omitempty"`
+	StructuredContent json.RawMessage `json:"structured_content,omitempty"`
+	IsError           bool            `json:"is_error,omitempty"`
+	ErrorMessage      string          `json:"error_message,omitempty"`
+}
+
+// Blocks returns the typed content blocks of an MCP tool result, or nil when the server
+// returned something else
+func (r *CallToolResult) Blocks() []gateway.ContentBlock {
+	blocks, _ := r.Content.([]gateway.ContentBlock)
+	return blocks
+}
+
+// setToolResult fills result from a JSON-RPC tools/call result. Content blocks are typed
+// when the result is well formed, and kept as returned otherwise.
+func (s *Service) setToolResult(result *CallToolResult, rpcResult map[string]interface{}) {
+	result.Success = true
+	if isError, ok := rpcResult["isError"].(bool); ok {
+		result.IsError = isError
+	}
+
+	raw, err := json.Marshal(rpcResult)
+	if err == nil {
+		var parsed *gateway.ToolResult
+		if parsed, err = gateway.ParseToolResult(raw); err == nil {
+			result.Content = parsed.Content
+			result.StructuredContent = parsed.StructuredContent
+			return
+		}
+	}
+	s.logger.Debug().Err(err).Msg("Tool result content is not typed")
+	result.Content = rpcResult["content"]
+}
+
+// limitToolResponse applies the response size limit to a tool call response
+func (s *Service) limitToolResponse(resp *http.Response) {
+	gateway.LimitResponseBody(resp, s.maxResponseBytes)
+}
+
+// toolResponseError describes a failure to read a tool call response
+func toolResponseError(err error) string {
+	if errors.Is(err, gateway.ErrResponseTooLarge) {
+		return fmt.Sprintf("Tool result is too large: %v", err)
+	}
+	return fmt.Sprintf("Failed to read response: %v", err)
 }
 
 // CallTool executes a tool on an MCP server
@@ -573,11 +1428,99 @@ func (s *Service) CallTool(ctx context.Context, req *CallToolRequest) (*CallTool
 	}
 }
 
+// TestCallRequest is a tool call against a server that isn't registered, made with the
+// gateway's MCP clients so auth and the SSRF checks apply as they would once registered
+type TestCallRequest struct {
+	URL             string                 `json:"url"`
+	Transport       domain.TransportType   `json:"transport"`
+	ProtocolVersion string                 `json:"protocol_version"`
+	ToolName        string                 `json:"tool_name"`
+	Arguments       map[string]interface{} `json:"arguments"`
+	TimeoutSeconds  int                    `json:"timeout"`
+	AuthType        domain.ServerAuthType  `json:"auth_type"`
+	AuthConfig      json.RawMessage        `json:"auth_config"`
+}
+
+// TestCallTool initializes a session with the server described by req, calls the tool
+// and closes the session again. Upstream failures are reported in the result. Returns
+// domain.ErrTestCallUnsupported for transports other than Streamable HTTP and SSE, and
+// the URL check's errors for servers that could not be registered.
+func (s *Service) TestCallTool(ctx context.Context, req *TestCallRequest) (*CallToolResult, error) {
+	transport := req.Transport
+	if transport == "" {
+		transport = domain.TransportStreamableHTTP
+	}
+	if transport != domain.TransportStreamableHTTP && transport != domain.TransportSSE {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTestCallUnsupported, transport)
+	}
+	if err := s.checkServerURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	timeout := req.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = 30
+	}
+	callCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	// A server ID of its own keeps the call's session apart from registered servers'
+	server := &domain.MCPServer{
+		ID:                "test-call-" + uuid.NewString(),
+		Name:              "test-call",
+		URL:               req.URL,
+		ProtocolVersion:   req.ProtocolVersion,
+		Transport:         transport,
+		AuthType:          req.AuthType,
+		AuthConfig:        req.AuthConfig,
+		TimeoutSeconds:    timeout,
+		IsActive:          true,
+		RequireInitialize: true,
+	}
+	params := map[string]interface{}{"name": req.ToolName, "arguments": req.Arguments}
+
+	var raw json.RawMessage
+	var err error
+	if transport == domain.TransportSSE {
+		raw, err = s.sseClient.Call(callCtx, server, "tools/call", params)
+	} else {
+		raw, err = s.mcpClient.Call(callCtx, server, "tools/call", params)
+		if termErr := s.mcpClient.TerminateSession(ctx, server); termErr != nil {
+			s.logger.Debug().Err(termErr).Str("url", req.URL).Msg("Failed to terminate test call session")
+		}
+		// Termination skips sessionless servers and keeps the session when it fails, and
+		// nothing calls this server ID again
+		s.mcpClient.ForgetSession(server.ID)
+	}
+
+	result := &CallToolResult{}
+	if err != nil {
+		result.ErrorMessage = fmt.Sprintf("Tool call failed: %v", err)
+		if errors.Is(err, gateway.ErrResponseTooLarge) {
+			result.ErrorMessage = toolResponseError(err)
+		}
+		return result, nil
+	}
+	var rpcResult map[string]interface{}
+	if err := json.Unmarshal(raw, &rpcResult); err != nil {
+		result.ErrorMessage = fmt.Sprintf("Failed to parse tool result: %v", err)
+		return result, nil
+	}
+	s.setToolResult(result, rpcResult)
+
+	s.logger.Info().
+		Str("url", req.URL).
+		Str("transport", string(transport)).
+		Str("tool", req.ToolName).
+		Msg("Test tool call completed")
+	return result, nil
+}
+
 // callToolStreamableHTTP calls a tool using Streamable HTTP transport
 // Note: Streamable HTTP servers may return SSE format responses
 func (s *Service) callToolStreamableHTTP(ctx context.Context, req *CallToolRequest) *CallToolResult {
 	result := &CallToolResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	protocolVersion := req.ProtocolVersion
 	if protocolVersion == "" {
@@ -669,11 +1612,12 @@ func (s *Service) callToolStreamableHTTP(ctx context.Context, req *CallToolReque
 		return result
 	}
 	defer callResp.Body.Close()
+	s.limitToolResponse(callResp)
 
 	// Read the response body
 	respBody, err := io.ReadAll(callResp.Body)
 	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("Failed to read response: %v", err)
+		result.ErrorMessage = toolResponseError(err)
 		return result
 	}
 
@@ -700,11 +1644,7 @@ func (s *Service) callToolStreamableHTTP(ctx context.Context, req *CallToolReque
 
 	// Extract the result
 	if rpcResult, ok := callResult["result"].(map[string]interface{}); ok {
-		result.Success = true
-		result.Content = rpcResult["content"]
-		if isError, ok := rpcResult["isError"].(bool); ok {
-			result.IsError = isError
-		}
+		s.setToolResult(result, rpcResult)
 	} else {
 		result.Success = true
 		result.Content = callResult
@@ -716,7 +1656,7 @@ func (s *Service) callToolStreamableHTTP(ctx context.Context, req *CallToolReque
 // callToolHTTP calls a tool using HTTP transport
 func (s *Service) callToolHTTP(ctx context.Context, req *CallToolRequest) *CallToolResult {
 	result := &CallToolResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	callURL := req.URL + "/tools/call"
 	payload := map[string]interface{}{
@@ -738,9 +1678,14 @@ func (s *Service) callToolHTTP(ctx context.Context, req *CallToolRequest) *CallT
 		return result
 	}
 	defer resp.Body.Close()
+	s.limitToolResponse(resp)
 
 	var callResult map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&callResult); err != nil {
+		if errors.Is(err, gateway.ErrResponseTooLarge) {
+			result.ErrorMessage = toolResponseError(err)
+			return result
+		}
 		result.ErrorMessage = fmt.Sprintf("Failed to parse response: %v", err)
 		return result
 	}
@@ -755,7 +1700,7 @@ func (s *Service) callToolHTTP(ctx context.Context, req *CallToolRequest) *CallT
 // Note: Many SSE servers actually use Streamable HTTP protocol (POST with SSE response)
 func (s *Service) callToolSSE(ctx context.Context, req *CallToolRequest) *CallToolResult {
 	result := &CallToolResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	callPayload := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -789,9 +1734,14 @@ func (s *Service) callToolSSE(ctx context.Context, req *CallToolRequest) *CallTo
 		}
 	}
 	defer resp.Body.Close()
+	s.limitToolResponse(resp)
 
 	// Read response body
-	bodyBytes, _ := io.ReadAll(resp.Body)
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.ErrorMessage = toolResponseError(err)
+		return result
+	}
 	bodyStr := string(bodyBytes)
 
 	var callResult map[string]interface{}
@@ -814,11 +1764,7 @@ func (s *Service) callToolSSE(ctx context.Context, req *CallToolRequest) *CallTo
 	}
 
 	if rpcResult, ok := callResult["result"].(map[string]interface{}); ok {
-		result.Success = true
-		result.Content = rpcResult["content"]
-		if isError, ok := rpcResult["isError"].(bool); ok {
-			result.IsError = isError
-		}
+		s.setToolResult(result, rpcResult)
 	} else {
 		result.Success = true
 		result.Content = callResult
@@ -831,7 +1777,7 @@ func (s *Service) callToolSSE(ctx context.Context, req *CallToolRequest) *CallTo
 // Note: Many SSE servers actually use Streamable HTTP protocol (POST with SSE response)
 func (s *Service) testSSETransport(ctx context.Context, baseURL string) *TestConnectionResult {
 	result := &TestConnectionResult{}
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := s.newHTTPClient(30 * time.Second)
 
 	// SSE servers that support Streamable HTTP require both Accept types
 	initPayload := map[string]interface{}{
@@ -889,7 +1835,7 @@ func (s *Service) testSSETransport(ctx context.Context, baseURL string) *TestCon
 
 	if rpcResult, ok := initResult["result"].(map[string]interface{}); ok {
 		result.Success = true
-		result.ServerInfo = rpcResult["serverInfo"]
+		result.applyInitializeResult(rpcResult)
 	} else if rpcError, ok := initResult["error"].(map[string]interface{}); ok {
 		result.ErrorMessage = fmt.Sprintf("%v", rpcError["message"])
 		return result
@@ -2,17 +2,25 @@ package registry
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/waffles/waffles/internal/config"
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/service/gateway"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -20,6 +28,8 @@ import (
 type mockServerRepository struct {
 	servers       map[string]*domain.MCPServer
 	healthRecords map[string]*domain.ServerHealth
+	healthEvents  []*domain.ServerHealthEvent
+	healthMu      sync.Mutex // Scheduled health checks save concurrently
 
 	// Error injection for testing error paths
 	createErr           error
@@ -30,6 +40,7 @@ type mockServerRepository struct {
 	deleteErr           error
 	getHealthStatusErr  error
 	saveHealthStatusErr error
+	saveHealthEventErr  error
 }
 
 func newMockRepository() *mockServerRepository {
@@ -70,6 +81,22 @@ func (m *mockServerRepository) Create(ctx context.Context, req *domain.ServerCre
 	return server, nil
 }
 
+func (m *mockServerRepository) CreateBatch(ctx context.Context, reqs []*domain.ServerCreate) ([]*domain.MCPServer, error) {
+	// All or nothing, like the transactional repository
+	created := make([]*domain.MCPServer, 0, len(reqs))
+	for _, req := range reqs {
+		server, err := m.Create(ctx, req)
+		if err != nil {
+			for _, s := range created {
+				delete(m.servers, s.ID)
+			}
+			return nil, err
+		}
+		created = append(created, server)
+	}
+	return created, nil
+}
+
 func (m *mockServerRepository) Get(ctx context.Context, id string) (*domain.MCPServer, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
@@ -90,6 +117,9 @@ func (m *mockServerRepository) List(ctx context.Context, filter *domain.ServerFi
 
 	var servers []*domain.MCPServer
 	for _, server := range m.servers {
+		if filter != nil && filter.IsActive != nil && server.IsActive != *filter.IsActive {
+			continue
+		}
 		servers = append(servers, server)
 	}
 
@@ -155,6 +185,9 @@ func (m *mockServerRepository) Update(ctx context.Context, id string, req *domai
 	if req.MaxConnections != nil {
 		server.MaxConnections = *req.MaxConnections
 	}
+	if req.AuthConfig != nil {
+		server.AuthConfig = req.AuthConfig
+	}
 
 	server.UpdatedAt = time.Now()
 
@@ -175,12 +208,24 @@ func (m *mockServerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockServerRepository) CountActive(ctx context.Context) (int, error) {
+	count := 0
+	for _, server := range m.servers {
+		if server.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (m *mockServerRepository) GetHealthStatus(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
 	if m.getHealthStatusErr != nil {
 		return nil, m.getHealthStatusErr
 	}
 
+	m.healthMu.Lock()
 	health, ok := m.healthRecords[serverID]
+	m.healthMu.Unlock()
 	if !ok {
 		return nil, domain.ErrNotFound
 	}
@@ -193,11 +238,48 @@ func (m *mockServerRepository) SaveHealthStatus(ctx context.Context, health *dom
 		return m.saveHealthStatusErr
 	}
 
+	m.healthMu.Lock()
 	m.healthRecords[health.ServerID] = health
+	m.healthMu.Unlock()
+
+	return nil
+}
+
+func (m *mockServerRepository) SaveHealthEvent(ctx context.Context, event *domain.ServerHealthEvent) error {
+	if m.saveHealthEventErr != nil {
+		return m.saveHealthEventErr
+	}
+
+	m.healthMu.Lock()
+	m.healthEvents = append(m.healthEvents, event)
+	m.healthMu.Unlock()
 
 	return nil
 }
 
+func (m *mockServerRepository) ListHealthEvents(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealthEvent, error) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+
+	events := make([]*domain.ServerHealthEvent, 0)
+	for i := len(m.healthEvents) - 1; i >= 0 && len(events) < limit; i-- {
+		if m.healthEvents[i].ServerID == serverID {
+			events = append(events, m.healthEvents[i])
+		}
+	}
+
+	return events, nil
+}
+
+func (m *mockServerRepository) SetSystemTags(ctx context.Context, id string, tags []string) error {
+	server, ok := m.servers[id]
+	if !ok {
+		return domain.ErrServerNotFound
+	}
+	server.SystemTags = tags
+	return nil
+}
+
 // testableService wraps Service for testing with mock repository.
 type testableService struct {
 	*Service
@@ -420,6 +502,182 @@ func TestCreateServer_RepositoryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "database error")
 }
 
+func TestCreateServer_MaxActiveServers(t *testing.T) {
+	mockRepo := newMockRepository()
+	// Keeps the async initial health check away from the servers map
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	s.SetMaxActiveServers(2)
+	ctx := context.Background()
+
+	// Disabled servers don't count toward the cap
+	mockRepo.servers["disabled"] = &domain.MCPServer{ID: "disabled", IsActive: false}
+
+	for _, name := range []string{"server-a", "server-b"} {
+		_, err := s.CreateServer(ctx, &domain.ServerCreate{Name: name, URL: "https://example.com/mcp"})
+		require.NoError(t, err, "creating up to the cap succeeds")
+	}
+
+	server, err := s.CreateServer(ctx, &domain.ServerCreate{Name: "server-c", URL: "https://example.com/mcp"})
+	assert.ErrorIs(t, err, domain.ErrServerLimitReached)
+	assert.Nil(t, server)
+	assert.Len(t, mockRepo.servers, 3)
+}
+
+func TestCreateServer_RejectsGatewayURL(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	guard, err := gateway.NewLoopGuard("gateway-a", gateway.ListenURLs("0.0.0.0", 8080)...)
+	require.NoError(t, err)
+	s.SetLoopGuard(guard)
+	ctx := context.Background()
+
+	server, err := s.CreateServer(ctx, &domain.ServerCreate{Name: "self", URL: "http://localhost:8080/api/v1/gateway/server-1"})
+	assert.ErrorIs(t, err, domain.ErrServerURLIsGateway)
+	assert.Nil(t, server)
+	assert.Empty(t, mockRepo.servers)
+
+	server, err = s.CreateServer(ctx, &domain.ServerCreate{Name: "backend", URL: "http://localhost:9090/mcp"})
+	require.NoError(t, err)
+
+	selfURL := "http://127.0.0.1:8080/mcp"
+	_, err = s.UpdateServer(ctx, server.ID, &domain.ServerUpdate{URL: &selfURL})
+	assert.ErrorIs(t, err, domain.ErrServerURLIsGateway)
+}
+
+func TestCreateServer_RejectsInternalAddress(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	guard, err := gateway.NewAddressGuard([]string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	s.SetAddressGuard(guard)
+	ctx := context.Background()
+
+	server, err := s.CreateServer(ctx, &domain.ServerCreate{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data"})
+	assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+	assert.ErrorIs(t, err, gateway.ErrAddressBlocked)
+	assert.Nil(t, server)
+	assert.Empty(t, mockRepo.servers)
+
+	server, err = s.CreateServer(ctx, &domain.ServerCreate{Name: "allowed", URL: "http://10.1.0.7:9090/mcp"})
+	require.NoError(t, err)
+
+	loopbackURL := "http://127.0.0.1:9090/mcp"
+	_, err = s.UpdateServer(ctx, server.ID, &domain.ServerUpdate{URL: &loopbackURL})
+	assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+
+	// The health check URL is polled by the scheduler, so it is checked too
+	_, err = s.CreateServer(ctx, &domain.ServerCreate{Name: "probed", URL: "http://10.1.0.8:9090/mcp", HealthCheckURL: "http://169.254.169.254/health"})
+	assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+	_, err = s.UpdateServer(ctx, server.ID, &domain.ServerUpdate{HealthCheckURL: &loopbackURL})
+	assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+
+	summary := s.ImportServers(ctx, []*domain.ServerCreate{{Name: "imported", URL: "http://10.1.0.9:9090/mcp", HealthCheckURL: loopbackURL}}, nil, true)
+	assert.Equal(t, 1, summary.Failed)
+
+	_, err = s.TestConnection(ctx, &TestConnectionRequest{URL: loopbackURL})
+	assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+}
+
+// importRequests is a batch whose second server fails validation
+func importRequests() []*domain.ServerCreate {
+	return []*domain.ServerCreate{
+		{Name: "alpha", URL: "https://alpha.example.com/mcp"},
+		{Name: "broken"},
+		{Name: "gamma", URL: "https://gamma.example.com/mcp", TimeoutSeconds: 5},
+	}
+}
+
+func requireURL(req *domain.ServerCreate) error {
+	if req.URL == "" {
+		return errors.New("url is required")
+	}
+	return nil
+}
+
+func TestImportServers_AllOrNothing(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	ctx := context.Background()
+
+	summary := s.ImportServers(ctx, importRequests(), requireURL, false)
+
+	assert.Equal(t, 0, summary.Created)
+	assert.Equal(t, 3, summary.Failed)
+	require.Len(t, summary.Results, 3)
+	assert.Equal(t, "url is required", summary.Results[1].Error)
+	assert.Contains(t, summary.Results[0].Error, "another server in the import is invalid")
+	assert.Nil(t, summary.Results[0].Server)
+	assert.Empty(t, mockRepo.servers, "nothing is created when one server is invalid")
+
+	// Without the invalid server the whole batch is created, with defaults applied
+	reqs := importRequests()
+	summary = s.ImportServers(ctx, []*domain.ServerCreate{reqs[0], reqs[2]}, requireURL, false)
+
+	assert.Equal(t, 2, summary.Created)
+	assert.Equal(t, 0, summary.Failed)
+	assert.Equal(t, 1, summary.Results[1].Index)
+	require.NotNil(t, summary.Results[1].Server)
+	assert.Equal(t, 5, summary.Results[1].Server.TimeoutSeconds)
+	assert.Equal(t, 60, summary.Results[1].Server.HealthCheckInterval)
+	assert.Len(t, mockRepo.servers, 2)
+}
+
+func TestImportServers_AllOrNothingRollsBack(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	mockRepo.createErr = errors.New("duplicate key value")
+	s := NewService(mockRepo, logger.NewNopLogger())
+
+	reqs := importRequests()
+	summary := s.ImportServers(context.Background(), []*domain.ServerCreate{reqs[0], reqs[2]}, requireURL, false)
+
+	assert.Equal(t, 0, summary.Created)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Contains(t, summary.Results[0].Error, "rolled back")
+	assert.NotContains(t, summary.Results[0].Error, "duplicate key", "storage errors aren't returned")
+}
+
+func TestImportServers_AllOrNothingLimit(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	s.SetMaxActiveServers(1)
+
+	reqs := importRequests()
+	summary := s.ImportServers(context.Background(), []*domain.ServerCreate{reqs[0], reqs[2]}, requireURL, false)
+
+	assert.Equal(t, 0, summary.Created)
+	assert.Contains(t, summary.Results[0].Error, domain.ErrServerLimitReached.Error())
+	assert.Empty(t, mockRepo.servers)
+}
+
+func TestImportServers_ContinueOnError(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.getErr = errors.New("not under test")
+	s := NewService(mockRepo, logger.NewNopLogger())
+	guard, err := gateway.NewLoopGuard("gateway-a", gateway.ListenURLs("0.0.0.0", 8080)...)
+	require.NoError(t, err)
+	s.SetLoopGuard(guard)
+
+	reqs := append(importRequests(), &domain.ServerCreate{Name: "self", URL: "http://localhost:8080/mcp"})
+	summary := s.ImportServers(context.Background(), reqs, requireURL, true)
+
+	assert.Equal(t, 2, summary.Created)
+	assert.Equal(t, 2, summary.Failed)
+	require.Len(t, summary.Results, 4)
+	assert.Equal(t, "alpha", summary.Results[0].Server.Name)
+	assert.Empty(t, summary.Results[0].Error)
+	assert.Equal(t, "url is required", summary.Results[1].Error)
+	assert.Nil(t, summary.Results[1].Server)
+	assert.Equal(t, "gamma", summary.Results[2].Server.Name)
+	assert.Contains(t, summary.Results[3].Error, domain.ErrServerURLIsGateway.Error())
+	assert.Len(t, mockRepo.servers, 2)
+}
+
 func TestListServers_Success(t *testing.T) {
 	ts := newTestableService()
 	ctx := context.Background()
@@ -598,6 +856,23 @@ func TestUpdateServer_Success(t *testing.T) {
 	assert.Equal(t, "https://new.example.com", server.URL)
 }
 
+func TestUpdateServer_KeepsRedactedCredentials(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:         "server-1",
+		AuthType:   domain.ServerAuthBasic,
+		AuthConfig: json.RawMessage(`{"username":"bot","password":"hunter2"}`),
+	}
+	svc := NewService(mockRepo, logger.NewNopLogger())
+
+	// The config as GET returns it, with the username changed
+	update := &domain.ServerUpdate{AuthConfig: json.RawMessage(`{"username":"deploy-bot","password":"***"}`)}
+	server, err := svc.UpdateServer(context.Background(), "server-1", update)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"deploy-bot","password":"hunter2"}`, string(server.AuthConfig))
+}
+
 func TestUpdateServer_NotFound(t *testing.T) {
 	ts := newTestableService()
 	ctx := context.Background()
@@ -669,6 +944,25 @@ func TestToggleServer_Disable(t *testing.T) {
 	assert.False(t, server.IsActive)
 }
 
+func TestToggleServer_EnableBeyondMaxActiveServers(t *testing.T) {
+	mockRepo := newMockRepository()
+	s := NewService(mockRepo, logger.NewNopLogger())
+	s.SetMaxActiveServers(1)
+	ctx := context.Background()
+
+	mockRepo.servers["active"] = &domain.MCPServer{ID: "active", IsActive: true}
+	mockRepo.servers["disabled"] = &domain.MCPServer{ID: "disabled", IsActive: false}
+
+	_, err := s.ToggleServer(ctx, "disabled", true)
+	assert.ErrorIs(t, err, domain.ErrServerLimitReached)
+	assert.False(t, mockRepo.servers["disabled"].IsActive)
+
+	// Re-enabling an already active server doesn't count it twice
+	server, err := s.ToggleServer(ctx, "active", true)
+	require.NoError(t, err)
+	assert.True(t, server.IsActive)
+}
+
 func TestToggleServer_NotFound(t *testing.T) {
 	ts := newTestableService()
 	ctx := context.Background()
@@ -877,6 +1171,665 @@ func TestPerformHealthCheck_ConnectionFailed(t *testing.T) {
 	assert.Contains(t, errorMsg, "Request failed")
 }
 
+func TestPerformHealthCheck_AddressGuard(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer ts.Close()
+
+	// A server registered before the guard, or whose name was rebound after the URL
+	// check, is refused when the connection is dialed
+	s := NewService(newMockRepository(), logger.NewNopLogger())
+	guard, err := gateway.NewAddressGuard(nil)
+	require.NoError(t, err)
+	s.SetAddressGuard(guard)
+
+	status, _, errorMsg := s.performHealthCheck(context.Background(), ts.URL+"/health")
+
+	assert.Equal(t, domain.ServerStatusUnhealthy, status)
+	assert.Contains(t, errorMsg, gateway.ErrAddressBlocked.Error())
+	assert.Zero(t, hits.Load())
+}
+
+func TestPerformHealthCheck_EgressPolicy(t *testing.T) {
+	policy, err := gateway.NewEgressPolicy("", []string{"mcp.internal"})
+	require.NoError(t, err)
+	s := NewService(newMockRepository(), logger.NewNopLogger())
+	s.SetEgressPolicy(policy)
+
+	status, _, errorMsg := s.performHealthCheck(context.Background(), "http://metadata.internal/health")
+
+	assert.Equal(t, domain.ServerStatusUnhealthy, status)
+	assert.Contains(t, errorMsg, gateway.ErrEgressDenied.Error())
+
+	// The MCP handshake health check connects through the same policy
+	_, err = s.mcpClient.Initialize(context.Background(), &domain.MCPServer{ID: "metadata", URL: "http://metadata.internal/mcp", IsActive: true})
+	assert.ErrorIs(t, err, gateway.ErrEgressDenied)
+}
+
+// mockMCPClient implements MCPClient for testing protocol-level health checks.
+type mockMCPClient struct {
+	session         *gateway.MCPSession
+	initErr         error
+	initCalled      bool
+	terminateCalled bool
+	callErr         error
+	calledMethods   []string
+	forgotten       []string
+}
+
+func (m *mockMCPClient) Initialize(ctx context.Context, server *domain.MCPServer) (*gateway.MCPSession, error) {
+	m.initCalled = true
+	if m.initErr != nil {
+		return nil, m.initErr
+	}
+	return m.session, nil
+}
+
+func (m *mockMCPClient) Call(ctx context.Context, server *domain.MCPServer, method string, params interface{}) (json.RawMessage, error) {
+	m.calledMethods = append(m.calledMethods, method)
+	if m.callErr != nil {
+		return nil, m.callErr
+	}
+	return json.RawMessage(`{"tools":[]}`), nil
+}
+
+func (m *mockMCPClient) TerminateSession(ctx context.Context, server *domain.MCPServer) error {
+	m.terminateCalled = true
+	return nil
+}
+
+func (m *mockMCPClient) ForgetSession(serverID string) {
+	m.forgotten = append(m.forgotten, serverID)
+}
+
+func TestCheckHealth_MCPCapturesServerVersion(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            "http://backend.invalid/mcp",
+		Transport:      domain.TransportStreamableHTTP,
+		TimeoutSeconds: 5,
+	}
+	mockClient := &mockMCPClient{
+		session: &gateway.MCPSession{
+			ServerInfo: gateway.ServerInfo{Name: "backend", Version: "2.3.1"},
+		},
+	}
+	s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger()}
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusHealthy, health.Status)
+	assert.Equal(t, "2.3.1", health.ServerVersion)
+	assert.Equal(t, domain.HealthCheckModeMCP, health.CheckMode)
+	assert.Empty(t, health.ErrorMessage)
+	assert.Equal(t, []string{"tools/list"}, mockClient.calledMethods)
+	assert.True(t, mockClient.terminateCalled)
+}
+
+func TestCheckHealth_AutoTagsCapabilities(t *testing.T) {
+	newServer := func() *domain.MCPServer {
+		return &domain.MCPServer{
+			ID:             "server-1",
+			URL:            "http://backend.invalid/mcp",
+			Transport:      domain.TransportStreamableHTTP,
+			TimeoutSeconds: 5,
+			Tags:           []string{"team:search"},
+		}
+	}
+	mockClient := &mockMCPClient{
+		session: &gateway.MCPSession{
+			Capabilities: json.RawMessage(`{"tools":{"listChanged":true},"resources":{},"logging":null}`),
+		},
+	}
+
+	t.Run("tags advertised capabilities", func(t *testing.T) {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = newServer()
+		s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger(), autoTag: true}
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		server := mockRepo.servers["server-1"]
+		assert.Equal(t, []string{"has:resources", "has:tools"}, server.SystemTags)
+		assert.Equal(t, []string{"team:search"}, server.Tags, "user tags are left alone")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = newServer()
+		s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger()}
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		assert.Empty(t, mockRepo.servers["server-1"].SystemTags)
+	})
+
+	t.Run("unreachable server keeps its tags", func(t *testing.T) {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = newServer()
+		mockRepo.servers["server-1"].SystemTags = []string{"has:prompts"}
+		failing := &mockMCPClient{initErr: errors.New("connection refused")}
+		s := &Service{repo: mockRepo, mcpClient: failing, logger: logger.NewNopLogger(), autoTag: true}
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		assert.Equal(t, []string{"has:prompts"}, mockRepo.servers["server-1"].SystemTags)
+	})
+}
+
+func TestCapabilityTags(t *testing.T) {
+	assert.Equal(t, []string{"has:prompts", "has:resources"}, CapabilityTags(json.RawMessage(`{"resources":{"subscribe":true},"prompts":{}}`)))
+	assert.Empty(t, CapabilityTags(json.RawMessage(`{}`)))
+	assert.Empty(t, CapabilityTags(nil))
+}
+
+func TestCheckHealth_MCPInitializeFails(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            "http://backend.invalid/mcp",
+		Transport:      domain.TransportStreamableHTTP,
+		TimeoutSeconds: 5,
+	}
+	mockClient := &mockMCPClient{initErr: errors.New("connection refused")}
+	s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger()}
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusUnhealthy, health.Status)
+	assert.Empty(t, health.ServerVersion)
+	assert.Contains(t, health.ErrorMessage, "connection refused")
+	assert.False(t, mockClient.terminateCalled)
+}
+
+func TestCheckHealth_HealthCheckURLUsesHTTPGet(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL + "/mcp",
+		Transport:      domain.TransportStreamableHTTP,
+		HealthCheckURL: ts.URL + "/health",
+		TimeoutSeconds: 5,
+	}
+	mockClient := &mockMCPClient{}
+	s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger()}
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusHealthy, health.Status)
+	assert.Empty(t, health.ServerVersion)
+	assert.Equal(t, domain.HealthCheckModeHTTP, health.CheckMode)
+	assert.False(t, mockClient.initCalled)
+}
+
+func TestCheckHealth_MCPToolsListFailsIsDegraded(t *testing.T) {
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            "http://backend.invalid/mcp",
+		Transport:      domain.TransportStreamableHTTP,
+		TimeoutSeconds: 5,
+	}
+	mockClient := &mockMCPClient{
+		session: &gateway.MCPSession{ServerInfo: gateway.ServerInfo{Name: "backend", Version: "2.3.1"}},
+		callErr: errors.New("method not found"),
+	}
+	s := &Service{repo: mockRepo, mcpClient: mockClient, logger: logger.NewNopLogger()}
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusDegraded, health.Status)
+	assert.Equal(t, "2.3.1", health.ServerVersion)
+	assert.Contains(t, health.ErrorMessage, "tools/list")
+	assert.True(t, mockClient.terminateCalled)
+}
+
+func TestTestAuth(t *testing.T) {
+	// backend requires a bearer token and answers initialize once authenticated
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.NotEqual(t, "tools/call", req.Method)
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]interface{}{
+				"protocolVersion": gateway.MCPProtocolVersion,
+				"capabilities":    map[string]interface{}{},
+				"serverInfo":      map[string]interface{}{"name": "backend", "version": "1.0.0"},
+			},
+		})
+	}))
+	defer backend.Close()
+
+	newService := func(token string) *Service {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = &domain.MCPServer{
+			ID:             "server-1",
+			URL:            backend.URL + "/mcp",
+			Transport:      domain.TransportStreamableHTTP,
+			AuthType:       domain.ServerAuthBearer,
+			AuthConfig:     json.RawMessage(`{"token":"` + token + `"}`),
+			TimeoutSeconds: 5,
+		}
+		return NewService(mockRepo, logger.NewNopLogger())
+	}
+
+	t.Run("correct credentials authenticate", func(t *testing.T) {
+		result, err := newService("secret").TestAuth(context.Background(), "server-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, AuthTestAuthenticated, result.Result)
+		assert.True(t, result.Authenticated)
+		assert.Equal(t, "bearer", result.AuthType)
+		assert.Empty(t, result.Error)
+	})
+
+	t.Run("wrong credentials are rejected", func(t *testing.T) {
+		result, err := newService("wrong").TestAuth(context.Background(), "server-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, AuthTestRejected, result.Result)
+		assert.False(t, result.Authenticated)
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+		assert.Contains(t, result.Error, "invalid token")
+	})
+
+	t.Run("other failures leave auth unverified", func(t *testing.T) {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = &domain.MCPServer{ID: "server-1", Transport: domain.TransportStreamableHTTP, TimeoutSeconds: 5}
+		s := &Service{repo: mockRepo, mcpClient: &mockMCPClient{initErr: errors.New("connection refused")}, logger: logger.NewNopLogger()}
+
+		result, err := s.TestAuth(context.Background(), "server-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, AuthTestError, result.Result)
+		assert.Equal(t, "none", result.AuthType)
+		assert.Zero(t, result.StatusCode)
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		mockRepo := newMockRepository()
+		mockRepo.servers["server-1"] = &domain.MCPServer{ID: "server-1", Transport: domain.TransportWebSocket}
+		s := NewService(mockRepo, logger.NewNopLogger())
+
+		_, err := s.TestAuth(context.Background(), "server-1")
+		assert.ErrorIs(t, err, domain.ErrAuthTestUnsupported)
+	})
+}
+
+func TestTestCallTool(t *testing.T) {
+	// backend requires a bearer token, answers initialize and echoes tools/call
+	var methods []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			methods = append(methods, "DELETE")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			ID     interface{}            `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		methods = append(methods, req.Method)
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		result := map[string]interface{}{
+			"protocolVersion": gateway.MCPProtocolVersion,
+			"capabilities":    map[string]interface{}{},
+			"serverInfo":      map[string]interface{}{"name": "backend", "version": "1.0.0"},
+		}
+		if req.Method == "tools/call" {
+			args, _ := req.Params["arguments"].(map[string]interface{})
+			result = map[string]interface{}{
+				"content": []map[string]interface{}{{"type": "text", "text": args["text"]}},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer backend.Close()
+
+	newRequest := func(token string) *TestCallRequest {
+		return &TestCallRequest{
+			URL:            backend.URL + "/mcp",
+			ToolName:       "echo",
+			Arguments:      map[string]interface{}{"text": "hello"},
+			TimeoutSeconds: 5,
+			AuthType:       domain.ServerAuthBearer,
+			AuthConfig:     json.RawMessage(`{"token":"` + token + `"}`),
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		methods = nil
+		s := NewService(newMockRepository(), logger.NewNopLogger())
+
+		result, err := s.TestCallTool(context.Background(), newRequest("secret"))
+
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		require.Len(t, result.Blocks(), 1)
+		assert.Equal(t, "hello", result.Blocks()[0].Text)
+		assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/call", "DELETE"}, methods)
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		s := NewService(newMockRepository(), logger.NewNopLogger())
+
+		result, err := s.TestCallTool(context.Background(), newRequest("wrong"))
+
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Contains(t, result.ErrorMessage, "invalid token")
+	})
+
+	t.Run("forgets the session", func(t *testing.T) {
+		s := NewService(newMockRepository(), logger.NewNopLogger())
+		mockClient := &mockMCPClient{}
+		s.mcpClient = mockClient
+
+		_, err := s.TestCallTool(context.Background(), newRequest("secret"))
+
+		require.NoError(t, err)
+		require.Len(t, mockClient.forgotten, 1)
+		assert.True(t, strings.HasPrefix(mockClient.forgotten[0], "test-call-"))
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		s := NewService(newMockRepository(), logger.NewNopLogger())
+		req := newRequest("secret")
+		req.Transport = domain.TransportWebSocket
+
+		_, err := s.TestCallTool(context.Background(), req)
+		assert.ErrorIs(t, err, domain.ErrTestCallUnsupported)
+	})
+
+	t.Run("blocked URL", func(t *testing.T) {
+		s := NewService(newMockRepository(), logger.NewNopLogger())
+		guard, err := gateway.NewAddressGuard(nil)
+		require.NoError(t, err)
+		s.SetAddressGuard(guard)
+
+		_, err = s.TestCallTool(context.Background(), newRequest("secret"))
+		assert.ErrorIs(t, err, domain.ErrServerURLBlocked)
+	})
+}
+
+func TestCheckHealth_HTTPModeSkipsMCPHandshake(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL + "/mcp",
+		Transport:      domain.TransportStreamableHTTP,
+		TimeoutSeconds: 5,
+	}
+	s := NewServiceWithConfig(mockRepo, logger.NewNopLogger(), nil, config.HealthCheckConfig{Mode: config.HealthCheckModeHTTP})
+	mockClient := &mockMCPClient{}
+	s.mcpClient = mockClient
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusHealthy, health.Status)
+	assert.Equal(t, domain.HealthCheckModeHTTP, health.CheckMode)
+	assert.False(t, mockClient.initCalled)
+}
+
+func TestCheckHealth_MCPWithSSEServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), `"initialize"`) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25","capabilities":{},"serverInfo":{"name":"backend","version":"0.9.0"}}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":2,"result":{"tools":[]}}`))
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL,
+		Transport:      domain.TransportSSE,
+		TimeoutSeconds: 5,
+	}
+	s := NewService(mockRepo, logger.NewNopLogger())
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health := mockRepo.healthRecords["server-1"]
+	require.NotNil(t, health)
+	assert.Equal(t, domain.ServerStatusHealthy, health.Status)
+	assert.Equal(t, "0.9.0", health.ServerVersion)
+	assert.Equal(t, domain.HealthCheckModeMCP, health.CheckMode)
+}
+
+func TestCheckHealth_MCPWithStreamableHTTPServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"initialize"`) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-11-25","capabilities":{},"serverInfo":{"name":"backend","version":"1.4.0"}}}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL,
+		Transport:      domain.TransportStreamableHTTP,
+		TimeoutSeconds: 5,
+	}
+	s := NewService(mockRepo, logger.NewNopLogger())
+
+	err := s.CheckHealth(context.Background(), "server-1")
+
+	require.NoError(t, err)
+	health, err := s.GetHealthStatus(context.Background(), "server-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ServerStatusHealthy, health.Status)
+	assert.Equal(t, "1.4.0", health.ServerVersion)
+}
+
+// breakerRegistryResetter adapts a gateway breaker registry to BreakerResetter.
+type breakerRegistryResetter struct {
+	registry *gateway.BreakerRegistry
+}
+
+func (b *breakerRegistryResetter) ResetBreaker(serverID string) bool {
+	return b.registry.Reset(serverID)
+}
+
+func TestCheckHealth_ResetsBreakerOnSuccess(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	newOpenBreaker := func() *gateway.BreakerRegistry {
+		breakers := gateway.NewBreakerRegistry(gateway.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour})
+		breakers.Get("server-1").RecordFailure()
+		require.Equal(t, gateway.BreakerOpen, breakers.Get("server-1").State())
+		return breakers
+	}
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL,
+		HealthCheckURL: ts.URL + "/health",
+		TimeoutSeconds: 5,
+	}
+
+	t.Run("successful manual check closes breaker", func(t *testing.T) {
+		healthy = true
+		breakers := newOpenBreaker()
+		s := NewServiceWithBreakers(mockRepo, logger.NewNopLogger(), &breakerRegistryResetter{registry: breakers})
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		assert.Equal(t, gateway.BreakerClosed, breakers.Get("server-1").State())
+	})
+
+	t.Run("failed manual check leaves breaker open", func(t *testing.T) {
+		healthy = false
+		breakers := newOpenBreaker()
+		s := NewServiceWithBreakers(mockRepo, logger.NewNopLogger(), &breakerRegistryResetter{registry: breakers})
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		assert.Equal(t, gateway.BreakerOpen, breakers.Get("server-1").State())
+	})
+
+	t.Run("reset disabled leaves breaker open", func(t *testing.T) {
+		healthy = true
+		breakers := newOpenBreaker()
+		s := NewService(mockRepo, logger.NewNopLogger())
+
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+
+		assert.Equal(t, gateway.BreakerOpen, breakers.Get("server-1").State())
+	})
+}
+
+func TestCheckHealth_RecordsStatusTransitions(t *testing.T) {
+	statusCode := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL,
+		HealthCheckURL: ts.URL + "/health",
+		TimeoutSeconds: 5,
+	}
+	s := NewService(mockRepo, logger.NewNopLogger())
+
+	var notified []*domain.ServerHealthEvent
+	s.OnHealthTransition(func(event *domain.ServerHealthEvent) {
+		notified = append(notified, event)
+	})
+
+	// unknown -> healthy, healthy (no event), healthy -> unhealthy, unhealthy (no event)
+	for _, code := range []int{http.StatusOK, http.StatusOK, http.StatusServiceUnavailable, http.StatusServiceUnavailable} {
+		statusCode = code
+		require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+	}
+
+	require.Len(t, mockRepo.healthEvents, 2)
+	assert.Equal(t, domain.ServerStatusUnknown, mockRepo.healthEvents[0].PreviousStatus)
+	assert.Equal(t, domain.ServerStatusHealthy, mockRepo.healthEvents[0].NewStatus)
+	assert.Equal(t, domain.ServerStatusHealthy, mockRepo.healthEvents[1].PreviousStatus)
+	assert.Equal(t, domain.ServerStatusUnhealthy, mockRepo.healthEvents[1].NewStatus)
+	assert.Equal(t, "Server error: 503", mockRepo.healthEvents[1].ErrorMessage)
+	assert.Equal(t, mockRepo.healthEvents, notified)
+
+	t.Run("lists events newest first", func(t *testing.T) {
+		events, err := s.ListHealthEvents(context.Background(), "server-1", 0)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.ServerStatusUnhealthy, events[0].NewStatus)
+	})
+
+	t.Run("list returns error for unknown server", func(t *testing.T) {
+		_, err := s.ListHealthEvents(context.Background(), "missing", 10)
+		assert.ErrorIs(t, err, domain.ErrServerNotFound)
+	})
+}
+
+func TestCheckHealth_EventSaveFailureDoesNotFailCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mockRepo := newMockRepository()
+	mockRepo.saveHealthEventErr = errors.New("database error")
+	mockRepo.servers["server-1"] = &domain.MCPServer{
+		ID:             "server-1",
+		URL:            ts.URL,
+		HealthCheckURL: ts.URL + "/health",
+		TimeoutSeconds: 5,
+	}
+	s := NewService(mockRepo, logger.NewNopLogger())
+
+	require.NoError(t, s.CheckHealth(context.Background(), "server-1"))
+	assert.Equal(t, domain.ServerStatusHealthy, mockRepo.healthRecords["server-1"].Status)
+}
+
 func TestTestHTTPTransport_Success(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/initialize" {
@@ -1054,6 +2007,113 @@ func TestTestConnection_UnsupportedTransport(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, result.Success)
 	assert.Contains(t, result.ErrorMessage, "Unsupported transport")
+
+	require.NotNil(t, result.Error)
+	assert.Equal(t, ErrorCodeUnsupportedTransport, result.Error.Code)
+	assert.Equal(t, "unknown", result.Error.RequestedTransport)
+	assert.ElementsMatch(t, []string{"http", "streamable_http", "sse"}, result.Error.SupportedTransports)
+
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"error":{"code":"unsupported_transport","requested_transport":"unknown","supported_transports":["http","streamable_http","sse"]}`)
+}
+
+func TestTestConnection_SupportedTransportHasNoStructuredError(t *testing.T) {
+	s := &Service{logger: logger.NewNopLogger()}
+
+	result, err := s.TestConnection(context.Background(), &TestConnectionRequest{
+		URL:            "http://127.0.0.1:1",
+		Transport:      "streamable_http",
+		TimeoutSeconds: 1,
+	})
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Nil(t, result.Error)
+}
+
+func TestTestConnection_ReportsInitializeDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{},"prompts":{"listChanged":true}},"serverInfo":{"name":"demo","version":"1.2.0"}}}`))
+	}))
+	defer ts.Close()
+
+	s := &Service{logger: logger.NewNopLogger()}
+	result, err := s.TestConnection(context.Background(), &TestConnectionRequest{URL: ts.URL, Transport: "streamable_http"})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "streamable_http", result.Transport)
+	assert.Equal(t, "2025-06-18", result.ProtocolVersion)
+	assert.Equal(t, "demo", result.ServerName)
+	assert.Equal(t, "1.2.0", result.ServerVersion)
+	assert.Equal(t, &TestConnectionCapabilities{Tools: true, Prompts: true}, result.Capabilities)
+	require.Len(t, result.Attempts, 1)
+	assert.True(t, result.Attempts[0].Success)
+}
+
+func TestTestConnection_AutoDetectFallsThrough(t *testing.T) {
+	// A legacy server that only answers the REST-style initialize endpoint
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/initialize" {
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	s := &Service{logger: logger.NewNopLogger()}
+	result, err := s.TestConnection(context.Background(), &TestConnectionRequest{URL: ts.URL})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "http", result.Transport)
+	require.Len(t, result.Attempts, 3)
+	for i, transport := range []string{"streamable_http", "sse", "http"} {
+		assert.Equal(t, transport, result.Attempts[i].Transport)
+	}
+	assert.False(t, result.Attempts[0].Success)
+	assert.NotEmpty(t, result.Attempts[0].ErrorMessage)
+	assert.False(t, result.Attempts[1].Success)
+	assert.True(t, result.Attempts[2].Success)
+}
+
+func TestTestConnection_AutoDetectStopsAtFirstWorkingTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26","capabilities":{},"serverInfo":{"name":"demo"}}}`))
+	}))
+	defer ts.Close()
+
+	s := &Service{logger: logger.NewNopLogger()}
+	result, err := s.TestConnection(context.Background(), &TestConnectionRequest{URL: ts.URL})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "streamable_http", result.Transport)
+	assert.Equal(t, "2025-03-26", result.ProtocolVersion)
+	assert.Len(t, result.Attempts, 1)
+}
+
+func TestTestConnection_AutoDetectAllFail(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	s := &Service{logger: logger.NewNopLogger()}
+	result, err := s.TestConnection(context.Background(), &TestConnectionRequest{URL: ts.URL})
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.ErrorMessage, "No transport worked")
+	require.Len(t, result.Attempts, 3)
+	for _, attempt := range result.Attempts {
+		assert.False(t, attempt.Success)
+		assert.NotEmpty(t, attempt.ErrorMessage)
+	}
 }
 
 func TestTestConnection_DefaultTimeout(t *testing.T) {
@@ -1601,6 +2661,90 @@ func TestCallToolStreamableHTTP_ProtocolVersion(t *testing.T) {
 	assert.True(t, result.Success)
 }
 
+// toolResultBackend answers initialize and then tools/call with result
+func toolResultBackend(t *testing.T, result string) *httptest.Server {
+	t.Helper()
+	callCount := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if callCount == 1 {
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{},"id":1}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":%s}\n\n", result)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCallToolStreamableHTTP_ContentBlocks(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff}
+	data := base64.StdEncoding.EncodeToString(image)
+	ts := toolResultBackend(t, `{"content":[`+
+		`{"type":"text","text":"Here is the chart"},`+
+		`{"type":"image","data":"`+data+`","mimeType":"image/png","annotations":{"audience":["user"]}},`+
+		`{"type":"resource","resource":{"uri":"file:///report.csv","mimeType":"text/csv","text":"a,b"}},`+
+		`{"type":"resource_link","uri":"file:///full.csv","name":"full.csv"}],`+
+		`"structuredContent":{"rows":2}}`)
+
+	s := &Service{logger: logger.NewNopLogger(), maxResponseBytes: gateway.DefaultMaxResponseBytes}
+	result := s.callToolStreamableHTTP(context.Background(), &CallToolRequest{URL: ts.URL, ToolName: "chart"})
+
+	require.True(t, result.Success, result.ErrorMessage)
+	blocks := result.Blocks()
+	require.Len(t, blocks, 4)
+
+	assert.Equal(t, gateway.ContentTypeText, blocks[0].Type)
+	assert.Equal(t, "Here is the chart", blocks[0].Text)
+	assert.False(t, blocks[0].IsBinary())
+
+	assert.Equal(t, gateway.ContentTypeImage, blocks[1].Type)
+	assert.True(t, blocks[1].IsBinary())
+	assert.Equal(t, "image/png", blocks[1].MimeType)
+	decoded, err := blocks[1].Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, image, decoded)
+	assert.JSONEq(t, `{"audience":["user"]}`, string(blocks[1].Annotations))
+
+	assert.Equal(t, gateway.ContentTypeResource, blocks[2].Type)
+	require.NotNil(t, blocks[2].Resource)
+	assert.Equal(t, "file:///report.csv", blocks[2].Resource.URI)
+
+	assert.Equal(t, gateway.ContentTypeResourceLink, blocks[3].Type)
+	assert.Equal(t, "file:///full.csv", blocks[3].URI)
+
+	assert.JSONEq(t, `{"rows":2}`, string(result.StructuredContent))
+
+	// The API response carries the blocks as the server sent them
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"data":"`+data+`"`)
+	assert.Contains(t, string(body), `"annotations":{"audience":["user"]}`)
+}
+
+func TestCallToolStreamableHTTP_InvalidImageKeptUntyped(t *testing.T) {
+	ts := toolResultBackend(t, `{"content":[{"type":"image","data":"%%%","mimeType":"image/png"}]}`)
+
+	s := &Service{logger: logger.NewNopLogger(), maxResponseBytes: gateway.DefaultMaxResponseBytes}
+	result := s.callToolStreamableHTTP(context.Background(), &CallToolRequest{URL: ts.URL, ToolName: "chart"})
+
+	assert.True(t, result.Success)
+	assert.Nil(t, result.Blocks())
+	assert.NotNil(t, result.Content)
+}
+
+func TestCallToolStreamableHTTP_ResponseTooLarge(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString(make([]byte, 4096))
+	ts := toolResultBackend(t, `{"content":[{"type":"image","data":"`+blob+`","mimeType":"image/png"}]}`)
+
+	s := &Service{logger: logger.NewNopLogger(), maxResponseBytes: 1024}
+	result := s.callToolStreamableHTTP(context.Background(), &CallToolRequest{URL: ts.URL, ToolName: "chart"})
+
+	assert.False(t, result.Success)
+	assert.Contains(t, result.ErrorMessage, "too large")
+}
+
 func TestCallToolHTTP_WithContent(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,103 @@
+package serveraccess
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
+)
+
+// decisionCache remembers recent CanAccessServer decisions for a short TTL. Invalidating
+// it bumps a generation, so a decision computed while access was changing isn't stored.
+type decisionCache struct {
+	ttl        time.Duration
+	maxEntries int
+	metrics    metrics.CacheMetrics
+	now        func() time.Time
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]cachedDecision
+}
+
+// cachedDecision is a cached access decision
+type cachedDecision struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+func newDecisionCache(ttl time.Duration, maxEntries int, cacheMetrics metrics.CacheMetrics) *decisionCache {
+	return &decisionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		metrics:    cacheMetrics,
+		now:        time.Now,
+		entries:    make(map[string]cachedDecision),
+	}
+}
+
+// decisionKey identifies a decision by the caller's roles, in any order, the server and
+// the access level
+func decisionKey(roles []string, serverID string, level domain.AccessLevel) string {
+	sorted := slices.Clone(roles)
+	slices.Sort(sorted)
+	return strings.Join(sorted, ",") + "\x00" + serverID + "\x00" + string(level)
+}
+
+// get returns the unexpired decision for key, and the generation to store a fresh one with
+func (c *decisionCache) get(key string) (allowed, ok bool, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if found && c.now().Before(entry.expiresAt) {
+		c.metrics.Hit()
+		return entry.allowed, true, c.generation
+	}
+	c.metrics.Miss()
+	if found {
+		delete(c.entries, key)
+		c.metrics.SetSize(len(c.entries))
+	}
+	return false, false, c.generation
+}
+
+// put stores a decision unless the cache was invalidated since generation. When full,
+// expired entries are dropped and the decision isn't stored if that frees no room.
+func (c *decisionCache) put(key string, allowed bool, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	now := c.now()
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.maxEntries {
+		evicted := 0
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+				evicted++
+			}
+		}
+		c.metrics.Evicted(evicted)
+		if len(c.entries) >= c.maxEntries {
+			c.metrics.SetSize(len(c.entries))
+			return
+		}
+	}
+	c.entries[key] = cachedDecision{allowed: allowed, expiresAt: now.Add(c.ttl)}
+	c.metrics.SetSize(len(c.entries))
+}
+
+// invalidate drops every cached decision
+func (c *decisionCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+	c.metrics.SetSize(0)
+}
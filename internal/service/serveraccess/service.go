@@ -2,8 +2,10 @@ package serveraccess
 
 import (
 	"context"
+	"time"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -16,6 +18,7 @@ type NamespaceRepository interface {
 type Service struct {
 	namespaceRepo NamespaceRepository
 	logger        logger.Logger
+	decisions     *decisionCache // Recent CanAccessServer decisions (nil = not cached)
 }
 
 // NewService creates a new server access service
@@ -26,6 +29,21 @@ func NewService(namespaceRepo NamespaceRepository, log logger.Logger) *Service {
 	}
 }
 
+// EnableDecisionCache caches CanAccessServer decisions for ttl, keeping up to maxEntries,
+// and reports the cache's effectiveness to metricsReg, which may be nil. InvalidateCache
+// must be called when namespace access or policies change. Must be called before the
+// service is used.
+func (s *Service) EnableDecisionCache(ttl time.Duration, maxEntries int, metricsReg *metrics.Registry) {
+	s.decisions = newDecisionCache(ttl, maxEntries, metricsReg.Cache(metrics.CacheAccessDecisions))
+}
+
+// InvalidateCache drops the cached access decisions, so the next checks see current access
+func (s *Service) InvalidateCache() {
+	if s.decisions != nil {
+		s.decisions.invalidate()
+	}
+}
+
 // IsAdmin checks if any of the given roles is "admin"
 func (s *Service) IsAdmin(roles []string) bool {
 	for _, r := range roles {
@@ -73,6 +91,24 @@ func (s *Service) CanAccessServer(ctx context.Context, roles []string, serverID
 		return true, nil
 	}
 
+	if s.decisions == nil {
+		return s.canAccessServer(ctx, roles, serverID, level)
+	}
+	key := decisionKey(roles, serverID, level)
+	allowed, ok, generation := s.decisions.get(key)
+	if ok {
+		return allowed, nil
+	}
+	allowed, err := s.canAccessServer(ctx, roles, serverID, level)
+	if err != nil {
+		return false, err
+	}
+	s.decisions.put(key, allowed, generation)
+	return allowed, nil
+}
+
+// canAccessServer checks the server against those the roles can access at the level
+func (s *Service) canAccessServer(ctx context.Context, roles []string, serverID string, level domain.AccessLevel) (bool, error) {
 	// Get accessible server IDs
 	accessibleIDs, err := s.GetAccessibleServerIDs(ctx, roles, level)
 	if err != nil {
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/waffles/waffles/internal/domain"
+	"github.com/waffles/waffles/internal/metrics"
 	"github.com/waffles/waffles/pkg/logger"
 )
 
@@ -16,9 +18,11 @@ import (
 type mockNamespaceRepository struct {
 	err                 error
 	accessibleServerIDs []string
+	calls               int
 }
 
 func (m *mockNamespaceRepository) GetAccessibleServerIDs(ctx context.Context, roles []string, level domain.AccessLevel) ([]string, error) {
+	m.calls++
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -214,6 +218,86 @@ func TestCanAccessServer(t *testing.T) {
 	}
 }
 
+func TestCanAccessServer_DecisionCache(t *testing.T) {
+	newCachingService := func(repo *mockNamespaceRepository) *Service {
+		svc := NewService(repo, logger.NewNopLogger())
+		svc.EnableDecisionCache(time.Minute, 100, nil)
+		return svc
+	}
+	ctx := context.Background()
+
+	t.Run("repeat check within the TTL is cached", func(t *testing.T) {
+		repo := &mockNamespaceRepository{accessibleServerIDs: []string{"server-1"}}
+		svc := newCachingService(repo)
+
+		allowed, err := svc.CanAccessServer(ctx, []string{"viewer", "operator"}, "server-1", domain.AccessLevelExecute)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = svc.CanAccessServer(ctx, []string{"operator", "viewer"}, "server-1", domain.AccessLevelExecute)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 1, repo.calls)
+
+		_, err = svc.CanAccessServer(ctx, []string{"viewer", "operator"}, "server-1", domain.AccessLevelView)
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo.calls, "other access levels are decided separately")
+	})
+
+	t.Run("invalidation drops cached decisions", func(t *testing.T) {
+		repo := &mockNamespaceRepository{accessibleServerIDs: []string{"server-1"}}
+		svc := newCachingService(repo)
+
+		_, err := svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.NoError(t, err)
+
+		repo.accessibleServerIDs = nil
+		svc.InvalidateCache()
+
+		allowed, err := svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("expired decisions are checked again", func(t *testing.T) {
+		repo := &mockNamespaceRepository{accessibleServerIDs: []string{"server-1"}}
+		svc := newCachingService(repo)
+		now := time.Now()
+		svc.decisions.now = func() time.Time { return now }
+
+		_, err := svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+		_, err = svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		repo := &mockNamespaceRepository{err: errors.New("database unavailable")}
+		svc := newCachingService(repo)
+
+		_, err := svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.Error(t, err)
+		_, err = svc.CanAccessServer(ctx, []string{"viewer"}, "server-1", domain.AccessLevelView)
+		require.Error(t, err)
+
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("decision computed during invalidation is not stored", func(t *testing.T) {
+		cache := newDecisionCache(time.Minute, 100, metrics.NopCacheMetrics)
+		_, _, generation := cache.get("key")
+		cache.invalidate()
+		cache.put("key", true, generation)
+
+		_, ok, _ := cache.get("key")
+		assert.False(t, ok)
+	})
+}
+
 func TestGetAccessibleServerIDs(t *testing.T) {
 	tests := []struct {
 		name                string
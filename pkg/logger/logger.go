@@ -74,9 +74,17 @@ const (
 	FatalLevel Level = "fatal"
 )
 
+// Subsystems that can be given their own log level
+const (
+	SubsystemGateway = "gateway" // MCP proxy traffic
+	SubsystemAPI     = "api"     // Management APIs (servers, namespaces, users, keys)
+)
+
 // Config holds logger configuration
 type Config struct {
 	Level  Level
 	Format string // "json" or "console"
 	Output io.Writer
+	// Subsystems overrides Level for loggers returned by ForSubsystem
+	Subsystems map[string]Level
 }
@@ -143,6 +143,46 @@ func TestLogger_LevelFiltering(t *testing.T) {
 	assert.Contains(t, output, "error message")
 }
 
+func TestLogger_SubsystemLevels(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewZerolog(Config{
+		Level:  InfoLevel,
+		Format: "json",
+		Output: &buf,
+		Subsystems: map[string]Level{
+			SubsystemGateway: DebugLevel,
+			SubsystemAPI:     InfoLevel,
+		},
+	})
+
+	gatewayLog := ForSubsystem(log, SubsystemGateway)
+	apiLog := ForSubsystem(log, SubsystemAPI)
+
+	gatewayLog.Debug().Msg("gateway debug")
+	apiLog.Debug().Msg("api debug")
+	apiLog.Info().Msg("api info")
+	log.Debug().Msg("root debug")
+
+	output := buf.String()
+	assert.Contains(t, output, "gateway debug")
+	assert.Contains(t, output, `"subsystem":"gateway"`)
+	assert.NotContains(t, output, "api debug")
+	assert.Contains(t, output, "api info")
+	assert.NotContains(t, output, "root debug", "loggers outside a subsystem keep the default level")
+
+	// Derived loggers keep the subsystem level
+	buf.Reset()
+	gatewayLog.With().Str("server_id", "s1").Logger().Debug().Msg("derived debug")
+	gatewayLog.WithContext(WithRequestID(context.Background(), "req-1")).Debug().Msg("request debug")
+	assert.Contains(t, buf.String(), "derived debug")
+	assert.Contains(t, buf.String(), "request debug")
+}
+
+func TestForSubsystem_OtherLoggers(t *testing.T) {
+	nop := NewNopLogger()
+	assert.Same(t, nop, ForSubsystem(nop, SubsystemGateway))
+}
+
 func TestContext_RequestID(t *testing.T) {
 	ctx := context.Background()
 
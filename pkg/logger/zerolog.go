@@ -10,14 +10,24 @@ import (
 
 // ZeroLogger wraps zerolog.Logger to implement Logger interface
 type ZeroLogger struct {
-	zl zerolog.Logger
+	zl         zerolog.Logger
+	subsystems map[string]zerolog.Level
 }
 
 // NewZerolog creates a new Zerolog-based logger
 func NewZerolog(cfg Config) Logger {
-	// Set global level
+	// The global level is the most verbose configured level so subsystem
+	// loggers can log below the default; each logger filters to its own level
 	level := parseLevel(cfg.Level)
-	zerolog.SetGlobalLevel(level)
+	subsystems := make(map[string]zerolog.Level, len(cfg.Subsystems))
+	globalLevel := level
+	for name, l := range cfg.Subsystems {
+		subsystems[name] = parseLevel(l)
+		if subsystems[name] < globalLevel {
+			globalLevel = subsystems[name]
+		}
+	}
+	zerolog.SetGlobalLevel(globalLevel)
 
 	// Configure time format
 	zerolog.TimeFieldFormat = time.RFC3339
@@ -39,7 +49,22 @@ func NewZerolog(cfg Config) Logger {
 		zl = zerolog.New(output).With().Timestamp().Logger()
 	}
 
-	return &ZeroLogger{zl: zl}
+	return &ZeroLogger{zl: zl.Level(level), subsystems: subsystems}
+}
+
+// ForSubsystem returns a logger for the named subsystem, tagged with a "subsystem" field
+// and using the subsystem's configured level when one is set. Loggers that don't
+// support per-subsystem levels are returned unchanged.
+func ForSubsystem(log Logger, name string) Logger {
+	z, ok := log.(*ZeroLogger)
+	if !ok {
+		return log
+	}
+	zl := z.zl.With().Str("subsystem", name).Logger()
+	if level, ok := z.subsystems[name]; ok {
+		zl = zl.Level(level)
+	}
+	return &ZeroLogger{zl: zl, subsystems: z.subsystems}
 }
 
 // parseLevel converts Level to zerolog.Level
@@ -87,7 +112,7 @@ func (z *ZeroLogger) Fatal() Event {
 
 // With returns a context for adding fields
 func (z *ZeroLogger) With() Context {
-	return &ZeroContext{zc: z.zl.With()}
+	return &ZeroContext{zc: z.zl.With(), subsystems: z.subsystems}
 }
 
 // WithContext returns a logger with request context fields
@@ -104,7 +129,7 @@ func (z *ZeroLogger) WithContext(ctx context.Context) Logger {
 		zl = zl.With().Str("user_id", userID).Logger()
 	}
 
-	return &ZeroLogger{zl: zl}
+	return &ZeroLogger{zl: zl, subsystems: z.subsystems}
 }
 
 // ZeroEvent wraps zerolog.Event to implement Event interface
@@ -173,7 +198,8 @@ func (e *ZeroEvent) Send() {
 
 // ZeroContext wraps zerolog.Context to implement Context interface
 type ZeroContext struct {
-	zc zerolog.Context
+	zc         zerolog.Context
+	subsystems map[string]zerolog.Level
 }
 
 // Str adds a string field
@@ -202,5 +228,5 @@ func (c *ZeroContext) Err(err error) Context {
 
 // Logger returns the logger with the context fields
 func (c *ZeroContext) Logger() Logger {
-	return &ZeroLogger{zl: c.zc.Logger()}
+	return &ZeroLogger{zl: c.zc.Logger(), subsystems: c.subsystems}
 }
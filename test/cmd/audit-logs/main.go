@@ -19,7 +19,8 @@ func main() {
 
 	// Query audit logs
 	query := `
-		SELECT id, method, path, response_status, latency_ms, ip_address, created_at
+		SELECT id, method, path, response_status, latency_ms, ip_address, created_at,
+			tool_name, tool_is_error, tool_error_code
 		FROM audit_logs
 		ORDER BY created_at DESC
 		LIMIT 10
@@ -37,8 +38,12 @@ func main() {
 		var id, method, path, ipAddress string
 		var responseStatus, latencyMS *int
 		var createdAt string
+		var toolName *string
+		var toolIsError *bool
+		var toolErrorCode *int
 
-		err := rows.Scan(&id, &method, &path, &responseStatus, &latencyMS, &ipAddress, &createdAt)
+		err := rows.Scan(&id, &method, &path, &responseStatus, &latencyMS, &ipAddress, &createdAt,
+			&toolName, &toolIsError, &toolErrorCode)
 		if err != nil {
 			log.Printf("Error scanning row: %v", err)
 			continue
@@ -53,6 +58,16 @@ func main() {
 			fmt.Printf(", Latency: %dms", *latencyMS)
 		}
 		fmt.Printf(", IP: %s\n", ipAddress)
+		if toolName != nil {
+			fmt.Printf("   Tool: %s", *toolName)
+			if toolIsError != nil {
+				fmt.Printf(", isError: %t", *toolIsError)
+			}
+			if toolErrorCode != nil {
+				fmt.Printf(", JSON-RPC error: %d", *toolErrorCode)
+			}
+			fmt.Println()
+		}
 		fmt.Printf("   Created: %s\n\n", createdAt)
 	}
 
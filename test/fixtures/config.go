@@ -16,6 +16,7 @@ func DefaultTestConfig() *config.Config {
 			ReadTimeout:     30 * time.Second,
 			WriteTimeout:    30 * time.Second,
 			ShutdownTimeout: 10 * time.Second,
+			DrainTimeout:    15 * time.Second,
 		},
 		Database: config.DatabaseConfig{
 			Host:            "localhost",